@@ -24,6 +24,7 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"time"
 
 	cnitypes "github.com/containernetworking/cni/pkg/types"
 	"github.com/containernetworking/plugins/pkg/ip"
@@ -35,7 +36,9 @@ import (
 	"k8s.io/klog"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/everoute/everoute/pkg/agent/controller/policy"
 	"github.com/everoute/everoute/pkg/agent/datapath"
+	"github.com/everoute/everoute/pkg/agent/rpcserver"
 	"github.com/everoute/everoute/pkg/constants"
 	"github.com/everoute/everoute/pkg/utils"
 )
@@ -64,12 +67,132 @@ type CNIConf struct {
 	SvcInternalIP    string `yaml:"svcInternalIP,omitempty"`
 }
 
+type TCPRSTDetectConf struct {
+	Threshold     int `yaml:"threshold"`
+	WindowSeconds int `yaml:"windowSeconds"`
+	DenySeconds   int `yaml:"denySeconds"`
+	// AllowCIDRs are sources never denied, e.g. load balancers resetting connections normally
+	AllowCIDRs []string `yaml:"allowCIDRs,omitempty"`
+}
+
+type PacketInLimitConf struct {
+	Rate          int `yaml:"rate"`
+	Burst         int `yaml:"burst"`
+	LearningRate  int `yaml:"learningRate,omitempty"`
+	LearningBurst int `yaml:"learningBurst,omitempty"`
+}
+
+type FlowCompactionConf struct {
+	IntervalSeconds int `yaml:"intervalSeconds"`
+	LoadThreshold   int `yaml:"loadThreshold"`
+}
+
+type FlowCookieConf struct {
+	RoundNumBits int    `yaml:"roundNumBits"`
+	FlowSeqBits  int    `yaml:"flowSeqBits"`
+	MaxRoundNum  uint64 `yaml:"maxRoundNum"`
+}
+
+type PolicyTierConf struct {
+	Name         string `yaml:"name"`
+	ID           uint8  `yaml:"id,omitempty"`
+	EgressTable  uint8  `yaml:"egressTable,omitempty"`
+	IngressTable uint8  `yaml:"ingressTable,omitempty"`
+}
+
+type PriorityBandConf struct {
+	Min int32 `yaml:"min"`
+	Max int32 `yaml:"max"`
+}
+
+type RPCTCPConf struct {
+	Addr     string `yaml:"addr"`
+	CertFile string `yaml:"certFile"`
+	KeyFile  string `yaml:"keyFile"`
+	CAFile   string `yaml:"caFile"`
+
+	// DisableUnixSocket listen only on tcp instead of the unix socket
+	DisableUnixSocket bool `yaml:"disableUnixSocket,omitempty"`
+}
+
 type agentConfig struct {
 	DatapathConfig map[string]string `yaml:"datapathConfig"`
 
 	// InternalIPs allow the items all ingress and egress traffics
 	InternalIPs []string `yaml:"internalIPs,omitempty"`
 
+	// InternalIPGraceSeconds delay install internal ips whitelist after datapath initialized, default no delay
+	InternalIPGraceSeconds int `yaml:"internalIPGraceSeconds,omitempty"`
+
+	// IPLearningIgnoreCIDRs skip learning endpoint ip in the cidrs, default loopback and link-local cidrs
+	IPLearningIgnoreCIDRs []string `yaml:"ipLearningIgnoreCIDRs,omitempty"`
+
+	// CTTimeoutPolicy set conntrack idle timeout seconds per protocol (tcp, udp, icmp) for connections allowed by
+	// rules with ct timeout
+	CTTimeoutPolicy map[string]uint32 `yaml:"ctTimeoutPolicy,omitempty"`
+
+	// CTFlushHighWaterMark batch conntrack clean when pending rules reach the mark instead of flush all conntrack
+	CTFlushHighWaterMark int `yaml:"ctFlushHighWaterMark,omitempty"`
+
+	// DeferCTCleanInBatch clean conntrack of rules changed in a policy sync at once after all rules applied
+	DeferCTCleanInBatch bool `yaml:"deferCTCleanInBatch,omitempty"`
+	// MaxCTCleanDeferSeconds max seconds conntrack clean deferred in a policy sync, default 30
+	MaxCTCleanDeferSeconds int `yaml:"maxCTCleanDeferSeconds,omitempty"`
+
+	// CTZoneStrategy assign conntrack zone of policy by global or vlan, default global
+	CTZoneStrategy string `yaml:"ctZoneStrategy,omitempty"`
+
+	// VerifyRuleFlow read back and verify policy rule flow after install, it adds latency to rule install
+	VerifyRuleFlow bool `yaml:"verifyRuleFlow,omitempty"`
+
+	// TCPRSTDetect deny source which sent tcp rst of established connections exceeding threshold in window
+	// temporarily, disable by default
+	TCPRSTDetect *TCPRSTDetectConf `yaml:"tcpRSTDetect,omitempty"`
+
+	// PacketInLimit limit rate per second and burst of packets sent to agent of each reason from each bridge, default
+	// rate 1000 and burst 2000, packets of arp learning are limited by learningRate and learningBurst separately
+	PacketInLimit *PacketInLimitConf `yaml:"packetInLimit,omitempty"`
+
+	// DecisionRecordSize record latest verdicts of connections hit policy rules for querying by rpc, the first packet
+	// of connections hit rules are sent to agent limited by packetInLimit, disable by default
+	DecisionRecordSize int `yaml:"decisionRecordSize,omitempty"`
+
+	// EnablePolicyLogging log the first packet of connections hit policy rules enabled logging, packets are sent to
+	// agent limited by packetInLimit, disable by default
+	EnablePolicyLogging bool `yaml:"enablePolicyLogging,omitempty"`
+
+	// RuleFlowReconcileSeconds read back policy rule flows in the interval and reinstall rule flows absent in ovs,
+	// disable by default, rule flows are only replayed on bridge reconnect
+	RuleFlowReconcileSeconds int `yaml:"ruleFlowReconcileSeconds,omitempty"`
+
+	// FlowCompaction compact policy rule flows when reconciles in interval no more than loadThreshold, disable by default
+	FlowCompaction *FlowCompactionConf `yaml:"flowCompaction,omitempty"`
+
+	// FlowCookie set the round num and flow sequence bits of flow cookie, and the max round num before wrap.
+	// Nodes restart agent frequently could take more round bits, default 4 round bits and 28 sequence bits
+	FlowCookie *FlowCookieConf `yaml:"flowCookie,omitempty"`
+
+	// MaxRulesPerPolicy reject policy or groupmembers update expands policy to more rules than it and keep
+	// its rules installed before, not positive means unlimited, default unlimited
+	MaxRulesPerPolicy int `yaml:"maxRulesPerPolicy,omitempty"`
+
+	// PriorityBands clamp priority of policies in the namespace into the band, so that policies of a tenant
+	// can't outrank critical policies of other tenants, unlimited by default
+	PriorityBands map[string]PriorityBandConf `yaml:"priorityBands,omitempty"`
+
+	// PolicyTiers the ordered policy tiers packet walked through, custom tiers could be inserted among builtin
+	// tiers tier0, tier1, tier-ecp and tier2 with unused tables, builtin tiers are set by name only. Default builtin tiers.
+	// It could be reloaded on SIGHUP, tiers of installed rules must be kept in the same order.
+	PolicyTiers []PolicyTierConf `yaml:"policyTiers,omitempty"`
+
+	// CleanupMigratedEndpoint remove flows of local endpoint once it's located on other agents only, without waiting
+	// for its ovsdb interface removed, so that stale flows don't allow traffics after migrated. Disable by default
+	CleanupMigratedEndpoint bool `yaml:"cleanupMigratedEndpoint,omitempty"`
+
+	// RPCTCP enable agent rpc server listening on tcp with TLS and client certificate verification besides the
+	// unix socket, disable by default
+	RPCTCP *RPCTCPConf `yaml:"rpcTCP,omitempty"`
+
 	// use it to connect kube-apiServer
 	APIServer string `yaml:"apiServer,omitempty"`
 
@@ -131,6 +254,80 @@ func (o *Options) complete() error {
 	}
 	o.Config = agentConfig
 
+	switch o.Config.CTZoneStrategy {
+	case "", datapath.CTZoneStrategyGlobal, datapath.CTZoneStrategyVlan:
+	default:
+		return fmt.Errorf("unsupported ctZoneStrategy %s", o.Config.CTZoneStrategy)
+	}
+
+	for namespace, band := range o.getPriorityBands() {
+		if err := band.Validate(); err != nil {
+			return fmt.Errorf("invalid priorityBands of namespace %s: %s", namespace, err)
+		}
+	}
+
+	if policyTiers := o.getPolicyTiers(); policyTiers != nil {
+		if err := policyTiers.Validate(); err != nil {
+			return fmt.Errorf("invalid policyTiers: %s", err)
+		}
+	}
+
+	if rpcTCP := o.Config.RPCTCP; rpcTCP != nil {
+		if rpcTCP.Addr == "" {
+			return fmt.Errorf("addr of rpcTCP must be set")
+		}
+		if rpcTCP.CertFile == "" || rpcTCP.KeyFile == "" || rpcTCP.CAFile == "" {
+			return fmt.Errorf("certFile, keyFile and caFile of rpcTCP must be set, tcp listener requires tls with client certificate")
+		}
+		if rpcTCP.DisableUnixSocket && o.Config.EnableCNI {
+			return fmt.Errorf("unix socket of rpc server can't be disabled when cni enabled")
+		}
+	}
+
+	if rstDetect := o.Config.TCPRSTDetect; rstDetect != nil {
+		if rstDetect.Threshold <= 0 || rstDetect.WindowSeconds <= 0 || rstDetect.DenySeconds <= 0 {
+			return fmt.Errorf("threshold, windowSeconds and denySeconds of tcpRSTDetect must be positive")
+		}
+		for _, cidr := range rstDetect.AllowCIDRs {
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				return fmt.Errorf("invalid allowCIDRs of tcpRSTDetect: %s", err)
+			}
+		}
+	}
+
+	if _, err := datapath.ParseIPLearningIgnoreCIDRs(o.Config.IPLearningIgnoreCIDRs); err != nil {
+		return fmt.Errorf("invalid ipLearningIgnoreCIDRs: %s", err)
+	}
+
+	if packetInLimit := o.Config.PacketInLimit; packetInLimit != nil {
+		if packetInLimit.Rate <= 0 || packetInLimit.Burst <= 0 {
+			return fmt.Errorf("rate and burst of packetInLimit must be positive")
+		}
+		if packetInLimit.LearningRate < 0 || packetInLimit.LearningBurst < 0 {
+			return fmt.Errorf("learningRate and learningBurst of packetInLimit must not be negative")
+		}
+	}
+
+	if o.Config.DecisionRecordSize < 0 {
+		return fmt.Errorf("decisionRecordSize must not be negative")
+	}
+
+	if o.Config.RuleFlowReconcileSeconds < 0 {
+		return fmt.Errorf("ruleFlowReconcileSeconds must not be negative")
+	}
+
+	if flowCompaction := o.Config.FlowCompaction; flowCompaction != nil {
+		if flowCompaction.IntervalSeconds <= 0 || flowCompaction.LoadThreshold < 0 {
+			return fmt.Errorf("intervalSeconds of flowCompaction must be positive and loadThreshold must not be negative")
+		}
+	}
+
+	if flowCookie := o.getFlowCookieConfig(); flowCookie != nil {
+		if err := flowCookie.Validate(); err != nil {
+			return fmt.Errorf("invalid flowCookie: %s", err)
+		}
+	}
+
 	if o.IsEnableCNI() {
 		ns := os.Getenv(constants.NamespaceNameENV)
 		if ns == "" {
@@ -186,13 +383,131 @@ func (o *Options) cniConfigCheck() error {
 	return nil
 }
 
+func (o *Options) getMaxRulesPerPolicy() int {
+	if o.Config.MaxRulesPerPolicy == 0 {
+		return policy.DefaultMaxRulesPerPolicy
+	}
+	return o.Config.MaxRulesPerPolicy
+}
+
+func (o *Options) getPriorityBands() map[string]policy.PriorityBand {
+	if len(o.Config.PriorityBands) == 0 {
+		return nil
+	}
+	priorityBands := make(map[string]policy.PriorityBand, len(o.Config.PriorityBands))
+	for namespace, band := range o.Config.PriorityBands {
+		priorityBands[namespace] = policy.PriorityBand{Min: band.Min, Max: band.Max}
+	}
+	return priorityBands
+}
+
+func (o *Options) getPolicyTiers() datapath.PolicyTiers {
+	if len(o.Config.PolicyTiers) == 0 {
+		return nil
+	}
+	policyTiers := make(datapath.PolicyTiers, 0, len(o.Config.PolicyTiers))
+	for _, tier := range o.Config.PolicyTiers {
+		if builtin, ok := datapath.BuiltinPolicyTier(tier.Name); ok {
+			policyTiers = append(policyTiers, builtin)
+			continue
+		}
+		policyTiers = append(policyTiers, datapath.PolicyTier{
+			Name:         tier.Name,
+			ID:           tier.ID,
+			EgressTable:  tier.EgressTable,
+			IngressTable: tier.IngressTable,
+		})
+	}
+	return policyTiers
+}
+
+func (o *Options) getFlowCompactionConfig() *policy.FlowCompactionConfig {
+	flowCompaction := o.Config.FlowCompaction
+	if flowCompaction == nil {
+		return nil
+	}
+	return &policy.FlowCompactionConfig{
+		Interval:      time.Duration(flowCompaction.IntervalSeconds) * time.Second,
+		LoadThreshold: flowCompaction.LoadThreshold,
+	}
+}
+
+func (o *Options) getFlowCookieConfig() *datapath.FlowCookieConfig {
+	flowCookie := o.Config.FlowCookie
+	if flowCookie == nil {
+		return nil
+	}
+	return &datapath.FlowCookieConfig{
+		RoundNumBits: flowCookie.RoundNumBits,
+		FlowSeqBits:  flowCookie.FlowSeqBits,
+		MaxRoundNum:  flowCookie.MaxRoundNum,
+	}
+}
+
+func (o *Options) getRPCTCPConfig() *rpcserver.TCPConfig {
+	rpcTCP := o.Config.RPCTCP
+	if rpcTCP == nil {
+		return nil
+	}
+	return &rpcserver.TCPConfig{
+		Addr:     rpcTCP.Addr,
+		CertFile: rpcTCP.CertFile,
+		KeyFile:  rpcTCP.KeyFile,
+		CAFile:   rpcTCP.CAFile,
+
+		DisableUnixSocket: rpcTCP.DisableUnixSocket,
+	}
+}
+
 func (o *Options) getDatapathConfig() *datapath.DpManagerConfig {
 	agentConfig := o.Config
 
 	dpConfig := &datapath.DpManagerConfig{
-		InternalIPs:      agentConfig.InternalIPs,
-		EnableIPLearning: true,
-		EnableCNI:        agentConfig.EnableCNI,
+		InternalIPs:           agentConfig.InternalIPs,
+		IPLearningIgnoreCIDRs: agentConfig.IPLearningIgnoreCIDRs,
+		EnableIPLearning:      true,
+		EnableCNI:             agentConfig.EnableCNI,
+		CTTimeoutPolicy:       agentConfig.CTTimeoutPolicy,
+		CTFlushHighWaterMark:  agentConfig.CTFlushHighWaterMark,
+		DeferCTCleanInBatch:   agentConfig.DeferCTCleanInBatch,
+		MaxCTCleanDefer:       time.Duration(agentConfig.MaxCTCleanDeferSeconds) * time.Second,
+		CTZoneStrategy:        agentConfig.CTZoneStrategy,
+		VerifyRuleFlow:        agentConfig.VerifyRuleFlow,
+		FlowCookie:            o.getFlowCookieConfig(),
+		PolicyTiers:           o.getPolicyTiers(),
+		InternalIPGracePeriod: time.Duration(agentConfig.InternalIPGraceSeconds) * time.Second,
+		DecisionRecordSize:    agentConfig.DecisionRecordSize,
+		EnablePolicyLogging:   agentConfig.EnablePolicyLogging,
+
+		RuleFlowReconcileInterval: time.Duration(agentConfig.RuleFlowReconcileSeconds) * time.Second,
+	}
+
+	if rstDetect := agentConfig.TCPRSTDetect; rstDetect != nil {
+		dpConfig.TCPRSTDetect = &datapath.TCPRSTDetectConfig{
+			Threshold:   rstDetect.Threshold,
+			Window:      time.Duration(rstDetect.WindowSeconds) * time.Second,
+			DenyTimeout: time.Duration(rstDetect.DenySeconds) * time.Second,
+		}
+		for _, cidr := range rstDetect.AllowCIDRs {
+			if _, ipNet, err := net.ParseCIDR(cidr); err == nil {
+				dpConfig.TCPRSTDetect.AllowCIDRs = append(dpConfig.TCPRSTDetect.AllowCIDRs, ipNet)
+			}
+		}
+	}
+
+	if packetInLimit := agentConfig.PacketInLimit; packetInLimit != nil {
+		dpConfig.PacketInLimit = &datapath.PacketInLimitConfig{
+			Rate:          float32(packetInLimit.Rate),
+			Burst:         packetInLimit.Burst,
+			LearningRate:  datapath.DefaultLearningPacketInRate,
+			LearningBurst: datapath.DefaultLearningPacketInBurst,
+		}
+		if packetInLimit.LearningRate > 0 {
+			dpConfig.PacketInLimit.LearningRate = float32(packetInLimit.LearningRate)
+		}
+		if packetInLimit.LearningBurst > 0 {
+			dpConfig.PacketInLimit.LearningBurst = packetInLimit.LearningBurst
+		}
 	}
 
 	managedVDSMap := make(map[string]string)
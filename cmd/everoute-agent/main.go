@@ -20,6 +20,9 @@ import (
 	"context"
 	"flag"
 	"net"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	ipamv1alpha1 "github.com/everoute/ipam/api/ipam/v1alpha1"
@@ -42,6 +45,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	ctrlPool "github.com/everoute/everoute/pkg/agent/controller/ippool"
+	"github.com/everoute/everoute/pkg/agent/controller/migration"
 	"github.com/everoute/everoute/pkg/agent/controller/overlay"
 	"github.com/everoute/everoute/pkg/agent/controller/policy"
 	ctrlProxy "github.com/everoute/everoute/pkg/agent/controller/proxy"
@@ -100,6 +104,8 @@ func main() {
 	// TODO Update vds which is managed by everoute agent from datapathConfig.
 	datapathConfig := opts.getDatapathConfig()
 	datapathManager := datapath.NewDatapathManager(datapathConfig, ofportIPMonitorChan)
+	// keep rule flows of the previous round until the policy controller synced rules
+	datapathManager.WaitPolicyRulesSynced()
 	datapathManager.InitializeDatapath(stopCtx.Done())
 
 	var mgr manager.Manager
@@ -120,14 +126,19 @@ func main() {
 	if err != nil {
 		klog.Fatalf("failed to add health check handler: %s", err)
 	}
+	err = mgr.AddMetricsExtraHandler(constants.AgentMetricsPath, datapathManager.AgentMetric.Handler())
+	if err != nil {
+		klog.Fatalf("failed to add agent metrics handler: %s", err)
+	}
 
 	proxyCache, err := startManager(stopCtx, mgr, datapathManager, proxySyncChan, overlaySyncChan)
 	if err != nil {
 		klog.Fatalf("error %v when start controller manager.", err)
 	}
 
-	rpcServer := rpcserver.Initialize(datapathManager, mgr.GetClient(), opts.IsEnableCNI(), proxyCache)
+	rpcServer := rpcserver.Initialize(datapathManager, mgr.GetClient(), opts.IsEnableCNI(), proxyCache, opts.getRPCTCPConfig())
 	go rpcServer.Run(stopCtx.Done())
+	go reloadPolicyTiersOnSignal(stopCtx, datapathManager)
 
 	if err := resourceUpdate(stopCtx, mgr, datapathManager); err != nil {
 		klog.Fatalf("resource update failed when start everoute-agent, err: %v", err)
@@ -136,6 +147,36 @@ func main() {
 	<-stopCtx.Done()
 }
 
+// reloadPolicyTiersOnSignal reloads policyTiers of the agent config file on SIGHUP, the other fields of
+// the config only take effect after agent restarted.
+func reloadPolicyTiersOnSignal(ctx context.Context, datapathManager *datapath.DpManager) {
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	defer signal.Stop(hupChan)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hupChan:
+			agentConfig, err := getAgentConfig()
+			if err != nil {
+				klog.Errorf("Failed to reload policy tiers: %s", err)
+				continue
+			}
+			policyTiers := (&Options{Config: agentConfig}).getPolicyTiers()
+			if policyTiers == nil {
+				policyTiers = datapath.DefaultPolicyTiers()
+			}
+			if err := datapathManager.ReloadTierConfig(policyTiers); err != nil {
+				klog.Errorf("Failed to reload policy tiers: %s", err)
+				continue
+			}
+			klog.Infof("Reloaded policy tiers from %s", agentConfigFilePath)
+		}
+	}
+}
+
 func initCNI(datapathManager *datapath.DpManager, mgr manager.Manager, proxySyncChan chan event.GenericEvent, overlaySyncChan chan event.GenericEvent) {
 	if opts.IsEnableOverlay() {
 		overlayReplayFunc := func() {
@@ -194,6 +235,10 @@ func startMonitor(datapathManager *datapath.DpManager, config *rest.Config, ofpo
 	ovsdbMonitor.RegisterOvsdbEventHandler(monitor.OvsdbEventHandlerFuncs{
 		LocalEndpointAddFunc: func(endpoint *datapath.Endpoint) {
 			err := datapathManager.AddLocalEndpoint(endpoint)
+			if datapath.IsUnmanagedBridgeError(err) {
+				klog.Warningf("Skip local endpoint: %v", err)
+				return
+			}
 			if err != nil {
 				klog.Errorf("Failed to add local endpoint: %+v, error: %+v", endpoint, err)
 			}
@@ -228,14 +273,29 @@ func startManager(ctx context.Context, mgr manager.Manager, datapathManager *dat
 	overlaySyncChan chan event.GenericEvent) (*ctrlProxy.Cache, error) {
 	var err error
 	// Policy controller: watch policy related resource and update
-	if err = (&policy.Reconciler{
+	policyReconciler := &policy.Reconciler{
 		Client:          mgr.GetClient(),
 		Scheme:          mgr.GetScheme(),
 		DatapathManager: datapathManager,
-	}).SetupWithManager(mgr); err != nil {
+		FlowCompaction:  opts.getFlowCompactionConfig(),
+		EverouteIPAM:    opts.UseEverouteIPAM(),
+		PriorityBands:   opts.getPriorityBands(),
+	}
+	policyReconciler.SetMaxRulesPerPolicy(opts.getMaxRulesPerPolicy())
+	if err = policyReconciler.SetupWithManager(mgr); err != nil {
 		klog.Fatalf("unable to create policy controller: %s", err.Error())
 	}
 
+	if opts.Config.CleanupMigratedEndpoint {
+		if err = (&migration.Reconciler{
+			Client:          mgr.GetClient(),
+			DatapathManager: datapathManager,
+			LocalNode:       utils.CurrentAgentName(),
+		}).SetupWithManager(mgr); err != nil {
+			klog.Fatalf("unable to create migration controller: %s", err.Error())
+		}
+	}
+
 	var proxyCache *ctrlProxy.Cache
 	if opts.IsEnableCNI() {
 		if opts.IsEnableOverlay() {
@@ -3,8 +3,10 @@ package main
 import (
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/url"
 	"os"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 	"k8s.io/klog"
@@ -21,6 +23,10 @@ type Options struct {
 	namespace            string
 	serverPort           int
 
+	groupCardinalityWarnThreshold int
+	clusterPodCIDR                string
+	customPolicyTiers             string
+
 	Config *controllerConfig
 }
 
@@ -77,6 +83,21 @@ func (o *Options) useEverouteIPAM() bool {
 	return o.Config.CNIConf.IPAM == constants.EverouteIPAM
 }
 
+func (o *Options) getClusterPodCIDR() *net.IPNet {
+	if o.clusterPodCIDR == "" {
+		return nil
+	}
+	_, cidr, _ := net.ParseCIDR(o.clusterPodCIDR)
+	return cidr
+}
+
+func (o *Options) getCustomPolicyTiers() []string {
+	if o.customPolicyTiers == "" {
+		return nil
+	}
+	return strings.Split(o.customPolicyTiers, ",")
+}
+
 func (o *Options) getIPAMCleanPeriod() int {
 	if !o.useEverouteIPAM() {
 		return 0
@@ -106,6 +127,12 @@ func (o *Options) complete() error {
 		}
 	}
 
+	if o.clusterPodCIDR != "" {
+		if _, _, err := net.ParseCIDR(o.clusterPodCIDR); err != nil {
+			return fmt.Errorf("can't set invalid cluster pod cidr %s, err: %s", o.clusterPodCIDR, err)
+		}
+	}
+
 	return o.cniConfigCheck()
 }
 
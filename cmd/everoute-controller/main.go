@@ -82,6 +82,12 @@ func main() {
 	flag.StringVar(&opts.tlsCertDir, "tls-certs-dir", "/etc/ssl/certs", "The certs dir for everoute webhook use.")
 	flag.StringVar(&opts.namespace, "namespace", "", "The namespace which everoute deploy in.")
 	flag.IntVar(&opts.serverPort, "port", 9443, "The port for the Everoute controller to serve on.")
+	flag.IntVar(&opts.groupCardinalityWarnThreshold, "group-cardinality-warn-threshold", 0,
+		"Warn when the number of endpoints matched an endpointgroup exceeds the threshold, 0 means never warn.")
+	flag.StringVar(&opts.clusterPodCIDR, "cluster-pod-cidr", "",
+		"Warn when ipBlock peer of securityPolicy overlaps the cluster pod cidr, empty means never warn.")
+	flag.StringVar(&opts.customPolicyTiers, "custom-policy-tiers", "",
+		"Comma separated custom tiers configured in policyTiers of agents, securityPolicy could attach to them besides the builtin tiers.")
 
 	klog.InitFlags(nil)
 	towerplugin.InitFlags(&towerPluginOptions, nil, "plugins.tower.")
@@ -135,8 +141,9 @@ func main() {
 
 	// group controller sync & manager group members.
 	if err = (&groupctrl.GroupReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:                   mgr.GetClient(),
+		Scheme:                   mgr.GetScheme(),
+		CardinalityWarnThreshold: opts.groupCardinalityWarnThreshold,
 	}).SetupWithManager(mgr); err != nil {
 		klog.Fatalf("unable to create group controller: %s", err.Error())
 	}
@@ -203,7 +210,9 @@ func main() {
 
 	// register validate handle
 	if err = (&webhook.ValidateWebhook{
-		Scheme: mgr.GetScheme(),
+		Scheme:            mgr.GetScheme(),
+		ClusterPodCIDR:    opts.getClusterPodCIDR(),
+		CustomPolicyTiers: opts.getCustomPolicyTiers(),
 	}).SetupWithManager(mgr); err != nil {
 		klog.Fatalf("unable to create crd validate webhook %s", err.Error())
 	}
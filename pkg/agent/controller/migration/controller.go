@@ -0,0 +1,144 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/everoute/everoute/pkg/apis/security/v1alpha1"
+)
+
+// EndpointMigrator removes and restores flows of the local endpoints migrated between agents.
+type EndpointMigrator interface {
+	RemoveMigratedLocalEndpoint(mac string, ips []net.IP) ([]string, error)
+	RestoreMigratedLocalEndpoint(mac string) ([]string, error)
+}
+
+// Reconciler removes flows of local endpoints which have been migrated to other agents. An endpoint is
+// migrated when its agents changed from containing the local agent to not, the local flows of it could be
+// stale if the ovsdb interface of it hasn't been removed on the local agent. The flows are restored when
+// the endpoint migrated back to the local agent.
+type Reconciler struct {
+	client.Client
+	DatapathManager EndpointMigrator
+	LocalNode       string
+}
+
+// nolint
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ep := v1alpha1.Endpoint{}
+	if err := r.Get(ctx, req.NamespacedName, &ep); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if ep.Status.MacAddress == "" {
+		return ctrl.Result{}, nil
+	}
+
+	if locatedOn(&ep, r.LocalNode) {
+		restored, err := r.DatapathManager.RestoreMigratedLocalEndpoint(ep.Status.MacAddress)
+		if err != nil {
+			klog.Errorf("Failed to restore local endpoint of %v migrated back to local agent: %s", req.NamespacedName, err)
+			return ctrl.Result{}, err
+		}
+		if len(restored) != 0 {
+			klog.Infof("Restored local endpoints %v of %v migrated back to local agent", restored, req.NamespacedName)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if len(ep.Status.Agents) == 0 {
+		return ctrl.Result{}, nil
+	}
+	var ips []net.IP
+	for _, ip := range ep.Status.IPs {
+		if epIP := net.ParseIP(ip.String()); epIP != nil {
+			ips = append(ips, epIP)
+		}
+	}
+	removed, err := r.DatapathManager.RemoveMigratedLocalEndpoint(ep.Status.MacAddress, ips)
+	if err != nil {
+		klog.Errorf("Failed to remove local endpoint of %v migrated to agents %v: %s", req.NamespacedName, ep.Status.Agents, err)
+		return ctrl.Result{}, err
+	}
+	if len(removed) != 0 {
+		klog.Infof("Removed local endpoints %v of %v migrated to agents %v", removed, req.NamespacedName, ep.Status.Agents)
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if mgr == nil {
+		return fmt.Errorf("can't setup with nil manager")
+	}
+	if r.LocalNode == "" {
+		return fmt.Errorf("can't setup without set param localNode")
+	}
+	if r.DatapathManager == nil {
+		return fmt.Errorf("can't setup without datapath manager")
+	}
+
+	c, err := controller.New("migration controller", mgr, controller.Options{
+		Reconciler: r,
+	})
+	if err != nil {
+		return err
+	}
+	return c.Watch(source.Kind(mgr.GetCache(), &v1alpha1.Endpoint{}), &handler.EnqueueRequestForObject{}, endpointPredicate(r.LocalNode))
+}
+
+// endpointPredicate only handles endpoints migrated between the local agent and other agents, the
+// endpoints never located on the local agent would be filtered out.
+func endpointPredicate(localNode string) predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(event.CreateEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldEP, ok := e.ObjectOld.(*v1alpha1.Endpoint)
+			if !ok {
+				return false
+			}
+			newEP, ok := e.ObjectNew.(*v1alpha1.Endpoint)
+			if !ok {
+				return false
+			}
+			return migratedAway(oldEP, newEP, localNode) || migratedBack(oldEP, newEP, localNode)
+		},
+		DeleteFunc: func(event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+// migratedAway returns true if the endpoint was located on the local agent, and now located on other agents only
+func migratedAway(oldEP, newEP *v1alpha1.Endpoint, localNode string) bool {
+	if newEP.Status.MacAddress == "" || len(newEP.Status.IPs) == 0 || len(newEP.Status.Agents) == 0 {
+		return false
+	}
+	return locatedOn(oldEP, localNode) && !locatedOn(newEP, localNode)
+}
+
+// migratedBack returns true if the endpoint wasn't located on the local agent, and now located on it
+func migratedBack(oldEP, newEP *v1alpha1.Endpoint, localNode string) bool {
+	if newEP.Status.MacAddress == "" {
+		return false
+	}
+	return !locatedOn(oldEP, localNode) && locatedOn(newEP, localNode)
+}
+
+func locatedOn(ep *v1alpha1.Endpoint, node string) bool {
+	return sets.New(ep.Status.Agents...).Has(node)
+}
@@ -0,0 +1,133 @@
+package migration
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/everoute/everoute/pkg/apis/security/v1alpha1"
+	"github.com/everoute/everoute/pkg/client/clientset_generated/clientset/scheme"
+	"github.com/everoute/everoute/pkg/types"
+)
+
+const epMac = "00:00:aa:aa:aa:01"
+
+func newEndpoint(mac string, ips []types.IPAddress, agents ...string) *v1alpha1.Endpoint {
+	return &v1alpha1.Endpoint{
+		ObjectMeta: metav1.ObjectMeta{Name: "ep1", Namespace: "default"},
+		Status:     v1alpha1.EndpointStatus{MacAddress: mac, IPs: ips, Agents: agents},
+	}
+}
+
+type fakeMigrator struct {
+	removed  map[string][]net.IP
+	restored []string
+}
+
+func (m *fakeMigrator) RemoveMigratedLocalEndpoint(mac string, ips []net.IP) ([]string, error) {
+	if m.removed == nil {
+		m.removed = make(map[string][]net.IP)
+	}
+	m.removed[mac] = ips
+	return []string{mac}, nil
+}
+
+func (m *fakeMigrator) RestoreMigratedLocalEndpoint(mac string) ([]string, error) {
+	m.restored = append(m.restored, mac)
+	return []string{mac}, nil
+}
+
+func TestMigratedAway(t *testing.T) {
+	ips := []types.IPAddress{"10.0.0.1"}
+	tests := []struct {
+		name   string
+		oldEP  *v1alpha1.Endpoint
+		newEP  *v1alpha1.Endpoint
+		expect bool
+	}{
+		{name: "located on local agent", oldEP: newEndpoint(epMac, ips, "node1"), newEP: newEndpoint(epMac, ips, "node1"), expect: false},
+		{name: "migrating from local agent", oldEP: newEndpoint(epMac, ips, "node1"), newEP: newEndpoint(epMac, ips, "node1", "node2"), expect: false},
+		{name: "migrated to other agents", oldEP: newEndpoint(epMac, ips, "node1"), newEP: newEndpoint(epMac, ips, "node2"), expect: true},
+		{name: "migrated between other agents", oldEP: newEndpoint(epMac, ips, "node3"), newEP: newEndpoint(epMac, ips, "node2"), expect: false},
+		{name: "always located on other agents", oldEP: newEndpoint(epMac, ips, "node2"), newEP: newEndpoint(epMac, ips, "node2"), expect: false},
+		{name: "without agents", oldEP: newEndpoint(epMac, ips, "node1"), newEP: newEndpoint(epMac, ips), expect: false},
+		{name: "without ips", oldEP: newEndpoint(epMac, nil, "node1"), newEP: newEndpoint(epMac, nil, "node2"), expect: false},
+		{name: "without mac", oldEP: newEndpoint("", ips, "node1"), newEP: newEndpoint("", ips, "node2"), expect: false},
+	}
+	for _, item := range tests {
+		if res := migratedAway(item.oldEP, item.newEP, "node1"); res != item.expect {
+			t.Errorf("test %s failed, expect %t, real %t", item.name, item.expect, res)
+		}
+	}
+}
+
+func TestEndpointPredicate(t *testing.T) {
+	p := endpointPredicate("node1")
+	ips := []types.IPAddress{"10.0.0.1"}
+	local := newEndpoint(epMac, ips, "node1")
+	remote := newEndpoint(epMac, ips, "node2")
+	otherRemote := newEndpoint(epMac, ips, "node3")
+
+	if p.Create(event.CreateEvent{Object: remote}) {
+		t.Errorf("should skip create event")
+	}
+	if !p.Update(event.UpdateEvent{ObjectOld: local, ObjectNew: remote}) {
+		t.Errorf("should handle endpoint migrated from local agent")
+	}
+	if !p.Update(event.UpdateEvent{ObjectOld: remote, ObjectNew: local}) {
+		t.Errorf("should handle endpoint migrated back to local agent")
+	}
+	if p.Update(event.UpdateEvent{ObjectOld: remote, ObjectNew: otherRemote}) {
+		t.Errorf("should skip endpoint migrated between other agents")
+	}
+	if p.Update(event.UpdateEvent{ObjectOld: remote, ObjectNew: remote}) {
+		t.Errorf("should skip endpoint update on other agents")
+	}
+	if p.Delete(event.DeleteEvent{Object: remote}) {
+		t.Errorf("should skip delete event")
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	ips := []types.IPAddress{"10.0.0.1", "fe80::1"}
+	req := ctrl.Request{NamespacedName: k8stypes.NamespacedName{Namespace: "default", Name: "ep1"}}
+	reconcile := func(ep *v1alpha1.Endpoint) *fakeMigrator {
+		migrator := &fakeMigrator{}
+		r := &Reconciler{
+			Client:          fakeclient.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(ep).Build(),
+			DatapathManager: migrator,
+			LocalNode:       "node1",
+		}
+		if _, err := r.Reconcile(context.Background(), req); err != nil {
+			t.Fatalf("unexpected reconcile error: %s", err)
+		}
+		return migrator
+	}
+
+	t.Run("should remove endpoint migrated to other agents", func(t *testing.T) {
+		migrator := reconcile(newEndpoint(epMac, ips, "node2"))
+		if len(migrator.removed[epMac]) != 2 || len(migrator.restored) != 0 {
+			t.Errorf("expect endpoint with ips removed, removed %v, restored %v", migrator.removed, migrator.restored)
+		}
+	})
+
+	t.Run("should restore endpoint migrated back to local agent", func(t *testing.T) {
+		migrator := reconcile(newEndpoint(epMac, ips, "node1"))
+		if len(migrator.removed) != 0 || len(migrator.restored) != 1 {
+			t.Errorf("expect endpoint restored, removed %v, restored %v", migrator.removed, migrator.restored)
+		}
+	})
+
+	t.Run("should skip endpoint without mac", func(t *testing.T) {
+		migrator := reconcile(newEndpoint("", ips, "node2"))
+		if len(migrator.removed) != 0 || len(migrator.restored) != 0 {
+			t.Errorf("expect endpoint skipped, removed %v, restored %v", migrator.removed, migrator.restored)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+	"net/netip"
+
+	"github.com/everoute/everoute/pkg/utils"
+)
+
+// compactRuleList merges rules only differ in src or dst ip into rules with minimal cidrs,
+// the compacted rule list matches the same packets with fewer flows.
+func (rule *CompleteRule) compactRuleList(ruleList []PolicyRule) []PolicyRule {
+	ruleList = compactRuleIPs(ruleList, func(r *PolicyRule) *string { return &r.SrcIPAddr })
+	ruleList = compactRuleIPs(ruleList, func(r *PolicyRule) *string { return &r.DstIPAddr })
+
+	for i := range ruleList {
+		ruleList[i].Name = fmt.Sprintf("%s-%s", rule.RuleID, GenerateFlowKey(ruleList[i]))
+	}
+	return ruleList
+}
+
+// compactRuleIPs groups rules by all fields except the ip returned by ipOf, and aggregates ips of each group
+func compactRuleIPs(ruleList []PolicyRule, ipOf func(*PolicyRule) *string) []PolicyRule {
+	var (
+		compacted []PolicyRule
+		keys      []string
+		templates = make(map[string]PolicyRule)
+		prefixes  = make(map[string][]netip.Prefix)
+		origins   = make(map[string]map[netip.Prefix]string)
+	)
+
+	for _, policyRule := range ruleList {
+		prefix, ok := parseIPPrefix(*ipOf(&policyRule))
+		if !ok {
+			// rule matches any ip or with unknown ip format could not be merged
+			compacted = append(compacted, policyRule)
+			continue
+		}
+
+		template := policyRule
+		template.Name = ""
+		*ipOf(&template) = ""
+		key := fmt.Sprintf("%s/%s", template.Action, GenerateFlowKey(template))
+
+		if _, ok := templates[key]; !ok {
+			keys = append(keys, key)
+			templates[key] = template
+			origins[key] = make(map[netip.Prefix]string)
+		}
+		prefixes[key] = append(prefixes[key], prefix)
+		origins[key][prefix] = *ipOf(&policyRule)
+	}
+
+	for _, key := range keys {
+		for _, prefix := range utils.MergeIPPrefixes(prefixes[key]) {
+			policyRule := templates[key]
+			// keep the origin ip format if the prefix not changed, avoid unnecessary flow replacement
+			if ip, ok := origins[key][prefix]; ok {
+				*ipOf(&policyRule) = ip
+			} else {
+				*ipOf(&policyRule) = prefix.String()
+			}
+			compacted = append(compacted, policyRule)
+		}
+	}
+
+	return compacted
+}
+
+// parseIPPrefix parse ip or cidr into prefix, ip is treated as host prefix
+func parseIPPrefix(ip string) (netip.Prefix, bool) {
+	if ip == "" {
+		return netip.Prefix{}, false
+	}
+	if addr, err := netip.ParseAddr(ip); err == nil {
+		addr = addr.Unmap()
+		return netip.PrefixFrom(addr, addr.BitLen()), true
+	}
+	prefix, err := netip.ParsePrefix(ip)
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+	if prefix.Addr().Is4In6() {
+		return netip.Prefix{}, false
+	}
+	return prefix.Masked(), true
+}
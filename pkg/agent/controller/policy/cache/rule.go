@@ -41,8 +41,9 @@ const (
 	RuleTypeDefaultRule       RuleType = "DefaultRule"
 	RuleTypeNormalRule        RuleType = "NormalRule"
 
-	RuleActionAllow RuleAction = "Allow"
-	RuleActionDrop  RuleAction = "Drop"
+	RuleActionAllow  RuleAction = "Allow"
+	RuleActionDrop   RuleAction = "Drop"
+	RuleActionReject RuleAction = "Reject"
 
 	RuleDirectionIn  RuleDirection = "Ingress"
 	RuleDirectionOut RuleDirection = "Egress"
@@ -63,6 +64,8 @@ type PolicyRule struct {
 	Tier            string        `json:"tier,omitempty"`
 	PriorityOffset  int32         `json:"priorityOffset,omitempty"`
 	EnforcementMode string        `json:"enforcementMode,omitempty"`
+	TrafficLocality string        `json:"trafficLocality,omitempty"`
+	VlanID          uint16        `json:"vlanID,omitempty"`
 	SrcIPAddr       string        `json:"srcIPAddr,omitempty"`
 	DstIPAddr       string        `json:"dstIPAddr,omitempty"`
 	IPProtocol      string        `json:"ipProtocol"`
@@ -70,6 +73,19 @@ type PolicyRule struct {
 	DstPort         uint16        `json:"dstPort,omitempty"`
 	SrcPortMask     uint16        `json:"srcPortMask,omitempty"`
 	DstPortMask     uint16        `json:"dstPortMask,omitempty"`
+	ICMPType        *uint8        `json:"icmpType,omitempty"`
+	ICMPCode        *uint8        `json:"icmpCode,omitempty"`
+
+	// LoggingTags is the logging tags of the rule, it doesn't affect the flow
+	LoggingTags map[string]string `json:"loggingTags,omitempty"`
+	// RuleGroup is the group the rule belongs to, rules in a group are enabled or disabled together
+	RuleGroup string `json:"ruleGroup,omitempty"`
+	// BlockARP denies ARP and ND of the endpoint the rule applied to, it doesn't affect the flow
+	BlockARP bool `json:"blockARP,omitempty"`
+	// Logged logs packets matched the rule, it doesn't affect the flow
+	Logged bool `json:"logged,omitempty"`
+	// LogSampleRate is the percentage of logged connections matched the rule, they are sampled in datapath
+	LogSampleRate int32 `json:"logSampleRate,omitempty"`
 }
 
 type DeepCopyBase interface {
@@ -109,6 +125,8 @@ type CompleteRule struct {
 	Tier            string
 	Priority        int32
 	EnforcementMode string
+	TrafficLocality string
+	VlanID          uint16 // only match packets tagged with the vlan, 0 matches all vlans
 	Action          RuleAction
 	Direction       RuleDirection
 
@@ -118,6 +136,24 @@ type CompleteRule struct {
 	// DefaultPolicyRule is true when the it's the default egress or ingress rule in policy.
 	DefaultPolicyRule bool
 
+	// Compact merges generated rules only differ in src or dst ip into rules with minimal cidrs.
+	Compact bool
+
+	// LoggingTags is the logging tags of the policy or the rule, e.g. policy id, name and type.
+	LoggingTags map[string]string
+
+	// RuleGroup is the rule group of the policy, empty if the policy doesn't belong to any group.
+	RuleGroup string
+
+	// BlockARP denies ARP and ND of the applied endpoints, only set in default rules of the policy.
+	BlockARP bool
+
+	// Logged is true when packets matched the rule should be logged for the rule action.
+	Logged bool
+
+	// LogSampleRate is the percentage of connections matched the rule should be logged for the rule action.
+	LogSampleRate int32
+
 	// SrcGroups is a groupName sets
 	SrcGroups sets.Set[string]
 	DstGroups sets.Set[string]
@@ -151,6 +187,11 @@ type RulePort struct {
 
 	// Protocol should set "" if want match all protocol.
 	Protocol securityv1alpha1.Protocol
+
+	// ICMPType is icmp type when Protocol is ICMP, nil matches all icmp types.
+	ICMPType *uint8
+	// ICMPCode is icmp code when ICMPType set, nil matches all icmp codes.
+	ICMPCode *uint8
 }
 
 func (rule *CompleteRule) Clone() *CompleteRule {
@@ -165,10 +206,18 @@ func (rule *CompleteRule) Clone() *CompleteRule {
 		Tier:              rule.Tier,
 		Priority:          rule.Priority,
 		EnforcementMode:   rule.EnforcementMode,
+		TrafficLocality:   rule.TrafficLocality,
+		VlanID:            rule.VlanID,
 		Action:            rule.Action,
 		Direction:         rule.Direction,
 		SymmetricMode:     rule.SymmetricMode,
 		DefaultPolicyRule: rule.DefaultPolicyRule,
+		Compact:           rule.Compact,
+		LoggingTags:       rule.LoggingTags,
+		RuleGroup:         rule.RuleGroup,
+		BlockARP:          rule.BlockARP,
+		Logged:            rule.Logged,
+		LogSampleRate:     rule.LogSampleRate,
 		SrcGroups:         rule.SrcGroups.Clone(),
 		DstGroups:         rule.DstGroups.Clone(),
 		SrcIPs:            rule.SrcIPs.Clone(),
@@ -194,7 +243,16 @@ func (rule *CompleteRule) GenerateRuleList(srcIPBlocks map[string]*IPBlockItem,
 			if srcIP != "" && dstIP != "" && srcIP == dstIP {
 				continue
 			}
+			// flow couldn't match both ipv4 and ipv6 address
+			family, ok := pairIPFamily(srcIP, dstIP)
+			if !ok {
+				continue
+			}
 			for _, port := range ports {
+				port, ok := portOfIPFamily(port, family)
+				if !ok {
+					continue
+				}
 				dstPorts := []RulePort{port}
 				if port.DstPortName != "" {
 					if dstIPBlock == nil {
@@ -226,9 +284,69 @@ func (rule *CompleteRule) GenerateRuleList(srcIPBlocks map[string]*IPBlockItem,
 		}
 	}
 
+	if rule.Compact {
+		return rule.compactRuleList(policyRuleList)
+	}
 	return policyRuleList
 }
 
+type ipFamily int
+
+const (
+	ipFamilyAny ipFamily = iota
+	ipFamilyV4
+	ipFamilyV6
+)
+
+func ipBlockFamily(ipBlock string) ipFamily {
+	switch {
+	case ipBlock == "":
+		return ipFamilyAny
+	case strings.Contains(ipBlock, ":"):
+		return ipFamilyV6
+	default:
+		return ipFamilyV4
+	}
+}
+
+// pairIPFamily returns the ip family of the src and dst ip blocks, returns false if they are different families
+func pairIPFamily(srcIPBlock, dstIPBlock string) (ipFamily, bool) {
+	srcFamily, dstFamily := ipBlockFamily(srcIPBlock), ipBlockFamily(dstIPBlock)
+	switch {
+	case srcFamily == ipFamilyAny:
+		return dstFamily, true
+	case dstFamily == ipFamilyAny || srcFamily == dstFamily:
+		return srcFamily, true
+	default:
+		return ipFamilyAny, false
+	}
+}
+
+// icmpv6EchoTypes maps icmp echo types to the icmpv6 echo types
+var icmpv6EchoTypes = map[uint8]uint8{
+	0: 129, // echo reply
+	8: 128, // echo request
+}
+
+// portOfIPFamily converts ICMP port to ICMPv6 for ipv6 addresses, returns false if the port is ICMPv6 but
+// the addresses are ipv4, or the icmp type has no icmpv6 equivalent.
+func portOfIPFamily(port RulePort, family ipFamily) (RulePort, bool) {
+	switch {
+	case family == ipFamilyV6 && port.Protocol == securityv1alpha1.ProtocolICMP:
+		port.Protocol = securityv1alpha1.ProtocolICMPv6
+		if port.ICMPType != nil {
+			icmpv6Type, ok := icmpv6EchoTypes[*port.ICMPType]
+			if !ok {
+				return port, false
+			}
+			port.ICMPType = &icmpv6Type
+		}
+	case family == ipFamilyV4 && port.Protocol == securityv1alpha1.ProtocolICMPv6:
+		return port, false
+	}
+	return port, true
+}
+
 func (rule *CompleteRule) assemblySrcIPBlocks(groupCache *GroupCache) map[string]*IPBlockItem {
 	ipBlocks, err := AssembleStaticIPAndGroup(rule.SrcIPs, rule.SrcGroups, groupCache)
 	if err != nil {
@@ -269,6 +387,8 @@ func (rule *CompleteRule) generateRule(srcIPBlock, dstIPBlock string, direction
 		Tier:            rule.Tier,
 		PriorityOffset:  0,
 		EnforcementMode: rule.EnforcementMode,
+		TrafficLocality: rule.TrafficLocality,
+		VlanID:          rule.VlanID,
 		SrcIPAddr:       srcIPBlock,
 		DstIPAddr:       dstIPBlock,
 		IPProtocol:      string(port.Protocol),
@@ -276,7 +396,14 @@ func (rule *CompleteRule) generateRule(srcIPBlock, dstIPBlock string, direction
 		DstPort:         port.DstPort,
 		SrcPortMask:     port.SrcPortMask,
 		DstPortMask:     port.DstPortMask,
+		ICMPType:        port.ICMPType,
+		ICMPCode:        port.ICMPCode,
 		Action:          rule.Action,
+		LoggingTags:     rule.LoggingTags,
+		RuleGroup:       rule.RuleGroup,
+		BlockARP:        rule.BlockARP,
+		Logged:          rule.Logged,
+		LogSampleRate:   rule.LogSampleRate,
 	}
 
 	if policyRule.Tier == constants.Tier2 {
@@ -371,6 +498,15 @@ func GenerateFlowKey(rule PolicyRule) string {
 	// We consider PolicyRule with the same spec but different action as the same flow.
 	// Some we remove the action to generate FlowKey here.
 	rule.Action = ""
+	// logging tags are not match fields of the flow
+	rule.LoggingTags = nil
+	// rule group only decides whether the flow installed or not
+	rule.RuleGroup = ""
+	// arp block only decides whether arp of the endpoint denied or not
+	rule.BlockARP = false
+	// logging only decides whether packets matched the flow logged or not
+	rule.Logged = false
+	rule.LogSampleRate = 0
 	return HashName(32, rule)
 }
 
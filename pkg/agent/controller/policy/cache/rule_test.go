@@ -1,6 +1,9 @@
 package cache
 
 import (
+	"net/netip"
+	"reflect"
+	"strings"
 	"testing"
 
 	securityv1alpha1 "github.com/everoute/everoute/pkg/apis/security/v1alpha1"
@@ -247,3 +250,114 @@ func TestAppendIPBlockPorts(t *testing.T) {
 		}
 	}
 }
+
+func TestCompactRuleList(t *testing.T) {
+	srcIPBlocks := map[string]*IPBlockItem{
+		"10.0.0.8/30": nil,
+		"10.0.0.10":   nil,
+		"192.168.1.1": nil,
+	}
+	for i := 0; i < 8; i++ {
+		srcIPBlocks[netip.AddrFrom4([4]byte{10, 0, 0, byte(i)}).String()] = nil
+	}
+	dstIPBlocks := map[string]*IPBlockItem{"": nil}
+	ports := []RulePort{
+		{DstPort: 22, DstPortMask: 0xffff, Protocol: securityv1alpha1.ProtocolTCP},
+		{DstPort: 53, DstPortMask: 0xffff, Protocol: securityv1alpha1.ProtocolUDP},
+	}
+	rule := &CompleteRule{
+		RuleID:    "ns/policy/normal/ingress0",
+		Tier:      "tier2",
+		Action:    RuleActionAllow,
+		Direction: RuleDirectionIn,
+	}
+
+	fragmented := rule.GenerateRuleList(srcIPBlocks, dstIPBlocks, ports)
+	rule.Compact = true
+	compacted := rule.GenerateRuleList(srcIPBlocks, dstIPBlocks, ports)
+
+	if len(fragmented) != 22 || len(compacted) != 6 {
+		t.Fatalf("expect compact 22 rules to 6 rules, got %d rules compact to %d rules", len(fragmented), len(compacted))
+	}
+
+	matchedSrc := func(ruleList []PolicyRule, port RulePort) map[string]bool {
+		matched := make(map[string]bool)
+		for _, policyRule := range ruleList {
+			if policyRule.DstPort != port.DstPort || policyRule.IPProtocol != string(port.Protocol) {
+				continue
+			}
+			if !strings.HasPrefix(policyRule.Name, rule.RuleID+"-") || policyRule.Name != rule.RuleID+"-"+GenerateFlowKey(policyRule) {
+				t.Errorf("unexpected rule name %s", policyRule.Name)
+			}
+			prefix, ok := parseIPPrefix(policyRule.SrcIPAddr)
+			if !ok {
+				t.Fatalf("unexpected rule src %s", policyRule.SrcIPAddr)
+			}
+			for addr := prefix.Addr(); prefix.Contains(addr); addr = addr.Next() {
+				matched[addr.String()] = true
+			}
+		}
+		return matched
+	}
+	for _, port := range ports {
+		if expect, res := matchedSrc(fragmented, port), matchedSrc(compacted, port); !reflect.DeepEqual(expect, res) {
+			t.Errorf("compacted rules match %v, expect %v", res, expect)
+		}
+	}
+
+	for _, policyRule := range compacted {
+		if policyRule.SrcIPAddr == "192.168.1.1/32" {
+			t.Errorf("src ip of rule not be merged should keep origin format")
+		}
+	}
+}
+
+func TestGenerateRuleListIPFamily(t *testing.T) {
+	echoRequest := uint8(8)
+	srcIPBlocks := map[string]*IPBlockItem{"10.0.0.1": nil, "fe80::1": nil}
+	dstIPBlocks := map[string]*IPBlockItem{"10.0.0.2": nil, "fe80::2": nil, "": nil}
+	ports := []RulePort{
+		{Protocol: securityv1alpha1.ProtocolICMP, ICMPType: &echoRequest},
+		{Protocol: securityv1alpha1.ProtocolICMPv6},
+	}
+	rule := &CompleteRule{
+		RuleID:    "ns/policy/normal/ingress0",
+		Tier:      "tier2",
+		Action:    RuleActionAllow,
+		Direction: RuleDirectionIn,
+	}
+
+	type ruleMatch struct {
+		src, dst, protocol string
+		icmpType           uint8
+	}
+	var res []ruleMatch
+	for _, policyRule := range rule.GenerateRuleList(srcIPBlocks, dstIPBlocks, ports) {
+		var icmpType uint8
+		if policyRule.ICMPType != nil {
+			icmpType = *policyRule.ICMPType
+		}
+		res = append(res, ruleMatch{policyRule.SrcIPAddr, policyRule.DstIPAddr, policyRule.IPProtocol, icmpType})
+	}
+
+	expect := []ruleMatch{
+		{"10.0.0.1", "10.0.0.2", "ICMP", 8},
+		{"10.0.0.1", "", "ICMP", 8},
+		{"fe80::1", "fe80::2", "ICMPv6", 128},
+		{"fe80::1", "fe80::2", "ICMPv6", 0},
+		{"fe80::1", "", "ICMPv6", 128},
+		{"fe80::1", "", "ICMPv6", 0},
+	}
+	if len(res) != len(expect) {
+		t.Fatalf("expect rules %v, got %v", expect, res)
+	}
+	for _, item := range expect {
+		found := false
+		for _, r := range res {
+			found = found || r == item
+		}
+		if !found {
+			t.Errorf("expect rule %v in rules %v", item, res)
+		}
+	}
+}
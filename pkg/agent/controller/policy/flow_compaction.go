@@ -0,0 +1,81 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog"
+
+	policycache "github.com/everoute/everoute/pkg/agent/controller/policy/cache"
+	"github.com/everoute/everoute/pkg/agent/datapath"
+)
+
+// FlowCompactionConfig configs compaction of policy rule flows. Every Interval, if policy and groupmembers
+// reconciled in the interval no more than LoadThreshold, flows of rules would be replaced by a minimal set.
+type FlowCompactionConfig struct {
+	Interval      time.Duration
+	LoadThreshold int
+}
+
+// runFlowCompaction compacts policy rule flows in low-activity windows until ctx done
+func (r *Reconciler) runFlowCompaction(ctx context.Context) {
+	wait.UntilWithContext(ctx, func(context.Context) {
+		r.compactRuleFlowsIfIdle()
+	}, r.FlowCompaction.Interval)
+}
+
+// compactRuleFlowsIfIdle compacts policy rule flows if policy and groupmembers reconciled since the last check
+// no more than LoadThreshold, returns true if compacted.
+func (r *Reconciler) compactRuleFlowsIfIdle() bool {
+	load := r.reconcileCount.Swap(0)
+	if load > int64(r.FlowCompaction.LoadThreshold) {
+		klog.V(4).Infof("skip policy flow compaction, %d reconciles in last %s", load, r.FlowCompaction.Interval)
+		return false
+	}
+	r.compactRuleFlows()
+	return true
+}
+
+// compactRuleFlows recomputes flows of uncompacted rules into a minimal set, new flows are installed before
+// the old flows removed, so rules always take effect during the replacement. The compacted flows match the
+// same packets with the same action, conntrack wouldn't be cleaned for the replacement.
+func (r *Reconciler) compactRuleFlows() {
+	r.reconcilerLock.Lock()
+	defer r.reconcilerLock.Unlock()
+
+	ctx := datapath.WithoutConntrackClean(context.Background())
+	for _, obj := range r.ruleCache.List() {
+		rule := obj.(*policycache.CompleteRule)
+		if rule.Compact {
+			continue
+		}
+
+		compactedRule := rule.Clone()
+		compactedRule.Compact = true
+		oldRuleList := rule.ListRules(r.groupCache)
+		newRuleList := compactedRule.ListRules(r.groupCache)
+		// rule list keeps the same when nothing could be merged, only mark the rule compacted
+		if len(newRuleList) < len(oldRuleList) {
+			klog.Infof("compact rule %s flows from %d to %d", rule.RuleID, len(oldRuleList), len(newRuleList))
+			r.syncPolicyRulesWithContextUntilSuccess(ctx, oldRuleList, newRuleList)
+		}
+		_ = r.ruleCache.Update(compactedRule)
+	}
+}
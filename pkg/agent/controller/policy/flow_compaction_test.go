@@ -0,0 +1,91 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	policycache "github.com/everoute/everoute/pkg/agent/controller/policy/cache"
+	"github.com/everoute/everoute/pkg/agent/datapath"
+	securityv1alpha1 "github.com/everoute/everoute/pkg/apis/security/v1alpha1"
+	"github.com/everoute/everoute/pkg/constants"
+)
+
+func TestFlowCompaction(t *testing.T) {
+	RegisterTestingT(t)
+
+	r := &Reconciler{
+		DatapathManager: datapath.NewDatapathManager(&datapath.DpManagerConfig{ManagedVDSMap: map[string]string{}}, nil),
+		ruleCache:       policycache.NewCompleteRuleCache(),
+		groupCache:      policycache.NewGroupCache(),
+		ifaceNameCache:  newLocalEndpointCache(fakeIfaceIPs{"veth1": "10.0.0.100/32"}.resolve),
+		FlowCompaction:  &FlowCompactionConfig{Interval: time.Minute, LoadThreshold: 1},
+	}
+	policy := &securityv1alpha1.SecurityPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "compact-policy"},
+		Spec: securityv1alpha1.SecurityPolicySpec{
+			Tier:        constants.Tier2,
+			AppliedTo:   []securityv1alpha1.ApplyToPeer{{InterfaceName: pointer.String("veth*")}},
+			DefaultRule: securityv1alpha1.DefaultRuleNone,
+		},
+	}
+	rule := securityv1alpha1.Rule{Name: "ingress"}
+	for i := 0; i < 4; i++ {
+		rule.From = append(rule.From, securityv1alpha1.SecurityPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: fmt.Sprintf("10.0.0.%d/32", i)}})
+	}
+	policy.Spec.IngressRules = []securityv1alpha1.Rule{rule}
+
+	ruleList, err := r.calculateExpectedPolicyRules(policy)
+	Expect(err).ShouldNot(HaveOccurred())
+	r.syncPolicyRulesUntilSuccess(nil, ruleList)
+	Expect(r.DatapathManager.Rules).Should(HaveLen(4))
+
+	t.Run("should not compact when reconciles exceed load threshold", func(t *testing.T) {
+		r.reconcileCount.Store(2)
+		Expect(r.compactRuleFlowsIfIdle()).Should(BeFalse())
+		Expect(r.DatapathManager.Rules).Should(HaveLen(4))
+	})
+
+	t.Run("should compact in the low-activity window", func(t *testing.T) {
+		r.reconcileCount.Add(1)
+		Expect(r.compactRuleFlowsIfIdle()).Should(BeTrue())
+		Expect(r.DatapathManager.Rules).Should(HaveLen(1))
+		for _, entry := range r.DatapathManager.Rules {
+			Expect(entry.EveroutePolicyRule.SrcIPAddr).Should(Equal("10.0.0.0/30"))
+		}
+	})
+
+	t.Run("should keep rules compacted on policy sync", func(t *testing.T) {
+		var oldRuleList []policycache.PolicyRule
+		completeRules, _ := r.ruleCache.ByIndex(policycache.PolicyIndex, policy.Namespace+"/"+policy.Name)
+		for _, completeRule := range completeRules {
+			oldRuleList = append(oldRuleList, completeRule.(*policycache.CompleteRule).ListRules(r.groupCache)...)
+		}
+		newRuleList, err := r.calculateExpectedPolicyRules(policy)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(newRuleList).Should(Equal(oldRuleList))
+		r.syncPolicyRulesUntilSuccess(oldRuleList, newRuleList)
+		Expect(r.DatapathManager.Rules).Should(HaveLen(1))
+	})
+}
@@ -0,0 +1,399 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog"
+	"k8s.io/utils/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	securityv1alpha1 "github.com/everoute/everoute/pkg/apis/security/v1alpha1"
+)
+
+const (
+	// DefaultFQDNMinTTL is the min interval resolved addresses of fqdn refreshed, it's also used when
+	// the resolver doesn't know ttl of the records
+	DefaultFQDNMinTTL = 30 * time.Second
+
+	fqdnResolveTimeout       = 5 * time.Second
+	fqdnRefreshCheckInterval = time.Second
+
+	defaultResolvConf = "/etc/resolv.conf"
+	dnsPort           = "53"
+	dnsMaxUDPSize     = 65535
+)
+
+// FQDNResolver resolves the domain name to addresses of A and AAAA records, and the ttl of the records.
+// The ttl zero means unknown.
+type FQDNResolver interface {
+	LookupIP(ctx context.Context, fqdn string) ([]net.IP, time.Duration, error)
+}
+
+// DNSResolver resolves domain names by querying the nameservers in resolv.conf, the ttl of the records is
+// returned so that the addresses are refreshed when the records expired
+type DNSResolver struct {
+	// ResolvConf is the path of resolv.conf, defaultResolvConf when empty
+	ResolvConf string
+}
+
+func (d DNSResolver) LookupIP(ctx context.Context, fqdn string) ([]net.IP, time.Duration, error) {
+	name, err := dnsmessage.NewName(strings.TrimSuffix(fqdn, ".") + ".")
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid domain name %s: %s", fqdn, err)
+	}
+
+	var errList []error
+	for _, server := range d.nameservers() {
+		ips, ttl, err := lookupIPFrom(ctx, server, name)
+		if err == nil {
+			return ips, ttl, nil
+		}
+		errList = append(errList, fmt.Errorf("nameserver %s: %s", server, err))
+	}
+	return nil, 0, errors.NewAggregate(errList)
+}
+
+// nameservers returns addresses of the nameservers in resolv.conf, the local nameserver is used when none
+func (d DNSResolver) nameservers() []string {
+	resolvConf := d.ResolvConf
+	if resolvConf == "" {
+		resolvConf = defaultResolvConf
+	}
+	content, err := os.ReadFile(resolvConf)
+	if err != nil {
+		klog.Errorf("failed to read %s, use the local nameserver: %s", resolvConf, err)
+	}
+
+	var servers []string
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "nameserver" && net.ParseIP(fields[1]) != nil {
+			servers = append(servers, net.JoinHostPort(fields[1], dnsPort))
+		}
+	}
+	if len(servers) == 0 {
+		servers = append(servers, net.JoinHostPort("127.0.0.1", dnsPort))
+	}
+	return servers
+}
+
+// lookupIPFrom queries A and AAAA records of the name from the nameserver, the ttl is the min ttl of the
+// answered records, including the cname records the name aliased by.
+func lookupIPFrom(ctx context.Context, server string, name dnsmessage.Name) ([]net.IP, time.Duration, error) {
+	var ips []net.IP
+	var ttl uint32
+	for _, qtype := range []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA} {
+		answers, err := dnsExchange(ctx, server, name, qtype)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, answer := range answers {
+			switch body := answer.Body.(type) {
+			case *dnsmessage.AResource:
+				ips = append(ips, net.IP(append([]byte(nil), body.A[:]...)))
+			case *dnsmessage.AAAAResource:
+				ips = append(ips, net.IP(append([]byte(nil), body.AAAA[:]...)))
+			case *dnsmessage.CNAMEResource:
+			default:
+				continue
+			}
+			if ttl == 0 || answer.Header.TTL < ttl {
+				ttl = answer.Header.TTL
+			}
+		}
+	}
+	return ips, time.Duration(ttl) * time.Second, nil
+}
+
+// dnsExchange sends the query to the nameserver over udp, and retries over tcp if the reply truncated
+func dnsExchange(ctx context.Context, server string, name dnsmessage.Name, qtype dnsmessage.Type) ([]dnsmessage.Resource, error) {
+	id := uint16(rand.Uint32())
+	query, err := (&dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: name, Type: qtype, Class: dnsmessage.ClassINET}},
+	}).Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := dnsRoundTrip(ctx, "udp", server, id, query)
+	if err == nil && reply.Truncated {
+		reply, err = dnsRoundTrip(ctx, "tcp", server, id, query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if reply.RCode != dnsmessage.RCodeSuccess {
+		return nil, fmt.Errorf("query %s %s: %s", qtype, name, reply.RCode)
+	}
+	return reply.Answers, nil
+}
+
+// dnsRoundTrip sends the query and returns the reply with the same id, messages over tcp are prefixed with
+// two bytes length
+func dnsRoundTrip(ctx context.Context, network, server string, id uint16, query []byte) (*dnsmessage.Message, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if network == "tcp" {
+		query = append([]byte{byte(len(query) >> 8), byte(len(query))}, query...)
+	}
+	if _, err = conn.Write(query); err != nil {
+		return nil, err
+	}
+
+	for {
+		var buf []byte
+		if network == "tcp" {
+			length := make([]byte, 2)
+			if _, err = io.ReadFull(conn, length); err != nil {
+				return nil, err
+			}
+			buf = make([]byte, int(length[0])<<8|int(length[1]))
+			if _, err = io.ReadFull(conn, buf); err != nil {
+				return nil, err
+			}
+		} else {
+			buf = make([]byte, dnsMaxUDPSize)
+			n, err := conn.Read(buf)
+			if err != nil {
+				return nil, err
+			}
+			buf = buf[:n]
+		}
+
+		var reply dnsmessage.Message
+		// ignore replies not for the query
+		if err = reply.Unpack(buf); err != nil || reply.ID != id || !reply.Response {
+			continue
+		}
+		return &reply, nil
+	}
+}
+
+type fqdnRecord struct {
+	cidrs    sets.Set[string]
+	expireAt time.Time
+}
+
+// fqdnCache caches resolved addresses of fqdn peers until the records ttl expired
+type fqdnCache struct {
+	lock     sync.Mutex
+	resolver FQDNResolver
+	minTTL   time.Duration
+	clock    clock.PassiveClock
+	records  map[string]*fqdnRecord
+}
+
+func newFQDNCache(resolver FQDNResolver, minTTL time.Duration, clock clock.PassiveClock) *fqdnCache {
+	return &fqdnCache{
+		resolver: resolver,
+		minTTL:   minTTL,
+		clock:    clock,
+		records:  make(map[string]*fqdnRecord),
+	}
+}
+
+// Get returns cidrs of the fqdn addresses, it never resolves the fqdn. The fqdn not cached is resolved by the
+// next Refresh, and would be reported as changed if it resolves any address.
+func (c *fqdnCache) Get(fqdn string) []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	record, ok := c.records[fqdn]
+	if !ok {
+		record = &fqdnRecord{cidrs: sets.New[string]()}
+		c.records[fqdn] = record
+	}
+	return sets.List(record.cidrs)
+}
+
+// Refresh resolves the fqdns which records expired or not resolved yet, and returns fqdns which addresses
+// changed. Records of fqdns not referenced any more are removed.
+func (c *fqdnCache) Refresh(referenced sets.Set[string]) sets.Set[string] {
+	var expired []string
+	c.lock.Lock()
+	for fqdn, record := range c.records {
+		if !referenced.Has(fqdn) {
+			delete(c.records, fqdn)
+			continue
+		}
+		if !c.clock.Now().Before(record.expireAt) {
+			expired = append(expired, fqdn)
+		}
+	}
+	for fqdn := range referenced {
+		if _, ok := c.records[fqdn]; !ok {
+			c.records[fqdn] = &fqdnRecord{cidrs: sets.New[string]()}
+			expired = append(expired, fqdn)
+		}
+	}
+	c.lock.Unlock()
+
+	changed := sets.New[string]()
+	for _, fqdn := range expired {
+		c.lock.Lock()
+		var oldCIDRs sets.Set[string]
+		if record, ok := c.records[fqdn]; ok {
+			oldCIDRs = record.cidrs
+		}
+		c.lock.Unlock()
+		if oldCIDRs == nil {
+			// removed by another refresh
+			continue
+		}
+
+		record := c.resolve(fqdn, oldCIDRs)
+		if !record.cidrs.Equal(oldCIDRs) {
+			klog.Infof("fqdn %s addresses changed from %v to %v", fqdn, sets.List(oldCIDRs), sets.List(record.cidrs))
+			changed.Insert(fqdn)
+		}
+		c.lock.Lock()
+		c.records[fqdn] = record
+		c.lock.Unlock()
+	}
+	return changed
+}
+
+// resolve returns the record of fqdn, the last cidrs are kept on resolve failure and retried after min ttl
+func (c *fqdnCache) resolve(fqdn string, lastCIDRs sets.Set[string]) *fqdnRecord {
+	ctx, cancel := context.WithTimeout(context.Background(), fqdnResolveTimeout)
+	defer cancel()
+
+	ips, ttl, err := c.resolver.LookupIP(ctx, fqdn)
+	if ttl < c.minTTL {
+		ttl = c.minTTL
+	}
+	record := &fqdnRecord{cidrs: lastCIDRs, expireAt: c.clock.Now().Add(ttl)}
+	if err != nil {
+		klog.Errorf("failed to resolve fqdn %s, keep last addresses %v: %s", fqdn, sets.List(lastCIDRs), err)
+		return record
+	}
+
+	record.cidrs = sets.New[string]()
+	for _, ip := range ips {
+		maskLen := net.IPv6len * 8
+		if ip.To4() != nil {
+			ip, maskLen = ip.To4(), net.IPv4len*8
+		}
+		record.cidrs.Insert((&net.IPNet{IP: ip, Mask: net.CIDRMask(maskLen, maskLen)}).String())
+	}
+	return record
+}
+
+// setupFQDNRefresh refreshes addresses of fqdn peers in background, and reconciles policies by the policy
+// controller when addresses changed
+func (r *Reconciler) setupFQDNRefresh(mgr ctrl.Manager, policyController controller.Controller) error {
+	if r.FQDNResolver == nil {
+		r.FQDNResolver = DNSResolver{}
+	}
+	if r.FQDNMinTTL == 0 {
+		r.FQDNMinTTL = DefaultFQDNMinTTL
+	}
+	r.fqdnCache = newFQDNCache(r.FQDNResolver, r.FQDNMinTTL, clock.RealClock{})
+
+	syncChan := make(chan event.GenericEvent)
+	if err := policyController.Watch(&source.Channel{Source: syncChan}, &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+	return mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		r.runFQDNRefresh(ctx, syncChan)
+		return nil
+	}))
+}
+
+// resolveFQDN resolves the fqdn peer with the cached addresses of the domain name, it never blocks on dns
+// queries. It resolves nothing before the domain name resolved by the refresh, the policy would be reconciled
+// again when addresses changed.
+func (r *Reconciler) resolveFQDN(fqdn string) []string {
+	cidrs := r.fqdnCache.Get(fqdn)
+	if len(cidrs) == 0 {
+		klog.Warningf("fqdn %s resolves nothing", fqdn)
+	}
+	return cidrs
+}
+
+// runFQDNRefresh refreshes expired fqdn addresses and reconciles policies which fqdn addresses changed
+func (r *Reconciler) runFQDNRefresh(ctx context.Context, syncChan chan<- event.GenericEvent) {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		var policyList securityv1alpha1.SecurityPolicyList
+		if err := r.List(ctx, &policyList); err != nil {
+			klog.Errorf("unable to list policies: %s", err)
+			return
+		}
+
+		referenced := sets.New[string]()
+		for i := range policyList.Items {
+			referenced.Insert(fqdnPeers(&policyList.Items[i])...)
+		}
+		changed := r.fqdnCache.Refresh(referenced)
+		if changed.Len() == 0 {
+			return
+		}
+
+		for i := range policyList.Items {
+			policy := &policyList.Items[i]
+			if !changed.HasAny(fqdnPeers(policy)...) {
+				continue
+			}
+			select {
+			case syncChan <- event.GenericEvent{Object: policy}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}, fqdnRefreshCheckInterval)
+}
+
+// fqdnPeers returns domain names of fqdn peers in egress rules of the policy
+func fqdnPeers(policy *securityv1alpha1.SecurityPolicy) []string {
+	var fqdns []string
+	for _, rule := range policy.Spec.EgressRules {
+		for _, peer := range rule.To {
+			if peer.FQDN != "" {
+				fqdns = append(fqdns, peer.FQDN)
+			}
+		}
+	}
+	return fqdns
+}
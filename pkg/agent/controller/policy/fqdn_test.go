@@ -0,0 +1,135 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/dns/dnsmessage"
+	"k8s.io/apimachinery/pkg/util/sets"
+	testclocks "k8s.io/utils/clock/testing"
+)
+
+type fakeResolver struct {
+	ips []net.IP
+	ttl time.Duration
+	err error
+}
+
+func (r *fakeResolver) LookupIP(context.Context, string) ([]net.IP, time.Duration, error) {
+	return r.ips, r.ttl, r.err
+}
+
+func TestFQDNCacheRefresh(t *testing.T) {
+	RegisterTestingT(t)
+
+	fqdn := "api.example.com"
+	resolver := &fakeResolver{ips: []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("fd00::1")}, ttl: time.Minute}
+	fakeClock := testclocks.NewFakePassiveClock(time.Now())
+	cache := newFQDNCache(resolver, 10*time.Second, fakeClock)
+
+	Expect(cache.Get(fqdn)).Should(BeEmpty(), "fqdn should never be resolved on get")
+	Expect(cache.Refresh(sets.New(fqdn))).Should(Equal(sets.New(fqdn)))
+	Expect(cache.Get(fqdn)).Should(ConsistOf("10.0.0.1/32", "fd00::1/128"))
+
+	resolver.ips = []net.IP{net.ParseIP("10.0.0.2")}
+	Expect(cache.Refresh(sets.New(fqdn))).Should(BeEmpty(), "records should be cached until ttl expired")
+	Expect(cache.Get(fqdn)).Should(ConsistOf("10.0.0.1/32", "fd00::1/128"))
+
+	fakeClock.SetTime(fakeClock.Now().Add(time.Minute))
+	Expect(cache.Refresh(sets.New(fqdn))).Should(Equal(sets.New(fqdn)))
+	Expect(cache.Get(fqdn)).Should(ConsistOf("10.0.0.2/32"))
+
+	t.Run("should keep last addresses on resolve failure", func(t *testing.T) {
+		resolver.err = fmt.Errorf("timeout")
+		resolver.ttl = 0
+		fakeClock.SetTime(fakeClock.Now().Add(time.Minute))
+		Expect(cache.Refresh(sets.New(fqdn))).Should(BeEmpty())
+		Expect(cache.Get(fqdn)).Should(ConsistOf("10.0.0.2/32"))
+
+		// retried after min ttl
+		resolver.err = nil
+		resolver.ips = []net.IP{net.ParseIP("10.0.0.3")}
+		fakeClock.SetTime(fakeClock.Now().Add(10 * time.Second))
+		Expect(cache.Refresh(sets.New(fqdn))).Should(Equal(sets.New(fqdn)))
+		Expect(cache.Get(fqdn)).Should(ConsistOf("10.0.0.3/32"))
+	})
+
+	t.Run("should remove records not referenced", func(t *testing.T) {
+		Expect(cache.Refresh(sets.New[string]())).Should(BeEmpty())
+		Expect(cache.records).ShouldNot(HaveKey(fqdn))
+	})
+}
+
+func TestDNSResolverLookupIP(t *testing.T) {
+	RegisterTestingT(t)
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	Expect(err).ShouldNot(HaveOccurred())
+	defer conn.Close()
+	go serveFakeDNS(conn, map[dnsmessage.Type][]dnsmessage.Resource{
+		dnsmessage.TypeA: {
+			{Header: dnsmessage.ResourceHeader{TTL: 300}, Body: &dnsmessage.AResource{A: [4]byte{10, 0, 0, 1}}},
+			{Header: dnsmessage.ResourceHeader{TTL: 120}, Body: &dnsmessage.AResource{A: [4]byte{10, 0, 0, 2}}},
+		},
+		dnsmessage.TypeAAAA: {
+			{Header: dnsmessage.ResourceHeader{TTL: 600}, Body: &dnsmessage.AAAAResource{AAAA: [16]byte{0xfd, 15: 1}}},
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), fqdnResolveTimeout)
+	defer cancel()
+	name := dnsmessage.MustNewName("api.example.com.")
+	ips, ttl, err := lookupIPFrom(ctx, conn.LocalAddr().String(), name)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(ips).Should(ConsistOf(net.ParseIP("10.0.0.1").To4(), net.ParseIP("10.0.0.2").To4(), net.ParseIP("fd00::1")))
+	Expect(ttl).Should(Equal(120 * time.Second))
+}
+
+// serveFakeDNS answers queries with the records of the query type
+func serveFakeDNS(conn net.PacketConn, records map[dnsmessage.Type][]dnsmessage.Resource) {
+	buf := make([]byte, dnsMaxUDPSize)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		var query dnsmessage.Message
+		if err = query.Unpack(buf[:n]); err != nil || len(query.Questions) != 1 {
+			continue
+		}
+		question := query.Questions[0]
+		reply := dnsmessage.Message{
+			Header:    dnsmessage.Header{ID: query.ID, Response: true},
+			Questions: query.Questions,
+		}
+		for _, record := range records[question.Type] {
+			record.Header.Name, record.Header.Type, record.Header.Class = question.Name, question.Type, question.Class
+			reply.Answers = append(reply.Answers, record)
+		}
+		packed, err := reply.Pack()
+		if err != nil {
+			continue
+		}
+		_, _ = conn.WriteTo(packed, addr)
+	}
+}
@@ -53,6 +53,7 @@ func (r *Reconciler) ReconcileGlobalPolicy(context.Context, ctrl.Request) (ctrl.
 	}
 
 	r.syncPolicyRulesUntilSuccess(oldPolicyRule, newPolicyRule)
+	r.initialSync.Reconciled(globalPolicySyncKey)
 	return ctrl.Result{}, nil
 }
 
@@ -91,6 +92,10 @@ func (r *Reconciler) calculateExpectGlobalPolicyRules() ([]cache.PolicyRule, err
 
 func newGlobalPolicyRulePair(policy securityv1alpha1.GlobalPolicy) []cache.PolicyRule {
 	var ingressRule, egressRule cache.PolicyRule
+	var loggingTags map[string]string
+	if policy.Spec.Logging != nil {
+		loggingTags = policy.Spec.Logging.Tags
+	}
 
 	ingressRule = cache.PolicyRule{
 		Direction:       cache.RuleDirectionIn,
@@ -99,6 +104,9 @@ func newGlobalPolicyRulePair(policy securityv1alpha1.GlobalPolicy) []cache.Polic
 		DstIPAddr:       "",
 		Action:          cache.RuleAction(policy.Spec.DefaultAction),
 		EnforcementMode: string(policy.Spec.GlobalPolicyEnforcementMode),
+		LoggingTags:     loggingTags,
+		Logged:          actionLogged(policy.Spec.Logging, cache.RuleAction(policy.Spec.DefaultAction)),
+		LogSampleRate:   actionLogSampleRate(policy.Spec.Logging, cache.RuleAction(policy.Spec.DefaultAction)),
 	}
 	ingressRule.Name = fmt.Sprintf("/%s/%s/global.ingress/-%s", DefaultGlobalPolicyName, cache.GlobalPolicy, cache.GenerateFlowKey(ingressRule))
 
@@ -109,6 +117,9 @@ func newGlobalPolicyRulePair(policy securityv1alpha1.GlobalPolicy) []cache.Polic
 		SrcIPAddr:       "",
 		Action:          cache.RuleAction(policy.Spec.DefaultAction),
 		EnforcementMode: string(policy.Spec.GlobalPolicyEnforcementMode),
+		LoggingTags:     loggingTags,
+		Logged:          actionLogged(policy.Spec.Logging, cache.RuleAction(policy.Spec.DefaultAction)),
+		LogSampleRate:   actionLogSampleRate(policy.Spec.Logging, cache.RuleAction(policy.Spec.DefaultAction)),
 	}
 	egressRule.Name = fmt.Sprintf("/%s/%s/global.egress/-%s", DefaultGlobalPolicyName, cache.GlobalPolicy, cache.GenerateFlowKey(egressRule))
 
@@ -0,0 +1,179 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	securityv1alpha1 "github.com/everoute/everoute/pkg/apis/security/v1alpha1"
+)
+
+// ifaceNameRefreshInterval is the interval local endpoints matching interface name patterns, vlan ranges
+// or endpoint network peers checked
+const ifaceNameRefreshInterval = 5 * time.Second
+
+// localEndpointCache caches ips of local endpoints resolved by keys, e.g. interface name patterns, vlan ranges
+// or endpoint network types
+type localEndpointCache[K comparable] struct {
+	lock     sync.Mutex
+	resolve  func(key K) []string
+	resolved map[K]sets.Set[string]
+}
+
+func newLocalEndpointCache[K comparable](resolve func(key K) []string) *localEndpointCache[K] {
+	return &localEndpointCache[K]{
+		resolve:  resolve,
+		resolved: make(map[K]sets.Set[string]),
+	}
+}
+
+// Get returns ips of local endpoints matching the key
+func (c *localEndpointCache[K]) Get(key K) []string {
+	ips := sets.New(c.resolve(key)...)
+	c.lock.Lock()
+	c.resolved[key] = ips
+	c.lock.Unlock()
+	return sets.List(ips)
+}
+
+// Refresh resolves the cached keys again, and returns keys which ips changed.
+// Keys not referenced any more are removed.
+func (c *localEndpointCache[K]) Refresh(referenced sets.Set[K]) sets.Set[K] {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	changed := sets.New[K]()
+	for key, ips := range c.resolved {
+		if !referenced.Has(key) {
+			delete(c.resolved, key)
+			continue
+		}
+		if newIPs := sets.New(c.resolve(key)...); !newIPs.Equal(ips) {
+			klog.Infof("local endpoints matching %v changed from %v to %v", key, sets.List(ips), sets.List(newIPs))
+			c.resolved[key] = newIPs
+			changed.Insert(key)
+		}
+	}
+	return changed
+}
+
+// setupIfaceNameRefresh checks local endpoints matching interface name patterns, vlan ranges or endpoint network
+// peers in background, and reconciles policies by the policy controller when endpoints changed, endpoint network
+// peers are also changed with the gateway of endpoints
+func (r *Reconciler) setupIfaceNameRefresh(mgr ctrl.Manager, policyController controller.Controller) error {
+	r.ifaceNameCache = newLocalEndpointCache(r.DatapathManager.GetLocalEndpointIPsByIfaceName)
+	r.vlanRangeCache = newLocalEndpointCache(func(vlanRange securityv1alpha1.VlanRange) []string {
+		return r.DatapathManager.GetLocalEndpointIPsByVlanRange(uint16(vlanRange.Start), uint16(vlanRange.End))
+	})
+	r.endpointNetworkCache = newLocalEndpointCache(r.resolveEndpointNetworkIPs)
+
+	syncChan := make(chan event.GenericEvent)
+	if err := policyController.Watch(&source.Channel{Source: syncChan}, &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+	return mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		r.runIfaceNameRefresh(ctx, syncChan)
+		return nil
+	}))
+}
+
+// runIfaceNameRefresh reconciles policies which applied interface name patterns, vlan ranges or endpoint
+// network peers match different local endpoints
+func (r *Reconciler) runIfaceNameRefresh(ctx context.Context, syncChan chan<- event.GenericEvent) {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		var policyList securityv1alpha1.SecurityPolicyList
+		if err := r.List(ctx, &policyList); err != nil {
+			klog.Errorf("unable to list policies: %s", err)
+			return
+		}
+
+		referencedIfaceNames := sets.New[string]()
+		referencedVlanRanges := sets.New[securityv1alpha1.VlanRange]()
+		referencedNetworks := sets.New[securityv1alpha1.EndpointNetworkType]()
+		for i := range policyList.Items {
+			referencedIfaceNames.Insert(appliedIfaceNames(&policyList.Items[i])...)
+			referencedVlanRanges.Insert(appliedVlanRanges(&policyList.Items[i])...)
+			referencedNetworks.Insert(endpointNetworkPeers(&policyList.Items[i])...)
+		}
+		changedIfaceNames := r.ifaceNameCache.Refresh(referencedIfaceNames)
+		changedVlanRanges := r.vlanRangeCache.Refresh(referencedVlanRanges)
+		changedNetworks := r.endpointNetworkCache.Refresh(referencedNetworks)
+		if changedIfaceNames.Len() == 0 && changedVlanRanges.Len() == 0 && changedNetworks.Len() == 0 {
+			return
+		}
+
+		for i := range policyList.Items {
+			policy := &policyList.Items[i]
+			if !changedIfaceNames.HasAny(appliedIfaceNames(policy)...) && !changedVlanRanges.HasAny(appliedVlanRanges(policy)...) &&
+				!changedNetworks.HasAny(endpointNetworkPeers(policy)...) {
+				continue
+			}
+			select {
+			case syncChan <- event.GenericEvent{Object: policy}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}, ifaceNameRefreshInterval)
+}
+
+// appliedIfaceNames returns interface name patterns the policy applied to
+func appliedIfaceNames(policy *securityv1alpha1.SecurityPolicy) []string {
+	var patterns []string
+	for _, appliedTo := range policy.Spec.AppliedTo {
+		if appliedTo.InterfaceName != nil {
+			patterns = append(patterns, *appliedTo.InterfaceName)
+		}
+	}
+	return patterns
+}
+
+// appliedVlanRanges returns vlan ranges the policy applied to
+func appliedVlanRanges(policy *securityv1alpha1.SecurityPolicy) []securityv1alpha1.VlanRange {
+	var vlanRanges []securityv1alpha1.VlanRange
+	for _, appliedTo := range policy.Spec.AppliedTo {
+		if appliedTo.VlanRange != nil {
+			vlanRanges = append(vlanRanges, *appliedTo.VlanRange)
+		}
+	}
+	return vlanRanges
+}
+
+// endpointNetworkPeers returns endpoint networks of the endpoint network peers in rules of the policy
+func endpointNetworkPeers(policy *securityv1alpha1.SecurityPolicy) []securityv1alpha1.EndpointNetworkType {
+	networks := sets.New[securityv1alpha1.EndpointNetworkType]()
+	for _, rule := range append(append([]securityv1alpha1.Rule{}, policy.Spec.IngressRules...), policy.Spec.EgressRules...) {
+		for _, peer := range append(append([]securityv1alpha1.SecurityPolicyPeer{}, rule.From...), rule.To...) {
+			if peer.EndpointNetwork != nil {
+				networks.Insert(*peer.EndpointNetwork)
+			}
+		}
+	}
+	return sets.List(networks)
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"net"
+	"path"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/pointer"
+
+	policycache "github.com/everoute/everoute/pkg/agent/controller/policy/cache"
+	"github.com/everoute/everoute/pkg/agent/datapath"
+	securityv1alpha1 "github.com/everoute/everoute/pkg/apis/security/v1alpha1"
+)
+
+// fakeIfaceIPs resolves interface name patterns with the interface ips set in test
+type fakeIfaceIPs map[string]string
+
+func (f fakeIfaceIPs) resolve(pattern string) []string {
+	var ips []string
+	for ifaceName, ip := range f {
+		if matched, _ := path.Match(pattern, ifaceName); matched {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
+
+func TestIfaceNameAppliedTo(t *testing.T) {
+	RegisterTestingT(t)
+
+	ifaceIPs := fakeIfaceIPs{"veth1": "10.0.0.1/32", "veth2": "10.0.0.2/32", "tap1": "10.0.0.3/32"}
+	r := &Reconciler{ifaceNameCache: newLocalEndpointCache(ifaceIPs.resolve)}
+	policy := &securityv1alpha1.SecurityPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "veth-policy"},
+		Spec: securityv1alpha1.SecurityPolicySpec{
+			AppliedTo: []securityv1alpha1.ApplyToPeer{{InterfaceName: pointer.String("veth*")}},
+			IngressRules: []securityv1alpha1.Rule{{
+				Name: "ingress",
+				From: []securityv1alpha1.SecurityPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "192.168.0.0/24"}}},
+			}},
+			DefaultRule: securityv1alpha1.DefaultRuleDrop,
+		},
+	}
+
+	completeRules, err := r.completePolicy(policy)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(completeRules).Should(HaveLen(2))
+	for _, rule := range completeRules {
+		Expect(rule.DstGroups).Should(BeEmpty())
+		Expect(rule.DstIPs).Should(Equal(sets.New("10.0.0.1/32", "10.0.0.2/32")))
+	}
+
+	t.Run("should refresh when endpoints matching interface name changed", func(t *testing.T) {
+		Expect(r.ifaceNameCache.Refresh(sets.New("veth*"))).Should(BeEmpty())
+
+		ifaceIPs["veth3"] = "10.0.0.4/32"
+		ifaceIPs["tap2"] = "10.0.0.5/32"
+		Expect(r.ifaceNameCache.Refresh(sets.New("veth*"))).Should(Equal(sets.New("veth*")))
+		Expect(r.ifaceNameCache.Get("veth*")).Should(Equal([]string{"10.0.0.1/32", "10.0.0.2/32", "10.0.0.4/32"}))
+	})
+
+	t.Run("should remove patterns not referenced", func(t *testing.T) {
+		Expect(r.ifaceNameCache.Refresh(sets.New[string]())).Should(BeEmpty())
+		Expect(r.ifaceNameCache.resolved).ShouldNot(HaveKey("veth*"))
+	})
+}
+
+func TestLocalEndpointsPeer(t *testing.T) {
+	RegisterTestingT(t)
+
+	ifaceIPs := fakeIfaceIPs{"veth1": "10.0.0.1/32", "veth2": "10.0.0.2/32"}
+	r := &Reconciler{
+		ifaceNameCache: newLocalEndpointCache(ifaceIPs.resolve),
+		endpointNetworkCache: newLocalEndpointCache(func(securityv1alpha1.EndpointNetworkType) []string {
+			return ifaceIPs.resolve("*")
+		}),
+	}
+	localEndpoints := securityv1alpha1.EndpointNetworkLocalEndpoints
+	policy := &securityv1alpha1.SecurityPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "local-only-policy"},
+		Spec: securityv1alpha1.SecurityPolicySpec{
+			AppliedTo: []securityv1alpha1.ApplyToPeer{{InterfaceName: pointer.String("veth*")}},
+			IngressRules: []securityv1alpha1.Rule{{
+				Name: "ingress",
+				From: []securityv1alpha1.SecurityPolicyPeer{{EndpointNetwork: &localEndpoints}},
+			}},
+			DefaultRule: securityv1alpha1.DefaultRuleDrop,
+		},
+	}
+	Expect(endpointNetworkPeers(policy)).Should(Equal([]securityv1alpha1.EndpointNetworkType{localEndpoints}))
+
+	completeRules, err := r.completePolicy(policy)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(completeRules).Should(HaveLen(2))
+	for _, rule := range completeRules {
+		if rule.DefaultPolicyRule {
+			Expect(rule.Action).Should(Equal(policycache.RuleActionDrop))
+			continue
+		}
+		Expect(rule.Action).Should(Equal(policycache.RuleActionAllow))
+		Expect(rule.SrcIPs).Should(Equal(sets.New("10.0.0.1/32", "10.0.0.2/32")), "intra-node traffic should be allowed")
+		Expect(rule.SrcIPs).ShouldNot(HaveKey("10.0.1.1/32"), "traffic from remote source should be dropped by default rule")
+		Expect(rule.TrafficLocality).Should(Equal(string(securityv1alpha1.TrafficLocalityIntraNode)),
+			"remote traffic spoofing local endpoint ips should not be allowed")
+	}
+
+	t.Run("should match local endpoints peers in separate rule", func(t *testing.T) {
+		policy := policy.DeepCopy()
+		policy.Spec.IngressRules[0].From = append(policy.Spec.IngressRules[0].From, securityv1alpha1.SecurityPolicyPeer{
+			IPBlock: &networkingv1.IPBlock{CIDR: "10.0.1.0/24"},
+		})
+		completeRules, err := r.completePolicy(policy)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(completeRules).Should(HaveLen(3))
+		for _, rule := range completeRules {
+			switch rule.RuleID {
+			case "default/local-only-policy/normal/ingress.ingress.local":
+				Expect(rule.SrcIPs).Should(Equal(sets.New("10.0.0.1/32", "10.0.0.2/32")))
+				Expect(rule.TrafficLocality).Should(Equal(string(securityv1alpha1.TrafficLocalityIntraNode)))
+			case "default/local-only-policy/normal/ingress.ingress":
+				Expect(rule.SrcIPs).Should(Equal(sets.New("10.0.1.0/24")))
+				Expect(rule.TrafficLocality).Should(BeEmpty())
+			}
+		}
+	})
+
+	t.Run("should refresh when local endpoints changed", func(t *testing.T) {
+		Expect(r.endpointNetworkCache.Refresh(sets.New(localEndpoints))).Should(BeEmpty())
+
+		ifaceIPs["tap1"] = "10.0.0.3/32"
+		Expect(r.endpointNetworkCache.Refresh(sets.New(localEndpoints))).Should(Equal(sets.New(localEndpoints)))
+		Expect(r.endpointNetworkCache.Get(localEndpoints)).Should(Equal([]string{"10.0.0.1/32", "10.0.0.2/32", "10.0.0.3/32"}))
+	})
+}
+
+func TestEndpointNetworkPeerRefresh(t *testing.T) {
+	RegisterTestingT(t)
+
+	r := &Reconciler{
+		DatapathManager: datapath.NewDatapathManager(&datapath.DpManagerConfig{ManagedVDSMap: map[string]string{}}, nil),
+	}
+	r.endpointNetworkCache = newLocalEndpointCache(r.resolveEndpointNetworkIPs)
+	gateway, subnet := securityv1alpha1.EndpointNetworkGateway, securityv1alpha1.EndpointNetworkSubnet
+	policy := &securityv1alpha1.SecurityPolicy{
+		Spec: securityv1alpha1.SecurityPolicySpec{
+			IngressRules: []securityv1alpha1.Rule{{From: []securityv1alpha1.SecurityPolicyPeer{{EndpointNetwork: &gateway}}}},
+			EgressRules:  []securityv1alpha1.Rule{{To: []securityv1alpha1.SecurityPolicyPeer{{EndpointNetwork: &subnet}, {EndpointNetwork: &gateway}}}},
+		},
+	}
+	Expect(endpointNetworkPeers(policy)).Should(Equal([]securityv1alpha1.EndpointNetworkType{gateway, subnet}))
+
+	Expect(r.resolveEndpointNetwork(gateway)).Should(BeEmpty(), "gateway unknown resolves nothing")
+	Expect(r.resolveEndpointNetwork(subnet)).Should(BeEmpty(), "gateway unknown resolves nothing")
+
+	r.DatapathManager.Info.GatewayIP = net.ParseIP("10.10.0.1")
+	r.DatapathManager.Info.GatewayMask = net.CIDRMask(24, 32)
+	Expect(r.endpointNetworkCache.Refresh(sets.New(endpointNetworkPeers(policy)...))).Should(Equal(sets.New(gateway, subnet)))
+	Expect(r.resolveEndpointNetwork(gateway)).Should(Equal([]string{"10.10.0.1/32"}))
+	Expect(r.resolveEndpointNetwork(subnet)).Should(Equal([]string{"10.10.0.0/24"}))
+
+	r.DatapathManager.Info.GatewayIP = net.ParseIP("10.10.0.254")
+	Expect(r.endpointNetworkCache.Refresh(sets.New(endpointNetworkPeers(policy)...))).Should(Equal(sets.New(gateway)))
+	Expect(r.resolveEndpointNetwork(gateway)).Should(Equal([]string{"10.10.0.254/32"}))
+}
+
+// fakeVlanIPs resolves vlan ranges with the vlan id of endpoint ips set in test
+type fakeVlanIPs map[string]uint32
+
+func (f fakeVlanIPs) resolve(vlanRange securityv1alpha1.VlanRange) []string {
+	var ips []string
+	for ip, vlanID := range f {
+		if vlanID >= vlanRange.Start && vlanID <= vlanRange.End {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
+
+func TestVlanRangeAppliedTo(t *testing.T) {
+	RegisterTestingT(t)
+
+	vlanIPs := fakeVlanIPs{"10.0.0.1/32": 50, "10.0.0.2/32": 100, "10.0.0.3/32": 150, "10.0.0.4/32": 199, "10.0.0.5/32": 200}
+	r := &Reconciler{vlanRangeCache: newLocalEndpointCache(vlanIPs.resolve)}
+	vlanRange := securityv1alpha1.VlanRange{Start: 100, End: 199}
+	policy := &securityv1alpha1.SecurityPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vlan-policy"},
+		Spec: securityv1alpha1.SecurityPolicySpec{
+			AppliedTo: []securityv1alpha1.ApplyToPeer{{VlanRange: &vlanRange}},
+			IngressRules: []securityv1alpha1.Rule{{
+				Name: "ingress",
+				From: []securityv1alpha1.SecurityPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "192.168.0.0/24"}}},
+			}},
+			DefaultRule: securityv1alpha1.DefaultRuleDrop,
+		},
+	}
+
+	completeRules, err := r.completePolicy(policy)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(completeRules).Should(HaveLen(2))
+	for _, rule := range completeRules {
+		Expect(rule.DstGroups).Should(BeEmpty())
+		Expect(rule.DstIPs).Should(Equal(sets.New("10.0.0.2/32", "10.0.0.3/32", "10.0.0.4/32")))
+		Expect(rule.DstIPs).ShouldNot(HaveKey("10.0.0.1/32"), "endpoint on vlan 50 should be unaffected")
+		Expect(rule.DstIPs).ShouldNot(HaveKey("10.0.0.5/32"), "endpoint on vlan 200 should be unaffected")
+	}
+
+	t.Run("should refresh when endpoints in vlan range changed", func(t *testing.T) {
+		Expect(r.vlanRangeCache.Refresh(sets.New(vlanRange))).Should(BeEmpty())
+
+		vlanIPs["10.0.0.6/32"] = 120
+		vlanIPs["10.0.0.7/32"] = 300
+		Expect(r.vlanRangeCache.Refresh(sets.New(vlanRange))).Should(Equal(sets.New(vlanRange)))
+		Expect(r.vlanRangeCache.Get(vlanRange)).Should(Equal([]string{"10.0.0.2/32", "10.0.0.3/32", "10.0.0.4/32", "10.0.0.6/32"}))
+	})
+}
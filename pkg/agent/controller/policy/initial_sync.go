@@ -0,0 +1,152 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	groupv1alpha1 "github.com/everoute/everoute/pkg/apis/group/v1alpha1"
+	securityv1alpha1 "github.com/everoute/everoute/pkg/apis/security/v1alpha1"
+)
+
+// globalPolicySyncKey is the initial sync key of global policies, any global policy reconcile syncs all of them
+const globalPolicySyncKey = "globalpolicy"
+
+func policySyncKey(policy k8stypes.NamespacedName) string {
+	return "securitypolicy/" + policy.String()
+}
+
+func groupMembersSyncKey(name string) string {
+	return "groupmembers/" + name
+}
+
+// initialSync tracks policies, groupmembers and global policies existed on agent start, done is called once
+// after all of them reconciled, so that the datapath keeps rule flows of the previous round until then
+type initialSync struct {
+	lock       sync.Mutex
+	listed     bool
+	pending    sets.Set[string]
+	reconciled sets.Set[string] // keys reconciled before listed
+	done       func()
+}
+
+func newInitialSync(done func()) *initialSync {
+	return &initialSync{
+		reconciled: sets.New[string](),
+		done:       done,
+	}
+}
+
+// Listed sets the keys existed on agent start, keys reconciled before are not waited
+func (s *initialSync) Listed(keys ...string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.listed = true
+	s.pending = sets.New(keys...).Difference(s.reconciled)
+	s.reconciled = nil
+	klog.Infof("Wait %d policies, groupmembers and global policies for initial sync", s.pending.Len())
+	s.checkDone()
+}
+
+// Reconciled marks the key reconciled, the reconcile must not be retried for the objects it depends on
+func (s *initialSync) Reconciled(key string) {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.listed {
+		s.reconciled.Insert(key)
+		return
+	}
+	s.pending.Delete(key)
+	s.checkDone()
+}
+
+func (s *initialSync) checkDone() {
+	if s.pending.Len() != 0 || s.done == nil {
+		return
+	}
+	klog.Infof("Policy rules initial sync done")
+	s.done()
+	s.done = nil
+}
+
+// setupInitialSync marks policy rules synced in datapath after objects existed on agent start reconciled
+func (r *Reconciler) setupInitialSync(mgr ctrl.Manager) error {
+	r.initialSync = newInitialSync(r.DatapathManager.MarkPolicyRulesSynced)
+
+	return mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		if !mgr.GetCache().WaitForCacheSync(ctx) {
+			return nil
+		}
+		var keys []string
+		_ = wait.PollImmediateUntilWithContext(ctx, time.Second, func(ctx context.Context) (bool, error) {
+			var err error
+			if keys, err = r.listInitialSyncKeys(ctx); err != nil {
+				klog.Errorf("Failed to list objects for policy rules initial sync: %s", err)
+				return false, nil
+			}
+			return true, nil
+		})
+		if ctx.Err() != nil {
+			return nil
+		}
+		r.initialSync.Listed(keys...)
+		return nil
+	}))
+}
+
+func (r *Reconciler) listInitialSyncKeys(ctx context.Context) ([]string, error) {
+	var keys []string
+
+	var policyList securityv1alpha1.SecurityPolicyList
+	if err := r.List(ctx, &policyList); err != nil {
+		return nil, err
+	}
+	for _, policy := range policyList.Items {
+		keys = append(keys, policySyncKey(k8stypes.NamespacedName{Namespace: policy.Namespace, Name: policy.Name}))
+	}
+
+	var groupMembersList groupv1alpha1.GroupMembersList
+	if err := r.List(ctx, &groupMembersList); err != nil {
+		return nil, err
+	}
+	for _, gm := range groupMembersList.Items {
+		keys = append(keys, groupMembersSyncKey(gm.Name))
+	}
+
+	var globalPolicyList securityv1alpha1.GlobalPolicyList
+	if err := r.List(ctx, &globalPolicyList); err != nil {
+		return nil, err
+	}
+	if len(globalPolicyList.Items) != 0 {
+		keys = append(keys, globalPolicySyncKey)
+	}
+	return keys, nil
+}
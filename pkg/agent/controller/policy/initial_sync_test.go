@@ -0,0 +1,67 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+)
+
+func TestInitialSync(t *testing.T) {
+	RegisterTestingT(t)
+
+	policyKey := policySyncKey(k8stypes.NamespacedName{Namespace: "ns", Name: "policy"})
+
+	t.Run("done after all listed objects reconciled", func(t *testing.T) {
+		var done int
+		s := newInitialSync(func() { done++ })
+		s.Listed(policyKey, groupMembersSyncKey("group"), globalPolicySyncKey)
+		s.Reconciled(policyKey)
+		s.Reconciled(globalPolicySyncKey)
+		Expect(done).Should(Equal(0))
+		s.Reconciled(groupMembersSyncKey("group"))
+		Expect(done).Should(Equal(1))
+		// done only once
+		s.Reconciled(policyKey)
+		Expect(done).Should(Equal(1))
+	})
+
+	t.Run("objects reconciled before listed shouldn't be waited", func(t *testing.T) {
+		var done int
+		s := newInitialSync(func() { done++ })
+		s.Reconciled(policyKey)
+		Expect(done).Should(Equal(0))
+		s.Listed(policyKey, globalPolicySyncKey)
+		Expect(done).Should(Equal(0))
+		s.Reconciled(globalPolicySyncKey)
+		Expect(done).Should(Equal(1))
+	})
+
+	t.Run("done at once without objects listed", func(t *testing.T) {
+		var done int
+		s := newInitialSync(func() { done++ })
+		s.Listed()
+		Expect(done).Should(Equal(1))
+	})
+
+	t.Run("nil initial sync should be ignored", func(t *testing.T) {
+		var s *initialSync
+		s.Reconciled(policyKey)
+	})
+}
@@ -19,9 +19,13 @@ package policy
 import (
 	"context"
 	"fmt"
+	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	ipamv1alpha1 "github.com/everoute/ipam/api/ipam/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -29,12 +33,14 @@ import (
 	"k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	policycache "github.com/everoute/everoute/pkg/agent/controller/policy/cache"
@@ -48,6 +54,21 @@ import (
 	"github.com/everoute/everoute/plugin/tower/pkg/informer"
 )
 
+const (
+	// DefaultMaxRulesPerPolicy is the default max rules a policy could expand to, unlimited by default
+	DefaultMaxRulesPerPolicy = 0
+	// RulesExceedLimitRetryInterval is the interval policy or groupmembers rejected for exceeds max rules per
+	// policy reconciled again, so that it would be applied after the limit raised
+	RulesExceedLimitRetryInterval = time.Minute
+	// RulesExceedLimitReason is the event reason of policy rejected for exceeds max rules per policy
+	RulesExceedLimitReason = "RulesExceedLimit"
+	// UnknownRuleTierReason is the event reason of policy rule skipped for its tier isn't configured
+	UnknownRuleTierReason = "UnknownRuleTier"
+	// UnsupportedTrafficLocalityReason is the event reason of policy rule skipped for its traffic locality isn't
+	// supported without overlay
+	UnsupportedTrafficLocalityReason = "UnsupportedTrafficLocality"
+)
+
 type Reconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
@@ -67,6 +88,46 @@ type Reconciler struct {
 	groupCache *policycache.GroupCache
 
 	DatapathManager *datapath.DpManager
+
+	// FlowCompaction compact policy rule flows in low-activity windows, disable when nil
+	FlowCompaction *FlowCompactionConfig
+
+	// EverouteIPAM resolves ipPool peers and watches ippools, ipPool peers resolve nothing when disabled
+	EverouteIPAM bool
+
+	// PriorityBands maps namespace to the priority band policies in it clamped into, unlimited when absent
+	PriorityBands map[string]PriorityBand
+
+	// FQDNResolver resolves fqdn peers, the system resolver is used when nil
+	FQDNResolver FQDNResolver
+	// FQDNMinTTL is the min interval resolved addresses of fqdn peers refreshed, DefaultFQDNMinTTL when zero
+	FQDNMinTTL time.Duration
+	fqdnCache  *fqdnCache
+
+	// ifaceNameCache caches local endpoint ips matching interface name patterns of applied to
+	ifaceNameCache *localEndpointCache[string]
+
+	// vlanRangeCache caches local endpoint ips matching vlan ranges of applied to
+	vlanRangeCache *localEndpointCache[securityv1alpha1.VlanRange]
+
+	// endpointNetworkCache caches resolved ips of endpoint network peers
+	endpointNetworkCache *localEndpointCache[securityv1alpha1.EndpointNetworkType]
+
+	// reconcileCount counts policy and groupmembers reconciles since last flow compaction check
+	reconcileCount atomic.Int64
+
+	// maxRulesPerPolicy rejects policy expands to more rules than it, unlimited when not positive
+	maxRulesPerPolicy atomic.Int64
+	recorder          record.EventRecorder
+
+	// initialSync marks policy rules synced in datapath after objects existed on agent start reconciled
+	initialSync *initialSync
+}
+
+// SetMaxRulesPerPolicy sets the max rules a policy could expand to. Policy exceeds it would be rejected with a
+// warning event, and the rules installed before left intact. Not positive means unlimited.
+func (r *Reconciler) SetMaxRulesPerPolicy(maxRules int) {
+	r.maxRulesPerPolicy.Store(int64(maxRules))
 }
 
 func (r *Reconciler) ReconcilePolicy(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -74,6 +135,7 @@ func (r *Reconciler) ReconcilePolicy(ctx context.Context, req ctrl.Request) (ctr
 
 	r.reconcilerLock.Lock()
 	defer r.reconcilerLock.Unlock()
+	r.reconcileCount.Add(1)
 
 	klog.Infof("Reconcile securitypolicy %s", req.NamespacedName)
 
@@ -90,6 +152,7 @@ func (r *Reconciler) ReconcilePolicy(ctx context.Context, req ctrl.Request) (ctr
 			return ctrl.Result{}, err
 		}
 		klog.Infof("succeed remove policy %s all rules", req.Name)
+		r.initialSync.Reconciled(policySyncKey(req.NamespacedName))
 		return ctrl.Result{}, nil
 	}
 
@@ -99,6 +162,7 @@ func (r *Reconciler) ReconcilePolicy(ctx context.Context, req ctrl.Request) (ctr
 func (r *Reconciler) ReconcileGroupMembers(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	r.reconcilerLock.Lock()
 	defer r.reconcilerLock.Unlock()
+	r.reconcileCount.Add(1)
 
 	klog.Infof("Receive groupmembers %s reconcile", req.NamespacedName)
 
@@ -117,15 +181,22 @@ func (r *Reconciler) ReconcileGroupMembers(ctx context.Context, req ctrl.Request
 			}
 			r.groupCache.DelGroupMembership(req.Name)
 			klog.Infof("Success delete groupmembers %s", req.Name)
+			r.initialSync.Reconciled(groupMembersSyncKey(req.Name))
 			return ctrl.Result{}, nil
 		}
 		klog.Errorf("Failed to get groupmembers %s: %v", req.Name, err)
 		return ctrl.Result{}, err
 	}
 
-	r.ruleUpdateByGroup(&gm)
+	if err := r.ruleUpdateByGroup(&gm); err != nil {
+		// keep the groupmembers cache and rules installed before, so that cached rules match flows installed
+		klog.Errorf("reject groupmembers %s, keep rules installed before: %s", req.Name, err)
+		r.initialSync.Reconciled(groupMembersSyncKey(req.Name))
+		return ctrl.Result{RequeueAfter: RulesExceedLimitRetryInterval}, nil
+	}
 	r.groupCache.UpdateGroupMembership(&gm)
 	klog.Infof("Success update groupmembers %s", req.Name)
+	r.initialSync.Reconciled(groupMembersSyncKey(req.Name))
 	return ctrl.Result{}, nil
 }
 
@@ -164,6 +235,8 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 		r.groupCache = policycache.NewGroupCache()
 	}
 
+	r.recorder = mgr.GetEventRecorderFor("everoute-agent")
+
 	if policyController, err = controller.New("policy-controller", mgr, controller.Options{
 		MaxConcurrentReconciles: constants.DefaultMaxConcurrentReconciles,
 		Reconciler:              reconcile.Func(r.ReconcilePolicy),
@@ -194,24 +267,94 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 
-	return globalPolicyController.Watch(source.Kind(mgr.GetCache(), &securityv1alpha1.GlobalPolicy{}), &handler.EnqueueRequestForObject{})
+	if err = globalPolicyController.Watch(source.Kind(mgr.GetCache(), &securityv1alpha1.GlobalPolicy{}), &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+
+	if r.EverouteIPAM {
+		if err = policyController.Watch(source.Kind(mgr.GetCache(), &ipamv1alpha1.IPPool{}), handler.EnqueueRequestsFromMapFunc(r.ipPoolReferencedPolicies)); err != nil {
+			return err
+		}
+	}
+
+	if err = r.setupFQDNRefresh(mgr, policyController); err != nil {
+		return err
+	}
+
+	if err = r.setupIfaceNameRefresh(mgr, policyController); err != nil {
+		return err
+	}
+
+	if err = r.setupInitialSync(mgr); err != nil {
+		return err
+	}
+
+	if r.FlowCompaction == nil {
+		return nil
+	}
+	return mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		r.runFlowCompaction(ctx)
+		return nil
+	}))
 }
 
-func (r *Reconciler) ruleUpdateByGroup(gm *groupv1alpha1.GroupMembers) {
+// ruleUpdateByGroup updates rules reference the group with the new groupmembers. It rejects the groupmembers
+// and updates nothing if any policy expands to more rules than max rules per policy.
+func (r *Reconciler) ruleUpdateByGroup(gm *groupv1alpha1.GroupMembers) error {
 	rules, _ := r.ruleCache.ByIndex(policycache.GroupIndex, gm.GetName())
 	if len(rules) == 0 {
-		return
+		return nil
 	}
 	var oldRuleList, newRuleList []policycache.PolicyRule
+	// rules num increased of each policy, indexed by policy namespace/name
+	policyRulesIncreased := make(map[string]int)
 	for i := range rules {
 		rule := rules[i].(*policycache.CompleteRule)
-		oldRuleList = append(oldRuleList, rule.ListRules(r.groupCache)...)
+		oldRules := rule.ListRules(r.groupCache)
+		oldRuleList = append(oldRuleList, oldRules...)
 		srcIPs := r.getRuleIPBlocksForUpdateGroupMembers(rule.SrcIPs, rule.SrcGroups, gm)
 		dstIPs := r.getRuleIPBlocksForUpdateGroupMembers(rule.DstIPs, rule.DstGroups, gm)
-		newRuleList = append(newRuleList, rule.GenerateRuleList(srcIPs, dstIPs, rule.Ports)...)
+		newRules := rule.GenerateRuleList(srcIPs, dstIPs, rule.Ports)
+		newRuleList = append(newRuleList, newRules...)
+		policyRulesIncreased[policyKeyOfRuleID(rule.RuleID)] += len(newRules) - len(oldRules)
 	}
 
+	if err := r.checkPolicyRulesLimit(policyRulesIncreased); err != nil {
+		return err
+	}
 	r.syncPolicyRulesUntilSuccess(oldRuleList, newRuleList)
+	return nil
+}
+
+// checkPolicyRulesLimit returns rulesExceedLimit error if any policy expands to more rules than max rules per
+// policy after its rules increased, the rejected policies are reported by warning events.
+func (r *Reconciler) checkPolicyRulesLimit(policyRulesIncreased map[string]int) error {
+	maxRules := r.maxRulesPerPolicy.Load()
+	if maxRules <= 0 {
+		return nil
+	}
+
+	var errList []error
+	for policyKey, increased := range policyRulesIncreased {
+		if increased <= 0 {
+			continue
+		}
+		rulesNum := increased
+		completeRules, _ := r.ruleCache.ByIndex(policycache.PolicyIndex, policyKey)
+		for _, completeRule := range completeRules {
+			rulesNum += len(completeRule.(*policycache.CompleteRule).ListRules(r.groupCache))
+		}
+		if int64(rulesNum) <= maxRules {
+			continue
+		}
+		err := fmt.Errorf("policy %s expands to %d rules, exceeds max %d rules per policy", policyKey, rulesNum, maxRules)
+		r.recordPolicyEvent(policyKey, RulesExceedLimitReason, "%s", err)
+		errList = append(errList, err)
+	}
+	if len(errList) != 0 {
+		return rulesExceedLimit{errors.NewAggregate(errList)}
+	}
+	return nil
 }
 
 //nolint:all
@@ -246,6 +389,9 @@ func (r *Reconciler) cleanPolicyDependents(policy k8stypes.NamespacedName) error
 
 func (r *Reconciler) processPolicyUpdate(policy *securityv1alpha1.SecurityPolicy) (ctrl.Result, error) {
 	var oldRuleList []policycache.PolicyRule
+	syncKey := policySyncKey(k8stypes.NamespacedName{Namespace: policy.Namespace, Name: policy.Name})
+	// calculate before rules expected, so that rules change after calculating would be reconciled again
+	nextActiveTransition := nextRuleActiveTransition(policy, time.Now())
 
 	completeRules, _ := r.ruleCache.ByIndex(policycache.PolicyIndex, policy.Namespace+"/"+policy.Name)
 	for _, completeRule := range completeRules {
@@ -258,6 +404,15 @@ func (r *Reconciler) processPolicyUpdate(policy *securityv1alpha1.SecurityPolicy
 		klog.V(2).Infof("Failed to calculate expect complete rule for policy %s, %s", policy.GetName(), err)
 		return ctrl.Result{RequeueAfter: time.Nanosecond}, nil
 	}
+	if isRulesExceedLimit(err) {
+		// the policy would be reconciled again when updated or after retry interval
+		klog.Errorf("reject policy %s/%s, keep rules installed before: %s", policy.Namespace, policy.Name, err)
+		if r.recorder != nil {
+			r.recorder.Event(policy, corev1.EventTypeWarning, RulesExceedLimitReason, err.Error())
+		}
+		r.initialSync.Reconciled(syncKey)
+		return ctrl.Result{RequeueAfter: RulesExceedLimitRetryInterval}, nil
+	}
 	if err != nil {
 		klog.Errorf("failed fetch new policy %s rules: %s", policy.Name, err)
 		return ctrl.Result{}, err
@@ -265,8 +420,10 @@ func (r *Reconciler) processPolicyUpdate(policy *securityv1alpha1.SecurityPolicy
 
 	// start a force full synchronization of policyrule
 	r.syncPolicyRulesUntilSuccess(oldRuleList, newRuleList)
+	r.initialSync.Reconciled(syncKey)
 
-	return ctrl.Result{}, nil
+	// reconcile again when any rule becomes active or inactive
+	return ctrl.Result{RequeueAfter: nextActiveTransition}, nil
 }
 
 func (r *Reconciler) calculateExpectedPolicyRules(policy *securityv1alpha1.SecurityPolicy) ([]policycache.PolicyRule, error) {
@@ -274,18 +431,34 @@ func (r *Reconciler) calculateExpectedPolicyRules(policy *securityv1alpha1.Secur
 
 	completeRules, err := r.completePolicy(policy)
 	if err != nil {
-		return policyRuleList, fmt.Errorf("flatten policy %s: %s", policy.Name, err)
+		return policyRuleList, fmt.Errorf("flatten policy %s: %w", policy.Name, err)
 	}
 
-	// todo: replace delete and add completeRules with update
+	// rules compacted keep compacted, otherwise rules compacted would be expanded on every policy sync
 	oldCompleteRules, _ := r.ruleCache.ByIndex(policycache.PolicyIndex, policy.Namespace+"/"+policy.Name)
+	compactedRules := sets.New[string]()
+	for _, oldCompleteRule := range oldCompleteRules {
+		if oldCompleteRule.(*policycache.CompleteRule).Compact {
+			compactedRules.Insert(oldCompleteRule.(*policycache.CompleteRule).RuleID)
+		}
+	}
+
+	for _, completeRule := range completeRules {
+		completeRule.Compact = compactedRules.Has(completeRule.RuleID)
+		policyRuleList = append(policyRuleList, completeRule.ListRules(r.groupCache)...)
+	}
+	// check before update cache, rules of the rejected policy keep the same
+	if maxRules := r.maxRulesPerPolicy.Load(); maxRules > 0 && int64(len(policyRuleList)) > maxRules {
+		return nil, rulesExceedLimit{fmt.Errorf("policy %s expands to %d rules, exceeds max %d rules per policy", policy.Name, len(policyRuleList), maxRules)}
+	}
+
+	// todo: replace delete and add completeRules with update
 	for _, oldCompleteRule := range oldCompleteRules {
 		_ = r.ruleCache.Delete(oldCompleteRule)
 	}
 
 	for _, completeRule := range completeRules {
 		_ = r.ruleCache.Add(completeRule)
-		policyRuleList = append(policyRuleList, completeRule.ListRules(r.groupCache)...)
 	}
 
 	return policyRuleList, nil
@@ -309,18 +482,32 @@ func (r *Reconciler) completePolicy(policy *securityv1alpha1.SecurityPolicy) ([]
 	var completeRules []*policycache.CompleteRule
 	var ingressEnabled, egressEnabled = policy.IsEnable()
 	ruleAction := policycache.RuleActionAllow
+	now := time.Now()
+	priority := r.policyPriority(policy)
 	if policy.Spec.IsBlocklist {
 		ruleAction = policycache.RuleActionDrop
 	}
 
 	appliedToPeer := make([]securityv1alpha1.SecurityPolicyPeer, 0, len(policy.Spec.AppliedTo))
+	appliedIfaceIPs := sets.New[string]()
 	for _, appliedTo := range policy.Spec.AppliedTo {
+		if appliedTo.InterfaceName != nil {
+			// interface name resolved by local endpoints instead of groups
+			appliedIfaceIPs.Insert(r.ifaceNameCache.Get(*appliedTo.InterfaceName)...)
+			continue
+		}
+		if appliedTo.VlanRange != nil {
+			// vlan range resolved by local endpoints vlan id instead of groups
+			appliedIfaceIPs.Insert(r.vlanRangeCache.Get(*appliedTo.VlanRange)...)
+			continue
+		}
 		appliedToPeer = append(appliedToPeer, ctrlpolicy.AppliedAsSecurityPeer(policy.GetNamespace(), appliedTo))
 	}
 	appliedGroups, appliedIPs, err := r.getPeersGroupsAndIPs(policy.GetNamespace(), appliedToPeer)
 	if err != nil {
 		return nil, err
 	}
+	appliedIPs = appliedIPs.Union(appliedIfaceIPs)
 
 	// if apply to is nil or empty, add all ips
 	if len(policy.Spec.AppliedTo) == 0 {
@@ -329,16 +516,25 @@ func (r *Reconciler) completePolicy(policy *securityv1alpha1.SecurityPolicy) ([]
 
 	if ingressEnabled {
 		for _, rule := range policy.Spec.IngressRules {
+			if !rule.IsActive(now) {
+				continue
+			}
 			ingressRuleTmpl := &policycache.CompleteRule{
 				RuleID:          fmt.Sprintf("%s/%s/%s/%s.%s", policy.Namespace, policy.Name, policycache.NormalPolicy, "ingress", rule.Name),
 				Tier:            policy.Spec.Tier,
-				Priority:        policy.Spec.Priority,
-				EnforcementMode: policy.Spec.SecurityPolicyEnforcementMode.String(),
+				Priority:        priority,
+				EnforcementMode: ruleEnforcementMode(policy, &rule),
+				TrafficLocality: string(rule.TrafficLocality),
+				VlanID:          rule.GetVlanID(),
 				Action:          ruleAction,
 				Direction:       policycache.RuleDirectionIn,
-				SymmetricMode:   policy.Spec.SymmetricMode,
+				SymmetricMode:   policy.IsSymmetric(networkingv1.PolicyTypeIngress),
 				DstGroups:       appliedGroups.Clone(),
 				DstIPs:          appliedIPs.Clone(),
+				LoggingTags:     ruleLoggingTags(policy, &rule),
+				Logged:          ruleLogged(policy, &rule, ruleAction),
+				LogSampleRate:   ruleLogSampleRate(policy, &rule, ruleAction),
+				RuleGroup:       policy.Labels[constants.RuleGroupLabelKey],
 			}
 
 			ingressRuleTmpl.Ports, err = FlattenPorts(rule.Ports)
@@ -361,10 +557,12 @@ func (r *Reconciler) completePolicy(policy *securityv1alpha1.SecurityPolicy) ([]
 		}
 
 		if policy.Spec.DefaultRule == securityv1alpha1.DefaultRuleDrop {
+			// the default rule takes the default rule logging as its rule logging, the policy logging if not set
+			defaultIngressLogging := &securityv1alpha1.Rule{Logging: policy.Spec.DefaultIngressRuleLogging}
 			defaultIngressRule := &policycache.CompleteRule{
 				RuleID:            fmt.Sprintf("%s/%s/%s/%s.%s", policy.Namespace, policy.Name, policycache.NormalPolicy, "default", "ingress"),
 				Tier:              policy.Spec.Tier,
-				Priority:          policy.Spec.Priority,
+				Priority:          priority,
 				EnforcementMode:   policy.Spec.SecurityPolicyEnforcementMode.String(),
 				Action:            policycache.RuleActionDrop,
 				Direction:         policycache.RuleDirectionIn,
@@ -374,6 +572,11 @@ func (r *Reconciler) completePolicy(policy *securityv1alpha1.SecurityPolicy) ([]
 				DstIPs:            appliedIPs.Clone(),
 				SrcIPs:            sets.New[string](""),       // matches all source IP
 				Ports:             []policycache.RulePort{{}}, // has a port matches all ports
+				LoggingTags:       ruleLoggingTags(policy, defaultIngressLogging),
+				Logged:            ruleLogged(policy, defaultIngressLogging, policycache.RuleActionDrop),
+				LogSampleRate:     ruleLogSampleRate(policy, defaultIngressLogging, policycache.RuleActionDrop),
+				RuleGroup:         policy.Labels[constants.RuleGroupLabelKey],
+				BlockARP:          policy.Spec.BlockARP,
 			}
 			completeRules = append(completeRules, defaultIngressRule)
 		}
@@ -381,16 +584,25 @@ func (r *Reconciler) completePolicy(policy *securityv1alpha1.SecurityPolicy) ([]
 
 	if egressEnabled {
 		for _, rule := range policy.Spec.EgressRules {
+			if !rule.IsActive(now) {
+				continue
+			}
 			egressRuleTmpl := &policycache.CompleteRule{
 				RuleID:          fmt.Sprintf("%s/%s/%s/%s.%s", policy.Namespace, policy.Name, policycache.NormalPolicy, "egress", rule.Name),
 				Tier:            policy.Spec.Tier,
-				Priority:        policy.Spec.Priority,
-				EnforcementMode: policy.Spec.SecurityPolicyEnforcementMode.String(),
+				Priority:        priority,
+				EnforcementMode: ruleEnforcementMode(policy, &rule),
+				TrafficLocality: string(rule.TrafficLocality),
+				VlanID:          rule.GetVlanID(),
 				Action:          ruleAction,
 				Direction:       policycache.RuleDirectionOut,
-				SymmetricMode:   policy.Spec.SymmetricMode,
+				SymmetricMode:   policy.IsSymmetric(networkingv1.PolicyTypeEgress),
 				SrcGroups:       appliedGroups.Clone(),
 				SrcIPs:          appliedIPs.Clone(),
+				LoggingTags:     ruleLoggingTags(policy, &rule),
+				Logged:          ruleLogged(policy, &rule, ruleAction),
+				LogSampleRate:   ruleLogSampleRate(policy, &rule, ruleAction),
+				RuleGroup:       policy.Labels[constants.RuleGroupLabelKey],
 			}
 
 			if len(rule.To) > 0 {
@@ -440,10 +652,12 @@ func (r *Reconciler) completePolicy(policy *securityv1alpha1.SecurityPolicy) ([]
 		}
 
 		if policy.Spec.DefaultRule == securityv1alpha1.DefaultRuleDrop {
+			// the default rule takes the default rule logging as its rule logging, the policy logging if not set
+			defaultEgressLogging := &securityv1alpha1.Rule{Logging: policy.Spec.DefaultEgressRuleLogging}
 			defaultEgressRule := &policycache.CompleteRule{
 				RuleID:            fmt.Sprintf("%s/%s/%s/%s.%s", policy.Namespace, policy.Name, policycache.NormalPolicy, "default", "egress"),
 				Tier:              policy.Spec.Tier,
-				Priority:          policy.Spec.Priority,
+				Priority:          priority,
 				EnforcementMode:   policy.Spec.SecurityPolicyEnforcementMode.String(),
 				Action:            policycache.RuleActionDrop,
 				Direction:         policycache.RuleDirectionOut,
@@ -453,6 +667,11 @@ func (r *Reconciler) completePolicy(policy *securityv1alpha1.SecurityPolicy) ([]
 				SrcIPs:            appliedIPs.Clone(),
 				DstIPs:            sets.New[string](""),       // matches all destination IP
 				Ports:             []policycache.RulePort{{}}, // has a port matches all ports
+				LoggingTags:       ruleLoggingTags(policy, defaultEgressLogging),
+				Logged:            ruleLogged(policy, defaultEgressLogging, policycache.RuleActionDrop),
+				LogSampleRate:     ruleLogSampleRate(policy, defaultEgressLogging, policycache.RuleActionDrop),
+				RuleGroup:         policy.Labels[constants.RuleGroupLabelKey],
+				BlockARP:          policy.Spec.BlockARP,
 			}
 			completeRules = append(completeRules, defaultEgressRule)
 		}
@@ -468,7 +687,36 @@ func (r *Reconciler) getCompleteRulesByParseSymmetricMode(ruleTmpl *policycache.
 		return rules, nil
 	}
 
-	if !policy.Spec.SymmetricMode {
+	localPeers, peers := splitLocalEndpointsPeers(peers)
+	if len(localPeers) != 0 && ruleTmpl.TrafficLocality != string(securityv1alpha1.TrafficLocalityInterNode) {
+		// ips of local endpoints could be spoofed by remote peers, the LocalEndpoints peers are matched
+		// in a separate rule limited to intra node traffic
+		localRuleTmpl := ruleTmpl.Clone()
+		localRuleTmpl.RuleID = fmt.Sprintf("%s.local", ruleTmpl.RuleID)
+		localRuleTmpl.TrafficLocality = string(securityv1alpha1.TrafficLocalityIntraNode)
+		localRules, err := r.getCompleteRulesByPeers(localRuleTmpl, policy, policyType, localPeers)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, localRules...)
+	}
+	if len(peers) == 0 {
+		return rules, nil
+	}
+
+	peerRules, err := r.getCompleteRulesByPeers(ruleTmpl, policy, policyType, peers)
+	if err != nil {
+		return nil, err
+	}
+	return append(rules, peerRules...), nil
+}
+
+// getCompleteRulesByPeers generates rules of the peers from the rule template, the peers with symmetric
+// mode and without are generated in different rules if the policy is symmetric
+func (r *Reconciler) getCompleteRulesByPeers(ruleTmpl *policycache.CompleteRule, policy *securityv1alpha1.SecurityPolicy,
+	policyType networkingv1.PolicyType, peers []securityv1alpha1.SecurityPolicyPeer) ([]*policycache.CompleteRule, error) {
+	var rules []*policycache.CompleteRule
+	if !policy.IsSymmetric(policyType) {
 		groups, ips, err := r.getPeersGroupsAndIPs(policy.Namespace, peers)
 		if err != nil {
 			return nil, err
@@ -508,6 +756,19 @@ func (r *Reconciler) getCompleteRulesByParseSymmetricMode(ruleTmpl *policycache.
 	return rules, nil
 }
 
+// splitLocalEndpointsPeers splits the LocalEndpoints endpoint network peers from the other peers
+func splitLocalEndpointsPeers(peers []securityv1alpha1.SecurityPolicyPeer) ([]securityv1alpha1.SecurityPolicyPeer, []securityv1alpha1.SecurityPolicyPeer) {
+	var localPeers, otherPeers []securityv1alpha1.SecurityPolicyPeer
+	for _, peer := range peers {
+		if peer.EndpointNetwork != nil && *peer.EndpointNetwork == securityv1alpha1.EndpointNetworkLocalEndpoints {
+			localPeers = append(localPeers, peer)
+			continue
+		}
+		otherPeers = append(otherPeers, peer)
+	}
+	return localPeers, otherPeers
+}
+
 // getPeersGroupsAndIPBlocks get ipBlocks from groups, return unique ipBlock list
 func (r *Reconciler) getPeersGroupsAndIPs(namespace string,
 	peers []securityv1alpha1.SecurityPolicyPeer, matchSymmetric ...bool) (sets.Set[string], sets.Set[string], error) {
@@ -536,11 +797,21 @@ func (r *Reconciler) getPeersGroupsAndIPs(namespace string,
 			for i := range ipNets {
 				ips.Insert(ipNets[i].String())
 			}
+		case peer.EndpointNetwork != nil:
+			ips.Insert(r.resolveEndpointNetwork(*peer.EndpointNetwork)...)
+		case peer.IPPool != nil:
+			poolIPs, err := r.resolveIPPool(*peer.IPPool)
+			if err != nil {
+				return nil, nil, err
+			}
+			ips.Insert(poolIPs...)
+		case peer.FQDN != "":
+			ips.Insert(r.resolveFQDN(peer.FQDN)...)
 		case peer.Endpoint != nil || peer.EndpointSelector != nil || peer.NamespaceSelector != nil:
 			group := ctrlpolicy.PeerAsEndpointGroup(namespace, peer).GetName()
 			_, exist := r.groupCache.ListGroupIPBlocks(group)
 			if !exist {
-				return nil, nil, groupNotFound(fmt.Errorf("group %s members not found", group))
+				return nil, nil, groupNotFound{fmt.Errorf("group %s members not found", group)}
 			}
 			groups.Insert(group)
 		default:
@@ -551,18 +822,130 @@ func (r *Reconciler) getPeersGroupsAndIPs(namespace string,
 	return groups, ips, nil
 }
 
+// resolveEndpointNetwork resolves the endpoint network peer by the endpoint network cache, so that policies
+// are reconciled again when the resolved ips changed, e.g. the gateway of endpoints changed.
+func (r *Reconciler) resolveEndpointNetwork(network securityv1alpha1.EndpointNetworkType) []string {
+	return r.endpointNetworkCache.Get(network)
+}
+
+// resolveEndpointNetworkIPs resolves the endpoint network peer with the gateway of endpoints on this agent,
+// it resolves nothing when the gateway is unknown, e.g. agent not works as cni. The LocalEndpoints peer
+// resolves with ips of endpoints on this agent, the rule of it is limited to intra node traffic.
+func (r *Reconciler) resolveEndpointNetworkIPs(network securityv1alpha1.EndpointNetworkType) []string {
+	if network == securityv1alpha1.EndpointNetworkLocalEndpoints {
+		return r.DatapathManager.GetLocalEndpointIPs()
+	}
+
+	gatewayIP, gatewayMask := r.DatapathManager.Info.GatewayIP, r.DatapathManager.Info.GatewayMask
+	if gatewayIP == nil {
+		klog.Warningf("gateway of endpoints unknown, endpoint network %s resolves nothing", network)
+		return nil
+	}
+
+	switch network {
+	case securityv1alpha1.EndpointNetworkGateway:
+		maskLen := net.IPv6len * 8
+		if gatewayIP.To4() != nil {
+			gatewayIP, maskLen = gatewayIP.To4(), net.IPv4len*8
+		}
+		return []string{(&net.IPNet{IP: gatewayIP, Mask: net.CIDRMask(maskLen, maskLen)}).String()}
+	case securityv1alpha1.EndpointNetworkSubnet:
+		if gatewayMask == nil {
+			klog.Warningf("subnet of endpoints unknown, endpoint network %s resolves nothing", network)
+			return nil
+		}
+		return []string{(&net.IPNet{IP: gatewayIP.Mask(gatewayMask), Mask: gatewayMask}).String()}
+	default:
+		klog.Errorf("unknown endpoint network %s", network)
+		return nil
+	}
+}
+
+// resolveIPPool resolves the ipPool peer with the allocatable cidr of the pool, or the subnet of the pool
+// if the pool allocates from ip range. It resolves nothing when the pool not found, the policy would be
+// reconciled again when the pool created.
+func (r *Reconciler) resolveIPPool(name securityv1alpha1.NamespacedName) ([]string, error) {
+	if !r.EverouteIPAM {
+		klog.Warningf("everoute ipam disabled, ipPool %s resolves nothing", name)
+		return nil, nil
+	}
+
+	var pool ipamv1alpha1.IPPool
+	err := r.Get(context.Background(), k8stypes.NamespacedName{Namespace: name.Namespace, Name: name.Name}, &pool)
+	if apierrors.IsNotFound(err) {
+		klog.Warningf("ipPool %s not found, resolves nothing", name)
+		return nil, nil
+	}
+	if err != nil {
+		klog.Errorf("unable to fetch ipPool %s: %s", name, err)
+		return nil, err
+	}
+
+	ipBlock := &networkingv1.IPBlock{CIDR: pool.Spec.Subnet}
+	if pool.Spec.CIDR != "" {
+		ipBlock = &networkingv1.IPBlock{CIDR: pool.Spec.CIDR, Except: pool.Spec.Except}
+	}
+	ipNets, err := utils.ParseIPBlock(ipBlock)
+	if err != nil {
+		klog.Errorf("unable parse ipPool %s %+v: %s", name, ipBlock, err)
+		return nil, err
+	}
+	ips := make([]string, 0, len(ipNets))
+	for i := range ipNets {
+		ips = append(ips, ipNets[i].String())
+	}
+	return ips, nil
+}
+
+// ipPoolReferencedPolicies returns requests of policies which peers reference the ipPool
+func (r *Reconciler) ipPoolReferencedPolicies(ctx context.Context, pool client.Object) []reconcile.Request {
+	var policyList securityv1alpha1.SecurityPolicyList
+	if err := r.List(ctx, &policyList); err != nil {
+		klog.Errorf("unable to list policies: %s", err)
+		return nil
+	}
+
+	poolName := securityv1alpha1.NamespacedName{Namespace: pool.GetNamespace(), Name: pool.GetName()}
+	var requests []reconcile.Request
+	for i := range policyList.Items {
+		policy := &policyList.Items[i]
+		var peers []securityv1alpha1.SecurityPolicyPeer
+		for _, rule := range policy.Spec.IngressRules {
+			peers = append(peers, rule.From...)
+		}
+		for _, rule := range policy.Spec.EgressRules {
+			peers = append(peers, rule.To...)
+		}
+		for _, peer := range peers {
+			if peer.IPPool != nil && *peer.IPPool == poolName {
+				requests = append(requests, reconcile.Request{NamespacedName: k8stypes.NamespacedName{Namespace: policy.Namespace, Name: policy.Name}})
+				break
+			}
+		}
+	}
+	return requests
+}
+
 func (r *Reconciler) getAllEpWithNamedPortGroup() (sets.Set[string], error) {
 	group := ctrlpolicy.GetAllEpWithNamedPortGroup().GetName()
 	_, exist := r.groupCache.ListGroupIPBlocks(group)
 	if !exist {
-		return nil, groupNotFound(fmt.Errorf("group %s members not found", group))
+		return nil, groupNotFound{fmt.Errorf("group %s members not found", group)}
 	}
 
 	return sets.New[string](group), nil
 }
 
 func (r *Reconciler) syncPolicyRulesUntilSuccess(oldRuleList, newRuleList []policycache.PolicyRule) {
-	var err = r.compareAndApplyPolicyRulesChanges(oldRuleList, newRuleList)
+	r.syncPolicyRulesWithContextUntilSuccess(context.Background(), oldRuleList, newRuleList)
+}
+
+func (r *Reconciler) syncPolicyRulesWithContextUntilSuccess(ctx context.Context, oldRuleList, newRuleList []policycache.PolicyRule) {
+	// clean conntrack of the rules changed once after all of them applied
+	ctx, ctBatch := r.DatapathManager.BeginConntrackBatch(ctx)
+	defer ctBatch.Commit()
+
+	var err = r.compareAndApplyPolicyRulesChanges(ctx, oldRuleList, newRuleList)
 	var rateLimiter = workqueue.NewItemExponentialFailureRateLimiter(time.Microsecond, time.Second)
 	var timeout = time.Minute * 5
 	var deadline = time.Now().Add(timeout)
@@ -576,16 +959,17 @@ func (r *Reconciler) syncPolicyRulesUntilSuccess(oldRuleList, newRuleList []poli
 		klog.Errorf("failed to sync policyRules, next sync after %s: %s", duration, err)
 		time.Sleep(duration)
 
-		err = r.compareAndApplyPolicyRulesChanges(oldRuleList, newRuleList)
+		err = r.compareAndApplyPolicyRulesChanges(ctx, oldRuleList, newRuleList)
 	}
 }
 
-func (r *Reconciler) compareAndApplyPolicyRulesChanges(oldRuleList, newRuleList []policycache.PolicyRule) error {
+func (r *Reconciler) compareAndApplyPolicyRulesChanges(ctx context.Context, oldRuleList, newRuleList []policycache.PolicyRule) error {
 	var (
-		errList    []error
-		newRuleMap = toRuleMap(newRuleList)
-		oldRuleMap = toRuleMap(oldRuleList)
-		allRuleSet = sets.StringKeySet(newRuleMap).Union(sets.StringKeySet(oldRuleMap))
+		addRules    []datapath.RuleSpec
+		removeRules []datapath.RuleRef
+		newRuleMap  = toRuleMap(newRuleList)
+		oldRuleMap  = toRuleMap(oldRuleList)
+		allRuleSet  = sets.StringKeySet(newRuleMap).Union(sets.StringKeySet(oldRuleMap))
 	)
 
 	for ruleName := range allRuleSet {
@@ -596,41 +980,62 @@ func (r *Reconciler) compareAndApplyPolicyRulesChanges(oldRuleList, newRuleList
 			if oldExist && ruleIsSame(oldRule, newRule) {
 				continue
 			}
+			ruleSpec, ok := r.toRuleSpec(flowKeyFromRuleName(newRule.Name), newRule)
+			if !ok {
+				continue
+			}
 			klog.Infof("create policyRule: %v", newRule)
-			errList = append(errList,
-				r.processPolicyRuleAdd(newRule),
-			)
+			addRules = append(addRules, ruleSpec)
 
 		} else if oldExist {
 			klog.Infof("remove policyRule: %v", oldRule)
-			errList = append(errList,
-				r.processPolicyRuleDelete(oldRule.Name),
-			)
+			removeRules = append(removeRules, datapath.RuleRef{
+				RuleID:   flowKeyFromRuleName(oldRule.Name),
+				RuleName: oldRule.Name,
+			})
 		}
 	}
 
-	return errors.NewAggregate(errList)
+	// install new rules before removing old rules, so that rules referenced by both never been removed
+	return errors.NewAggregate([]error{
+		r.processPolicyRulesAdd(ctx, addRules),
+		r.processPolicyRulesDelete(ctx, removeRules),
+	})
 }
 
-func (r *Reconciler) processPolicyRuleDelete(ruleName string) error {
-	return r.DatapathManager.RemoveEveroutePolicyRule(flowKeyFromRuleName(ruleName), ruleName)
+func (r *Reconciler) processPolicyRulesDelete(ctx context.Context, rules []datapath.RuleRef) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	return r.DatapathManager.RemoveEveroutePolicyRules(ctx, rules)
 }
 
-func (r *Reconciler) processPolicyRuleAdd(policyRule *policycache.PolicyRule) error {
-
-	klog.Infof("add rule %s to datapath", policyRule.Name)
-	if err := r.addPolicyRuleToDatapath(flowKeyFromRuleName(policyRule.Name), policyRule); err != nil {
-		return err
+func (r *Reconciler) processPolicyRulesAdd(ctx context.Context, rules []datapath.RuleSpec) error {
+	if len(rules) == 0 {
+		return nil
 	}
-
-	return nil
+	klog.Infof("add %d rules to datapath", len(rules))
+	return r.DatapathManager.AddEveroutePolicyRules(ctx, rules)
 }
 
-func (r *Reconciler) addPolicyRuleToDatapath(ruleID string, rule *policycache.PolicyRule) error {
-	// Process PolicyRule: convert it to everoutePolicyRule, filter illegal PolicyRule; install everoutePolicyRule flow
-	everoutePolicyRule := toEveroutePolicyRule(ruleID, rule)
-	ruleDirection := getRuleDirection(rule.Direction)
-	ruleTier := getRuleTier(rule.Tier)
-
-	return r.DatapathManager.AddEveroutePolicyRule(everoutePolicyRule, rule.Name, ruleDirection, ruleTier, rule.EnforcementMode)
+func (r *Reconciler) toRuleSpec(ruleID string, rule *policycache.PolicyRule) (datapath.RuleSpec, bool) {
+	tier, ok := r.getRuleTier(rule)
+	if !ok {
+		return datapath.RuleSpec{}, false
+	}
+	if !r.trafficLocalitySupported(rule) {
+		return datapath.RuleSpec{}, false
+	}
+	// Process PolicyRule: convert it to everoutePolicyRule, filter illegal PolicyRule
+	return datapath.RuleSpec{
+		Rule:      toEveroutePolicyRule(ruleID, rule),
+		RuleName:  rule.Name,
+		Direction: getRuleDirection(rule.Direction),
+		Tier:      tier,
+		Mode:      rule.EnforcementMode,
+
+		LoggingTags: rule.LoggingTags,
+		RuleGroup:   rule.RuleGroup,
+		BlockARP:    rule.BlockARP,
+	}, true
 }
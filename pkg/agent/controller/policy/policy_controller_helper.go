@@ -17,11 +17,15 @@ limitations under the License.
 package policy
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
-	"runtime/debug"
 	"strings"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog"
 
 	policycache "github.com/everoute/everoute/pkg/agent/controller/policy/cache"
@@ -59,6 +63,14 @@ func toEveroutePolicyRule(ruleID string, rule *policycache.PolicyRule) *datapath
 		DstPort:     rule.DstPort,
 		DstPortMask: rule.DstPortMask,
 		Action:      ruleAction,
+		ICMPType:    rule.ICMPType,
+		ICMPCode:    rule.ICMPCode,
+
+		TrafficLocality: getTrafficLocality(rule.TrafficLocality),
+		VlanID:          rule.VlanID,
+
+		Logged:        rule.Logged,
+		LogSampleRate: rule.LogSampleRate,
 	}
 
 	return everoutePolicyRule
@@ -77,6 +89,10 @@ func protocolToInt(ipProtocol string) uint8 {
 		protoNo = 4
 	case "VRRP":
 		protoNo = 112
+	case "GRE":
+		protoNo = 47
+	case "ICMPv6":
+		protoNo = 58
 	case "":
 		protoNo = 0
 	default:
@@ -85,6 +101,17 @@ func protocolToInt(ipProtocol string) uint8 {
 	return protoNo
 }
 
+func getTrafficLocality(locality string) string {
+	switch securityv1alpha1.TrafficLocality(locality) {
+	case securityv1alpha1.TrafficLocalityIntraNode:
+		return datapath.TrafficLocalityIntraNode
+	case securityv1alpha1.TrafficLocalityInterNode:
+		return datapath.TrafficLocalityInterNode
+	default:
+		return ""
+	}
+}
+
 func getRuleAction(ruleAction policycache.RuleAction) string {
 	var action string
 	switch ruleAction {
@@ -92,6 +119,8 @@ func getRuleAction(ruleAction policycache.RuleAction) string {
 		action = "allow"
 	case policycache.RuleActionDrop:
 		action = "deny"
+	case policycache.RuleActionReject:
+		action = "reject"
 	default:
 		klog.Fatalf("unsupport ruleAction %s in policyrule.", ruleAction)
 		return action
@@ -112,22 +141,64 @@ func getRuleDirection(ruleDir policycache.RuleDirection) uint8 {
 	return direction
 }
 
-func getRuleTier(ruleTier string) uint8 {
-	var tier uint8
-	switch ruleTier {
-	case constants.Tier0:
-		tier = datapath.POLICY_TIER1
-	case constants.Tier1:
-		tier = datapath.POLICY_TIER2
-	case constants.Tier2:
-		tier = datapath.POLICY_TIER3
-	case constants.TierECP:
-		tier = datapath.POLICY_TIER_ECP
-	default:
-		debug.PrintStack()
-		klog.Fatalf("unsupport ruleTier %s in policyRule.", ruleTier)
+// getRuleTier returns the tier of datapath rule by the tier name in the configured policy tiers, the rule
+// with unknown tier is reported by error log, warning event of the policy and metric, and false returned.
+func (r *Reconciler) getRuleTier(rule *policycache.PolicyRule) (uint8, bool) {
+	tier, ok := r.DatapathManager.PolicyTierID(rule.Tier)
+	if ok {
+		return tier, true
+	}
+
+	klog.Errorf("skip policyRule %s with unknown tier %s", rule.Name, rule.Tier)
+	r.DatapathManager.AgentMetric.IncUnknownTierRuleSkipped(rule.Tier)
+	r.recordRuleEvent(rule, UnknownRuleTierReason, "skip rule %s with unknown tier %s", rule.Name, rule.Tier)
+	return 0, false
+}
+
+// trafficLocalitySupported returns false if the rule limited to traffic locality is installed without overlay,
+// the traffic locality is unknown in other modes, the rule is reported by error log and warning event.
+func (r *Reconciler) trafficLocalitySupported(rule *policycache.PolicyRule) bool {
+	if rule.TrafficLocality == "" || r.DatapathManager.IsEnableOverlay() {
+		return true
+	}
+
+	klog.Errorf("skip policyRule %s with traffic locality %s, only supported in overlay mode", rule.Name, rule.TrafficLocality)
+	r.recordRuleEvent(rule, UnsupportedTrafficLocalityReason, "skip rule %s with traffic locality %s, only supported in overlay mode",
+		rule.Name, rule.TrafficLocality)
+	return false
+}
+
+// recordRuleEvent records warning event of the policy the rule belongs to
+func (r *Reconciler) recordRuleEvent(rule *policycache.PolicyRule, reason, messageFmt string, args ...interface{}) {
+	r.recordPolicyEvent(rule.Name, reason, messageFmt, args...)
+}
+
+// recordPolicyEvent records warning event of the policy, the policyKey could be the policy namespace/name or
+// the rule id or name prefixed with it
+func (r *Reconciler) recordPolicyEvent(policyKey, reason, messageFmt string, args ...interface{}) {
+	if r.recorder == nil {
+		return
+	}
+	// rule name format like: policyNamespace/policyName/policyType/ruleName-flowKey
+	if keys := strings.Split(policyKey, "/"); len(keys) >= 2 {
+		policyRef := &corev1.ObjectReference{
+			APIVersion: securityv1alpha1.SchemeGroupVersion.String(),
+			Kind:       "SecurityPolicy",
+			Namespace:  keys[0],
+			Name:       keys[1],
+		}
+		r.recorder.Eventf(policyRef, corev1.EventTypeWarning, reason, messageFmt, args...)
 	}
-	return tier
+}
+
+// policyKeyOfRuleID returns policy namespace/name of the rule, rule id format like:
+// policyNamespace/policyName/policyType/ruleName
+func policyKeyOfRuleID(ruleID string) string {
+	keys := strings.SplitN(ruleID, "/", 3)
+	if len(keys) < 2 {
+		return ruleID
+	}
+	return keys[0] + "/" + keys[1]
 }
 
 func flowKeyFromRuleName(ruleName string) string {
@@ -136,53 +207,103 @@ func flowKeyFromRuleName(ruleName string) string {
 	return keys[len(keys)-1]
 }
 
-func ruleIsSame(r1, r2 *policycache.PolicyRule) bool {
-	return r1 != nil && r2 != nil && reflect.DeepEqual(r1, r2)
+// ruleLoggingTags returns logging tags of the rule, the policy logging tags are used if the rule has no logging
+func ruleLoggingTags(policy *securityv1alpha1.SecurityPolicy, rule *securityv1alpha1.Rule) map[string]string {
+	if rule != nil && rule.Logging != nil {
+		return rule.Logging.Tags
+	}
+	if policy.Spec.Logging != nil {
+		return policy.Spec.Logging.Tags
+	}
+	return nil
+}
+
+// ruleLogged returns whether packets matched the rule with the action should be logged, the policy logging
+// is used if the rule has no logging
+func ruleLogged(policy *securityv1alpha1.SecurityPolicy, rule *securityv1alpha1.Rule, action policycache.RuleAction) bool {
+	logging := policy.Spec.Logging
+	if rule != nil && rule.Logging != nil {
+		logging = rule.Logging
+	}
+	return actionLogged(logging, action)
 }
 
-func posToMask(pos int) uint16 {
-	var ret uint16 = 0xffff
-	for i := 16; i > pos; i-- {
-		ret <<= 1
+// actionLogged returns whether packets with the action should be logged by the logging options
+func actionLogged(logging *securityv1alpha1.Logging, action policycache.RuleAction) bool {
+	if action == policycache.RuleActionAllow {
+		return logging.IsAllowEnabled()
 	}
+	return logging.IsDropEnabled()
+}
 
-	return ret
+// ruleLogSampleRate returns the percentage of connections matched the rule with the action should be logged, the
+// policy logging is used if the rule has no logging
+func ruleLogSampleRate(policy *securityv1alpha1.SecurityPolicy, rule *securityv1alpha1.Rule, action policycache.RuleAction) int32 {
+	logging := policy.Spec.Logging
+	if rule != nil && rule.Logging != nil {
+		logging = rule.Logging
+	}
+	return actionLogSampleRate(logging, action)
+}
+
+// actionLogSampleRate returns the percentage of connections with the action should be logged by the logging options
+func actionLogSampleRate(logging *securityv1alpha1.Logging, action policycache.RuleAction) int32 {
+	if action == policycache.RuleActionAllow {
+		return logging.GetAllowSampleRate()
+	}
+	return logging.GetDropSampleRate()
+}
+
+// ruleEnforcementMode returns enforcement mode of the rule, the policy enforcement mode is used if the rule has no mode
+func ruleEnforcementMode(policy *securityv1alpha1.SecurityPolicy, rule *securityv1alpha1.Rule) string {
+	if rule != nil && rule.EnforcementMode != "" {
+		return rule.EnforcementMode.String()
+	}
+	return policy.Spec.SecurityPolicyEnforcementMode.String()
+}
+
+// nextRuleActiveTransition returns the duration until the next time any rule of the policy becomes active
+// or inactive, returns 0 if no rule would change after now
+func nextRuleActiveTransition(policy *securityv1alpha1.SecurityPolicy, now time.Time) time.Duration {
+	var next time.Duration
+	for _, rule := range append(append([]securityv1alpha1.Rule{}, policy.Spec.IngressRules...), policy.Spec.EgressRules...) {
+		for _, boundary := range []*metav1.Time{rule.ActiveFrom, rule.ActiveUntil} {
+			if boundary == nil || !boundary.After(now) {
+				continue
+			}
+			if d := boundary.Sub(now); next == 0 || d < next {
+				next = d
+			}
+		}
+	}
+	return next
+}
+
+func ruleIsSame(r1, r2 *policycache.PolicyRule) bool {
+	return r1 != nil && r2 != nil && reflect.DeepEqual(r1, r2)
 }
 
 func calPortRangeMask(begin uint16, end uint16, protocol securityv1alpha1.Protocol) []policycache.RulePort {
 	var rulePortList []policycache.RulePort
 
+	// single port 0 means match all ports
 	if begin == 0 && end == 0 {
 		return append(rulePortList, policycache.RulePort{
 			Protocol: protocol,
 			DstPort:  0,
 		})
 	}
+	// port 0 in a port range isn't a valid port, masks covering it would match all ports in datapath
+	if begin == 0 {
+		begin = 1
+	}
 
-	var pos int
-	for begin <= end && begin != 0 {
-		// find "1" pos from right
-		var temp = begin
-		pos = 16
-		for {
-			if temp%2 == 1 {
-				break
-			}
-			temp >>= 1
-			pos--
-		}
-		// check from pos to end
-		for i := pos; i <= 16; i++ {
-			if end >= begin+(1<<(16-i))-1 {
-				rulePortList = append(rulePortList, policycache.RulePort{
-					Protocol:    protocol,
-					DstPort:     begin,
-					DstPortMask: posToMask(i),
-				})
-				begin += 1 << (16 - i)
-				break
-			}
-		}
+	for _, portMask := range datapath.PortRangeToMasks(begin, end) {
+		rulePortList = append(rulePortList, policycache.RulePort{
+			Protocol:    protocol,
+			DstPort:     portMask.Port,
+			DstPortMask: portMask.Mask,
+		})
 	}
 	return rulePortList
 }
@@ -225,8 +346,15 @@ func FlattenPorts(ports []securityv1alpha1.SecurityPolicyPort) ([]policycache.Ru
 	var portMapTCP [65536]bool
 	var portMapUDP [65536]bool
 	var portlessProtocol = make(map[securityv1alpha1.Protocol]bool, 0)
+	var icmpPorts = make(map[string]policycache.RulePort)
 
 	for _, port := range ports {
+		if port.Protocol == securityv1alpha1.ProtocolICMP && port.ICMPType != nil {
+			icmpPort := toICMPRulePort(port)
+			icmpPorts[icmpPortKey(icmpPort)] = icmpPort
+			continue
+		}
+
 		if port.Protocol != securityv1alpha1.ProtocolTCP && port.Protocol != securityv1alpha1.ProtocolUDP {
 			// ignore port when Protocol neither TCP nor UDP
 			portlessProtocol[port.Protocol] = true
@@ -279,9 +407,36 @@ func FlattenPorts(ports []securityv1alpha1.SecurityPolicyPort) ([]policycache.Ru
 		})
 	}
 
+	// icmp ports with type have been covered when all icmp matches
+	if !portlessProtocol[securityv1alpha1.ProtocolICMP] {
+		for _, key := range sets.StringKeySet(icmpPorts).List() {
+			rulePortList = append(rulePortList, icmpPorts[key])
+		}
+	}
+
 	return rulePortList, nil
 }
 
+func toICMPRulePort(port securityv1alpha1.SecurityPolicyPort) policycache.RulePort {
+	icmpType := uint8(*port.ICMPType)
+	rulePort := policycache.RulePort{
+		Protocol: port.Protocol,
+		ICMPType: &icmpType,
+	}
+	if port.ICMPCode != nil {
+		icmpCode := uint8(*port.ICMPCode)
+		rulePort.ICMPCode = &icmpCode
+	}
+	return rulePort
+}
+
+func icmpPortKey(port policycache.RulePort) string {
+	if port.ICMPCode == nil {
+		return fmt.Sprintf("%d", *port.ICMPType)
+	}
+	return fmt.Sprintf("%d/%d", *port.ICMPType, *port.ICMPCode)
+}
+
 func toRuleMap(ruleList []policycache.PolicyRule) map[string]*policycache.PolicyRule {
 	var ruleMap = make(map[string]*policycache.PolicyRule, len(ruleList))
 	for item, rule := range ruleList {
@@ -294,10 +449,15 @@ func toRuleMap(ruleList []policycache.PolicyRule) map[string]*policycache.Policy
 
 type (
 	// groupNotFound means policy needed group not found, needed retry.
-	groupNotFound error
+	groupNotFound struct{ error }
+	// rulesExceedLimit means policy expands to more rules than max rules per policy, rejected.
+	rulesExceedLimit struct{ error }
 )
 
 func isGroupNotFound(err error) bool {
-	_, isType := err.(groupNotFound)
-	return isType
+	return errors.As(err, &groupNotFound{})
+}
+
+func isRulesExceedLimit(err error) bool {
+	return errors.As(err, &rulesExceedLimit{})
 }
@@ -0,0 +1,276 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+
+	policycache "github.com/everoute/everoute/pkg/agent/controller/policy/cache"
+	"github.com/everoute/everoute/pkg/agent/datapath"
+	groupv1alpha1 "github.com/everoute/everoute/pkg/apis/group/v1alpha1"
+	securityv1alpha1 "github.com/everoute/everoute/pkg/apis/security/v1alpha1"
+	"github.com/everoute/everoute/pkg/constants"
+	"github.com/everoute/everoute/pkg/types"
+)
+
+func TestRuleLogging(t *testing.T) {
+	RegisterTestingT(t)
+
+	r := &Reconciler{ifaceNameCache: newLocalEndpointCache(fakeIfaceIPs{"veth1": "10.0.0.1/32"}.resolve)}
+	newPolicy := func(logging *securityv1alpha1.Logging) *securityv1alpha1.SecurityPolicy {
+		return &securityv1alpha1.SecurityPolicy{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "logging-policy"},
+			Spec: securityv1alpha1.SecurityPolicySpec{
+				AppliedTo: []securityv1alpha1.ApplyToPeer{{InterfaceName: pointer.String("veth*")}},
+				IngressRules: []securityv1alpha1.Rule{{
+					Name: "ingress",
+					From: []securityv1alpha1.SecurityPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "192.168.0.0/24"}}},
+				}},
+				DefaultRule: securityv1alpha1.DefaultRuleDrop,
+				Logging:     logging,
+			},
+		}
+	}
+	// datapathRules returns datapath rules generated from the policy by the rule action
+	datapathRules := func(policy *securityv1alpha1.SecurityPolicy) map[policycache.RuleAction]*datapath.EveroutePolicyRule {
+		completeRules, err := r.completePolicy(policy)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(completeRules).Should(HaveLen(2))
+
+		rules := make(map[policycache.RuleAction]*datapath.EveroutePolicyRule)
+		for _, completeRule := range completeRules {
+			srcIPs, err := policycache.AssembleStaticIPAndGroup(completeRule.SrcIPs, completeRule.SrcGroups, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			dstIPs, err := policycache.AssembleStaticIPAndGroup(completeRule.DstIPs, completeRule.DstGroups, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			for _, rule := range completeRule.GenerateRuleList(srcIPs, dstIPs, completeRule.Ports) {
+				rule := rule
+				rules[rule.Action] = toEveroutePolicyRule("", &rule)
+			}
+		}
+		return rules
+	}
+	// loggedRules returns whether datapath rules generated from the policy logged by the rule action
+	loggedRules := func(policy *securityv1alpha1.SecurityPolicy) map[policycache.RuleAction]bool {
+		logged := make(map[policycache.RuleAction]bool)
+		for action, rule := range datapathRules(policy) {
+			logged[action] = rule.Logged
+		}
+		return logged
+	}
+	// logSampleRates returns log sample rates of datapath rules generated from the policy by the rule action
+	logSampleRates := func(policy *securityv1alpha1.SecurityPolicy) map[policycache.RuleAction]int32 {
+		rates := make(map[policycache.RuleAction]int32)
+		for action, rule := range datapathRules(policy) {
+			rates[action] = rule.LogSampleRate
+		}
+		return rates
+	}
+
+	t.Run("drop only logging policy should log deny flows but not allow flows", func(t *testing.T) {
+		policy := newPolicy(&securityv1alpha1.Logging{Enabled: true, DropEnabled: pointer.Bool(true), AllowEnabled: pointer.Bool(false)})
+		Expect(loggedRules(policy)).Should(Equal(map[policycache.RuleAction]bool{
+			policycache.RuleActionAllow: false,
+			policycache.RuleActionDrop:  true,
+		}))
+	})
+
+	t.Run("logging policy should log both allow and deny flows by default", func(t *testing.T) {
+		policy := newPolicy(&securityv1alpha1.Logging{Enabled: true})
+		Expect(loggedRules(policy)).Should(Equal(map[policycache.RuleAction]bool{
+			policycache.RuleActionAllow: true,
+			policycache.RuleActionDrop:  true,
+		}))
+	})
+
+	t.Run("rule logging should override the policy logging", func(t *testing.T) {
+		policy := newPolicy(nil)
+		policy.Spec.IngressRules[0].Logging = &securityv1alpha1.Logging{Enabled: true, DropEnabled: pointer.Bool(false)}
+		Expect(loggedRules(policy)).Should(Equal(map[policycache.RuleAction]bool{
+			policycache.RuleActionAllow: true,
+			policycache.RuleActionDrop:  false,
+		}))
+	})
+
+	t.Run("logging policy should log deny flows at full rate and allow flows at sample rate", func(t *testing.T) {
+		policy := newPolicy(&securityv1alpha1.Logging{Enabled: true, AllowSampleRate: pointer.Int32(10)})
+		Expect(logSampleRates(policy)).Should(Equal(map[policycache.RuleAction]int32{
+			policycache.RuleActionAllow: 10,
+			policycache.RuleActionDrop:  securityv1alpha1.DefaultLoggingSampleRate,
+		}))
+	})
+
+	t.Run("default rule logging should override the policy logging of the default rule", func(t *testing.T) {
+		policy := newPolicy(&securityv1alpha1.Logging{Enabled: true, Tags: map[string]string{"PolicyID": "policy1"}})
+		policy.Spec.DefaultIngressRuleLogging = &securityv1alpha1.Logging{Enabled: true, Tags: map[string]string{"PolicyID": "policy1", "Direction": "Ingress"}}
+		completeRules, err := r.completePolicy(policy)
+		Expect(err).ShouldNot(HaveOccurred())
+		loggingTags := make(map[policycache.RuleAction]map[string]string)
+		for _, completeRule := range completeRules {
+			loggingTags[completeRule.Action] = completeRule.LoggingTags
+		}
+		Expect(loggingTags).Should(Equal(map[policycache.RuleAction]map[string]string{
+			policycache.RuleActionAllow: {"PolicyID": "policy1"},
+			policycache.RuleActionDrop:  {"PolicyID": "policy1", "Direction": "Ingress"},
+		}))
+	})
+}
+
+func TestRuleVlanID(t *testing.T) {
+	RegisterTestingT(t)
+
+	r := &Reconciler{ifaceNameCache: newLocalEndpointCache(fakeIfaceIPs{"veth1": "10.0.0.1/32"}.resolve)}
+	policy := &securityv1alpha1.SecurityPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vlan-policy"},
+		Spec: securityv1alpha1.SecurityPolicySpec{
+			AppliedTo: []securityv1alpha1.ApplyToPeer{{InterfaceName: pointer.String("veth*")}},
+			IngressRules: []securityv1alpha1.Rule{{
+				Name:   "ingress",
+				From:   []securityv1alpha1.SecurityPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "192.168.0.0/24"}}},
+				VlanID: pointer.Int32(100),
+			}},
+			DefaultRule: securityv1alpha1.DefaultRuleDrop,
+		},
+	}
+
+	completeRules, err := r.completePolicy(policy)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(completeRules).Should(HaveLen(2))
+
+	vlanIDs := make(map[policycache.RuleAction]uint16)
+	for _, completeRule := range completeRules {
+		srcIPs, err := policycache.AssembleStaticIPAndGroup(completeRule.SrcIPs, completeRule.SrcGroups, nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		dstIPs, err := policycache.AssembleStaticIPAndGroup(completeRule.DstIPs, completeRule.DstGroups, nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		for _, rule := range completeRule.GenerateRuleList(srcIPs, dstIPs, completeRule.Ports) {
+			rule := rule
+			vlanIDs[rule.Action] = toEveroutePolicyRule("", &rule).VlanID
+		}
+	}
+	// only the rule on the vlan matches vlan, the default rule matches all vlans
+	Expect(vlanIDs).Should(Equal(map[policycache.RuleAction]uint16{
+		policycache.RuleActionAllow: 100,
+		policycache.RuleActionDrop:  0,
+	}))
+}
+
+func TestRuleUnknownTier(t *testing.T) {
+	RegisterTestingT(t)
+
+	recorder := record.NewFakeRecorder(10)
+	r := &Reconciler{
+		DatapathManager: datapath.NewDatapathManager(&datapath.DpManagerConfig{ManagedVDSMap: map[string]string{}}, nil),
+		recorder:        recorder,
+	}
+
+	t.Run("rule with configured tier should be installed", func(t *testing.T) {
+		rule := &policycache.PolicyRule{Name: "default/policy1/normal/ingress-flowkey1", Tier: constants.Tier2,
+			Action: policycache.RuleActionAllow, Direction: policycache.RuleDirectionIn}
+		spec, ok := r.toRuleSpec("flowkey1", rule)
+		Expect(ok).Should(BeTrue())
+		Expect(spec.RuleName).Should(Equal(rule.Name))
+		Expect(recorder.Events).Should(BeEmpty())
+	})
+
+	t.Run("rule with unknown tier should be skipped with an event", func(t *testing.T) {
+		rule := &policycache.PolicyRule{Name: "default/policy1/normal/ingress-flowkey2", Tier: "unknown",
+			Action: policycache.RuleActionAllow, Direction: policycache.RuleDirectionIn}
+		_, ok := r.toRuleSpec("flowkey2", rule)
+		Expect(ok).Should(BeFalse())
+		Expect(recorder.Events).Should(Receive(ContainSubstring(UnknownRuleTierReason)))
+	})
+}
+
+func TestRuleTrafficLocality(t *testing.T) {
+	RegisterTestingT(t)
+
+	recorder := record.NewFakeRecorder(10)
+	r := &Reconciler{
+		DatapathManager: datapath.NewDatapathManager(&datapath.DpManagerConfig{ManagedVDSMap: map[string]string{}}, nil),
+		recorder:        recorder,
+	}
+
+	t.Run("rule without traffic locality should be installed", func(t *testing.T) {
+		rule := &policycache.PolicyRule{Name: "default/policy1/normal/ingress-flowkey1", Tier: constants.Tier2,
+			Action: policycache.RuleActionAllow, Direction: policycache.RuleDirectionIn}
+		_, ok := r.toRuleSpec("flowkey1", rule)
+		Expect(ok).Should(BeTrue())
+		Expect(recorder.Events).Should(BeEmpty())
+	})
+
+	t.Run("rule with traffic locality should be skipped with an event without overlay", func(t *testing.T) {
+		rule := &policycache.PolicyRule{Name: "default/policy1/normal/ingress-flowkey2", Tier: constants.Tier2,
+			Action: policycache.RuleActionAllow, Direction: policycache.RuleDirectionIn, TrafficLocality: datapath.TrafficLocalityInterNode}
+		_, ok := r.toRuleSpec("flowkey2", rule)
+		Expect(ok).Should(BeFalse())
+		Expect(recorder.Events).Should(Receive(ContainSubstring(UnsupportedTrafficLocalityReason)))
+	})
+}
+
+func TestRuleUpdateByGroupExceedLimit(t *testing.T) {
+	RegisterTestingT(t)
+
+	recorder := record.NewFakeRecorder(10)
+	r := &Reconciler{
+		DatapathManager: datapath.NewDatapathManager(&datapath.DpManagerConfig{ManagedVDSMap: map[string]string{}}, nil),
+		ruleCache:       policycache.NewCompleteRuleCache(),
+		groupCache:      policycache.NewGroupCache(),
+		recorder:        recorder,
+	}
+	newGroupMembers := func(ips ...types.IPAddress) *groupv1alpha1.GroupMembers {
+		gm := &groupv1alpha1.GroupMembers{ObjectMeta: metav1.ObjectMeta{Name: "group1"}}
+		for _, ip := range ips {
+			gm.GroupMembers = append(gm.GroupMembers, groupv1alpha1.GroupMember{IPs: []types.IPAddress{ip}})
+		}
+		return gm
+	}
+	r.groupCache.UpdateGroupMembership(newGroupMembers("10.0.0.1"))
+	rule := &policycache.CompleteRule{
+		RuleID:    "default/policy1/normal/ingress",
+		Tier:      constants.Tier2,
+		Action:    policycache.RuleActionAllow,
+		Direction: policycache.RuleDirectionIn,
+		SrcGroups: sets.New("group1"),
+		DstIPs:    sets.New("10.0.1.1/32"),
+		Ports:     []policycache.RulePort{{}},
+	}
+	Expect(r.ruleCache.Add(rule)).Should(Succeed())
+	r.syncPolicyRulesUntilSuccess(nil, rule.ListRules(r.groupCache))
+	Expect(r.DatapathManager.Rules).Should(HaveLen(1))
+	r.SetMaxRulesPerPolicy(2)
+
+	t.Run("should reject groupmembers expands policy beyond max rules", func(t *testing.T) {
+		err := r.ruleUpdateByGroup(newGroupMembers("10.0.0.1", "10.0.0.2", "10.0.0.3"))
+		Expect(isRulesExceedLimit(err)).Should(BeTrue())
+		Expect(recorder.Events).Should(Receive(ContainSubstring(RulesExceedLimitReason)))
+		Expect(r.DatapathManager.Rules).Should(HaveLen(1))
+	})
+
+	t.Run("should update groupmembers within max rules", func(t *testing.T) {
+		Expect(r.ruleUpdateByGroup(newGroupMembers("10.0.0.1", "10.0.0.2"))).Should(Succeed())
+		Expect(recorder.Events).Should(BeEmpty())
+		Expect(r.DatapathManager.Rules).Should(HaveLen(2))
+	})
+}
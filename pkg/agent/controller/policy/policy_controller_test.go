@@ -19,13 +19,16 @@ package policy_test
 import (
 	"context"
 	"fmt"
+	"net"
 	"reflect"
 	"testing"
 	"time"
 
+	ipamv1alpha1 "github.com/everoute/ipam/api/ipam/v1alpha1"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/format"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8stypes "k8s.io/apimachinery/pkg/types"
@@ -193,6 +196,188 @@ var _ = Describe("PolicyController", func() {
 			})
 		})
 
+		When("create a sample policy with endpoint network peer", func() {
+			var policy *securityv1alpha1.SecurityPolicy
+			var gatewayIP net.IP
+			var gatewayMask net.IPMask
+
+			BeforeEach(func() {
+				gatewayIP, gatewayMask = pCtrl.DatapathManager.Info.GatewayIP, pCtrl.DatapathManager.Info.GatewayMask
+				pCtrl.DatapathManager.Info.GatewayIP = net.ParseIP("10.10.0.1")
+				pCtrl.DatapathManager.Info.GatewayMask = net.CIDRMask(24, 32)
+
+				gateway := securityv1alpha1.EndpointNetworkGateway
+				subnet := securityv1alpha1.EndpointNetworkSubnet
+				policy = newTestPolicy(group1, group2, group3, newTestPort("TCP", "22", "number"), newTestPort("UDP", "53", "number"))
+				policy.Spec.IngressRules[0].From = []securityv1alpha1.SecurityPolicyPeer{{EndpointNetwork: &gateway}}
+				policy.Spec.EgressRules[0].To = []securityv1alpha1.SecurityPolicyPeer{{EndpointNetwork: &subnet}}
+
+				By("create policy " + policy.Name)
+				Expect(k8sClient.Create(ctx, policy)).Should(Succeed())
+			})
+			AfterEach(func() {
+				pCtrl.DatapathManager.Info.GatewayIP = gatewayIP
+				pCtrl.DatapathManager.Info.GatewayMask = gatewayMask
+			})
+
+			It("should resolve peer to the endpoint gateway and subnet", func() {
+				assertPolicyRulesNum(policy, 4)
+				assertCompleteRuleNum(4)
+
+				assertHasPolicyRule(policy, "Ingress", "Allow", "10.10.0.1/32", 0, "192.168.1.1/32", 22, "TCP")
+				assertHasPolicyRule(policy, "Egress", "Allow", "192.168.1.1/32", 0, "10.10.0.0/24", 53, "UDP")
+			})
+		})
+
+		When("create a sample policy with ipPool peer", func() {
+			var policy *securityv1alpha1.SecurityPolicy
+			var pool *ipamv1alpha1.IPPool
+
+			BeforeEach(func() {
+				pool = &ipamv1alpha1.IPPool{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pool-test-" + rand.String(6),
+						Namespace: metav1.NamespaceDefault,
+					},
+					Spec: ipamv1alpha1.IPPoolSpec{
+						CIDR:    "10.20.1.0/24",
+						Subnet:  "10.20.0.0/16",
+						Gateway: "10.20.0.1",
+					},
+				}
+				By("create ipPool " + pool.Name)
+				Expect(k8sClient.Create(ctx, pool)).Should(Succeed())
+
+				poolName := &securityv1alpha1.NamespacedName{Name: pool.Name, Namespace: pool.Namespace}
+				policy = newTestPolicy(group1, group2, group3, newTestPort("TCP", "22", "number"), newTestPort("UDP", "53", "number"))
+				policy.Spec.IngressRules[0].From = []securityv1alpha1.SecurityPolicyPeer{{IPPool: poolName}}
+				policy.Spec.EgressRules[0].To = []securityv1alpha1.SecurityPolicyPeer{{IPPool: poolName}}
+
+				By("create policy " + policy.Name)
+				Expect(k8sClient.Create(ctx, policy)).Should(Succeed())
+			})
+			AfterEach(func() {
+				Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, pool))).Should(Succeed())
+			})
+
+			It("should resolve peer to the ipPool cidr", func() {
+				assertPolicyRulesNum(policy, 4)
+				assertCompleteRuleNum(4)
+
+				assertHasPolicyRule(policy, "Ingress", "Allow", "10.20.1.0/24", 0, "192.168.1.1/32", 22, "TCP")
+				assertHasPolicyRule(policy, "Egress", "Allow", "192.168.1.1/32", 0, "10.20.1.0/24", 53, "UDP")
+			})
+
+			When("update the ipPool cidr", func() {
+				BeforeEach(func() {
+					assertHasPolicyRule(policy, "Ingress", "Allow", "10.20.1.0/24", 0, "192.168.1.1/32", 22, "TCP")
+
+					updatePool := pool.DeepCopy()
+					Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pool), updatePool)).Should(Succeed())
+					updatePool.Spec.CIDR = "10.20.2.0/24"
+					Expect(k8sClient.Update(ctx, updatePool)).Should(Succeed())
+				})
+
+				It("should follow the ipPool cidr", func() {
+					assertHasPolicyRule(policy, "Ingress", "Allow", "10.20.2.0/24", 0, "192.168.1.1/32", 22, "TCP")
+					assertHasPolicyRule(policy, "Egress", "Allow", "192.168.1.1/32", 0, "10.20.2.0/24", 53, "UDP")
+					assertNoPolicyRule(policy, "Ingress", "Allow", "10.20.1.0/24", 0, "192.168.1.1/32", 22, "TCP")
+				})
+			})
+
+			When("update the ipPool allocates from ip range", func() {
+				BeforeEach(func() {
+					updatePool := pool.DeepCopy()
+					Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pool), updatePool)).Should(Succeed())
+					updatePool.Spec.CIDR = ""
+					updatePool.Spec.Start = "10.20.0.10"
+					updatePool.Spec.End = "10.20.0.20"
+					Expect(k8sClient.Update(ctx, updatePool)).Should(Succeed())
+				})
+
+				It("should resolve peer to the ipPool subnet", func() {
+					assertHasPolicyRule(policy, "Ingress", "Allow", "10.20.0.0/16", 0, "192.168.1.1/32", 22, "TCP")
+					assertHasPolicyRule(policy, "Egress", "Allow", "192.168.1.1/32", 0, "10.20.0.0/16", 53, "UDP")
+				})
+			})
+		})
+
+		When("create a sample policy with fqdn peer", func() {
+			var policy *securityv1alpha1.SecurityPolicy
+			var fqdn string
+
+			BeforeEach(func() {
+				fqdn = "api-" + rand.String(6) + ".example.com"
+				fqdnResolver.Set(fqdn, "10.30.0.1", "10.30.0.2", "fd00::1")
+
+				policy = newTestPolicy(group1, group2, group3, newTestPort("TCP", "22", "number"), newTestPort("UDP", "53", "number"))
+				policy.Spec.EgressRules[0].To = []securityv1alpha1.SecurityPolicyPeer{{FQDN: fqdn}}
+
+				By("create policy " + policy.Name)
+				Expect(k8sClient.Create(ctx, policy)).Should(Succeed())
+			})
+
+			It("should resolve peer to the fqdn addresses", func() {
+				assertHasPolicyRule(policy, "Egress", "Allow", "192.168.1.1/32", 0, "10.30.0.1/32", 53, "UDP")
+				assertHasPolicyRule(policy, "Egress", "Allow", "192.168.1.1/32", 0, "10.30.0.2/32", 53, "UDP")
+				assertHasPolicyRule(policy, "Egress", "Allow", "192.168.1.1/32", 0, "fd00::1/128", 53, "UDP")
+			})
+
+			When("the fqdn addresses changed", func() {
+				BeforeEach(func() {
+					assertHasPolicyRule(policy, "Egress", "Allow", "192.168.1.1/32", 0, "10.30.0.1/32", 53, "UDP")
+					fqdnResolver.Set(fqdn, "10.30.0.2", "10.30.0.3")
+				})
+
+				It("should follow the fqdn addresses and remove stale addresses", func() {
+					assertHasPolicyRule(policy, "Egress", "Allow", "192.168.1.1/32", 0, "10.30.0.3/32", 53, "UDP")
+					assertHasPolicyRule(policy, "Egress", "Allow", "192.168.1.1/32", 0, "10.30.0.2/32", 53, "UDP")
+					assertNoPolicyRule(policy, "Egress", "Allow", "192.168.1.1/32", 0, "10.30.0.1/32", 53, "UDP")
+					assertNoPolicyRule(policy, "Egress", "Allow", "192.168.1.1/32", 0, "fd00::1/128", 53, "UDP")
+				})
+			})
+		})
+
+		When("update policy expands beyond max rules per policy", func() {
+			var testPolicy *securityv1alpha1.SecurityPolicy
+
+			BeforeEach(func() {
+				testPolicy = newTestPolicy(group1, group2, group3, newTestPort("TCP", "22", "number"), newTestPort("UDP", "53", "number"))
+				By("create policy " + testPolicy.Name)
+				Expect(k8sClient.Create(ctx, testPolicy)).Should(Succeed())
+				assertPolicyRulesNum(testPolicy, 4)
+
+				pCtrl.SetMaxRulesPerPolicy(8)
+				updatePolicy := testPolicy.DeepCopy()
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(testPolicy), updatePolicy)).Should(Succeed())
+				// port range 1000-1999 expands to 7 ingress rules
+				updatePolicy.Spec.IngressRules[0].Ports = []securityv1alpha1.SecurityPolicyPort{*newTestPort("TCP", "1000-1999", "number")}
+				Expect(k8sClient.Update(ctx, updatePolicy)).Should(Succeed())
+			})
+			AfterEach(func() {
+				pCtrl.SetMaxRulesPerPolicy(0)
+			})
+
+			It("should reject the policy and keep rules installed before", func() {
+				Eventually(func(g Gomega) {
+					var eventList corev1.EventList
+					g.Expect(k8sClient.List(ctx, &eventList, client.InNamespace(testPolicy.Namespace))).Should(Succeed())
+					var reasons []string
+					for _, event := range eventList.Items {
+						if event.InvolvedObject.Name == testPolicy.Name {
+							reasons = append(reasons, event.Reason)
+						}
+					}
+					g.Expect(reasons).Should(ContainElement(policy.RulesExceedLimitReason))
+				}, timeout, interval).Should(Succeed())
+
+				Consistently(func() int {
+					return len(getRuleByPolicy(testPolicy))
+				}, time.Second, interval).Should(Equal(4))
+				assertHasPolicyRule(testPolicy, "Ingress", "Allow", "192.168.2.1/32", 0, "192.168.1.1/32", 22, "TCP")
+			})
+		})
+
 		When("create a sample policy with named port", func() {
 			var policy *securityv1alpha1.SecurityPolicy
 			BeforeEach(func() {
@@ -383,6 +568,30 @@ var _ = Describe("PolicyController", func() {
 				assertHasPolicyRule(policy, "Egress", "Drop", "192.168.1.1/32", 0, "", 0, "")
 			})
 
+			When("ingress rule active in a time window", func() {
+				var activeFrom, activeUntil time.Time
+
+				BeforeEach(func() {
+					activeFrom = time.Now().Add(3 * time.Second)
+					activeUntil = activeFrom.Add(3 * time.Second)
+					policy.Spec.IngressRules[0].ActiveFrom = &metav1.Time{Time: activeFrom}
+					policy.Spec.IngressRules[0].ActiveUntil = &metav1.Time{Time: activeUntil}
+					mustUpdatePolicy(ctx, policy)
+				})
+
+				It("should install the rule at active from and remove it at active until", func() {
+					assertPolicyRulesNum(policy, 3)
+					assertHasPolicyRule(policy, "Egress", "Allow", "192.168.1.1/32", 0, "192.168.3.1/32", 80, "UDP")
+
+					assertHasPolicyRule(policy, "Ingress", "Allow", "192.168.2.1/32", 0, "192.168.1.1/32", 22, "TCP")
+					Expect(time.Now()).ShouldNot(BeTemporally("<", activeFrom))
+
+					assertNoPolicyRule(policy, "Ingress", "Allow", "192.168.2.1/32", 0, "192.168.1.1/32", 22, "TCP")
+					Expect(time.Now()).ShouldNot(BeTemporally("<", activeUntil))
+					assertPolicyRulesNum(policy, 3)
+				})
+			})
+
 			When("add a group into applied groups", func() {
 				var newGroup *testGroup
 				var updPolicy *securityv1alpha1.SecurityPolicy
@@ -775,6 +984,48 @@ var _ = Describe("PolicyController", func() {
 					assertNoPolicyRule(policy, "Ingress", "Allow", "192.168.1.1/32", 0, "192.168.3.1/32", 123, "UDP")
 				})
 			})
+			When("limit symmetric mode to ingress rules", func() {
+				BeforeEach(func() {
+					policy.Spec.SymmetricPolicyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}
+					mustUpdatePolicy(ctx, policy)
+				})
+
+				It("should generate symmetric rules only for ingress rules", func() {
+					assertPolicyRulesNum(policy, 5)
+					assertCompleteRuleNum(4)
+
+					assertHasPolicyRule(policy, "Egress", "Allow", "192.168.2.1/32", 0, "192.168.1.1/32", 443, "TCP")
+					assertNoPolicyRule(policy, "Ingress", "Allow", "192.168.1.1/32", 0, "192.168.3.1/32", 123, "UDP")
+				})
+
+				When("ingress rule peer DisableSymmetric", func() {
+					BeforeEach(func() {
+						policy.Spec.IngressRules[0].From[0].DisableSymmetric = true
+						mustUpdatePolicy(ctx, policy)
+					})
+
+					It("should not generate symmetric rule for the peer", func() {
+						assertPolicyRulesNum(policy, 4)
+						assertCompleteRuleNum(4)
+
+						assertNoPolicyRule(policy, "Egress", "Allow", "192.168.2.1/32", 0, "192.168.1.1/32", 443, "TCP")
+					})
+				})
+			})
+			When("limit symmetric mode to egress rules", func() {
+				BeforeEach(func() {
+					policy.Spec.SymmetricPolicyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeEgress}
+					mustUpdatePolicy(ctx, policy)
+				})
+
+				It("should generate symmetric rules only for egress rules", func() {
+					assertPolicyRulesNum(policy, 5)
+					assertCompleteRuleNum(4)
+
+					assertNoPolicyRule(policy, "Egress", "Allow", "192.168.2.1/32", 0, "192.168.1.1/32", 443, "TCP")
+					assertHasPolicyRule(policy, "Ingress", "Allow", "192.168.1.1/32", 0, "192.168.3.1/32", 123, "UDP")
+				})
+			})
 		})
 
 		When("create a sample policy with enable ingress only", func() {
@@ -1700,6 +1951,47 @@ func TestFlattenPorts(t *testing.T) {
 				{DstPort: 80, DstPortMask: 0xffff, Protocol: "TCP"},
 			},
 		},
+		"should coalesce discrete ports and portRange": {
+			portRange: newTestPort("TCP", "80,443,8080-8090,81", "number"),
+			expectRulePort: []cache.RulePort{
+				{DstPort: 80, DstPortMask: 0xfffe, Protocol: "TCP"},
+				{DstPort: 443, DstPortMask: 0xffff, Protocol: "TCP"},
+				{DstPort: 8080, DstPortMask: 0xfff8, Protocol: "TCP"},
+				{DstPort: 8088, DstPortMask: 0xfffe, Protocol: "TCP"},
+				{DstPort: 8090, DstPortMask: 0xffff, Protocol: "TCP"},
+			},
+		},
+		"should match all ports with empty portRange": {
+			portRange: newTestPort("TCP", "", "number"),
+			expectRulePort: []cache.RulePort{
+				{DstPort: 0, Protocol: "TCP"},
+			},
+		},
+		"should match all ports with port 0": {
+			portRange: newTestPort("TCP", "0", "number"),
+			expectRulePort: []cache.RulePort{
+				{DstPort: 0, Protocol: "TCP"},
+			},
+		},
+		"should skip port 0 in portRange": {
+			portRange: newTestPort("TCP", "0-3", "number"),
+			expectRulePort: []cache.RulePort{
+				{DstPort: 1, DstPortMask: 0xffff, Protocol: "TCP"},
+				{DstPort: 2, DstPortMask: 0xfffe, Protocol: "TCP"},
+			},
+		},
+		"should match all icmp without icmp type": {
+			portRange: newTestPort("ICMP", "", "number"),
+			expectRulePort: []cache.RulePort{
+				{Protocol: "ICMP"},
+			},
+		},
+		"should keep icmp type and code": {
+			portRange: newTestICMPPort(8, 0),
+			expectRulePort: []cache.RulePort{
+				{Protocol: "ICMP", ICMPType: uint8Ptr(8), ICMPCode: uint8Ptr(0)},
+			},
+		},
 	}
 
 	for name, tc := range testCases {
@@ -1715,6 +2007,45 @@ func TestFlattenPorts(t *testing.T) {
 	}
 }
 
+func TestFlattenICMPPorts(t *testing.T) {
+	t.Run("icmp types should be deduplicated", func(t *testing.T) {
+		ports, err := policy.FlattenPorts([]securityv1alpha1.SecurityPolicyPort{*newTestICMPPort(8, 0), *newTestICMPPort(0, 0), *newTestICMPPort(8, 0)})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		expectRulePort := []cache.RulePort{
+			{Protocol: "ICMP", ICMPType: uint8Ptr(0), ICMPCode: uint8Ptr(0)},
+			{Protocol: "ICMP", ICMPType: uint8Ptr(8), ICMPCode: uint8Ptr(0)},
+		}
+		if !reflect.DeepEqual(ports, expectRulePort) {
+			t.Fatalf("expect rule ports: %+v, get rule ports: %+v", expectRulePort, ports)
+		}
+	})
+
+	t.Run("icmp types should be covered by all icmp", func(t *testing.T) {
+		ports, err := policy.FlattenPorts([]securityv1alpha1.SecurityPolicyPort{*newTestICMPPort(8, 0), *newTestPort("ICMP", "", "number")})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		expectRulePort := []cache.RulePort{{Protocol: "ICMP"}}
+		if !reflect.DeepEqual(ports, expectRulePort) {
+			t.Fatalf("expect rule ports: %+v, get rule ports: %+v", expectRulePort, ports)
+		}
+	})
+}
+
+func newTestICMPPort(icmpType, icmpCode int32) *securityv1alpha1.SecurityPolicyPort {
+	return &securityv1alpha1.SecurityPolicyPort{
+		Protocol: securityv1alpha1.ProtocolICMP,
+		ICMPType: &icmpType,
+		ICMPCode: &icmpCode,
+	}
+}
+
+func uint8Ptr(v uint8) *uint8 {
+	return &v
+}
+
 func newTestPort(protocol, portRange, portType string) *securityv1alpha1.SecurityPolicyPort {
 	return &securityv1alpha1.SecurityPolicyPort{
 		Protocol:  securityv1alpha1.Protocol(protocol),
@@ -0,0 +1,64 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"fmt"
+
+	"k8s.io/klog"
+
+	securityv1alpha1 "github.com/everoute/everoute/pkg/apis/security/v1alpha1"
+)
+
+// PriorityBand is the range of policy priority allowed for policies in a namespace
+type PriorityBand struct {
+	Min int32
+	Max int32
+}
+
+func (b PriorityBand) Validate() error {
+	if b.Min < 1 || b.Max > 100 || b.Min > b.Max {
+		return fmt.Errorf("invalid priority band [%d, %d], must be within [1, 100] and min not greater than max", b.Min, b.Max)
+	}
+	return nil
+}
+
+// Clamp returns the priority clamped into the band
+func (b PriorityBand) Clamp(priority int32) int32 {
+	if priority < b.Min {
+		return b.Min
+	}
+	if priority > b.Max {
+		return b.Max
+	}
+	return priority
+}
+
+// policyPriority returns the effective priority of the policy, which is clamped into the priority band of its
+// namespace, so that policies of a tenant can't outrank critical policies of other tenants
+func (r *Reconciler) policyPriority(policy *securityv1alpha1.SecurityPolicy) int32 {
+	band, ok := r.PriorityBands[policy.GetNamespace()]
+	if !ok {
+		return policy.Spec.Priority
+	}
+	priority := band.Clamp(policy.Spec.Priority)
+	if priority != policy.Spec.Priority {
+		klog.V(4).Infof("priority %d of policy %s/%s clamped to %d by priority band of the namespace",
+			policy.Spec.Priority, policy.GetNamespace(), policy.GetName(), priority)
+	}
+	return priority
+}
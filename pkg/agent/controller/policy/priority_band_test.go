@@ -0,0 +1,86 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	securityv1alpha1 "github.com/everoute/everoute/pkg/apis/security/v1alpha1"
+)
+
+func TestPriorityBand(t *testing.T) {
+	RegisterTestingT(t)
+
+	r := &Reconciler{
+		ifaceNameCache: newLocalEndpointCache(fakeIfaceIPs{"veth1": "10.0.0.1/32"}.resolve),
+		PriorityBands:  map[string]PriorityBand{"tenant": {Min: 10, Max: 50}},
+	}
+	newPolicy := func(namespace string, priority int32) *securityv1alpha1.SecurityPolicy {
+		return &securityv1alpha1.SecurityPolicy{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "policy"},
+			Spec: securityv1alpha1.SecurityPolicySpec{
+				Tier:      "tier2",
+				Priority:  priority,
+				AppliedTo: []securityv1alpha1.ApplyToPeer{{InterfaceName: pointer.String("veth*")}},
+				IngressRules: []securityv1alpha1.Rule{{
+					Name: "ingress",
+					From: []securityv1alpha1.SecurityPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "192.168.0.0/24"}}},
+				}},
+				DefaultRule: securityv1alpha1.DefaultRuleDrop,
+			},
+		}
+	}
+	expectPriority := func(policy *securityv1alpha1.SecurityPolicy, priority int32) {
+		completeRules, err := r.completePolicy(policy)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(completeRules).ShouldNot(BeEmpty())
+		for _, rule := range completeRules {
+			Expect(rule.Priority).Should(Equal(priority))
+		}
+	}
+
+	t.Run("tenant policy requests higher priority should be clamped to its band", func(t *testing.T) {
+		expectPriority(newPolicy("tenant", 90), 50)
+	})
+
+	t.Run("tenant policy requests lower priority should be clamped to its band", func(t *testing.T) {
+		expectPriority(newPolicy("tenant", 1), 10)
+	})
+
+	t.Run("tenant policy priority within its band should be kept", func(t *testing.T) {
+		expectPriority(newPolicy("tenant", 30), 30)
+	})
+
+	t.Run("policy in namespace without band should be kept", func(t *testing.T) {
+		expectPriority(newPolicy("default", 90), 90)
+	})
+}
+
+func TestPriorityBandValidate(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(PriorityBand{Min: 1, Max: 100}.Validate()).Should(Succeed())
+	Expect(PriorityBand{Min: 30, Max: 30}.Validate()).Should(Succeed())
+	Expect(PriorityBand{Min: 0, Max: 30}.Validate()).ShouldNot(Succeed())
+	Expect(PriorityBand{Min: 30, Max: 101}.Validate()).ShouldNot(Succeed())
+	Expect(PriorityBand{Min: 50, Max: 30}.Validate()).ShouldNot(Succeed())
+}
@@ -19,11 +19,14 @@ package policy_test
 import (
 	"context"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
+	ipamv1alpha1 "github.com/everoute/ipam/api/ipam/v1alpha1"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/format"
@@ -52,6 +55,7 @@ var (
 	useExistingCluster    bool
 	ctx, cancel           = context.WithCancel(ctrl.SetupSignalHandler())
 	pCtrl                 *policy.Reconciler
+	fqdnResolver          = &fakeFQDNResolver{records: map[string][]net.IP{}}
 )
 
 const (
@@ -95,6 +99,8 @@ var _ = BeforeSuite(func() {
 	*/
 	err = clientsetscheme.AddToScheme(scheme.Scheme)
 	Expect(err).Should(Succeed())
+	err = ipamv1alpha1.AddToScheme(scheme.Scheme)
+	Expect(err).Should(Succeed())
 
 	/*
 		One thing that this autogenerated file is missing, however, is a way to actually start your controller.
@@ -130,6 +136,9 @@ var _ = BeforeSuite(func() {
 		Client:          k8sManager.GetClient(),
 		Scheme:          k8sManager.GetScheme(),
 		DatapathManager: datapathManager,
+		EverouteIPAM:    true,
+		FQDNResolver:    fqdnResolver,
+		FQDNMinTTL:      time.Second,
 	}
 	err = (pCtrl).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
@@ -197,3 +206,28 @@ func NewPolicyRuleMatcher(rule cache.PolicyRule) gtypes.GomegaMatcher {
 		expRule: rule,
 	}
 }
+
+// fakeFQDNResolver resolves domain names to the records set in test
+type fakeFQDNResolver struct {
+	lock    sync.Mutex
+	records map[string][]net.IP
+}
+
+func (r *fakeFQDNResolver) LookupIP(_ context.Context, fqdn string) ([]net.IP, time.Duration, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	ips, ok := r.records[fqdn]
+	if !ok {
+		return nil, 0, fmt.Errorf("no such host %s", fqdn)
+	}
+	return ips, 0, nil
+}
+
+func (r *fakeFQDNResolver) Set(fqdn string, ips ...string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.records[fqdn] = nil
+	for _, ip := range ips {
+		r.records[fqdn] = append(r.records[fqdn], net.ParseIP(ip))
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datapath
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/contiv/libOpenflow/openflow13"
+	"github.com/contiv/ofnet/ofctrl"
+	log "github.com/sirupsen/logrus"
+	uerr "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// arpBlockIPs returns the endpoint ip addresses of the installed rules blocking ARP. Rules applied to
+// all endpoints are ignored, or the node would lose all its neighbors. Caller must hold flowReplayMutex.
+func (datapathManager *DpManager) arpBlockIPs() sets.Set[string] {
+	ipAddrs := sets.New[string]()
+	for _, entry := range datapathManager.Rules {
+		if entry.ARPBlockReference.Len() == 0 || datapathManager.ruleGroupDisabled(entry) {
+			continue
+		}
+		if ipAddr := ruleEndpointIPAddr(entry.EveroutePolicyRule, entry.Direction); ipAddr != "" {
+			ipAddrs.Insert(ipAddr)
+		}
+	}
+	return ipAddrs
+}
+
+// setARPBlockReference adds or removes the reference in ARP block references of the rule by whether the
+// reference denies ARP. Caller must hold flowReplayMutex.
+func setARPBlockReference(entry *EveroutePolicyRuleEntry, ruleName string, blockARP bool) {
+	if blockARP {
+		entry.ARPBlockReference.Insert(ruleName)
+	} else {
+		entry.ARPBlockReference.Delete(ruleName)
+	}
+}
+
+// syncARPBlockFlows updates the ARP and ND drop flows of all policy bridges by the installed rules blocking
+// ARP. Caller must hold flowReplayMutex.
+func (datapathManager *DpManager) syncARPBlockFlows() error {
+	ipAddrs := datapathManager.arpBlockIPs()
+
+	var errList []error
+	for vdsID, bridgeChain := range datapathManager.BridgeChainMap {
+		policyBridge, ok := bridgeChain[POLICY_BRIDGE_KEYWORD].(*PolicyBridge)
+		if !ok {
+			continue
+		}
+		if err := policyBridge.SetARPBlockIPs(ipAddrs); err != nil {
+			errList = append(errList, fmt.Errorf("failed to set arp block flows of vds %s: %s", vdsID, err))
+		}
+	}
+	return uerr.NewAggregate(errList)
+}
+
+// arpBlockFlowMatches returns matches of the flows drop ARP or ND of the ip address. ARP is matched by the
+// sender or target protocol address. ND is matched by the target address of neighbor solicitation and
+// advertisement, other ICMPv6 of the address isn't affected.
+func arpBlockFlowMatches(ipAddr string) ([]ofctrl.FlowMatch, error) {
+	ip, ipMask, err := ParseIPAddrMaskString(ipAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	var priority uint16 = HIGH_MATCH_FLOW_PRIORITY + FLOW_MATCH_OFFSET
+	if ip.To4() != nil {
+		return []ofctrl.FlowMatch{
+			{Priority: priority, Ethertype: PROTOCOL_ARP, ArpSpa: ip, ArpSpaMask: ipMask},
+			{Priority: priority, Ethertype: PROTOCOL_ARP, ArpTpa: ip, ArpTpaMask: ipMask},
+		}, nil
+	}
+	var matches []ofctrl.FlowMatch
+	for _, icmpv6Type := range []uint8{icmpv6TypeNeighborSolicitation, icmpv6TypeNeighborAdvertisement} {
+		rawMatchFields, err := ndTargetMatchFields(icmpv6Type, *ip, *ipMask)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, ofctrl.FlowMatch{
+			Priority:      priority,
+			Ethertype:     PROTOCOL_IPV6,
+			IpProto:       PROTOCOL_ICMPV6,
+			RawMatchField: rawMatchFields,
+		})
+	}
+	return matches, nil
+}
+
+// ndTargetMatchFields returns match fields of the ICMPv6 type and the neighbor discovery target address
+func ndTargetMatchFields(icmpv6Type uint8, target, targetMask net.IP) ([]*openflow13.MatchField, error) {
+	typeField, err := openflow13.FindFieldHeaderByName("NXM_NX_ICMPV6_TYPE", false)
+	if err != nil {
+		return nil, err
+	}
+	typeField.Value = &openflow13.IcmpTypeField{Type: icmpv6Type}
+
+	targetField, err := openflow13.FindFieldHeaderByName("NXM_NX_ND_TARGET", true)
+	if err != nil {
+		return nil, err
+	}
+	targetField.Value = &openflow13.Ipv6DstField{Ipv6Dst: target}
+	targetField.Mask = &openflow13.Ipv6DstField{Ipv6Dst: targetMask}
+	return []*openflow13.MatchField{typeField, targetField}, nil
+}
+
+// SetARPBlockIPs drops ARP and ND of the ip addresses in the input table, drop flows of the other ip addresses
+// are removed. The flows would be installed on bridge init if the switch hasn't connected.
+func (p *PolicyBridge) SetARPBlockIPs(ipAddrs sets.Set[string]) error {
+	if p.IsSwitchConnected() {
+		for ipAddr, flows := range p.arpBlockFlows {
+			if ipAddrs.Has(ipAddr) {
+				continue
+			}
+			for _, flow := range flows {
+				if err := flow.Delete(); err != nil {
+					return fmt.Errorf("failed to delete arp block flow of %s, error: %v", ipAddr, err)
+				}
+			}
+			delete(p.arpBlockFlows, ipAddr)
+			log.Infof("Unblock ARP and ND of %s", ipAddr)
+		}
+		for ipAddr := range ipAddrs {
+			if err := p.installARPBlockFlows(ipAddr); err != nil {
+				return err
+			}
+		}
+	}
+	p.arpBlockIPs = ipAddrs.Clone()
+	return nil
+}
+
+func (p *PolicyBridge) installARPBlockFlows(ipAddr string) error {
+	if _, ok := p.arpBlockFlows[ipAddr]; ok {
+		return nil
+	}
+	matches, err := arpBlockFlowMatches(ipAddr)
+	if err != nil {
+		return fmt.Errorf("invalid arp block ip %s: %s", ipAddr, err)
+	}
+
+	var flows []*ofctrl.Flow
+	for _, match := range matches {
+		// Table 0, drop ARP and ND of the endpoint
+		flow, _ := p.inputTable.NewFlow(match)
+		if err := flow.Next(p.OfSwitch.DropAction()); err != nil {
+			for _, installed := range flows {
+				_ = installed.Delete()
+			}
+			return fmt.Errorf("failed to install arp block flow of %s, error: %v", ipAddr, err)
+		}
+		flows = append(flows, flow)
+	}
+	p.arpBlockFlows[ipAddr] = flows
+	log.Infof("Block ARP and ND of %s", ipAddr)
+	return nil
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datapath
+
+import (
+	"encoding/binary"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/contiv/libOpenflow/protocol"
+	"github.com/contiv/ofnet/ofctrl"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/everoute/everoute/pkg/constants"
+)
+
+// decisionRuleLookupTimeout is the max time waiting for rules lock when recording a decision, the
+// decision is recorded without rule if rules are being replayed
+const decisionRuleLookupTimeout = 100 * time.Millisecond
+
+// PolicyDecision is the verdict of a packet decided by a work mode policy rule flow
+type PolicyDecision struct {
+	Time       time.Time
+	BridgeName string
+	SrcIP      string
+	DstIP      string
+	IPProtocol uint8
+	SrcPort    uint16
+	DstPort    uint16
+	FlowID     uint64
+	// RuleID and Action are empty if the rule of the flow unknown, e.g. removed before recording
+	RuleID string
+	Action string
+}
+
+// decisionRecorder records the latest policy decisions in a ring buffer
+type decisionRecorder struct {
+	lock      sync.Mutex
+	decisions []PolicyDecision
+	next      int // index of the next decision in decisions
+	full      bool
+}
+
+func newDecisionRecorder(size int) *decisionRecorder {
+	return &decisionRecorder{decisions: make([]PolicyDecision, size)}
+}
+
+// record saves the decision, the oldest decision would be overwritten if the buffer full
+func (r *decisionRecorder) record(decision PolicyDecision) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.decisions[r.next] = decision
+	r.next = (r.next + 1) % len(r.decisions)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// list returns the recorded decisions from the oldest to the latest
+func (r *decisionRecorder) list() []PolicyDecision {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.full {
+		return append([]PolicyDecision{}, r.decisions[:r.next]...)
+	}
+	return append(append([]PolicyDecision{}, r.decisions[r.next:]...), r.decisions[:r.next]...)
+}
+
+// recordDecision records the decision of the packet decided by the rule flow, and logs the decision if the
+// rule enabled logging
+func (datapathManager *DpManager) recordDecision(bridgeName string, flowID uint64, pkt *ofctrl.PacketIn) {
+	decision, ok := packetDecision(pkt)
+	if !ok {
+		return
+	}
+	decision.FlowID = flowID
+	decision.Time = time.Now()
+	decision.BridgeName = bridgeName
+
+	var logged bool
+	var loggingTags map[string]string
+	if datapathManager.flowReplayMutex.RTryLockWithTimeout(decisionRuleLookupTimeout) {
+		if entry := datapathManager.FlowIDToRules[flowID]; entry != nil && entry.EveroutePolicyRule != nil {
+			decision.RuleID = entry.EveroutePolicyRule.RuleID
+			decision.Action = entry.EveroutePolicyRule.Action
+			// connections of rules logging at a sample rate are sampled by the datapath, only sampled
+			// connections are marked for logging
+			logged = entry.EveroutePolicyRule.Logged && packetInReg(pkt, constants.OVSReg4)>>PolicyLogReg4Bit&0x1 == 0x1
+			loggingTags = entry.LoggingTags
+		}
+		datapathManager.flowReplayMutex.RUnlock()
+	}
+	if datapathManager.decisionRecorder != nil {
+		datapathManager.decisionRecorder.record(decision)
+	}
+	if logged {
+		datapathManager.decisionLogFunc(decision, loggingTags)
+	}
+}
+
+// logPolicyDecision logs the decision of the packet matched a rule enabled logging
+func logPolicyDecision(decision PolicyDecision, loggingTags map[string]string) {
+	log.WithFields(log.Fields{
+		"bridge":   decision.BridgeName,
+		"rule":     decision.RuleID,
+		"action":   decision.Action,
+		"src":      decision.SrcIP,
+		"dst":      decision.DstIP,
+		"protocol": decision.IPProtocol,
+		"srcPort":  decision.SrcPort,
+		"dstPort":  decision.DstPort,
+		"flowID":   decision.FlowID,
+		"tags":     loggingTags,
+	}).Info("Policy rule matched")
+}
+
+// GetPolicyDecisions returns the recorded policy decisions from the oldest to the latest, returns
+// nil if decision recording disabled
+func (datapathManager *DpManager) GetPolicyDecisions() []PolicyDecision {
+	if datapathManager.decisionRecorder == nil {
+		return nil
+	}
+	return datapathManager.decisionRecorder.list()
+}
+
+// packetDecision parses 5-tuple of the ip packet into decision, returns false if not ip packet
+func packetDecision(pkt *ofctrl.PacketIn) (PolicyDecision, bool) {
+	decision := PolicyDecision{}
+
+	var srcIP, dstIP net.IP
+	var payload interface{}
+	switch ip := pkt.Data.Data.(type) {
+	case *protocol.IPv4:
+		srcIP, dstIP, decision.IPProtocol, payload = ip.NWSrc, ip.NWDst, ip.Protocol, ip.Data
+	case *protocol.IPv6:
+		srcIP, dstIP, decision.IPProtocol, payload = ip.NWSrc, ip.NWDst, ipv6UpperProtocol(ip), ip.Data
+	default:
+		return decision, false
+	}
+	decision.SrcIP, decision.DstIP = srcIP.String(), dstIP.String()
+
+	switch l4 := payload.(type) {
+	case *protocol.UDP:
+		decision.SrcPort, decision.DstPort = l4.PortSrc, l4.PortDst
+	case interface{ MarshalBinary() ([]byte, error) }:
+		// tcp segment isn't parsed by the protocol package, ports are the first 4 bytes
+		if decision.IPProtocol != protocol.Type_TCP {
+			break
+		}
+		if data, err := l4.MarshalBinary(); err == nil && len(data) >= 4 {
+			decision.SrcPort, decision.DstPort = binary.BigEndian.Uint16(data[0:]), binary.BigEndian.Uint16(data[2:])
+		}
+	}
+	return decision, true
+}
+
+// ipv6UpperProtocol returns the protocol of the payload after ipv6 extension headers
+func ipv6UpperProtocol(ip *protocol.IPv6) uint8 {
+	switch {
+	case ip.FragmentHeader != nil:
+		return ip.FragmentHeader.NextHeader
+	case ip.RoutingHeader != nil:
+		return ip.RoutingHeader.NextHeader
+	case ip.HbhHeader != nil:
+		return ip.HbhHeader.NextHeader
+	}
+	return ip.NextHeader
+}
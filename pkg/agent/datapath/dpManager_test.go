@@ -0,0 +1,1739 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datapath
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/contiv/libOpenflow/openflow13"
+	"github.com/contiv/libOpenflow/protocol"
+	"github.com/contiv/libOpenflow/util"
+	"github.com/contiv/ofnet/ofctrl"
+	. "github.com/onsi/gomega"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/sets"
+	testclocks "k8s.io/utils/clock/testing"
+
+	"github.com/everoute/everoute/pkg/agent/metrics"
+	rpcv1alpha1 "github.com/everoute/everoute/pkg/apis/rpc/v1alpha1"
+	"github.com/everoute/everoute/pkg/constants"
+	"github.com/everoute/everoute/pkg/types"
+)
+
+func TestCleanConntrackFlowSaturation(t *testing.T) {
+	const chanSize, ruleNum, roundNum = 20, 15, 100
+
+	// saturate clean conntrack chan with rules, returns the times full flush required
+	saturate := func(dpMgr *DpManager, ruleNum int) int {
+		var fullFlushes int
+		for round := 0; round < roundNum; round++ {
+			for i := 0; i < ruleNum; i++ {
+				dpMgr.cleanConntrackFlow(&EveroutePolicyRule{RuleID: fmt.Sprintf("rule-%d", i)})
+				if dpMgr.getFlush() {
+					// simulate the full flush by cleanConntrackWorker
+					fullFlushes++
+					dpMgr.setFlush(false)
+				}
+			}
+		}
+		return fullFlushes
+	}
+	newDpManager := func(highWaterMark int) *DpManager {
+		dpMgr := NewDatapathManager(&DpManagerConfig{
+			ManagedVDSMap:        map[string]string{},
+			CTFlushHighWaterMark: highWaterMark,
+		}, nil)
+		dpMgr.cleanConntrackChan = make(chan EveroutePolicyRule, chanSize)
+		return dpMgr
+	}
+
+	t.Run("default strategy should full flush when chan overflow", func(t *testing.T) {
+		if fullFlushes := saturate(newDpManager(0), ruleNum); fullFlushes == 0 {
+			t.Errorf("expect full flush when chan overflow")
+		}
+	})
+
+	t.Run("high-water mark strategy should batch rules instead of full flush", func(t *testing.T) {
+		dpMgr := newDpManager(chanSize / 2)
+		if fullFlushes := saturate(dpMgr, ruleNum); fullFlushes != 0 {
+			t.Errorf("expect no full flush, got %d full flushes", fullFlushes)
+		}
+		pendingRules := mergeRuleList(dpMgr.takeCleanConntrackBatch(), receiveRuleListFromChan(dpMgr.cleanConntrackChan))
+		if len(pendingRules) != ruleNum {
+			t.Errorf("expect %d rules pending clean, got %d", ruleNum, len(pendingRules))
+		}
+	})
+
+	t.Run("high-water mark strategy should bound full flushes when batch overflow", func(t *testing.T) {
+		fullFlushes := saturate(newDpManager(chanSize/2), chanSize*2)
+		if fullFlushes == 0 || fullFlushes > roundNum*2 {
+			t.Errorf("expect full flushes in (0, %d], got %d", roundNum*2, fullFlushes)
+		}
+	})
+}
+
+// fakePolicyBridge is a connected policy bridge which installs rule flows without ovs
+type fakePolicyBridge struct {
+	*PolicyBridge
+	addErr error
+	// disconnected marks the bridge not ready
+	disconnected atomic.Bool
+	// lastRule is the last rule installed, the flow read back is built from it
+	lastRule *EveroutePolicyRule
+	// installedFlow mutates the flow read back from the installed flow
+	installedFlow func(flow *InstalledFlow)
+	// installedFlowIDs are the rule table flows read back from ovs
+	installedFlowIDs sets.Set[uint64]
+	// onBarrier is called on barrier before the rule table flows read back
+	onBarrier func()
+	// addNum is the number of rules installed
+	addNum int
+	// onAdd is called before the rule installed
+	onAdd func()
+	// ruleModes are the enforcement modes of the rules installed
+	ruleModes map[string]string
+	// addedEndpoints are interface uuids of the local endpoints added
+	addedEndpoints []string
+	// removedEndpoints are interface uuids of the local endpoints removed
+	removedEndpoints []string
+}
+
+func (b *fakePolicyBridge) IsSwitchConnected() bool {
+	return !b.disconnected.Load()
+}
+
+func (b *fakePolicyBridge) AddMicroSegmentRule(rule *EveroutePolicyRule, direction uint8, tier uint8, mode string) (*FlowEntry, error) {
+	if b.addErr != nil {
+		return nil, b.addErr
+	}
+	if b.onAdd != nil {
+		b.onAdd()
+	}
+	b.lastRule = rule
+	b.addNum++
+	if b.ruleModes == nil {
+		b.ruleModes = make(map[string]string)
+	}
+	b.ruleModes[rule.RuleID] = mode
+	return &FlowEntry{
+		Table:    &ofctrl.Table{TableId: INGRESS_TIER2_TABLE},
+		Priority: uint16(rule.Priority),
+		FlowID:   uint64(rand.Int()),
+	}, nil
+}
+
+func (b *fakePolicyBridge) preparePolicyTables(tiers PolicyTiers) {}
+
+func (b *fakePolicyBridge) switchPolicyTables(tiers PolicyTiers) error {
+	b.setRuleTables(tiers.ruleTables())
+	return nil
+}
+
+func (b *fakePolicyBridge) AddLocalEndpoint(endpoint *Endpoint) error {
+	b.addedEndpoints = append(b.addedEndpoints, endpoint.InterfaceUUID)
+	return nil
+}
+
+func (b *fakePolicyBridge) RemoveLocalEndpoint(endpoint *Endpoint) error {
+	b.removedEndpoints = append(b.removedEndpoints, endpoint.InterfaceUUID)
+	return nil
+}
+
+func (b *fakePolicyBridge) ReadRuleFlow(flowEntry *FlowEntry) (*InstalledFlow, error) {
+	match, err := ruleFlowMatch(b.lastRule)
+	if err != nil {
+		return nil, err
+	}
+	flow := &InstalledFlow{TableID: flowEntry.Table.TableId, Priority: flowEntry.Priority, Cookie: flowEntry.FlowID, Match: match}
+	if b.installedFlow != nil {
+		b.installedFlow(flow)
+	}
+	return flow, nil
+}
+
+func (b *fakePolicyBridge) ReadRuleTableFlowIDs() (sets.Set[uint64], error) {
+	return b.installedFlowIDs, nil
+}
+
+func (b *fakePolicyBridge) barrier(time.Duration) error {
+	if b.onBarrier != nil {
+		b.onBarrier()
+	}
+	return nil
+}
+
+func getRuleFlowInstallMetric(t *testing.T, dpMgr *DpManager, name, vdsID, operation string) *dto.Metric {
+	mfs, err := dpMgr.AgentMetric.Registry().Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %s", err)
+	}
+	for _, mf := range mfs {
+		if mf.GetName() != name {
+			continue
+		}
+		for _, metric := range mf.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels[metrics.VDSLabel] == vdsID && labels[metrics.OperationLabel] == operation {
+				return metric
+			}
+		}
+	}
+	return nil
+}
+
+func TestRuleFlowInstallMetrics(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := NewDatapathManager(&DpManagerConfig{ManagedVDSMap: map[string]string{}}, nil)
+	bridge := &fakePolicyBridge{PolicyBridge: NewPolicyBridge("ovsbr1", dpMgr)}
+	dpMgr.BridgeChainMap["vds1"] = map[string]Bridge{POLICY_BRIDGE_KEYWORD: bridge}
+	rule := &EveroutePolicyRule{RuleID: "rule1", Priority: 200, SrcIPAddr: "10.100.100.1", Action: "allow"}
+
+	t.Run("histogram should observe a sample after rule add", func(t *testing.T) {
+		Expect(dpMgr.AddEveroutePolicyRule(rule, "rule1", POLICY_DIRECTION_IN, POLICY_TIER2, DEFAULT_POLICY_ENFORCEMENT_MODE)).Should(Succeed())
+		metric := getRuleFlowInstallMetric(t, dpMgr, "everoute_agent_rule_flow_install_duration_seconds", "vds1", metrics.RuleOperationAdd)
+		Expect(metric).ShouldNot(BeNil())
+		Expect(metric.GetHistogram().GetSampleCount()).Should(Equal(uint64(1)))
+	})
+
+	t.Run("histogram should observe a sample after rule update", func(t *testing.T) {
+		newRule := *rule
+		newRule.Priority = 300
+		Expect(dpMgr.AddEveroutePolicyRule(&newRule, "rule1", POLICY_DIRECTION_IN, POLICY_TIER2, DEFAULT_POLICY_ENFORCEMENT_MODE)).Should(Succeed())
+		metric := getRuleFlowInstallMetric(t, dpMgr, "everoute_agent_rule_flow_install_duration_seconds", "vds1", metrics.RuleOperationUpdate)
+		Expect(metric).ShouldNot(BeNil())
+		Expect(metric.GetHistogram().GetSampleCount()).Should(Equal(uint64(1)))
+	})
+
+	t.Run("failure counter should increase when rule install failed", func(t *testing.T) {
+		bridge.addErr = fmt.Errorf("some error")
+		failedRule := &EveroutePolicyRule{RuleID: "rule2", Priority: 200, DstIPAddr: "10.100.100.2", Action: "deny"}
+		Expect(dpMgr.AddEveroutePolicyRule(failedRule, "rule2", POLICY_DIRECTION_OUT, POLICY_TIER2, DEFAULT_POLICY_ENFORCEMENT_MODE)).ShouldNot(Succeed())
+		metric := getRuleFlowInstallMetric(t, dpMgr, "everoute_agent_rule_flow_install_failures_total", "vds1", metrics.RuleOperationAdd)
+		Expect(metric).ShouldNot(BeNil())
+		Expect(metric.GetCounter().GetValue()).Should(Equal(float64(1)))
+	})
+}
+
+func TestEndpointRuleMetrics(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := NewDatapathManager(&DpManagerConfig{ManagedVDSMap: map[string]string{}}, nil)
+	dpMgr.Config.ManagedVDSMap["vds1"] = "ovsbr1"
+	dpMgr.deleteFlowFunc = func(*ofctrl.Table, uint16, uint64) error { return nil }
+	dpMgr.BridgeChainMap["vds1"] = map[string]Bridge{POLICY_BRIDGE_KEYWORD: &fakePolicyBridge{PolicyBridge: NewPolicyBridge("ovsbr1", dpMgr)}}
+	endpoint := &Endpoint{InterfaceUUID: "uuid1", InterfaceName: "ep1", IPAddr: net.ParseIP("10.100.100.1"), BridgeName: "ovsbr1"}
+	endpointRuleFlows := func() float64 {
+		metric := getEndpointRuleMetric(t, dpMgr, "everoute_agent_endpoint_rule_flows", endpoint.InterfaceUUID)
+		if metric == nil {
+			return -1
+		}
+		return metric.GetGauge().GetValue()
+	}
+
+	Expect(dpMgr.AddLocalEndpoint(endpoint)).Should(Succeed())
+	Expect(endpointRuleFlows()).Should(Equal(float64(0)))
+
+	ingressRule := &EveroutePolicyRule{RuleID: "rule1", Priority: 200, DstIPAddr: "10.100.100.1/32", Action: "allow"}
+	egressRule := &EveroutePolicyRule{RuleID: "rule2", Priority: 200, SrcIPAddr: "10.100.100.1", Action: "allow"}
+	otherRule := &EveroutePolicyRule{RuleID: "rule3", Priority: 200, DstIPAddr: "10.100.100.2", Action: "allow"}
+	Expect(dpMgr.AddEveroutePolicyRule(ingressRule, "rule1", POLICY_DIRECTION_IN, POLICY_TIER2, DEFAULT_POLICY_ENFORCEMENT_MODE)).Should(Succeed())
+	Expect(dpMgr.AddEveroutePolicyRule(egressRule, "rule2", POLICY_DIRECTION_OUT, POLICY_TIER2, DEFAULT_POLICY_ENFORCEMENT_MODE)).Should(Succeed())
+	Expect(dpMgr.AddEveroutePolicyRule(otherRule, "rule3", POLICY_DIRECTION_IN, POLICY_TIER2, DEFAULT_POLICY_ENFORCEMENT_MODE)).Should(Succeed())
+	Expect(endpointRuleFlows()).Should(Equal(float64(2)))
+	Expect(getEndpointRuleMetric(t, dpMgr, "everoute_agent_endpoint_rules", endpoint.InterfaceUUID).GetGauge().GetValue()).Should(Equal(float64(2)))
+
+	t.Run("gauge should decrease after rule removed", func(t *testing.T) {
+		Expect(dpMgr.RemoveEveroutePolicyRule("rule2", "rule2")).Should(Succeed())
+		Expect(endpointRuleFlows()).Should(Equal(float64(1)))
+	})
+
+	t.Run("gauge should increase after rule updated to the endpoint ip", func(t *testing.T) {
+		updatedRule := *otherRule
+		updatedRule.DstIPAddr = "10.100.100.0/24"
+		Expect(dpMgr.AddEveroutePolicyRule(&updatedRule, "rule3", POLICY_DIRECTION_IN, POLICY_TIER2, DEFAULT_POLICY_ENFORCEMENT_MODE)).Should(Succeed())
+		Expect(endpointRuleFlows()).Should(Equal(float64(2)))
+		Expect(dpMgr.ruleIDsByIPAddr).ShouldNot(HaveKey("10.100.100.2"))
+	})
+
+	t.Run("gauge should be refreshed after endpoint ip changed", func(t *testing.T) {
+		localBridge := &LocalBridge{BaseBridge: BaseBridge{datapathManager: dpMgr}}
+		localBridge.cleanLocalIPAddr(0)
+		Expect(endpoint.IPAddr).Should(BeNil())
+		Expect(endpointRuleFlows()).Should(Equal(float64(0)))
+	})
+
+	t.Run("gauge should be removed after endpoint removed", func(t *testing.T) {
+		Expect(dpMgr.RemoveLocalEndpoint(endpoint)).Should(Succeed())
+		Expect(endpointRuleFlows()).Should(Equal(float64(-1)))
+	})
+}
+
+func TestRemoveMigratedLocalEndpoint(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := NewDatapathManager(&DpManagerConfig{ManagedVDSMap: map[string]string{}}, nil)
+	dpMgr.Config.ManagedVDSMap["vds1"] = "ovsbr1"
+	bridge := &fakePolicyBridge{PolicyBridge: NewPolicyBridge("ovsbr1", dpMgr)}
+	dpMgr.BridgeChainMap["vds1"] = map[string]Bridge{POLICY_BRIDGE_KEYWORD: bridge}
+	migrated := &Endpoint{InterfaceUUID: "uuid1", InterfaceName: "ep1", IPAddr: net.ParseIP("10.100.100.1"), MacAddrStr: "00:00:aa:aa:aa:01", BridgeName: "ovsbr1"}
+	other := &Endpoint{InterfaceUUID: "uuid2", InterfaceName: "ep2", IPAddr: net.ParseIP("10.100.100.2"), MacAddrStr: "00:00:aa:aa:aa:02", BridgeName: "ovsbr1"}
+	Expect(dpMgr.AddLocalEndpoint(migrated)).Should(Succeed())
+	Expect(dpMgr.AddLocalEndpoint(other)).Should(Succeed())
+
+	ips := []net.IP{net.ParseIP("10.100.100.1")}
+
+	t.Run("should not remove endpoint with different mac", func(t *testing.T) {
+		removed, err := dpMgr.RemoveMigratedLocalEndpoint("00:00:aa:aa:aa:03", ips)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(removed).Should(BeEmpty())
+		Expect(bridge.removedEndpoints).Should(BeEmpty())
+	})
+
+	t.Run("should not remove endpoint by ip only", func(t *testing.T) {
+		removed, err := dpMgr.RemoveMigratedLocalEndpoint("", ips)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(removed).Should(BeEmpty())
+		Expect(bridge.removedEndpoints).Should(BeEmpty())
+	})
+
+	t.Run("should remove flows of the migrated endpoint", func(t *testing.T) {
+		removed, err := dpMgr.RemoveMigratedLocalEndpoint("00:00:AA:AA:AA:01", ips)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(removed).Should(ConsistOf("uuid1"))
+		Expect(bridge.removedEndpoints).Should(ConsistOf("uuid1"))
+		Expect(dpMgr.localEndpointDB.Has("uuid1")).Should(BeFalse())
+		Expect(dpMgr.localEndpointDB.Has("uuid2")).Should(BeTrue())
+		Expect(dpMgr.GetLocalEndpointIPs()).Should(ConsistOf("10.100.100.2/32"))
+	})
+
+	t.Run("should restore flows of the endpoint migrated back", func(t *testing.T) {
+		updated := copyEp(migrated)
+		updated.PortNo = 12
+		Expect(dpMgr.UpdateLocalEndpoint(updated, migrated)).Should(Succeed())
+		Expect(bridge.addedEndpoints).Should(ConsistOf("uuid1", "uuid2"))
+
+		restored, err := dpMgr.RestoreMigratedLocalEndpoint("00:00:aa:aa:aa:01")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(restored).Should(ConsistOf("uuid1"))
+		Expect(bridge.addedEndpoints).Should(ConsistOf("uuid1", "uuid2", "uuid1"))
+		Expect(dpMgr.migratedEndpoints.Has("uuid1")).Should(BeFalse())
+		Expect(dpMgr.GetLocalEndpointIPs()).Should(ConsistOf("10.100.100.1/32", "10.100.100.2/32"))
+		ep, _ := dpMgr.localEndpointDB.Get("uuid1")
+		Expect(ep.(*Endpoint).PortNo).Should(Equal(uint32(12)))
+	})
+
+	t.Run("should forget the migrated endpoint after ovsdb interface removed", func(t *testing.T) {
+		_, err := dpMgr.RemoveMigratedLocalEndpoint("00:00:aa:aa:aa:01", ips)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(dpMgr.RemoveLocalEndpoint(migrated)).Should(Succeed())
+		Expect(bridge.removedEndpoints).Should(ConsistOf("uuid1", "uuid1"))
+		Expect(dpMgr.migratedEndpoints.Has("uuid1")).Should(BeFalse())
+		Expect(dpMgr.RemoveLocalEndpoint(migrated)).ShouldNot(Succeed())
+	})
+}
+
+func getEndpointRuleMetric(t *testing.T, dpMgr *DpManager, name, interfaceUUID string) *dto.Metric {
+	mfs, err := dpMgr.AgentMetric.Registry().Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %s", err)
+	}
+	for _, mf := range mfs {
+		if mf.GetName() != name {
+			continue
+		}
+		for _, metric := range mf.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == metrics.InterfaceUUIDLabel && label.GetValue() == interfaceUUID {
+					return metric
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func newFakeRuleSpecs(num int) []RuleSpec {
+	var specs []RuleSpec
+	for i := 0; i < num; i++ {
+		// rules would be referenced by two rule names
+		index := i % (num/2 + 1)
+		rule := &EveroutePolicyRule{
+			RuleID:    fmt.Sprintf("rule-%d", index),
+			Priority:  200,
+			SrcIPAddr: fmt.Sprintf("10.100.%d.%d", index/250, index%250+1),
+			Action:    "allow",
+		}
+		specs = append(specs, RuleSpec{
+			Rule:      rule,
+			RuleName:  fmt.Sprintf("policy/rule-%d", i),
+			Direction: POLICY_DIRECTION_IN,
+			Tier:      POLICY_TIER2,
+			Mode:      DEFAULT_POLICY_ENFORCEMENT_MODE,
+		})
+	}
+	return specs
+}
+
+func newFakeRuleDpManager() *DpManager {
+	dpMgr := NewDatapathManager(&DpManagerConfig{ManagedVDSMap: map[string]string{}}, nil)
+	dpMgr.BridgeChainMap["vds1"] = map[string]Bridge{POLICY_BRIDGE_KEYWORD: &fakePolicyBridge{PolicyBridge: NewPolicyBridge("ovsbr1", dpMgr)}}
+	return dpMgr
+}
+
+// ruleIndexerState returns rules state without flow ids, which allocated randomly by fakePolicyBridge
+func ruleIndexerState(dpMgr *DpManager) map[string]EveroutePolicyRuleEntry {
+	state := make(map[string]EveroutePolicyRuleEntry, len(dpMgr.Rules))
+	for ruleID, entry := range dpMgr.Rules {
+		item := *entry
+		item.RuleFlowMap = nil
+		state[ruleID] = item
+	}
+	return state
+}
+
+func TestBatchEveroutePolicyRules(t *testing.T) {
+	RegisterTestingT(t)
+
+	specs := newFakeRuleSpecs(100)
+	sequentialDpMgr, batchDpMgr := newFakeRuleDpManager(), newFakeRuleDpManager()
+
+	t.Run("batched add should produce the same rules as sequential add", func(t *testing.T) {
+		for _, spec := range specs {
+			Expect(sequentialDpMgr.AddEveroutePolicyRule(spec.Rule, spec.RuleName, spec.Direction, spec.Tier, spec.Mode)).Should(Succeed())
+		}
+		Expect(batchDpMgr.AddEveroutePolicyRules(context.Background(), specs)).Should(Succeed())
+
+		Expect(batchDpMgr.Rules).Should(HaveLen(len(specs)/2 + 1))
+		Expect(ruleIndexerState(batchDpMgr)).Should(Equal(ruleIndexerState(sequentialDpMgr)))
+	})
+
+	t.Run("batched remove should produce the same rules as sequential remove", func(t *testing.T) {
+		// remove only the rule references, rules still referenced by the other rule names
+		var refs []RuleRef
+		for _, spec := range specs[len(specs)/2+1:] {
+			refs = append(refs, RuleRef{RuleID: spec.Rule.RuleID, RuleName: spec.RuleName})
+		}
+		for _, ref := range refs {
+			Expect(sequentialDpMgr.RemoveEveroutePolicyRule(ref.RuleID, ref.RuleName)).Should(Succeed())
+		}
+		Expect(batchDpMgr.RemoveEveroutePolicyRules(context.Background(), refs)).Should(Succeed())
+
+		Expect(ruleIndexerState(batchDpMgr)).Should(Equal(ruleIndexerState(sequentialDpMgr)))
+	})
+
+	t.Run("batched add should clean conntrack of installed rules at once", func(t *testing.T) {
+		dpMgr := newFakeRuleDpManager()
+		Expect(dpMgr.AddEveroutePolicyRules(context.Background(), specs)).Should(Succeed())
+		Expect(dpMgr.cleanConntrackChan).Should(HaveLen(len(dpMgr.Rules)))
+	})
+
+	t.Run("batched add should stop when context canceled", func(t *testing.T) {
+		dpMgr := newFakeRuleDpManager()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		Expect(dpMgr.AddEveroutePolicyRules(ctx, specs)).ShouldNot(Succeed())
+		Expect(dpMgr.Rules).Should(BeEmpty())
+	})
+}
+
+func TestDeferCTCleanInBatch(t *testing.T) {
+	RegisterTestingT(t)
+
+	specs := newFakeRuleSpecs(100)
+	dpMgr := newFakeRuleDpManager()
+	dpMgr.Config.DeferCTCleanInBatch = true
+	var cleanPasses int
+	dpMgr.ctDeleteFunc = func(family netlink.InetFamily, filter netlink.CustomConntrackFilter) (uint, error) {
+		cleanPasses++
+		return 0, nil
+	}
+
+	ctx, batch := dpMgr.BeginConntrackBatch(context.Background())
+	Expect(dpMgr.AddEveroutePolicyRules(ctx, specs)).Should(Succeed())
+	Expect(dpMgr.RemoveEveroutePolicyRules(ctx, []RuleRef{{RuleID: specs[0].Rule.RuleID, RuleName: specs[0].RuleName}})).Should(Succeed())
+	Expect(dpMgr.cleanConntrackChan).Should(BeEmpty())
+
+	batch.Commit()
+	Expect(dpMgr.cleanConntrackChan).Should(HaveLen(len(dpMgr.Rules)))
+
+	// simulate one pass of cleanConntrackWorker
+	dpMgr.cleanConntrackByFamily(receiveRuleListFromChan(dpMgr.cleanConntrackChan))
+	Expect(dpMgr.cleanConntrackChan).Should(BeEmpty())
+	Expect(cleanPasses).Should(Equal(1))
+
+	t.Run("nested batch should clean conntrack when the outermost committed", func(t *testing.T) {
+		dpMgr := newFakeRuleDpManager()
+		dpMgr.Config.DeferCTCleanInBatch = true
+
+		ctx, outer := dpMgr.BeginConntrackBatch(context.Background())
+		ctx, inner := dpMgr.BeginConntrackBatch(ctx)
+		Expect(dpMgr.AddEveroutePolicyRules(ctx, specs)).Should(Succeed())
+		inner.Commit()
+		Expect(dpMgr.cleanConntrackChan).Should(BeEmpty())
+		outer.Commit()
+		Expect(dpMgr.cleanConntrackChan).Should(HaveLen(len(dpMgr.Rules)))
+	})
+
+	t.Run("rules changed out of the batch should not be deferred", func(t *testing.T) {
+		dpMgr := newFakeRuleDpManager()
+		dpMgr.Config.DeferCTCleanInBatch = true
+
+		_, batch := dpMgr.BeginConntrackBatch(context.Background())
+		defer batch.Commit()
+		Expect(dpMgr.AddEveroutePolicyRules(context.Background(), specs)).Should(Succeed())
+		Expect(dpMgr.cleanConntrackChan).Should(HaveLen(len(dpMgr.Rules)))
+	})
+
+	t.Run("conntrack should be cleaned when the batch deferred over max defer time", func(t *testing.T) {
+		dpMgr := newFakeRuleDpManager()
+		dpMgr.Config.DeferCTCleanInBatch = true
+		dpMgr.Config.MaxCTCleanDefer = 100 * time.Millisecond
+
+		ctx, batch := dpMgr.BeginConntrackBatch(context.Background())
+		defer batch.Commit()
+		Expect(dpMgr.AddEveroutePolicyRules(ctx, specs[:50])).Should(Succeed())
+		Expect(dpMgr.cleanConntrackChan).Should(BeEmpty())
+		Eventually(func() int { return len(dpMgr.cleanConntrackChan) }, time.Second, 10*time.Millisecond).Should(Equal(50))
+
+		// the batch expired, rules changed after that are cleaned immediately
+		Expect(dpMgr.AddEveroutePolicyRules(ctx, specs[50:])).Should(Succeed())
+		Expect(dpMgr.cleanConntrackChan).Should(HaveLen(len(dpMgr.Rules)))
+	})
+
+	t.Run("batch should take no effect when the option disabled", func(t *testing.T) {
+		dpMgr := newFakeRuleDpManager()
+		ctx, batch := dpMgr.BeginConntrackBatch(context.Background())
+		Expect(batch).Should(BeNil())
+		Expect(dpMgr.AddEveroutePolicyRules(ctx, specs)).Should(Succeed())
+		Expect(dpMgr.cleanConntrackChan).Should(HaveLen(len(dpMgr.Rules)))
+		batch.Commit()
+	})
+
+	t.Run("rules changed without conntrack clean should never clean conntrack", func(t *testing.T) {
+		dpMgr := newFakeRuleDpManager()
+		dpMgr.Config.DeferCTCleanInBatch = true
+
+		ctx, batch := dpMgr.BeginConntrackBatch(WithoutConntrackClean(context.Background()))
+		Expect(dpMgr.AddEveroutePolicyRules(ctx, specs)).Should(Succeed())
+		Expect(dpMgr.RemoveEveroutePolicyRules(ctx, []RuleRef{{RuleID: specs[0].Rule.RuleID, RuleName: specs[0].RuleName}})).Should(Succeed())
+		batch.Commit()
+		Expect(dpMgr.cleanConntrackChan).Should(BeEmpty())
+	})
+}
+
+func BenchmarkAddEveroutePolicyRules(b *testing.B) {
+	specs := newFakeRuleSpecs(500)
+
+	b.Run("sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			dpMgr := newFakeRuleDpManager()
+			b.StartTimer()
+			for _, spec := range specs {
+				_ = dpMgr.AddEveroutePolicyRule(spec.Rule, spec.RuleName, spec.Direction, spec.Tier, spec.Mode)
+			}
+		}
+	})
+
+	b.Run("batch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			dpMgr := newFakeRuleDpManager()
+			b.StartTimer()
+			_ = dpMgr.AddEveroutePolicyRules(context.Background(), specs)
+		}
+	})
+}
+
+func BenchmarkGetRuleConflicts(b *testing.B) {
+	dpMgr := newFakeRuleDpManager()
+	for i := 0; i < 2000; i++ {
+		action, tier := EveroutePolicyAllow, uint8(POLICY_TIER2)
+		if i%2 == 0 {
+			action = EveroutePolicyDeny
+		}
+		if i%4 < 2 {
+			tier = POLICY_TIER3
+		}
+		rule := &EveroutePolicyRule{
+			RuleID:    fmt.Sprintf("rule-%d", i),
+			Priority:  100 + i%50,
+			SrcIPAddr: fmt.Sprintf("10.%d.%d.0/%d", i/250, i%250, 24+i%8),
+			Action:    action,
+		}
+		dpMgr.Rules[rule.RuleID] = &EveroutePolicyRuleEntry{EveroutePolicyRule: rule, Direction: POLICY_DIRECTION_IN, Tier: tier,
+			Mode: DEFAULT_POLICY_ENFORCEMENT_MODE}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = dpMgr.GetRuleConflicts()
+	}
+}
+
+func TestVerifyRuleFlow(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := NewDatapathManager(&DpManagerConfig{ManagedVDSMap: map[string]string{}, VerifyRuleFlow: true}, nil)
+	bridge := &fakePolicyBridge{PolicyBridge: NewPolicyBridge("ovsbr1", dpMgr)}
+	dpMgr.BridgeChainMap["vds1"] = map[string]Bridge{POLICY_BRIDGE_KEYWORD: bridge}
+	dpMgr.deleteFlowFunc = func(*ofctrl.Table, uint16, uint64) error { return nil }
+
+	t.Run("rule flow installed as expected should pass verification", func(t *testing.T) {
+		rule := &EveroutePolicyRule{RuleID: "rule1", Priority: 200, SrcIPAddr: "10.100.100.0/24", IPProtocol: PROTOCOL_TCP,
+			DstPort: 0x1000, DstPortMask: 0xf000, Action: "allow"}
+		Expect(dpMgr.AddEveroutePolicyRule(rule, "rule1", POLICY_DIRECTION_IN, POLICY_TIER2, DEFAULT_POLICY_ENFORCEMENT_MODE)).Should(Succeed())
+		Expect(dpMgr.Rules).Should(HaveKey("rule1"))
+	})
+
+	t.Run("rule flow installed with mismatched match should be flagged", func(t *testing.T) {
+		bridge.installedFlow = func(flow *InstalledFlow) { flow.Match["nw_src"] = "10.100.100.3" }
+		rule := &EveroutePolicyRule{RuleID: "rule2", Priority: 200, SrcIPAddr: "10.100.100.2", Action: "deny"}
+		err := dpMgr.AddEveroutePolicyRule(rule, "rule2", POLICY_DIRECTION_OUT, POLICY_TIER2, DEFAULT_POLICY_ENFORCEMENT_MODE)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).Should(ContainSubstring("expect nw_src=10.100.100.2"))
+		Expect(dpMgr.Rules).ShouldNot(HaveKey("rule2"))
+
+		metric := getRuleFlowInstallMetric(t, dpMgr, "everoute_agent_rule_flow_install_failures_total", "vds1", metrics.RuleOperationAdd)
+		Expect(metric).ShouldNot(BeNil())
+		Expect(metric.GetCounter().GetValue()).Should(Equal(float64(1)))
+	})
+
+	t.Run("rule flow installed with mismatched priority should be flagged", func(t *testing.T) {
+		bridge.installedFlow = func(flow *InstalledFlow) { flow.Priority++ }
+		rule := &EveroutePolicyRule{RuleID: "rule3", Priority: 200, DstIPAddr: "10.100.100.3", Action: "allow"}
+		Expect(dpMgr.AddEveroutePolicyRule(rule, "rule3", POLICY_DIRECTION_IN, POLICY_TIER2, DEFAULT_POLICY_ENFORCEMENT_MODE)).ShouldNot(Succeed())
+		Expect(dpMgr.Rules).ShouldNot(HaveKey("rule3"))
+	})
+
+	t.Run("shared rule failed to verify should be reinstalled on retry", func(t *testing.T) {
+		bridge.installedFlow = nil
+		rule := &EveroutePolicyRule{RuleID: "rule4", Priority: 200, DstIPAddr: "10.100.100.4", Action: "allow"}
+		Expect(dpMgr.AddEveroutePolicyRule(rule, "policy1/rule4", POLICY_DIRECTION_IN, POLICY_TIER2, DEFAULT_POLICY_ENFORCEMENT_MODE)).Should(Succeed())
+		Expect(dpMgr.AddEveroutePolicyRule(rule, "policy2/rule4", POLICY_DIRECTION_IN, POLICY_TIER2, DEFAULT_POLICY_ENFORCEMENT_MODE)).Should(Succeed())
+
+		bridge.installedFlow = func(flow *InstalledFlow) { flow.Priority++ }
+		newRule := &EveroutePolicyRule{RuleID: "rule4", Priority: 200, DstIPAddr: "10.100.100.4", IPProtocol: PROTOCOL_TCP, Action: "allow"}
+		Expect(dpMgr.AddEveroutePolicyRule(newRule, "policy2/rule4", POLICY_DIRECTION_IN, POLICY_TIER2, DEFAULT_POLICY_ENFORCEMENT_MODE)).ShouldNot(Succeed())
+		Expect(dpMgr.Rules).Should(HaveKey("rule4"))
+		Expect(dpMgr.Rules["rule4"].PolicyRuleReference.List()).Should(ConsistOf("policy1/rule4", "policy2/rule4"))
+		Expect(dpMgr.Rules["rule4"].RuleFlowMap).Should(BeEmpty())
+
+		bridge.installedFlow = nil
+		Expect(dpMgr.AddEveroutePolicyRule(newRule, "policy2/rule4", POLICY_DIRECTION_IN, POLICY_TIER2, DEFAULT_POLICY_ENFORCEMENT_MODE)).Should(Succeed())
+		Expect(dpMgr.Rules["rule4"].RuleFlowMap).Should(HaveKey("vds1"))
+	})
+}
+
+func TestCTTimeoutPolicy(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := NewDatapathManager(&DpManagerConfig{ManagedVDSMap: map[string]string{}, CTTimeoutPolicy: map[string]uint32{"udp": 10}}, nil)
+	bridge := &fakePolicyBridge{PolicyBridge: NewPolicyBridge("ovsbr1", dpMgr)}
+	dpMgr.BridgeChainMap["vds1"] = map[string]Bridge{POLICY_BRIDGE_KEYWORD: bridge}
+	var appliedZones []uint16
+	var applyErr error
+	dpMgr.ctTimeoutPolicyFunc = func(zone uint16, policy map[string]uint32) error {
+		if applyErr != nil {
+			return applyErr
+		}
+		Expect(policy).Should(Equal(map[string]uint32{"udp": 10}))
+		appliedZones = append(appliedZones, zone)
+		return nil
+	}
+
+	t.Run("rule without ct timeout should not apply ct timeout policy", func(t *testing.T) {
+		rule := &EveroutePolicyRule{RuleID: "rule1", Priority: 200, SrcIPAddr: "10.100.100.1", Action: "allow"}
+		Expect(dpMgr.AddEveroutePolicyRule(rule, "rule1", POLICY_DIRECTION_IN, POLICY_TIER2, DEFAULT_POLICY_ENFORCEMENT_MODE)).Should(Succeed())
+		Expect(appliedZones).Should(BeEmpty())
+	})
+
+	t.Run("failed to apply ct timeout policy should fail the rule", func(t *testing.T) {
+		applyErr = fmt.Errorf("some error")
+		rule := &EveroutePolicyRule{RuleID: "rule2", Priority: 200, SrcIPAddr: "10.100.100.2", Action: "allow", CTTimeout: true}
+		Expect(dpMgr.AddEveroutePolicyRule(rule, "rule2", POLICY_DIRECTION_IN, POLICY_TIER2, DEFAULT_POLICY_ENFORCEMENT_MODE)).ShouldNot(Succeed())
+		Expect(dpMgr.Rules).ShouldNot(HaveKey("rule2"))
+		applyErr = nil
+	})
+
+	t.Run("ct timeout policy should be applied to the ct timeout zone once", func(t *testing.T) {
+		for _, ruleID := range []string{"rule2", "rule3"} {
+			rule := &EveroutePolicyRule{RuleID: ruleID, Priority: 200, DstIPAddr: "10.100.100.3", Action: "allow", CTTimeout: true}
+			Expect(dpMgr.AddEveroutePolicyRule(rule, ruleID, POLICY_DIRECTION_IN, POLICY_TIER2, DEFAULT_POLICY_ENFORCEMENT_MODE)).Should(Succeed())
+		}
+		Expect(appliedZones).Should(Equal([]uint16{constants.CTZoneForPolicyCTTimeout}))
+	})
+
+	t.Run("conntrack of rules should be cleaned in the ct timeout zone", func(t *testing.T) {
+		rule := dpMgr.conntrackCleanRule(dpMgr.Rules["rule3"].EveroutePolicyRule, POLICY_DIRECTION_IN)
+		Expect(rule.CTZones).Should(ConsistOf(constants.CTZoneForPolicy, constants.CTZoneForPolicyCTTimeout))
+	})
+
+	t.Run("ct timeout policy should not be applied when assign ct zone by vlan", func(t *testing.T) {
+		vlanDpMgr := NewDatapathManager(&DpManagerConfig{ManagedVDSMap: map[string]string{}, CTZoneStrategy: CTZoneStrategyVlan,
+			CTTimeoutPolicy: map[string]uint32{"udp": 10}}, nil)
+		vlanDpMgr.ctTimeoutPolicyFunc = dpMgr.ctTimeoutPolicyFunc
+		appliedZones = nil
+		Expect(vlanDpMgr.applyCTTimeoutPolicy()).Should(Succeed())
+		Expect(appliedZones).Should(BeEmpty())
+	})
+}
+
+func newFlowStats(tableID uint8, priority uint16, cookie uint64, ctLabelMatch bool) *openflow13.FlowStats {
+	flowStats := openflow13.NewFlowStats()
+	flowStats.TableId = tableID
+	flowStats.Priority = priority
+	flowStats.Cookie = cookie
+	if ctLabelMatch {
+		field, _ := openflow13.FindFieldHeaderByName("NXM_NX_CT_LABEL", true)
+		flowStats.Match.Fields = append(flowStats.Match.Fields, *field)
+	}
+	return flowStats
+}
+
+func TestDetectLeakedFlows(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := NewDatapathManager(&DpManagerConfig{ManagedVDSMap: map[string]string{}}, nil)
+	policyBridge := NewPolicyBridge("ovsbr1", dpMgr)
+	dpMgr.BridgeChainMap["vds1"] = map[string]Bridge{POLICY_BRIDGE_KEYWORD: policyBridge}
+	dpMgr.FlowIDToRules[0x10000001] = &EveroutePolicyRuleEntry{
+		EveroutePolicyRule: &EveroutePolicyRule{RuleID: "rule1", Priority: 200, Action: "allow"},
+	}
+
+	// plant an orphan flow in the policy rule table
+	policyBridge.MultipartReply(nil, &openflow13.MultipartReply{
+		Type: openflow13.MultipartType_Flow,
+		Body: []util.Message{
+			newFlowStats(INGRESS_TIER2_TABLE, 200, 0x10000001, false),
+			newFlowStats(INGRESS_TIER3_TABLE, 300, 0x10000002, false),
+			newFlowStats(INGRESS_TIER2_TABLE, DEFAULT_FLOW_MISS_PRIORITY, 0x10000003, false),
+			newFlowStats(INGRESS_TIER3_MONITOR_TABLE, HIGH_MATCH_FLOW_PRIORITY, 0x10000004, true),
+			newFlowStats(CT_COMMIT_TABLE, MID_MATCH_FLOW_PRIORITY, 0x10000005, false),
+		},
+	})
+
+	t.Run("flows of the running flow stats request should not be diffed", func(t *testing.T) {
+		Expect(dpMgr.DetectLeakedFlows(false)).Should(BeEmpty())
+	})
+
+	t.Run("orphan flow should be detected", func(t *testing.T) {
+		policyBridge.completeRuleTableFlows()
+		leakedFlows := dpMgr.DetectLeakedFlows(false)
+		Expect(leakedFlows).Should(HaveLen(1))
+		Expect(leakedFlows[0].VdsID).Should(Equal("vds1"))
+		Expect(leakedFlows[0].BridgeName).Should(Equal("ovsbr1-policy"))
+		Expect(leakedFlows[0].FlowEntry.FlowID).Should(Equal(uint64(0x10000002)))
+		Expect(leakedFlows[0].FlowEntry.TableID).Should(Equal(uint32(INGRESS_TIER3_TABLE)))
+
+		mfs, err := dpMgr.AgentMetric.Registry().Gather()
+		Expect(err).Should(Succeed())
+		var leakedNum float64
+		for _, mf := range mfs {
+			if mf.GetName() == "everoute_agent_leaked_flows" {
+				leakedNum = mf.GetMetric()[0].GetGauge().GetValue()
+			}
+		}
+		Expect(leakedNum).Should(Equal(float64(1)))
+	})
+
+	t.Run("flow owned by rule should not be detected", func(t *testing.T) {
+		dpMgr.FlowIDToRules[0x10000002] = &EveroutePolicyRuleEntry{
+			EveroutePolicyRule: &EveroutePolicyRule{RuleID: "rule2", Priority: 300, Action: "deny"},
+		}
+		Expect(dpMgr.DetectLeakedFlows(false)).Should(BeEmpty())
+	})
+
+	t.Run("flow deleted with its rule should not be detected", func(t *testing.T) {
+		dpMgr.deleteFlowFunc = func(*ofctrl.Table, uint16, uint64) error { return nil }
+		entry := dpMgr.FlowIDToRules[0x10000002]
+		entry.RuleFlowMap = map[string]*FlowEntry{"vds1": {Priority: 300, FlowID: 0x10000002}}
+		Expect(dpMgr.deleteRuleFlows("rule2", entry)).Should(Succeed())
+		Expect(dpMgr.DetectLeakedFlows(false)).Should(BeEmpty())
+	})
+
+	t.Run("removed leaked flow should not be detected again", func(t *testing.T) {
+		var deletedFlowIDs []uint64
+		dpMgr.deleteFlowFunc = func(_ *ofctrl.Table, _ uint16, flowID uint64) error {
+			deletedFlowIDs = append(deletedFlowIDs, flowID)
+			return nil
+		}
+		policyBridge.MultipartReply(nil, &openflow13.MultipartReply{
+			Type: openflow13.MultipartType_Flow,
+			Body: []util.Message{
+				newFlowStats(INGRESS_TIER2_TABLE, 200, 0x10000001, false),
+				newFlowStats(INGRESS_TIER3_TABLE, 300, 0x10000006, false),
+			},
+		})
+		policyBridge.completeRuleTableFlows()
+		Expect(dpMgr.DetectLeakedFlows(true)).Should(HaveLen(1))
+		Expect(deletedFlowIDs).Should(Equal([]uint64{0x10000006}))
+		Expect(dpMgr.DetectLeakedFlows(false)).Should(BeEmpty())
+	})
+}
+
+func TestReplayVDSMicroSegmentFlow(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := NewDatapathManager(&DpManagerConfig{ManagedVDSMap: map[string]string{}}, nil)
+	bridge := &fakePolicyBridge{PolicyBridge: NewPolicyBridge("ovsbr1", dpMgr)}
+	dpMgr.BridgeChainMap["vds1"] = map[string]Bridge{POLICY_BRIDGE_KEYWORD: bridge}
+	var ctFlushNum int
+	dpMgr.ctFlushFunc = func() error {
+		ctFlushNum++
+		return nil
+	}
+
+	for i := 1; i <= 3; i++ {
+		rule := &EveroutePolicyRule{RuleID: fmt.Sprintf("rule%d", i), Priority: 200, SrcIPAddr: fmt.Sprintf("10.100.100.%d", i), Action: "allow"}
+		Expect(dpMgr.AddEveroutePolicyRule(rule, rule.RuleID, POLICY_DIRECTION_IN, POLICY_TIER2, DEFAULT_POLICY_ENFORCEMENT_MODE)).Should(Succeed())
+	}
+	for len(dpMgr.cleanConntrackChan) > 0 {
+		<-dpMgr.cleanConntrackChan
+	}
+
+	t.Run("unchanged rules should not be reinstalled", func(t *testing.T) {
+		rule1FlowID := dpMgr.Rules["rule1"].RuleFlowMap["vds1"].FlowID
+		rule2FlowID := dpMgr.Rules["rule2"].RuleFlowMap["vds1"].FlowID
+		rule3FlowID := dpMgr.Rules["rule3"].RuleFlowMap["vds1"].FlowID
+		bridge.installedFlowIDs = sets.New(rule1FlowID, rule2FlowID)
+		bridge.addNum = 0
+
+		Expect(dpMgr.ReplayVDSMicroSegmentFlow("vds1")).Should(Succeed())
+		Expect(bridge.addNum).Should(Equal(1))
+		Expect(dpMgr.Rules["rule1"].RuleFlowMap["vds1"].FlowID).Should(Equal(rule1FlowID))
+		Expect(dpMgr.Rules["rule2"].RuleFlowMap["vds1"].FlowID).Should(Equal(rule2FlowID))
+		Expect(dpMgr.Rules["rule3"].RuleFlowMap["vds1"].FlowID).ShouldNot(Equal(rule3FlowID))
+		Expect(dpMgr.FlowIDToRules[dpMgr.Rules["rule3"].RuleFlowMap["vds1"].FlowID]).Should(Equal(dpMgr.Rules["rule3"]))
+
+		Expect(ctFlushNum).Should(BeZero())
+		Expect(dpMgr.cleanConntrackChan).Should(HaveLen(1))
+		Expect((<-dpMgr.cleanConntrackChan).RuleID).Should(Equal("rule3"))
+	})
+
+	t.Run("all rules should be rebuilt when ovs reports no rule flows", func(t *testing.T) {
+		bridge.installedFlowIDs = nil
+		bridge.addNum = 0
+
+		Expect(dpMgr.ReplayVDSMicroSegmentFlow("vds1")).Should(Succeed())
+		Expect(bridge.addNum).Should(Equal(3))
+		Expect(ctFlushNum).Should(Equal(1))
+		Expect(dpMgr.cleanConntrackChan).Should(BeEmpty())
+	})
+}
+
+func TestReconcileRuleFlows(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := NewDatapathManager(&DpManagerConfig{ManagedVDSMap: map[string]string{}}, nil)
+	bridge := &fakePolicyBridge{PolicyBridge: NewPolicyBridge("ovsbr1", dpMgr)}
+	dpMgr.BridgeChainMap["vds1"] = map[string]Bridge{POLICY_BRIDGE_KEYWORD: bridge}
+
+	for i := 1; i <= 2; i++ {
+		rule := &EveroutePolicyRule{RuleID: fmt.Sprintf("rule%d", i), Priority: 200, SrcIPAddr: fmt.Sprintf("10.100.100.%d", i), Action: "allow"}
+		Expect(dpMgr.AddEveroutePolicyRule(rule, rule.RuleID, POLICY_DIRECTION_IN, POLICY_TIER2, DEFAULT_POLICY_ENFORCEMENT_MODE)).Should(Succeed())
+	}
+	for len(dpMgr.cleanConntrackChan) > 0 {
+		<-dpMgr.cleanConntrackChan
+	}
+	rule1FlowID := dpMgr.Rules["rule1"].RuleFlowMap["vds1"].FlowID
+	rule2FlowID := dpMgr.Rules["rule2"].RuleFlowMap["vds1"].FlowID
+
+	t.Run("rule flows all installed should not be reinstalled", func(t *testing.T) {
+		bridge.installedFlowIDs = sets.New(rule1FlowID, rule2FlowID)
+		bridge.addNum = 0
+
+		Expect(dpMgr.ReconcileRuleFlows()).Should(Succeed())
+		Expect(bridge.addNum).Should(BeZero())
+		Expect(dpMgr.cleanConntrackChan).Should(BeEmpty())
+	})
+
+	t.Run("rule flow removed out-of-band should be reinstalled", func(t *testing.T) {
+		bridge.installedFlowIDs = sets.New(rule1FlowID)
+		bridge.addNum = 0
+
+		Expect(dpMgr.ReconcileRuleFlows()).Should(Succeed())
+		Expect(bridge.addNum).Should(Equal(1))
+		Expect(dpMgr.Rules["rule1"].RuleFlowMap["vds1"].FlowID).Should(Equal(rule1FlowID))
+		newFlowID := dpMgr.Rules["rule2"].RuleFlowMap["vds1"].FlowID
+		Expect(newFlowID).ShouldNot(Equal(rule2FlowID))
+		Expect(dpMgr.FlowIDToRules).Should(HaveKeyWithValue(newFlowID, dpMgr.Rules["rule2"]))
+		Expect(dpMgr.FlowIDToRules).ShouldNot(HaveKey(rule2FlowID))
+
+		Expect(dpMgr.cleanConntrackChan).Should(HaveLen(1))
+		Expect((<-dpMgr.cleanConntrackChan).RuleID).Should(Equal("rule2"))
+
+		mfs, err := dpMgr.AgentMetric.Registry().Gather()
+		Expect(err).Should(Succeed())
+		var reinstalled float64
+		for _, mf := range mfs {
+			if mf.GetName() == "everoute_agent_rule_flow_reinstalled_total" {
+				reinstalled = mf.GetMetric()[0].GetCounter().GetValue()
+			}
+		}
+		Expect(reinstalled).Should(Equal(float64(1)))
+	})
+
+	t.Run("rule flow replaced after snapshot should not be reinstalled", func(t *testing.T) {
+		rule1 := dpMgr.Rules["rule1"]
+		rule1FlowID := rule1.RuleFlowMap["vds1"].FlowID
+		bridge.installedFlowIDs = sets.New(dpMgr.Rules["rule2"].RuleFlowMap["vds1"].FlowID)
+		bridge.onBarrier = func() {
+			// reading rule flows should not hold the flow replay lock
+			dpMgr.lockflowReplayWithTimeout()
+			defer dpMgr.flowReplayMutex.Unlock()
+			Expect(dpMgr.replayRuleFlow("vds1", "rule1", rule1)).Should(Succeed())
+		}
+		defer func() { bridge.onBarrier = nil }()
+		bridge.addNum = 0
+
+		Expect(dpMgr.ReconcileRuleFlows()).Should(Succeed())
+		Expect(bridge.addNum).Should(Equal(1))
+		Expect(rule1.RuleFlowMap["vds1"].FlowID).ShouldNot(Equal(rule1FlowID))
+		Expect(dpMgr.cleanConntrackChan).Should(BeEmpty())
+	})
+
+	t.Run("bridge reporting no rule flows should be skipped", func(t *testing.T) {
+		bridge.installedFlowIDs = nil
+		bridge.addNum = 0
+
+		Expect(dpMgr.ReconcileRuleFlows()).Should(Succeed())
+		Expect(bridge.addNum).Should(BeZero())
+	})
+}
+
+func TestReplayProgress(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := newFakeRuleDpManager()
+	bridge := dpMgr.BridgeChainMap["vds1"][POLICY_BRIDGE_KEYWORD].(*fakePolicyBridge)
+	dpMgr.ctFlushFunc = func() error { return nil }
+	Expect(dpMgr.GetReplayStatus()).Should(BeEmpty())
+
+	specs := newFakeRuleSpecs(10)
+	Expect(dpMgr.AddEveroutePolicyRules(context.Background(), specs)).Should(Succeed())
+	total := len(dpMgr.Rules)
+
+	// record progress reported during the replay
+	var progressReplayed []uint32
+	bridge.onAdd = func() {
+		status := dpMgr.GetReplayStatus()
+		Expect(status).Should(HaveLen(1))
+		Expect(status[0].GetInProgress()).Should(BeTrue())
+		Expect(status[0].GetTotalRules()).Should(Equal(uint32(total)))
+		progressReplayed = append(progressReplayed, status[0].GetReplayedRules())
+	}
+	Expect(dpMgr.ReplayVDSMicroSegmentFlow("vds1")).Should(Succeed())
+
+	Expect(progressReplayed).Should(HaveLen(total))
+	for i := range progressReplayed {
+		Expect(progressReplayed[i]).Should(Equal(uint32(i)))
+	}
+	status := dpMgr.GetReplayStatus()
+	Expect(status).Should(HaveLen(1))
+	Expect(status[0].GetVdsID()).Should(Equal("vds1"))
+	Expect(status[0].GetReplayedRules()).Should(Equal(uint32(total)))
+	Expect(status[0].GetInProgress()).Should(BeFalse())
+	Expect(status[0].GetFinishTime()).ShouldNot(BeZero())
+
+	mfs, err := dpMgr.AgentMetric.Registry().Gather()
+	Expect(err).ShouldNot(HaveOccurred())
+	gauges := make(map[string]float64)
+	for _, mf := range mfs {
+		for _, metric := range mf.GetMetric() {
+			if metric.GetGauge() != nil {
+				gauges[mf.GetName()] = metric.GetGauge().GetValue()
+			}
+		}
+	}
+	Expect(gauges).Should(HaveKeyWithValue("everoute_agent_rule_replay_replayed_rules", float64(total)))
+	Expect(gauges).Should(HaveKeyWithValue("everoute_agent_rule_replay_total_rules", float64(total)))
+
+	t.Run("failed replay should be finished with the error", func(t *testing.T) {
+		bridge.onAdd = nil
+		bridge.addErr = fmt.Errorf("bridge disconnected")
+		defer func() { bridge.addErr = nil }()
+
+		Expect(dpMgr.ReplayVDSMicroSegmentFlow("vds1")).ShouldNot(Succeed())
+		status := dpMgr.GetReplayStatus()
+		Expect(status).Should(HaveLen(1))
+		Expect(status[0].GetReplayedRules()).Should(BeZero())
+		Expect(status[0].GetInProgress()).Should(BeFalse())
+		Expect(status[0].GetFinishTime()).ShouldNot(BeZero())
+		Expect(status[0].GetError()).Should(ContainSubstring("bridge disconnected"))
+	})
+}
+
+func TestRuleGroup(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := newFakeRuleDpManager()
+	bridge := dpMgr.BridgeChainMap["vds1"][POLICY_BRIDGE_KEYWORD].(*fakePolicyBridge)
+	deletedFlowIDs := sets.New[uint64]()
+	dpMgr.deleteFlowFunc = func(table *ofctrl.Table, priority uint16, flowID uint64) error {
+		deletedFlowIDs.Insert(flowID)
+		return nil
+	}
+
+	var specs []RuleSpec
+	for i := 1; i <= 4; i++ {
+		group := "pci"
+		if i%2 == 0 {
+			group = "dev"
+		}
+		rule := &EveroutePolicyRule{RuleID: fmt.Sprintf("rule%d", i), Priority: 200, SrcIPAddr: fmt.Sprintf("10.100.100.%d", i), Action: "allow"}
+		specs = append(specs, RuleSpec{Rule: rule, RuleName: rule.RuleID, Direction: POLICY_DIRECTION_IN,
+			Tier: POLICY_TIER2, Mode: DEFAULT_POLICY_ENFORCEMENT_MODE, RuleGroup: group})
+	}
+	Expect(dpMgr.AddEveroutePolicyRules(context.Background(), specs)).Should(Succeed())
+	for len(dpMgr.cleanConntrackChan) > 0 {
+		<-dpMgr.cleanConntrackChan
+	}
+	devFlowIDs := sets.New(dpMgr.Rules["rule2"].RuleFlowMap["vds1"].FlowID, dpMgr.Rules["rule4"].RuleFlowMap["vds1"].FlowID)
+	pciFlowIDs := sets.New(dpMgr.Rules["rule1"].RuleFlowMap["vds1"].FlowID, dpMgr.Rules["rule3"].RuleFlowMap["vds1"].FlowID)
+
+	t.Run("disable group should only remove flows of the group", func(t *testing.T) {
+		Expect(dpMgr.SetRuleGroupEnabled("pci", false)).Should(Succeed())
+
+		Expect(deletedFlowIDs).Should(Equal(pciFlowIDs))
+		Expect(dpMgr.Rules).Should(HaveLen(4))
+		Expect(dpMgr.Rules["rule1"].RuleFlowMap).Should(BeEmpty())
+		Expect(dpMgr.Rules["rule3"].RuleFlowMap).Should(BeEmpty())
+		Expect(dpMgr.Rules["rule2"].RuleFlowMap).Should(HaveKey("vds1"))
+		Expect(dpMgr.Rules["rule4"].RuleFlowMap).Should(HaveKey("vds1"))
+		Expect(sets.KeySet(dpMgr.FlowIDToRules)).Should(Equal(devFlowIDs))
+		Expect(dpMgr.cleanConntrackChan).Should(HaveLen(2))
+		for len(dpMgr.cleanConntrackChan) > 0 {
+			Expect((<-dpMgr.cleanConntrackChan).RuleID).Should(BeElementOf("rule1", "rule3"))
+		}
+	})
+
+	t.Run("rule added to disabled group should not install flows", func(t *testing.T) {
+		bridge.addNum = 0
+		rule := &EveroutePolicyRule{RuleID: "rule5", Priority: 200, SrcIPAddr: "10.100.100.5", Action: "allow"}
+		Expect(dpMgr.AddEveroutePolicyRules(context.Background(), []RuleSpec{{Rule: rule, RuleName: rule.RuleID, Direction: POLICY_DIRECTION_IN,
+			Tier: POLICY_TIER2, Mode: DEFAULT_POLICY_ENFORCEMENT_MODE, RuleGroup: "pci"}})).Should(Succeed())
+		Expect(bridge.addNum).Should(BeZero())
+		Expect(dpMgr.Rules["rule5"].RuleFlowMap).Should(BeEmpty())
+		Expect(dpMgr.cleanConntrackChan).Should(BeEmpty())
+	})
+
+	t.Run("replay should skip rules of disabled group", func(t *testing.T) {
+		bridge.addNum = 0
+		bridge.installedFlowIDs = nil
+		Expect(dpMgr.ReplayVDSMicroSegmentFlow("vds1")).Should(Succeed())
+		Expect(bridge.addNum).Should(Equal(2))
+		Expect(dpMgr.Rules["rule1"].RuleFlowMap).Should(BeEmpty())
+	})
+
+	t.Run("enable group should reinstall flows of the group", func(t *testing.T) {
+		bridge.addNum = 0
+		Expect(dpMgr.SetRuleGroupEnabled("pci", true)).Should(Succeed())
+
+		Expect(bridge.addNum).Should(Equal(3))
+		for _, ruleID := range []string{"rule1", "rule3", "rule5"} {
+			Expect(dpMgr.Rules[ruleID].RuleFlowMap).Should(HaveKey("vds1"))
+			Expect(dpMgr.FlowIDToRules[dpMgr.Rules[ruleID].RuleFlowMap["vds1"].FlowID]).Should(Equal(dpMgr.Rules[ruleID]))
+		}
+		Expect(dpMgr.cleanConntrackChan).Should(HaveLen(3))
+	})
+
+	t.Run("rule shared by groups should keep flows until all groups disabled", func(t *testing.T) {
+		rule := &EveroutePolicyRule{RuleID: "shared", Priority: 200, SrcIPAddr: "10.100.100.6", Action: "allow"}
+		newSpec := func(ruleName, group string) RuleSpec {
+			return RuleSpec{Rule: rule, RuleName: ruleName, Direction: POLICY_DIRECTION_IN, Tier: POLICY_TIER2,
+				Mode: DEFAULT_POLICY_ENFORCEMENT_MODE, RuleGroup: group}
+		}
+		Expect(dpMgr.AddEveroutePolicyRules(context.Background(), []RuleSpec{newSpec("shared-pci", "pci"), newSpec("shared-dev", "dev")})).Should(Succeed())
+		Expect(dpMgr.Rules["shared"].RuleFlowMap).Should(HaveKey("vds1"))
+
+		Expect(dpMgr.SetRuleGroupEnabled("pci", false)).Should(Succeed())
+		Expect(dpMgr.Rules["shared"].RuleFlowMap).Should(HaveKey("vds1"))
+		Expect(dpMgr.SetRuleGroupEnabled("dev", false)).Should(Succeed())
+		Expect(dpMgr.Rules["shared"].RuleFlowMap).Should(BeEmpty())
+
+		Expect(dpMgr.SetRuleGroupEnabled("dev", true)).Should(Succeed())
+		Expect(dpMgr.Rules["shared"].RuleFlowMap).Should(HaveKey("vds1"))
+		Expect(dpMgr.RemoveEveroutePolicyRule("shared", "shared-dev")).Should(Succeed())
+		Expect(dpMgr.Rules["shared"].RuleFlowMap).Should(BeEmpty())
+
+		Expect(dpMgr.AddEveroutePolicyRules(context.Background(), []RuleSpec{newSpec("shared-none", "")})).Should(Succeed())
+		Expect(dpMgr.Rules["shared"].RuleFlowMap).Should(HaveKey("vds1"))
+	})
+
+	Expect(dpMgr.SetRuleGroupEnabled("", false)).ShouldNot(Succeed())
+}
+
+func TestARPBlockReference(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := newFakeRuleDpManager()
+	dpMgr.deleteFlowFunc = func(*ofctrl.Table, uint16, uint64) error { return nil }
+	rule := &EveroutePolicyRule{RuleID: "flow-key", Priority: 200, DstIPAddr: "10.100.100.1/32", Action: EveroutePolicyDeny}
+	newSpec := func(ruleName string, blockARP bool) RuleSpec {
+		return RuleSpec{Rule: rule, RuleName: ruleName, Direction: POLICY_DIRECTION_IN, Tier: POLICY_TIER1,
+			Mode: DEFAULT_POLICY_ENFORCEMENT_MODE, BlockARP: blockARP}
+	}
+
+	Expect(dpMgr.AddEveroutePolicyRules(context.Background(), []RuleSpec{newSpec("isolation", true)})).Should(Succeed())
+	Expect(sets.List(dpMgr.arpBlockIPs())).Should(ConsistOf("10.100.100.1/32"))
+
+	t.Run("rule shared by a reference not blocking arp should keep arp blocked", func(t *testing.T) {
+		Expect(dpMgr.AddEveroutePolicyRules(context.Background(), []RuleSpec{newSpec("normal", false)})).Should(Succeed())
+		Expect(sets.List(dpMgr.arpBlockIPs())).Should(ConsistOf("10.100.100.1/32"))
+	})
+
+	t.Run("arp should be unblocked after the blocking reference removed", func(t *testing.T) {
+		Expect(dpMgr.RemoveEveroutePolicyRule(rule.RuleID, "isolation")).Should(Succeed())
+		Expect(dpMgr.Rules).Should(HaveKey(rule.RuleID))
+		Expect(dpMgr.arpBlockIPs()).Should(BeEmpty())
+	})
+
+	t.Run("arp should be blocked after the reference updated to block arp", func(t *testing.T) {
+		Expect(dpMgr.AddEveroutePolicyRules(context.Background(), []RuleSpec{newSpec("normal", true)})).Should(Succeed())
+		Expect(sets.List(dpMgr.arpBlockIPs())).Should(ConsistOf("10.100.100.1/32"))
+	})
+}
+
+func TestRuleEnforcementMode(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := newFakeRuleDpManager()
+	bridge := dpMgr.BridgeChainMap["vds1"][POLICY_BRIDGE_KEYWORD].(*fakePolicyBridge)
+	dpMgr.deleteFlowFunc = func(*ofctrl.Table, uint16, uint64) error { return nil }
+
+	// rules of the same policy, the new rule is monitored while the sibling rules drop
+	var specs []RuleSpec
+	for i, mode := range []string{"monitor", "work", "work"} {
+		rule := &EveroutePolicyRule{RuleID: fmt.Sprintf("rule%d", i), Priority: 200, SrcIPAddr: fmt.Sprintf("10.100.100.%d", i+1), Action: "deny"}
+		specs = append(specs, RuleSpec{Rule: rule, RuleName: fmt.Sprintf("ns/policy/ingress.rule%d", i), Direction: POLICY_DIRECTION_IN,
+			Tier: POLICY_TIER2, Mode: mode})
+	}
+	Expect(dpMgr.AddEveroutePolicyRules(context.Background(), specs)).Should(Succeed())
+	Expect(bridge.ruleModes).Should(Equal(map[string]string{"rule0": "monitor", "rule1": "work", "rule2": "work"}))
+	Expect(dpMgr.Rules["rule0"].Mode).Should(Equal("monitor"))
+	Expect(dpMgr.Rules["rule1"].Mode).Should(Equal("work"))
+
+	t.Run("rule mode changed should reinstall rule flow", func(t *testing.T) {
+		bridge.addNum = 0
+		specs[0].Mode = "work"
+		Expect(dpMgr.AddEveroutePolicyRules(context.Background(), specs[:1])).Should(Succeed())
+		Expect(bridge.addNum).Should(Equal(1))
+		Expect(bridge.ruleModes["rule0"]).Should(Equal("work"))
+		Expect(dpMgr.Rules["rule0"].Mode).Should(Equal("work"))
+	})
+}
+
+func TestPacketInLimiter(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := NewDatapathManager(&DpManagerConfig{ManagedVDSMap: map[string]string{}}, nil)
+	fakeClock := testclocks.NewFakePassiveClock(time.Now())
+	dpMgr.packetInLimiter = newPacketInLimiter(&PacketInLimitConfig{Rate: 10, Burst: 20, LearningRate: 5, LearningBurst: 10}, fakeClock, dpMgr.AgentMetric)
+	localBridge := NewLocalBridge("ovsbr1", dpMgr)
+	policyBridge := NewPolicyBridge("ovsbr1", dpMgr)
+
+	droppedPacketIn := func(bridge string, reason PacketInReason) float64 {
+		mfs, err := dpMgr.AgentMetric.Registry().Gather()
+		Expect(err).Should(Succeed())
+		for _, mf := range mfs {
+			if mf.GetName() != "everoute_agent_packet_in_dropped_total" {
+				continue
+			}
+			for _, metric := range mf.GetMetric() {
+				labels := make(map[string]string)
+				for _, label := range metric.GetLabel() {
+					labels[label.GetName()] = label.GetValue()
+				}
+				if labels["bridge"] == bridge && labels["reason"] == reason.String() {
+					return metric.GetCounter().GetValue()
+				}
+			}
+		}
+		return 0
+	}
+
+	// flood packet-in of learning from local bridge and reject from policy bridge, they are limited separately
+	for i := 0; i < 100; i++ {
+		localBridge.PacketRcvd(nil, &ofctrl.PacketIn{})
+		policyBridge.PacketRcvd(nil, &ofctrl.PacketIn{TableId: CT_DROP_TABLE, Data: protocol.Ethernet{}})
+	}
+	Expect(droppedPacketIn(localBridge.GetName(), PacketInLearning)).Should(Equal(float64(90)))
+	Expect(droppedPacketIn(policyBridge.GetName(), PacketInReject)).Should(Equal(float64(80)))
+
+	t.Run("packet-in of other reasons should not be limited by flooded reasons", func(t *testing.T) {
+		for i := 0; i < 20; i++ {
+			policyBridge.PacketRcvd(nil, &ofctrl.PacketIn{TableId: CT_STATE_TABLE, Data: protocol.Ethernet{}})
+		}
+		Expect(droppedPacketIn(policyBridge.GetName(), PacketInRSTDetect)).Should(BeZero())
+	})
+
+	t.Run("packet-in not sent by any feature should be ignored", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			policyBridge.PacketRcvd(nil, &ofctrl.PacketIn{TableId: CT_COMMIT_TABLE})
+		}
+		Expect(droppedPacketIn(policyBridge.GetName(), PacketInIPOptions)).Should(BeZero())
+	})
+
+	t.Run("limiter should allow packet-in at the rate", func(t *testing.T) {
+		fakeClock.SetTime(fakeClock.Now().Add(time.Second))
+		for i := 0; i < 100; i++ {
+			localBridge.PacketRcvd(nil, &ofctrl.PacketIn{})
+		}
+		Expect(droppedPacketIn(localBridge.GetName(), PacketInLearning)).Should(Equal(float64(185)))
+	})
+}
+
+func TestIPLearningIgnoreCIDRs(t *testing.T) {
+	RegisterTestingT(t)
+
+	ipUpdateChan := make(chan *types.EndpointIP, 10)
+	dpMgr := NewDatapathManager(&DpManagerConfig{ManagedVDSMap: map[string]string{}}, ipUpdateChan)
+	localBridge := newLocalBridge("ovsbr1", dpMgr)
+	mac, _ := net.ParseMAC("00:11:22:33:44:55")
+	dpMgr.localEndpointDB.Set("ep1", &Endpoint{InterfaceUUID: "ep1", PortNo: 1, MacAddrStr: mac.String(), BridgeName: "ovsbr1"})
+
+	arpPacket := func(ip string) protocol.Ethernet {
+		return protocol.Ethernet{Ethertype: PROTOCOL_ARP, HWSrc: mac, Data: &protocol.ARP{
+			Operation: ArpOperRequest, HWSrc: mac, IPSrc: net.ParseIP(ip).To4(), IPDst: net.ParseIP("10.0.0.254").To4(),
+		}}
+	}
+
+	localBridge.processArp(arpPacket("169.254.10.1"), 1)
+	Expect(localBridge.learnedIPAddressMap).Should(BeEmpty())
+	Expect(ipUpdateChan).Should(BeEmpty())
+
+	localBridge.processArp(arpPacket("10.0.0.1"), 1)
+	Expect(localBridge.learnedIPAddressMap).Should(HaveKey("10.0.0.1"))
+	Expect(ipUpdateChan).Should(HaveLen(1))
+	Expect((<-ipUpdateChan).IP.String()).Should(Equal("10.0.0.1"))
+
+	t.Run("configured cidrs should replace the default cidrs", func(t *testing.T) {
+		dpMgr := NewDatapathManager(&DpManagerConfig{ManagedVDSMap: map[string]string{}, IPLearningIgnoreCIDRs: []string{"10.0.0.0/24"}}, nil)
+		Expect(dpMgr.ipLearningIgnored(net.ParseIP("10.0.0.1"))).Should(BeTrue())
+		Expect(dpMgr.ipLearningIgnored(net.ParseIP("169.254.10.1"))).Should(BeFalse())
+	})
+}
+
+func TestInternalIPv6Rules(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := newFakeRuleDpManager()
+	dpMgr.deleteFlowFunc = func(*ofctrl.Table, uint16, uint64) error { return nil }
+	bridge := dpMgr.BridgeChainMap["vds1"][POLICY_BRIDGE_KEYWORD].(*fakePolicyBridge)
+
+	Expect(dpMgr.addIntenalIP("fd00::10", 2)).Should(Succeed())
+	Expect(dpMgr.Rules).Should(HaveKey("internal.ingress.fd00::10"))
+	Expect(dpMgr.Rules).Should(HaveKey("internal.egress.fd00::10"))
+	ingress, egress := dpMgr.Rules["internal.ingress.fd00::10"], dpMgr.Rules["internal.egress.fd00::10"]
+	Expect(ingress.EveroutePolicyRule.DstIPAddr).Should(Equal("fd00::10"))
+	Expect(ingress.EveroutePolicyRule.Action).Should(Equal(EveroutePolicyAllow))
+	Expect(ingress.Direction).Should(Equal(uint8(POLICY_DIRECTION_IN)))
+	Expect(egress.EveroutePolicyRule.SrcIPAddr).Should(Equal("fd00::10"))
+	Expect(egress.Direction).Should(Equal(uint8(POLICY_DIRECTION_OUT)))
+	Expect(bridge.addNum).Should(Equal(2))
+
+	match, err := ruleFlowMatch(ingress.EveroutePolicyRule)
+	Expect(err).Should(Succeed())
+	Expect(match).Should(Equal(map[string]string{"ipv6": "", "ipv6_dst": "fd00::10"}))
+
+	t.Run("ipv6 rule flow should match ipv6 address", func(t *testing.T) {
+		ipSa, ipSaMask, err := ParseIPAddrMaskString("fd00::/64")
+		Expect(err).Should(Succeed())
+		flowMatch := ofctrl.FlowMatch{Ethertype: PROTOCOL_IP, IpSa: ipSa, IpSaMask: ipSaMask}
+		Expect(setRuleFlowIPv6Match(&flowMatch)).Should(Succeed())
+		Expect(flowMatch.Ethertype).Should(Equal(uint16(PROTOCOL_IPV6)))
+		Expect(flowMatch.IpSa).Should(BeNil())
+		Expect(flowMatch.Ipv6Sa.String()).Should(Equal("fd00::"))
+		Expect(flowMatch.Ipv6SaMask.String()).Should(Equal("ffff:ffff:ffff:ffff::"))
+
+		ipDa, ipDaMask, _ := ParseIPAddrMaskString("10.0.0.1")
+		flowMatch = ofctrl.FlowMatch{Ethertype: PROTOCOL_IP, IpSa: ipSa, IpSaMask: ipSaMask, IpDa: ipDa, IpDaMask: ipDaMask}
+		Expect(setRuleFlowIPv6Match(&flowMatch)).ShouldNot(Succeed())
+	})
+
+	t.Run("remove ipv6 internal ip should remove the rules", func(t *testing.T) {
+		dpMgr.removeIntenalIP("fd00::10", 2)
+		Expect(dpMgr.Rules).ShouldNot(HaveKey("internal.ingress.fd00::10"))
+		Expect(dpMgr.Rules).ShouldNot(HaveKey("internal.egress.fd00::10"))
+	})
+}
+
+func TestInternalIPInstallDeferred(t *testing.T) {
+	RegisterTestingT(t)
+	defer func(interval time.Duration) { internalIPRetryInterval = interval }(internalIPRetryInterval)
+	internalIPRetryInterval = 10 * time.Millisecond
+
+	install := func(dpMgr *DpManager) <-chan struct{} {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			dpMgr.installInternalIPs(make(chan struct{}))
+		}()
+		return done
+	}
+
+	t.Run("should defer install until bridges ready", func(t *testing.T) {
+		dpMgr := newFakeRuleDpManager()
+		dpMgr.Config.InternalIPs = []string{"10.0.0.10"}
+		bridge := dpMgr.BridgeChainMap["vds1"][POLICY_BRIDGE_KEYWORD].(*fakePolicyBridge)
+		bridge.disconnected.Store(true)
+
+		done := install(dpMgr)
+		Consistently(done, 100*time.Millisecond).ShouldNot(BeClosed())
+
+		bridge.disconnected.Store(false)
+		Eventually(done).Should(BeClosed())
+		Expect(dpMgr.Rules).Should(HaveKey("internal.ingress.10.0.0.10"))
+		Expect(dpMgr.Rules).Should(HaveKey("internal.egress.10.0.0.10"))
+	})
+
+	t.Run("should defer install until grace period passed", func(t *testing.T) {
+		dpMgr := newFakeRuleDpManager()
+		dpMgr.Config.InternalIPs = []string{"10.0.0.10"}
+		dpMgr.Config.InternalIPGracePeriod = 200 * time.Millisecond
+
+		start := time.Now()
+		done := install(dpMgr)
+		Eventually(done).Should(BeClosed())
+		Expect(time.Since(start)).Should(BeNumerically(">=", 200*time.Millisecond))
+		Expect(dpMgr.Rules).Should(HaveKey("internal.ingress.10.0.0.10"))
+	})
+
+	t.Run("should stop waiting when stopped", func(t *testing.T) {
+		dpMgr := newFakeRuleDpManager()
+		dpMgr.Config.InternalIPs = []string{"10.0.0.10"}
+		dpMgr.BridgeChainMap["vds1"][POLICY_BRIDGE_KEYWORD].(*fakePolicyBridge).disconnected.Store(true)
+
+		stopChan := make(chan struct{})
+		close(stopChan)
+		dpMgr.installInternalIPs(stopChan)
+		Expect(dpMgr.Rules).ShouldNot(HaveKey("internal.ingress.10.0.0.10"))
+	})
+}
+
+func TestGetLocalEndpointIPsByIfaceName(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := NewDatapathManager(&DpManagerConfig{ManagedVDSMap: map[string]string{}}, nil)
+	dpMgr.localEndpointDB.Set("ep1", &Endpoint{InterfaceUUID: "ep1", InterfaceName: "veth1", IPAddr: net.ParseIP("10.0.0.1")})
+	dpMgr.localEndpointDB.Set("ep2", &Endpoint{InterfaceUUID: "ep2", InterfaceName: "veth2", IPAddr: net.ParseIP("10.0.0.2"), IPv6Addr: net.ParseIP("fd00::2")})
+	dpMgr.localEndpointDB.Set("ep3", &Endpoint{InterfaceUUID: "ep3", InterfaceName: "tap1", IPAddr: net.ParseIP("10.0.0.3")})
+	dpMgr.localEndpointDB.Set("ep4", &Endpoint{InterfaceUUID: "ep4", InterfaceName: "veth4"})
+
+	Expect(dpMgr.GetLocalEndpointIPsByIfaceName("veth*")).Should(Equal([]string{"10.0.0.1/32", "10.0.0.2/32", "fd00::2/128"}))
+	Expect(dpMgr.GetLocalEndpointIPsByIfaceName("tap1")).Should(Equal([]string{"10.0.0.3/32"}))
+	Expect(dpMgr.GetLocalEndpointIPsByIfaceName("eth*")).Should(BeEmpty())
+}
+
+func TestGetLocalEndpointIPs(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := NewDatapathManager(&DpManagerConfig{ManagedVDSMap: map[string]string{}}, nil)
+	Expect(dpMgr.GetLocalEndpointIPs()).Should(BeEmpty())
+
+	dpMgr.localEndpointDB.Set("ep1", &Endpoint{InterfaceUUID: "ep1", IPAddr: net.ParseIP("10.0.0.1")})
+	dpMgr.localEndpointDB.Set("ep2", &Endpoint{InterfaceUUID: "ep2", IPAddr: net.ParseIP("10.0.0.2"), IPv6Addr: net.ParseIP("fd00::2")})
+	dpMgr.localEndpointDB.Set("ep3", &Endpoint{InterfaceUUID: "ep3"})
+	Expect(dpMgr.GetLocalEndpointIPs()).Should(Equal([]string{"10.0.0.1/32", "10.0.0.2/32", "fd00::2/128"}))
+}
+
+func TestGetLocalEndpointIPsByVlanRange(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := NewDatapathManager(&DpManagerConfig{ManagedVDSMap: map[string]string{}}, nil)
+	dpMgr.localEndpointDB.Set("ep1", &Endpoint{InterfaceUUID: "ep1", VlanID: 50, IPAddr: net.ParseIP("10.0.0.1")})
+	dpMgr.localEndpointDB.Set("ep2", &Endpoint{InterfaceUUID: "ep2", VlanID: 100, IPAddr: net.ParseIP("10.0.0.2")})
+	dpMgr.localEndpointDB.Set("ep3", &Endpoint{InterfaceUUID: "ep3", VlanID: 150, IPAddr: net.ParseIP("10.0.0.3"), IPv6Addr: net.ParseIP("fd00::3")})
+	dpMgr.localEndpointDB.Set("ep4", &Endpoint{InterfaceUUID: "ep4", VlanID: 199, IPAddr: net.ParseIP("10.0.0.4")})
+	dpMgr.localEndpointDB.Set("ep5", &Endpoint{InterfaceUUID: "ep5", VlanID: 200, IPAddr: net.ParseIP("10.0.0.5")})
+
+	Expect(dpMgr.GetLocalEndpointIPsByVlanRange(100, 199)).Should(Equal([]string{"10.0.0.2/32", "10.0.0.3/32", "10.0.0.4/32", "fd00::3/128"}))
+	Expect(dpMgr.GetLocalEndpointIPsByVlanRange(50, 50)).Should(Equal([]string{"10.0.0.1/32"}))
+	Expect(dpMgr.GetLocalEndpointIPsByVlanRange(300, 400)).Should(BeEmpty())
+}
+
+func TestGetEffectiveRules(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := NewDatapathManager(&DpManagerConfig{ManagedVDSMap: map[string]string{}}, nil)
+	dpMgr.Config.ManagedVDSMap = map[string]string{"vds1": "ovsbr1", "vds2": "ovsbr2"}
+	// endpoints with the same ip on different vlan and vds
+	dpMgr.localEndpointDB.Set("ep1", &Endpoint{InterfaceUUID: "ep1", IPAddr: net.ParseIP("10.0.0.1"), VlanID: 10, BridgeName: "ovsbr1"})
+	dpMgr.localEndpointDB.Set("ep2", &Endpoint{InterfaceUUID: "ep2", IPAddr: net.ParseIP("10.0.0.1"), VlanID: 20, BridgeName: "ovsbr2"})
+
+	newRuleEntry := func(ruleID string, direction, tier uint8, priority int, srcIP, dstIP string, vdsIDs ...string) *EveroutePolicyRuleEntry {
+		entry := &EveroutePolicyRuleEntry{
+			EveroutePolicyRule: &EveroutePolicyRule{RuleID: ruleID, Priority: priority, SrcIPAddr: srcIP, DstIPAddr: dstIP, Action: "allow"},
+			Direction:          direction,
+			Tier:               tier,
+			Mode:               DEFAULT_POLICY_ENFORCEMENT_MODE,
+			RuleFlowMap:        map[string]*FlowEntry{},
+		}
+		for _, vdsID := range vdsIDs {
+			entry.RuleFlowMap[vdsID] = &FlowEntry{}
+		}
+		dpMgr.Rules[ruleID] = entry
+		return entry
+	}
+	newRuleEntry("vds1-ingress-tier2", POLICY_DIRECTION_IN, POLICY_TIER3, 100, "", "10.0.0.1/32", "vds1")
+	newRuleEntry("vds1-ingress-isolation", POLICY_DIRECTION_IN, POLICY_TIER1, 70, "", "10.0.0.1/32", "vds1")
+	newRuleEntry("vds1-ingress-tier2-high", POLICY_DIRECTION_IN, POLICY_TIER3, 200, "10.0.1.0/24", "", "vds1")
+	newRuleEntry("vds1-ingress-other-endpoint", POLICY_DIRECTION_IN, POLICY_TIER3, 200, "", "10.0.0.2/32", "vds1")
+	newRuleEntry("vds2-ingress-tier2", POLICY_DIRECTION_IN, POLICY_TIER3, 100, "", "10.0.0.1/32", "vds2")
+	newRuleEntry("egress-tier2", POLICY_DIRECTION_OUT, POLICY_TIER3, 100, "10.0.0.0/24", "", "vds1", "vds2")
+	newRuleEntry("egress-vlan20", POLICY_DIRECTION_OUT, POLICY_TIER3, 100, "10.0.0.0/24", "", "vds1", "vds2").EveroutePolicyRule.VlanID = 20
+
+	ruleIDs := func(entries []*rpcv1alpha1.RuleEntry) []string {
+		var ids []string
+		for _, entry := range entries {
+			ids = append(ids, entry.GetEveroutePolicyRule().GetRuleID())
+		}
+		return ids
+	}
+
+	t.Run("should return ordered rules of the endpoint on vlan 10", func(t *testing.T) {
+		rules, err := dpMgr.GetEffectiveRules(net.ParseIP("10.0.0.1"), 10)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(ruleIDs(rules)).Should(Equal([]string{"egress-tier2", "vds1-ingress-isolation", "vds1-ingress-tier2-high", "vds1-ingress-tier2"}))
+		for _, rule := range rules {
+			Expect(rule.GetRuleFlowMap()).Should(HaveKey("vds1"))
+			Expect(rule.GetRuleFlowMap()).ShouldNot(HaveKey("vds2"))
+		}
+	})
+
+	t.Run("should return rules of the endpoint with the same ip on vlan 20", func(t *testing.T) {
+		rules, err := dpMgr.GetEffectiveRules(net.ParseIP("10.0.0.1"), 20)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(ruleIDs(rules)).Should(Equal([]string{"egress-tier2", "egress-vlan20", "vds2-ingress-tier2"}))
+	})
+
+	t.Run("should return error when endpoint not on the vlan", func(t *testing.T) {
+		_, err := dpMgr.GetEffectiveRules(net.ParseIP("10.0.0.1"), 30)
+		Expect(err).Should(HaveOccurred())
+	})
+}
+
+func TestGetEndpoints(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := NewDatapathManager(&DpManagerConfig{ManagedVDSMap: map[string]string{}}, nil)
+	Expect(dpMgr.GetEndpoints()).Should(BeEmpty())
+
+	updateTime := time.Unix(1700000000, 0)
+	dpMgr.localEndpointDB.Set("ep2", &Endpoint{InterfaceUUID: "ep2", InterfaceName: "tap2", MacAddrStr: "00:00:00:00:00:02",
+		PortNo: 2, VlanID: 20, BridgeName: "ovsbr1"})
+	dpMgr.localEndpointDB.Set("ep1", &Endpoint{InterfaceUUID: "ep1", InterfaceName: "tap1", IPAddr: net.ParseIP("10.0.0.1"),
+		IPv6Addr: net.ParseIP("fe80::1"), IPAddrLastUpdateTime: updateTime, MacAddrStr: "00:00:00:00:00:01",
+		PortNo: 1, VlanID: 10, BridgeName: "ovsbr1"})
+
+	endpoints := dpMgr.GetEndpoints()
+	Expect(endpoints).Should(HaveLen(2))
+	Expect(endpoints[0].GetInterfaceUUID()).Should(Equal("ep1"))
+	Expect(endpoints[0].GetInterfaceName()).Should(Equal("tap1"))
+	Expect(endpoints[0].GetIPAddr()).Should(Equal("10.0.0.1"))
+	Expect(endpoints[0].GetIPv6Addr()).Should(Equal("fe80::1"))
+	Expect(endpoints[0].GetMacAddr()).Should(Equal("00:00:00:00:00:01"))
+	Expect(endpoints[0].GetVlanID()).Should(Equal(uint32(10)))
+	Expect(endpoints[0].GetOfPort()).Should(Equal(uint32(1)))
+	Expect(endpoints[0].GetBridgeName()).Should(Equal("ovsbr1"))
+	Expect(endpoints[0].GetIPAddrLastUpdateTime()).Should(Equal(updateTime.Unix()))
+
+	// endpoint without learned ip
+	Expect(endpoints[1].GetInterfaceUUID()).Should(Equal("ep2"))
+	Expect(endpoints[1].GetIPAddr()).Should(BeEmpty())
+	Expect(endpoints[1].GetIPv6Addr()).Should(BeEmpty())
+	Expect(endpoints[1].GetIPAddrLastUpdateTime()).Should(BeZero())
+	Expect(endpoints[1].GetOfPort()).Should(Equal(uint32(2)))
+}
+
+func TestRefreshEndpointIP(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := NewDatapathManager(&DpManagerConfig{ManagedVDSMap: map[string]string{}}, nil)
+	var probedIPs []*types.EndpointIP
+	dpMgr.ipProbeFunc = func(ctx context.Context, endpointIP *types.EndpointIP) error {
+		probedIPs = append(probedIPs, endpointIP)
+		return nil
+	}
+	// use loopback as bridge internal port, which always exists
+	dpMgr.localEndpointDB.Set("ep1", &Endpoint{InterfaceUUID: "ep1", IPAddr: net.ParseIP("10.0.0.1"), PortNo: 1, VlanID: 10, BridgeName: "lo"})
+	dpMgr.localEndpointDB.Set("ep2", &Endpoint{InterfaceUUID: "ep2", IPAddr: net.ParseIP("10.0.0.2"), PortNo: 2, VlanID: 20, Trunk: "20", BridgeName: "lo"})
+	dpMgr.localEndpointDB.Set("ep3", &Endpoint{InterfaceUUID: "ep3", PortNo: 3, BridgeName: "lo"})
+	dpMgr.localEndpointDB.Set("ep4", &Endpoint{InterfaceUUID: "ep4", IPAddr: net.ParseIP("10.0.0.4"), IPv6Addr: net.ParseIP("fd00::4"),
+		PortNo: 4, BridgeName: "lo"})
+
+	t.Run("refresh the requested endpoint", func(t *testing.T) {
+		probedIPs = nil
+		results, err := dpMgr.RefreshEndpointIP(context.Background(), "ep1")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(results).Should(HaveLen(1))
+		Expect(results[0].GetInterfaceUUID()).Should(Equal("ep1"))
+		Expect(results[0].GetSuccess()).Should(BeTrue())
+		Expect(probedIPs).Should(HaveLen(1))
+		Expect(probedIPs[0].IP.String()).Should(Equal("10.0.0.1"))
+		Expect(probedIPs[0].OfPort).Should(Equal(uint32(1)))
+		Expect(probedIPs[0].BridgeName).Should(Equal("lo"))
+		// packet out without vlan tag on access port
+		Expect(probedIPs[0].VlanID).Should(BeZero())
+	})
+
+	t.Run("refresh all endpoints", func(t *testing.T) {
+		probedIPs = nil
+		results, err := dpMgr.RefreshEndpointIP(context.Background(), "")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(results).Should(HaveLen(4))
+		Expect(results[0].GetSuccess()).Should(BeTrue())
+		Expect(results[1].GetSuccess()).Should(BeTrue())
+		// endpoint without learned ip couldn't be probed
+		Expect(results[2].GetInterfaceUUID()).Should(Equal("ep3"))
+		Expect(results[2].GetSuccess()).Should(BeFalse())
+		Expect(results[2].GetError()).ShouldNot(BeEmpty())
+		Expect(results[3].GetSuccess()).Should(BeTrue())
+		Expect(probedIPs).Should(HaveLen(4))
+		Expect(probedIPs[1].IP.String()).Should(Equal("10.0.0.2"))
+		Expect(probedIPs[1].VlanID).Should(Equal(uint16(20)))
+		// both ipv4 and ipv6 of the endpoint should be probed
+		Expect(probedIPs[2].IP.String()).Should(Equal("10.0.0.4"))
+		Expect(probedIPs[3].IP.String()).Should(Equal("fd00::4"))
+	})
+
+	t.Run("refresh not exist endpoint", func(t *testing.T) {
+		_, err := dpMgr.RefreshEndpointIP(context.Background(), "ep5")
+		Expect(err).Should(HaveOccurred())
+	})
+}
+
+func TestNeighborSolicitation(t *testing.T) {
+	RegisterTestingT(t)
+
+	mac, _ := net.ParseMAC("00:aa:bb:cc:dd:ee")
+	Expect(linkLocalAddrOf(mac).String()).Should(Equal("fe80::2aa:bbff:fecc:ddee"))
+	Expect(solicitedNodeAddrOf(net.ParseIP("fd00::12:3456")).String()).Should(Equal("ff02::1:ff12:3456"))
+
+	ns := neighborSolicitationOf(mac, net.ParseIP("fd00::12:3456"))
+	Expect(ns.Type).Should(Equal(uint8(icmpv6TypeNeighborSolicitation)))
+	Expect(net.IP(ns.Data[4:20]).String()).Should(Equal("fd00::12:3456"))
+	Expect(ns.Data[20:22]).Should(Equal([]byte{ndOptionSourceLinkLayerAddr, 1}))
+	Expect(net.HardwareAddr(ns.Data[22:28])).Should(Equal(mac))
+}
+
+func TestCleanConntrackInCTZone(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := NewDatapathManager(&DpManagerConfig{ManagedVDSMap: map[string]string{}, CTZoneStrategy: CTZoneStrategyVlan}, nil)
+	dpMgr.localEndpointDB.Set("ep1", &Endpoint{InterfaceUUID: "ep1", IPAddr: net.ParseIP("10.0.0.1"), VlanID: 10})
+	dpMgr.localEndpointDB.Set("ep2", &Endpoint{InterfaceUUID: "ep2", IPAddr: net.ParseIP("10.0.0.2"), VlanID: 20})
+	dpMgr.localEndpointDB.Set("ep3", &Endpoint{InterfaceUUID: "ep3", IPAddr: net.ParseIP("10.0.0.5"), Trunk: "10,20"})
+
+	newConntrackFlow := func(zone uint16, srcIP, dstIP string) *netlink.ConntrackFlow {
+		flow := &netlink.ConntrackFlow{Zone: zone}
+		flow.Forward.Protocol, flow.Forward.SrcIP, flow.Forward.DstIP = 6, net.ParseIP(srcIP), net.ParseIP(dstIP)
+		flow.Reverse.Protocol, flow.Reverse.SrcIP, flow.Reverse.DstIP = 6, net.ParseIP(dstIP), net.ParseIP(srcIP)
+		return flow
+	}
+	zone10Flow := newConntrackFlow(PolicyCTZoneOfVlan(10), "10.0.1.1", "10.0.0.1")
+	zone20Flow := newConntrackFlow(PolicyCTZoneOfVlan(20), "10.0.1.1", "10.0.0.1")
+
+	t.Run("flush rule of endpoint leaves the other zone untouched", func(t *testing.T) {
+		RegisterTestingT(t)
+		rule := dpMgr.conntrackCleanRule(&EveroutePolicyRule{RuleID: "rule1", DstIPAddr: "10.0.0.1/32"}, POLICY_DIRECTION_IN)
+		Expect(rule.CTZones).Should(Equal([]uint16{PolicyCTZoneOfVlan(10)}))
+		Expect(rule.MatchConntrackFlow(zone10Flow)).Should(BeTrue())
+		Expect(rule.MatchConntrackFlow(zone20Flow)).Should(BeFalse())
+	})
+
+	t.Run("rule of multiple endpoints flush their zones", func(t *testing.T) {
+		RegisterTestingT(t)
+		rule := dpMgr.conntrackCleanRule(&EveroutePolicyRule{RuleID: "rule2", SrcIPAddr: "10.0.0.0/30"}, POLICY_DIRECTION_OUT)
+		Expect(rule.CTZones).Should(ConsistOf(PolicyCTZoneOfVlan(10), PolicyCTZoneOfVlan(20)))
+		Expect(rule.MatchConntrackFlow(newConntrackFlow(PolicyCTZoneOfVlan(20), "10.0.0.2", "10.0.1.1"))).Should(BeTrue())
+		Expect(rule.MatchConntrackFlow(newConntrackFlow(PolicyCTZoneOfVlan(30), "10.0.0.2", "10.0.1.1"))).Should(BeFalse())
+	})
+
+	t.Run("rule of unknown or trunk endpoint flush all zones", func(t *testing.T) {
+		RegisterTestingT(t)
+		Expect(dpMgr.conntrackCleanRule(&EveroutePolicyRule{RuleID: "rule3"}, POLICY_DIRECTION_IN).CTZones).Should(BeEmpty())
+		Expect(dpMgr.conntrackCleanRule(&EveroutePolicyRule{RuleID: "rule4", DstIPAddr: "10.0.0.100"}, POLICY_DIRECTION_IN).CTZones).Should(BeEmpty())
+		Expect(dpMgr.conntrackCleanRule(&EveroutePolicyRule{RuleID: "rule5", DstIPAddr: "10.0.0.5"}, POLICY_DIRECTION_IN).CTZones).Should(BeEmpty())
+	})
+
+	t.Run("merge rule list should union zones of the same rule", func(t *testing.T) {
+		RegisterTestingT(t)
+		rules := mergeRuleList(
+			EveroutePolicyRuleList{{RuleID: "rule1", CTZones: []uint16{1}}, {RuleID: "rule2", CTZones: []uint16{1}}},
+			EveroutePolicyRuleList{{RuleID: "rule1", CTZones: []uint16{2}}, {RuleID: "rule2"}},
+		)
+		Expect(rules).Should(HaveLen(2))
+		Expect(rules[0].CTZones).Should(Equal([]uint16{1, 2}))
+		Expect(rules[1].CTZones).Should(BeEmpty())
+	})
+
+	t.Run("global strategy flush policy zone only", func(t *testing.T) {
+		RegisterTestingT(t)
+		dpMgr.Config.CTZoneStrategy = CTZoneStrategyGlobal
+		defer func() { dpMgr.Config.CTZoneStrategy = CTZoneStrategyVlan }()
+		rule := dpMgr.conntrackCleanRule(&EveroutePolicyRule{RuleID: "rule1", DstIPAddr: "10.0.0.1/32"}, POLICY_DIRECTION_IN)
+		Expect(rule.MatchConntrackFlow(newConntrackFlow(constants.CTZoneForPolicy, "10.0.1.1", "10.0.0.1"))).Should(BeTrue())
+		Expect(rule.MatchConntrackFlow(newConntrackFlow(constants.CTZoneLocalBr, "10.0.1.1", "10.0.0.1"))).Should(BeFalse())
+	})
+}
+
+func TestEstablishedConntrackCleanOnRuleRemove(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := newFakeRuleDpManager()
+	dpMgr.deleteFlowFunc = func(*ofctrl.Table, uint16, uint64) error { return nil }
+	rule := &EveroutePolicyRule{RuleID: "allow-web", Priority: 200, DstIPAddr: "10.0.0.10/32", IPProtocol: PROTOCOL_TCP,
+		DstPort: 80, DstPortMask: 0xffff, Action: EveroutePolicyAllow}
+	Expect(dpMgr.AddEveroutePolicyRule(rule, "policy/allow-web", POLICY_DIRECTION_IN, POLICY_TIER3, DEFAULT_POLICY_ENFORCEMENT_MODE)).Should(Succeed())
+	// conntrack of the rule is cleaned on install, drain it
+	receiveRuleListFromChan(dpMgr.cleanConntrackChan)
+
+	newEstFlow := func(srcIP, dstIP string, dstPort uint16) *netlink.ConntrackFlow {
+		flow := &netlink.ConntrackFlow{Zone: constants.CTZoneForPolicy}
+		flow.Forward.Protocol, flow.Forward.SrcIP, flow.Forward.DstIP = PROTOCOL_TCP, net.ParseIP(srcIP), net.ParseIP(dstIP)
+		flow.Forward.SrcPort, flow.Forward.DstPort = 40000, dstPort
+		flow.Reverse.Protocol, flow.Reverse.SrcIP, flow.Reverse.DstIP = PROTOCOL_TCP, net.ParseIP(dstIP), net.ParseIP(srcIP)
+		flow.Reverse.SrcPort, flow.Reverse.DstPort = dstPort, 40000
+		return flow
+	}
+
+	Expect(dpMgr.RemoveEveroutePolicyRule(rule.RuleID, "policy/allow-web")).Should(Succeed())
+	Expect(dpMgr.Rules).ShouldNot(HaveKey(rule.RuleID))
+	cleanRules := receiveRuleListFromChan(dpMgr.cleanConntrackChan)
+	Expect(cleanRules).Should(HaveLen(1))
+	// established connections allowed by the rule bypass policy tables, they must be torn down
+	Expect(cleanRules.MatchConntrackFlow(newEstFlow("10.0.1.1", "10.0.0.10", 80))).Should(BeTrue())
+	Expect(cleanRules.MatchConntrackFlow(newEstFlow("10.0.1.1", "10.0.0.10", 22))).Should(BeFalse())
+	Expect(cleanRules.MatchConntrackFlow(newEstFlow("10.0.1.1", "10.0.0.11", 80))).Should(BeFalse())
+}
+
+func TestMonitorRuleKeepConntrack(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := newFakeRuleDpManager()
+	dpMgr.deleteFlowFunc = func(*ofctrl.Table, uint16, uint64) error { return nil }
+	monitorRule := &EveroutePolicyRule{RuleID: "monitor-deny", Priority: 200, DstIPAddr: "10.0.0.10/32", Action: EveroutePolicyDeny}
+
+	t.Run("add monitor rule should not clean conntrack", func(t *testing.T) {
+		Expect(dpMgr.AddEveroutePolicyRule(monitorRule, "policy/monitor-deny", POLICY_DIRECTION_IN, POLICY_TIER3,
+			MONITOR_POLICY_ENFORCEMENT_MODE)).Should(Succeed())
+		Expect(dpMgr.Rules).Should(HaveKey(monitorRule.RuleID))
+		Expect(dpMgr.cleanConntrackChan).Should(BeEmpty())
+	})
+
+	t.Run("remove monitor rule should not clean conntrack", func(t *testing.T) {
+		Expect(dpMgr.RemoveEveroutePolicyRule(monitorRule.RuleID, "policy/monitor-deny")).Should(Succeed())
+		Expect(dpMgr.Rules).ShouldNot(HaveKey(monitorRule.RuleID))
+		Expect(dpMgr.cleanConntrackChan).Should(BeEmpty())
+	})
+
+	t.Run("work rule should still clean conntrack", func(t *testing.T) {
+		workRule := &EveroutePolicyRule{RuleID: "work-deny", Priority: 200, DstIPAddr: "10.0.0.10/32", Action: EveroutePolicyDeny}
+		Expect(dpMgr.AddEveroutePolicyRule(workRule, "policy/work-deny", POLICY_DIRECTION_IN, POLICY_TIER3,
+			DEFAULT_POLICY_ENFORCEMENT_MODE)).Should(Succeed())
+		Expect(receiveRuleListFromChan(dpMgr.cleanConntrackChan)).Should(ConsistOf(HaveField("RuleID", workRule.RuleID)))
+	})
+}
+
+func TestCleanConntrackByFamily(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := NewDatapathManager(&DpManagerConfig{ManagedVDSMap: map[string]string{}}, nil)
+	cleaned := make(map[netlink.InetFamily][]string)
+	dpMgr.ctDeleteFunc = func(family netlink.InetFamily, filter netlink.CustomConntrackFilter) (uint, error) {
+		for _, rule := range filter.(EveroutePolicyRuleList) {
+			cleaned[family] = append(cleaned[family], rule.RuleID)
+		}
+		return 0, nil
+	}
+
+	dpMgr.cleanConntrackByFamily(EveroutePolicyRuleList{
+		{RuleID: "ipv4-rule", SrcIPAddr: "10.0.0.1/32"},
+		{RuleID: "ipv6-rule", DstIPAddr: "fd00::1/128"},
+		{RuleID: "any-ip-rule", IPProtocol: 6, DstPort: 80},
+	})
+	Expect(cleaned).Should(Equal(map[netlink.InetFamily][]string{
+		unix.AF_INET:  {"ipv4-rule", "any-ip-rule"},
+		unix.AF_INET6: {"ipv6-rule", "any-ip-rule"},
+	}))
+
+	t.Run("family without rules should not be cleaned", func(t *testing.T) {
+		cleaned = make(map[netlink.InetFamily][]string)
+		dpMgr.cleanConntrackByFamily(EveroutePolicyRuleList{{RuleID: "ipv6-rule", SrcIPAddr: "fd00::1"}})
+		Expect(cleaned).Should(Equal(map[netlink.InetFamily][]string{unix.AF_INET6: {"ipv6-rule"}}))
+	})
+}
+
+func TestTrafficLocality(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := NewDatapathManager(&DpManagerConfig{ManagedVDSMap: map[string]string{}, EnableCNI: true,
+		CNIConfig: &DpManagerCNIConfig{EncapMode: constants.EncapModeGeneve}}, nil)
+	dpMgr.localEndpointDB.Set("ep1", &Endpoint{InterfaceUUID: "ep1", IPAddr: net.ParseIP("10.0.0.1"), BridgeName: "ovsbr1"})
+	dpMgr.localEndpointDB.Set("ep2", &Endpoint{InterfaceUUID: "ep2", IPAddr: net.ParseIP("10.0.0.2"), BridgeName: "ovsbr1"})
+
+	Expect(dpMgr.trafficLocality(net.ParseIP("10.0.0.2"))).Should(Equal(TrafficLocalityIntraNode))
+	Expect(dpMgr.trafficLocality(net.ParseIP("10.0.1.1"))).Should(Equal(TrafficLocalityInterNode))
+
+	// egress rules drop inter node traffic and log intra node traffic
+	for _, rule := range []*EveroutePolicyRule{
+		{RuleID: "inter-node", Priority: 200, Action: EveroutePolicyDeny, TrafficLocality: TrafficLocalityInterNode},
+		{RuleID: "intra-node", Priority: 200, Action: EveroutePolicyAllow, TrafficLocality: TrafficLocalityIntraNode},
+	} {
+		dpMgr.Rules[rule.RuleID] = &EveroutePolicyRuleEntry{EveroutePolicyRule: rule, Direction: POLICY_DIRECTION_OUT,
+			Tier: POLICY_TIER3, Mode: DEFAULT_POLICY_ENFORCEMENT_MODE, RuleFlowMap: map[string]*FlowEntry{}}
+	}
+
+	result := dpMgr.QueryReachable(net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.2"), PROTOCOL_TCP, 80)
+	Expect(result.GetReachable()).Should(BeTrue())
+	Expect(result.GetEgress().GetRuleID()).Should(Equal("intra-node"))
+
+	result = dpMgr.QueryReachable(net.ParseIP("10.0.0.1"), net.ParseIP("10.0.1.1"), PROTOCOL_TCP, 80)
+	Expect(result.GetReachable()).Should(BeFalse())
+	Expect(result.GetEgress().GetRuleID()).Should(Equal("inter-node"))
+
+	t.Run("should match intra node packet mark", func(t *testing.T) {
+		field, err := trafficLocalityMatchField(TrafficLocalityIntraNode, true)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(field.Value.(*openflow13.Uint32Message).Data).Should(Equal(IntraNodePktMark))
+		Expect(field.Mask.(*openflow13.Uint32Message).Data).Should(Equal(IntraNodePktMark))
+
+		field, err = trafficLocalityMatchField(TrafficLocalityInterNode, true)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(field.Value.(*openflow13.Uint32Message).Data).Should(BeZero())
+		Expect(field.Mask.(*openflow13.Uint32Message).Data).Should(Equal(IntraNodePktMark))
+	})
+
+	t.Run("should not support traffic locality without overlay", func(t *testing.T) {
+		_, err := trafficLocalityMatchField(TrafficLocalityIntraNode, false)
+		Expect(err).Should(HaveOccurred())
+	})
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datapath
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// ruleEndpointIPAddr returns the ip address of the endpoint the rule applied to, empty means the rule
+// applied to all endpoints
+func ruleEndpointIPAddr(rule *EveroutePolicyRule, direction uint8) string {
+	if direction == POLICY_DIRECTION_OUT {
+		return rule.SrcIPAddr
+	}
+	return rule.DstIPAddr
+}
+
+func endpointMatchIPAddr(endpoint *Endpoint, ipAddr string) bool {
+	if ipAddr == "" {
+		return true
+	}
+	return endpoint.IPAddr != nil && matchIP(ipAddr, endpoint.IPAddr) ||
+		endpoint.IPv6Addr != nil && matchIP(ipAddr, endpoint.IPv6Addr)
+}
+
+// ruleIPAddrStats is the number of rules with the same rule endpoint ip address, and the number of their
+// rule flows in each vds.
+type ruleIPAddrStats struct {
+	rules int
+	flows map[string]int
+}
+
+// endpointRuleMetrics caches rule stats of each rule endpoint ip address, it's protected by its own lock
+// instead of flowReplayMutex, so that gauges of local endpoints could be synced without flowReplayMutex.
+type endpointRuleMetrics struct {
+	lock  sync.Mutex
+	stats map[string]*ruleIPAddrStats
+	// dirtyIPAddrs are rule endpoint ip addresses whose stats changed since the last sync
+	dirtyIPAddrs sets.Set[string]
+	// dirtyEndpoints are local endpoints added, updated or removed since the last sync
+	dirtyEndpoints []*Endpoint
+}
+
+func newEndpointRuleMetrics() *endpointRuleMetrics {
+	return &endpointRuleMetrics{
+		stats:        make(map[string]*ruleIPAddrStats),
+		dirtyIPAddrs: sets.New[string](),
+	}
+}
+
+// indexRule adds the rule to the index of its rule endpoint ip address. Caller must hold flowReplayMutex.
+func (datapathManager *DpManager) indexRule(ruleID string, entry *EveroutePolicyRuleEntry) {
+	ipAddr := ruleEndpointIPAddr(entry.EveroutePolicyRule, entry.Direction)
+	if datapathManager.ruleIDsByIPAddr[ipAddr] == nil {
+		datapathManager.ruleIDsByIPAddr[ipAddr] = sets.New[string]()
+	}
+	datapathManager.ruleIDsByIPAddr[ipAddr].Insert(ruleID)
+}
+
+// unindexRule removes the rule from the index of its rule endpoint ip address. Caller must hold flowReplayMutex.
+func (datapathManager *DpManager) unindexRule(ruleID string, entry *EveroutePolicyRuleEntry) {
+	ipAddr := ruleEndpointIPAddr(entry.EveroutePolicyRule, entry.Direction)
+	if ruleIDs := datapathManager.ruleIDsByIPAddr[ipAddr]; ruleIDs != nil {
+		ruleIDs.Delete(ruleID)
+		if ruleIDs.Len() == 0 {
+			delete(datapathManager.ruleIDsByIPAddr, ipAddr)
+		}
+	}
+}
+
+// updateEndpointRuleMetrics recounts rules and rule flows of the rule endpoint ip addresses, gauges of local
+// endpoints matching them are updated by syncEndpointRuleMetrics. Caller must hold flowReplayMutex.
+func (datapathManager *DpManager) updateEndpointRuleMetrics(ruleIPAddrs sets.Set[string]) {
+	if ruleIPAddrs.Len() == 0 {
+		return
+	}
+	stats := make(map[string]*ruleIPAddrStats, ruleIPAddrs.Len())
+	for ipAddr := range ruleIPAddrs {
+		ruleIDs := datapathManager.ruleIDsByIPAddr[ipAddr]
+		if ruleIDs.Len() == 0 {
+			stats[ipAddr] = nil
+			continue
+		}
+		ipAddrStats := &ruleIPAddrStats{flows: make(map[string]int)}
+		for ruleID := range ruleIDs {
+			ipAddrStats.rules++
+			for vdsID := range datapathManager.Rules[ruleID].RuleFlowMap {
+				ipAddrStats.flows[vdsID]++
+			}
+		}
+		stats[ipAddr] = ipAddrStats
+	}
+
+	m := datapathManager.endpointRuleMetrics
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for ipAddr, ipAddrStats := range stats {
+		if ipAddrStats == nil {
+			delete(m.stats, ipAddr)
+		} else {
+			m.stats[ipAddr] = ipAddrStats
+		}
+		m.dirtyIPAddrs.Insert(ipAddr)
+	}
+}
+
+// refreshEndpointRuleMetrics marks the local endpoint to be synced, the gauges of the endpoint are removed
+// if it's no longer in the local endpoint db when synced.
+func (datapathManager *DpManager) refreshEndpointRuleMetrics(endpoint *Endpoint) {
+	m := datapathManager.endpointRuleMetrics
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.dirtyEndpoints = append(m.dirtyEndpoints, endpoint)
+}
+
+// syncEndpointRuleMetrics sets gauges of local endpoints marked or matching rule endpoint ip addresses changed
+// since the last sync. It should be called without flowReplayMutex.
+func (datapathManager *DpManager) syncEndpointRuleMetrics() {
+	m := datapathManager.endpointRuleMetrics
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.dirtyIPAddrs.Len() == 0 && len(m.dirtyEndpoints) == 0 {
+		return
+	}
+
+	dirtyEndpoints := sets.New[string]()
+	for _, endpoint := range m.dirtyEndpoints {
+		if cached, _ := datapathManager.localEndpointDB.Get(endpoint.InterfaceUUID); cached != endpoint {
+			datapathManager.AgentMetric.RemoveEndpointRuleStats(endpoint.InterfaceUUID, endpoint.InterfaceName)
+			continue
+		}
+		dirtyEndpoints.Insert(endpoint.InterfaceUUID)
+	}
+
+	for item := range datapathManager.localEndpointDB.IterBuffered() {
+		endpoint := item.Val.(*Endpoint)
+		endpoint.IPAddrMutex.RLock()
+		if dirtyEndpoints.Has(endpoint.InterfaceUUID) || m.matchDirtyIPAddrs(endpoint) {
+			rules, flows := m.countEndpointRules(endpoint, datapathManager.endpointVDS(endpoint))
+			datapathManager.AgentMetric.SetEndpointRuleStats(endpoint.InterfaceUUID, endpoint.InterfaceName, rules, flows)
+		}
+		endpoint.IPAddrMutex.RUnlock()
+	}
+
+	m.dirtyIPAddrs = sets.New[string]()
+	m.dirtyEndpoints = nil
+}
+
+func (m *endpointRuleMetrics) matchDirtyIPAddrs(endpoint *Endpoint) bool {
+	for ipAddr := range m.dirtyIPAddrs {
+		if endpointMatchIPAddr(endpoint, ipAddr) {
+			return true
+		}
+	}
+	return false
+}
+
+// countEndpointRules counts rules applied to the local endpoint, and the rule flows installed in the vds the
+// endpoint attached to.
+func (m *endpointRuleMetrics) countEndpointRules(endpoint *Endpoint, vdsID string) (rules, flows int) {
+	for ipAddr, ipAddrStats := range m.stats {
+		if endpointMatchIPAddr(endpoint, ipAddr) {
+			rules += ipAddrStats.rules
+			flows += ipAddrStats.flows[vdsID]
+		}
+	}
+	return rules, flows
+}
+
+func (datapathManager *DpManager) endpointVDS(endpoint *Endpoint) string {
+	for vdsID, ovsbrname := range datapathManager.Config.ManagedVDSMap {
+		if ovsbrname == endpoint.BridgeName {
+			return vdsID
+		}
+	}
+	return ""
+}
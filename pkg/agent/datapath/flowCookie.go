@@ -0,0 +1,221 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datapath
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/contiv/libOpenflow/openflow13"
+	"github.com/contiv/ofnet/ofctrl"
+)
+
+// FlowCookieBits is the low bits of flow cookie allocated by datapath, split into round num and flow sequence
+const FlowCookieBits = FLOW_ROUND_NUM_LENGTH + FLOW_SEQ_NUM_LENGTH
+
+var defaultFlowCookie = DefaultFlowCookieConfig()
+
+// FlowCookieConfig configs the layout of flow cookie, | RoundNumBits round num | FlowSeqBits flow sequence |.
+// More round bits allow more agent restart rounds before the round num wraps, more sequence bits allow more
+// flows allocated in a round. The layout is persisted with the round num, so that flows of the previous round
+// are deleted by the layout they were allocated with after the layout changed on a node.
+type FlowCookieConfig struct {
+	RoundNumBits int
+	FlowSeqBits  int
+	MaxRoundNum  uint64 // round num wraps to 1 after reaching it, it must fit in RoundNumBits
+}
+
+// DefaultFlowCookieConfig returns the layout of 4 bits round num and 28 bits flow sequence
+func DefaultFlowCookieConfig() *FlowCookieConfig {
+	return &FlowCookieConfig{
+		RoundNumBits: FLOW_ROUND_NUM_LENGTH,
+		FlowSeqBits:  FLOW_SEQ_NUM_LENGTH,
+		MaxRoundNum:  MaxRoundNum,
+	}
+}
+
+func (c *FlowCookieConfig) Validate() error {
+	if c.RoundNumBits <= 0 || c.FlowSeqBits <= 0 {
+		return fmt.Errorf("roundNumBits %d and flowSeqBits %d of flow cookie must be positive", c.RoundNumBits, c.FlowSeqBits)
+	}
+	if c.RoundNumBits+c.FlowSeqBits != FlowCookieBits {
+		return fmt.Errorf("roundNumBits %d plus flowSeqBits %d of flow cookie must be %d",
+			c.RoundNumBits, c.FlowSeqBits, FlowCookieBits)
+	}
+	if c.MaxRoundNum < 1 || c.MaxRoundNum > c.roundNumMax() {
+		return fmt.Errorf("maxRoundNum %d of flow cookie must be in [1, %d]", c.MaxRoundNum, c.roundNumMax())
+	}
+	return nil
+}
+
+// roundNumMax returns the max round num fits in RoundNumBits
+func (c *FlowCookieConfig) roundNumMax() uint64 {
+	return uint64(1)<<c.RoundNumBits - 1
+}
+
+func (c *FlowCookieConfig) flowSeqMask() uint64 {
+	return uint64(1)<<c.FlowSeqBits - 1
+}
+
+// nextRoundNum returns the round num after num, flipping to the minimum round num 1 when num reaches MaxRoundNum
+func (c *FlowCookieConfig) nextRoundNum(num uint64) uint64 {
+	if num >= c.MaxRoundNum {
+		return 1
+	}
+	return num + 1
+}
+
+// nextRoundNumAfter returns the round num after the previous round allocated with the previous layout, cookies
+// of the returned round never overlap with cookies of the previous round, so that flows of the previous round
+// wouldn't be taken as flows of the current round when the layout changed.
+func (c *FlowCookieConfig) nextRoundNumAfter(previous *FlowCookieConfig, previousRoundNum uint64) uint64 {
+	roundNum := c.nextRoundNum(previousRoundNum)
+	for i := uint64(0); i < c.MaxRoundNum && c.roundOverlaps(roundNum, previous, previousRoundNum); i++ {
+		roundNum = c.nextRoundNum(roundNum)
+	}
+	return roundNum
+}
+
+// roundOverlaps returns true if cookies of the round overlap with cookies of the other round in the other layout
+func (c *FlowCookieConfig) roundOverlaps(roundNum uint64, other *FlowCookieConfig, otherRoundNum uint64) bool {
+	begin, end := roundNum<<c.FlowSeqBits, (roundNum+1)<<c.FlowSeqBits
+	otherBegin, otherEnd := otherRoundNum<<other.FlowSeqBits, (otherRoundNum+1)<<other.FlowSeqBits
+	return begin < otherEnd && otherBegin < end
+}
+
+// roundCookieWithMask returns cookie and cookie mask matches flows allocated in the round
+func (c *FlowCookieConfig) roundCookieWithMask(roundNum uint64) (uint64, uint64) {
+	return roundNum << c.FlowSeqBits, c.roundNumMax() << c.FlowSeqBits
+}
+
+// roundNum returns the round num of the flow cookie
+func (c *FlowCookieConfig) roundNum(flowID uint64) uint64 {
+	return flowID >> c.FlowSeqBits & c.roundNumMax()
+}
+
+// flowSeq returns the flow sequence of the flow cookie
+func (c *FlowCookieConfig) flowSeq(flowID uint64) uint64 {
+	return flowID & c.flowSeqMask()
+}
+
+// The flow cookie of policy rule is recorded in ct_label, the round num takes the lowest RoundNumBits, the flow
+// sequence of tier2 monitor rule takes the rest of the low 32 bits, the flow sequence of tier3 monitor rule and
+// work rule follow it in order. With the default layout, they are bits 0-3, 4-31, 32-59 and 60-87.
+
+func (c *FlowCookieConfig) roundNumNXRange() *openflow13.NXRange {
+	return openflow13.NewNXRange(0, c.RoundNumBits-1)
+}
+
+func (c *FlowCookieConfig) monitorTier2FlowSpaceNXRange() *openflow13.NXRange {
+	return openflow13.NewNXRange(c.RoundNumBits, FlowCookieBits-1)
+}
+
+func (c *FlowCookieConfig) monitorTier3FlowSpaceNXRange() *openflow13.NXRange {
+	return openflow13.NewNXRange(FlowCookieBits, FlowCookieBits+c.FlowSeqBits-1)
+}
+
+func (c *FlowCookieConfig) workFlowSpaceNXRange() *openflow13.NXRange {
+	return openflow13.NewNXRange(FlowCookieBits+c.FlowSeqBits, FlowCookieBits+2*c.FlowSeqBits-1)
+}
+
+// puntedRuleFlowID returns the flow cookie of the work mode rule flow decided the packet sent to controller
+// by the ct commit punt flow, the round num and work flow sequence are read from xxreg0 in the packet in,
+// the bits above them are the same as the cookie of the punt flow in the same bridge.
+func (c *FlowCookieConfig) puntedRuleFlowID(pkt *ofctrl.PacketIn) uint64 {
+	xxreg0 := packetInXXReg0(pkt)
+	roundNum := xxreg0.bits(0, c.RoundNumBits)
+	flowSeq := xxreg0.bits(FlowCookieBits+c.FlowSeqBits, c.FlowSeqBits)
+	return pkt.Cookie&^(uint64(1)<<FlowCookieBits-1) | roundNum<<c.FlowSeqBits | flowSeq
+}
+
+// xxreg is the 128 bits register, consists of reg0 through reg3 with reg0 as the most significant
+type xxreg struct {
+	high, low uint64
+}
+
+// bits returns size bits of the register from the start bit, size must not exceed 64
+func (r xxreg) bits(start, size int) uint64 {
+	mask := uint64(1)<<size - 1
+	switch {
+	case start >= 64:
+		return r.high >> (start - 64) & mask
+	case start+size <= 64:
+		return r.low >> start & mask
+	default:
+		return (r.low>>start | r.high<<(64-start)) & mask
+	}
+}
+
+// packetInXXReg0 returns xxreg0 of the packet in, registers not in the match are zero
+func packetInXXReg0(pkt *ofctrl.PacketIn) xxreg {
+	var regs [4]uint64
+	for i := range regs {
+		regs[i] = uint64(packetInReg(pkt, i))
+	}
+	return xxreg{high: regs[0]<<32 | regs[1], low: regs[2]<<32 | regs[3]}
+}
+
+// packetInReg returns the register of the packet in, returns zero if the register not in the match
+func packetInReg(pkt *ofctrl.PacketIn, regID int) uint32 {
+	for _, field := range pkt.Match.Fields {
+		if field.Class != openflow13.OXM_CLASS_NXM_1 || int(field.Field) != regID {
+			continue
+		}
+		if value, ok := field.Value.(*openflow13.Uint32Message); ok {
+			return value.Data
+		}
+	}
+	return 0
+}
+
+func (c *FlowCookieConfig) newCookieAllocator(roundNum uint64) *flowCookieAllocator {
+	return &flowCookieAllocator{
+		roundNum: roundNum,
+		flowSeq:  1,
+		seqBits:  c.FlowSeqBits,
+	}
+}
+
+// flowCookieAllocator allocates flow cookie of the round with the configured layout
+type flowCookieAllocator struct {
+	lock      sync.Mutex
+	roundNum  uint64
+	flowSeq   uint64
+	seqBits   int
+	fixedMask uint64
+}
+
+func (a *flowCookieAllocator) SetFixedMask(mask uint64) {
+	a.fixedMask = mask
+}
+
+func (a *flowCookieAllocator) RequestCookie() uint64 {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	id := a.roundNum<<a.seqBits | a.flowSeq
+	a.flowSeq++
+	return id | a.fixedMask
+}
+
+// flowCookie returns the configured flow cookie layout, default layout if not configured
+func (datapathManager *DpManager) flowCookie() *FlowCookieConfig {
+	if datapathManager.Config == nil || datapathManager.Config.FlowCookie == nil {
+		return defaultFlowCookie
+	}
+	return datapathManager.Config.FlowCookie
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datapath
+
+import (
+	"testing"
+
+	"github.com/contiv/libOpenflow/openflow13"
+	"github.com/contiv/ofnet/ofctrl"
+	"github.com/contiv/ofnet/ofctrl/cookie"
+	. "github.com/onsi/gomega"
+)
+
+func TestFlowCookieConfigValidate(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(DefaultFlowCookieConfig().Validate()).Should(Succeed())
+	Expect((&FlowCookieConfig{RoundNumBits: 8, FlowSeqBits: 24, MaxRoundNum: 255}).Validate()).Should(Succeed())
+
+	invalidConfigs := map[string]*FlowCookieConfig{
+		"bits sum less than 32":        {RoundNumBits: 4, FlowSeqBits: 24, MaxRoundNum: 15},
+		"bits sum more than 32":        {RoundNumBits: 8, FlowSeqBits: 28, MaxRoundNum: 15},
+		"zero round bits":              {RoundNumBits: 0, FlowSeqBits: 32, MaxRoundNum: 1},
+		"negative seq bits":            {RoundNumBits: 33, FlowSeqBits: -1, MaxRoundNum: 1},
+		"zero max round num":           {RoundNumBits: 4, FlowSeqBits: 28, MaxRoundNum: 0},
+		"max round num exceeds bits":   {RoundNumBits: 4, FlowSeqBits: 28, MaxRoundNum: 16},
+		"max round num exceeds 8 bits": {RoundNumBits: 8, FlowSeqBits: 24, MaxRoundNum: 256},
+	}
+	for name, config := range invalidConfigs {
+		Expect(config.Validate()).ShouldNot(Succeed(), name)
+	}
+}
+
+func TestFlowCookieRoundNumWrap(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("default layout wrap at MaxRoundNum", func(t *testing.T) {
+		config := DefaultFlowCookieConfig()
+		Expect(config.nextRoundNum(0)).Should(Equal(uint64(1)))
+		Expect(config.nextRoundNum(MaxRoundNum - 1)).Should(Equal(uint64(MaxRoundNum)))
+		Expect(config.nextRoundNum(MaxRoundNum)).Should(Equal(uint64(1)))
+	})
+
+	t.Run("wrap at configured max round num", func(t *testing.T) {
+		config := &FlowCookieConfig{RoundNumBits: 8, FlowSeqBits: 24, MaxRoundNum: 200}
+		Expect(config.nextRoundNum(MaxRoundNum)).Should(Equal(uint64(MaxRoundNum + 1)))
+		Expect(config.nextRoundNum(199)).Should(Equal(uint64(200)))
+		Expect(config.nextRoundNum(200)).Should(Equal(uint64(1)))
+		// round num persisted with a larger max before
+		Expect(config.nextRoundNum(250)).Should(Equal(uint64(1)))
+	})
+
+	t.Run("round num exceeds configured max shouldn't be persisted", func(t *testing.T) {
+		config := &FlowCookieConfig{RoundNumBits: 8, FlowSeqBits: 24, MaxRoundNum: 200}
+		Expect(persistentRoundInfo(201, nil, config)).ShouldNot(Succeed())
+		Expect(persistentRoundInfo(MaxRoundNum+1, nil, DefaultFlowCookieConfig())).ShouldNot(Succeed())
+	})
+}
+
+func TestFlowCookieLayoutChange(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("round num persisted without layout should be deleted by default layout", func(t *testing.T) {
+		config := &FlowCookieConfig{RoundNumBits: 8, FlowSeqBits: 24, MaxRoundNum: 255}
+		roundInfo, err := roundInfoFromExternalIds(map[string]string{datapathRestartRound: "3"}, config)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(roundInfo.previousRoundNum).Should(Equal(uint64(3)))
+		cookie, mask := roundInfo.previousFlowCookie.roundCookieWithMask(3)
+		expectCookie, expectMask := DefaultFlowCookieConfig().roundCookieWithMask(3)
+		Expect(cookie).Should(Equal(expectCookie))
+		Expect(mask).Should(Equal(expectMask))
+	})
+
+	t.Run("previous round should be deleted by persisted layout", func(t *testing.T) {
+		externalIds := map[string]string{datapathRestartRound: "3", datapathRoundNumBits: "8"}
+		roundInfo, err := roundInfoFromExternalIds(externalIds, DefaultFlowCookieConfig())
+		Expect(err).ShouldNot(HaveOccurred())
+		cookie, mask := roundInfo.previousFlowCookie.roundCookieWithMask(3)
+		Expect(cookie).Should(Equal(uint64(3) << 24))
+		Expect(mask).Should(Equal(uint64(0xff) << 24))
+	})
+
+	t.Run("current round shouldn't overlap with previous round of old layout", func(t *testing.T) {
+		// round 3 of 4 bits layout covers round 48 to 63 of 8 bits layout
+		config := &FlowCookieConfig{RoundNumBits: 8, FlowSeqBits: 24, MaxRoundNum: 255}
+		externalIds := map[string]string{datapathRestartRound: "3", datapathRoundNumBits: "4"}
+		roundInfo, err := roundInfoFromExternalIds(externalIds, config)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(roundInfo.curRoundNum).Should(Equal(uint64(4)))
+
+		// round 20 of 8 bits layout wraps to round 1 of 4 bits layout, which covers round 16 to 31 of 8 bits layout
+		externalIds = map[string]string{datapathRestartRound: "20", datapathRoundNumBits: "8"}
+		roundInfo, err = roundInfoFromExternalIds(externalIds, DefaultFlowCookieConfig())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(roundInfo.curRoundNum).Should(Equal(uint64(2)))
+
+		externalIds = map[string]string{datapathRestartRound: "63", datapathRoundNumBits: "8"}
+		roundInfo, err = roundInfoFromExternalIds(externalIds, DefaultFlowCookieConfig())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(roundInfo.curRoundNum).Should(Equal(uint64(1)))
+	})
+
+	t.Run("bad persisted layout should fail", func(t *testing.T) {
+		externalIds := map[string]string{datapathRestartRound: "3", datapathRoundNumBits: "32"}
+		_, err := roundInfoFromExternalIds(externalIds, DefaultFlowCookieConfig())
+		Expect(err).Should(HaveOccurred())
+	})
+}
+
+func TestFlowCookieLayout(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("default layout should be compatible with ofnet cookie", func(t *testing.T) {
+		config := DefaultFlowCookieConfig()
+		allocator := config.newCookieAllocator(3)
+		expectAllocator := cookie.NewAllocator(3)
+		for i := 0; i < 3; i++ {
+			Expect(allocator.RequestCookie()).Should(Equal(expectAllocator.RequestCookie()))
+		}
+
+		roundCookie, roundCookieMask := config.roundCookieWithMask(3)
+		expectCookie, expectCookieMask := cookie.RoundCookieWithMask(3)
+		Expect(roundCookie).Should(Equal(expectCookie))
+		Expect(roundCookieMask).Should(Equal(expectCookieMask))
+
+		Expect(*config.roundNumNXRange()).Should(Equal(*RoundNumNXRange))
+		Expect(*config.monitorTier2FlowSpaceNXRange()).Should(Equal(*MonitorTier2FlowSpaceNXRange))
+		Expect(*config.monitorTier3FlowSpaceNXRange()).Should(Equal(*MonitorTier3FlowSpaceNXRange))
+		Expect(config.workFlowSpaceNXRange().GetOfs()).Should(Equal(uint16(60)))
+		Expect(config.workFlowSpaceNXRange().GetNbits()).Should(Equal(uint16(28)))
+	})
+
+	t.Run("configured layout should split round and sequence", func(t *testing.T) {
+		config := &FlowCookieConfig{RoundNumBits: 8, FlowSeqBits: 24, MaxRoundNum: 200}
+		allocator := config.newCookieAllocator(200)
+		flowID := allocator.RequestCookie()
+		Expect(flowID).Should(Equal(uint64(200<<24 | 1)))
+		Expect(config.roundNum(flowID)).Should(Equal(uint64(200)))
+		Expect(config.flowSeq(flowID)).Should(Equal(uint64(1)))
+
+		allocator.SetFixedMask(1 << 32)
+		flowID = allocator.RequestCookie()
+		Expect(flowID).Should(Equal(uint64(1<<32 | 200<<24 | 2)))
+		Expect(config.roundNum(flowID)).Should(Equal(uint64(200)))
+		Expect(config.flowSeq(flowID)).Should(Equal(uint64(2)))
+
+		roundCookie, roundCookieMask := config.roundCookieWithMask(200)
+		Expect(roundCookie).Should(Equal(uint64(200 << 24)))
+		Expect(roundCookieMask).Should(Equal(uint64(0xff000000)))
+		Expect(flowID & roundCookieMask).Should(Equal(roundCookie))
+
+		Expect(config.roundNumNXRange().GetOfs()).Should(Equal(uint16(0)))
+		Expect(config.roundNumNXRange().GetNbits()).Should(Equal(uint16(8)))
+		Expect(config.monitorTier2FlowSpaceNXRange().GetOfs()).Should(Equal(uint16(8)))
+		Expect(config.monitorTier2FlowSpaceNXRange().GetNbits()).Should(Equal(uint16(24)))
+		Expect(config.monitorTier3FlowSpaceNXRange().GetOfs()).Should(Equal(uint16(32)))
+		Expect(config.monitorTier3FlowSpaceNXRange().GetNbits()).Should(Equal(uint16(24)))
+		Expect(config.workFlowSpaceNXRange().GetOfs()).Should(Equal(uint16(56)))
+		Expect(config.workFlowSpaceNXRange().GetNbits()).Should(Equal(uint16(24)))
+	})
+}
+
+func TestPuntedRuleFlowID(t *testing.T) {
+	RegisterTestingT(t)
+
+	// xxreg0 of rule flow 0x1_2345678 with default layout: round num 1 in bits 0-3, work flow sequence in bits 60-87
+	pkt := &ofctrl.PacketIn{Cookie: 0x5_00000007}
+	for i, reg := range []uint32{0, 0x234567, 0x80000000, 0x1} {
+		pkt.Match.Fields = append(pkt.Match.Fields, *openflow13.NewRegMatchField(i, reg, nil))
+	}
+	Expect(defaultFlowCookie.puntedRuleFlowID(pkt)).Should(Equal(uint64(0x5_12345678)), "fixed bits kept from punt flow cookie")
+
+	// round num 0x12 in bits 0-7, work flow sequence 0x345678 in bits 56-79 with 8 bits round num layout
+	config := &FlowCookieConfig{RoundNumBits: 8, FlowSeqBits: 24, MaxRoundNum: 255}
+	pkt = &ofctrl.PacketIn{Cookie: 0x7}
+	for i, reg := range []uint32{0, 0x3456, 0x78000000, 0x12} {
+		pkt.Match.Fields = append(pkt.Match.Fields, *openflow13.NewRegMatchField(i, reg, nil))
+	}
+	Expect(config.puntedRuleFlowID(pkt)).Should(Equal(uint64(0x12345678)))
+}
+
+func TestCookieAllocatorOfRound(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := NewDatapathManager(&DpManagerConfig{ManagedVDSMap: map[string]string{}}, nil)
+	allocator := dpMgr.cookieAllocatorOfRound("vds1", 3)
+	flowID := allocator.RequestCookie()
+
+	t.Run("allocator of the same round should continue the flow sequence", func(t *testing.T) {
+		replayAllocator := dpMgr.cookieAllocatorOfRound("vds1", 3)
+		Expect(replayAllocator).Should(BeIdenticalTo(allocator))
+		Expect(replayAllocator.RequestCookie()).Should(Equal(flowID + 1))
+	})
+
+	t.Run("allocator of a new round should restart the flow sequence", func(t *testing.T) {
+		Expect(dpMgr.cookieAllocatorOfRound("vds1", 4).RequestCookie()).Should(Equal(uint64(4<<FLOW_SEQ_NUM_LENGTH | 1)))
+		Expect(dpMgr.cookieAllocatorOfRound("vds2", 3).RequestCookie()).Should(Equal(uint64(3<<FLOW_SEQ_NUM_LENGTH | 1)))
+	})
+}
@@ -5,7 +5,6 @@ import (
 	"sync"
 
 	"github.com/contiv/libOpenflow/openflow13"
-	"github.com/contiv/ofnet/ofctrl/cookie"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -38,9 +37,10 @@ func (i *idGenerate) ascendUint64() uint64 {
 var learnCookieID = &idGenerate{}
 var groupID = &idGenerate{}
 
-func getLearnCookieID() (uint64, error) {
+// getLearnCookieID allocates cookie of learned flows in round 0, it must fit in the flow sequence bits
+func getLearnCookieID(flowSeqBits int) (uint64, error) {
 	id := learnCookieID.ascendUint64()
-	if id >= (uint64(1) << cookie.BitWidthFlowId) {
+	if id >= (uint64(1) << flowSeqBits) {
 		log.Error("No enough avalible cookie id")
 		return 0, errors.New("no enough avalible cookie id")
 	}
@@ -47,6 +47,7 @@ const (
 	P_NONE                             = 0xffff
 
 	InternalSvcPktMark uint32 = 1 << constants.InternalSvcPktMarkBit
+	IntraNodePktMark   uint32 = 1 << constants.IntraNodePktMarkBit
 )
 
 var (
@@ -55,6 +56,10 @@ var (
 	InternalSvcPktMarkMask uint32 = 1 << constants.InternalSvcPktMarkBit
 
 	InternalSvcPktMarkRange *openflow13.NXRange = openflow13.NewNXRange(constants.InternalSvcPktMarkBit, constants.InternalSvcPktMarkBit)
+	IntraNodePktMarkRange   *openflow13.NXRange = openflow13.NewNXRange(constants.IntraNodePktMarkBit, constants.IntraNodePktMarkBit)
+
+	// DefaultIPLearningIgnoreCIDRs are loopback and link-local cidrs, ip in them is not learned from endpoints
+	DefaultIPLearningIgnoreCIDRs = []string{"127.0.0.0/8", "169.254.0.0/16", "::1/128", "fe80::/10"}
 )
 
 type LocalBridge struct {
@@ -104,6 +109,9 @@ func newLocalBridge(brName string, datapathManager *DpManager) *LocalBridge {
 }
 
 func (l *LocalBridge) PacketRcvd(sw *ofctrl.OFSwitch, pkt *ofctrl.PacketIn) {
+	if !l.datapathManager.packetInLimiter.allow(l.name, PacketInLearning) {
+		return
+	}
 	switch pkt.Data.Ethertype {
 	case PROTOCOL_ARP:
 		if (pkt.Match.Type == openflow13.MatchType_OXM) &&
@@ -134,14 +142,10 @@ func (l *LocalBridge) processArp(pkt protocol.Ethernet, inPort uint32) {
 	case *protocol.ARP:
 		var arpIn protocol.ARP = *t
 
-		l.learnedIPAddressMapMutex.Lock()
-		defer l.learnedIPAddressMapMutex.Unlock()
-		l.setLocalEndpointIPAddr(arpIn, inPort)
-		ipReference, ok := l.learnedIPAddressMap[arpIn.IPSrc.String()]
-		if !ok {
-			l.processLocalEndpointUpdate(arpIn, pkt.VLANID.VID, inPort)
-		} else if ok && ipReference.updateTimes > 0 {
-			l.processLocalEndpointUpdate(arpIn, pkt.VLANID.VID, inPort)
+		if l.datapathManager.ipLearningIgnored(arpIn.IPSrc) {
+			log.Debugf("Ignore learning ip %s from port %d", arpIn.IPSrc, inPort)
+		} else {
+			l.learnArpIPAddr(arpIn, pkt.VLANID.VID, inPort)
 		}
 
 		select {
@@ -153,6 +157,45 @@ func (l *LocalBridge) processArp(pkt protocol.Ethernet, inPort uint32) {
 	}
 }
 
+func (l *LocalBridge) learnArpIPAddr(arpIn protocol.ARP, vlanID uint16, inPort uint32) {
+	l.learnedIPAddressMapMutex.Lock()
+	defer l.learnedIPAddressMapMutex.Unlock()
+
+	l.setLocalEndpointIPAddr(arpIn, inPort)
+	ipReference, ok := l.learnedIPAddressMap[arpIn.IPSrc.String()]
+	if !ok {
+		l.processLocalEndpointUpdate(arpIn, vlanID, inPort)
+	} else if ok && ipReference.updateTimes > 0 {
+		l.processLocalEndpointUpdate(arpIn, vlanID, inPort)
+	}
+}
+
+// ParseIPLearningIgnoreCIDRs parses cidrs of ip not learned from endpoints, nil means DefaultIPLearningIgnoreCIDRs
+func ParseIPLearningIgnoreCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	if cidrs == nil {
+		cidrs = DefaultIPLearningIgnoreCIDRs
+	}
+	ipNets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		ipNets = append(ipNets, ipNet)
+	}
+	return ipNets, nil
+}
+
+// ipLearningIgnored returns true if the ip should not be learned from endpoints
+func (datapathManager *DpManager) ipLearningIgnored(ip net.IP) bool {
+	for _, ipNet := range datapathManager.ipLearningIgnoreCIDRs {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 func (l *LocalBridge) cleanLocalIPAddressCacheWorker(cycle, timeout int, stopChan <-chan struct{}) {
 	ticker := time.NewTicker(time.Duration(cycle) * time.Second)
 	for {
@@ -197,11 +240,16 @@ func (l *LocalBridge) cleanLocalIPAddr(timeout int) {
 			continue
 		}
 		ipExpiredTime := endpoint.IPAddrLastUpdateTime.Add(time.Duration(timeout) * time.Second)
-		if time.Now().After(ipExpiredTime) {
+		expired := time.Now().After(ipExpiredTime)
+		if expired {
 			endpoint.IPAddr = nil
 		}
 		endpoint.IPAddrMutex.Unlock()
+		if expired {
+			l.datapathManager.refreshEndpointRuleMetrics(endpoint)
+		}
 	}
+	l.datapathManager.syncEndpointRuleMetrics()
 }
 
 func (l *LocalBridge) setLocalEndpointIPAddr(arpIn protocol.ARP, inPort uint32) {
@@ -268,6 +316,7 @@ func (l *LocalBridge) notifyLocalEndpointUpdate(arpIn protocol.ARP, vlanID uint1
 // specific type Bridge interface
 func (l *LocalBridge) BridgeInit() {
 	sw := l.OfSwitch
+	installControllerMeters(sw, l.datapathManager.packetInLimiter.config, PacketInLearning)
 
 	l.vlanInputTable = sw.DefaultTable()
 	l.vlanFilterTable, _ = sw.NewTable(VLAN_FILTER_TABLE)
@@ -887,8 +936,7 @@ func (l *LocalBridge) initFromLocalArpSendToCtrlTable(sw *ofctrl.OFSwitch) error
 		Priority:  HIGH_MATCH_FLOW_PRIORITY,
 		Ethertype: PROTOCOL_ARP,
 	})
-	sendToControllerAct := fromLocalArpSendToCtrlFlow.NewControllerAction(sw.ControllerID, 0)
-	_ = fromLocalArpSendToCtrlFlow.SendToController(sendToControllerAct)
+	_ = sendToMeteredController(sw, fromLocalArpSendToCtrlFlow, 0, PacketInLearning)
 	if err := fromLocalArpSendToCtrlFlow.Next(ofctrl.NewEmptyElem()); err != nil {
 		return fmt.Errorf("failed to install from local arp send to controller flow, error: %v", err)
 	}
@@ -47,8 +47,10 @@ type LocalBridgeOverlay struct {
 	enableProxy    bool
 	natPort        uint32
 	localEpFlowMap map[string]*ofctrl.Flow
-	arpFlowMap     map[string]*ofctrl.Flow
-	icmpFlowMap    map[string]*ofctrl.Flow
+	// intraNodeFlowMap marks traffic to the local endpoint as intra node traffic for policy match
+	intraNodeFlowMap map[string][]*ofctrl.Flow
+	arpFlowMap       map[string]*ofctrl.Flow
+	icmpFlowMap      map[string]*ofctrl.Flow
 }
 
 func newLocalBridgeOverlay(brName string, datapathManager *DpManager) *LocalBridgeOverlay {
@@ -72,6 +74,7 @@ func (l *LocalBridgeOverlay) BridgeInitCNI() {
 		l.natPort = l.datapathManager.Info.LocalGwOfPort
 	}
 	l.localEpFlowMap = make(map[string]*ofctrl.Flow)
+	l.intraNodeFlowMap = make(map[string][]*ofctrl.Flow)
 	if l.enableERIPAM {
 		l.arpFlowMap = make(map[string]*ofctrl.Flow)
 		l.icmpFlowMap = make(map[string]*ofctrl.Flow)
@@ -165,9 +168,54 @@ func (l *LocalBridgeOverlay) AddLocalEndpoint(endpoint *Endpoint) error {
 	}
 	l.localEpFlowMap[endpoint.InterfaceUUID] = flow
 	log.Infof("Local bridge overlay, success to add local endpoint flow in forward to local table, endpoint: %+v", endpoint)
+
+	intraNodeFlows, err := l.addIntraNodeFlows(endpoint.IPAddr)
+	if err != nil {
+		log.Errorf("Failed to add intra node flows in local bridge overlay for endpoint: %+v, err: %v", endpoint, err)
+		return err
+	}
+	l.intraNodeFlowMap[endpoint.InterfaceUUID] = intraNodeFlows
 	return nil
 }
 
+// addIntraNodeFlows marks traffic from local pods or services to the endpoint ip with IntraNodePktMark
+func (l *LocalBridgeOverlay) addIntraNodeFlows(ip net.IP) ([]*ofctrl.Flow, error) {
+	podFlow, _ := l.fromLocalTable.NewFlow(ofctrl.FlowMatch{
+		Ethertype: PROTOCOL_IP,
+		IpDa:      &ip,
+		Priority:  MID_MATCH_FLOW_PRIORITY,
+	})
+	if err := podFlow.LoadField("nxm_nx_pkt_mark", constants.PktMarkSetValue, IntraNodePktMarkRange); err != nil {
+		return nil, fmt.Errorf("failed to setup from local table intra node flow set mark action, err: %v", err)
+	}
+	if err := l.setFromLocalPodFlowActions(podFlow); err != nil {
+		return nil, err
+	}
+
+	// service traffic backend selected by nat bridge
+	svcFlow, _ := l.fromNatTable.NewFlow(ofctrl.FlowMatch{
+		Ethertype:   PROTOCOL_IP,
+		IpDa:        &ip,
+		PktMark:     InternalSvcPktMark,
+		PktMarkMask: &InternalSvcPktMarkMask,
+		Priority:    HIGH_MATCH_FLOW_PRIORITY + FLOW_MATCH_OFFSET,
+	})
+	if err := svcFlow.LoadField("nxm_nx_pkt_mark", constants.PktMarkSetValue, IntraNodePktMarkRange); err != nil {
+		return nil, fmt.Errorf("failed to setup from nat table intra node flow set mark action, err: %v", err)
+	}
+	if err := svcFlow.LoadField(LBOOutputPortReg, uint64(l.datapathManager.BridgeChainPortMap[l.name][LocalToPolicySuffix]), LBOOutputPortRange); err != nil {
+		return nil, fmt.Errorf("failed to setup from nat table intra node flow load field action, err: %v", err)
+	}
+	if err := svcFlow.Resubmit(nil, &LBOPaddingL2Table); err != nil {
+		return nil, fmt.Errorf("failed to setup from nat table intra node flow resubmit action, err: %v", err)
+	}
+	if err := svcFlow.Next(ofctrl.NewEmptyElem()); err != nil {
+		return nil, fmt.Errorf("failed to install from nat table intra node flow, err: %v", err)
+	}
+
+	return []*ofctrl.Flow{podFlow, svcFlow}, nil
+}
+
 func (l *LocalBridgeOverlay) RemoveLocalEndpoint(endpoint *Endpoint) error {
 	if endpoint == nil {
 		return nil
@@ -182,6 +230,14 @@ func (l *LocalBridgeOverlay) RemoveLocalEndpoint(endpoint *Endpoint) error {
 	}
 	delete(l.localEpFlowMap, endpoint.InterfaceUUID)
 	log.Infof("Local bridge overlay: success delete local endpoint flow in forward to local table, endpoint: %+v", endpoint)
+
+	for _, flow := range l.intraNodeFlowMap[endpoint.InterfaceUUID] {
+		if err := flow.Delete(); err != nil {
+			log.Errorf("Failed to delete intra node flow in local bridge overlay, endpoint: %+v, err: %v", endpoint, err)
+			return err
+		}
+	}
+	delete(l.intraNodeFlowMap, endpoint.InterfaceUUID)
 	return nil
 }
 
@@ -477,6 +533,11 @@ func (l *LocalBridgeOverlay) initFromLocalTable() error {
 		Ethertype: PROTOCOL_IP,
 		Priority:  NORMAL_MATCH_FLOW_PRIORITY,
 	})
+	return l.setFromLocalPodFlowActions(podFlow)
+}
+
+// setFromLocalPodFlowActions sends pod to pod traffic to policy bridge and installs the flow
+func (l *LocalBridgeOverlay) setFromLocalPodFlowActions(podFlow *ofctrl.Flow) error {
 	if err := podFlow.LoadField(LBOOutputPortReg, uint64(l.datapathManager.BridgeChainPortMap[l.name][LocalToPolicySuffix]), LBOOutputPortRange); err != nil {
 		return fmt.Errorf("failed to setup from local table pod flow load output port action, err: %v", err)
 	}
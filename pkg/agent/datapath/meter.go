@@ -0,0 +1,252 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datapath
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/contiv/libOpenflow/common"
+	"github.com/contiv/libOpenflow/openflow13"
+	"github.com/contiv/libOpenflow/util"
+	"github.com/contiv/ofnet/ofctrl"
+)
+
+// PacketInReason is the feature which sends packets to the controller, each bridge installs a controller
+// meter per reason, so that packets of one feature flooding the controller could neither starve packets
+// of other features nor packets of other bridges.
+type PacketInReason uint32
+
+// The value of the reason is used as the meter id of the reason on each bridge.
+const (
+	PacketInLearning PacketInReason = iota + 1
+	PacketInPolicyLogging
+	PacketInReject
+	PacketInIPOptions
+	PacketInRSTDetect
+)
+
+var packetInReasonNames = map[PacketInReason]string{
+	PacketInLearning:      "learning",
+	PacketInPolicyLogging: "policy_logging",
+	PacketInReject:        "reject",
+	PacketInIPOptions:     "ip_options",
+	PacketInRSTDetect:     "rst_detect",
+}
+
+func (r PacketInReason) String() string {
+	if name, ok := packetInReasonNames[r]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", uint32(r))
+}
+
+const (
+	meterModCommandAdd    = 0
+	meterModCommandDelete = 2
+	meterFlagPktps        = 0x2
+	meterFlagBurst        = 0x4
+	meterFlagStats        = 0x8
+	meterBandTypeDrop     = 1
+	meterModHeaderLen     = 16
+	meterBandDropLen      = 16
+
+	// properties of NXAST_CONTROLLER2, each property is padded to 8 bytes
+	nxController2HeaderLen      = 16
+	nxController2PropMaxLen     = 0
+	nxController2PropController = 1
+	nxController2PropReason     = 2
+	nxController2PropMeterID    = 5
+	nxController2PropLen        = 8
+	nxController2MaxLenAll      = 0xffff
+)
+
+// MeterMod is the openflow13 meter mod message with a single drop band, packets exceeding Rate per second
+// with Burst are dropped by the meter.
+type MeterMod struct {
+	common.Header
+	Command uint16
+	MeterID uint32
+	Rate    uint32
+	Burst   uint32
+}
+
+func newMeterMod(command uint16, meterID, rate, burst uint32) *MeterMod {
+	m := &MeterMod{
+		Header:  openflow13.NewOfp13Header(),
+		Command: command,
+		MeterID: meterID,
+		Rate:    rate,
+		Burst:   burst,
+	}
+	m.Header.Type = openflow13.Type_MeterMod
+	return m
+}
+
+func (m *MeterMod) Len() uint16 {
+	if m.Command == meterModCommandDelete {
+		return meterModHeaderLen
+	}
+	return meterModHeaderLen + meterBandDropLen
+}
+
+func (m *MeterMod) MarshalBinary() ([]byte, error) {
+	m.Header.Length = m.Len()
+	header, err := m.Header.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, m.Len())
+	copy(data, header)
+	binary.BigEndian.PutUint16(data[8:], m.Command)
+	binary.BigEndian.PutUint16(data[10:], meterFlagPktps|meterFlagBurst|meterFlagStats)
+	binary.BigEndian.PutUint32(data[12:], m.MeterID)
+	if m.Command == meterModCommandDelete {
+		return data, nil
+	}
+	binary.BigEndian.PutUint16(data[16:], meterBandTypeDrop)
+	binary.BigEndian.PutUint16(data[18:], meterBandDropLen)
+	binary.BigEndian.PutUint32(data[20:], m.Rate)
+	binary.BigEndian.PutUint32(data[24:], m.Burst)
+	return data, nil
+}
+
+func (m *MeterMod) UnmarshalBinary(data []byte) error {
+	if len(data) < meterModHeaderLen {
+		return fmt.Errorf("the []byte is too short to unmarshal a full MeterMod message")
+	}
+	if err := m.Header.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	m.Command = binary.BigEndian.Uint16(data[8:])
+	m.MeterID = binary.BigEndian.Uint32(data[12:])
+	if len(data) >= meterModHeaderLen+meterBandDropLen {
+		m.Rate = binary.BigEndian.Uint32(data[20:])
+		m.Burst = binary.BigEndian.Uint32(data[24:])
+	}
+	return nil
+}
+
+// NXActionController2 is the NXAST_CONTROLLER2 action, it sends packet to the controller through the meter
+type NXActionController2 struct {
+	*openflow13.NXActionHeader
+	ControllerID uint16
+	Reason       uint8
+	MeterID      uint32
+}
+
+func (a *NXActionController2) Len() uint16 {
+	return nxController2HeaderLen + 4*nxController2PropLen
+}
+
+func (a *NXActionController2) MarshalBinary() ([]byte, error) {
+	a.Length = a.Len()
+	header, err := a.NXActionHeader.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, a.Len())
+	copy(data, header)
+	n := nxController2HeaderLen
+	putProp := func(propType uint16, valueLen uint16, put func([]byte)) {
+		binary.BigEndian.PutUint16(data[n:], propType)
+		binary.BigEndian.PutUint16(data[n+2:], 4+valueLen)
+		put(data[n+4:])
+		n += nxController2PropLen
+	}
+	putProp(nxController2PropMaxLen, 2, func(b []byte) { binary.BigEndian.PutUint16(b, nxController2MaxLenAll) })
+	putProp(nxController2PropController, 2, func(b []byte) { binary.BigEndian.PutUint16(b, a.ControllerID) })
+	putProp(nxController2PropReason, 1, func(b []byte) { b[0] = a.Reason })
+	putProp(nxController2PropMeterID, 4, func(b []byte) { binary.BigEndian.PutUint32(b, a.MeterID) })
+	return data, nil
+}
+
+func (a *NXActionController2) UnmarshalBinary(data []byte) error {
+	a.NXActionHeader = new(openflow13.NXActionHeader)
+	if err := a.NXActionHeader.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	if len(data) < int(a.Length) || int(a.Length) < nxController2HeaderLen {
+		return fmt.Errorf("the []byte is too short to unmarshal a full NXActionController2 message")
+	}
+	for n := nxController2HeaderLen; n+4 <= int(a.Length); {
+		propType := binary.BigEndian.Uint16(data[n:])
+		propLen := int(binary.BigEndian.Uint16(data[n+2:]))
+		if propLen < 4 || n+propLen > int(a.Length) {
+			return fmt.Errorf("invalid NXActionController2 property length %d", propLen)
+		}
+		switch propType {
+		case nxController2PropController:
+			a.ControllerID = binary.BigEndian.Uint16(data[n+4:])
+		case nxController2PropReason:
+			a.Reason = data[n+4]
+		case nxController2PropMeterID:
+			a.MeterID = binary.BigEndian.Uint32(data[n+4:])
+		}
+		n += (propLen + 7) / 8 * 8
+	}
+	return nil
+}
+
+// meteredControllerAction implements ofctrl.Action, it sends packet to the controller through the controller
+// meter of the packet-in reason.
+type meteredControllerAction struct {
+	controllerID uint16
+	reason       uint8
+	meterID      uint32
+}
+
+func newMeteredControllerAction(controllerID uint16, reason uint8, packetInReason PacketInReason) *meteredControllerAction {
+	return &meteredControllerAction{
+		controllerID: controllerID,
+		reason:       reason,
+		meterID:      uint32(packetInReason),
+	}
+}
+
+func (a *meteredControllerAction) ToOfAction() (openflow13.Action, error) {
+	return &NXActionController2{
+		NXActionHeader: openflow13.NewNxActionHeader(openflow13.NXAST_CONTROLLER2),
+		ControllerID:   a.controllerID,
+		Reason:         a.reason,
+		MeterID:        a.meterID,
+	}, nil
+}
+
+func (a *meteredControllerAction) GetActionType() string {
+	return ofctrl.ActTypeController
+}
+
+// sendToMeteredController adds action to the flow which sends packet to the controller through the controller
+// meter of the packet-in reason
+func sendToMeteredController(sw *ofctrl.OFSwitch, flow *ofctrl.Flow, reason uint8, packetInReason PacketInReason) error {
+	return flow.AddAction(newMeteredControllerAction(sw.ControllerID, reason, packetInReason))
+}
+
+// installControllerMeters (re)installs controller meters of the packet-in reasons on the bridge, it must be
+// called before flows sending packets to the controller are installed.
+func installControllerMeters(sw *ofctrl.OFSwitch, config *PacketInLimitConfig, reasons ...PacketInReason) {
+	for _, reason := range reasons {
+		rate, burst := config.limitOf(reason)
+		for _, msg := range []util.Message{
+			newMeterMod(meterModCommandDelete, uint32(reason), 0, 0),
+			newMeterMod(meterModCommandAdd, uint32(reason), uint32(rate), uint32(burst)),
+		} {
+			sw.Send(msg)
+		}
+	}
+}
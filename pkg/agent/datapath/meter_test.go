@@ -0,0 +1,66 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datapath
+
+import (
+	"testing"
+
+	"github.com/contiv/libOpenflow/openflow13"
+	. "github.com/onsi/gomega"
+)
+
+func TestMeterMod(t *testing.T) {
+	RegisterTestingT(t)
+
+	data, err := newMeterMod(meterModCommandAdd, uint32(PacketInReject), 100, 200).MarshalBinary()
+	Expect(err).Should(Succeed())
+	Expect(data[:4]).Should(Equal([]byte{openflow13.VERSION, openflow13.Type_MeterMod, 0, 32}))
+	Expect(data[8:]).Should(Equal([]byte{
+		0, 0, 0, 0xe, 0, 0, 0, 3, // command add, flags pktps|burst|stats, meter id
+		0, 1, 0, 16, 0, 0, 0, 100, 0, 0, 0, 200, 0, 0, 0, 0, // drop band
+	}))
+
+	data, err = newMeterMod(meterModCommandDelete, uint32(PacketInReject), 0, 0).MarshalBinary()
+	Expect(err).Should(Succeed())
+	Expect(data).Should(HaveLen(16))
+	meterMod := new(MeterMod)
+	Expect(meterMod.UnmarshalBinary(data)).Should(Succeed())
+	Expect(meterMod.Command).Should(Equal(uint16(meterModCommandDelete)))
+	Expect(meterMod.MeterID).Should(Equal(uint32(PacketInReject)))
+}
+
+func TestMeteredControllerAction(t *testing.T) {
+	RegisterTestingT(t)
+
+	act, err := newMeteredControllerAction(1, openflow13.R_ACTION, PacketInPolicyLogging).ToOfAction()
+	Expect(err).Should(Succeed())
+	data, err := act.MarshalBinary()
+	Expect(err).Should(Succeed())
+	Expect(data).Should(Equal([]byte{
+		0xff, 0xff, 0, 48, 0, 0, 0x23, 0x20, 0, 37, 0, 0, 0, 0, 0, 0, // NXAST_CONTROLLER2
+		0, 0, 0, 6, 0xff, 0xff, 0, 0, // max len
+		0, 1, 0, 6, 0, 1, 0, 0, // controller id
+		0, 2, 0, 5, openflow13.R_ACTION, 0, 0, 0, // reason
+		0, 5, 0, 8, 0, 0, 0, 2, // meter id
+	}))
+
+	decoded := new(NXActionController2)
+	Expect(decoded.UnmarshalBinary(data)).Should(Succeed())
+	Expect(decoded.ControllerID).Should(Equal(uint16(1)))
+	Expect(decoded.Reason).Should(Equal(uint8(openflow13.R_ACTION)))
+	Expect(decoded.MeterID).Should(Equal(uint32(PacketInPolicyLogging)))
+}
@@ -23,7 +23,9 @@ import (
 	"fmt"
 	"net"
 	"os/exec"
+	"path"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -33,18 +35,20 @@ import (
 	"github.com/contiv/libOpenflow/openflow13"
 	"github.com/contiv/libOpenflow/protocol"
 	"github.com/contiv/ofnet/ofctrl"
-	"github.com/contiv/ofnet/ofctrl/cookie"
 	"github.com/contiv/ofnet/ovsdbDriver"
 	cmap "github.com/orcaman/concurrent-map"
 	log "github.com/sirupsen/logrus"
 	lock "github.com/viney-shih/go-lock"
 	"github.com/vishvananda/netlink"
 	"golang.org/x/sys/unix"
+	uerr "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog"
+	"k8s.io/utils/clock"
 
 	policycache "github.com/everoute/everoute/pkg/agent/controller/policy/cache"
+	"github.com/everoute/everoute/pkg/agent/metrics"
 	"github.com/everoute/everoute/pkg/apis/rpc/v1alpha1"
 	"github.com/everoute/everoute/pkg/constants"
 	"github.com/everoute/everoute/pkg/types"
@@ -70,6 +74,11 @@ const (
 	POLICY_TIER3    = 150
 )
 
+const (
+	CTZoneStrategyGlobal = "global"
+	CTZoneStrategyVlan   = "vlan"
+)
+
 //nolint:all
 const (
 	POLICY_DIRECTION_OUT = 0
@@ -89,15 +98,19 @@ const (
 	FLOW_ROUND_NUM_MASK             = 0xf0000000
 	FLOW_SEQ_NUM_MASK               = 0x0fffffff
 	DEFAULT_POLICY_ENFORCEMENT_MODE = "work"
+	MONITOR_POLICY_ENFORCEMENT_MODE = "monitor"
 )
 
 //nolint:all
 const (
-	PROTOCOL_ARP  = 0x0806
-	PROTOCOL_IP   = 0x0800
-	PROTOCOL_UDP  = 0x11
-	PROTOCOL_TCP  = 0x06
-	PROTOCOL_ICMP = 0x01
+	PROTOCOL_ARP    = 0x0806
+	PROTOCOL_IP     = 0x0800
+	PROTOCOL_IPV6   = 0x86dd
+	PROTOCOL_UDP    = 0x11
+	PROTOCOL_TCP    = 0x06
+	PROTOCOL_ICMP   = 0x01
+	PROTOCOL_GRE    = 0x2f
+	PROTOCOL_ICMPV6 = 0x3a
 )
 
 //nolint:all
@@ -111,6 +124,8 @@ const (
 
 const (
 	datapathRestartRound            string = "datapathRestartRound"
+	datapathRoundNumBits            string = "datapathRoundNumBits"
+	datapathDisabledRuleGroups      string = "datapathDisabledRuleGroups"
 	ovsVswitchdUnixDomainSockPath   string = "/var/run/openvswitch"
 	ovsVswitchdUnixDomainSockSuffix string = "mgmt"
 	ovsdbDomainSock                        = "/var/run/openvswitch/db.sock"
@@ -127,6 +142,7 @@ const (
 	ClsBridgeL2ForwardingTableHardTimeout   = 300
 	ClsBridgeL2ForwardingTableIdleTimeout   = 300
 	MaxIPAddressLearningFrenquency          = 5
+	RuleFlowStatsUpdateInterval             = 10
 
 	LocalToPolicySuffix = "local-to-policy"
 	PolicyToLocalSuffix = "policy-to-local"
@@ -147,6 +163,24 @@ const (
 	MaxArpChanCache = 100
 
 	MaxCleanConntrackChanSize = 5000
+	// DefaultMaxCTCleanDefer is the default max time conntrack clean deferred in a batch
+	DefaultMaxCTCleanDefer = 30 * time.Second
+
+	VerifyRuleFlowInterval = 10 * time.Millisecond
+	VerifyRuleFlowTimeout  = time.Second
+	RuleFlowBarrierTimeout = 10 * time.Second
+
+	// ReplayProgressCheckpoint is the number of rules replayed between two progress logs
+	ReplayProgressCheckpoint = 1000
+
+	// previous round flows would be flushed after policy rules synced and the number of current round flows
+	// keeps unchanged in RoundFlowConvergeStableTimes successive samples, but never in RoundFlowFlushMinDelay
+	RoundFlowConvergeInterval    = time.Second
+	RoundFlowConvergeStableTimes = 5
+	RoundFlowFlushMinDelay       = 15 * time.Second
+	RoundFlowConvergeTimeout     = 5 * time.Minute
+
+	RSTDenyExpireInterval = time.Second
 )
 
 var (
@@ -163,6 +197,9 @@ var (
 	IcmpTypeReply   uint8
 
 	lockTimeout = 5 * time.Minute
+
+	// internalIPRetryInterval is the interval of checking bridges ready and retrying internal ip rules install
+	internalIPRetryInterval = time.Second
 )
 
 var IPMaskMatchFullBit = net.ParseIP("255.255.255.255")
@@ -215,6 +252,19 @@ type Bridge interface {
 	getOfSwitch() *ofctrl.OFSwitch
 }
 
+// ruleFlowReader reads back the installed rule flow, it's implemented by policy bridge
+type ruleFlowReader interface {
+	ReadRuleFlow(flowEntry *FlowEntry) (*InstalledFlow, error)
+	ReadRuleTableFlowIDs() (sets.Set[uint64], error)
+	barrier(timeout time.Duration) error
+}
+
+// policyTierLoader switches the policy tables to the reloaded tiers, it's implemented by policy bridge
+type policyTierLoader interface {
+	preparePolicyTables(tiers PolicyTiers)
+	switchPolicyTables(tiers PolicyTiers) error
+}
+
 type DpManager struct {
 	DpManagerMutex     sync.Mutex
 	BridgeChainMap     map[string]map[string]Bridge                 // map vds to bridge instance map
@@ -230,14 +280,58 @@ type DpManager struct {
 	FlowIDToRules             map[uint64]*EveroutePolicyRuleEntry
 	flowReplayMutex           *lock.CASMutex
 
-	flushMutex         *lock.ChanMutex
-	needFlush          bool                    // need to flush
-	cleanConntrackChan chan EveroutePolicyRule // clean conntrack entries for rule in chan
+	flushMutex          *lock.ChanMutex
+	needFlush           bool                    // need to flush
+	cleanConntrackChan  chan EveroutePolicyRule // clean conntrack entries for rule in chan
+	cleanConntrackBatch EveroutePolicyRuleList  // rules drained from cleanConntrackChan when reach high-water mark
 
 	ArpChan chan ArpInfo
 
+	AgentMetric *metrics.AgentMetric
+
+	packetInLimiter *packetInLimiter
+
+	decisionRecorder *decisionRecorder // nil if decision recording disabled
+	decisionLogFunc  func(decision PolicyDecision, loggingTags map[string]string)
+
+	ipLearningIgnoreCIDRs []*net.IPNet
+
 	proxyReplayFunc   func()
 	overlayReplayFunc func()
+	ctFlushFunc       func() error // flush all conntrack entries
+	// ctTimeoutPolicyFunc sets the ct timeout policy of the zone, it's applied on the first rule with CTTimeout installed
+	ctTimeoutPolicyFunc    func(zone uint16, policy map[string]uint32) error
+	ctTimeoutPolicyApplied bool
+	// ctDeleteFunc deletes conntrack entries of the family match the filter
+	ctDeleteFunc   func(family netlink.InetFamily, filter netlink.CustomConntrackFilter) (uint, error)
+	deleteFlowFunc func(table *ofctrl.Table, priority uint16, flowID uint64) error
+	ipProbeFunc    func(ctx context.Context, endpointIP *types.EndpointIP) error // send arp or ns probe to endpoint ip
+
+	// disabledRuleGroups are rule groups whose rule flows are removed from datapath
+	disabledRuleGroups sets.Set[string]
+
+	// ruleIDsByIPAddr indexes Rules by the rule endpoint ip address, it's protected by flowReplayMutex
+	ruleIDsByIPAddr     map[string]sets.Set[string]
+	endpointRuleMetrics *endpointRuleMetrics
+
+	// migratedEndpoints are local endpoints whose flows have been removed for migrated to other agents, keyed
+	// by interface uuid. They are restored when migrated back, and forgotten when the ovsdb interface removed.
+	migratedEndpoints cmap.ConcurrentMap
+
+	// replayProgress is the latest rule flows replay progress of each vds, it's not protected by
+	// flowReplayMutex, so that it could be read during replay
+	replayProgressLock sync.RWMutex
+	replayProgress     map[string]*ReplayProgress
+
+	// cookieAllocators are the flow cookie allocators of the current round of each vds, the allocator is kept
+	// on bridge reconnect, so that flows allocated after reconnect never reuse cookies of the flows kept in ovs
+	cookieAllocatorLock sync.Mutex
+	cookieAllocators    map[string]*flowCookieAllocator
+
+	// policyRulesSynced is closed after rules of policies existed on agent start installed, flows of the
+	// previous round are kept until then. Nil means there's no policy controller to wait for.
+	policyRulesSynced     chan struct{}
+	policyRulesSyncedOnce sync.Once
 
 	// everoute ipam
 	ippoolSubnets sets.Set[string]
@@ -275,6 +369,51 @@ type DpManagerConfig struct {
 	EnableIPLearning bool                // enable ip learning
 	EnableCNI        bool                // enable CNI in Everoute
 	CNIConfig        *DpManagerCNIConfig // config related CNI
+	CTTimeoutPolicy  map[string]uint32   // map protocol to conntrack idle timeout seconds of connections allowed by rules with CTTimeout
+	// CTFlushHighWaterMark drain clean conntrack chan into a batch when it reach the mark, the batch
+	// entries would be cleaned by rules, full flush only when the batch overflow. 0 means full flush
+	// when clean conntrack chan overflow.
+	CTFlushHighWaterMark int
+	// DeferCTCleanInBatch holds conntrack clean of rules changed in the batch started by BeginConntrackBatch,
+	// and cleans them at once on commit, so that a sync changes many rules disrupts connections only once.
+	DeferCTCleanInBatch bool
+	// MaxCTCleanDefer is the max time conntrack clean deferred in a batch, conntrack of rules changed in the
+	// batch are cleaned after it even if the batch not committed. 0 means DefaultMaxCTCleanDefer.
+	MaxCTCleanDefer time.Duration
+	// CTZoneStrategy is the strategy of assigning conntrack zone to endpoints on policy bridge, global
+	// assign all endpoints to CTZoneForPolicy, vlan derive the zone from the endpoint vlan, so that
+	// endpoints with overlapping ip on different vlans have isolated conntrack. Default global.
+	CTZoneStrategy string
+	// VerifyRuleFlow reads back and verifies the rule flow after install, it catches silent ovs install
+	// failures, but adds latency to rule install.
+	VerifyRuleFlow bool
+	// TCPRSTDetect counts tcp rst sent by sources on policy bridge, and denies the source exceeding the
+	// threshold temporarily. Nil means disable the detection.
+	TCPRSTDetect *TCPRSTDetectConfig
+	// FlowCookie is the layout of round num and flow sequence in flow cookie, nodes restart agent frequently
+	// could take more round bits to delay the round num wrap. Nil means the default layout.
+	FlowCookie *FlowCookieConfig
+	// PacketInLimit limits packet-in of each reason from each bridge to the controller with controller meters.
+	// Nil means DefaultPacketInRate with DefaultPacketInBurst, and the default learning limit.
+	PacketInLimit *PacketInLimitConfig
+	// IPLearningIgnoreCIDRs are cidrs of ip not learned from endpoints, e.g. link-local address configured
+	// by guest os. Nil means DefaultIPLearningIgnoreCIDRs.
+	IPLearningIgnoreCIDRs []string
+	// InternalIPGracePeriod delays installing internal ip whitelist after datapath initialized, rules are
+	// installed only after all bridges connected and retried on failure. 0 means install without delay.
+	InternalIPGracePeriod time.Duration
+	// DecisionRecordSize records verdicts and matched rules of new connections hit work mode rule flows
+	// into a ring buffer of the size, e.g. for building regression corpus. 0 means disable the recording.
+	DecisionRecordSize int
+	// EnablePolicyLogging sends the first packet of new connections hit rules enabled logging to the agent
+	// for logging, the packets are limited by the controller meter. Rule logging is ignored if disabled.
+	EnablePolicyLogging bool
+	// RuleFlowReconcileInterval reads back rule flows of policy bridges in the interval, and reinstalls rules whose
+	// flows are absent in ovs. 0 means rule flows are only replayed on bridge reconnect.
+	RuleFlowReconcileInterval time.Duration
+	// PolicyTiers is the ordered list of policy tiers packet walked through in policy bridge, custom tiers
+	// could be inserted among the builtin tiers. Nil means DefaultPolicyTiers.
+	PolicyTiers PolicyTiers
 }
 
 type DpManagerCNIConfig struct {
@@ -298,6 +437,7 @@ type Endpoint struct {
 	VlanID               uint16 // endpoint vlan id
 	Trunk                string // vlan trunk config
 	BridgeName           string // bridge name that endpoint attached to
+	NotReady             bool   // traffic to not ready endpoint would be dropped until it marked ready
 }
 
 type EveroutePolicyRule struct {
@@ -310,12 +450,35 @@ type EveroutePolicyRule struct {
 	SrcPortMask uint16
 	DstPort     uint16 // destination port
 	DstPortMask uint16
-	Action      string // rule action: 'allow' or 'deny'
+	Action      string // rule action: 'allow', 'deny' or 'reject'
+	IPOptions   bool   // only match packets with ip options, e.g. source routing, supported by deny rule
+	ICMPType    *uint8 // icmp type, nil matches all icmp types
+	ICMPCode    *uint8 // icmp code, nil matches all icmp codes
+
+	TrafficLocality string // 'intra-node' or 'inter-node', only supported in overlay mode, empty matches both
+	VlanID          uint16 // only match packets tagged with the vlan, 0 matches all vlans
+
+	CTZones []uint16 // conntrack zones conntrack of the rule cleaned in, empty matches all zones
+
+	Logged        bool  // packets matched the rule would be logged
+	LogSampleRate int32 // percentage of logged connections matched the rule sampled in datapath, zero logs all connections
+
+	CTTimeout bool // connections allowed by the rule expire by the ct timeout policy when idle
 }
 
 const (
 	EveroutePolicyAllow string = "allow"
 	EveroutePolicyDeny  string = "deny"
+	// EveroutePolicyReject denies the packet as deny, and replies tcp rst or icmp unreachable to the source
+	EveroutePolicyReject string = "reject"
+)
+
+const (
+	// TrafficLocalityIntraNode matches traffic between endpoints on the same node
+	TrafficLocalityIntraNode string = "intra-node"
+	// TrafficLocalityInterNode matches traffic not marked as intra node, including traffic from or to other nodes
+	// and outside the cluster
+	TrafficLocalityInterNode string = "inter-node"
 )
 
 type FlowEntry struct {
@@ -324,6 +487,28 @@ type FlowEntry struct {
 	FlowID   uint64
 }
 
+// flowCounter is the hit counters of a flow
+type flowCounter struct {
+	PacketCount uint64
+	ByteCount   uint64
+}
+
+// UnmanagedBridgeError means the endpoint attached to a bridge not in ManagedVDSMap, its flows weren't programmed
+// AddLocalEndpoint doesn't log it, callers decide how to report it.
+type UnmanagedBridgeError struct {
+	InterfaceUUID string
+	BridgeName    string
+}
+
+func (e *UnmanagedBridgeError) Error() string {
+	return fmt.Sprintf("endpoint %s attached to unmanaged bridge %s", e.InterfaceUUID, e.BridgeName)
+}
+
+func IsUnmanagedBridgeError(err error) bool {
+	var target *UnmanagedBridgeError
+	return errors.As(err, &target)
+}
+
 type EveroutePolicyRuleEntry struct {
 	EveroutePolicyRule  *EveroutePolicyRule
 	Direction           uint8
@@ -331,11 +516,25 @@ type EveroutePolicyRuleEntry struct {
 	Mode                string
 	RuleFlowMap         map[string]*FlowEntry
 	PolicyRuleReference sets.String
+	LoggingTags         map[string]string // logging tags of the rule, policy type of it labels the rule metrics
+	RuleGroupReference  map[string]string // rule group of each reference, flows are not installed when all groups disabled
+	ARPBlockReference   sets.String       // references denying ARP and ND of the endpoint the rule applied to
+}
+
+// ReplayProgress is the progress of rule flows replay on a vds
+type ReplayProgress struct {
+	Replayed   int
+	Total      int
+	StartTime  time.Time
+	FinishTime time.Time // zero if the replay not finished
+	Error      error     // the reason the replay failed, nil if the replay succeeded or not finished
 }
 
 type RoundInfo struct {
 	previousRoundNum uint64
 	curRoundNum      uint64
+	// previousFlowCookie is the flow cookie layout flows of the previous round allocated with
+	previousFlowCookie *FlowCookieConfig
 }
 
 type PolicyInfo struct {
@@ -369,14 +568,44 @@ func NewDatapathManager(datapathConfig *DpManagerConfig, ofPortIPAddressUpdateCh
 	datapathManager.Rules = make(map[string]*EveroutePolicyRuleEntry)
 	datapathManager.FlowIDToRules = make(map[uint64]*EveroutePolicyRuleEntry)
 	datapathManager.Config = datapathConfig
+	if err := datapathManager.flowCookie().Validate(); err != nil {
+		log.Fatalf("Invalid flow cookie config: %v", err)
+	}
+	if err := datapathManager.policyTiers().Validate(); err != nil {
+		log.Fatalf("Invalid policy tiers config: %v", err)
+	}
+	ipLearningIgnoreCIDRs, err := ParseIPLearningIgnoreCIDRs(datapathConfig.IPLearningIgnoreCIDRs)
+	if err != nil {
+		log.Fatalf("Invalid ip learning ignore cidrs: %v", err)
+	}
+	datapathManager.ipLearningIgnoreCIDRs = ipLearningIgnoreCIDRs
 	datapathManager.localEndpointDB = cmap.New()
 	datapathManager.Info = new(DpManagerInfo)
 	datapathManager.flowReplayMutex = lock.NewCASMutex()
 	datapathManager.flushMutex = lock.NewChanMutex()
 	datapathManager.cleanConntrackChan = make(chan EveroutePolicyRule, MaxCleanConntrackChanSize)
 	datapathManager.ArpChan = make(chan ArpInfo, MaxArpChanCache)
+	datapathManager.AgentMetric = metrics.NewAgentMetric()
+	datapathManager.packetInLimiter = newPacketInLimiter(datapathConfig.PacketInLimit, clock.RealClock{}, datapathManager.AgentMetric)
+	if datapathConfig.DecisionRecordSize > 0 {
+		datapathManager.decisionRecorder = newDecisionRecorder(datapathConfig.DecisionRecordSize)
+	}
+	datapathManager.decisionLogFunc = logPolicyDecision
 	datapathManager.proxyReplayFunc = func() {}
 	datapathManager.overlayReplayFunc = func() {}
+	datapathManager.ctFlushFunc = func() error { return netlink.ConntrackTableFlush(netlink.ConntrackTable) }
+	datapathManager.ctDeleteFunc = func(family netlink.InetFamily, filter netlink.CustomConntrackFilter) (uint, error) {
+		return netlink.ConntrackDeleteFilter(netlink.ConntrackTable, family, filter)
+	}
+	datapathManager.deleteFlowFunc = ofctrl.DeleteFlow
+	datapathManager.ctTimeoutPolicyFunc = SetCTTimeoutPolicy
+	datapathManager.ipProbeFunc = datapathManager.HandleEndpointIPTimeout
+	datapathManager.disabledRuleGroups = sets.New[string]()
+	datapathManager.ruleIDsByIPAddr = make(map[string]sets.Set[string])
+	datapathManager.endpointRuleMetrics = newEndpointRuleMetrics()
+	datapathManager.migratedEndpoints = cmap.New()
+	datapathManager.replayProgress = make(map[string]*ReplayProgress)
+	datapathManager.cookieAllocators = make(map[string]*flowCookieAllocator)
 	datapathManager.ippoolSubnets = sets.New[string]()
 	datapathManager.ippoolGWs = sets.New[string]()
 
@@ -427,16 +656,29 @@ func (datapathManager *DpManager) InitializeDatapath(stopChan <-chan struct{}) {
 	}
 	wg.Wait()
 
-	// add rules for internalIP
-	for index, internalIP := range datapathManager.Config.InternalIPs {
-		datapathManager.addIntenalIP(internalIP, index)
+	if len(datapathManager.Config.CTTimeoutPolicy) != 0 && datapathManager.IsCTZoneByVlan() {
+		log.Warningf("ct timeout policy is only supported by %s ct zone strategy, ignore it", CTZoneStrategyGlobal)
 	}
+
+	// add rules for internalIP
+	datapathManager.installInternalIPs(stopChan)
 	// add internal ip handle
 	if len(datapathManager.Config.InternalIPs) != 0 {
 		go datapathManager.syncIntenalIPs(stopChan)
 	}
 
 	go wait.Until(datapathManager.cleanConntrackWorker, time.Second, stopChan)
+	if datapathManager.Config.TCPRSTDetect != nil {
+		go wait.Until(datapathManager.removeExpiredRSTDeny, RSTDenyExpireInterval, stopChan)
+	}
+	go wait.Until(datapathManager.requestRuleFlowStats, RuleFlowStatsUpdateInterval*time.Second, stopChan)
+	if interval := datapathManager.Config.RuleFlowReconcileInterval; interval > 0 {
+		go wait.Until(func() {
+			if err := datapathManager.ReconcileRuleFlows(); err != nil {
+				log.Errorf("Failed to reconcile rule flows: %v", err)
+			}
+		}, interval, stopChan)
+	}
 
 	for vdsID, bridgeName := range datapathManager.Config.ManagedVDSMap {
 		for bridgeKeyword := range datapathManager.ControllerMap[vdsID] {
@@ -464,6 +706,25 @@ func (datapathManager *DpManager) SetOverlaySyncFunc(f func()) {
 	datapathManager.overlayReplayFunc = f
 }
 
+// WaitPolicyRulesSynced makes flows of the previous round flushed only after MarkPolicyRulesSynced called, so
+// that previous round rule flows keep working until rules of the current round installed. It must be called
+// before InitializeDatapath.
+func (datapathManager *DpManager) WaitPolicyRulesSynced() {
+	datapathManager.policyRulesSynced = make(chan struct{})
+}
+
+// MarkPolicyRulesSynced marks rules of policies existed on agent start installed, it's safe to call it many
+// times or without WaitPolicyRulesSynced.
+func (datapathManager *DpManager) MarkPolicyRulesSynced() {
+	if datapathManager.policyRulesSynced == nil {
+		return
+	}
+	datapathManager.policyRulesSyncedOnce.Do(func() {
+		log.Infof("Policy rules synced")
+		close(datapathManager.policyRulesSynced)
+	})
+}
+
 func (datapathManager *DpManager) GetChainBridge() []string {
 	datapathManager.lockRflowReplayWithTimeout()
 	defer datapathManager.flowReplayMutex.RUnlock()
@@ -508,40 +769,385 @@ func (datapathManager *DpManager) GetPolicyByFlowID(flowID ...uint64) []*PolicyI
 	return policyInfoList
 }
 
-func (datapathManager *DpManager) GetRulesByFlowIDs(flowIDs ...uint64) []*v1alpha1.RuleEntry {
+// GetRulesByFlowIDs returns rules own the flows, rule flows are scoped to the vds when vdsID is not empty
+func (datapathManager *DpManager) GetRulesByFlowIDs(vdsID string, flowIDs ...uint64) []*v1alpha1.RuleEntry {
 	datapathManager.lockRflowReplayWithTimeout()
 	defer datapathManager.flowReplayMutex.RUnlock()
 	ans := []*v1alpha1.RuleEntry{}
 	for _, id := range flowIDs {
 		if entry, ok := datapathManager.FlowIDToRules[id]; ok {
-			ans = append(ans, datapathRule2RpcRule(entry))
+			if rpcRule := datapathRule2RpcRule(entry, vdsID); rpcRule != nil {
+				ans = append(ans, rpcRule)
+			}
 		}
 	}
 	return ans
 }
 
-func (datapathManager *DpManager) GetRulesByRuleIDs(ruleIDs ...string) []*v1alpha1.RuleEntry {
+// GetRulesByRuleIDs returns rules with the ids, rule flows are scoped to the vds when vdsID is not empty
+func (datapathManager *DpManager) GetRulesByRuleIDs(vdsID string, ruleIDs ...string) []*v1alpha1.RuleEntry {
 	datapathManager.lockRflowReplayWithTimeout()
 	defer datapathManager.flowReplayMutex.RUnlock()
 	ans := []*v1alpha1.RuleEntry{}
 	for _, id := range ruleIDs {
 		if entry, ok := datapathManager.Rules[id]; ok {
-			ans = append(ans, datapathRule2RpcRule(entry))
+			if rpcRule := datapathRule2RpcRule(entry, vdsID); rpcRule != nil {
+				ans = append(ans, rpcRule)
+			}
 		}
 	}
 	return ans
 }
 
-func (datapathManager *DpManager) GetAllRules() []*v1alpha1.RuleEntry {
+// GetAllRules returns all rules, rule flows are scoped to the vds when vdsID is not empty
+func (datapathManager *DpManager) GetAllRules(vdsID string) []*v1alpha1.RuleEntry {
 	datapathManager.lockRflowReplayWithTimeout()
 	defer datapathManager.flowReplayMutex.RUnlock()
 	ans := []*v1alpha1.RuleEntry{}
 	for _, entry := range datapathManager.Rules {
-		ans = append(ans, datapathRule2RpcRule(entry))
+		if rpcRule := datapathRule2RpcRule(entry, vdsID); rpcRule != nil {
+			ans = append(ans, rpcRule)
+		}
+	}
+	return ans
+}
+
+// DumpFlows returns flows installed by everoute policy rules for each vds and bridge
+func (datapathManager *DpManager) DumpFlows() []*v1alpha1.BridgeFlowDump {
+	datapathManager.lockRflowReplayWithTimeout()
+	defer datapathManager.flowReplayMutex.RUnlock()
+
+	ans := []*v1alpha1.BridgeFlowDump{}
+	for _, vdsID := range sets.StringKeySet(datapathManager.BridgeChainMap).List() {
+		bridgeChain := datapathManager.BridgeChainMap[vdsID]
+		for _, keyword := range sets.StringKeySet(bridgeChain).List() {
+			bridgeFlowDump := &v1alpha1.BridgeFlowDump{
+				VdsID:         vdsID,
+				BridgeKeyword: keyword,
+				BridgeName:    bridgeChain[keyword].GetName(),
+				FlowEntries:   []*v1alpha1.FlowDumpEntry{},
+			}
+			// policy rule flows only installed in policy bridge
+			if keyword == POLICY_BRIDGE_KEYWORD {
+				for _, ruleID := range sets.StringKeySet(datapathManager.Rules).List() {
+					entry := datapathManager.Rules[ruleID]
+					flowEntry, ok := entry.RuleFlowMap[vdsID]
+					if !ok || flowEntry == nil {
+						continue
+					}
+					bridgeFlowDump.FlowEntries = append(bridgeFlowDump.FlowEntries, &v1alpha1.FlowDumpEntry{
+						FlowEntry:           flowEntry2RpcFlowEntry(flowEntry),
+						RuleID:              ruleID,
+						PolicyRuleReference: policyRuleReference2RpcReference(entry.PolicyRuleReference),
+					})
+				}
+			}
+			ans = append(ans, bridgeFlowDump)
+		}
+	}
+	return ans
+}
+
+// QueryReachable decides whether packet from srcIP to dstIP with protocol and dst port is allowed by the
+// installed work mode rules, rules are walked in the same order of datapath policy tier tables.
+func (datapathManager *DpManager) QueryReachable(srcIP, dstIP net.IP, protocol uint8, port uint16) *v1alpha1.ReachableResult {
+	datapathManager.lockRflowReplayWithTimeout()
+	defer datapathManager.flowReplayMutex.RUnlock()
+
+	egress := datapathManager.decideRule(POLICY_DIRECTION_OUT, srcIP, dstIP, protocol, port)
+	ingress := datapathManager.decideRule(POLICY_DIRECTION_IN, srcIP, dstIP, protocol, port)
+
+	return &v1alpha1.ReachableResult{
+		Reachable: !isDenyAction(egress.Action) && !isDenyAction(ingress.Action),
+		Egress:    egress,
+		Ingress:   ingress,
+	}
+}
+
+func (datapathManager *DpManager) decideRule(direction uint8, srcIP, dstIP net.IP, protocol uint8, port uint16) *v1alpha1.RuleDecision {
+	for _, tier := range datapathManager.policyTiers().order() {
+		var decided *EveroutePolicyRuleEntry
+		for _, entry := range datapathManager.Rules {
+			if entry.Direction != direction || entry.Tier != tier || entry.Mode != DEFAULT_POLICY_ENFORCEMENT_MODE {
+				continue
+			}
+			if datapathManager.ruleGroupDisabled(entry) {
+				continue
+			}
+			// queried packet carries no ip options
+			if entry.EveroutePolicyRule.IPOptions {
+				continue
+			}
+			if !entry.EveroutePolicyRule.matchIPTuple(protocol, srcIP, dstIP, 0, port) {
+				continue
+			}
+			if locality := entry.EveroutePolicyRule.TrafficLocality; locality != "" && locality != datapathManager.trafficLocality(dstIP) {
+				continue
+			}
+			// the highest priority flow wins, use rule id to break a tie
+			if decided == nil || entry.EveroutePolicyRule.Priority > decided.EveroutePolicyRule.Priority ||
+				entry.EveroutePolicyRule.Priority == decided.EveroutePolicyRule.Priority && entry.EveroutePolicyRule.RuleID < decided.EveroutePolicyRule.RuleID {
+				decided = entry
+			}
+		}
+		if decided != nil {
+			return &v1alpha1.RuleDecision{
+				Direction:           uint32(direction),
+				Action:              decided.EveroutePolicyRule.Action,
+				Tier:                uint32(tier),
+				Priority:            int32(decided.EveroutePolicyRule.Priority),
+				RuleID:              decided.EveroutePolicyRule.RuleID,
+				PolicyRuleReference: policyRuleReference2RpcReference(decided.PolicyRuleReference),
+			}
+		}
+	}
+
+	// packet not matched any rule would be allowed by the last tier default flow
+	return &v1alpha1.RuleDecision{
+		Direction:           uint32(direction),
+		Action:              "allow",
+		PolicyRuleReference: []*v1alpha1.PolicyRuleReference{},
+	}
+}
+
+// trafficLocality returns intra-node if the packet to dstIP would be marked as intra node traffic by local bridge
+func (datapathManager *DpManager) trafficLocality(dstIP net.IP) string {
+	if !datapathManager.IsEnableOverlay() {
+		return ""
+	}
+	for item := range datapathManager.localEndpointDB.IterBuffered() {
+		if endpoint := item.Val.(*Endpoint); endpoint.IPAddr != nil && endpoint.IPAddr.Equal(dstIP) {
+			return TrafficLocalityIntraNode
+		}
+	}
+	return TrafficLocalityInterNode
+}
+
+// isDenyAction returns true if packets matched rule with the action would be dropped
+func isDenyAction(action string) bool {
+	return action == EveroutePolicyDeny || action == EveroutePolicyReject
+}
+
+// GetRuleConflicts returns work mode rules shadowed by a higher priority rule with conflicting action in
+// the same direction and tier, e.g. an allow rule never works because a higher priority deny rule matches
+// all the packets it matches. The conflicts are sorted by direction, tier, and the shadowed rule id.
+func (datapathManager *DpManager) GetRuleConflicts() []*v1alpha1.RuleConflict {
+	datapathManager.lockRflowReplayWithTimeout()
+	defer datapathManager.flowReplayMutex.RUnlock()
+
+	// rules are indexed by direction and tier, rules of each index are sorted by priority from high to low, so
+	// that only rules with higher priority in the same direction and tier are checked for each rule
+	type ruleIndex struct{ direction, tier uint8 }
+	type indexedRule struct {
+		entry *EveroutePolicyRuleEntry
+		match *ruleMatch
+	}
+	index := make(map[ruleIndex][]indexedRule)
+	for _, entry := range datapathManager.Rules {
+		if entry.Mode != DEFAULT_POLICY_ENFORCEMENT_MODE || datapathManager.ruleGroupDisabled(entry) {
+			continue
+		}
+		key := ruleIndex{direction: entry.Direction, tier: entry.Tier}
+		index[key] = append(index[key], indexedRule{entry: entry, match: newRuleMatch(entry.EveroutePolicyRule)})
+	}
+	keys := make([]ruleIndex, 0, len(index))
+	for key := range index {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].direction != keys[j].direction {
+			return keys[i].direction < keys[j].direction
+		}
+		return keys[i].tier < keys[j].tier
+	})
+	sortByRuleID := func(rules []indexedRule) {
+		sort.Slice(rules, func(i, j int) bool {
+			return rules[i].entry.EveroutePolicyRule.RuleID < rules[j].entry.EveroutePolicyRule.RuleID
+		})
+	}
+
+	ans := []*v1alpha1.RuleConflict{}
+	for _, key := range keys {
+		rules := index[key]
+		byPriority := make([]indexedRule, len(rules))
+		copy(byPriority, rules)
+		sort.SliceStable(byPriority, func(i, j int) bool {
+			return byPriority[i].entry.EveroutePolicyRule.Priority > byPriority[j].entry.EveroutePolicyRule.Priority
+		})
+		sortByRuleID(rules)
+
+		for _, shadowed := range rules {
+			var shadowedBy []indexedRule
+			for _, candidate := range byPriority {
+				if candidate.entry.EveroutePolicyRule.Priority <= shadowed.entry.EveroutePolicyRule.Priority {
+					break
+				}
+				if isDenyAction(shadowed.entry.EveroutePolicyRule.Action) == isDenyAction(candidate.entry.EveroutePolicyRule.Action) {
+					continue
+				}
+				if shadowed.match.coveredBy(candidate.match) {
+					shadowedBy = append(shadowedBy, candidate)
+				}
+			}
+			sortByRuleID(shadowedBy)
+			for _, candidate := range shadowedBy {
+				ans = append(ans, &v1alpha1.RuleConflict{
+					Shadowed:   datapathRule2RpcRule(shadowed.entry, ""),
+					ShadowedBy: datapathRule2RpcRule(candidate.entry, ""),
+				})
+			}
+		}
+	}
+	return ans
+}
+
+// GetEffectiveRules returns rules apply to the local endpoint with the ip on the vlan, rules are scoped to the vds
+// the endpoint attached to. The rules are ordered by direction, then by tier as packet walked through, then by
+// priority from high to low.
+func (datapathManager *DpManager) GetEffectiveRules(ip net.IP, vlanID uint16) ([]*v1alpha1.RuleEntry, error) {
+	datapathManager.lockRflowReplayWithTimeout()
+	defer datapathManager.flowReplayMutex.RUnlock()
+
+	vdsID, ok := datapathManager.getLocalEndpointVDS(ip, vlanID)
+	if !ok {
+		return nil, fmt.Errorf("local endpoint with ip %s on vlan %d not found", ip, vlanID)
+	}
+
+	var entries []*EveroutePolicyRuleEntry
+	for _, entry := range datapathManager.Rules {
+		if _, ok := entry.RuleFlowMap[vdsID]; !ok {
+			continue
+		}
+		endpointIPAddr := ruleEndpointIPAddr(entry.EveroutePolicyRule, entry.Direction)
+		if endpointIPAddr != "" && !matchIP(endpointIPAddr, ip) {
+			continue
+		}
+		if ruleVlanID := entry.EveroutePolicyRule.VlanID; ruleVlanID != 0 && ruleVlanID != vlanID {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	tierOrder := datapathManager.policyTiers().order()
+	tierIndex := make(map[uint8]int, len(tierOrder))
+	for index, tier := range tierOrder {
+		tierIndex[tier] = index
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		ri, rj := entries[i], entries[j]
+		if ri.Direction != rj.Direction {
+			return ri.Direction < rj.Direction
+		}
+		if tierIndex[ri.Tier] != tierIndex[rj.Tier] {
+			return tierIndex[ri.Tier] < tierIndex[rj.Tier]
+		}
+		if ri.EveroutePolicyRule.Priority != rj.EveroutePolicyRule.Priority {
+			return ri.EveroutePolicyRule.Priority > rj.EveroutePolicyRule.Priority
+		}
+		return ri.EveroutePolicyRule.RuleID < rj.EveroutePolicyRule.RuleID
+	})
+
+	ans := make([]*v1alpha1.RuleEntry, 0, len(entries))
+	for _, entry := range entries {
+		ans = append(ans, datapathRule2RpcRule(entry, vdsID))
+	}
+	return ans, nil
+}
+
+// GetEndpoints returns local endpoints with the learned ip addresses, endpoints are sorted by interface uuid
+func (datapathManager *DpManager) GetEndpoints() []*v1alpha1.EndpointEntry {
+	ans := []*v1alpha1.EndpointEntry{}
+	for item := range datapathManager.localEndpointDB.IterBuffered() {
+		endpoint := item.Val.(*Endpoint)
+		entry := &v1alpha1.EndpointEntry{
+			InterfaceUUID: endpoint.InterfaceUUID,
+			InterfaceName: endpoint.InterfaceName,
+			MacAddr:       endpoint.MacAddrStr,
+			VlanID:        uint32(endpoint.VlanID),
+			OfPort:        endpoint.PortNo,
+			BridgeName:    endpoint.BridgeName,
+		}
+		endpoint.IPAddrMutex.RLock()
+		if endpoint.IPAddr != nil {
+			entry.IPAddr = endpoint.IPAddr.String()
+		}
+		if endpoint.IPv6Addr != nil {
+			entry.IPv6Addr = endpoint.IPv6Addr.String()
+		}
+		if !endpoint.IPAddrLastUpdateTime.IsZero() {
+			entry.IPAddrLastUpdateTime = endpoint.IPAddrLastUpdateTime.Unix()
+		}
+		endpoint.IPAddrMutex.RUnlock()
+		ans = append(ans, entry)
 	}
+	sort.Slice(ans, func(i, j int) bool {
+		return ans[i].InterfaceUUID < ans[j].InterfaceUUID
+	})
 	return ans
 }
 
+// GetLocalEndpointIPsByIfaceName returns ip cidrs of local endpoints which interface name matches the pattern,
+// the pattern follows shell file name pattern, e.g. veth*.
+func (datapathManager *DpManager) GetLocalEndpointIPsByIfaceName(pattern string) []string {
+	return datapathManager.getLocalEndpointIPs(func(endpoint *Endpoint) bool {
+		matched, _ := path.Match(pattern, endpoint.InterfaceName)
+		return matched
+	})
+}
+
+// GetLocalEndpointIPs returns ip cidrs of all local endpoints
+func (datapathManager *DpManager) GetLocalEndpointIPs() []string {
+	return datapathManager.getLocalEndpointIPs(func(*Endpoint) bool { return true })
+}
+
+// GetLocalEndpointIPsByVlanRange returns ip cidrs of local endpoints which vlan id in the closed range [start, end]
+func (datapathManager *DpManager) GetLocalEndpointIPsByVlanRange(start, end uint16) []string {
+	return datapathManager.getLocalEndpointIPs(func(endpoint *Endpoint) bool {
+		return endpoint.VlanID >= start && endpoint.VlanID <= end
+	})
+}
+
+func (datapathManager *DpManager) getLocalEndpointIPs(match func(endpoint *Endpoint) bool) []string {
+	ips := sets.New[string]()
+	for item := range datapathManager.localEndpointDB.IterBuffered() {
+		endpoint := item.Val.(*Endpoint)
+		if !match(endpoint) {
+			continue
+		}
+		endpoint.IPAddrMutex.RLock()
+		if endpoint.IPAddr != nil && endpoint.IPAddr.To4() != nil {
+			ips.Insert((&net.IPNet{IP: endpoint.IPAddr.To4(), Mask: net.CIDRMask(32, 32)}).String())
+		}
+		if endpoint.IPv6Addr != nil {
+			ips.Insert((&net.IPNet{IP: endpoint.IPv6Addr, Mask: net.CIDRMask(128, 128)}).String())
+		}
+		endpoint.IPAddrMutex.RUnlock()
+	}
+	return sets.List(ips)
+}
+
+// getLocalEndpointVDS returns the vds which the local endpoint with the ip on the vlan attached to
+func (datapathManager *DpManager) getLocalEndpointVDS(ip net.IP, vlanID uint16) (string, bool) {
+	for item := range datapathManager.localEndpointDB.IterBuffered() {
+		endpoint := item.Val.(*Endpoint)
+		if endpoint.VlanID != vlanID {
+			continue
+		}
+		endpoint.IPAddrMutex.RLock()
+		matched := endpoint.IPAddr.Equal(ip) || endpoint.IPv6Addr.Equal(ip)
+		endpoint.IPAddrMutex.RUnlock()
+		if !matched {
+			continue
+		}
+		for vdsID, ovsbrname := range datapathManager.Config.ManagedVDSMap {
+			if ovsbrname == endpoint.BridgeName {
+				return vdsID, true
+			}
+		}
+	}
+	return "", false
+}
+
 func (datapathManager *DpManager) InitializeCNI() {
 	var wg sync.WaitGroup
 	for vdsID := range datapathManager.Config.ManagedVDSMap {
@@ -726,15 +1332,20 @@ func NewVDSForConfigBase(datapathManager *DpManager, vdsID, ovsbrname string) {
 }
 
 func InitializeVDS(datapathManager *DpManager, vdsID string, ovsbrName string, stopChan <-chan struct{}) {
-	roundInfo, err := getRoundInfo(datapathManager.OvsdbDriverMap[vdsID][LOCAL_BRIDGE_KEYWORD])
+	flowCookie := datapathManager.flowCookie()
+	roundInfo, err := getRoundInfo(datapathManager.OvsdbDriverMap[vdsID][LOCAL_BRIDGE_KEYWORD], flowCookie)
 	if err != nil {
 		log.Fatalf("Failed to get Roundinfo from ovsdb: %v", err)
 	}
 
-	cookieAllocator := cookie.NewAllocator(roundInfo.curRoundNum)
+	cookieAllocator := flowCookie.newCookieAllocator(roundInfo.curRoundNum)
+	datapathManager.setCookieAllocator(vdsID, cookieAllocator)
+	if err := datapathManager.loadDisabledRuleGroups(vdsID); err != nil {
+		log.Fatalf("Failed to load disabled rule groups from ovsdb: %v", err)
+	}
 	for brKeyword := range datapathManager.BridgeChainMap[vdsID] {
 		// Delete flow with curRoundNum cookie, for case: failed when restart process flow install.
-		datapathManager.BridgeChainMap[vdsID][brKeyword].getOfSwitch().DeleteFlowByRoundInfo(roundInfo.curRoundNum)
+		datapathManager.BridgeChainMap[vdsID][brKeyword].getOfSwitch().DeleteFlowByCookie(flowCookie.roundCookieWithMask(roundInfo.curRoundNum))
 		// update cookie
 		datapathManager.BridgeChainMap[vdsID][brKeyword].getOfSwitch().CookieAllocator = cookieAllocator
 		// bridge init
@@ -760,23 +1371,52 @@ func InitializeVDS(datapathManager *DpManager, vdsID string, ovsbrName string, s
 		}
 	}
 
-	// Delete flow with previousRoundNum cookie, and then persistent curRoundNum to ovsdb. We need to wait for long
-	// enough to guarantee that all of the basic flow which we are still required updated with new roundInfo encoding to
-	// flow cookie fields. But the time required to update all of the basic flow with updated roundInfo is
-	// non-determined.
-	// TODO  Implement a deterministic mechanism to control outdated flow flush procedure
-	go func(vdsID string) {
-		time.Sleep(time.Second * 15)
-
+	// Delete flow with previousRoundNum cookie, and then persistent curRoundNum to ovsdb. We need to wait until all of
+	// the basic flow and rule flow which we are still required updated with new roundInfo encoding to flow cookie
+	// fields, so flush only after policy rules synced and the number of curRoundNum flows on bridges of the vds converged.
+	countFlows := func() (int, error) {
+		var total int
+		for brKeyword := range datapathManager.BridgeChainMap[vdsID] {
+			count, err := countRoundFlows(datapathManager.BridgeChainMap[vdsID][brKeyword].GetName(), flowCookie, roundInfo.curRoundNum)
+			if err != nil {
+				return 0, err
+			}
+			total += count
+		}
+		return total, nil
+	}
+	flush := func() {
 		for brKeyword := range datapathManager.BridgeChainMap[vdsID] {
-			datapathManager.BridgeChainMap[vdsID][brKeyword].getOfSwitch().DeleteFlowByRoundInfo(roundInfo.previousRoundNum)
+			datapathManager.BridgeChainMap[vdsID][brKeyword].getOfSwitch().DeleteFlowByCookie(roundInfo.previousFlowCookie.roundCookieWithMask(roundInfo.previousRoundNum))
 		}
 
-		err := persistentRoundInfo(roundInfo.curRoundNum, datapathManager.OvsdbDriverMap[vdsID][LOCAL_BRIDGE_KEYWORD])
+		err := persistentRoundInfo(roundInfo.curRoundNum, datapathManager.OvsdbDriverMap[vdsID][LOCAL_BRIDGE_KEYWORD], flowCookie)
 		if err != nil {
 			log.Fatalf("Failed to persistent roundInfo into ovsdb: %v", err)
 		}
-	}(vdsID)
+		log.Infof("Flushed flows of round %d on vds %s", roundInfo.previousRoundNum, vdsID)
+	}
+	go flushAfterFlowConverged(countFlows, flush, datapathManager.policyRulesSynced, RoundFlowConvergeInterval,
+		RoundFlowConvergeStableTimes, RoundFlowFlushMinDelay, RoundFlowConvergeTimeout, stopChan)
+}
+
+func (datapathManager *DpManager) setCookieAllocator(vdsID string, allocator *flowCookieAllocator) {
+	datapathManager.cookieAllocatorLock.Lock()
+	defer datapathManager.cookieAllocatorLock.Unlock()
+	datapathManager.cookieAllocators[vdsID] = allocator
+}
+
+// cookieAllocatorOfRound returns the flow cookie allocator of the vds in the round, the allocator in use is
+// returned if it's of the same round, it continues the flow sequence rather than restarting from the first.
+func (datapathManager *DpManager) cookieAllocatorOfRound(vdsID string, roundNum uint64) *flowCookieAllocator {
+	datapathManager.cookieAllocatorLock.Lock()
+	defer datapathManager.cookieAllocatorLock.Unlock()
+	if allocator := datapathManager.cookieAllocators[vdsID]; allocator != nil && allocator.roundNum == roundNum {
+		return allocator
+	}
+	allocator := datapathManager.flowCookie().newCookieAllocator(roundNum)
+	datapathManager.cookieAllocators[vdsID] = allocator
+	return allocator
 }
 
 func (datapathManager *DpManager) replayVDSFlow(vdsID, bridgeName, bridgeKeyword string) error {
@@ -789,17 +1429,18 @@ func (datapathManager *DpManager) replayVDSFlow(vdsID, bridgeName, bridgeKeyword
 	}
 
 	// replay basic connectivity flow
-	roundInfo, err := getRoundInfo(datapathManager.OvsdbDriverMap[vdsID][LOCAL_BRIDGE_KEYWORD])
+	flowCookie := datapathManager.flowCookie()
+	roundInfo, err := getRoundInfo(datapathManager.OvsdbDriverMap[vdsID][LOCAL_BRIDGE_KEYWORD], flowCookie)
 	if err != nil {
 		return fmt.Errorf("failed to get Roundinfo from ovsdb: %v", err)
 	}
-	cookieAllocator := cookie.NewAllocator(roundInfo.curRoundNum)
+	cookieAllocator := datapathManager.cookieAllocatorOfRound(vdsID, roundInfo.curRoundNum)
 	datapathManager.BridgeChainMap[vdsID][bridgeKeyword].getOfSwitch().CookieAllocator = cookieAllocator
 	datapathManager.BridgeChainMap[vdsID][bridgeKeyword].BridgeInit()
 	datapathManager.BridgeChainMap[vdsID][bridgeKeyword].BridgeInitCNI()
 
 	// replay local endpoint flow
-	if bridgeKeyword == LOCAL_BRIDGE_KEYWORD || bridgeKeyword == NAT_BRIDGE_KEYWORD ||
+	if bridgeKeyword == LOCAL_BRIDGE_KEYWORD || bridgeKeyword == NAT_BRIDGE_KEYWORD || bridgeKeyword == POLICY_BRIDGE_KEYWORD ||
 		(datapathManager.IsEnableOverlay() && bridgeKeyword == UPLINK_BRIDGE_KEYWORD) {
 		if err := datapathManager.ReplayVDSLocalEndpointFlow(vdsID, bridgeKeyword); err != nil {
 			return fmt.Errorf("failed to replay local endpoint flow while vswitchd restart, error: %v", err)
@@ -863,78 +1504,314 @@ func (datapathManager *DpManager) ReplayVDSLocalEndpointFlow(vdsID string, keyWo
 	return nil
 }
 
-func (datapathManager *DpManager) ReplayVDSMicroSegmentFlow(vdsID string) error {
-	for ruleID, erPolicyRuleEntry := range datapathManager.Rules {
-		// Add new policy rule flow to datapath
-		flowEntry, err := datapathManager.BridgeChainMap[vdsID][POLICY_BRIDGE_KEYWORD].AddMicroSegmentRule(erPolicyRuleEntry.EveroutePolicyRule,
-			erPolicyRuleEntry.Direction, erPolicyRuleEntry.Tier, erPolicyRuleEntry.Mode)
-		if err != nil {
-			return fmt.Errorf("failed to add microsegment rule to vdsID %v, bridge %s, error: %v", vdsID, datapathManager.BridgeChainMap[vdsID][POLICY_BRIDGE_KEYWORD], err)
+// ReplayVDSMicroSegmentFlow replays rule flows on the policy bridge of the vds after it reconnected. Rule flows
+// still installed in ovs are kept, only the absent ones would be reinstalled and their conntrack cleaned. All
+// rule flows would be rebuilt and conntrack flushed when ovs reports no rule flows, e.g. ovs restarted.
+func (datapathManager *DpManager) ReplayVDSMicroSegmentFlow(vdsID string) (err error) {
+	policyBridge := datapathManager.BridgeChainMap[vdsID][POLICY_BRIDGE_KEYWORD]
+
+	var installedFlowIDs sets.Set[uint64]
+	if reader, ok := policyBridge.(ruleFlowReader); ok {
+		var readErr error
+		installedFlowIDs, readErr = reader.ReadRuleTableFlowIDs()
+		if readErr != nil {
+			log.Errorf("Failed to read rule flows of vds %s, rebuild all rule flows: %v", vdsID, readErr)
 		}
-		// udpate new policy rule flow to datapath flow cache
-		datapathManager.Rules[ruleID].RuleFlowMap[vdsID] = flowEntry
+	}
+	total := len(datapathManager.Rules)
+	datapathManager.updateReplayProgress(vdsID, 0, total)
+	defer func() { datapathManager.finishReplayProgress(vdsID, err) }()
+	if installedFlowIDs.Len() == 0 {
+		return datapathManager.rebuildVDSMicroSegmentFlow(vdsID)
+	}
 
-		// update new flowID to policy entry map
-		datapathManager.FlowIDToRules[flowEntry.FlowID] = erPolicyRuleEntry
+	var replayed int
+	var cleanRules EveroutePolicyRuleList
+	for ruleID, erPolicyRuleEntry := range datapathManager.Rules {
+		flowEntry := erPolicyRuleEntry.RuleFlowMap[vdsID]
+		switch {
+		case datapathManager.ruleGroupDisabled(erPolicyRuleEntry):
+		case flowEntry != nil && installedFlowIDs.Has(flowEntry.FlowID):
+			// the flow is kept in ovs, rebind it to the reconnected switch
+			erPolicyRuleEntry.RuleFlowMap[vdsID] = &FlowEntry{
+				Table:    &ofctrl.Table{Switch: policyBridge.getOfSwitch(), TableId: flowEntry.Table.TableId},
+				Priority: flowEntry.Priority,
+				FlowID:   flowEntry.FlowID,
+			}
+		default:
+			if err := datapathManager.replayRuleFlow(vdsID, ruleID, erPolicyRuleEntry); err != nil {
+				return err
+			}
+			if !skipConntrackClean(erPolicyRuleEntry.Mode) {
+				cleanRules = append(cleanRules, datapathManager.conntrackCleanRule(erPolicyRuleEntry.EveroutePolicyRule, erPolicyRuleEntry.Direction))
+			}
+		}
+		replayed++
+		datapathManager.updateReplayProgress(vdsID, replayed, total)
 	}
-	// TODO: clear except table if we support helpers
-	netlink.ConntrackTableFlush(netlink.ConntrackTable)
+	datapathManager.cleanConntrackFlows(cleanRules)
+	log.Infof("Replayed %d of %d rules on vds %s", len(cleanRules), len(datapathManager.Rules), vdsID)
 
 	return nil
 }
 
-func (datapathManager *DpManager) ReplayEverouteIPAMFlow(vdsID string, brKey string) error {
-	if brKey == LOCAL_BRIDGE_KEYWORD {
-		// replay icmp reply flow
-		gws := datapathManager.ippoolGWs.UnsortedList()
-		for _, gw := range gws {
-			if err := datapathManager.BridgeChainMap[vdsID][brKey].AddIPPoolGW(gw); err != nil {
-				return err
+// ReconcileRuleFlows reads back rule table flows of policy bridges, and reinstalls rules whose flows are absent
+// in ovs, it recovers flows dropped by ovs silently without a bridge disconnect. Bridges disconnected or reporting
+// no rule flows are skipped, they would be replayed on reconnect.
+//
+// The expected flow ids are snapshotted under the read lock, a barrier is sent to ovs so that flows sent before
+// are visible in the dump, and the dump is diffed by cookie without holding the lock. Only the rules whose flows
+// are absent are reinstalled under the write lock, if their flows haven't been changed since the snapshot.
+func (datapathManager *DpManager) ReconcileRuleFlows() error {
+	expectedFlows := datapathManager.expectedRuleFlows()
+
+	var errList []error
+	absentFlows := make(map[string]map[uint64]string, len(expectedFlows))
+	for _, vdsID := range sets.StringKeySet(expectedFlows).List() {
+		policyBridge := datapathManager.BridgeChainMap[vdsID][POLICY_BRIDGE_KEYWORD]
+		reader := policyBridge.(ruleFlowReader)
+		if err := reader.barrier(RuleFlowBarrierTimeout); err != nil {
+			errList = append(errList, fmt.Errorf("failed to wait barrier of vds %s: %v", vdsID, err))
+			continue
+		}
+		installedFlowIDs, err := reader.ReadRuleTableFlowIDs()
+		if err != nil {
+			errList = append(errList, fmt.Errorf("failed to read rule flows of vds %s: %v", vdsID, err))
+			continue
+		}
+		if installedFlowIDs.Len() == 0 {
+			continue
+		}
+		for flowID, ruleID := range expectedFlows[vdsID] {
+			if !installedFlowIDs.Has(flowID) {
+				if absentFlows[vdsID] == nil {
+					absentFlows[vdsID] = make(map[uint64]string)
+				}
+				absentFlows[vdsID][flowID] = ruleID
 			}
 		}
 	}
+	if len(absentFlows) == 0 {
+		return uerr.NewAggregate(errList)
+	}
 
-	if brKey == UPLINK_BRIDGE_KEYWORD || brKey == LOCAL_BRIDGE_KEYWORD {
-		// replay arp and ip reply flow
-		subnets := datapathManager.ippoolSubnets.UnsortedList()
-		for _, subnet := range subnets {
-			if err := datapathManager.BridgeChainMap[vdsID][brKey].AddIPPoolSubnet(subnet); err != nil {
-				return err
+	datapathManager.lockflowReplayWithTimeout()
+	defer datapathManager.flowReplayMutex.Unlock()
+
+	var cleanRules EveroutePolicyRuleList
+	for _, vdsID := range sets.StringKeySet(absentFlows).List() {
+		if !datapathManager.BridgeChainMap[vdsID][POLICY_BRIDGE_KEYWORD].IsSwitchConnected() {
+			continue
+		}
+		var reinstalled int
+		for flowID, ruleID := range absentFlows[vdsID] {
+			erPolicyRuleEntry := datapathManager.Rules[ruleID]
+			if erPolicyRuleEntry == nil || datapathManager.ruleGroupDisabled(erPolicyRuleEntry) {
+				continue
+			}
+			// the rule flow has been replaced or removed since the snapshot
+			if flowEntry := erPolicyRuleEntry.RuleFlowMap[vdsID]; flowEntry == nil || flowEntry.FlowID != flowID {
+				continue
 			}
+			log.Warnf("Flow %#x of rule %s absent on vds %s, reinstall it", flowID, ruleID, vdsID)
+			if err := datapathManager.replayRuleFlow(vdsID, ruleID, erPolicyRuleEntry); err != nil {
+				errList = append(errList, err)
+				continue
+			}
+			delete(datapathManager.FlowIDToRules, flowID)
+			if !skipConntrackClean(erPolicyRuleEntry.Mode) {
+				cleanRules = append(cleanRules, datapathManager.conntrackCleanRule(erPolicyRuleEntry.EveroutePolicyRule, erPolicyRuleEntry.Direction))
+			}
+			reinstalled++
+		}
+		if reinstalled != 0 {
+			datapathManager.AgentMetric.AddRuleFlowReinstalled(vdsID, reinstalled)
+			log.Infof("Reinstalled %d absent rule flows on vds %s", reinstalled, vdsID)
 		}
 	}
+	datapathManager.cleanConntrackFlows(cleanRules)
 
-	return nil
+	return uerr.NewAggregate(errList)
 }
 
-func (datapathManager *DpManager) AddIPPoolSubnet(subnet string) error {
-	datapathManager.lockflowReplayWithTimeout()
-	defer datapathManager.flowReplayMutex.Unlock()
-	if datapathManager.ippoolSubnets.Has(subnet) {
-		return nil
-	}
+// expectedRuleFlows returns the flow ids of enabled rules mapped to the rule id on each connected policy bridge
+// which could read back rule flows
+func (datapathManager *DpManager) expectedRuleFlows() map[string]map[uint64]string {
+	datapathManager.lockRflowReplayWithTimeout()
+	defer datapathManager.flowReplayMutex.RUnlock()
 
-	for vdsID := range datapathManager.BridgeChainMap {
-		if err := datapathManager.BridgeChainMap[vdsID][UPLINK_BRIDGE_KEYWORD].AddIPPoolSubnet(subnet); err != nil {
-			klog.Errorf("Failed to add IPPool subnet %s flow in uplink bridge: %v", subnet, err)
-			return err
+	expectedFlows := make(map[string]map[uint64]string, len(datapathManager.BridgeChainMap))
+	for vdsID, bridgeChain := range datapathManager.BridgeChainMap {
+		policyBridge := bridgeChain[POLICY_BRIDGE_KEYWORD]
+		if _, ok := policyBridge.(ruleFlowReader); !ok || !policyBridge.IsSwitchConnected() {
+			continue
 		}
-		if err := datapathManager.BridgeChainMap[vdsID][LOCAL_BRIDGE_KEYWORD].AddIPPoolSubnet(subnet); err != nil {
-			klog.Errorf("Failed to add IPPool subnet %s flow in local bridge: %v", subnet, err)
-			return err
+		flows := make(map[uint64]string, len(datapathManager.Rules))
+		for ruleID, erPolicyRuleEntry := range datapathManager.Rules {
+			flowEntry := erPolicyRuleEntry.RuleFlowMap[vdsID]
+			if flowEntry != nil && !datapathManager.ruleGroupDisabled(erPolicyRuleEntry) {
+				flows[flowEntry.FlowID] = ruleID
+			}
 		}
+		expectedFlows[vdsID] = flows
 	}
-
-	datapathManager.ippoolSubnets.Insert(subnet)
-	return nil
+	return expectedFlows
 }
 
-func (datapathManager *DpManager) DelIPPoolSubnet(subnet string) error {
-	datapathManager.lockflowReplayWithTimeout()
-	defer datapathManager.flowReplayMutex.Unlock()
-	if !datapathManager.ippoolSubnets.Has(subnet) {
-		return nil
-	}
+// rebuildVDSMicroSegmentFlow reinstalls all rule flows on the policy bridge of the vds and flushes conntrack
+func (datapathManager *DpManager) rebuildVDSMicroSegmentFlow(vdsID string) error {
+	var replayed int
+	for ruleID, erPolicyRuleEntry := range datapathManager.Rules {
+		if !datapathManager.ruleGroupDisabled(erPolicyRuleEntry) {
+			if err := datapathManager.replayRuleFlow(vdsID, ruleID, erPolicyRuleEntry); err != nil {
+				return err
+			}
+		}
+		replayed++
+		datapathManager.updateReplayProgress(vdsID, replayed, len(datapathManager.Rules))
+	}
+	// TODO: clear except table if we support helpers
+	if err := datapathManager.ctFlushFunc(); err != nil {
+		log.Errorf("Failed to flush conntrack after rebuild rule flows of vds %s: %v", vdsID, err)
+	}
+
+	return nil
+}
+
+// updateReplayProgress records replayed rules of total rules on the vds, a new replay starts when replayed is 0.
+// The progress is logged at every ReplayProgressCheckpoint rules.
+func (datapathManager *DpManager) updateReplayProgress(vdsID string, replayed, total int) {
+	datapathManager.replayProgressLock.Lock()
+	progress := datapathManager.replayProgress[vdsID]
+	if progress == nil || replayed == 0 {
+		progress = &ReplayProgress{StartTime: time.Now()}
+		datapathManager.replayProgress[vdsID] = progress
+	}
+	progress.Replayed, progress.Total = replayed, total
+	datapathManager.replayProgressLock.Unlock()
+
+	datapathManager.AgentMetric.SetRuleReplayProgress(vdsID, replayed, total)
+	if replayed%ReplayProgressCheckpoint == 0 {
+		log.Infof("Replay progress of rules on vds %s: %d/%d", vdsID, replayed, total)
+	}
+}
+
+// finishReplayProgress marks the replay on the vds finished, with the error if the replay failed
+func (datapathManager *DpManager) finishReplayProgress(vdsID string, err error) {
+	datapathManager.replayProgressLock.Lock()
+	progress := datapathManager.replayProgress[vdsID]
+	progress.FinishTime, progress.Error = time.Now(), err
+	replayed, total := progress.Replayed, progress.Total
+	datapathManager.replayProgressLock.Unlock()
+
+	if err != nil {
+		log.Errorf("Replay of rules on vds %s failed at %d/%d: %v", vdsID, replayed, total, err)
+		return
+	}
+	log.Infof("Replay of rules on vds %s finished: %d/%d", vdsID, replayed, total)
+}
+
+// GetReplayStatus returns the latest rule flows replay progress of each vds, it doesn't wait for the replay
+func (datapathManager *DpManager) GetReplayStatus() []*v1alpha1.ReplayStatus {
+	datapathManager.replayProgressLock.RLock()
+	defer datapathManager.replayProgressLock.RUnlock()
+
+	ans := []*v1alpha1.ReplayStatus{}
+	for _, vdsID := range sets.StringKeySet(datapathManager.replayProgress).List() {
+		progress := datapathManager.replayProgress[vdsID]
+		status := &v1alpha1.ReplayStatus{
+			VdsID:         vdsID,
+			ReplayedRules: uint32(progress.Replayed),
+			TotalRules:    uint32(progress.Total),
+			InProgress:    progress.FinishTime.IsZero(),
+			StartTime:     progress.StartTime.Unix(),
+		}
+		if !progress.FinishTime.IsZero() {
+			status.FinishTime = progress.FinishTime.Unix()
+		}
+		if progress.Error != nil {
+			status.Error = progress.Error.Error()
+		}
+		ans = append(ans, status)
+	}
+	return ans
+}
+
+func (datapathManager *DpManager) replayRuleFlow(vdsID, ruleID string, erPolicyRuleEntry *EveroutePolicyRuleEntry) error {
+	// Add new policy rule flow to datapath
+	flowEntry, err := datapathManager.BridgeChainMap[vdsID][POLICY_BRIDGE_KEYWORD].AddMicroSegmentRule(erPolicyRuleEntry.EveroutePolicyRule,
+		erPolicyRuleEntry.Direction, erPolicyRuleEntry.Tier, erPolicyRuleEntry.Mode)
+	if err != nil {
+		return fmt.Errorf("failed to add microsegment rule to vdsID %v, bridge %s, error: %v", vdsID, datapathManager.BridgeChainMap[vdsID][POLICY_BRIDGE_KEYWORD], err)
+	}
+	// udpate new policy rule flow to datapath flow cache
+	oldFlowEntry := erPolicyRuleEntry.RuleFlowMap[vdsID]
+	erPolicyRuleEntry.RuleFlowMap[vdsID] = flowEntry
+
+	// update new flowID to policy entry map
+	datapathManager.FlowIDToRules[flowEntry.FlowID] = erPolicyRuleEntry
+
+	datapathManager.AgentMetric.AddRuleFlow(ruleID, erPolicyRuleEntry.EveroutePolicyRule.Action,
+		erPolicyRuleEntry.LoggingTags[constants.LoggingTagPolicyType], flowEntry.FlowID)
+	if oldFlowEntry != nil && oldFlowEntry.FlowID != flowEntry.FlowID {
+		datapathManager.AgentMetric.RemoveRuleFlow(oldFlowEntry.FlowID)
+	}
+
+	return nil
+}
+
+func (datapathManager *DpManager) ReplayEverouteIPAMFlow(vdsID string, brKey string) error {
+	if brKey == LOCAL_BRIDGE_KEYWORD {
+		// replay icmp reply flow
+		gws := datapathManager.ippoolGWs.UnsortedList()
+		for _, gw := range gws {
+			if err := datapathManager.BridgeChainMap[vdsID][brKey].AddIPPoolGW(gw); err != nil {
+				return err
+			}
+		}
+	}
+
+	if brKey == UPLINK_BRIDGE_KEYWORD || brKey == LOCAL_BRIDGE_KEYWORD {
+		// replay arp and ip reply flow
+		subnets := datapathManager.ippoolSubnets.UnsortedList()
+		for _, subnet := range subnets {
+			if err := datapathManager.BridgeChainMap[vdsID][brKey].AddIPPoolSubnet(subnet); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (datapathManager *DpManager) AddIPPoolSubnet(subnet string) error {
+	datapathManager.lockflowReplayWithTimeout()
+	defer datapathManager.flowReplayMutex.Unlock()
+	if datapathManager.ippoolSubnets.Has(subnet) {
+		return nil
+	}
+
+	for vdsID := range datapathManager.BridgeChainMap {
+		if err := datapathManager.BridgeChainMap[vdsID][UPLINK_BRIDGE_KEYWORD].AddIPPoolSubnet(subnet); err != nil {
+			klog.Errorf("Failed to add IPPool subnet %s flow in uplink bridge: %v", subnet, err)
+			return err
+		}
+		if err := datapathManager.BridgeChainMap[vdsID][LOCAL_BRIDGE_KEYWORD].AddIPPoolSubnet(subnet); err != nil {
+			klog.Errorf("Failed to add IPPool subnet %s flow in local bridge: %v", subnet, err)
+			return err
+		}
+	}
+
+	datapathManager.ippoolSubnets.Insert(subnet)
+	return nil
+}
+
+func (datapathManager *DpManager) DelIPPoolSubnet(subnet string) error {
+	datapathManager.lockflowReplayWithTimeout()
+	defer datapathManager.flowReplayMutex.Unlock()
+	if !datapathManager.ippoolSubnets.Has(subnet) {
+		return nil
+	}
 
 	for vdsID := range datapathManager.BridgeChainMap {
 		if err := datapathManager.BridgeChainMap[vdsID][UPLINK_BRIDGE_KEYWORD].DelIPPoolSubnet(subnet); err != nil {
@@ -1034,6 +1911,7 @@ func (datapathManager *DpManager) skipLocalEndpoint(endpoint *Endpoint) bool {
 }
 
 func (datapathManager *DpManager) AddLocalEndpoint(endpoint *Endpoint) error {
+	defer datapathManager.syncEndpointRuleMetrics()
 	datapathManager.lockflowReplayWithTimeout()
 	defer datapathManager.flowReplayMutex.Unlock()
 	if !datapathManager.IsBridgesConnected() {
@@ -1056,20 +1934,23 @@ func (datapathManager *DpManager) AddLocalEndpoint(endpoint *Endpoint) error {
 			// if it's failed to add endpoint flow, replayVDSFlow routine would rebuild local endpoint flow according to
 			// current localEndpointDB
 			datapathManager.localEndpointDB.Set(endpoint.InterfaceUUID, endpoint)
+			datapathManager.refreshEndpointRuleMetrics(endpoint)
 			for kword := range datapathManager.BridgeChainMap[vdsID] {
 				br := datapathManager.BridgeChainMap[vdsID][kword]
 				if err := br.AddLocalEndpoint(endpoint); err != nil {
 					return fmt.Errorf("failed to add local endpoint %s to vds %v, bridge %v, error: %v", endpoint.InterfaceUUID, vdsID, br.GetName(), err)
 				}
 			}
-			break
+			return nil
 		}
 	}
 
-	return nil
+	datapathManager.AgentMetric.IncUnmanagedBridgeEndpoint(endpoint.BridgeName)
+	return &UnmanagedBridgeError{InterfaceUUID: endpoint.InterfaceUUID, BridgeName: endpoint.BridgeName}
 }
 
 func (datapathManager *DpManager) UpdateLocalEndpoint(newEndpoint, oldEndpoint *Endpoint) error {
+	defer datapathManager.syncEndpointRuleMetrics()
 	datapathManager.lockflowReplayWithTimeout()
 	defer datapathManager.flowReplayMutex.Unlock()
 	if !datapathManager.IsBridgesConnected() {
@@ -1080,6 +1961,16 @@ func (datapathManager *DpManager) UpdateLocalEndpoint(newEndpoint, oldEndpoint *
 	for vdsID, ovsbrname := range datapathManager.Config.ManagedVDSMap {
 		if ovsbrname == newEndpoint.BridgeName {
 			oldEP, _ := datapathManager.localEndpointDB.Get(oldEndpoint.InterfaceUUID)
+			if migratedEP, ok := datapathManager.migratedEndpoints.Get(oldEndpoint.InterfaceUUID); oldEP == nil && ok {
+				// keep the latest endpoint, it would be added back when migrated back to the local agent
+				if datapathManager.Config.EnableIPLearning {
+					newEndpoint.IPAddr = utils.IPCopy(migratedEP.(*Endpoint).IPAddr)
+				}
+				datapathManager.migratedEndpoints.Remove(oldEndpoint.InterfaceUUID)
+				datapathManager.migratedEndpoints.Set(newEndpoint.InterfaceUUID, newEndpoint)
+				log.Infof("Skip update flows of local endpoint %s which has been migrated to other agents", oldEndpoint.InterfaceUUID)
+				return nil
+			}
 			if oldEP == nil {
 				return fmt.Errorf("old local endpoint: %v not found", oldEP)
 			}
@@ -1091,6 +1982,7 @@ func (datapathManager *DpManager) UpdateLocalEndpoint(newEndpoint, oldEndpoint *
 
 			// assume that ofport does not update, so doesn't need to remove old flow for local bridge overlay
 			datapathManager.localEndpointDB.Remove(oldEndpoint.InterfaceUUID)
+			datapathManager.refreshEndpointRuleMetrics(ep)
 			if !datapathManager.IsEnableOverlay() {
 				err = datapathManager.BridgeChainMap[vdsID][LOCAL_BRIDGE_KEYWORD].RemoveLocalEndpoint(oldEndpoint)
 				if err != nil {
@@ -1105,6 +1997,7 @@ func (datapathManager *DpManager) UpdateLocalEndpoint(newEndpoint, oldEndpoint *
 				return fmt.Errorf("new local endpoint: %v already exits", newEP)
 			}
 			datapathManager.localEndpointDB.Set(newEndpoint.InterfaceUUID, newEndpoint)
+			datapathManager.refreshEndpointRuleMetrics(newEndpoint)
 			for kword := range datapathManager.BridgeChainMap[vdsID] {
 				br := datapathManager.BridgeChainMap[vdsID][kword]
 				// for cni, endpoint ipaddr may update from null, so try to add endpoint
@@ -1121,12 +2014,18 @@ func (datapathManager *DpManager) UpdateLocalEndpoint(newEndpoint, oldEndpoint *
 }
 
 func (datapathManager *DpManager) RemoveLocalEndpoint(endpoint *Endpoint) error {
+	defer datapathManager.syncEndpointRuleMetrics()
 	datapathManager.lockflowReplayWithTimeout()
 	defer datapathManager.flowReplayMutex.Unlock()
 	if !datapathManager.IsBridgesConnected() {
 		datapathManager.WaitForBridgeConnected()
 	}
 	ep, _ := datapathManager.localEndpointDB.Get(endpoint.InterfaceUUID)
+	if ep == nil && datapathManager.migratedEndpoints.Has(endpoint.InterfaceUUID) {
+		// flows of the endpoint have been removed on it migrated
+		datapathManager.migratedEndpoints.Remove(endpoint.InterfaceUUID)
+		return nil
+	}
 	if ep == nil {
 		return fmt.Errorf("Endpoint with interface name: %v, ofport: %v wasnot found", endpoint.InterfaceName, endpoint.PortNo)
 	}
@@ -1136,6 +2035,7 @@ func (datapathManager *DpManager) RemoveLocalEndpoint(endpoint *Endpoint) error
 		if ovsbrname == cachedEP.BridgeName {
 			// Same as addLocalEndpoint routine, keep datapath endpointDB is consistent with ovsdb
 			datapathManager.localEndpointDB.Remove(endpoint.InterfaceUUID)
+			datapathManager.refreshEndpointRuleMetrics(cachedEP)
 			for kword := range datapathManager.BridgeChainMap[vdsID] {
 				br := datapathManager.BridgeChainMap[vdsID][kword]
 				if err := br.RemoveLocalEndpoint(endpoint); err != nil {
@@ -1150,100 +2050,884 @@ func (datapathManager *DpManager) RemoveLocalEndpoint(endpoint *Endpoint) error
 	return nil
 }
 
+// RemoveMigratedLocalEndpoint removes local endpoints with the mac and any of the ips which have been migrated
+// to other agents, so that stale flows of the endpoint don't allow traffics before its ovsdb interface removed.
+// The mac is required to identify the interface, endpoints are never matched by ip only.
+func (datapathManager *DpManager) RemoveMigratedLocalEndpoint(mac string, ips []net.IP) ([]string, error) {
+	if mac == "" || len(ips) == 0 {
+		return nil, nil
+	}
+	// the most endpoints are not located on the local agent, lookup without flowReplay lock first
+	if len(datapathManager.migratedLocalEndpoints(mac, ips)) == 0 {
+		return nil, nil
+	}
+
+	defer datapathManager.syncEndpointRuleMetrics()
+	datapathManager.lockflowReplayWithTimeout()
+	defer datapathManager.flowReplayMutex.Unlock()
+	if !datapathManager.IsBridgesConnected() {
+		datapathManager.WaitForBridgeConnected()
+	}
+
+	var removed []string
+	for _, endpoint := range datapathManager.migratedLocalEndpoints(mac, ips) {
+		for vdsID, ovsbrname := range datapathManager.Config.ManagedVDSMap {
+			if ovsbrname != endpoint.BridgeName {
+				continue
+			}
+			log.Infof("Remove local endpoint %s with mac %s migrated to other agents", endpoint.InterfaceUUID, mac)
+			datapathManager.localEndpointDB.Remove(endpoint.InterfaceUUID)
+			datapathManager.migratedEndpoints.Set(endpoint.InterfaceUUID, endpoint)
+			datapathManager.refreshEndpointRuleMetrics(endpoint)
+			for kword := range datapathManager.BridgeChainMap[vdsID] {
+				br := datapathManager.BridgeChainMap[vdsID][kword]
+				if err := br.RemoveLocalEndpoint(endpoint); err != nil {
+					return removed, fmt.Errorf("failed to remove migrated local endpoint %v from vds %v, bridge %v, error: %v", endpoint.InterfaceUUID, vdsID, br.GetName(), err)
+				}
+			}
+			removed = append(removed, endpoint.InterfaceUUID)
+			break
+		}
+	}
+
+	return removed, nil
+}
+
+// RestoreMigratedLocalEndpoint adds back local endpoints with the mac which have been removed by
+// RemoveMigratedLocalEndpoint, when the endpoint migrated back to the local agent.
+func (datapathManager *DpManager) RestoreMigratedLocalEndpoint(mac string) ([]string, error) {
+	if mac == "" || len(datapathManager.restorableLocalEndpoints(mac)) == 0 {
+		return nil, nil
+	}
+
+	defer datapathManager.syncEndpointRuleMetrics()
+	datapathManager.lockflowReplayWithTimeout()
+	defer datapathManager.flowReplayMutex.Unlock()
+	if !datapathManager.IsBridgesConnected() {
+		datapathManager.WaitForBridgeConnected()
+	}
+
+	var restored []string
+	for _, endpoint := range datapathManager.restorableLocalEndpoints(mac) {
+		for vdsID, ovsbrname := range datapathManager.Config.ManagedVDSMap {
+			if ovsbrname != endpoint.BridgeName {
+				continue
+			}
+			log.Infof("Restore local endpoint %s with mac %s migrated back to local agent", endpoint.InterfaceUUID, mac)
+			datapathManager.migratedEndpoints.Remove(endpoint.InterfaceUUID)
+			datapathManager.localEndpointDB.Set(endpoint.InterfaceUUID, endpoint)
+			datapathManager.refreshEndpointRuleMetrics(endpoint)
+			for kword := range datapathManager.BridgeChainMap[vdsID] {
+				br := datapathManager.BridgeChainMap[vdsID][kword]
+				if err := br.AddLocalEndpoint(endpoint); err != nil {
+					return restored, fmt.Errorf("failed to restore migrated local endpoint %v to vds %v, bridge %v, error: %v", endpoint.InterfaceUUID, vdsID, br.GetName(), err)
+				}
+			}
+			restored = append(restored, endpoint.InterfaceUUID)
+			break
+		}
+	}
+
+	return restored, nil
+}
+
+func (datapathManager *DpManager) migratedLocalEndpoints(mac string, ips []net.IP) []*Endpoint {
+	var endpoints []*Endpoint
+	for item := range datapathManager.localEndpointDB.IterBuffered() {
+		endpoint := item.Val.(*Endpoint)
+		if !strings.EqualFold(mac, endpoint.MacAddrStr) {
+			continue
+		}
+		endpoint.IPAddrMutex.RLock()
+		for _, ip := range ips {
+			if ip.Equal(endpoint.IPAddr) || ip.Equal(endpoint.IPv6Addr) {
+				endpoints = append(endpoints, endpoint)
+				break
+			}
+		}
+		endpoint.IPAddrMutex.RUnlock()
+	}
+	return endpoints
+}
+
+func (datapathManager *DpManager) restorableLocalEndpoints(mac string) []*Endpoint {
+	var endpoints []*Endpoint
+	for item := range datapathManager.migratedEndpoints.IterBuffered() {
+		if endpoint := item.Val.(*Endpoint); strings.EqualFold(mac, endpoint.MacAddrStr) {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints
+}
+
+// RuleSpec describes a policy rule to install, it carries the arguments of AddEveroutePolicyRule
+type RuleSpec struct {
+	Rule      *EveroutePolicyRule
+	RuleName  string
+	Direction uint8
+	Tier      uint8
+	Mode      string
+
+	// LoggingTags is the logging tags of the rule, e.g. policy id, name and type
+	LoggingTags map[string]string
+	// RuleGroup is the rule group of the rule, rule flows are not installed when the group disabled
+	RuleGroup string
+	// BlockARP denies ARP and ND of the endpoint the rule applied to besides the rule flows
+	BlockARP bool
+}
+
+// RuleRef references a policy rule to remove, it carries the arguments of RemoveEveroutePolicyRule
+type RuleRef struct {
+	RuleID   string
+	RuleName string
+}
+
 func (datapathManager *DpManager) AddEveroutePolicyRule(rule *EveroutePolicyRule, ruleName string, direction uint8, tier uint8, mode string) error {
+	return datapathManager.AddEveroutePolicyRules(context.Background(), []RuleSpec{{
+		Rule:      rule,
+		RuleName:  ruleName,
+		Direction: direction,
+		Tier:      tier,
+		Mode:      mode,
+	}})
+}
+
+// AddEveroutePolicyRules installs the rules under one flowReplayMutex acquisition, conntrack of the
+// installed rules would be cleaned at once. A failed rule doesn't stop the others, all errors are
+// aggregated. Rules not installed when ctx is done would be skipped.
+func (datapathManager *DpManager) AddEveroutePolicyRules(ctx context.Context, specs []RuleSpec) error {
+	defer datapathManager.syncEndpointRuleMetrics()
+	verifyRuleFlows, err := datapathManager.addEveroutePolicyRules(ctx, specs)
+	// flows are read back from ovs outside flowReplayMutex, it may take up to VerifyRuleFlowTimeout
+	if verifyErr := datapathManager.verifyRuleFlows(verifyRuleFlows); verifyErr != nil {
+		return uerr.NewAggregate([]error{err, verifyErr})
+	}
+	return err
+}
+
+// addEveroutePolicyRules installs the rules, returns the installed rule flows to verify if VerifyRuleFlow enabled
+func (datapathManager *DpManager) addEveroutePolicyRules(ctx context.Context, specs []RuleSpec) ([]verifyRuleFlowEntry, error) {
 	datapathManager.lockflowReplayWithTimeout()
 	defer datapathManager.flowReplayMutex.Unlock()
 	if !datapathManager.IsBridgesConnected() {
 		datapathManager.WaitForBridgeConnected()
 	}
 
+	var errList []error
+	var cleanRules EveroutePolicyRuleList
+	var arpBlockChanged bool
+	var verifyRuleFlows []verifyRuleFlowEntry
+	ruleIPAddrs := sets.New[string]()
+	for i := range specs {
+		if err := ctx.Err(); err != nil {
+			errList = append(errList, err)
+			break
+		}
+		operation := metrics.RuleOperationAdd
+		if oldEntry := datapathManager.Rules[specs[i].Rule.RuleID]; oldEntry != nil {
+			operation = metrics.RuleOperationUpdate
+			ruleIPAddrs.Insert(ruleEndpointIPAddr(oldEntry.EveroutePolicyRule, oldEntry.Direction))
+			arpBlockChanged = arpBlockChanged || oldEntry.ARPBlockReference.Len() != 0
+		}
+		arpBlockChanged = arpBlockChanged || specs[i].BlockARP
+		installed, err := datapathManager.addEveroutePolicyRule(&specs[i])
+		if err != nil {
+			errList = append(errList, err)
+			continue
+		}
+		ruleIPAddrs.Insert(ruleEndpointIPAddr(specs[i].Rule, specs[i].Direction))
+		if installed && !skipConntrackClean(specs[i].Mode) {
+			cleanRules = append(cleanRules, datapathManager.conntrackCleanRule(specs[i].Rule, specs[i].Direction))
+		}
+		if installed && datapathManager.Config.VerifyRuleFlow {
+			verifyRuleFlows = append(verifyRuleFlows, datapathManager.verifyRuleFlowEntries(&specs[i], operation)...)
+		}
+	}
+
+	if arpBlockChanged {
+		if err := datapathManager.syncARPBlockFlows(); err != nil {
+			errList = append(errList, err)
+		}
+	}
+	datapathManager.updateEndpointRuleMetrics(ruleIPAddrs)
+	datapathManager.cleanConntrackFlowsInBatch(ctx, cleanRules)
+	return verifyRuleFlows, uerr.NewAggregate(errList)
+}
+
+// addEveroutePolicyRule installs the rule flows, returns true if flows installed. Caller must hold flowReplayMutex.
+func (datapathManager *DpManager) addEveroutePolicyRule(spec *RuleSpec) (bool, error) {
+	rule, ruleName, direction, tier, mode := spec.Rule, spec.RuleName, spec.Direction, spec.Tier, spec.Mode
+
 	// check if we already have the rule
 	var ruleEntry *EveroutePolicyRuleEntry
 	if _, ok := datapathManager.Rules[rule.RuleID]; ok {
 		ruleEntry = datapathManager.Rules[rule.RuleID]
 
-		if RuleIsSame(ruleEntry.EveroutePolicyRule, rule) {
+		if RuleIsSame(ruleEntry.EveroutePolicyRule, rule) && ruleEntry.Mode == mode {
+			disabled := datapathManager.ruleGroupDisabled(ruleEntry)
 			datapathManager.Rules[rule.RuleID].PolicyRuleReference.Insert(ruleName)
-			log.Infof("Rule already exists. new rule: {%+v}, old rule: {%+v}", rule, ruleEntry.EveroutePolicyRule)
-			return nil
+			ruleEntry.RuleGroupReference[ruleName] = spec.RuleGroup
+			setARPBlockReference(ruleEntry, ruleName, spec.BlockARP)
+			// flows of the rule are removed if failed to verify, reinstall them
+			if datapathManager.ruleGroupDisabled(ruleEntry) == disabled && !datapathManager.ruleFlowsMissing(ruleEntry) {
+				log.Infof("Rule already exists. new rule: {%+v}, old rule: {%+v}", rule, ruleEntry.EveroutePolicyRule)
+				return false, nil
+			}
 		}
 		log.Infof("Rule already exists. update old rule: {%+v} to new rule: {%+v} ", ruleEntry.EveroutePolicyRule, rule)
 	}
 
 	log.Infof("Received AddRule: %+v", rule)
+	operation := metrics.RuleOperationAdd
+	if ruleEntry != nil {
+		operation = metrics.RuleOperationUpdate
+	}
 	ruleFlowMap := make(map[string]*FlowEntry)
-	// Install policy rule flow to datapath
-	for vdsID, bridgeChain := range datapathManager.BridgeChainMap {
-		flowEntry, err := bridgeChain[POLICY_BRIDGE_KEYWORD].AddMicroSegmentRule(rule, direction, tier, mode)
+	ruleGroupReference := map[string]string{ruleName: spec.RuleGroup}
+	if ruleEntry != nil {
+		for name, group := range ruleEntry.RuleGroupReference {
+			if name != ruleName {
+				ruleGroupReference[name] = group
+			}
+		}
+	}
+	installed := !datapathManager.ruleGroupsDisabled(ruleGroupReference)
+	if !installed {
+		// flows of the rule would be installed when any rule group of it enabled
+		log.Infof("Rule groups %v of rule %s are disabled, skip install rule flows", ruleGroupReference, rule.RuleID)
+		if ruleEntry != nil {
+			if err := datapathManager.deleteRuleFlows(rule.RuleID, ruleEntry); err != nil {
+				return false, err
+			}
+		}
+	} else {
+		if rule.CTTimeout {
+			if err := datapathManager.applyCTTimeoutPolicy(); err != nil {
+				return false, err
+			}
+		}
+		// Install policy rule flow to datapath
+		for vdsID, bridgeChain := range datapathManager.BridgeChainMap {
+			start := time.Now()
+			flowEntry, err := bridgeChain[POLICY_BRIDGE_KEYWORD].AddMicroSegmentRule(rule, direction, tier, mode)
+			if err != nil {
+				datapathManager.AgentMetric.IncRuleFlowInstallFailure(vdsID, operation)
+				log.Errorf("Failed to add microsegment rule to vdsID %v, bridge %s, error: %v", vdsID, bridgeChain[POLICY_BRIDGE_KEYWORD], err)
+				return false, err
+			}
+			datapathManager.AgentMetric.ObserveRuleFlowInstall(vdsID, operation, time.Since(start))
+			ruleFlowMap[vdsID] = flowEntry
+		}
+	}
+
+	// save the rule. ruleFlowMap need deepcopy, NOTE
+	if ruleEntry == nil {
+		ruleEntry = &EveroutePolicyRuleEntry{
+			PolicyRuleReference: sets.NewString(ruleName),
+			ARPBlockReference:   sets.NewString(),
+		}
+	} else {
+		datapathManager.unindexRule(rule.RuleID, ruleEntry)
+	}
+	ruleEntry.Direction = direction
+	ruleEntry.Tier = tier
+	ruleEntry.Mode = mode
+	ruleEntry.EveroutePolicyRule = rule
+	ruleEntry.LoggingTags = spec.LoggingTags
+	ruleEntry.RuleGroupReference = ruleGroupReference
+	setARPBlockReference(ruleEntry, ruleName, spec.BlockARP)
+	oldRuleFlowMap := ruleEntry.RuleFlowMap
+	ruleEntry.RuleFlowMap = ruleFlowMap
+
+	// save flowID reference
+	for _, v := range ruleEntry.RuleFlowMap {
+		datapathManager.FlowIDToRules[v.FlowID] = ruleEntry
+		datapathManager.AgentMetric.AddRuleFlow(rule.RuleID, rule.Action, spec.LoggingTags[constants.LoggingTagPolicyType], v.FlowID)
+	}
+	for vdsID, v := range oldRuleFlowMap {
+		if ruleFlowMap[vdsID] == nil || ruleFlowMap[vdsID].FlowID != v.FlowID {
+			datapathManager.AgentMetric.RemoveRuleFlow(v.FlowID)
+		}
+	}
+
+	datapathManager.Rules[rule.RuleID] = ruleEntry
+	datapathManager.indexRule(rule.RuleID, ruleEntry)
+
+	return installed, nil
+}
+
+func (datapathManager *DpManager) RemoveEveroutePolicyRule(ruleID string, ruleName string) error {
+	return datapathManager.RemoveEveroutePolicyRules(context.Background(), []RuleRef{{RuleID: ruleID, RuleName: ruleName}})
+}
+
+// RemoveEveroutePolicyRules removes the rules under one flowReplayMutex acquisition, conntrack of the
+// removed rules would be cleaned at once. A failed rule doesn't stop the others, all errors are
+// aggregated. Rules not removed when ctx is done would be skipped.
+func (datapathManager *DpManager) RemoveEveroutePolicyRules(ctx context.Context, refs []RuleRef) error {
+	defer datapathManager.syncEndpointRuleMetrics()
+	datapathManager.lockflowReplayWithTimeout()
+	defer datapathManager.flowReplayMutex.Unlock()
+	if !datapathManager.IsBridgesConnected() {
+		datapathManager.WaitForBridgeConnected()
+	}
+
+	var errList []error
+	var cleanRules EveroutePolicyRuleList
+	var arpBlockChanged bool
+	ruleIPAddrs := sets.New[string]()
+	for i := range refs {
+		if err := ctx.Err(); err != nil {
+			errList = append(errList, err)
+			break
+		}
+		var direction uint8
+		var mode string
+		var blockARP bool
+		if ruleEntry := datapathManager.Rules[refs[i].RuleID]; ruleEntry != nil {
+			// flows of the rule might be removed for rule groups of the remaining references disabled
+			direction, mode, blockARP = ruleEntry.Direction, ruleEntry.Mode, ruleEntry.ARPBlockReference.Len() != 0
+		}
+		removedRule, err := datapathManager.removeEveroutePolicyRule(refs[i].RuleID, refs[i].RuleName)
 		if err != nil {
-			log.Errorf("Failed to add microsegment rule to vdsID %v, bridge %s, error: %v", vdsID, bridgeChain[POLICY_BRIDGE_KEYWORD], err)
+			errList = append(errList, err)
+			continue
+		}
+		arpBlockChanged = arpBlockChanged || blockARP
+		if removedRule != nil {
+			ruleIPAddrs.Insert(ruleEndpointIPAddr(removedRule, direction))
+			if !skipConntrackClean(mode) {
+				cleanRules = append(cleanRules, datapathManager.conntrackCleanRule(removedRule, direction))
+			}
+		}
+	}
+
+	if arpBlockChanged {
+		if err := datapathManager.syncARPBlockFlows(); err != nil {
+			errList = append(errList, err)
+		}
+	}
+	datapathManager.updateEndpointRuleMetrics(ruleIPAddrs)
+	datapathManager.cleanConntrackFlowsInBatch(ctx, cleanRules)
+	return uerr.NewAggregate(errList)
+}
+
+// removeEveroutePolicyRule removes the rule flows, returns the rule if flows removed. Caller must hold flowReplayMutex.
+func (datapathManager *DpManager) removeEveroutePolicyRule(ruleID string, ruleName string) (*EveroutePolicyRule, error) {
+	log.Infof("Received remove rule: %+v", ruleName)
+
+	pRule := datapathManager.Rules[ruleID]
+	if pRule == nil {
+		log.Errorf("ruleID %v not found when deleting", ruleID)
+		return nil, nil
+	}
+
+	// check and remove rule reference
+	disabled := datapathManager.ruleGroupDisabled(pRule)
+	pRule.PolicyRuleReference.Delete(ruleName)
+	pRule.ARPBlockReference.Delete(ruleName)
+	delete(pRule.RuleGroupReference, ruleName)
+	if pRule.PolicyRuleReference.Len() > 0 {
+		if disabled || !datapathManager.ruleGroupDisabled(pRule) {
+			return nil, nil
+		}
+		// rule groups of the remaining references are all disabled
+		if err := datapathManager.deleteRuleFlows(ruleID, pRule); err != nil {
+			return nil, err
+		}
+		return pRule.EveroutePolicyRule, nil
+	}
+
+	if err := datapathManager.deleteRuleFlows(ruleID, pRule); err != nil {
+		return nil, err
+	}
+
+	if pRule.PolicyRuleReference.Len() == 0 {
+		delete(datapathManager.Rules, ruleID)
+		datapathManager.unindexRule(ruleID, pRule)
+	}
+
+	return pRule.EveroutePolicyRule, nil
+}
+
+// deleteRuleFlows deletes the rule flows on all vds, deleted flows are removed from the rule entry.
+// Caller must hold flowReplayMutex.
+func (datapathManager *DpManager) deleteRuleFlows(ruleID string, pRule *EveroutePolicyRuleEntry) error {
+	for vdsID, flowEntry := range pRule.RuleFlowMap {
+		err := datapathManager.deleteFlowFunc(flowEntry.Table, flowEntry.Priority, flowEntry.FlowID)
+		if err != nil {
+			datapathManager.AgentMetric.IncRuleFlowInstallFailure(vdsID, metrics.RuleOperationRemove)
+			log.Errorf("Failed to delete flow for rule: %+v. Err: %v", ruleID, err)
 			return err
 		}
-		ruleFlowMap[vdsID] = flowEntry
+		// remove flowID reference
+		delete(datapathManager.FlowIDToRules, flowEntry.FlowID)
+		datapathManager.forgetRuleTableFlow(vdsID, flowEntry.FlowID)
+		datapathManager.AgentMetric.RemoveRuleFlow(flowEntry.FlowID)
+		delete(pRule.RuleFlowMap, vdsID)
+	}
+	return nil
+}
+
+// forgetRuleTableFlow removes the deleted rule flow from flow stats of the policy bridge on the vds
+func (datapathManager *DpManager) forgetRuleTableFlow(vdsID string, flowID uint64) {
+	if policyBridge, ok := datapathManager.BridgeChainMap[vdsID][POLICY_BRIDGE_KEYWORD].(*PolicyBridge); ok {
+		policyBridge.forgetRuleTableFlow(flowID)
+	}
+}
+
+// ruleGroupDisabled returns true if rule groups of all references of the rule are disabled
+func (datapathManager *DpManager) ruleGroupDisabled(pRule *EveroutePolicyRuleEntry) bool {
+	return datapathManager.ruleGroupsDisabled(pRule.RuleGroupReference)
+}
+
+// ruleGroupsDisabled returns true if all the rule groups are disabled, a reference without rule group is
+// never disabled
+func (datapathManager *DpManager) ruleGroupsDisabled(ruleGroupReference map[string]string) bool {
+	for _, group := range ruleGroupReference {
+		if group == "" || !datapathManager.disabledRuleGroups.Has(group) {
+			return false
+		}
+	}
+	return len(ruleGroupReference) != 0
+}
+
+func hasRuleGroup(pRule *EveroutePolicyRuleEntry, group string) bool {
+	for _, ruleGroup := range pRule.RuleGroupReference {
+		if ruleGroup == group {
+			return true
+		}
+	}
+	return false
+}
+
+// SetRuleGroupEnabled enables or disables all rules of the group under one flowReplayMutex acquisition. Flows of
+// rules in a disabled group are removed from datapath while the rules are kept, they would be reinstalled when
+// the group enabled again. Rules added to a disabled group would not install flows until the group enabled.
+func (datapathManager *DpManager) SetRuleGroupEnabled(group string, enabled bool) error {
+	if group == "" {
+		return fmt.Errorf("rule group name must be specified")
+	}
+
+	defer datapathManager.syncEndpointRuleMetrics()
+	datapathManager.lockflowReplayWithTimeout()
+	defer datapathManager.flowReplayMutex.Unlock()
+	if !datapathManager.IsBridgesConnected() {
+		datapathManager.WaitForBridgeConnected()
+	}
+
+	if enabled {
+		datapathManager.disabledRuleGroups.Delete(group)
+	} else {
+		datapathManager.disabledRuleGroups.Insert(group)
+	}
+
+	var errList []error
+	// persist disabled rule groups, so that rules of them wouldn't be installed after agent restarted
+	for vdsID := range datapathManager.BridgeChainMap {
+		if driver := datapathManager.OvsdbDriverMap[vdsID][LOCAL_BRIDGE_KEYWORD]; driver != nil {
+			if err := persistentDisabledRuleGroups(datapathManager.disabledRuleGroups, driver); err != nil {
+				errList = append(errList, fmt.Errorf("failed to persist disabled rule groups of vds %s: %s", vdsID, err))
+			}
+		}
+	}
+	var cleanRules EveroutePolicyRuleList
+	var changedRules int
+	var arpBlockChanged bool
+	ruleIPAddrs := sets.New[string]()
+	for ruleID, ruleEntry := range datapathManager.Rules {
+		if !hasRuleGroup(ruleEntry, group) {
+			continue
+		}
+		arpBlockChanged = arpBlockChanged || ruleEntry.ARPBlockReference.Len() != 0
+		var changed bool
+		if !datapathManager.ruleGroupDisabled(ruleEntry) {
+			for vdsID := range datapathManager.BridgeChainMap {
+				if _, ok := ruleEntry.RuleFlowMap[vdsID]; ok {
+					continue
+				}
+				if err := datapathManager.replayRuleFlow(vdsID, ruleID, ruleEntry); err != nil {
+					errList = append(errList, err)
+					continue
+				}
+				changed = true
+			}
+		} else {
+			changed = len(ruleEntry.RuleFlowMap) != 0
+			if err := datapathManager.deleteRuleFlows(ruleID, ruleEntry); err != nil {
+				errList = append(errList, err)
+			}
+		}
+		if changed {
+			changedRules++
+			ruleIPAddrs.Insert(ruleEndpointIPAddr(ruleEntry.EveroutePolicyRule, ruleEntry.Direction))
+			if !skipConntrackClean(ruleEntry.Mode) {
+				cleanRules = append(cleanRules, datapathManager.conntrackCleanRule(ruleEntry.EveroutePolicyRule, ruleEntry.Direction))
+			}
+		}
+	}
+	log.Infof("Set rule group %s enabled %t, %d rules changed", group, enabled, changedRules)
+	if arpBlockChanged {
+		if err := datapathManager.syncARPBlockFlows(); err != nil {
+			errList = append(errList, err)
+		}
+	}
+	datapathManager.updateEndpointRuleMetrics(ruleIPAddrs)
+
+	datapathManager.cleanConntrackFlows(cleanRules)
+	return uerr.NewAggregate(errList)
+}
+
+// verifyRuleFlowEntry is an installed rule flow to verify
+type verifyRuleFlowEntry struct {
+	vdsID     string
+	bridge    Bridge
+	ruleName  string
+	rule      *EveroutePolicyRule
+	flowEntry *FlowEntry
+	operation string
+}
+
+// verifyRuleFlowEntries returns the installed flows of the rule to verify. Caller must hold flowReplayMutex.
+func (datapathManager *DpManager) verifyRuleFlowEntries(spec *RuleSpec, operation string) []verifyRuleFlowEntry {
+	ruleEntry := datapathManager.Rules[spec.Rule.RuleID]
+	var entries []verifyRuleFlowEntry
+	for vdsID, flowEntry := range ruleEntry.RuleFlowMap {
+		entries = append(entries, verifyRuleFlowEntry{
+			vdsID:     vdsID,
+			bridge:    datapathManager.BridgeChainMap[vdsID][POLICY_BRIDGE_KEYWORD],
+			ruleName:  spec.RuleName,
+			rule:      spec.Rule,
+			flowEntry: flowEntry,
+			operation: operation,
+		})
+	}
+	return entries
+}
+
+// verifyRuleFlows verifies the installed rule flows, flows failed to verify are removed, so that the rule would
+// be reinstalled on retry. It must be called without holding flowReplayMutex.
+func (datapathManager *DpManager) verifyRuleFlows(entries []verifyRuleFlowEntry) error {
+	var errList []error
+	var unverified []verifyRuleFlowEntry
+	for _, entry := range entries {
+		if err := verifyRuleFlow(entry.bridge, entry.rule, entry.flowEntry); err != nil {
+			datapathManager.AgentMetric.IncRuleFlowInstallFailure(entry.vdsID, entry.operation)
+			log.Errorf("Failed to verify microsegment rule flow on vdsID %v, bridge %s, error: %v", entry.vdsID, entry.bridge.GetName(), err)
+			errList = append(errList, err)
+			unverified = append(unverified, entry)
+		}
+	}
+	if len(unverified) != 0 {
+		if err := datapathManager.removeUnverifiedRuleFlows(unverified); err != nil {
+			errList = append(errList, err)
+		}
+	}
+	return uerr.NewAggregate(errList)
+}
+
+// removeUnverifiedRuleFlows removes flows of the rules failed to verify, the rule is removed if the verified
+// reference is the only reference of it.
+func (datapathManager *DpManager) removeUnverifiedRuleFlows(entries []verifyRuleFlowEntry) error {
+	defer datapathManager.syncEndpointRuleMetrics()
+	datapathManager.lockflowReplayWithTimeout()
+	defer datapathManager.flowReplayMutex.Unlock()
+
+	var errList []error
+	var arpBlockChanged bool
+	ruleIPAddrs := sets.New[string]()
+	for _, entry := range entries {
+		ruleEntry := datapathManager.Rules[entry.rule.RuleID]
+		if ruleEntry == nil || ruleEntry.RuleFlowMap[entry.vdsID] != entry.flowEntry {
+			// the rule has been updated or removed during verification
+			continue
+		}
+		log.Infof("Remove flows of rule %s failed to verify", entry.rule.RuleID)
+		if err := datapathManager.deleteRuleFlows(entry.rule.RuleID, ruleEntry); err != nil {
+			errList = append(errList, err)
+			continue
+		}
+		ruleIPAddrs.Insert(ruleEndpointIPAddr(ruleEntry.EveroutePolicyRule, ruleEntry.Direction))
+		if ruleEntry.PolicyRuleReference.Equal(sets.NewString(entry.ruleName)) {
+			arpBlockChanged = arpBlockChanged || ruleEntry.ARPBlockReference.Len() != 0
+			delete(datapathManager.Rules, entry.rule.RuleID)
+			datapathManager.unindexRule(entry.rule.RuleID, ruleEntry)
+		}
+	}
+
+	if arpBlockChanged {
+		if err := datapathManager.syncARPBlockFlows(); err != nil {
+			errList = append(errList, err)
+		}
+	}
+	datapathManager.updateEndpointRuleMetrics(ruleIPAddrs)
+	return uerr.NewAggregate(errList)
+}
+
+// ruleFlowsMissing returns true if flows of the enabled rule are not installed on all vds
+func (datapathManager *DpManager) ruleFlowsMissing(pRule *EveroutePolicyRuleEntry) bool {
+	return !datapathManager.ruleGroupDisabled(pRule) && len(pRule.RuleFlowMap) != len(datapathManager.BridgeChainMap)
+}
+
+// verifyRuleFlow reads back the installed rule flow, and checks it matches the rule. The flow mod is sent
+// asynchronously, so the flow would be read again until it's found or timeout.
+func verifyRuleFlow(br Bridge, rule *EveroutePolicyRule, flowEntry *FlowEntry) error {
+	reader, ok := br.(ruleFlowReader)
+	if !ok {
+		return nil
+	}
+
+	var installed *InstalledFlow
+	err := wait.PollImmediate(VerifyRuleFlowInterval, VerifyRuleFlowTimeout, func() (bool, error) {
+		var err error
+		installed, err = reader.ReadRuleFlow(flowEntry)
+		return installed != nil, err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read back flow %#x of rule %s: %v", flowEntry.FlowID, rule.RuleID, err)
+	}
+
+	if installed.TableID != flowEntry.Table.TableId || installed.Priority != flowEntry.Priority {
+		return fmt.Errorf("flow %#x of rule %s installed in table %d priority %d, expect table %d priority %d", flowEntry.FlowID,
+			rule.RuleID, installed.TableID, installed.Priority, flowEntry.Table.TableId, flowEntry.Priority)
+	}
+	expectMatch, err := ruleFlowMatch(rule)
+	if err != nil {
+		return err
+	}
+	for field, value := range expectMatch {
+		if actual, ok := installed.Match[field]; !ok || actual != value {
+			return fmt.Errorf("flow %#x of rule %s installed with match %v, expect %s=%s", flowEntry.FlowID,
+				rule.RuleID, installed.Match, field, value)
+		}
+	}
+	return nil
+}
+
+// ruleFlowMatch returns match fields of the rule flow in ovs-ofctl format
+func ruleFlowMatch(rule *EveroutePolicyRule) (map[string]string, error) {
+	// ovs-ofctl names ipv6 flows with ipv6 and protocols suffixed with 6, e.g. tcp6, and ipv6 prefixed ip fields
+	ipProtocol, protocolSuffix, ipField, ipLen := "ip", "", "nw", net.IPv4len
+	if isIPv6Addr(rule.SrcIPAddr) || isIPv6Addr(rule.DstIPAddr) {
+		ipProtocol, protocolSuffix, ipField, ipLen = "ipv6", "6", "ipv6", net.IPv6len
+	}
+
+	match := make(map[string]string)
+	switch rule.IPProtocol {
+	case 0:
+		match[ipProtocol] = ""
+	case PROTOCOL_ICMP:
+		match["icmp"+protocolSuffix] = ""
+	case PROTOCOL_TCP:
+		match["tcp"+protocolSuffix] = ""
+	case PROTOCOL_UDP:
+		match["udp"+protocolSuffix] = ""
+	default:
+		match[ipProtocol] = ""
+		match["nw_proto"] = strconv.Itoa(int(rule.IPProtocol))
+	}
+
+	for field, ipAddr := range map[string]string{ipField + "_src": rule.SrcIPAddr, ipField + "_dst": rule.DstIPAddr} {
+		if ipAddr == "" {
+			continue
+		}
+		ip, mask, err := ParseIPAddrMaskString(ipAddr)
+		if err != nil {
+			return nil, err
+		}
+		ipMask := net.IPMask(mask.To4())
+		if ipLen == net.IPv6len {
+			ipMask = net.IPMask(mask.To16())
+		}
+		ones, _ := ipMask.Size()
+		match[field] = ip.Mask(ipMask).String()
+		if ones != ipLen*8 {
+			match[field] = fmt.Sprintf("%s/%d", match[field], ones)
+		}
+	}
+
+	if rule.IPProtocol == PROTOCOL_TCP || rule.IPProtocol == PROTOCOL_UDP {
+		for field, port := range map[string][2]uint16{"tp_src": {rule.SrcPort, rule.SrcPortMask}, "tp_dst": {rule.DstPort, rule.DstPortMask}} {
+			switch {
+			case port[0] == 0:
+			case port[1] == 0 || port[1] == PortMaskMatchFullBit:
+				match[field] = strconv.Itoa(int(port[0]))
+			default:
+				match[field] = fmt.Sprintf("%#x/%#x", port[0], port[1])
+			}
+		}
+	}
+
+	if rule.ICMPType != nil {
+		match["icmp_type"] = strconv.Itoa(int(*rule.ICMPType))
+	}
+	if rule.ICMPCode != nil {
+		match["icmp_code"] = strconv.Itoa(int(*rule.ICMPCode))
+	}
+	if rule.VlanID != 0 {
+		match["dl_vlan"] = strconv.Itoa(int(rule.VlanID))
+	}
+	return match, nil
+}
+
+// requestRuleFlowStats requests flow stats of policy bridges, the replies would be counted into rule metrics
+func (datapathManager *DpManager) requestRuleFlowStats() {
+	for vdsID := range datapathManager.BridgeChainMap {
+		br := datapathManager.BridgeChainMap[vdsID][POLICY_BRIDGE_KEYWORD]
+		if br == nil || !br.IsSwitchConnected() {
+			continue
+		}
+
+		flowStatsRequest := openflow13.NewFlowStatsRequest()
+		flowStatsRequest.TableId = openflow13.OFPTT_ALL
+		multipartRequest := &openflow13.MultipartRequest{
+			Header: openflow13.NewOfp13Header(),
+			Type:   openflow13.MultipartType_Flow,
+			Body:   flowStatsRequest,
+		}
+		multipartRequest.Header.Type = openflow13.Type_MultiPartRequest
+		if policyBridge, ok := br.(*PolicyBridge); ok {
+			policyBridge.completeRuleTableFlows()
+		}
+		br.getOfSwitch().Send(multipartRequest)
+	}
+
+	if leakedFlows := datapathManager.DetectLeakedFlows(false); len(leakedFlows) != 0 {
+		log.Warnf("Found %d flows not owned by any rule on policy bridge: %+v", len(leakedFlows), leakedFlows)
+	}
+}
+
+// DetectLeakedFlows diffs the policy rule table flows from the last flow stats against rules, and
+// returns flows not owned by any rule. The leaked flows would be removed from datapath if remove.
+func (datapathManager *DpManager) DetectLeakedFlows(remove bool) []*v1alpha1.LeakedFlow {
+	if remove {
+		// flows are removed from datapath, it must not run with rule install concurrently
+		datapathManager.lockflowReplayWithTimeout()
+		defer datapathManager.flowReplayMutex.Unlock()
+	} else {
+		datapathManager.lockRflowReplayWithTimeout()
+		defer datapathManager.flowReplayMutex.RUnlock()
+	}
+
+	ans := []*v1alpha1.LeakedFlow{}
+	for _, vdsID := range sets.StringKeySet(datapathManager.BridgeChainMap).List() {
+		policyBridge, ok := datapathManager.BridgeChainMap[vdsID][POLICY_BRIDGE_KEYWORD].(*PolicyBridge)
+		if !ok {
+			continue
+		}
+
+		var leakedNum int
+		for _, flow := range policyBridge.getRuleTableFlows() {
+			if _, ok := datapathManager.FlowIDToRules[flow.FlowID]; ok {
+				continue
+			}
+			ans = append(ans, &v1alpha1.LeakedFlow{
+				VdsID:      vdsID,
+				BridgeName: policyBridge.GetName(),
+				FlowEntry:  flowEntry2RpcFlowEntry(flow),
+			})
+			if remove {
+				err := datapathManager.deleteFlowFunc(flow.Table, flow.Priority, flow.FlowID)
+				if err == nil {
+					policyBridge.forgetRuleTableFlow(flow.FlowID)
+					continue
+				}
+				log.Errorf("Failed to remove leaked flow %+v on vds %s: %s", flow, vdsID, err)
+			}
+			leakedNum++
+		}
+		datapathManager.AgentMetric.SetLeakedFlows(vdsID, leakedNum)
+	}
+	return ans
+}
+
+// GetRuleStats returns hit counters of rules from the last flow stats, counters of rule flows
+// on all vds are aggregated by rule id
+func (datapathManager *DpManager) GetRuleStats() []*v1alpha1.RuleStats {
+	datapathManager.lockRflowReplayWithTimeout()
+	defer datapathManager.flowReplayMutex.RUnlock()
+
+	ruleStats := make(map[string]*v1alpha1.RuleStats)
+	for _, vdsID := range sets.StringKeySet(datapathManager.BridgeChainMap).List() {
+		policyBridge, ok := datapathManager.BridgeChainMap[vdsID][POLICY_BRIDGE_KEYWORD].(*PolicyBridge)
+		if !ok {
+			continue
+		}
+
+		for flowID, counter := range policyBridge.getRuleFlowStats() {
+			entry, ok := datapathManager.FlowIDToRules[flowID]
+			if !ok || entry.EveroutePolicyRule == nil {
+				continue
+			}
+			ruleID := entry.EveroutePolicyRule.RuleID
+			if ruleStats[ruleID] == nil {
+				ruleStats[ruleID] = &v1alpha1.RuleStats{RuleID: ruleID}
+			}
+			ruleStats[ruleID].PacketCount += counter.PacketCount
+			ruleStats[ruleID].ByteCount += counter.ByteCount
+			ruleStats[ruleID].Flows = append(ruleStats[ruleID].Flows, &v1alpha1.RuleFlowStats{
+				FlowID:      flowID,
+				VdsID:       vdsID,
+				PacketCount: counter.PacketCount,
+				ByteCount:   counter.ByteCount,
+			})
+		}
 	}
 
-	datapathManager.cleanConntrackFlow(rule)
-
-	// save the rule. ruleFlowMap need deepcopy, NOTE
-	if ruleEntry == nil {
-		ruleEntry = &EveroutePolicyRuleEntry{
-			PolicyRuleReference: sets.NewString(ruleName),
-		}
+	ans := make([]*v1alpha1.RuleStats, 0, len(ruleStats))
+	for _, stats := range ruleStats {
+		sort.Slice(stats.Flows, func(i, j int) bool {
+			if stats.Flows[i].VdsID != stats.Flows[j].VdsID {
+				return stats.Flows[i].VdsID < stats.Flows[j].VdsID
+			}
+			return stats.Flows[i].FlowID < stats.Flows[j].FlowID
+		})
+		ans = append(ans, stats)
 	}
-	ruleEntry.Direction = direction
-	ruleEntry.Tier = tier
-	ruleEntry.Mode = mode
-	ruleEntry.EveroutePolicyRule = rule
-	ruleEntry.RuleFlowMap = ruleFlowMap
+	sort.Slice(ans, func(i, j int) bool { return ans[i].RuleID < ans[j].RuleID })
+	return ans
+}
 
-	// save flowID reference
-	for _, v := range ruleEntry.RuleFlowMap {
-		datapathManager.FlowIDToRules[v.FlowID] = ruleEntry
+// SetTableMissAction sets the table-miss action of the policy bridge of the vds at runtime, the other vds is unaffected
+func (datapathManager *DpManager) SetTableMissAction(vdsID string, action TableMissAction) error {
+	if action != TableMissFailClosed && action != TableMissFailOpen {
+		return fmt.Errorf("unknown table-miss action %s", action)
 	}
 
-	datapathManager.Rules[rule.RuleID] = ruleEntry
-
-	return nil
-}
-
-func (datapathManager *DpManager) RemoveEveroutePolicyRule(ruleID string, ruleName string) error {
 	datapathManager.lockflowReplayWithTimeout()
 	defer datapathManager.flowReplayMutex.Unlock()
-	if !datapathManager.IsBridgesConnected() {
-		datapathManager.WaitForBridgeConnected()
+
+	policyBridge, ok := datapathManager.BridgeChainMap[vdsID][POLICY_BRIDGE_KEYWORD].(*PolicyBridge)
+	if !ok {
+		return fmt.Errorf("policy bridge of vds %s not found", vdsID)
+	}
+	if err := policyBridge.SetTableMissAction(action); err != nil {
+		return err
 	}
+	log.Infof("Set table-miss action of vds %s to %s", vdsID, action)
+	return nil
+}
 
-	log.Infof("Received remove rule: %+v", ruleName)
+// GetTableMissAction returns the table-miss action of the policy bridge of the vds
+func (datapathManager *DpManager) GetTableMissAction(vdsID string) (TableMissAction, error) {
+	datapathManager.lockRflowReplayWithTimeout()
+	defer datapathManager.flowReplayMutex.RUnlock()
 
-	pRule := datapathManager.Rules[ruleID]
-	if pRule == nil {
-		log.Errorf("ruleID %v not found when deleting", ruleID)
-		return nil
+	policyBridge, ok := datapathManager.BridgeChainMap[vdsID][POLICY_BRIDGE_KEYWORD].(*PolicyBridge)
+	if !ok {
+		return "", fmt.Errorf("policy bridge of vds %s not found", vdsID)
 	}
+	return policyBridge.GetTableMissAction(), nil
+}
 
-	// check and remove rule reference
-	pRule.PolicyRuleReference.Delete(ruleName)
-	if pRule.PolicyRuleReference.Len() > 0 {
-		return nil
-	}
+func (datapathManager *DpManager) removeExpiredRSTDeny() {
+	datapathManager.lockRflowReplayWithTimeout()
+	defer datapathManager.flowReplayMutex.RUnlock()
 
 	for vdsID := range datapathManager.BridgeChainMap {
-		err := ofctrl.DeleteFlow(pRule.RuleFlowMap[vdsID].Table, pRule.RuleFlowMap[vdsID].Priority, pRule.RuleFlowMap[vdsID].FlowID)
-		if err != nil {
-			log.Errorf("Failed to delete flow for rule: %+v. Err: %v", ruleID, err)
-			return err
+		if policyBridge, ok := datapathManager.BridgeChainMap[vdsID][POLICY_BRIDGE_KEYWORD].(*PolicyBridge); ok {
+			policyBridge.removeExpiredRSTDeny()
 		}
-		// remove flowID reference
-		delete(datapathManager.FlowIDToRules, pRule.RuleFlowMap[vdsID].FlowID)
-	}
-
-	datapathManager.cleanConntrackFlow(datapathManager.Rules[ruleID].EveroutePolicyRule)
-
-	if pRule.PolicyRuleReference.Len() == 0 {
-		delete(datapathManager.Rules, ruleID)
 	}
-
-	return nil
 }
 
 func (datapathManager *DpManager) GetNatBridges() []*NatBridge {
@@ -1281,31 +2965,74 @@ func (datapathManager *DpManager) syncIntenalIPs(stopChan <-chan struct{}) {
 		klog.Fatalf("fail to init ip addr update handle, err: %s", err)
 	}
 	for addr := range addrUpdateChan {
-		if addr.LinkAddress.IP.IsLoopback() || addr.LinkAddress.IP.To4() == nil {
+		// ipv6 link-local address is configured on each interface, it's not used for management traffic
+		if addr.LinkAddress.IP.IsLoopback() || (addr.LinkAddress.IP.To4() == nil && addr.LinkAddress.IP.IsLinkLocalUnicast()) {
 			continue
 		}
 		if addr.NewAddr {
-			datapathManager.addIntenalIP(addr.LinkAddress.IP.String(), addr.LinkIndex)
+			if err := datapathManager.addIntenalIP(addr.LinkAddress.IP.String(), addr.LinkIndex); err != nil {
+				log.Errorf("Failed to add internal whitelist: %s", err)
+			}
 		} else {
 			datapathManager.removeIntenalIP(addr.LinkAddress.IP.String(), addr.LinkIndex)
 		}
 	}
 }
 
-func (datapathManager *DpManager) addIntenalIP(ip string, index int) {
+// installInternalIPs installs rules of configured internal ips after the grace period, it waits until all
+// bridges connected and retries on failure instead of exiting, returns when installed or stopped.
+func (datapathManager *DpManager) installInternalIPs(stopChan <-chan struct{}) {
+	internalIPs := datapathManager.Config.InternalIPs
+	if len(internalIPs) == 0 {
+		return
+	}
+
+	if gracePeriod := datapathManager.Config.InternalIPGracePeriod; gracePeriod > 0 {
+		log.Infof("Wait %s before install internal whitelist", gracePeriod)
+		select {
+		case <-time.After(gracePeriod):
+		case <-stopChan:
+			return
+		}
+	}
+
+	installed := make([]bool, len(internalIPs))
+	_ = wait.PollImmediateUntil(internalIPRetryInterval, func() (bool, error) {
+		if !datapathManager.IsBridgesConnected() {
+			log.Infof("Bridges not ready, defer install internal whitelist")
+			return false, nil
+		}
+		done := true
+		for index, internalIP := range internalIPs {
+			if installed[index] {
+				continue
+			}
+			if err := datapathManager.addIntenalIP(internalIP, index); err != nil {
+				log.Errorf("Failed to add internal whitelist, would retry later: %s", err)
+				done = false
+				continue
+			}
+			installed[index] = true
+		}
+		return done, nil
+	}, stopChan)
+}
+
+func (datapathManager *DpManager) addIntenalIP(ip string, index int) error {
 	ruleNameSuffix := fmt.Sprintf("%s-%d", ip, index)
 	// add internal ingress rule
 	err := datapathManager.AddEveroutePolicyRule(newInternalIngressRule(ip),
 		InternalIngressRulePrefix+ruleNameSuffix, POLICY_DIRECTION_IN, POLICY_TIER3, DEFAULT_POLICY_ENFORCEMENT_MODE)
 	if err != nil {
-		log.Fatalf("Failed to add internal whitelist: %s: %v", ip, err)
+		return fmt.Errorf("%s: %v", ip, err)
 	}
 	// add internal egress rule
 	err = datapathManager.AddEveroutePolicyRule(newInternalEgressRule(ip),
 		InternalEgressRulePrefix+ruleNameSuffix, POLICY_DIRECTION_OUT, POLICY_TIER3, DEFAULT_POLICY_ENFORCEMENT_MODE)
 	if err != nil {
-		log.Fatalf("Failed to add internal whitelist: %s: %v", ip, err)
+		return fmt.Errorf("%s: %v", ip, err)
 	}
+	return nil
 }
 
 func (datapathManager *DpManager) removeIntenalIP(ip string, index int) {
@@ -1338,7 +3065,7 @@ func (datapathManager *DpManager) cleanConntrackWorker() {
 	for {
 		if datapathManager.getFlush() {
 			datapathManager.lockflushWithTimeout()
-			err := netlink.ConntrackTableFlush(netlink.ConntrackTable)
+			err := datapathManager.ctFlushFunc()
 			if err != nil {
 				klog.Errorf("Flush ct failed: %v", err)
 			} else {
@@ -1352,12 +3079,24 @@ func (datapathManager *DpManager) cleanConntrackWorker() {
 		if ruleList == nil {
 			return
 		}
-		matches, err := netlink.ConntrackDeleteFilter(netlink.ConntrackTable, unix.AF_INET, ruleList)
+		ruleList = mergeRuleList(datapathManager.takeCleanConntrackBatch(), ruleList)
+		datapathManager.cleanConntrackByFamily(ruleList)
+	}
+}
+
+// cleanConntrackByFamily cleans conntrack entries of each ip family with rules may match the family
+func (datapathManager *DpManager) cleanConntrackByFamily(ruleList EveroutePolicyRuleList) {
+	for _, family := range []netlink.InetFamily{unix.AF_INET, unix.AF_INET6} {
+		familyRules := ruleList.FilterByFamily(family)
+		if len(familyRules) == 0 {
+			continue
+		}
+		matches, err := datapathManager.ctDeleteFunc(family, familyRules)
 		if err != nil {
-			klog.Errorf("clear conntrack error, rules: %+v, err: %s", ruleList, err)
+			klog.Errorf("clear conntrack of family %d error, rules: %+v, err: %s", family, familyRules, err)
 			continue
 		}
-		klog.Infof("clear conntrack for rules: %+v, matches %d", ruleList, matches)
+		klog.Infof("clear conntrack of family %d for rules: %+v, matches %d", family, familyRules, matches)
 	}
 }
 
@@ -1366,13 +3105,33 @@ func (datapathManager *DpManager) cleanConntrackFlow(rule *EveroutePolicyRule) {
 		klog.Error("The rule for clean conntrack flow is nil")
 		return
 	}
+	datapathManager.cleanConntrackFlows(EveroutePolicyRuleList{*rule})
+}
+
+// cleanConntrackFlows cleans conntrack of the rules, cleanConntrackChan would be drained at most
+// once even if the rules overflow it.
+func (datapathManager *DpManager) cleanConntrackFlows(rules EveroutePolicyRuleList) {
+	if len(rules) == 0 {
+		return
+	}
 
 	if datapathManager.getFlush() {
 		return
 	}
 
-	if len(datapathManager.cleanConntrackChan) < cap(datapathManager.cleanConntrackChan) {
-		datapathManager.cleanConntrackChan <- *rule
+	if highWaterMark := datapathManager.Config.CTFlushHighWaterMark; highWaterMark > 0 &&
+		len(datapathManager.cleanConntrackChan)+len(rules) > highWaterMark {
+		// keep the last rule to wake up cleanConntrackWorker
+		if !datapathManager.batchCleanConntrackChan(rules[:len(rules)-1]...) {
+			return
+		}
+		rules = rules[len(rules)-1:]
+	}
+
+	if len(datapathManager.cleanConntrackChan)+len(rules) <= cap(datapathManager.cleanConntrackChan) {
+		for _, rule := range rules {
+			datapathManager.cleanConntrackChan <- rule
+		}
 		return
 	}
 
@@ -1387,6 +3146,206 @@ func (datapathManager *DpManager) cleanConntrackFlow(rule *EveroutePolicyRule) {
 	}
 }
 
+// ConntrackBatch defers conntrack clean of rules changed with the context of the batch until it committed,
+// or until it lasts over MaxCTCleanDefer, conntrack of rules changed after that are cleaned immediately.
+type ConntrackBatch struct {
+	dpManager *DpManager
+	timer     *time.Timer
+
+	lock    sync.Mutex
+	rules   EveroutePolicyRuleList // rules changed in the batch
+	expired bool                   // true if committed or deferred over MaxCTCleanDefer
+}
+
+type conntrackBatchKey struct{}
+
+type withoutConntrackCleanKey struct{}
+
+// WithoutConntrackClean returns the context with which rules changed by AddEveroutePolicyRules and
+// RemoveEveroutePolicyRules don't clean conntrack. It's used when rules are replaced by rules matching the
+// same packets with the same action, connections committed by the old rules are still allowed.
+func WithoutConntrackClean(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withoutConntrackCleanKey{}, true)
+}
+
+// BeginConntrackBatch starts a batch and returns the context carries it, conntrack of rules changed by
+// AddEveroutePolicyRules and RemoveEveroutePolicyRules with the context would be cleaned at once when the
+// batch committed, rules changed with other contexts are not deferred. If ctx carries a batch already, the
+// returned batch is nil and rules are cleaned when the outer one committed. It returns nil batch unless
+// DeferCTCleanInBatch enabled, commit of the nil batch takes no effect.
+func (datapathManager *DpManager) BeginConntrackBatch(ctx context.Context) (context.Context, *ConntrackBatch) {
+	if !datapathManager.Config.DeferCTCleanInBatch {
+		return ctx, nil
+	}
+	if _, ok := ctx.Value(conntrackBatchKey{}).(*ConntrackBatch); ok {
+		return ctx, nil
+	}
+
+	maxDefer := datapathManager.Config.MaxCTCleanDefer
+	if maxDefer <= 0 {
+		maxDefer = DefaultMaxCTCleanDefer
+	}
+	batch := &ConntrackBatch{dpManager: datapathManager}
+	batch.timer = time.AfterFunc(maxDefer, func() {
+		if batch.flush() {
+			klog.Warningf("Conntrack clean deferred in batch over %s, clean it before the batch committed", maxDefer)
+		}
+	})
+	return context.WithValue(ctx, conntrackBatchKey{}, batch), batch
+}
+
+// Commit cleans conntrack of rules changed in the batch, rules changed with the batch context after commit
+// are cleaned immediately.
+func (b *ConntrackBatch) Commit() {
+	if b == nil {
+		return
+	}
+	b.timer.Stop()
+	b.flush()
+}
+
+// flush expires the batch and cleans conntrack of the rules deferred, returns false if expired already
+func (b *ConntrackBatch) flush() bool {
+	b.lock.Lock()
+	if b.expired {
+		b.lock.Unlock()
+		return false
+	}
+	b.expired = true
+	rules := b.rules
+	b.rules = nil
+	b.lock.Unlock()
+
+	b.dpManager.cleanConntrackFlows(rules)
+	return true
+}
+
+// deferClean holds the rules until the batch committed, returns false if the batch expired
+func (b *ConntrackBatch) deferClean(rules EveroutePolicyRuleList) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.expired {
+		return false
+	}
+	b.rules = mergeRuleList(b.rules, rules)
+	return true
+}
+
+// cleanConntrackFlowsInBatch defers conntrack clean of the rules into the batch carried by ctx, or cleans
+// them immediately if no batch in progress
+func (datapathManager *DpManager) cleanConntrackFlowsInBatch(ctx context.Context, rules EveroutePolicyRuleList) {
+	if len(rules) == 0 || ctx.Value(withoutConntrackCleanKey{}) != nil {
+		return
+	}
+	if batch, ok := ctx.Value(conntrackBatchKey{}).(*ConntrackBatch); ok && batch.deferClean(rules) {
+		return
+	}
+	datapathManager.cleanConntrackFlows(rules)
+}
+
+// skipConntrackClean returns true if conntrack needn't be cleaned for the rule in the mode. Monitor rules never
+// drop packets, cleaning conntrack for them would only disrupt the existing connections.
+func skipConntrackClean(mode string) bool {
+	return mode == MONITOR_POLICY_ENFORCEMENT_MODE
+}
+
+// ctTimeoutPolicyEnabled returns true if connections of rules with CTTimeout are committed into the ct timeout
+// zone, it's only supported by the global ct zone strategy.
+func (datapathManager *DpManager) ctTimeoutPolicyEnabled() bool {
+	return len(datapathManager.Config.CTTimeoutPolicy) != 0 && !datapathManager.IsCTZoneByVlan()
+}
+
+// applyCTTimeoutPolicy sets the ct timeout policy of the ct timeout zone if it hasn't been set.
+// Caller must hold flowReplayMutex.
+func (datapathManager *DpManager) applyCTTimeoutPolicy() error {
+	if !datapathManager.ctTimeoutPolicyEnabled() || datapathManager.ctTimeoutPolicyApplied {
+		return nil
+	}
+	if err := datapathManager.ctTimeoutPolicyFunc(constants.CTZoneForPolicyCTTimeout, datapathManager.Config.CTTimeoutPolicy); err != nil {
+		return fmt.Errorf("failed to apply ct timeout policy: %s", err)
+	}
+	datapathManager.ctTimeoutPolicyApplied = true
+	return nil
+}
+
+// conntrackCleanRule returns a copy of the rule with conntrack zones of the local endpoints it applied to,
+// so that cleaning conntrack of the rule leaves connections of the other zones untouched.
+func (datapathManager *DpManager) conntrackCleanRule(rule *EveroutePolicyRule, direction uint8) EveroutePolicyRule {
+	cleanRule := *rule
+	cleanRule.CTZones = datapathManager.policyCTZones(rule, direction)
+	return cleanRule
+}
+
+// policyCTZones returns policy conntrack zones of local endpoints match the rule, nil means all zones
+// because endpoints of the rule can't be determined.
+func (datapathManager *DpManager) policyCTZones(rule *EveroutePolicyRule, direction uint8) []uint16 {
+	if !datapathManager.IsCTZoneByVlan() {
+		if datapathManager.ctTimeoutPolicyEnabled() {
+			return []uint16{constants.CTZoneForPolicy, constants.CTZoneForPolicyCTTimeout}
+		}
+		return []uint16{constants.CTZoneForPolicy}
+	}
+
+	endpointIPAddr := rule.DstIPAddr
+	if direction == POLICY_DIRECTION_OUT {
+		endpointIPAddr = rule.SrcIPAddr
+	}
+	if endpointIPAddr == "" {
+		return nil
+	}
+
+	zones := sets.New[uint16]()
+	for item := range datapathManager.localEndpointDB.IterBuffered() {
+		endpoint := item.Val.(*Endpoint)
+		endpoint.IPAddrMutex.RLock()
+		matched := matchIP(endpointIPAddr, endpoint.IPAddr) || matchIP(endpointIPAddr, endpoint.IPv6Addr)
+		endpoint.IPAddrMutex.RUnlock()
+		if !matched {
+			continue
+		}
+		if endpoint.Trunk != "" {
+			// vlan of trunk endpoint packets can't be determined
+			return nil
+		}
+		zones.Insert(PolicyCTZoneOfVlan(endpoint.VlanID))
+	}
+	if zones.Len() == 0 {
+		return nil
+	}
+	return sets.List(zones)
+}
+
+// batchCleanConntrackChan drains cleanConntrackChan and the rules into cleanConntrackBatch, the batch would
+// be cleaned with the next rule received by cleanConntrackWorker. It returns false when the batch overflow
+// and full flush has been required.
+func (datapathManager *DpManager) batchCleanConntrackChan(rules ...EveroutePolicyRule) bool {
+	datapathManager.lockflushWithTimeout()
+	defer datapathManager.flushMutex.Unlock()
+
+	var drained EveroutePolicyRuleList
+	for len(datapathManager.cleanConntrackChan) > 0 {
+		drained = append(drained, <-datapathManager.cleanConntrackChan)
+	}
+	datapathManager.cleanConntrackBatch = mergeRuleList(datapathManager.cleanConntrackBatch, append(drained, rules...))
+
+	if len(datapathManager.cleanConntrackBatch) > cap(datapathManager.cleanConntrackChan) {
+		klog.Info("The cleanConntrackBatch has overflowed, flush all conntrack")
+		datapathManager.cleanConntrackBatch = nil
+		datapathManager.needFlush = true
+		return false
+	}
+	return true
+}
+
+func (datapathManager *DpManager) takeCleanConntrackBatch() EveroutePolicyRuleList {
+	datapathManager.lockflushWithTimeout()
+	defer datapathManager.flushMutex.Unlock()
+
+	batch := datapathManager.cleanConntrackBatch
+	datapathManager.cleanConntrackBatch = nil
+	return batch
+}
+
 func (datapathManager *DpManager) IsEnableCNI() bool {
 	if datapathManager.Config == nil {
 		return false
@@ -1394,6 +3353,14 @@ func (datapathManager *DpManager) IsEnableCNI() bool {
 	return datapathManager.Config.EnableCNI
 }
 
+// IsCTZoneByVlan returns true if policy conntrack zone is derived from endpoint vlan
+func (datapathManager *DpManager) IsCTZoneByVlan() bool {
+	if datapathManager.Config == nil {
+		return false
+	}
+	return datapathManager.Config.CTZoneStrategy == CTZoneStrategyVlan
+}
+
 func (datapathManager *DpManager) IsEnableProxy() bool {
 	if !datapathManager.IsEnableCNI() {
 		return false
@@ -1437,10 +3404,83 @@ func (datapathManager *DpManager) HandleEndpointIPTimeout(_ context.Context, end
 	if ofSwitch == nil {
 		return fmt.Errorf("connect to bridge %s break", endpointIP.BridgeName)
 	}
+	if endpointIP.IP.To4() == nil {
+		return setNSRequest(ofSwitch, endpointIP.OfPort, endpointIP.VlanID, endpointIP.Mac, endpointIP.IP)
+	}
 	setARPRequest(ofSwitch, endpointIP.OfPort, endpointIP.VlanID, endpointIP.Mac, endpointIP.IP)
 	return nil
 }
 
+// RefreshEndpointIP probes the learned ipv4 and ipv6 of the local endpoint with the interface uuid, the endpoint ip
+// would be relearned from the arp reply or neighbor advertisement without waiting for ip timeout. All local endpoints are probed if interfaceUUID is
+// empty. A failed endpoint doesn't stop the others, the result of each endpoint is returned.
+func (datapathManager *DpManager) RefreshEndpointIP(ctx context.Context, interfaceUUID string) ([]*v1alpha1.EndpointIPRefreshResult, error) {
+	var endpoints []*Endpoint
+	if interfaceUUID != "" {
+		ep, ok := datapathManager.localEndpointDB.Get(interfaceUUID)
+		if !ok {
+			return nil, fmt.Errorf("local endpoint with interface %s not found", interfaceUUID)
+		}
+		endpoints = append(endpoints, ep.(*Endpoint))
+	} else {
+		for item := range datapathManager.localEndpointDB.IterBuffered() {
+			endpoints = append(endpoints, item.Val.(*Endpoint))
+		}
+		sort.Slice(endpoints, func(i, j int) bool {
+			return endpoints[i].InterfaceUUID < endpoints[j].InterfaceUUID
+		})
+	}
+
+	ans := []*v1alpha1.EndpointIPRefreshResult{}
+	for _, endpoint := range endpoints {
+		result := &v1alpha1.EndpointIPRefreshResult{InterfaceUUID: endpoint.InterfaceUUID, Success: true}
+		if err := datapathManager.refreshEndpointIP(ctx, endpoint); err != nil {
+			log.Errorf("Failed to refresh ip of endpoint %s: %v", endpoint.InterfaceUUID, err)
+			result.Success, result.Error = false, err.Error()
+		}
+		ans = append(ans, result)
+	}
+	return ans, nil
+}
+
+func (datapathManager *DpManager) refreshEndpointIP(ctx context.Context, endpoint *Endpoint) error {
+	endpoint.IPAddrMutex.RLock()
+	var ips []net.IP
+	for _, ip := range []net.IP{endpoint.IPAddr, endpoint.IPv6Addr} {
+		if ip != nil {
+			ips = append(ips, ip)
+		}
+	}
+	endpoint.IPAddrMutex.RUnlock()
+	if len(ips) == 0 {
+		return fmt.Errorf("no ip learned on endpoint %s", endpoint.InterfaceUUID)
+	}
+
+	// send arp request or neighbor solicitation from the bridge internal port, the same as ip timeout probe
+	iface, err := net.InterfaceByName(endpoint.BridgeName)
+	if err != nil {
+		return fmt.Errorf("failed to get mac of bridge %s: %s", endpoint.BridgeName, err)
+	}
+	var vlanID uint16
+	if endpoint.Trunk != "" {
+		vlanID = endpoint.VlanID
+	}
+	var errList []error
+	for _, ip := range ips {
+		err := datapathManager.ipProbeFunc(ctx, &types.EndpointIP{
+			BridgeName: endpoint.BridgeName,
+			OfPort:     endpoint.PortNo,
+			VlanID:     vlanID,
+			IP:         ip,
+			Mac:        iface.HardwareAddr,
+		})
+		if err != nil {
+			errList = append(errList, fmt.Errorf("probe ip %s: %s", ip, err))
+		}
+	}
+	return uerr.NewAggregate(errList)
+}
+
 func (datapathManager *DpManager) getOfSwitchByBridge(bridgeName, bridgeKeyword string) *ofctrl.OFSwitch {
 	datapathManager.DpManagerMutex.Lock()
 	defer datapathManager.DpManagerMutex.Unlock()
@@ -1472,6 +3512,74 @@ func setARPRequest(ofSwitch *ofctrl.OFSwitch, ofPort uint32, vlanID uint16, srcM
 	ofSwitch.Send(ofPacketOut)
 }
 
+const (
+	icmpv6TypeNeighborSolicitation  = 135
+	icmpv6TypeNeighborAdvertisement = 136
+	ndOptionSourceLinkLayerAddr     = 1
+	// neighbor discovery messages must be sent with hop limit 255, see RFC 4861
+	ndHopLimit = 255
+)
+
+// setNSRequest sends neighbor solicitation of the dstIP to its solicited-node multicast address, the source
+// address is the link local address of srcMac, so that the endpoint replies with neighbor advertisement.
+func setNSRequest(ofSwitch *ofctrl.OFSwitch, ofPort uint32, vlanID uint16, srcMac net.HardwareAddr, dstIP net.IP) error {
+	ipv6 := &protocol.IPv6{
+		Version:    6,
+		NextHeader: protocol.Type_IPv6ICMP,
+		HopLimit:   ndHopLimit,
+		NWSrc:      linkLocalAddrOf(srcMac),
+		NWDst:      solicitedNodeAddrOf(dstIP),
+		Data:       neighborSolicitationOf(srcMac, dstIP),
+	}
+	if err := fillIPv6Checksums(ipv6); err != nil {
+		return err
+	}
+
+	nsReqPkt := protocol.NewEthernet()
+	nsReqPkt.HWSrc = srcMac
+	nsReqPkt.HWDst = net.HardwareAddr{0x33, 0x33, ipv6.NWDst[12], ipv6.NWDst[13], ipv6.NWDst[14], ipv6.NWDst[15]}
+	nsReqPkt.VLANID.VID = vlanID
+	nsReqPkt.Ethertype = protocol.IPv6_MSG
+	nsReqPkt.Data = ipv6
+
+	ofPacketOut := openflow13.NewPacketOut()
+	ofPacketOut.AddAction(openflow13.NewActionOutput(ofPort))
+	ofPacketOut.Data = nsReqPkt
+
+	ofSwitch.Send(ofPacketOut)
+	return nil
+}
+
+// neighborSolicitationOf returns icmpv6 neighbor solicitation of the target with source link-layer address option
+func neighborSolicitationOf(srcMac net.HardwareAddr, target net.IP) *protocol.ICMP {
+	icmp := protocol.NewICMP()
+	icmp.Type = icmpv6TypeNeighborSolicitation
+	// 4 bytes reserved, 16 bytes target address, 8 bytes source link-layer address option
+	icmp.Data = make([]byte, 4+net.IPv6len+8)
+	copy(icmp.Data[4:], target.To16())
+	icmp.Data[4+net.IPv6len] = ndOptionSourceLinkLayerAddr
+	icmp.Data[4+net.IPv6len+1] = 1
+	copy(icmp.Data[4+net.IPv6len+2:], srcMac)
+	return icmp
+}
+
+// solicitedNodeAddrOf returns the solicited-node multicast address ff02::1:ffXX:XXXX of the ip, see RFC 4291
+func solicitedNodeAddrOf(ip net.IP) net.IP {
+	addr := net.ParseIP("ff02::1:ff00:0")
+	copy(addr[13:], ip.To16()[13:])
+	return addr
+}
+
+// linkLocalAddrOf returns the link local address fe80::/64 of the mac in modified EUI-64 format, see RFC 4291
+func linkLocalAddrOf(mac net.HardwareAddr) net.IP {
+	addr := net.ParseIP("fe80::")
+	if len(mac) != 6 {
+		return addr
+	}
+	copy(addr[8:], []byte{mac[0] ^ 0x02, mac[1], mac[2], 0xff, 0xfe, mac[3], mac[4], mac[5]})
+	return addr
+}
+
 func receiveRuleListFromChan(ruleChan <-chan EveroutePolicyRule) EveroutePolicyRuleList {
 	var ruleList EveroutePolicyRuleList
 
@@ -1498,6 +3606,31 @@ func receiveRuleListFromChan(ruleChan <-chan EveroutePolicyRule) EveroutePolicyR
 	}
 }
 
+// mergeRuleList returns the union of the rule lists, rules with the same RuleID would be merged
+func mergeRuleList(list1, list2 EveroutePolicyRuleList) EveroutePolicyRuleList {
+	var ruleList EveroutePolicyRuleList
+	ruleIndex := make(map[string]int)
+	for _, list := range []EveroutePolicyRuleList{list1, list2} {
+		for _, rule := range list {
+			if index, ok := ruleIndex[rule.RuleID]; ok {
+				ruleList[index].CTZones = mergeCTZones(ruleList[index].CTZones, rule.CTZones)
+				continue
+			}
+			ruleIndex[rule.RuleID] = len(ruleList)
+			ruleList = append(ruleList, rule)
+		}
+	}
+	return ruleList
+}
+
+// mergeCTZones returns union of the conntrack zones, empty zones means all zones
+func mergeCTZones(zones1, zones2 []uint16) []uint16 {
+	if len(zones1) == 0 || len(zones2) == 0 {
+		return nil
+	}
+	return sets.List(sets.New(zones1...).Insert(zones2...))
+}
+
 func RuleIsSame(r1, r2 *EveroutePolicyRule) bool {
 	return reflect.DeepEqual(*r1, *r2)
 }
@@ -1514,19 +3647,20 @@ func DeepCopyMap(theMap interface{}) interface{} {
 	return dstMap.Interface()
 }
 
-func getRoundInfo(ovsdbDriver *ovsdbDriver.OvsDriver) (*RoundInfo, error) {
-	var num, newRoundNum uint64
-	var err error
-
+func getRoundInfo(ovsdbDriver *ovsdbDriver.OvsDriver, flowCookie *FlowCookieConfig) (*RoundInfo, error) {
 	externalIds, err := ovsdbDriver.GetExternalIds()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get ovsdb externalids: %v", err)
 	}
+	return roundInfoFromExternalIds(externalIds, flowCookie)
+}
 
+func roundInfoFromExternalIds(externalIds map[string]string, flowCookie *FlowCookieConfig) (*RoundInfo, error) {
 	if len(externalIds) == 0 {
 		log.Infof("Bridge's external-ids are empty")
 		return &RoundInfo{
-			curRoundNum: uint64(1),
+			curRoundNum:        uint64(1),
+			previousFlowCookie: flowCookie,
 		}, nil
 	}
 
@@ -1534,39 +3668,94 @@ func getRoundInfo(ovsdbDriver *ovsdbDriver.OvsDriver) (*RoundInfo, error) {
 	if !exists {
 		log.Infof("Bridge's external-ids don't contain ofnetRestartRound field")
 		return &RoundInfo{
-			curRoundNum: uint64(1),
+			curRoundNum:        uint64(1),
+			previousFlowCookie: flowCookie,
 		}, nil
 	}
 
-	num, err = strconv.ParseUint(roundNum, 10, 64)
+	num, err := strconv.ParseUint(roundNum, 10, 64)
 	if err != nil {
 		return nil, fmt.Errorf("bad format of round number: %+v, parse error: %+v", roundNum, err)
 	}
 
-	// Flipping current round num with minimum round num value while it equals with the maximum round num
-	if num >= MaxRoundNum {
-		newRoundNum = 1
-	} else {
-		newRoundNum = num + 1
+	// the round num persisted without layout was allocated with the default layout
+	previousFlowCookie := DefaultFlowCookieConfig()
+	if roundNumBits, exists := externalIds[datapathRoundNumBits]; exists {
+		bits, err := strconv.Atoi(roundNumBits)
+		if err != nil || bits <= 0 || bits >= FlowCookieBits {
+			return nil, fmt.Errorf("bad format of round num bits: %+v, parse error: %+v", roundNumBits, err)
+		}
+		previousFlowCookie = &FlowCookieConfig{RoundNumBits: bits, FlowSeqBits: FlowCookieBits - bits}
+		previousFlowCookie.MaxRoundNum = previousFlowCookie.roundNumMax()
+	}
+	if previousFlowCookie.RoundNumBits != flowCookie.RoundNumBits {
+		log.Infof("Flow cookie round num bits changed from %d to %d", previousFlowCookie.RoundNumBits, flowCookie.RoundNumBits)
 	}
 
+	// Flipping current round num with minimum round num value while it reaches the configured maximum round num
 	return &RoundInfo{
-		previousRoundNum: num,
-		curRoundNum:      newRoundNum,
+		previousRoundNum:   num,
+		curRoundNum:        flowCookie.nextRoundNumAfter(previousFlowCookie, num),
+		previousFlowCookie: previousFlowCookie,
 	}, nil
 }
 
-func persistentRoundInfo(curRoundNum uint64, ovsdbDriver *ovsdbDriver.OvsDriver) error {
+func persistentRoundInfo(curRoundNum uint64, ovsdbDriver *ovsdbDriver.OvsDriver, flowCookie *FlowCookieConfig) error {
+	if curRoundNum > flowCookie.MaxRoundNum {
+		return fmt.Errorf("round num %d exceeds max round num %d of flow cookie", curRoundNum, flowCookie.MaxRoundNum)
+	}
+
 	externalIds, err := ovsdbDriver.GetExternalIds()
 	if err != nil {
 		return err
 	}
 
 	externalIds[datapathRestartRound] = fmt.Sprint(curRoundNum)
+	externalIds[datapathRoundNumBits] = fmt.Sprint(flowCookie.RoundNumBits)
+
+	return ovsdbDriver.SetExternalIds(externalIds)
+}
+
+// loadDisabledRuleGroups loads disabled rule groups persisted in ovsdb of the vds, so that rules of them added
+// after agent restarted wouldn't install flows.
+func (datapathManager *DpManager) loadDisabledRuleGroups(vdsID string) error {
+	externalIds, err := datapathManager.OvsdbDriverMap[vdsID][LOCAL_BRIDGE_KEYWORD].GetExternalIds()
+	if err != nil {
+		return fmt.Errorf("failed to get ovsdb externalids: %v", err)
+	}
+	if externalIds[datapathDisabledRuleGroups] == "" {
+		return nil
+	}
+
+	groups := strings.Split(externalIds[datapathDisabledRuleGroups], ",")
+	datapathManager.lockflowReplayWithTimeout()
+	defer datapathManager.flowReplayMutex.Unlock()
+	datapathManager.disabledRuleGroups.Insert(groups...)
+	log.Infof("Loaded disabled rule groups %v of vds %s", groups, vdsID)
+	return nil
+}
+
+func persistentDisabledRuleGroups(groups sets.Set[string], ovsdbDriver *ovsdbDriver.OvsDriver) error {
+	externalIds, err := ovsdbDriver.GetExternalIds()
+	if err != nil {
+		return err
+	}
+
+	if groups.Len() == 0 {
+		delete(externalIds, datapathDisabledRuleGroups)
+	} else {
+		externalIds[datapathDisabledRuleGroups] = strings.Join(sets.List(groups), ",")
+	}
 
 	return ovsdbDriver.SetExternalIds(externalIds)
 }
 
+// isIPv6Addr returns true if the ip address or cidr is ipv6
+func isIPv6Addr(ipAddr string) bool {
+	ip, _, err := ParseIPAddrMaskString(ipAddr)
+	return err == nil && ip.To4() == nil
+}
+
 // ParseIPAddrMaskString Parse IP addr string
 func ParseIPAddrMaskString(ipAddr string) (*net.IP, *net.IP, error) {
 	if strings.Contains(ipAddr, "/") {
@@ -1577,6 +3766,9 @@ func ParseIPAddrMaskString(ipAddr string) (*net.IP, *net.IP, error) {
 		}
 
 		ipMask := net.ParseIP(IP_BROADCAST_ADDR).Mask(ipNet.Mask)
+		if ipDav.To4() == nil {
+			ipMask = net.IP(ipNet.Mask)
+		}
 
 		return &ipDav, &ipMask, nil
 	}
@@ -1587,6 +3779,9 @@ func ParseIPAddrMaskString(ipAddr string) (*net.IP, *net.IP, error) {
 	}
 
 	ipMask := net.ParseIP(IP_BROADCAST_ADDR)
+	if ipDa.To4() == nil {
+		ipMask = net.IP(net.CIDRMask(net.IPv6len*8, net.IPv6len*8))
+	}
 
 	return &ipDa, &ipMask, nil
 }
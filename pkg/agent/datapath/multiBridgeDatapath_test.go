@@ -23,6 +23,7 @@ import (
 	"net"
 	"os"
 	"os/exec"
+	"reflect"
 	"regexp"
 	"strings"
 	"testing"
@@ -33,6 +34,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/rand"
 
 	"github.com/everoute/everoute/pkg/apis/security/v1alpha1"
+	"github.com/everoute/everoute/pkg/constants"
 	"github.com/everoute/everoute/pkg/types"
 )
 
@@ -55,6 +57,8 @@ var (
 			"ovsbr0": "ovsbr0",
 		},
 		EnableIPLearning: true,
+		CTTimeoutPolicy:  map[string]uint32{"udp": 10},
+		TCPRSTDetect:     &TCPRSTDetectConfig{Threshold: 3, Window: time.Minute, DenyTimeout: 5 * time.Second},
 	}
 
 	cniDpMgr  *DpManager
@@ -107,6 +111,16 @@ var (
 		BridgeName:    "ovsbr0",
 		Trunk:         "1,2,3",
 	}
+	ep4 = &Endpoint{
+		InterfaceName: "ep4",
+		InterfaceUUID: "40000000-0000-0000-0000-000000000000",
+		PortNo:        uint32(44),
+		IPAddr:        net.ParseIP("10.10.1.9"),
+		MacAddrStr:    "00:00:aa:aa:aa:dd",
+		BridgeName:    "ovsbr0",
+		VlanID:        uint16(1),
+		NotReady:      true,
+	}
 
 	rule1 = &EveroutePolicyRule{
 		RuleID:     "rule1",
@@ -150,17 +164,34 @@ var (
 	ctDropMatchFlow                = "table=70, priority=300,ct_label=0x80000000000000000000000000000000/0x80000000000000000000000000000000,ip actions=load:0x20->NXM_NX_REG4[0..15],goto_table:71"
 	ingressTier3MonitorDropFlow    = "table=59, priority=300,ct_label=0x40000000000000000000000000000000/0x40000000000000000000000000000000,ip actions=move:NXM_NX_CT_LABEL[0..3]->NXM_NX_XXREG0[0..3],move:NXM_NX_CT_LABEL[32..59]->NXM_NX_XXREG0[32..59],move:NXM_NX_CT_LABEL[126]->NXM_NX_XXREG0[126],goto_table:60"
 	ingressTier3MonitorDefaultFlow = "table=59, priority=10 actions=move:NXM_NX_CT_LABEL[0..3]->NXM_NX_XXREG0[0..3],move:NXM_NX_CT_LABEL[32..59]->NXM_NX_XXREG0[32..59],move:NXM_NX_CT_LABEL[126]->NXM_NX_XXREG0[126],goto_table:60"
+	ep4NotReadyDropFlow            = "table=70, priority=303,ip,dl_dst=00:00:aa:aa:aa:dd actions=drop"
+	ep4NotReadyIPv6DropFlow        = "table=70, priority=303,ipv6,dl_dst=00:00:aa:aa:aa:dd actions=drop"
 )
 
+// ovsAvailable is true if openvswitch is running on the host, tests on the real
+// bridges are skipped without it, the others only rely on fake bridges.
+var ovsAvailable bool
+
 func TestMain(m *testing.M) {
-	setupEverouteDp()
-	setupOverlayDp()
+	ovsAvailable = exec.Command("ovs-vsctl", "--timeout=3", "show").Run() == nil
+	if ovsAvailable {
+		setupEverouteDp()
+		setupOverlayDp()
+	}
 	exitCode := m.Run()
-	teardownEverouteDp()
-	teardownOverlayDp()
+	if ovsAvailable {
+		teardownEverouteDp()
+		teardownOverlayDp()
+	}
 	os.Exit(exitCode)
 }
 
+func skipWithoutOVS(t *testing.T) {
+	if !ovsAvailable {
+		t.Skip("skip test without openvswitch")
+	}
+}
+
 func setupEverouteDp() {
 	if err := ExcuteCommand(SetupBridgeChain, "ovsbr0"); err != nil {
 		log.Fatalf("Failed to setup bridgechain, error: %v", err)
@@ -199,10 +230,12 @@ func teardownOverlayDp() {
 }
 
 func TestOverlayDp(t *testing.T) {
+	skipWithoutOVS(t)
 	testLocalEndpointOverlay(t)
 }
 
 func TestEverouteDp(t *testing.T) {
+	skipWithoutOVS(t)
 	var err error
 	if defaultFlowList, err = dumpAllFlows(); err != nil {
 		log.Fatalf("Failed to dump default flow while test env setup")
@@ -216,9 +249,16 @@ func TestEverouteDp(t *testing.T) {
 	})
 
 	testLocalEndpoint(t)
+	testEndpointReadiness(t)
 	testERPolicyRule(t)
 	testPolicyTableInit(t)
+	testTableMissAction(t)
+	testEstablishedShortcut(t)
+	testARPBlock(t)
+	testTCPRSTDetect(t)
+	testCTTimeoutPolicy(t)
 	testMonitorRule(t)
+	testTableLayout(t)
 	testFlowReplay(t)
 	testRoundNumFlip(t)
 	testHandleEndpointIPTimeout(t)
@@ -282,6 +322,17 @@ func testLocalEndpoint(t *testing.T) {
 	if err := datapathManager.AddLocalEndpoint(ep3); err != nil {
 		t.Errorf("Failed to add local endpoint %v, error: %v", ep3, err)
 	}
+
+	t.Run("Test add local endpoint on unmanaged bridge", func(t *testing.T) {
+		unmanagedEp := copyEp(ep1)
+		unmanagedEp.InterfaceUUID = "50000000-0000-0000-0000-000000000000"
+		unmanagedEp.BridgeName = "unmanagedbr0"
+		err := datapathManager.AddLocalEndpoint(unmanagedEp)
+		Expect(IsUnmanagedBridgeError(err)).Should(BeTrue())
+		if ep, _ := datapathManager.localEndpointDB.Get(unmanagedEp.InterfaceUUID); ep != nil {
+			t.Errorf("Endpoint %v on unmanaged bridge should not be added", unmanagedEp)
+		}
+	})
 	t.Run("validate local endpoint forwarding flow add", func(t *testing.T) {
 		Eventually(func() error {
 			return flowValidator([]string{ep3VlanInputFlow1, ep3VlanFilterFlow1, ep3VlanFilterFlow2})
@@ -289,6 +340,44 @@ func testLocalEndpoint(t *testing.T) {
 	})
 }
 
+func testEndpointReadiness(t *testing.T) {
+	RegisterTestingT(t)
+
+	if err := datapathManager.AddLocalEndpoint(ep4); err != nil {
+		t.Errorf("Failed to add local endpoint %v, error: %v", ep4, err)
+	}
+	t.Run("traffic to not ready endpoint should be dropped", func(t *testing.T) {
+		Eventually(func() error {
+			return flowValidator([]string{ep4NotReadyDropFlow, ep4NotReadyIPv6DropFlow})
+		}, timeout, interval).Should(Succeed())
+	})
+
+	readyEP4 := &Endpoint{
+		InterfaceName: ep4.InterfaceName,
+		InterfaceUUID: ep4.InterfaceUUID,
+		PortNo:        ep4.PortNo,
+		IPAddr:        ep4.IPAddr,
+		MacAddrStr:    ep4.MacAddrStr,
+		BridgeName:    ep4.BridgeName,
+		VlanID:        ep4.VlanID,
+	}
+	if err := datapathManager.UpdateLocalEndpoint(readyEP4, ep4); err != nil {
+		t.Errorf("Failed to mark local endpoint %v ready, error: %v", ep4, err)
+	}
+	t.Run("traffic to ready endpoint should not be dropped", func(t *testing.T) {
+		Eventually(func() error {
+			return flowValidator([]string{ep4NotReadyDropFlow})
+		}, timeout, interval).ShouldNot(Succeed())
+		Eventually(func() error {
+			return flowValidator([]string{ep4NotReadyIPv6DropFlow})
+		}, timeout, interval).ShouldNot(Succeed())
+	})
+
+	if err := datapathManager.RemoveLocalEndpoint(readyEP4); err != nil {
+		t.Errorf("Failed to remove local endpoint %v, error: %v", ep4, err)
+	}
+}
+
 func testERPolicyRule(t *testing.T) {
 	t.Run("check policy rule work mode", func(t *testing.T) {
 		if err := datapathManager.AddEveroutePolicyRule(rule1, "rule1", POLICY_DIRECTION_IN, POLICY_TIER2, DEFAULT_POLICY_ENFORCEMENT_MODE); err != nil {
@@ -329,6 +418,140 @@ func testERPolicyRule(t *testing.T) {
 		}
 	})
 
+	t.Run("check policy rule match gre protocol", func(t *testing.T) {
+		RegisterTestingT(t)
+
+		rule := &EveroutePolicyRule{
+			RuleID:     rand.String(20),
+			Priority:   rand.IntnRange(DEFAULT_FLOW_MISS_PRIORITY, HIGH_MATCH_FLOW_PRIORITY),
+			SrcIPAddr:  randomIP(),
+			DstIPAddr:  randomIP(),
+			IPProtocol: PROTOCOL_GRE,
+			Action:     "allow",
+		}
+		err := datapathManager.AddEveroutePolicyRule(rule, rule.RuleID, POLICY_DIRECTION_IN, POLICY_TIER2, DEFAULT_POLICY_ENFORCEMENT_MODE)
+		Expect(err).ShouldNot(HaveOccurred())
+		Eventually(func() error {
+			flows, err := dumpAllFlows("ovsbr0-policy")
+			if err != nil {
+				return err
+			}
+			expectedMatch := fmt.Sprintf("table=%d, priority=%d,ip,nw_src=%s,nw_dst=%s,nw_proto=47 actions=",
+				INGRESS_TIER2_TABLE, rule.Priority, rule.SrcIPAddr, rule.DstIPAddr)
+			for _, flow := range flows {
+				if strings.HasPrefix(flow, expectedMatch) {
+					return nil
+				}
+			}
+			return fmt.Errorf("expected flow %s is not contains in current flow list\n: %v", expectedMatch, flows)
+		}, timeout, interval).ShouldNot(HaveOccurred())
+		err = datapathManager.RemoveEveroutePolicyRule(rule.RuleID, rule.RuleID)
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	t.Run("check policy rule match vlan id", func(t *testing.T) {
+		RegisterTestingT(t)
+
+		rule := &EveroutePolicyRule{
+			RuleID:     rand.String(20),
+			Priority:   rand.IntnRange(DEFAULT_FLOW_MISS_PRIORITY, HIGH_MATCH_FLOW_PRIORITY),
+			SrcIPAddr:  randomIP(),
+			IPProtocol: PROTOCOL_TCP,
+			DstPort:    80,
+			VlanID:     100,
+			Action:     "allow",
+		}
+		expectedMatch, err := ruleFlowMatch(rule)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(expectedMatch).Should(HaveKeyWithValue("dl_vlan", "100"))
+
+		err = datapathManager.AddEveroutePolicyRule(rule, rule.RuleID, POLICY_DIRECTION_IN, POLICY_TIER2, DEFAULT_POLICY_ENFORCEMENT_MODE)
+		Expect(err).ShouldNot(HaveOccurred())
+		Eventually(func() error {
+			flows, err := dumpAllFlows("ovsbr0-policy")
+			if err != nil {
+				return err
+			}
+			for _, flowStr := range flows {
+				flow, err := parseOfctlFlow(flowStr)
+				if err != nil || flow.TableID != INGRESS_TIER2_TABLE || flow.Priority != uint16(rule.Priority) {
+					continue
+				}
+				if reflect.DeepEqual(flow.Match, expectedMatch) {
+					return nil
+				}
+			}
+			return fmt.Errorf("expected flow match %v is not contains in current flow list\n: %v", expectedMatch, flows)
+		}, timeout, interval).ShouldNot(HaveOccurred())
+		err = datapathManager.RemoveEveroutePolicyRule(rule.RuleID, rule.RuleID)
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	t.Run("check policy rule match icmp type and code", func(t *testing.T) {
+		RegisterTestingT(t)
+
+		srcIP, dstIP := randomIP(), randomIP()
+		priority := rand.IntnRange(DEFAULT_FLOW_MISS_PRIORITY, HIGH_MATCH_FLOW_PRIORITY-1)
+		echoType, echoCode := uint8(8), uint8(0)
+		allowEcho := &EveroutePolicyRule{
+			RuleID:     rand.String(20),
+			Priority:   priority + 1,
+			SrcIPAddr:  srcIP,
+			DstIPAddr:  dstIP,
+			IPProtocol: PROTOCOL_ICMP,
+			ICMPType:   &echoType,
+			ICMPCode:   &echoCode,
+			Action:     "allow",
+		}
+		denyICMP := &EveroutePolicyRule{
+			RuleID:     rand.String(20),
+			Priority:   priority,
+			SrcIPAddr:  srcIP,
+			DstIPAddr:  dstIP,
+			IPProtocol: PROTOCOL_ICMP,
+			Action:     "deny",
+		}
+		for _, rule := range []*EveroutePolicyRule{allowEcho, denyICMP} {
+			err := datapathManager.AddEveroutePolicyRule(rule, rule.RuleID, POLICY_DIRECTION_IN, POLICY_TIER2, DEFAULT_POLICY_ENFORCEMENT_MODE)
+			Expect(err).ShouldNot(HaveOccurred())
+		}
+
+		hitFlowPriority := func(icmpType, icmpCode int) (int, error) {
+			flows, err := dumpAllFlows("ovsbr0-policy")
+			if err != nil {
+				return 0, err
+			}
+			packet := map[string]string{"ip": "", "icmp": "", "nw_src": srcIP, "nw_dst": dstIP,
+				"nw_proto": "1", "icmp_type": fmt.Sprint(icmpType), "icmp_code": fmt.Sprint(icmpCode)}
+			return lookupFlowPriority(flows, INGRESS_TIER2_TABLE, packet), nil
+		}
+
+		// echo request should match the allow rule, and icmp redirect should match the deny rule
+		Eventually(func() (int, error) { return hitFlowPriority(8, 0) }, timeout, interval).Should(Equal(allowEcho.Priority))
+		Eventually(func() (int, error) { return hitFlowPriority(5, 1) }, timeout, interval).Should(Equal(denyICMP.Priority))
+
+		for _, rule := range []*EveroutePolicyRule{allowEcho, denyICMP} {
+			err := datapathManager.RemoveEveroutePolicyRule(rule.RuleID, rule.RuleID)
+			Expect(err).ShouldNot(HaveOccurred())
+		}
+	})
+
+	t.Run("icmp type match should not supported by non icmp rule", func(t *testing.T) {
+		RegisterTestingT(t)
+
+		icmpType := uint8(8)
+		rule := &EveroutePolicyRule{
+			RuleID:     rand.String(20),
+			Priority:   rand.IntnRange(DEFAULT_FLOW_MISS_PRIORITY, HIGH_MATCH_FLOW_PRIORITY),
+			SrcIPAddr:  randomIP(),
+			IPProtocol: PROTOCOL_TCP,
+			ICMPType:   &icmpType,
+			Action:     "allow",
+		}
+		err := datapathManager.AddEveroutePolicyRule(rule, rule.RuleID, POLICY_DIRECTION_IN, POLICY_TIER2, DEFAULT_POLICY_ENFORCEMENT_MODE)
+		Expect(err).Should(HaveOccurred())
+	})
+
 	t.Run("check policy rule monitor mode", func(t *testing.T) {
 		RegisterTestingT(t)
 
@@ -445,6 +668,159 @@ func testPolicyTableInit(t *testing.T) {
 	})
 }
 
+func testTableMissAction(t *testing.T) {
+	failOpenFlow := "table=10, priority=300,ip actions=goto_table:70"
+	policyBridgeFlows := func() []string {
+		flows, err := dumpAllFlows("ovsbr0-policy")
+		Expect(err).ShouldNot(HaveOccurred())
+		return flows
+	}
+
+	t.Run("fail open should bypass policy tables", func(t *testing.T) {
+		Expect(datapathManager.SetTableMissAction("ovsbr0", TableMissFailOpen)).Should(Succeed())
+		Eventually(policyBridgeFlows, timeout, interval).Should(ContainElement(failOpenFlow))
+	})
+
+	t.Run("fail closed should evaluate packets with policy tables", func(t *testing.T) {
+		Expect(datapathManager.SetTableMissAction("ovsbr0", TableMissFailClosed)).Should(Succeed())
+		Eventually(policyBridgeFlows, timeout, interval).ShouldNot(ContainElement(failOpenFlow))
+	})
+}
+
+func testEstablishedShortcut(t *testing.T) {
+	estShortcutFlows := []string{
+		"table=1, priority=203,ct_state=-new+est actions=goto_table:2",
+		"table=1, priority=203,ct_state=-inv+rel+trk actions=goto_table:2",
+		"table=2, priority=200 actions=goto_table:70",
+	}
+	policyBridgeFlows := func() []string {
+		flows, err := dumpAllFlows("ovsbr0-policy")
+		Expect(err).ShouldNot(HaveOccurred())
+		return flows
+	}
+
+	t.Run("established and related packets should take the shortcut table", func(t *testing.T) {
+		Eventually(policyBridgeFlows, timeout, interval).Should(ContainElements(estShortcutFlows))
+	})
+
+	t.Run("rule flows should not be installed in the shortcut table", func(t *testing.T) {
+		rule := &EveroutePolicyRule{RuleID: "est-shortcut-rule", Priority: 200, SrcIPAddr: "10.100.201.1", Action: "allow"}
+		Expect(datapathManager.AddEveroutePolicyRule(rule, rule.RuleID, POLICY_DIRECTION_OUT, POLICY_TIER3, DEFAULT_POLICY_ENFORCEMENT_MODE)).Should(Succeed())
+		defer func() {
+			Expect(datapathManager.RemoveEveroutePolicyRule(rule.RuleID, rule.RuleID)).Should(Succeed())
+		}()
+		for _, flowEntry := range datapathManager.Rules[rule.RuleID].RuleFlowMap {
+			Expect(flowEntry.Table.TableId).ShouldNot(Equal(uint8(CT_ESTABLISHED_TABLE)))
+		}
+		for _, flow := range policyBridgeFlows() {
+			if strings.HasPrefix(flow, "table=2,") {
+				Expect(estShortcutFlows).Should(ContainElement(flow))
+			}
+		}
+	})
+}
+
+func testARPBlock(t *testing.T) {
+	arpBlockFlows := []string{
+		"table=0, priority=303,arp,arp_spa=10.100.202.1 actions=drop",
+		"table=0, priority=303,arp,arp_tpa=10.100.202.1 actions=drop",
+	}
+	policyBridgeFlows := func() []string {
+		flows, err := dumpAllFlows("ovsbr0-policy")
+		Expect(err).ShouldNot(HaveOccurred())
+		return flows
+	}
+	rule := &EveroutePolicyRule{RuleID: "arp-block-rule", Priority: 200, DstIPAddr: "10.100.202.1", Action: "deny"}
+
+	t.Run("arp of the endpoint should be dropped when rule blocks arp", func(t *testing.T) {
+		Expect(datapathManager.AddEveroutePolicyRules(context.Background(), []RuleSpec{{
+			Rule: rule, RuleName: rule.RuleID, Direction: POLICY_DIRECTION_IN, Tier: POLICY_TIER1,
+			Mode: DEFAULT_POLICY_ENFORCEMENT_MODE, BlockARP: true,
+		}})).Should(Succeed())
+		Eventually(policyBridgeFlows, timeout, interval).Should(ContainElements(arpBlockFlows))
+	})
+
+	t.Run("arp of the endpoint should be allowed after rule removed", func(t *testing.T) {
+		Expect(datapathManager.RemoveEveroutePolicyRule(rule.RuleID, rule.RuleID)).Should(Succeed())
+		Eventually(policyBridgeFlows, timeout, interval).ShouldNot(ContainElement(BeElementOf(arpBlockFlows)))
+	})
+}
+
+func testTCPRSTDetect(t *testing.T) {
+	policyBridge := datapathManager.BridgeChainMap["ovsbr0"][POLICY_BRIDGE_KEYWORD].(*PolicyBridge)
+	rstDenyFlow := "table=0, priority=306,ip,nw_src=10.0.0.1 actions=drop"
+	policyBridgeFlows := func() []string {
+		flows, err := dumpAllFlows("ovsbr0-policy")
+		Expect(err).ShouldNot(HaveOccurred())
+		return flows
+	}
+
+	t.Run("source under the rst threshold should not be denied", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			pkt := newIPv4PacketIn(11, nil)
+			pkt.TableId = CT_STATE_TABLE
+			policyBridge.PacketRcvd(policyBridge.OfSwitch, pkt)
+		}
+		Consistently(policyBridgeFlows, time.Second, interval).ShouldNot(ContainElement(rstDenyFlow))
+	})
+
+	t.Run("source exceeding the rst threshold should be denied temporarily", func(t *testing.T) {
+		pkt := newIPv4PacketIn(11, nil)
+		pkt.TableId = CT_STATE_TABLE
+		policyBridge.PacketRcvd(policyBridge.OfSwitch, pkt)
+		Eventually(policyBridgeFlows, timeout, interval).Should(ContainElement(rstDenyFlow))
+		Eventually(policyBridgeFlows, timeout, interval).ShouldNot(ContainElement(rstDenyFlow))
+	})
+}
+
+func testCTTimeoutPolicy(t *testing.T) {
+	t.Run("connections of rule with ct timeout should commit into zone with ct timeout policy", func(t *testing.T) {
+		rule := &EveroutePolicyRule{RuleID: "ct-timeout-rule", Priority: 200, IPProtocol: PROTOCOL_UDP, DstPort: 123,
+			DstPortMask: 0xffff, Action: EveroutePolicyAllow, CTTimeout: true}
+		if err := datapathManager.AddEveroutePolicyRule(rule, "ct-timeout-rule", POLICY_DIRECTION_IN, POLICY_TIER2, DEFAULT_POLICY_ENFORCEMENT_MODE); err != nil {
+			t.Fatalf("Failed to add rule %v with ct timeout, error: %v", rule, err)
+		}
+		defer func() {
+			if err := datapathManager.RemoveEveroutePolicyRule(rule.RuleID, "ct-timeout-rule"); err != nil {
+				t.Errorf("Failed to remove rule %v with ct timeout, error: %v", rule, err)
+			}
+		}()
+
+		flows, err := dumpAllFlows("ovsbr0-policy")
+		if err != nil {
+			t.Fatalf("Failed to dump policy bridge flows: %v", err)
+		}
+		var committed, lookedUp bool
+		for _, flow := range flows {
+			if strings.Contains(flow, fmt.Sprintf("ct(commit,table=%d,zone=%d", CT_DROP_TABLE, constants.CTZoneForPolicyCTTimeout)) {
+				committed = true
+			}
+			if strings.Contains(flow, fmt.Sprintf("ct(table=%d,zone=%d)", CT_STATE_TABLE, constants.CTZoneForPolicyCTTimeout)) {
+				lookedUp = true
+			}
+		}
+		if !committed || !lookedUp {
+			t.Fatalf("Failed to find ct lookup and commit flows of zone %d in %v", constants.CTZoneForPolicyCTTimeout, flows)
+		}
+
+		out, err := excuteCommand(fmt.Sprintf("ovs-vsctl list-zone-tp %s", ovsSystemDatapath))
+		if err != nil {
+			t.Fatalf("Failed to list ct timeout policy: %v", err)
+		}
+		for _, line := range strings.Split(string(out), "\n") {
+			if strings.Contains(line, fmt.Sprintf("Zone:%d,", constants.CTZoneForPolicyCTTimeout)) {
+				for _, attr := range []string{"udp_first=10", "udp_single=10", "udp_multiple=10"} {
+					if !strings.Contains(line, attr) {
+						t.Errorf("Expect ct timeout policy %s in %s", attr, line)
+					}
+				}
+				return
+			}
+		}
+		t.Errorf("Failed to find ct timeout policy of zone %d in %s", constants.CTZoneForPolicyCTTimeout, string(out))
+	})
+}
+
 func testMonitorRule(t *testing.T) {
 	t.Run("test ER policy rule with monitor mode", func(t *testing.T) {
 		if err := datapathManager.AddEveroutePolicyRule(rule1, "rule1", POLICY_DIRECTION_IN, POLICY_TIER2, v1alpha1.MonitorMode.String()); err != nil {
@@ -473,6 +849,32 @@ func testMonitorRule(t *testing.T) {
 	})
 }
 
+func testTableLayout(t *testing.T) {
+	t.Run("rule flows should be installed in tables of the tier layout", func(t *testing.T) {
+		for i, tierTable := range datapathManager.GetTableLayout().TierTables {
+			rule := &EveroutePolicyRule{
+				RuleID:    fmt.Sprintf("layout-rule-%d", i),
+				Priority:  200,
+				SrcIPAddr: fmt.Sprintf("10.100.200.%d", i+1),
+				Action:    "allow",
+			}
+			err := datapathManager.AddEveroutePolicyRule(rule, rule.RuleID, uint8(tierTable.Direction), uint8(tierTable.Tier), tierTable.Mode)
+			if err != nil {
+				t.Fatalf("Failed to add ER policy rule: %v, error: %v", rule, err)
+			}
+			for vdsID, flowEntry := range datapathManager.Rules[rule.RuleID].RuleFlowMap {
+				if flowEntry.Table.TableId != uint8(tierTable.Table) {
+					t.Errorf("expect rule %s of layout %+v installed in table %d of vds %s, got %d",
+						rule.RuleID, tierTable, tierTable.Table, vdsID, flowEntry.Table.TableId)
+				}
+			}
+			if err := datapathManager.RemoveEveroutePolicyRule(rule.RuleID, rule.RuleID); err != nil {
+				t.Errorf("Failed to remove ER policy rule: %v, error: %v", rule, err)
+			}
+		}
+	})
+}
+
 func testFlowReplay(t *testing.T) {
 	RegisterTestingT(t)
 
@@ -520,13 +922,13 @@ func testRoundNumFlip(t *testing.T) {
 
 	t.Run("persistentRoundInfo into local bridge", func(t *testing.T) {
 		Eventually(func() error {
-			return persistentRoundInfo(roundInfo.curRoundNum, datapathManager.OvsdbDriverMap["ovsbr0"][LOCAL_BRIDGE_KEYWORD])
+			return persistentRoundInfo(roundInfo.curRoundNum, datapathManager.OvsdbDriverMap["ovsbr0"][LOCAL_BRIDGE_KEYWORD], defaultFlowCookie)
 		}, timeout, interval).Should(Succeed())
 	})
 
 	t.Run("validate ER agent Round num flip", func(t *testing.T) {
 		Eventually(func() bool {
-			round, _ := getRoundInfo(datapathManager.OvsdbDriverMap["ovsbr0"][LOCAL_BRIDGE_KEYWORD])
+			round, _ := getRoundInfo(datapathManager.OvsdbDriverMap["ovsbr0"][LOCAL_BRIDGE_KEYWORD], defaultFlowCookie)
 			return round.curRoundNum == 1
 		}, timeout, interval).Should(BeTrue())
 	})
@@ -666,6 +1068,29 @@ func dumpAllFlows(bridges ...string) ([]string, error) {
 	return flowDump, nil
 }
 
+// lookupFlowPriority returns priority of the flow in the table which the packet would hit, packet is
+// described by match fields in ovs-ofctl format, return -1 if none flow matches
+func lookupFlowPriority(flows []string, tableID uint8, packet map[string]string) int {
+	hitPriority := -1
+	for _, flowStr := range flows {
+		flow, err := parseOfctlFlow(flowStr)
+		if err != nil || flow.TableID != tableID || int(flow.Priority) <= hitPriority {
+			continue
+		}
+		matched := true
+		for field, value := range flow.Match {
+			if packetValue, ok := packet[field]; !ok || packetValue != value {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			hitPriority = int(flow.Priority)
+		}
+	}
+	return hitPriority
+}
+
 func testLocalEndpointOverlay(t *testing.T) {
 	RegisterTestingT(t)
 	ep1Copy := copyEp(ep1)
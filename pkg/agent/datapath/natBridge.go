@@ -86,6 +86,8 @@ type NatBridge struct {
 	svcIndexCache *cache.SvcIndex // service flow and group database
 	// l3FlowMap the key is interface uuid, the value is l3ForwardTable flow
 	l3FlowMap map[string]*ofctrl.Flow
+	// hairpinFlowMap the key is interface uuid, the value is hairpin snat flow in l3ForwardTable
+	hairpinFlowMap map[string]*ofctrl.Flow
 
 	kubeProxyReplace bool
 }
@@ -107,6 +109,7 @@ func (n *NatBridge) BridgeInitCNI() {
 	}
 	n.svcIndexCache = cache.NewSvcIndex()
 	n.l3FlowMap = make(map[string]*ofctrl.Flow)
+	n.hairpinFlowMap = make(map[string]*ofctrl.Flow)
 	n.kubeProxyReplace = n.datapathManager.Config.CNIConfig.KubeProxyReplace
 
 	sw := n.OfSwitch
@@ -206,6 +209,10 @@ func (n *NatBridge) AddLocalEndpoint(endpoint *Endpoint) error {
 	}
 	n.l3FlowMap[endpoint.InterfaceUUID] = flow
 	log.Infof("Nat bridge success add flow %+v for local endpoint interfaceUUID %s", flow, endpoint.InterfaceUUID)
+
+	if n.kubeProxyReplace {
+		return n.addHairpinFlow(endpoint)
+	}
 	return nil
 }
 
@@ -222,9 +229,58 @@ func (n *NatBridge) RemoveLocalEndpoint(endpoint *Endpoint) error {
 	}
 	delete(n.l3FlowMap, endpoint.InterfaceUUID)
 	log.Infof("Nat bridge success delete l3 forward flow for local endpoint interfaceUUID %s", endpoint.InterfaceUUID)
+
+	if flow, ok := n.hairpinFlowMap[endpoint.InterfaceUUID]; ok && flow != nil {
+		if err := flow.Delete(); err != nil {
+			log.Errorf("Delete endpoint correspond hairpin flow failed, endpoint: %+v, err: %s", endpoint, err)
+			return err
+		}
+	}
+	delete(n.hairpinFlowMap, endpoint.InterfaceUUID)
 	return nil
 }
 
+// addHairpinFlow snat the pod->svc->self traffic to gateway ip, otherwise the pod would drop the reply
+// whose source and destination are both itself
+func (n *NatBridge) addHairpinFlow(endpoint *Endpoint) error {
+	if n.hairpinFlowMap[endpoint.InterfaceUUID] != nil {
+		return nil
+	}
+
+	flow, err := n.l3ForwardTable.NewFlow(hairpinFlowMatch(endpoint.IPAddr))
+	if err != nil {
+		log.Errorf("Failed to new a hairpin flow in l3Forward table %d for endpoint %+v, err: %s", NatBrL3ForwardTable, endpoint, err)
+		return err
+	}
+	_ = flow.SetConntrack(hairpinSNATAction(n.datapathManager.Info.GatewayIP))
+	if err := flow.Next(ofctrl.NewEmptyElem()); err != nil {
+		log.Errorf("Failed to install hairpin flow %+v, endpoint: %+v, err: %s", flow, endpoint, err)
+		return err
+	}
+
+	n.hairpinFlowMap[endpoint.InterfaceUUID] = flow
+	log.Infof("Nat bridge success add hairpin flow %+v for local endpoint interfaceUUID %s", flow, endpoint.InterfaceUUID)
+	return nil
+}
+
+// hairpinFlowMatch matches the traffic from the endpoint to itself after dnat
+func hairpinFlowMatch(endpointIP net.IP) ofctrl.FlowMatch {
+	return ofctrl.FlowMatch{
+		Priority:  HIGH_MATCH_FLOW_PRIORITY,
+		Ethertype: PROTOCOL_IP,
+		IpSa:      &endpointIP,
+		IpDa:      &endpointIP,
+	}
+}
+
+// hairpinSNATAction commits the hairpin traffic in a separate ct zone with snat to gateway ip, the traffic
+// resubmit to l3ForwardTable again and forward to the endpoint
+func hairpinSNATAction(gatewayIP net.IP) *ofctrl.ConnTrackAction {
+	var zone uint16 = constants.CTZoneNatBrHairpin
+	natAct, _ := ofctrl.NewSNatAction(ofctrl.NewIPRange(gatewayIP), nil).ToOfAction()
+	return ofctrl.NewConntrackAction(true, false, &NatBrL3ForwardTable, &zone, natAct)
+}
+
 func (n *NatBridge) GetSvcIndexCache() *cache.SvcIndex {
 	return n.svcIndexCache
 }
@@ -886,6 +942,29 @@ func (n *NatBridge) initCTZoneTable() error {
 		log.Errorf("Failed to install flow in CTZone table %d: %s", NatBrCTZoneTable, err)
 		return err
 	}
+
+	if n.kubeProxyReplace {
+		// un-snat the reply of hairpin traffic to gateway ip before the normal ct
+		var zone uint16 = constants.CTZoneNatBrHairpin
+		ctState := openflow13.NewCTStates()
+		ctState.UnsetTrk()
+		hairpinFlow, err := n.ctZoneTable.NewFlow(ofctrl.FlowMatch{
+			Priority:  HIGH_MATCH_FLOW_PRIORITY,
+			Ethertype: PROTOCOL_IP,
+			IpDa:      &n.datapathManager.Info.GatewayIP,
+			CtStates:  ctState,
+		})
+		if err != nil {
+			log.Errorf("Failed to new a hairpin flow in CTZone table %d: %s", NatBrCTZoneTable, err)
+			return err
+		}
+		natAct, _ := ofctrl.NewNatAction().ToOfAction()
+		_ = hairpinFlow.SetConntrack(ofctrl.NewConntrackAction(false, false, &NatBrCTZoneTable, &zone, natAct))
+		if err = hairpinFlow.Next(ofctrl.NewEmptyElem()); err != nil {
+			log.Errorf("Failed to install hairpin flow in CTZone table %d: %s", NatBrCTZoneTable, err)
+			return err
+		}
+	}
 	return nil
 }
 
@@ -1045,7 +1124,7 @@ func (n *NatBridge) buildLearnActOfSessionAffinityLearnTable(ipProto uint8, lear
 	backendPortField := ofctrl.LearnField{Name: BackendPortReg, Start: 0}
 	chooseBackendFlagField := ofctrl.LearnField{Name: ChooseBackendFlagReg, Start: uint16(ChooseBackendFlagStart)}
 
-	cookieID, err := getLearnCookieID()
+	cookieID, err := getLearnCookieID(n.datapathManager.flowCookie().FlowSeqBits)
 	if err != nil {
 		return nil, err
 	}
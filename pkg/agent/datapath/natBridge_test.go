@@ -0,0 +1,65 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datapath
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/contiv/libOpenflow/openflow13"
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/everoute/pkg/constants"
+)
+
+func TestHairpinFlow(t *testing.T) {
+	RegisterTestingT(t)
+
+	endpointIP := net.ParseIP("10.0.0.5")
+	gatewayIP := net.ParseIP("10.0.0.1")
+
+	t.Run("hairpin flow should match traffic from the endpoint to itself", func(t *testing.T) {
+		match := hairpinFlowMatch(endpointIP)
+		Expect(match.Priority).Should(BeNumerically(">", MID_MATCH_FLOW_PRIORITY))
+		Expect(match.Ethertype).Should(Equal(uint16(PROTOCOL_IP)))
+		Expect(match.IpSa).ShouldNot(BeNil())
+		Expect(match.IpDa).ShouldNot(BeNil())
+		Expect(match.IpSa.Equal(endpointIP)).Should(BeTrue())
+		Expect(match.IpDa.Equal(endpointIP)).Should(BeTrue())
+		Expect(match.IpSaMask).Should(BeNil())
+		Expect(match.IpDaMask).Should(BeNil())
+	})
+
+	t.Run("hairpin flow should commit in hairpin ct zone with snat to gateway ip", func(t *testing.T) {
+		ctAction := hairpinSNATAction(gatewayIP)
+		action, err := ctAction.ToOfAction()
+		Expect(err).ShouldNot(HaveOccurred())
+		ct := action.(*openflow13.NXActionConnTrack)
+		Expect(ct.Flags & openflow13.NX_CT_F_COMMIT).ShouldNot(BeZero())
+		Expect(ct.ZoneSrc).Should(BeZero())
+		Expect(ct.ZoneOfsNbits).Should(Equal(uint16(constants.CTZoneNatBrHairpin)))
+		Expect(ct.RecircTable).Should(Equal(NatBrL3ForwardTable))
+
+		Expect(ctAction.Actions).Should(HaveLen(1))
+		nat := ctAction.Actions[0].(*openflow13.NXActionCTNAT)
+		Expect(nat.Flags & openflow13.NX_NAT_F_SRC).ShouldNot(BeZero())
+		data, err := nat.MarshalBinary()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(bytes.Contains(data, gatewayIP.To4())).Should(BeTrue())
+	})
+}
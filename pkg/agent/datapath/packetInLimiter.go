@@ -0,0 +1,111 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datapath
+
+import (
+	"sync"
+
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/utils/clock"
+
+	"github.com/everoute/everoute/pkg/agent/metrics"
+)
+
+const (
+	DefaultPacketInRate          = 1000
+	DefaultPacketInBurst         = 2000
+	DefaultLearningPacketInRate  = 1000
+	DefaultLearningPacketInBurst = 2000
+)
+
+// PacketInLimitConfig limits packet-in of each reason from each bridge to the controller to Rate per second
+// with Burst, packet-in of learning is limited to LearningRate per second with LearningBurst separately,
+// packet-in exceeding the limit would be dropped.
+type PacketInLimitConfig struct {
+	Rate          float32
+	Burst         int
+	LearningRate  float32
+	LearningBurst int
+}
+
+func defaultPacketInLimitConfig() *PacketInLimitConfig {
+	return &PacketInLimitConfig{
+		Rate:          DefaultPacketInRate,
+		Burst:         DefaultPacketInBurst,
+		LearningRate:  DefaultLearningPacketInRate,
+		LearningBurst: DefaultLearningPacketInBurst,
+	}
+}
+
+// limitOf returns the rate and burst of packet-in of the reason
+func (c *PacketInLimitConfig) limitOf(reason PacketInReason) (float32, int) {
+	if reason == PacketInLearning {
+		return c.LearningRate, c.LearningBurst
+	}
+	return c.Rate, c.Burst
+}
+
+// packetInLimiter limits packet-in of each reason from each bridge, it's the same limit as the controller
+// meters installed on bridges, packets would be limited in the agent if the datapath doesn't support meters.
+type packetInLimiter struct {
+	config *PacketInLimitConfig
+	clock  clock.PassiveClock
+	metric *metrics.AgentMetric
+
+	lock     sync.Mutex
+	limiters map[packetInLimiterKey]flowcontrol.PassiveRateLimiter
+}
+
+type packetInLimiterKey struct {
+	bridge string
+	reason PacketInReason
+}
+
+func newPacketInLimiter(config *PacketInLimitConfig, c clock.PassiveClock, metric *metrics.AgentMetric) *packetInLimiter {
+	if config == nil {
+		config = defaultPacketInLimitConfig()
+	}
+	return &packetInLimiter{
+		config:   config,
+		clock:    c,
+		metric:   metric,
+		limiters: make(map[packetInLimiterKey]flowcontrol.PassiveRateLimiter),
+	}
+}
+
+// allow returns false and counts the packet-in dropped if packet-in of the reason exceeds the limit of the bridge
+func (l *packetInLimiter) allow(bridge string, reason PacketInReason) bool {
+	if l.limiterOf(bridge, reason).TryAccept() {
+		return true
+	}
+	l.metric.IncPacketInDropped(bridge, reason.String())
+	return false
+}
+
+func (l *packetInLimiter) limiterOf(bridge string, reason PacketInReason) flowcontrol.PassiveRateLimiter {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	key := packetInLimiterKey{bridge: bridge, reason: reason}
+	limiter, ok := l.limiters[key]
+	if !ok {
+		rate, burst := l.config.limitOf(reason)
+		limiter = flowcontrol.NewTokenBucketPassiveRateLimiterWithClock(rate, burst, l.clock)
+		l.limiters[key] = limiter
+	}
+	return limiter
+}
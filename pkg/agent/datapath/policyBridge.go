@@ -4,11 +4,18 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"os/exec"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/contiv/libOpenflow/openflow13"
+	"github.com/contiv/libOpenflow/protocol"
+	"github.com/contiv/libOpenflow/util"
 	"github.com/contiv/ofnet/ofctrl"
 	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/everoute/everoute/pkg/constants"
 )
@@ -17,6 +24,7 @@ import (
 const (
 	INPUT_TABLE                 = 0
 	CT_STATE_TABLE              = 1
+	CT_ESTABLISHED_TABLE        = 2
 	DIRECTION_SELECTION_TABLE   = 10
 	EGRESS_TIER1_TABLE          = 20
 	EGRESS_TIER2_MONITOR_TABLE  = 24
@@ -45,6 +53,37 @@ const (
 	MonitorTier3FlowSpaceXXREG0BitSize  = MonitorTier3FlowSpaceXXREG0BitEnd - MonitorTier3FlowSpaceXXREG0BitStart + 1
 	WorkPolicyActionXXREG0Bit           = 127 // codepoint6
 	MonitorTier3PolicyActionXXREG0Bit   = 126 // codepoint5
+
+	// OVS can't match ip options, packets matched ip options rule would be sent to controller for
+	// inspection, packets without ip options are resubmitted with the inspected mark in reg5.
+	IPOptionsInspectedReg      = 5
+	IPOptionsInspectedRegField = "nxm_nx_reg5"
+	IPv4HeaderMinIHL           = 5
+
+	// packets denied by reject rule are marked in reg4 besides the deny action, the ct drop table sends
+	// them to controller, which replies tcp rst or icmp unreachable to the source.
+	PolicyRejectReg4Bit = 16
+
+	// packets decided by work mode rule flows recording decisions or logging are marked in reg4, the ct
+	// commit table sends the first packet of the connection marked to controller through the meter, the
+	// decided rule flow is identified by the round num and work flow sequence in xxreg0.
+	PolicyPuntReg4Bit = 17
+	// packets decided by work mode rule flows enabled logging are marked in reg4 besides the punt mark, rules
+	// logging at a sample rate resubmit packets to the select group of the rate, which marks the sampled
+	// connections, connections are selected by hash of the flow.
+	PolicyLogReg4Bit           = 18
+	PolicyLogSampleGroupIDBase = 0x10000
+	// packets decided by allow rule flows with ct timeout policy are marked in reg4, the ct commit table
+	// commits them into the ct timeout zone. Packets not established in the policy zone are looked up again
+	// in the ct timeout zone, they are marked in reg4 so that would be looked up only once.
+	PolicyCTTimeoutReg4Bit       = 19
+	PolicyCTTimeoutLookupReg4Bit = 20
+	// ovs extension command of group mod creates the group or modifies the existing one
+	groupModCommandAddOrModify = 0x8000
+
+	// conntrack zone derived from vlan is carried in reg7 when assign conntrack zone by vlan
+	PolicyCTZoneReg = "nxm_nx_reg7"
+	VlanIDMask      = 0x0fff
 )
 
 var (
@@ -58,6 +97,15 @@ var (
 	MonitorTier3FlowSpaceNXRange    = openflow13.NewNXRange(MonitorTier3FlowSpaceXXREG0BitStart, MonitorTier3FlowSpaceXXREG0BitEnd)
 	WorkPolicyActionNXRange         = openflow13.NewNXRange(WorkPolicyActionXXREG0Bit, WorkPolicyActionXXREG0Bit)
 	MonitorTier3PolicyActionNXRange = openflow13.NewNXRange(MonitorTier3PolicyActionXXREG0Bit, MonitorTier3PolicyActionXXREG0Bit)
+	IPOptionsInspectedNXRange       = openflow13.NewNXRange(0, 0)
+	PolicyRejectNXRange             = openflow13.NewNXRange(PolicyRejectReg4Bit, PolicyRejectReg4Bit)
+	PolicyPuntNXRange               = openflow13.NewNXRange(PolicyPuntReg4Bit, PolicyPuntReg4Bit)
+	PolicyLogNXRange                = openflow13.NewNXRange(PolicyLogReg4Bit, PolicyLogReg4Bit)
+	PolicyPuntLogNXRange            = openflow13.NewNXRange(PolicyPuntReg4Bit, PolicyLogReg4Bit)
+	PolicyCTTimeoutNXRange          = openflow13.NewNXRange(PolicyCTTimeoutReg4Bit, PolicyCTTimeoutReg4Bit)
+	PolicyCTTimeoutLookupNXRange    = openflow13.NewNXRange(PolicyCTTimeoutLookupReg4Bit, PolicyCTTimeoutLookupReg4Bit)
+	PolicyCTZoneNXRange             = openflow13.NewNXRange(0, 15)
+	PolicyCTZoneVlanBaseNXRange     = openflow13.NewNXRange(12, 15)
 )
 
 type PolicyBridge struct {
@@ -65,6 +113,7 @@ type PolicyBridge struct {
 
 	inputTable                     *ofctrl.Table
 	ctStateTable                   *ofctrl.Table
+	ctEstablishedTable             *ofctrl.Table
 	directionSelectionTable        *ofctrl.Table
 	egressTier1PolicyTable         *ofctrl.Table
 	egressTier2PolicyMonitorTable  *ofctrl.Table
@@ -82,26 +131,271 @@ type PolicyBridge struct {
 	ctDropTable                    *ofctrl.Table
 	sfcPolicyTable                 *ofctrl.Table
 	policyForwardingTable          *ofctrl.Table
+	policyTables                   map[uint8]*ofctrl.Table // map table id to policy tables of all tiers, including custom tiers
+	ruleTablesMutex                sync.RWMutex
+	ruleTables                     map[uint8]bool         // tables which policy rule flows installed in, replaced on tier reload
+	tierDefaultFlows               map[uint8]*ofctrl.Flow // map table id to default flow of the tier table
+
+	notReadyEndpointFlow map[string][]*ofctrl.Flow // map not ready endpoint interface uuid to its drop flows
+
+	ruleTableFlowsMutex     sync.Mutex
+	observingRuleTableFlows map[uint64]*FlowEntry  // rule table flows received for the running flow stats request
+	ruleTableFlows          map[uint64]*FlowEntry  // rule table flows of the last flow stats request
+	ruleFlowStats           map[uint64]flowCounter // latest hit counters of rule table flows
+
+	barrierMutex   sync.Mutex
+	barrierWaiters map[uint32]chan struct{} // map xid of the desc request following barrier to its waiter
+
+	tableMissAction TableMissAction
+	failOpenFlow    *ofctrl.Flow // flow bypass policy tables when table-miss action is fail-open
+
+	rstDetector  *rstDetector // nil if tcp rst detection disabled
+	rstDenyMutex sync.Mutex
+	rstDenyFlows map[string]*ofctrl.Flow // map source denied for sending rst to its drop flow
+
+	arpBlockIPs   sets.Set[string]          // endpoint ip addresses whose ARP and ND are denied
+	arpBlockFlows map[string][]*ofctrl.Flow // map endpoint ip address to its ARP and ND drop flows
+
+	logSampleGroupMutex sync.Mutex
+	logSampleGroups     map[uint32]*ofctrl.Group // map group id to select group sampling logging at the rate
 }
 
+// TableMissAction is the action of the policy bridge for packets not decided by policy rules
+type TableMissAction string
+
+const (
+	// TableMissFailClosed evaluates packets with policy rules, it's the default table-miss action
+	TableMissFailClosed TableMissAction = "FailClosed"
+	// TableMissFailOpen forwards packets bypass policy rules, e.g. for the vds under maintenance
+	TableMissFailOpen TableMissAction = "FailOpen"
+)
+
 func NewPolicyBridge(brName string, datapathManager *DpManager) *PolicyBridge {
 	policyBridge := new(PolicyBridge)
 	policyBridge.name = fmt.Sprintf("%s-policy", brName)
 	policyBridge.datapathManager = datapathManager
+	policyBridge.notReadyEndpointFlow = make(map[string][]*ofctrl.Flow)
+	policyBridge.observingRuleTableFlows = make(map[uint64]*FlowEntry)
+	policyBridge.ruleTableFlows = make(map[uint64]*FlowEntry)
+	policyBridge.ruleFlowStats = make(map[uint64]flowCounter)
+	policyBridge.barrierWaiters = make(map[uint32]chan struct{})
+	policyBridge.setRuleTables(datapathManager.policyTiers().ruleTables())
+	policyBridge.tableMissAction = TableMissFailClosed
+	policyBridge.rstDenyFlows = make(map[string]*ofctrl.Flow)
+	policyBridge.arpBlockIPs = sets.New[string]()
+	policyBridge.arpBlockFlows = make(map[string][]*ofctrl.Flow)
+	if datapathManager.Config.TCPRSTDetect != nil {
+		policyBridge.rstDetector = newRSTDetector(datapathManager.Config.TCPRSTDetect)
+	}
 	return policyBridge
 }
 
 func (p *PolicyBridge) PacketRcvd(sw *ofctrl.OFSwitch, pkt *ofctrl.PacketIn) {
+	reason, ok := p.packetInReason(pkt)
+	if !ok || !p.datapathManager.packetInLimiter.allow(p.name, reason) {
+		return
+	}
+
+	switch reason {
+	case PacketInRSTDetect:
+		p.processRSTPacket(pkt)
+		return
+	case PacketInReject:
+		if packetOut := rejectPacket(pkt); packetOut != nil {
+			sw.Send(packetOut)
+		}
+		return
+	case PacketInPolicyLogging:
+		p.datapathManager.recordDecision(p.name, p.datapathManager.flowCookie().puntedRuleFlowID(pkt), pkt)
+		return
+	}
+
+	// the packet resumes in the rule table with conntrack looked up again, the zone is restored in reg7
+	var tableID = pkt.TableId
+	ctAction, err := p.newPolicyConntrackAction(false, &tableID)
+	if err != nil {
+		log.Errorf("Failed to new ct action for packet with ip options matched rule flow %#x: %s", pkt.Cookie, err)
+		return
+	}
+	resumeAction, _ := ctAction.ToOfAction()
+	packetOut := inspectIPOptions(pkt, resumeAction)
+	if packetOut == nil {
+		log.Debugf("Drop packet with ip options matched rule flow %#x", pkt.Cookie)
+		return
+	}
+	sw.Send(packetOut)
+}
+
+// packetInReason returns the feature which sent the packet to controller
+func (p *PolicyBridge) packetInReason(pkt *ofctrl.PacketIn) (PacketInReason, bool) {
+	switch {
+	case pkt.TableId == CT_STATE_TABLE:
+		// tcp rst sample flow in ct state table sends copy of rst of established connections to controller
+		return PacketInRSTDetect, true
+	case pkt.TableId == CT_DROP_TABLE:
+		// reject flow in ct drop table sends packet denied by reject rule to controller
+		return PacketInReject, true
+	case pkt.TableId == CT_COMMIT_TABLE:
+		// punt flow in ct commit table sends copy of the first packet of connection marked by rule flows
+		// to controller for decision recording and logging
+		return PacketInPolicyLogging, true
+	case !p.getRuleTables()[pkt.TableId]:
+		return 0, false
+	default:
+		// ip options rule flows send packet to controller for inspection
+		return PacketInIPOptions, true
+	}
+}
+
+// inspectIPOptions returns nil if the packet has ip options and should be dropped, otherwise returns the
+// packet out which resumes the packet with the resume action, e.g. conntrack lookup recirculated to the rule
+// table sent the packet, registers of the pipeline are restored, the packet would skip ip options rules.
+func inspectIPOptions(pkt *ofctrl.PacketIn, resumeAction openflow13.Action) *openflow13.PacketOut {
+	ipv4, ok := pkt.Data.Data.(*protocol.IPv4)
+	if !ok || ipv4.IHL > IPv4HeaderMinIHL {
+		return nil
+	}
+
+	inspectedField, _ := openflow13.FindFieldHeaderByName(IPOptionsInspectedRegField, false)
+
+	packetOut := openflow13.NewPacketOut()
+	packetOut.InPort = packetInPort(pkt)
+	for _, regLoad := range packetInRegLoads(pkt) {
+		packetOut.AddAction(regLoad)
+	}
+	packetOut.AddAction(openflow13.NewNXActionRegLoad(IPOptionsInspectedNXRange.ToOfsBits(), inspectedField, 0x1))
+	packetOut.AddAction(resumeAction)
+	packetOut.Data = &pkt.Data
+	return packetOut
+}
+
+// packetInRegLoads returns actions which load registers of the packet in, so that the packet out continues
+// the pipeline with the same registers
+func packetInRegLoads(pkt *ofctrl.PacketIn) []openflow13.Action {
+	var regLoads []openflow13.Action
+	for _, field := range pkt.Match.Fields {
+		if field.Class != openflow13.OXM_CLASS_NXM_1 || field.Field > openflow13.NXM_NX_REG15 {
+			continue
+		}
+		value, ok := field.Value.(*openflow13.Uint32Message)
+		if !ok || value.Data == 0 {
+			continue
+		}
+		regField, err := openflow13.FindFieldHeaderByName(fmt.Sprintf("nxm_nx_reg%d", field.Field), false)
+		if err != nil {
+			continue
+		}
+		regLoads = append(regLoads, openflow13.NewNXActionRegLoad(openflow13.NewNXRange(0, 31).ToOfsBits(), regField, uint64(value.Data)))
+	}
+	return regLoads
 }
 
 func (p *PolicyBridge) MultipartReply(sw *ofctrl.OFSwitch, rep *openflow13.MultipartReply) {
+	if rep.Type == openflow13.MultipartType_Desc {
+		p.barrierReplied(rep.Xid)
+		return
+	}
+	if rep.Type != openflow13.MultipartType_Flow {
+		return
+	}
+	for _, body := range rep.Body {
+		if flowStats, ok := body.(*openflow13.FlowStats); ok {
+			p.datapathManager.AgentMetric.UpdateRuleFlowStats(flowStats.Cookie, flowStats.PacketCount)
+			p.observeRuleTableFlow(sw, flowStats)
+		}
+	}
+}
+
+// observeRuleTableFlow records flow which may be installed by policy rule, table default flows
+// and ct label match flows are installed on bridge init and would be skipped.
+func (p *PolicyBridge) observeRuleTableFlow(sw *ofctrl.OFSwitch, flowStats *openflow13.FlowStats) {
+	if !p.getRuleTables()[flowStats.TableId] || flowStats.Priority == DEFAULT_FLOW_MISS_PRIORITY {
+		return
+	}
+	for _, field := range flowStats.Match.Fields {
+		if field.Class == openflow13.OXM_CLASS_NXM_1 && field.Field == openflow13.NXM_NX_CT_LABEL {
+			return
+		}
+	}
+
+	p.ruleTableFlowsMutex.Lock()
+	defer p.ruleTableFlowsMutex.Unlock()
+	p.observingRuleTableFlows[flowStats.Cookie] = &FlowEntry{
+		Table:    &ofctrl.Table{Switch: sw, TableId: flowStats.TableId},
+		Priority: flowStats.Priority,
+		FlowID:   flowStats.Cookie,
+	}
+	p.ruleFlowStats[flowStats.Cookie] = flowCounter{PacketCount: flowStats.PacketCount, ByteCount: flowStats.ByteCount}
+}
+
+// completeRuleTableFlows should be called before sending a new flow stats request, flows
+// received for the last request would be taken as the current rule table flows.
+func (p *PolicyBridge) completeRuleTableFlows() {
+	p.ruleTableFlowsMutex.Lock()
+	defer p.ruleTableFlowsMutex.Unlock()
+	p.ruleTableFlows = p.observingRuleTableFlows
+	p.observingRuleTableFlows = make(map[uint64]*FlowEntry)
+	for flowID := range p.ruleFlowStats {
+		if _, ok := p.ruleTableFlows[flowID]; !ok {
+			delete(p.ruleFlowStats, flowID)
+		}
+	}
+}
+
+// getRuleFlowStats returns the latest hit counters of rule table flows
+func (p *PolicyBridge) getRuleFlowStats() map[uint64]flowCounter {
+	p.ruleTableFlowsMutex.Lock()
+	defer p.ruleTableFlowsMutex.Unlock()
+
+	stats := make(map[uint64]flowCounter, len(p.ruleFlowStats))
+	for flowID, counter := range p.ruleFlowStats {
+		stats[flowID] = counter
+	}
+	return stats
+}
+
+// forgetRuleTableFlow removes the deleted flow from the flow stats, so that it wouldn't be taken as leaked
+// before the next flow stats request completed
+func (p *PolicyBridge) forgetRuleTableFlow(flowID uint64) {
+	p.ruleTableFlowsMutex.Lock()
+	defer p.ruleTableFlowsMutex.Unlock()
+	delete(p.observingRuleTableFlows, flowID)
+	delete(p.ruleTableFlows, flowID)
+	delete(p.ruleFlowStats, flowID)
+}
+
+func (p *PolicyBridge) getRuleTableFlows() []*FlowEntry {
+	p.ruleTableFlowsMutex.Lock()
+	defer p.ruleTableFlowsMutex.Unlock()
+
+	flows := make([]*FlowEntry, 0, len(p.ruleTableFlows))
+	for _, flow := range p.ruleTableFlows {
+		flows = append(flows, flow)
+	}
+	sort.Slice(flows, func(i, j int) bool { return flows[i].FlowID < flows[j].FlowID })
+	return flows
 }
 
 func (p *PolicyBridge) BridgeInit() {
 	sw := p.OfSwitch
 
+	// flows installed before bridge reconnect have been flushed, they would be rebuilt by replay
+	p.notReadyEndpointFlow = make(map[string][]*ofctrl.Flow)
+	p.arpBlockFlows = make(map[string][]*ofctrl.Flow)
+	p.logSampleGroupMutex.Lock()
+	p.logSampleGroups = make(map[uint32]*ofctrl.Group)
+	p.logSampleGroupMutex.Unlock()
+	p.ruleTableFlowsMutex.Lock()
+	p.observingRuleTableFlows = make(map[uint64]*FlowEntry)
+	p.ruleTableFlows = make(map[uint64]*FlowEntry)
+	p.ruleTableFlowsMutex.Unlock()
+
+	installControllerMeters(sw, p.datapathManager.packetInLimiter.config,
+		PacketInPolicyLogging, PacketInReject, PacketInIPOptions, PacketInRSTDetect)
+
 	p.inputTable = sw.DefaultTable()
 	p.ctStateTable, _ = sw.NewTable(CT_STATE_TABLE)
+	p.ctEstablishedTable, _ = sw.NewTable(CT_ESTABLISHED_TABLE)
 	p.directionSelectionTable, _ = sw.NewTable(DIRECTION_SELECTION_TABLE)
 	p.ingressTier1PolicyTable, _ = sw.NewTable(INGRESS_TIER1_TABLE)
 	p.ingressTier2PolicyMonitorTable, _ = sw.NewTable(INGRESS_TIER2_MONITOR_TABLE)
@@ -119,6 +413,13 @@ func (p *PolicyBridge) BridgeInit() {
 	p.ctDropTable, _ = sw.NewTable(CT_DROP_TABLE)
 	p.sfcPolicyTable, _ = sw.NewTable(SFC_POLICY_TABLE)
 	p.policyForwardingTable, _ = sw.NewTable(POLICY_FORWARDING_TABLE)
+	ruleTables := p.getRuleTables()
+	p.policyTables = make(map[uint8]*ofctrl.Table, len(ruleTables))
+	for tableID := range ruleTables {
+		if p.policyTables[tableID] = sw.GetTable(tableID); p.policyTables[tableID] == nil {
+			p.policyTables[tableID], _ = sw.NewTable(tableID)
+		}
+	}
 
 	if err := p.initInputTable(sw); err != nil {
 		log.Fatalf("Failed to init inputTable, error: %v", err)
@@ -138,6 +439,174 @@ func (p *PolicyBridge) BridgeInit() {
 	if err := p.initPolicyForwardingTable(sw); err != nil {
 		log.Fatalf("Failed to init policy forwarding table, error: %v", err)
 	}
+	p.failOpenFlow = nil
+	if err := p.installTableMissFlow(p.tableMissAction); err != nil {
+		log.Fatalf("Failed to init table-miss flow, error: %v", err)
+	}
+	if err := p.initRSTDetectFlow(); err != nil {
+		log.Fatalf("Failed to init tcp rst detect flow, error: %v", err)
+	}
+	for ipAddr := range p.arpBlockIPs {
+		if err := p.installARPBlockFlows(ipAddr); err != nil {
+			log.Errorf("Failed to init arp block flow: %v", err)
+		}
+	}
+}
+
+// SetTableMissAction sets table-miss action of the bridge, the flow would be installed on bridge init
+// if the switch hasn't connected.
+func (p *PolicyBridge) SetTableMissAction(action TableMissAction) error {
+	if action == p.tableMissAction {
+		return nil
+	}
+	if p.IsSwitchConnected() {
+		if err := p.installTableMissFlow(action); err != nil {
+			return err
+		}
+	}
+	p.tableMissAction = action
+	return nil
+}
+
+func (p *PolicyBridge) GetTableMissAction() TableMissAction {
+	return p.tableMissAction
+}
+
+func (p *PolicyBridge) installTableMissFlow(action TableMissAction) error {
+	if action != TableMissFailOpen {
+		if p.failOpenFlow == nil {
+			return nil
+		}
+		if err := p.failOpenFlow.Delete(); err != nil {
+			return fmt.Errorf("failed to delete fail open flow, error: %v", err)
+		}
+		p.failOpenFlow = nil
+		return nil
+	}
+
+	if p.failOpenFlow != nil {
+		return nil
+	}
+	// Table 10, bypass policy tables and commit into ct directly
+	failOpenFlow, _ := p.directionSelectionTable.NewFlow(ofctrl.FlowMatch{
+		Priority:  HIGH_MATCH_FLOW_PRIORITY,
+		Ethertype: PROTOCOL_IP,
+	})
+	if err := failOpenFlow.Next(p.ctCommitTable); err != nil {
+		return fmt.Errorf("failed to install fail open flow, error: %v", err)
+	}
+	p.failOpenFlow = failOpenFlow
+	return nil
+}
+
+func (p *PolicyBridge) initRSTDetectFlow() error {
+	if p.rstDetector == nil {
+		return nil
+	}
+
+	// Table 1, send copy of tcp rst of established connections to controller for detection, the packet
+	// continues as other established packets. Rst of untracked connections are not counted, so that
+	// spoofed rst could hardly get the source denied.
+	ctEstState := openflow13.NewCTStates()
+	ctEstState.SetEst()
+	ctEstState.SetTrk()
+	tcpRstFlag := uint16(tcpFlagRST)
+	rstSampleFlow, _ := p.ctStateTable.NewFlow(ofctrl.FlowMatch{
+		Priority:     MID_MATCH_FLOW_PRIORITY + 2*FLOW_MATCH_OFFSET,
+		Ethertype:    PROTOCOL_IP,
+		IpProto:      ofctrl.IP_PROTO_TCP,
+		TcpFlags:     &tcpRstFlag,
+		TcpFlagsMask: &tcpRstFlag,
+		CtStates:     ctEstState,
+	})
+	_ = sendToMeteredController(p.OfSwitch, rstSampleFlow, 0, PacketInRSTDetect)
+	if err := rstSampleFlow.Next(p.ctEstablishedTable); err != nil {
+		return fmt.Errorf("failed to install tcp rst sample flow, error: %v", err)
+	}
+
+	// flows of the old switch are lost, deny the sources again
+	p.rstDenyMutex.Lock()
+	defer p.rstDenyMutex.Unlock()
+	p.rstDenyFlows = make(map[string]*ofctrl.Flow)
+	for _, src := range p.rstDetector.deniedSources() {
+		if err := p.installRSTDenyFlow(src); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *PolicyBridge) processRSTPacket(pkt *ofctrl.PacketIn) {
+	if p.rstDetector == nil {
+		return
+	}
+	ipv4, ok := pkt.Data.Data.(*protocol.IPv4)
+	if !ok {
+		return
+	}
+	if p.rstDetector.allowed(ipv4.NWSrc) {
+		return
+	}
+	src := ipv4.NWSrc.String()
+	// deny flow of the source would be installed on bridge init if the switch disconnected
+	if !p.rstDetector.observe(src, time.Now()) || !p.IsSwitchConnected() {
+		return
+	}
+
+	p.rstDenyMutex.Lock()
+	defer p.rstDenyMutex.Unlock()
+	if err := p.installRSTDenyFlow(src); err != nil {
+		log.Errorf("Failed to deny source %s exceeding tcp rst threshold: %s", src, err)
+		return
+	}
+	log.Warnf("Deny source %s for %s, it sent over %d tcp rst in %s", src,
+		p.rstDetector.config.DenyTimeout, p.rstDetector.config.Threshold, p.rstDetector.config.Window)
+}
+
+// installRSTDenyFlow drops ip packets from the source, rstDenyMutex should be held by the caller
+func (p *PolicyBridge) installRSTDenyFlow(src string) error {
+	if _, ok := p.rstDenyFlows[src]; ok {
+		return nil
+	}
+	srcIP := net.ParseIP(src)
+	if srcIP == nil {
+		return fmt.Errorf("invalid source ip %s", src)
+	}
+	// Table 0, drop ip packets from the source denied
+	rstDenyFlow, _ := p.inputTable.NewFlow(ofctrl.FlowMatch{
+		Priority:  HIGH_MATCH_FLOW_PRIORITY + 2*FLOW_MATCH_OFFSET,
+		Ethertype: PROTOCOL_IP,
+		IpSa:      &srcIP,
+	})
+	if err := rstDenyFlow.Next(p.OfSwitch.DropAction()); err != nil {
+		return fmt.Errorf("failed to install tcp rst deny flow of %s, error: %v", src, err)
+	}
+	p.rstDenyFlows[src] = rstDenyFlow
+	return nil
+}
+
+// removeExpiredRSTDeny removes the drop flows of sources whose deny expired
+func (p *PolicyBridge) removeExpiredRSTDeny() {
+	if p.rstDetector == nil {
+		return
+	}
+
+	p.rstDetector.expire(time.Now())
+	denied := sets.NewString(p.rstDetector.deniedSources()...)
+
+	p.rstDenyMutex.Lock()
+	defer p.rstDenyMutex.Unlock()
+	for src, flow := range p.rstDenyFlows {
+		if denied.Has(src) {
+			continue
+		}
+		if err := flow.Delete(); err != nil {
+			log.Errorf("Failed to remove tcp rst deny flow of %s: %s", src, err)
+			continue
+		}
+		delete(p.rstDenyFlows, src)
+		log.Infof("Deny of source %s for exceeding tcp rst threshold expired", src)
+	}
 }
 
 func (p *PolicyBridge) initDirectionSelectionTable() error {
@@ -146,14 +615,14 @@ func (p *PolicyBridge) initDirectionSelectionTable() error {
 		Priority:  MID_MATCH_FLOW_PRIORITY,
 		InputPort: uint32(p.datapathManager.BridgeChainPortMap[localBrName][PolicyToLocalSuffix]),
 	})
-	if err := fromLocalToEgressFlow.Next(p.egressTier1PolicyTable); err != nil {
+	if err := fromLocalToEgressFlow.Next(p.policyTables[p.datapathManager.policyTiers().tableChain(POLICY_DIRECTION_OUT)[0]]); err != nil {
 		return fmt.Errorf("failed to install from local to egress flow, error: %v", err)
 	}
 	fromUpstreamToIngressFlow, _ := p.directionSelectionTable.NewFlow(ofctrl.FlowMatch{
 		Priority:  MID_MATCH_FLOW_PRIORITY,
 		InputPort: uint32(p.datapathManager.BridgeChainPortMap[localBrName][PolicyToClsSuffix]),
 	})
-	if err := fromUpstreamToIngressFlow.Next(p.ingressTier1PolicyTable); err != nil {
+	if err := fromUpstreamToIngressFlow.Next(p.policyTables[p.datapathManager.policyTiers().tableChain(POLICY_DIRECTION_IN)[0]]); err != nil {
 		return fmt.Errorf("failed to install from upstream to ingress flow, error: %v", err)
 	}
 
@@ -162,13 +631,18 @@ func (p *PolicyBridge) initDirectionSelectionTable() error {
 
 func (p *PolicyBridge) initInputTable(sw *ofctrl.OFSwitch) error {
 	var ctStateTableID uint8 = CT_STATE_TABLE
-	var policyConntrackZone = constants.CTZoneForPolicy
 	localBrName := strings.TrimSuffix(p.name, "-policy")
-	ctAction := ofctrl.NewConntrackAction(false, false, &ctStateTableID, &policyConntrackZone)
+	ctAction, err := p.newPolicyConntrackAction(false, &ctStateTableID)
+	if err != nil {
+		return fmt.Errorf("failed to new input ct action, error: %v", err)
+	}
 	inputIPRedirectFlow, _ := p.inputTable.NewFlow(ofctrl.FlowMatch{
 		Priority:  HIGH_MATCH_FLOW_PRIORITY,
 		Ethertype: PROTOCOL_IP,
 	})
+	if err := p.loadPolicyCTZone(inputIPRedirectFlow); err != nil {
+		return fmt.Errorf("failed to load input ct zone, error: %v", err)
+	}
 	_ = inputIPRedirectFlow.SetConntrack(ctAction)
 	if err := inputIPRedirectFlow.Next(ofctrl.NewEmptyElem()); err != nil {
 		return fmt.Errorf("failed to install input ip redirect flow, error: %v", err)
@@ -206,7 +680,6 @@ func (p *PolicyBridge) initInputTable(sw *ofctrl.OFSwitch) error {
 }
 
 func (p *PolicyBridge) initCTFlow(sw *ofctrl.OFSwitch) error {
-	var policyConntrackZone = constants.CTZoneForPolicy
 	// Table 1, ctState table, est state flow
 	// FIXME. should add ctEst flow and ctInv flow with same priority. With different, it have no side effect to flow intent.
 	ctEstState := openflow13.NewCTStates()
@@ -216,10 +689,20 @@ func (p *PolicyBridge) initCTFlow(sw *ofctrl.OFSwitch) error {
 		Priority: MID_MATCH_FLOW_PRIORITY + FLOW_MATCH_OFFSET,
 		CtStates: ctEstState,
 	})
-	if err := ctStateFlow.Next(p.ctCommitTable); err != nil {
+	if err := ctStateFlow.Next(p.ctEstablishedTable); err != nil {
 		return fmt.Errorf("failed to install ct est state flow, error: %v", err)
 	}
 
+	// Table 2, ct established table, established and related packets of connections allowed before
+	// bypass policy tables, they are still dropped in ct commit table if the connection marked deny.
+	// Connections no longer allowed are torn down by conntrack clean when rules changed.
+	ctEstablishedFlow, _ := p.ctEstablishedTable.NewFlow(ofctrl.FlowMatch{
+		Priority: MID_MATCH_FLOW_PRIORITY,
+	})
+	if err := ctEstablishedFlow.Next(p.ctCommitTable); err != nil {
+		return fmt.Errorf("failed to install ct established shortcut flow, error: %v", err)
+	}
+
 	// Table 1, ctState table, invalid state flow
 	ctInvState := openflow13.NewCTStates()
 	ctInvState.SetInv()
@@ -293,12 +776,41 @@ func (p *PolicyBridge) initCTFlow(sw *ofctrl.OFSwitch) error {
 	srcField, _ := openflow13.FindFieldHeaderByName("nxm_nx_xxreg0", false)
 	dstField, _ := openflow13.FindFieldHeaderByName("nxm_nx_ct_label", false)
 	moveAct := openflow13.NewNXActionRegMove(128, 0, 0, srcField, dstField)
-	ctCommitAction := ofctrl.NewConntrackAction(true, false, &ctDropTable, &policyConntrackZone, moveAct)
+	ctCommitAction, err := p.newPolicyConntrackAction(true, &ctDropTable, moveAct)
+	if err != nil {
+		return fmt.Errorf("failed to new ct commit action, error: %v", err)
+	}
 	_ = ctCommitFlow.SetConntrack(ctCommitAction)
 	if err := ctCommitFlow.Next(ofctrl.NewEmptyElem()); err != nil {
 		return fmt.Errorf("failed to install ct normal commit flow, error: %v", err)
 	}
 
+	// send copy of the first packet of connection decided by rule flow recording decision or logging to
+	// controller, the connection is committed the same as the normal commit flow
+	ctCommitPuntFlow, _ := p.ctCommitTable.NewFlow(ofctrl.FlowMatch{
+		Priority:  MID_MATCH_FLOW_PRIORITY + FLOW_MATCH_OFFSET,
+		Ethertype: PROTOCOL_IP,
+		CtStates:  ctTrkState,
+		Regs: []*ofctrl.NXRegister{
+			{
+				RegID: constants.OVSReg4,
+				Data:  0x1,
+				Range: PolicyPuntNXRange,
+			},
+		},
+	})
+	_ = sendToMeteredController(p.OfSwitch, ctCommitPuntFlow, openflow13.R_ACTION, PacketInPolicyLogging)
+	_ = ctCommitPuntFlow.SetConntrack(ctCommitAction)
+	if err := ctCommitPuntFlow.Next(ofctrl.NewEmptyElem()); err != nil {
+		return fmt.Errorf("failed to install ct commit punt flow, error: %v", err)
+	}
+
+	if p.datapathManager.ctTimeoutPolicyEnabled() {
+		if err := p.initCTTimeoutFlow(moveAct); err != nil {
+			return err
+		}
+	}
+
 	ctCommitTableDefaultFlow, _ := p.ctCommitTable.NewFlow(ofctrl.FlowMatch{
 		Priority: DEFAULT_FLOW_MISS_PRIORITY,
 	})
@@ -320,6 +832,20 @@ func (p *PolicyBridge) initCTFlow(sw *ofctrl.OFSwitch) error {
 	if err := ctByPassFlow1.Next(p.OfSwitch.DropAction()); err != nil {
 		return fmt.Errorf("failed to install ct drop flow, error: %v", err)
 	}
+	ctRejectFlow, _ := p.ctDropTable.NewFlow(ofctrl.FlowMatch{
+		Priority: MID_MATCH_FLOW_PRIORITY + 2*FLOW_MATCH_OFFSET,
+		Regs: []*ofctrl.NXRegister{
+			{
+				RegID: constants.OVSReg4,
+				Data:  0x20 | 1<<PolicyRejectReg4Bit,
+				Range: openflow13.NewNXRange(0, PolicyRejectReg4Bit),
+			},
+		},
+	})
+	_ = sendToMeteredController(p.OfSwitch, ctRejectFlow, 0, PacketInReject)
+	if err := ctRejectFlow.Next(ofctrl.NewEmptyElem()); err != nil {
+		return fmt.Errorf("failed to install ct reject flow, error: %v", err)
+	}
 	ctByPassFlow2, _ := p.ctDropTable.NewFlow(ofctrl.FlowMatch{
 		Priority: MID_MATCH_FLOW_PRIORITY + FLOW_MATCH_OFFSET,
 		Regs: []*ofctrl.NXRegister{
@@ -348,69 +874,13 @@ func (p *PolicyBridge) initCTFlow(sw *ofctrl.OFSwitch) error {
 }
 
 func (p *PolicyBridge) initPolicyTable() error {
-	// egress policy table
-	egressTier1DefaultFlow, _ := p.egressTier1PolicyTable.NewFlow(ofctrl.FlowMatch{
-		Priority: DEFAULT_FLOW_MISS_PRIORITY,
-	})
-	if err := egressTier1DefaultFlow.Next(p.egressTier2PolicyMonitorTable); err != nil {
-		return fmt.Errorf("failed to install egress tier1 default flow, error: %v", err)
-	}
-	egressTier2MonitorDefaultFlow, _ := p.egressTier2PolicyMonitorTable.NewFlow(ofctrl.FlowMatch{
-		Priority: DEFAULT_FLOW_MISS_PRIORITY,
-	})
-	if err := egressTier2MonitorDefaultFlow.Next(p.egressTier2PolicyTable); err != nil {
-		return fmt.Errorf("failed to install egress tier2 monitor table default flow, error: %v", err)
-	}
-	egressTier2DefaultFlow, _ := p.egressTier2PolicyTable.NewFlow(ofctrl.FlowMatch{
-		Priority: DEFAULT_FLOW_MISS_PRIORITY,
-	})
-	if err := egressTier2DefaultFlow.Next(p.egressTierECPPolicyTable); err != nil {
-		return fmt.Errorf("failed to install egress tier2 default flow, error: %v", err)
-	}
-	egressTierECPDefaultFlow, _ := p.egressTierECPPolicyTable.NewFlow(ofctrl.FlowMatch{
-		Priority: DEFAULT_FLOW_MISS_PRIORITY,
-	})
-	if err := egressTierECPDefaultFlow.Next(p.egressTier3PolicyMonitorTable); err != nil {
-		return fmt.Errorf("failed to install egress tier ecp default flow, error: %v", err)
-	}
-	egressTier3MonitorDefaultFlow, _ := p.egressTier3PolicyMonitorTable.NewFlow(ofctrl.FlowMatch{
-		Priority: DEFAULT_FLOW_MISS_PRIORITY,
-	})
-	if err := egressTier3MonitorDefaultFlow.Next(p.egressTier3PolicyTable); err != nil {
-		return fmt.Errorf("failed to install egress tier2 monitor table default flow, error: %v", err)
-	}
-	egressTier3DefaultFlow, _ := p.egressTier3PolicyTable.NewFlow(ofctrl.FlowMatch{
-		Priority: DEFAULT_FLOW_MISS_PRIORITY,
-	})
-	if err := egressTier3DefaultFlow.Next(p.ctCommitTable); err != nil {
-		return fmt.Errorf("failed to install egress tier3 default flow, error: %v", err)
+	p.tierDefaultFlows = make(map[uint8]*ofctrl.Flow, len(p.policyTables))
+	if err := p.initPolicyTableChain(); err != nil {
+		return err
 	}
 
-	// ingress policy table
-	ingressTier1DefaultFlow, _ := p.ingressTier1PolicyTable.NewFlow(ofctrl.FlowMatch{
-		Priority: DEFAULT_FLOW_MISS_PRIORITY,
-	})
-	if err := ingressTier1DefaultFlow.Next(p.ingressTier2PolicyMonitorTable); err != nil {
-		return fmt.Errorf("failed to install ingress tier1 default flow, error: %v", err)
-	}
-	ingressTier2MonitorDefaultFlow, _ := p.ingressTier2PolicyMonitorTable.NewFlow(ofctrl.FlowMatch{
-		Priority: DEFAULT_FLOW_MISS_PRIORITY,
-	})
-	if err := ingressTier2MonitorDefaultFlow.Next(p.ingressTier2PolicyTable); err != nil {
-		return fmt.Errorf("failed to install ingress tier2 monitor table default flow, error: %v", err)
-	}
-	ingressTier2DefaultFlow, _ := p.ingressTier2PolicyTable.NewFlow(ofctrl.FlowMatch{
-		Priority: DEFAULT_FLOW_MISS_PRIORITY,
-	})
-	if err := ingressTier2DefaultFlow.Next(p.ingressTierECPPolicyTable); err != nil {
-		return fmt.Errorf("failed to install ingress tier2 default flow, error: %v", err)
-	}
-	ingressTierECPDefaultFlow, _ := p.ingressTierECPPolicyTable.NewFlow(ofctrl.FlowMatch{
-		Priority: DEFAULT_FLOW_MISS_PRIORITY,
-	})
-	if err := ingressTierECPDefaultFlow.Next(p.ingressTier3PolicyMonitorTable); err != nil {
-		return fmt.Errorf("failed to install ingress tier2 default flow, error: %v", err)
-	}
+	// move flow cookie of tier3 monitor rule recorded in ct_label into xxreg0
+	flowCookie := p.datapathManager.flowCookie()
 	ingressTier3MonitorDropMatchFlow, _ := p.ingressTier3PolicyMonitorTable.NewFlow(ofctrl.FlowMatch{
 		Priority:    HIGH_MATCH_FLOW_PRIORITY,
 		Ethertype:   PROTOCOL_IP,
@@ -425,14 +895,14 @@ func (p *PolicyBridge) initPolicyTable() error {
 		return fmt.Errorf("failed to install ingress tier3 monitor table drop match flow, error: %v", err)
 	}
 	if err := ingressTier3MonitorDropMatchFlow.MoveField(
-		MonitorTier3FlowSpaceXXREG0BitSize,
+		uint16(flowCookie.FlowSeqBits),
 		MonitorTier3FlowSpaceXXREG0BitStart,
 		MonitorTier3FlowSpaceXXREG0BitStart,
 		"nxm_nx_ct_label", "nxm_nx_xxreg0", false); err != nil {
 		return fmt.Errorf("failed to install ingress tier3 monitor table drop match flow, error: %v", err)
 	}
 	if err := ingressTier3MonitorDropMatchFlow.MoveField(
-		RoundNumXXREG0BitSize,
+		uint16(flowCookie.RoundNumBits),
 		RoundNumXXREG0BitStart,
 		RoundNumXXREG0BitStart,
 		"nxm_nx_ct_label", "nxm_nx_xxreg0", false); err != nil {
@@ -452,14 +922,14 @@ func (p *PolicyBridge) initPolicyTable() error {
 		return fmt.Errorf("failed to install ingress tier3 monitor table default flow, error: %v", err)
 	}
 	if err := ingressTier3MonitorDefaultFlow.MoveField(
-		MonitorTier3FlowSpaceXXREG0BitSize,
+		uint16(flowCookie.FlowSeqBits),
 		MonitorTier3FlowSpaceXXREG0BitStart,
 		MonitorTier3FlowSpaceXXREG0BitStart,
 		"nxm_nx_ct_label", "nxm_nx_xxreg0", false); err != nil {
 		return fmt.Errorf("failed to install ingress tier3 monitor table default flow, error: %v", err)
 	}
 	if err := ingressTier3MonitorDefaultFlow.MoveField(
-		RoundNumXXREG0BitSize,
+		uint16(flowCookie.RoundNumBits),
 		RoundNumXXREG0BitStart,
 		RoundNumXXREG0BitStart,
 		"nxm_nx_ct_label", "nxm_nx_xxreg0", false); err != nil {
@@ -468,12 +938,6 @@ func (p *PolicyBridge) initPolicyTable() error {
 	if err := ingressTier3MonitorDefaultFlow.Next(p.ingressTier3PolicyTable); err != nil {
 		return fmt.Errorf("failed to install ingress tier3 monitor table default flow, error: %v", err)
 	}
-	ingressTier3DefaultFlow, _ := p.ingressTier3PolicyTable.NewFlow(ofctrl.FlowMatch{
-		Priority: DEFAULT_FLOW_MISS_PRIORITY,
-	})
-	if err := ingressTier3DefaultFlow.Next(p.ctCommitTable); err != nil {
-		return fmt.Errorf("failed to install ingress tier3 default flow, error: %v", err)
-	}
 
 	// sfc policy table
 	sfcPolicyTableDefaultFlow, _ := p.sfcPolicyTable.NewFlow(ofctrl.FlowMatch{
@@ -486,6 +950,45 @@ func (p *PolicyBridge) initPolicyTable() error {
 	return nil
 }
 
+// initPolicyTableChain installs default flows of the tier tables, packet walks through tables of tiers in
+// order, and goes to ct commit table if no rule matched.
+func (p *PolicyBridge) initPolicyTableChain() error {
+	for _, direction := range []uint8{POLICY_DIRECTION_OUT, POLICY_DIRECTION_IN} {
+		chain := p.datapathManager.policyTiers().tableChain(direction)
+		for index, tableID := range chain {
+			// ingress tier3 monitor table moves ct_label fields before the ingress tier3 table
+			if tableID == INGRESS_TIER3_MONITOR_TABLE {
+				continue
+			}
+			nextTable := p.ctCommitTable
+			if index+1 < len(chain) {
+				nextTable = p.policyTables[chain[index+1]]
+			}
+			defaultFlow, err := p.installTierDefaultFlow(p.policyTables[tableID], nextTable)
+			if err != nil {
+				return fmt.Errorf("failed to install policy table %d default flow, error: %v", tableID, err)
+			}
+			p.tierDefaultFlows[tableID] = defaultFlow
+		}
+	}
+	return nil
+}
+
+// installTierDefaultFlow sends packets missed rules of the tier table to the next table, tables of
+// custom tiers may take table id greater than the next table, which can't be reached by goto table.
+func (p *PolicyBridge) installTierDefaultFlow(table, nextTable *ofctrl.Table) (*ofctrl.Flow, error) {
+	defaultFlow, _ := table.NewFlow(ofctrl.FlowMatch{
+		Priority: DEFAULT_FLOW_MISS_PRIORITY,
+	})
+	if nextTable.TableId > table.TableId {
+		return defaultFlow, defaultFlow.Next(nextTable)
+	}
+	if err := defaultFlow.Resubmit(nil, &nextTable.TableId); err != nil {
+		return nil, err
+	}
+	return defaultFlow, defaultFlow.Next(ofctrl.NewEmptyElem())
+}
+
 func (p *PolicyBridge) initPolicyForwardingTable(sw *ofctrl.OFSwitch) error {
 	localBrName := strings.TrimSuffix(p.name, "-policy")
 	// policy forwarding table
@@ -534,14 +1037,13 @@ func (p *PolicyBridge) initALGFlow(sw *ofctrl.OFSwitch) error {
 		Priority: MID_MATCH_FLOW_PRIORITY + FLOW_MATCH_OFFSET,
 		CtStates: ctRelState,
 	})
-	if err := ctRelFlow.Next(p.ctCommitTable); err != nil {
+	if err := ctRelFlow.Next(p.ctEstablishedTable); err != nil {
 		return fmt.Errorf("failed to install ct rel state flow, err: %v", err)
 	}
 
 	ctTrkState := openflow13.NewCTStates()
 	ctTrkState.SetNew()
 	ctTrkState.SetTrk()
-	var policyConntrackZone = constants.CTZoneForPolicy
 	var ctDropTable uint8 = CT_DROP_TABLE
 	srcField, _ := openflow13.FindFieldHeaderByName("nxm_nx_xxreg0", false)
 	dstField, _ := openflow13.FindFieldHeaderByName("nxm_nx_ct_label", false)
@@ -556,7 +1058,10 @@ func (p *PolicyBridge) initALGFlow(sw *ofctrl.OFSwitch) error {
 		TcpDstPortMask: PortMaskMatchFullBit,
 		CtStates:       ctTrkState,
 	})
-	ftpAction := ofctrl.NewConntrackAction(true, false, &ctDropTable, &policyConntrackZone, moveAct)
+	ftpAction, err := p.newPolicyConntrackAction(true, &ctDropTable, moveAct)
+	if err != nil {
+		return fmt.Errorf("failed to new ftp ct action, err: %v", err)
+	}
 	ftpAction.SetAlg(FTPPort)
 	_ = ftpFlow.SetConntrack(ftpAction)
 	if err := ftpFlow.Next(ofctrl.NewEmptyElem()); err != nil {
@@ -572,7 +1077,10 @@ func (p *PolicyBridge) initALGFlow(sw *ofctrl.OFSwitch) error {
 		UdpDstPortMask: PortMaskMatchFullBit,
 		CtStates:       ctTrkState,
 	})
-	tftpAction := ofctrl.NewConntrackAction(true, false, &ctDropTable, &policyConntrackZone, moveAct)
+	tftpAction, err := p.newPolicyConntrackAction(true, &ctDropTable, moveAct)
+	if err != nil {
+		return fmt.Errorf("failed to new tftp ct action, err: %v", err)
+	}
 	tftpAction.SetAlg(TFTPPort)
 	_ = tftpFlow.SetConntrack(tftpAction)
 	if err := tftpFlow.Next(ofctrl.NewEmptyElem()); err != nil {
@@ -581,99 +1089,220 @@ func (p *PolicyBridge) initALGFlow(sw *ofctrl.OFSwitch) error {
 	return nil
 }
 
+// newPolicyConntrackAction returns ct action in the policy conntrack zone, the zone is read from reg7
+// when assign conntrack zone by vlan
+// initCTTimeoutFlow installs flows lookup and commit connections of rules with ct timeout policy in the ct
+// timeout zone, the zone has the ct timeout policy, so that only connections of the rules expire by it.
+func (p *PolicyBridge) initCTTimeoutFlow(moveAct *openflow13.NXActionRegMove) error {
+	var ctStateTableID uint8 = CT_STATE_TABLE
+	var ctDropTable uint8 = CT_DROP_TABLE
+	ctTimeoutZone := constants.CTZoneForPolicyCTTimeout
+	ctTrkState := openflow13.NewCTStates()
+	ctTrkState.SetNew()
+	ctTrkState.SetTrk()
+
+	// connections not established in the policy zone may be committed in the ct timeout zone
+	ctTimeoutLookupFlow, _ := p.ctStateTable.NewFlow(ofctrl.FlowMatch{
+		Priority: MID_MATCH_FLOW_PRIORITY - FLOW_MATCH_OFFSET,
+		CtStates: ctTrkState,
+		Regs: []*ofctrl.NXRegister{
+			{
+				RegID: constants.OVSReg4,
+				Data:  0x0,
+				Range: PolicyCTTimeoutLookupNXRange,
+			},
+		},
+	})
+	if err := ctTimeoutLookupFlow.LoadField("nxm_nx_reg4", 0x1, PolicyCTTimeoutLookupNXRange); err != nil {
+		return err
+	}
+	_ = ctTimeoutLookupFlow.SetConntrack(ofctrl.NewConntrackAction(false, false, &ctStateTableID, &ctTimeoutZone))
+	if err := ctTimeoutLookupFlow.Next(ofctrl.NewEmptyElem()); err != nil {
+		return fmt.Errorf("failed to install ct timeout lookup flow, error: %v", err)
+	}
+
+	// commit connections of rules with ct timeout policy into the ct timeout zone, the same as the normal
+	// commit flow and punt flow
+	ctTimeoutCommitAction := ofctrl.NewConntrackAction(true, false, &ctDropTable, &ctTimeoutZone, moveAct)
+	for _, punt := range []bool{false, true} {
+		priority := uint16(MID_MATCH_FLOW_PRIORITY + 1)
+		regs := []*ofctrl.NXRegister{{RegID: constants.OVSReg4, Data: 0x1, Range: PolicyCTTimeoutNXRange}}
+		if punt {
+			priority = MID_MATCH_FLOW_PRIORITY + FLOW_MATCH_OFFSET + 1
+			regs = append(regs, &ofctrl.NXRegister{RegID: constants.OVSReg4, Data: 0x1, Range: PolicyPuntNXRange})
+		}
+		ctTimeoutCommitFlow, _ := p.ctCommitTable.NewFlow(ofctrl.FlowMatch{
+			Priority:  priority,
+			Ethertype: PROTOCOL_IP,
+			CtStates:  ctTrkState,
+			Regs:      regs,
+		})
+		if punt {
+			_ = sendToMeteredController(p.OfSwitch, ctTimeoutCommitFlow, openflow13.R_ACTION, PacketInPolicyLogging)
+		}
+		_ = ctTimeoutCommitFlow.SetConntrack(ctTimeoutCommitAction)
+		if err := ctTimeoutCommitFlow.Next(ofctrl.NewEmptyElem()); err != nil {
+			return fmt.Errorf("failed to install ct timeout commit flow, error: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func (p *PolicyBridge) newPolicyConntrackAction(commit bool, table *uint8, actions ...openflow13.Action) (*ofctrl.ConnTrackAction, error) {
+	if p.datapathManager.IsCTZoneByVlan() {
+		return ofctrl.NewConntrackActionWithZoneField(commit, false, table, PolicyCTZoneReg, PolicyCTZoneNXRange, actions...)
+	}
+	var policyConntrackZone = constants.CTZoneForPolicy
+	return ofctrl.NewConntrackAction(commit, false, table, &policyConntrackZone, actions...), nil
+}
+
+// loadPolicyCTZone loads conntrack zone derived from the packet vlan into reg7, it does nothing unless
+// assign conntrack zone by vlan. The reg7 is kept when packet recirculated from conntrack.
+func (p *PolicyBridge) loadPolicyCTZone(flow *ofctrl.Flow) error {
+	if !p.datapathManager.IsCTZoneByVlan() {
+		return nil
+	}
+	if err := flow.LoadField(PolicyCTZoneReg, uint64(constants.CTZoneForPolicyVlanBase>>12), PolicyCTZoneVlanBaseNXRange); err != nil {
+		return err
+	}
+	return flow.MoveField(12, 0, 0, "nxm_of_vlan_tci", PolicyCTZoneReg, false)
+}
+
+// PolicyCTZoneOfVlan returns the policy conntrack zone of the vlan when assign conntrack zone by vlan
+func PolicyCTZoneOfVlan(vlanID uint16) uint16 {
+	return constants.CTZoneForPolicyVlanBase | vlanID&VlanIDMask
+}
+
 func (p *PolicyBridge) BridgeReset() {
 }
 
 func (p *PolicyBridge) AddLocalEndpoint(endpoint *Endpoint) error {
+	// endpoint mac or readiness may changed, always remove the old flows first
+	if err := p.removeNotReadyEndpointFlow(endpoint.InterfaceUUID); err != nil {
+		return err
+	}
+	if !endpoint.NotReady {
+		return nil
+	}
+	macAddr, err := net.ParseMAC(endpoint.MacAddrStr)
+	if err != nil {
+		return fmt.Errorf("failed to parse not ready endpoint %s mac %s, error: %v", endpoint.InterfaceUUID, endpoint.MacAddrStr, err)
+	}
+
+	// drop all ipv4 and ipv6 traffic to the not ready endpoint before ct commit, no matter the policy action,
+	// match the endpoint mac for the endpoint ip may not learned yet
+	for _, ethertype := range []uint16{PROTOCOL_IP, PROTOCOL_IPV6} {
+		notReadyEndpointFlow, _ := p.ctCommitTable.NewFlow(ofctrl.FlowMatch{
+			Priority:  HIGH_MATCH_FLOW_PRIORITY + FLOW_MATCH_OFFSET,
+			Ethertype: ethertype,
+			MacDa:     &macAddr,
+		})
+		if err := notReadyEndpointFlow.Next(p.OfSwitch.DropAction()); err != nil {
+			return fmt.Errorf("failed to install not ready endpoint %s drop flow, error: %v", endpoint.InterfaceUUID, err)
+		}
+		log.Infof("add not ready endpoint flow: %v", notReadyEndpointFlow)
+		p.notReadyEndpointFlow[endpoint.InterfaceUUID] = append(p.notReadyEndpointFlow[endpoint.InterfaceUUID], notReadyEndpointFlow)
+	}
+
 	return nil
 }
 
 func (p *PolicyBridge) RemoveLocalEndpoint(endpoint *Endpoint) error {
+	return p.removeNotReadyEndpointFlow(endpoint.InterfaceUUID)
+}
+
+func (p *PolicyBridge) removeNotReadyEndpointFlow(interfaceUUID string) error {
+	flows, ok := p.notReadyEndpointFlow[interfaceUUID]
+	if !ok {
+		return nil
+	}
+	for _, flow := range flows {
+		log.Infof("remove not ready endpoint flow: %v", flow)
+		if err := flow.Delete(); err != nil {
+			return fmt.Errorf("failed to remove not ready endpoint %s drop flow, error: %v", interfaceUUID, err)
+		}
+	}
+	delete(p.notReadyEndpointFlow, interfaceUUID)
 	return nil
 }
 
 func (p *PolicyBridge) GetTierTable(direction uint8, tier uint8, mode string) (*ofctrl.Table, *ofctrl.Table, error) {
-	var policyTable, nextTable *ofctrl.Table
 	// POLICY_TIER0 for endpoint isolation policy:
 	// 1) high priority rule is whitelist for support forensic policyrule, thus packet that match
 	//    that rules should passthrough other policy tier ---- send to ctCommitTable;
 	// 2) low priority rule is blacklist for support general isolation policyrule.
-	switch mode {
-	case "work":
-		switch direction {
-		case POLICY_DIRECTION_OUT:
-			switch tier {
-			case POLICY_TIER1:
-				policyTable = p.egressTier1PolicyTable
-				nextTable = p.ctCommitTable
-			case POLICY_TIER2:
-				policyTable = p.egressTier2PolicyTable
-				nextTable = p.ctCommitTable
-			case POLICY_TIER3:
-				policyTable = p.egressTier3PolicyTable
-				nextTable = p.ctCommitTable
-			case POLICY_TIER_ECP:
-				policyTable = p.egressTierECPPolicyTable
-				nextTable = p.ctCommitTable
-			default:
-				return nil, nil, errors.New("unknown policy tier")
-			}
-		case POLICY_DIRECTION_IN:
-			switch tier {
-			case POLICY_TIER1:
-				policyTable = p.ingressTier1PolicyTable
-				nextTable = p.ctCommitTable
-			case POLICY_TIER2:
-				policyTable = p.ingressTier2PolicyTable
-				nextTable = p.ctCommitTable
-			case POLICY_TIER3:
-				policyTable = p.ingressTier3PolicyTable
-				nextTable = p.ctCommitTable
-			case POLICY_TIER_ECP:
-				policyTable = p.ingressTierECPPolicyTable
-				nextTable = p.ctCommitTable
-			default:
-				return nil, nil, errors.New("unknown policy tier")
-			}
-		}
-	case "monitor":
-		switch direction {
-		case POLICY_DIRECTION_OUT:
-			switch tier {
-			case POLICY_TIER1:
-				return nil, nil, fmt.Errorf("policy tier1 without monitor mode support")
-			case POLICY_TIER2:
-				policyTable = p.egressTier2PolicyMonitorTable
-				nextTable = p.egressTier2PolicyTable
-			case POLICY_TIER3:
-				policyTable = p.egressTier3PolicyMonitorTable
-				nextTable = p.egressTier3PolicyTable
-			case POLICY_TIER_ECP:
-				return nil, nil, fmt.Errorf("monitor mode doesn't support tier-ecp")
-			default:
-				return nil, nil, errors.New("unknown policy tier")
-			}
-		case POLICY_DIRECTION_IN:
-			switch tier {
-			case POLICY_TIER1:
-				return nil, nil, fmt.Errorf("policy tier1 without monitor mode support")
-			case POLICY_TIER2:
-				policyTable = p.ingressTier2PolicyMonitorTable
-				nextTable = p.ingressTier2PolicyTable
-			case POLICY_TIER3:
-				policyTable = p.ingressTier3PolicyMonitorTable
-				nextTable = p.ingressTier3PolicyTable
-			case POLICY_TIER_ECP:
-				return nil, nil, fmt.Errorf("monitor mode doesn't support tier-ecp")
-			default:
-				return nil, nil, errors.New("unknown policy tier")
-			}
-		}
-	default:
+	if mode != "work" && mode != "monitor" {
 		return nil, nil, fmt.Errorf("unknown work mode (%s)", mode)
 	}
+	tableID, ok := p.datapathManager.policyTiers().table(direction, tier)
+	if !ok {
+		return nil, nil, errors.New("unknown policy tier")
+	}
+	if mode == "work" {
+		return p.policyTables[tableID], p.ctCommitTable, nil
+	}
+
+	monitorTableID, ok := policyTierMonitorTables[tier][direction]
+	if !ok {
+		return nil, nil, fmt.Errorf("policy tier %d without monitor mode support", tier)
+	}
+	return p.policyTables[monitorTableID], p.policyTables[tableID], nil
+}
+
+// trafficLocalityMatchField matches IntraNodePktMark set by local bridge overlay, the packet mark 0 can't be
+// matched by FlowMatch, so use raw field instead
+func trafficLocalityMatchField(locality string, enableOverlay bool) (*openflow13.MatchField, error) {
+	if !enableOverlay {
+		return nil, fmt.Errorf("traffic locality match only supported in overlay mode")
+	}
+
+	var pktMark uint32
+	switch locality {
+	case TrafficLocalityIntraNode:
+		pktMark = IntraNodePktMark
+	case TrafficLocalityInterNode:
+		pktMark = 0
+	default:
+		return nil, fmt.Errorf("unknown traffic locality %s", locality)
+	}
+
+	field, err := openflow13.FindFieldHeaderByName("NXM_NX_PKT_MARK", true)
+	if err != nil {
+		return nil, err
+	}
+	field.Value = &openflow13.Uint32Message{Data: pktMark}
+	field.Mask = &openflow13.Uint32Message{Data: IntraNodePktMark}
+	return field, nil
+}
 
-	return policyTable, nextTable, nil
+// setRuleFlowVlanMatch matches the vlan id with the vlan present flag, so that packets without vlan tag never
+// match the rule flow. It does nothing if vlanID is 0.
+func setRuleFlowVlanMatch(match *ofctrl.FlowMatch, vlanID uint16) {
+	if vlanID == 0 {
+		return
+	}
+	match.VlanId = vlanID & VlanIDMask
+	match.VlanIdMask = &vlanIDAndFlagMask
+}
+
+// setRuleFlowIPv6Match moves ip match of the rule flow to ipv6 match if the rule matches ipv6 address
+func setRuleFlowIPv6Match(match *ofctrl.FlowMatch) error {
+	isIPv6 := func(ip *net.IP) bool { return ip != nil && ip.To4() == nil }
+	isIPv4 := func(ip *net.IP) bool { return ip != nil && ip.To4() != nil }
+
+	switch {
+	case !isIPv6(match.IpSa) && !isIPv6(match.IpDa):
+		return nil
+	case isIPv4(match.IpSa) || isIPv4(match.IpDa):
+		return fmt.Errorf("match both ipv4 and ipv6 address is not supported")
+	}
+
+	match.Ethertype = PROTOCOL_IPV6
+	match.Ipv6Sa, match.Ipv6SaMask = match.IpSa, match.IpSaMask
+	match.Ipv6Da, match.Ipv6DaMask = match.IpDa, match.IpDaMask
+	match.IpSa, match.IpSaMask, match.IpDa, match.IpDaMask = nil, nil, nil, nil
+	return nil
 }
 
 //nolint:funlen
@@ -714,8 +1343,36 @@ func (p *PolicyBridge) AddMicroSegmentRule(rule *EveroutePolicyRule, direction u
 		}
 	}
 
-	// Install the rule in policy table
-	ruleFlow, err := policyTable.NewFlow(ofctrl.FlowMatch{
+	var regs []*ofctrl.NXRegister
+	if rule.IPOptions {
+		if mode != "work" || rule.Action != EveroutePolicyDeny {
+			return nil, fmt.Errorf("ip options match only supported by work mode deny rule")
+		}
+		regs = append(regs, &ofctrl.NXRegister{RegID: IPOptionsInspectedReg, Data: 0, Range: IPOptionsInspectedNXRange})
+	}
+
+	// icmp type and code 0 are valid values, e.g. echo reply, match them with raw field
+	var rawMatchFields []*openflow13.MatchField
+	if rule.TrafficLocality != "" {
+		localityField, err := trafficLocalityMatchField(rule.TrafficLocality, p.datapathManager.IsEnableOverlay())
+		if err != nil {
+			return nil, err
+		}
+		rawMatchFields = append(rawMatchFields, localityField)
+	}
+	if rule.ICMPType != nil || rule.ICMPCode != nil {
+		if rule.IPProtocol != PROTOCOL_ICMP {
+			return nil, fmt.Errorf("icmp type and code match only supported by icmp rule")
+		}
+		if rule.ICMPType != nil {
+			rawMatchFields = append(rawMatchFields, openflow13.NewIcmpTypeField(*rule.ICMPType))
+		}
+		if rule.ICMPCode != nil {
+			rawMatchFields = append(rawMatchFields, openflow13.NewIcmpCodeField(*rule.ICMPCode))
+		}
+	}
+
+	flowMatch := ofctrl.FlowMatch{
 		Priority:       uint16(rule.Priority),
 		Ethertype:      PROTOCOL_IP,
 		IpDa:           ipDa,
@@ -731,30 +1388,54 @@ func (p *PolicyBridge) AddMicroSegmentRule(rule *EveroutePolicyRule, direction u
 		UdpSrcPortMask: rule.SrcPortMask,
 		UdpDstPort:     rule.DstPort,
 		UdpDstPortMask: rule.DstPortMask,
-	})
+		Regs:           regs,
+		RawMatchField:  rawMatchFields,
+	}
+	setRuleFlowVlanMatch(&flowMatch, rule.VlanID)
+	if err := setRuleFlowIPv6Match(&flowMatch); err != nil {
+		log.Errorf("Failed to match ip of rule {%v}. Err: %v", rule, err)
+		return nil, err
+	}
+
+	// Install the rule in policy table
+	ruleFlow, err := policyTable.NewFlow(flowMatch)
 	if err != nil {
 		log.Errorf("Failed to add flow for rule {%v}. Err: %v", rule, err)
 		return nil, err
 	}
 
+	if rule.IPOptions {
+		// packets send to controller for ip options inspection
+		_ = sendToMeteredController(p.OfSwitch, ruleFlow, 0, PacketInIPOptions)
+		if err := ruleFlow.Next(ofctrl.NewEmptyElem()); err != nil {
+			return nil, err
+		}
+		return &FlowEntry{
+			Table:    policyTable,
+			Priority: ruleFlow.Match.Priority,
+			FlowID:   ruleFlow.FlowID,
+		}, nil
+	}
+
+	flowCookie := p.datapathManager.flowCookie()
 	switch mode {
 	case "monitor":
-		if err := ruleFlow.LoadField("nxm_nx_xxreg0", ruleFlow.FlowID>>FLOW_SEQ_NUM_LENGTH, RoundNumNXRange); err != nil {
+		if err := ruleFlow.LoadField("nxm_nx_xxreg0", flowCookie.roundNum(ruleFlow.FlowID), flowCookie.roundNumNXRange()); err != nil {
 			return nil, err
 		}
 
 		switch tier {
 		case POLICY_TIER2:
-			if err := ruleFlow.LoadField("nxm_nx_xxreg0", ruleFlow.FlowID&FLOW_SEQ_NUM_MASK, MonitorTier2FlowSpaceNXRange); err != nil {
+			if err := ruleFlow.LoadField("nxm_nx_xxreg0", flowCookie.flowSeq(ruleFlow.FlowID), flowCookie.monitorTier2FlowSpaceNXRange()); err != nil {
 				return nil, err
 			}
 		case POLICY_TIER3:
-			if rule.Action == "deny" {
+			if rule.Action == EveroutePolicyDeny || rule.Action == EveroutePolicyReject {
 				if err := ruleFlow.LoadField("nxm_nx_xxreg0", 0x1, MonitorTier3PolicyActionNXRange); err != nil {
 					return nil, err
 				}
 			}
-			if err := ruleFlow.LoadField("nxm_nx_xxreg0", ruleFlow.FlowID&FLOW_SEQ_NUM_MASK, MonitorTier3FlowSpaceNXRange); err != nil {
+			if err := ruleFlow.LoadField("nxm_nx_xxreg0", flowCookie.flowSeq(ruleFlow.FlowID), flowCookie.monitorTier3FlowSpaceNXRange()); err != nil {
 				return nil, err
 			}
 		}
@@ -763,6 +1444,32 @@ func (p *PolicyBridge) AddMicroSegmentRule(rule *EveroutePolicyRule, direction u
 			return nil, err
 		}
 	case "work":
+		// mark packet for decision recording and logging, the first packet of the connection would be sent
+		// to controller in ct commit table, the mark of rules decided before is overwritten
+		logged := rule.Logged && p.datapathManager.Config.EnablePolicyLogging
+		sampled := logged && rule.LogSampleRate > 0 && rule.LogSampleRate < 100
+		var punt, logMark uint64
+		if logged && !sampled {
+			punt, logMark = 0x1, 0x1
+		}
+		if p.datapathManager.decisionRecorder != nil {
+			punt = 0x1
+		}
+		if err := ruleFlow.LoadField("nxm_nx_reg4", punt, PolicyPuntNXRange); err != nil {
+			return nil, err
+		}
+		if err := ruleFlow.LoadField("nxm_nx_reg4", logMark, PolicyLogNXRange); err != nil {
+			return nil, err
+		}
+		if p.datapathManager.ctTimeoutPolicyEnabled() {
+			var ctTimeout uint64
+			if rule.CTTimeout && rule.Action == EveroutePolicyAllow {
+				ctTimeout = 0x1
+			}
+			if err := ruleFlow.LoadField("nxm_nx_reg4", ctTimeout, PolicyCTTimeoutNXRange); err != nil {
+				return nil, err
+			}
+		}
 		switch rule.Action {
 		case "allow":
 			if rule.Priority == GLOBAL_DEFAULT_POLICY_FLOW_PRIORITY {
@@ -770,25 +1477,36 @@ func (p *PolicyBridge) AddMicroSegmentRule(rule *EveroutePolicyRule, direction u
 					return nil, err
 				}
 			}
-		case "deny":
+		case "deny", "reject":
 			if err := ruleFlow.LoadField("nxm_nx_reg4", 0x20, openflow13.NewNXRange(0, 15)); err != nil {
 				return nil, err
 			}
 			if err := ruleFlow.LoadField("nxm_nx_xxreg0", 0x1, WorkPolicyActionNXRange); err != nil {
 				return nil, err
 			}
+			if rule.Action == EveroutePolicyReject {
+				if err := ruleFlow.LoadField("nxm_nx_reg4", 0x1, PolicyRejectNXRange); err != nil {
+					return nil, err
+				}
+			}
 		default:
 			return nil, fmt.Errorf("unknown action")
 		}
 
-		if err := ruleFlow.LoadField("nxm_nx_xxreg0", ruleFlow.FlowID>>FLOW_SEQ_NUM_LENGTH, RoundNumNXRange); err != nil {
+		if err := ruleFlow.LoadField("nxm_nx_xxreg0", flowCookie.roundNum(ruleFlow.FlowID), flowCookie.roundNumNXRange()); err != nil {
 			return nil, err
 		}
-		if err := ruleFlow.LoadField("nxm_nx_xxreg0", ruleFlow.FlowID&FLOW_SEQ_NUM_MASK, openflow13.NewNXRange(60, 87)); err != nil {
+		if err := ruleFlow.LoadField("nxm_nx_xxreg0", flowCookie.flowSeq(ruleFlow.FlowID), flowCookie.workFlowSpaceNXRange()); err != nil {
 			return nil, err
 		}
 
-		if err := ruleFlow.Next(nextTable); err != nil {
+		var next ofctrl.FgraphElem = nextTable
+		if sampled {
+			if next, err = p.logSampleGroup(rule.LogSampleRate, nextTable); err != nil {
+				return nil, err
+			}
+		}
+		if err := ruleFlow.Next(next); err != nil {
 			return nil, err
 		}
 	}
@@ -800,6 +1518,141 @@ func (p *PolicyBridge) AddMicroSegmentRule(rule *EveroutePolicyRule, direction u
 	}, nil
 }
 
+// logSampleGroup returns the select group which marks connections for logging at the sample rate, and
+// resubmits packets to the next table. Groups are shared by rules with the same rate and next table.
+func (p *PolicyBridge) logSampleGroup(rate int32, nextTable *ofctrl.Table) (*ofctrl.Group, error) {
+	p.logSampleGroupMutex.Lock()
+	defer p.logSampleGroupMutex.Unlock()
+
+	groupID := PolicyLogSampleGroupIDBase | uint32(rate)<<8 | uint32(nextTable.TableId)
+	if group, ok := p.logSampleGroups[groupID]; ok {
+		return group, nil
+	}
+	group := p.OfSwitch.GetGroup(groupID)
+	if group == nil {
+		var err error
+		if group, err = p.OfSwitch.NewGroup(groupID, openflow13.OFPGT_SELECT); err != nil {
+			return nil, fmt.Errorf("failed to new log sample group %#x, error: %v", groupID, err)
+		}
+	}
+
+	markField, err := openflow13.FindFieldHeaderByName("nxm_nx_reg4", false)
+	if err != nil {
+		return nil, err
+	}
+	sampledBucket := openflow13.NewBucket()
+	sampledBucket.Weight = uint16(rate)
+	sampledBucket.AddAction(openflow13.NewNXActionRegLoad(PolicyPuntLogNXRange.ToOfsBits(), markField, 0x3))
+	sampledBucket.AddAction(openflow13.NewNXActionResubmitTableAction(openflow13.OFPP_IN_PORT, nextTable.TableId))
+	unsampledBucket := openflow13.NewBucket()
+	unsampledBucket.Weight = uint16(100 - rate)
+	unsampledBucket.AddAction(openflow13.NewNXActionResubmitTableAction(openflow13.OFPP_IN_PORT, nextTable.TableId))
+	sampledBucket.Length, unsampledBucket.Length = sampledBucket.Len(), unsampledBucket.Len()
+
+	// the group may be installed before the bridge reconnected, which is referred by flows of the last round
+	groupMod := openflow13.NewGroupMod()
+	groupMod.GroupId = groupID
+	groupMod.Command = groupModCommandAddOrModify
+	groupMod.Type = openflow13.OFPGT_SELECT
+	groupMod.AddBucket(*sampledBucket)
+	groupMod.AddBucket(*unsampledBucket)
+	p.OfSwitch.Send(groupMod)
+
+	p.logSampleGroups[groupID] = group
+	return group, nil
+}
+
+// ReadRuleFlow reads back the installed rule flow from ovs, returns nil if the flow not found
+func (p *PolicyBridge) ReadRuleFlow(flowEntry *FlowEntry) (*InstalledFlow, error) {
+	cmdStr := fmt.Sprintf("ovs-ofctl -O Openflow13 dump-flows %s 'table=%d,cookie=%#x/-1'", p.name, flowEntry.Table.TableId, flowEntry.FlowID)
+	out, err := exec.Command("/bin/sh", "-c", cmdStr).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to dump flow %#x: %v, output: %s", flowEntry.FlowID, err, string(out))
+	}
+
+	// the first line is the reply header
+	for _, line := range strings.Split(string(out), "\n")[1:] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		return parseOfctlFlow(line)
+	}
+	return nil, nil
+}
+
+// barrier waits until ovs has processed all the messages sent to the bridge before, flows dumped by ovs-ofctl
+// after it would include the flows sent before. ofctrl doesn't deliver barrier reply, a desc request is sent
+// after the barrier request instead, whose reply could only be sent after the barrier completed.
+func (p *PolicyBridge) barrier(timeout time.Duration) error {
+	barrierRequest := openflow13.NewOfp13Header()
+	barrierRequest.Type = openflow13.Type_BarrierRequest
+	descRequest := &openflow13.MultipartRequest{
+		Header: openflow13.NewOfp13Header(),
+		Type:   openflow13.MultipartType_Desc,
+		Body:   util.NewBuffer(nil),
+	}
+	descRequest.Header.Type = openflow13.Type_MultiPartRequest
+
+	replied := make(chan struct{})
+	p.barrierMutex.Lock()
+	p.barrierWaiters[descRequest.Xid] = replied
+	p.barrierMutex.Unlock()
+	defer func() {
+		p.barrierMutex.Lock()
+		delete(p.barrierWaiters, descRequest.Xid)
+		p.barrierMutex.Unlock()
+	}()
+
+	sw := p.getOfSwitch()
+	sw.Send(&barrierRequest)
+	sw.Send(descRequest)
+	select {
+	case <-replied:
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("wait barrier of bridge %s timeout after %s", p.name, timeout)
+	}
+}
+
+func (p *PolicyBridge) barrierReplied(xid uint32) {
+	p.barrierMutex.Lock()
+	defer p.barrierMutex.Unlock()
+	if replied, ok := p.barrierWaiters[xid]; ok {
+		close(replied)
+		delete(p.barrierWaiters, xid)
+	}
+}
+
+// ReadRuleTableFlowIDs reads flow ids of the policy rule table flows installed in ovs, table default
+// flows and ct label match flows are installed on bridge init and would be skipped.
+func (p *PolicyBridge) ReadRuleTableFlowIDs() (sets.Set[uint64], error) {
+	cmdStr := fmt.Sprintf("ovs-ofctl -O Openflow13 dump-flows %s", p.name)
+	out, err := exec.Command("/bin/sh", "-c", cmdStr).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to dump flows: %v, output: %s", err, string(out))
+	}
+
+	ruleTables := p.getRuleTables()
+	flowIDs := sets.New[uint64]()
+	// the first line is the reply header
+	for _, line := range strings.Split(string(out), "\n")[1:] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		flow, err := parseOfctlFlow(line)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := flow.Match["ct_label"]; ok {
+			continue
+		}
+		if ruleTables[flow.TableID] && flow.Priority != DEFAULT_FLOW_MISS_PRIORITY {
+			flowIDs.Insert(flow.Cookie)
+		}
+	}
+	return flowIDs, nil
+}
+
 func (p *PolicyBridge) RemoveMicroSegmentRule(rule *EveroutePolicyRule) error {
 	return nil
 }
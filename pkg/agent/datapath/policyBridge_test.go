@@ -0,0 +1,662 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datapath
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/contiv/libOpenflow/openflow13"
+	"github.com/contiv/libOpenflow/protocol"
+	"github.com/contiv/ofnet/ofctrl"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/everoute/everoute/pkg/constants"
+)
+
+// newIPv4PacketIn returns packet in of an udp packet from 10.0.0.1 to 10.0.0.2 with the ip options
+func newIPv4PacketIn(inPort uint32, options []byte) *ofctrl.PacketIn {
+	ipHeaderLen := 20 + len(options)
+	data := make([]byte, 14+ipHeaderLen+8)
+
+	copy(data[0:6], []byte{0x00, 0x00, 0xaa, 0xaa, 0xaa, 0xbb})
+	copy(data[6:12], []byte{0x00, 0x00, 0xaa, 0xaa, 0xaa, 0xaa})
+	binary.BigEndian.PutUint16(data[12:], 0x0800)
+
+	ip := data[14:]
+	ip[0] = 4<<4 | uint8(ipHeaderLen/4)
+	binary.BigEndian.PutUint16(ip[2:], uint16(ipHeaderLen+8))
+	ip[8] = 64
+	ip[9] = 17
+	copy(ip[12:16], []byte{10, 0, 0, 1})
+	copy(ip[16:20], []byte{10, 0, 0, 2})
+	copy(ip[20:], options)
+
+	udp := ip[ipHeaderLen:]
+	binary.BigEndian.PutUint16(udp[0:], 1000)
+	binary.BigEndian.PutUint16(udp[2:], 2000)
+	binary.BigEndian.PutUint16(udp[4:], 8)
+
+	pkt := &ofctrl.PacketIn{TableId: INGRESS_TIER2_TABLE, Cookie: 0x10000001}
+	if err := pkt.Data.UnmarshalBinary(data); err != nil {
+		panic(err)
+	}
+	pkt.Match.Fields = append(pkt.Match.Fields, *openflow13.NewInPortField(inPort))
+	return pkt
+}
+
+func TestInspectIPOptions(t *testing.T) {
+	RegisterTestingT(t)
+
+	var tableID uint8 = INGRESS_TIER2_TABLE
+	resumeAction, _ := ofctrl.NewConntrackAction(false, false, &tableID, nil).ToOfAction()
+
+	t.Run("packet with source routing options should be dropped", func(t *testing.T) {
+		// loose source and record route: type, length, pointer, route data, end of options
+		lsrr := []byte{0x83, 0x07, 0x04, 10, 0, 0, 3, 0x00}
+		Expect(inspectIPOptions(newIPv4PacketIn(11, lsrr), resumeAction)).Should(BeNil())
+	})
+
+	t.Run("packet without ip options should be resumed in the rule table", func(t *testing.T) {
+		pkt := newIPv4PacketIn(11, nil)
+		pkt.Match.Fields = append(pkt.Match.Fields, *openflow13.NewRegMatchField(constants.OVSReg4, 0x20, nil))
+		packetOut := inspectIPOptions(pkt, resumeAction)
+		Expect(packetOut).ShouldNot(BeNil())
+		Expect(packetOut.InPort).Should(Equal(uint32(11)))
+		Expect(packetOut.Actions).Should(HaveLen(3))
+
+		regRestore, ok := packetOut.Actions[0].(*openflow13.NXActionRegLoad)
+		Expect(ok).Should(BeTrue())
+		Expect(regRestore.DstReg.Field).Should(Equal(uint8(constants.OVSReg4)))
+		Expect(regRestore.Value).Should(Equal(uint64(0x20)))
+
+		regLoad, ok := packetOut.Actions[1].(*openflow13.NXActionRegLoad)
+		Expect(ok).Should(BeTrue())
+		Expect(regLoad.DstReg.Field).Should(Equal(uint8(IPOptionsInspectedReg)))
+		Expect(regLoad.Value).Should(Equal(uint64(0x1)))
+
+		ct, ok := packetOut.Actions[2].(*openflow13.NXActionConnTrack)
+		Expect(ok).Should(BeTrue())
+		Expect(ct.RecircTable).Should(Equal(uint8(INGRESS_TIER2_TABLE)))
+	})
+}
+
+// newTCPPacketIn returns packet in of a tcp segment from 10.0.0.1:1000 to 10.0.0.2:2000 in ct drop table
+func newTCPPacketIn(inPort uint32, flags uint8, seq, ack uint32, payloadLen int) *ofctrl.PacketIn {
+	data := make([]byte, 14+20+20+payloadLen)
+
+	copy(data[0:6], []byte{0x00, 0x00, 0xaa, 0xaa, 0xaa, 0xbb})
+	copy(data[6:12], []byte{0x00, 0x00, 0xaa, 0xaa, 0xaa, 0xaa})
+	binary.BigEndian.PutUint16(data[12:], 0x0800)
+
+	ip := data[14:]
+	ip[0] = 4<<4 | 5
+	binary.BigEndian.PutUint16(ip[2:], uint16(40+payloadLen))
+	ip[8] = 64
+	ip[9] = 6
+	copy(ip[12:16], []byte{10, 0, 0, 1})
+	copy(ip[16:20], []byte{10, 0, 0, 2})
+
+	tcp := ip[20:]
+	binary.BigEndian.PutUint16(tcp[0:], 1000)
+	binary.BigEndian.PutUint16(tcp[2:], 2000)
+	binary.BigEndian.PutUint32(tcp[4:], seq)
+	binary.BigEndian.PutUint32(tcp[8:], ack)
+	tcp[12] = 5 << 4
+	tcp[13] = flags
+
+	pkt := &ofctrl.PacketIn{TableId: CT_DROP_TABLE}
+	if err := pkt.Data.UnmarshalBinary(data); err != nil {
+		panic(err)
+	}
+	pkt.Match.Fields = append(pkt.Match.Fields, *openflow13.NewInPortField(inPort))
+	return pkt
+}
+
+// newIPv6PacketIn returns packet in of the ipv6 packet from fd00::1 to fd00::2 carries the upper layer payload
+// in ct drop table
+func newIPv6PacketIn(inPort uint32, nextHeader uint8, payload []byte) *ofctrl.PacketIn {
+	data := make([]byte, 14+40+len(payload))
+
+	copy(data[0:6], []byte{0x00, 0x00, 0xaa, 0xaa, 0xaa, 0xbb})
+	copy(data[6:12], []byte{0x00, 0x00, 0xaa, 0xaa, 0xaa, 0xaa})
+	binary.BigEndian.PutUint16(data[12:], 0x86dd)
+
+	ip := data[14:]
+	ip[0] = 6 << 4
+	binary.BigEndian.PutUint16(ip[4:], uint16(len(payload)))
+	ip[6] = nextHeader
+	ip[7] = 64
+	copy(ip[8:24], net.ParseIP("fd00::1"))
+	copy(ip[24:40], net.ParseIP("fd00::2"))
+	copy(ip[40:], payload)
+
+	pkt := &ofctrl.PacketIn{TableId: CT_DROP_TABLE}
+	if err := pkt.Data.UnmarshalBinary(data); err != nil {
+		panic(err)
+	}
+	pkt.Match.Fields = append(pkt.Match.Fields, *openflow13.NewInPortField(inPort))
+	return pkt
+}
+
+func TestRejectPacket(t *testing.T) {
+	RegisterTestingT(t)
+
+	replyOf := func(packetOut *openflow13.PacketOut) *protocol.IPv4 {
+		Expect(packetOut).ShouldNot(BeNil())
+		Expect(packetOut.InPort).Should(Equal(uint32(11)))
+		Expect(packetOut.Actions).Should(HaveLen(1))
+		output, ok := packetOut.Actions[0].(*openflow13.ActionOutput)
+		Expect(ok).Should(BeTrue())
+		Expect(output.Port).Should(Equal(uint32(openflow13.P_IN_PORT)))
+
+		eth := packetOut.Data.(*protocol.Ethernet)
+		Expect(eth.HWDst.String()).Should(Equal("00:00:aa:aa:aa:aa"))
+		Expect(eth.HWSrc.String()).Should(Equal("00:00:aa:aa:aa:bb"))
+		ipv4 := eth.Data.(*protocol.IPv4)
+		Expect(ipv4.NWSrc.String()).Should(Equal("10.0.0.2"))
+		Expect(ipv4.NWDst.String()).Should(Equal("10.0.0.1"))
+
+		// checksum over the header including the checksum field should be zero
+		raw, err := ipv4.MarshalBinary()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(checksum(raw[:20])).Should(BeZero())
+		return ipv4
+	}
+
+	t.Run("tcp syn should be replied rst ack", func(t *testing.T) {
+		ipv4 := replyOf(rejectPacket(newTCPPacketIn(11, tcpFlagSYN, 100, 0, 0)))
+		rst := ipv4.Data.(*protocol.TCP)
+		Expect(rst.PortSrc).Should(Equal(uint16(2000)))
+		Expect(rst.PortDst).Should(Equal(uint16(1000)))
+		Expect(rst.Code).Should(Equal(uint8(tcpFlagRST | tcpFlagACK)))
+		Expect(rst.SeqNum).Should(BeZero())
+		Expect(rst.AckNum).Should(Equal(uint32(101)))
+	})
+
+	t.Run("tcp segment with ack should be replied rst with the ack sequence", func(t *testing.T) {
+		ipv4 := replyOf(rejectPacket(newTCPPacketIn(11, tcpFlagACK, 100, 500, 10)))
+		rst := ipv4.Data.(*protocol.TCP)
+		Expect(rst.Code).Should(Equal(uint8(tcpFlagRST)))
+		Expect(rst.SeqNum).Should(Equal(uint32(500)))
+	})
+
+	t.Run("tcp rst should not be replied", func(t *testing.T) {
+		Expect(rejectPacket(newTCPPacketIn(11, tcpFlagRST, 100, 0, 0))).Should(BeNil())
+	})
+
+	t.Run("udp should be replied icmp unreachable", func(t *testing.T) {
+		pkt := newIPv4PacketIn(11, nil)
+		ipv4 := replyOf(rejectPacket(pkt))
+		icmp := ipv4.Data.(*protocol.ICMP)
+		Expect(icmp.Type).Should(Equal(uint8(icmpTypeDestUnreachable)))
+		Expect(icmp.Code).Should(Equal(uint8(icmpCodeAdminProhibited)))
+		// unused 4 bytes, original ip header and 8 bytes of the udp
+		Expect(icmp.Data).Should(HaveLen(4 + 20 + 8))
+		raw, err := icmp.MarshalBinary()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(checksum(raw)).Should(BeZero())
+	})
+
+	t.Run("packet with ip options should not be replied", func(t *testing.T) {
+		lsrr := []byte{0x83, 0x07, 0x04, 10, 0, 0, 3, 0x00}
+		Expect(rejectPacket(newIPv4PacketIn(11, lsrr))).Should(BeNil())
+	})
+}
+
+func TestRejectIPv6Packet(t *testing.T) {
+	RegisterTestingT(t)
+
+	replyOf := func(packetOut *openflow13.PacketOut) (*protocol.IPv6, []byte) {
+		Expect(packetOut).ShouldNot(BeNil())
+		Expect(packetOut.InPort).Should(Equal(uint32(11)))
+		Expect(packetOut.Actions).Should(HaveLen(1))
+
+		eth := packetOut.Data.(*protocol.Ethernet)
+		Expect(eth.Ethertype).Should(Equal(uint16(protocol.IPv6_MSG)))
+		Expect(eth.HWDst.String()).Should(Equal("00:00:aa:aa:aa:aa"))
+		ipv6 := eth.Data.(*protocol.IPv6)
+		Expect(ipv6.NWSrc.String()).Should(Equal("fd00::2"))
+		Expect(ipv6.NWDst.String()).Should(Equal("fd00::1"))
+
+		// checksum over the pseudo header and the payload including the checksum field should be zero
+		raw, err := ipv6.MarshalBinary()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(raw).Should(HaveLen(40 + int(ipv6.Length)))
+		pseudoHeader := make([]byte, 40)
+		copy(pseudoHeader, raw[8:40])
+		binary.BigEndian.PutUint32(pseudoHeader[32:], uint32(ipv6.Length))
+		pseudoHeader[39] = ipv6.NextHeader
+		Expect(checksum(append(pseudoHeader, raw[40:]...))).Should(BeZero())
+		return ipv6, raw[40:]
+	}
+	tcpSegment := func(flags uint8, seq uint32) []byte {
+		tcp := make([]byte, 20)
+		binary.BigEndian.PutUint16(tcp[0:], 1000)
+		binary.BigEndian.PutUint16(tcp[2:], 2000)
+		binary.BigEndian.PutUint32(tcp[4:], seq)
+		tcp[12] = 5 << 4
+		tcp[13] = flags
+		return tcp
+	}
+
+	t.Run("tcp syn should be replied rst ack", func(t *testing.T) {
+		ipv6, payload := replyOf(rejectPacket(newIPv6PacketIn(11, protocol.Type_TCP, tcpSegment(tcpFlagSYN, 100))))
+		Expect(ipv6.NextHeader).Should(Equal(uint8(protocol.Type_TCP)))
+		rst := protocol.NewTCP()
+		Expect(rst.UnmarshalBinary(payload)).Should(Succeed())
+		Expect(rst.PortSrc).Should(Equal(uint16(2000)))
+		Expect(rst.PortDst).Should(Equal(uint16(1000)))
+		Expect(rst.Code).Should(Equal(uint8(tcpFlagRST | tcpFlagACK)))
+		Expect(rst.AckNum).Should(Equal(uint32(101)))
+	})
+
+	t.Run("tcp rst should not be replied", func(t *testing.T) {
+		Expect(rejectPacket(newIPv6PacketIn(11, protocol.Type_TCP, tcpSegment(tcpFlagRST, 100)))).Should(BeNil())
+	})
+
+	t.Run("udp should be replied icmpv6 unreachable", func(t *testing.T) {
+		udp := []byte{0x03, 0xe8, 0x07, 0xd0, 0x00, 0x08, 0x00, 0x00}
+		ipv6, _ := replyOf(rejectPacket(newIPv6PacketIn(11, protocol.Type_UDP, udp)))
+		Expect(ipv6.NextHeader).Should(Equal(uint8(protocol.Type_IPv6ICMP)))
+		icmp := ipv6.Data.(*protocol.ICMP)
+		Expect(icmp.Type).Should(Equal(uint8(icmpv6TypeDestUnreachable)))
+		Expect(icmp.Code).Should(Equal(uint8(icmpv6CodeAdminProhibited)))
+		// unused 4 bytes and the whole original packet
+		Expect(icmp.Data).Should(HaveLen(4 + 40 + 8))
+	})
+
+	t.Run("icmpv6 unreachable should carry the original packet within the minimum mtu", func(t *testing.T) {
+		udp := make([]byte, 1500)
+		binary.BigEndian.PutUint16(udp[4:], 1500)
+		ipv6, _ := replyOf(rejectPacket(newIPv6PacketIn(11, protocol.Type_UDP, udp)))
+		Expect(40 + int(ipv6.Length)).Should(Equal(icmpv6ErrorMaxLength))
+	})
+
+	t.Run("icmpv6 echo request should be replied and icmpv6 error should not", func(t *testing.T) {
+		echo := []byte{icmpv6TypeEchoRequest, 0, 0, 0, 0, 1, 0, 1}
+		replyOf(rejectPacket(newIPv6PacketIn(11, protocol.Type_IPv6ICMP, echo)))
+		unreachable := []byte{icmpv6TypeDestUnreachable, 0, 0, 0, 0, 0, 0, 0}
+		Expect(rejectPacket(newIPv6PacketIn(11, protocol.Type_IPv6ICMP, unreachable))).Should(BeNil())
+	})
+}
+
+func TestPolicyCTZoneByVlan(t *testing.T) {
+	RegisterTestingT(t)
+
+	newPolicyBridge := func(strategy string) *PolicyBridge {
+		dpMgr := NewDatapathManager(&DpManagerConfig{ManagedVDSMap: map[string]string{}, CTZoneStrategy: strategy}, nil)
+		return NewPolicyBridge("ovsbr1", dpMgr)
+	}
+	getCTAction := func(p *PolicyBridge) *openflow13.NXActionConnTrack {
+		var ctStateTableID uint8 = CT_STATE_TABLE
+		ctAction, err := p.newPolicyConntrackAction(false, &ctStateTableID)
+		Expect(err).ShouldNot(HaveOccurred())
+		action, err := ctAction.ToOfAction()
+		Expect(err).ShouldNot(HaveOccurred())
+		return action.(*openflow13.NXActionConnTrack)
+	}
+
+	t.Run("endpoints with the same ip on different vlans should have isolated ct zone", func(t *testing.T) {
+		zone10, zone20 := PolicyCTZoneOfVlan(10), PolicyCTZoneOfVlan(20)
+		Expect(zone10).ShouldNot(Equal(zone20))
+		for _, zone := range []uint16{PolicyCTZoneOfVlan(0), zone10, zone20, PolicyCTZoneOfVlan(4095)} {
+			Expect(zone).Should(BeNumerically(">=", constants.CTZoneForPolicyVlanBase))
+			Expect(zone).Should(BeNumerically("<", constants.CTZoneUplinkBr))
+		}
+	})
+
+	t.Run("ct action should use zone from reg7 when assign ct zone by vlan", func(t *testing.T) {
+		zoneField, err := openflow13.FindFieldHeaderByName(PolicyCTZoneReg, true)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		ctAction := getCTAction(newPolicyBridge(CTZoneStrategyVlan))
+		Expect(ctAction.ZoneSrc).Should(Equal(zoneField.MarshalHeader()))
+		Expect(ctAction.ZoneOfsNbits).Should(Equal(PolicyCTZoneNXRange.ToOfsBits()))
+	})
+
+	t.Run("ct action should use the policy zone by default", func(t *testing.T) {
+		for _, strategy := range []string{"", CTZoneStrategyGlobal} {
+			ctAction := getCTAction(newPolicyBridge(strategy))
+			Expect(ctAction.ZoneSrc).Should(BeZero())
+			Expect(ctAction.ZoneOfsNbits).Should(Equal(constants.CTZoneForPolicy))
+		}
+	})
+}
+
+func TestRuleFlowVlanMatch(t *testing.T) {
+	RegisterTestingT(t)
+
+	t.Run("rule flow should match vlan id with vlan present flag", func(t *testing.T) {
+		flowMatch := ofctrl.FlowMatch{Ethertype: PROTOCOL_IP}
+		setRuleFlowVlanMatch(&flowMatch, 100)
+		Expect(flowMatch.VlanId).Should(Equal(uint16(100)))
+		Expect(flowMatch.VlanIdMask).ShouldNot(BeNil())
+
+		field := openflow13.NewVlanIdField(flowMatch.VlanId, flowMatch.VlanIdMask)
+		Expect(field.Value.(*openflow13.VlanIdField).VlanId).Should(Equal(uint16(100 | openflow13.OFPVID_PRESENT)))
+		Expect(field.HasMask).Should(BeTrue())
+		Expect(field.Mask.(*openflow13.VlanIdField).VlanId).Should(Equal(uint16(openflow13.OFPVID_PRESENT | VlanIDMask)))
+	})
+
+	t.Run("rule flow should not match vlan without vlan id", func(t *testing.T) {
+		flowMatch := ofctrl.FlowMatch{Ethertype: PROTOCOL_IP}
+		setRuleFlowVlanMatch(&flowMatch, 0)
+		Expect(flowMatch.VlanId).Should(BeZero())
+		Expect(flowMatch.VlanIdMask).Should(BeNil())
+	})
+}
+
+func TestTableMissActionPerVDS(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := NewDatapathManager(&DpManagerConfig{ManagedVDSMap: map[string]string{}}, nil)
+	dpMgr.BridgeChainMap["vds1"] = map[string]Bridge{POLICY_BRIDGE_KEYWORD: NewPolicyBridge("ovsbr1", dpMgr)}
+	dpMgr.BridgeChainMap["vds2"] = map[string]Bridge{POLICY_BRIDGE_KEYWORD: NewPolicyBridge("ovsbr2", dpMgr)}
+	assertTableMissAction := func(vdsID string, expect TableMissAction) {
+		action, err := dpMgr.GetTableMissAction(vdsID)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(action).Should(Equal(expect))
+	}
+
+	t.Run("table-miss action should be fail closed by default", func(t *testing.T) {
+		assertTableMissAction("vds1", TableMissFailClosed)
+		assertTableMissAction("vds2", TableMissFailClosed)
+	})
+
+	t.Run("fail open on one vds should not affect the other", func(t *testing.T) {
+		Expect(dpMgr.SetTableMissAction("vds1", TableMissFailOpen)).Should(Succeed())
+		assertTableMissAction("vds1", TableMissFailOpen)
+		assertTableMissAction("vds2", TableMissFailClosed)
+
+		Expect(dpMgr.SetTableMissAction("vds1", TableMissFailClosed)).Should(Succeed())
+		assertTableMissAction("vds1", TableMissFailClosed)
+		assertTableMissAction("vds2", TableMissFailClosed)
+	})
+
+	t.Run("should reject unknown vds or action", func(t *testing.T) {
+		Expect(dpMgr.SetTableMissAction("vds3", TableMissFailOpen)).ShouldNot(Succeed())
+		Expect(dpMgr.SetTableMissAction("vds2", "Forward")).ShouldNot(Succeed())
+		assertTableMissAction("vds2", TableMissFailClosed)
+	})
+}
+
+func TestARPBlockIPs(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := NewDatapathManager(&DpManagerConfig{ManagedVDSMap: map[string]string{}}, nil)
+	policyBridge := NewPolicyBridge("ovsbr1", dpMgr)
+	dpMgr.BridgeChainMap["vds1"] = map[string]Bridge{POLICY_BRIDGE_KEYWORD: policyBridge}
+	dpMgr.disabledRuleGroups.Insert("disabled-group")
+	addRule := func(ruleID, dstIPAddr, ruleGroup string, blockARP bool) {
+		rule := &EveroutePolicyRule{RuleID: ruleID, DstIPAddr: dstIPAddr, Action: EveroutePolicyDeny}
+		dpMgr.Rules[ruleID] = &EveroutePolicyRuleEntry{EveroutePolicyRule: rule, Direction: POLICY_DIRECTION_IN,
+			Tier: POLICY_TIER1, RuleGroupReference: map[string]string{ruleID: ruleGroup}, ARPBlockReference: sets.NewString(),
+			RuleFlowMap: map[string]*FlowEntry{}}
+		setARPBlockReference(dpMgr.Rules[ruleID], ruleID, blockARP)
+	}
+
+	addRule("isolation", "10.0.0.1/32", "", true)
+	addRule("isolation-v6", "fe80::1/128", "", true)
+	addRule("normal", "10.0.0.2/32", "", false)
+	addRule("apply-to-all", "", "", true)
+	addRule("group-disabled", "10.0.0.3/32", "disabled-group", true)
+
+	t.Run("should block arp of endpoints isolated by enabled rules", func(t *testing.T) {
+		Expect(dpMgr.syncARPBlockFlows()).Should(Succeed())
+		Expect(sets.List(policyBridge.arpBlockIPs)).Should(ConsistOf("10.0.0.1/32", "fe80::1/128"))
+	})
+
+	t.Run("should unblock arp after rule removed", func(t *testing.T) {
+		delete(dpMgr.Rules, "isolation-v6")
+		Expect(dpMgr.syncARPBlockFlows()).Should(Succeed())
+		Expect(sets.List(policyBridge.arpBlockIPs)).Should(ConsistOf("10.0.0.1/32"))
+	})
+
+	t.Run("should drop arp and neighbor discovery of the endpoint", func(t *testing.T) {
+		matches, err := arpBlockFlowMatches("10.0.0.1/32")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(matches).Should(HaveLen(2))
+		Expect(matches).Should(HaveEach(HaveField("Ethertype", uint16(PROTOCOL_ARP))))
+		Expect(matches[0].ArpSpa.String()).Should(Equal("10.0.0.1"))
+		Expect(matches[1].ArpTpa.String()).Should(Equal("10.0.0.1"))
+
+		matches, err = arpBlockFlowMatches("fe80::1")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(matches).Should(HaveLen(2))
+		Expect(matches).Should(HaveEach(HaveField("IpProto", uint8(PROTOCOL_ICMPV6))))
+		for i, icmpv6Type := range []uint8{icmpv6TypeNeighborSolicitation, icmpv6TypeNeighborAdvertisement} {
+			Expect(matches[i].Ipv6Sa).Should(BeNil())
+			Expect(matches[i].Ipv6Da).Should(BeNil())
+			Expect(matches[i].RawMatchField).Should(HaveLen(2))
+			Expect(matches[i].RawMatchField[0].Value).Should(Equal(&openflow13.IcmpTypeField{Type: icmpv6Type}))
+			Expect(matches[i].RawMatchField[1].Value.(*openflow13.Ipv6DstField).Ipv6Dst.String()).Should(Equal("fe80::1"))
+		}
+
+		_, err = arpBlockFlowMatches("10.0.0.256")
+		Expect(err).Should(HaveOccurred())
+	})
+}
+
+func TestRSTDetector(t *testing.T) {
+	RegisterTestingT(t)
+
+	now := time.Now()
+	newDetector := func() *rstDetector {
+		return newRSTDetector(&TCPRSTDetectConfig{Threshold: 3, Window: 10 * time.Second, DenyTimeout: 30 * time.Second})
+	}
+
+	t.Run("source exceeding the threshold should be denied once", func(t *testing.T) {
+		detector := newDetector()
+		for i := 0; i < 3; i++ {
+			Expect(detector.observe("10.0.0.1", now)).Should(BeFalse())
+		}
+		Expect(detector.observe("10.0.0.2", now)).Should(BeFalse())
+		Expect(detector.observe("10.0.0.1", now)).Should(BeTrue())
+		Expect(detector.observe("10.0.0.1", now)).Should(BeFalse())
+		Expect(detector.deniedSources()).Should(ConsistOf("10.0.0.1"))
+	})
+
+	t.Run("rst in different windows should not be accumulated", func(t *testing.T) {
+		detector := newDetector()
+		for i := 0; i < 8; i++ {
+			Expect(detector.observe("10.0.0.1", now.Add(time.Duration(i)*5*time.Second))).Should(BeFalse())
+		}
+		Expect(detector.deniedSources()).Should(BeEmpty())
+	})
+
+	t.Run("deny of source should expire after deny timeout", func(t *testing.T) {
+		detector := newDetector()
+		for i := 0; i < 4; i++ {
+			detector.observe("10.0.0.1", now)
+		}
+		detector.expire(now.Add(29 * time.Second))
+		Expect(detector.deniedSources()).Should(ConsistOf("10.0.0.1"))
+		detector.expire(now.Add(30 * time.Second))
+		Expect(detector.deniedSources()).Should(BeEmpty())
+		Expect(detector.observe("10.0.0.1", now.Add(30*time.Second))).Should(BeFalse())
+	})
+}
+
+func TestProcessRSTPacket(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := NewDatapathManager(&DpManagerConfig{
+		ManagedVDSMap: map[string]string{},
+		TCPRSTDetect:  &TCPRSTDetectConfig{Threshold: 2, Window: time.Minute, DenyTimeout: time.Minute},
+	}, nil)
+
+	_, allowCIDR, _ := net.ParseCIDR("10.0.1.0/24")
+	dpMgr.Config.TCPRSTDetect.AllowCIDRs = []*net.IPNet{allowCIDR}
+	policyBridge := NewPolicyBridge("ovsbr1", dpMgr)
+
+	t.Run("source exceeding the threshold should be denied", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			pkt := newIPv4PacketIn(11, nil)
+			pkt.TableId = CT_STATE_TABLE
+			policyBridge.PacketRcvd(nil, pkt)
+		}
+		Expect(policyBridge.rstDetector.deniedSources()).Should(ConsistOf("10.0.0.1"))
+	})
+
+	t.Run("source in the allowlist should never be denied", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			pkt := newIPv4PacketIn(11, nil)
+			pkt.TableId = CT_STATE_TABLE
+			pkt.Data.Data.(*protocol.IPv4).NWSrc = net.ParseIP("10.0.1.1")
+			policyBridge.PacketRcvd(nil, pkt)
+		}
+		Expect(policyBridge.rstDetector.deniedSources()).Should(ConsistOf("10.0.0.1"))
+	})
+}
+
+// puntPacketIn makes the packet in sent by the ct commit punt flow, the round num and work flow sequence of
+// the rule flow decided the packet are carried in xxreg0 with the default flow cookie layout
+func puntPacketIn(pkt *ofctrl.PacketIn, ruleFlowID uint64) *ofctrl.PacketIn {
+	pkt.TableId, pkt.Reason, pkt.Cookie = CT_COMMIT_TABLE, openflow13.R_ACTION, 0x7
+	flowSeq := defaultFlowCookie.flowSeq(ruleFlowID)
+	workStart := FlowCookieBits + defaultFlowCookie.FlowSeqBits
+	low := defaultFlowCookie.roundNum(ruleFlowID) | flowSeq<<workStart
+	high := flowSeq >> (64 - workStart)
+	for i, reg := range []uint64{high >> 32, high & 0xffffffff, low >> 32, low & 0xffffffff} {
+		pkt.Match.Fields = append(pkt.Match.Fields, *openflow13.NewRegMatchField(i, uint32(reg), nil))
+	}
+	return pkt
+}
+
+// markLogged marks the packet in for logging like the rule flow enabled logging or the sampled bucket
+func markLogged(pkt *ofctrl.PacketIn) *ofctrl.PacketIn {
+	mark := uint32(1<<PolicyPuntReg4Bit | 1<<PolicyLogReg4Bit)
+	pkt.Match.Fields = append(pkt.Match.Fields, *openflow13.NewRegMatchField(constants.OVSReg4, mark, nil))
+	return pkt
+}
+
+func TestRecordDecision(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := NewDatapathManager(&DpManagerConfig{
+		ManagedVDSMap:      map[string]string{},
+		DecisionRecordSize: 2,
+	}, nil)
+	dpMgr.FlowIDToRules[0x10000001] = &EveroutePolicyRuleEntry{
+		EveroutePolicyRule: &EveroutePolicyRule{RuleID: "rule1", Action: EveroutePolicyAllow},
+	}
+	dpMgr.FlowIDToRules[0x10000002] = &EveroutePolicyRuleEntry{
+		EveroutePolicyRule: &EveroutePolicyRule{RuleID: "rule2", Action: EveroutePolicyDeny},
+	}
+	policyBridge := NewPolicyBridge("ovsbr1", dpMgr)
+
+	t.Run("packet sent for ip options inspection should not be recorded", func(t *testing.T) {
+		lsrr := []byte{0x83, 0x07, 0x04, 10, 0, 0, 3, 0x00}
+		policyBridge.PacketRcvd(nil, newIPv4PacketIn(11, lsrr))
+		Expect(dpMgr.GetPolicyDecisions()).Should(BeEmpty())
+	})
+
+	t.Run("sampled packets should be recorded with verdict and matched rule", func(t *testing.T) {
+		policyBridge.PacketRcvd(nil, puntPacketIn(newIPv4PacketIn(11, nil), 0x10000001))
+		policyBridge.PacketRcvd(nil, puntPacketIn(newTCPPacketIn(11, tcpFlagSYN, 100, 0, 0), 0x10000002))
+
+		decisions := dpMgr.GetPolicyDecisions()
+		Expect(decisions).Should(HaveLen(2))
+		Expect(decisions[0].BridgeName).Should(Equal("ovsbr1-policy"))
+		Expect(decisions[0].SrcIP).Should(Equal("10.0.0.1"))
+		Expect(decisions[0].DstIP).Should(Equal("10.0.0.2"))
+		Expect(decisions[0].IPProtocol).Should(Equal(uint8(protocol.Type_UDP)))
+		Expect(decisions[0].SrcPort).Should(Equal(uint16(1000)))
+		Expect(decisions[0].DstPort).Should(Equal(uint16(2000)))
+		Expect(decisions[0].FlowID).Should(Equal(uint64(0x10000001)))
+		Expect(decisions[0].RuleID).Should(Equal("rule1"))
+		Expect(decisions[0].Action).Should(Equal(EveroutePolicyAllow))
+
+		Expect(decisions[1].IPProtocol).Should(Equal(uint8(protocol.Type_TCP)))
+		Expect(decisions[1].SrcPort).Should(Equal(uint16(1000)))
+		Expect(decisions[1].DstPort).Should(Equal(uint16(2000)))
+		Expect(decisions[1].RuleID).Should(Equal("rule2"))
+		Expect(decisions[1].Action).Should(Equal(EveroutePolicyDeny))
+	})
+
+	t.Run("oldest decision should be overwritten when the buffer full", func(t *testing.T) {
+		policyBridge.PacketRcvd(nil, puntPacketIn(newIPv4PacketIn(11, nil), 0x10000003))
+
+		decisions := dpMgr.GetPolicyDecisions()
+		Expect(decisions).Should(HaveLen(2))
+		Expect(decisions[0].RuleID).Should(Equal("rule2"))
+		Expect(decisions[1].FlowID).Should(Equal(uint64(0x10000003)))
+		Expect(decisions[1].RuleID).Should(BeEmpty(), "flow not owned by any rule")
+	})
+}
+
+func TestLogRuleDecision(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := NewDatapathManager(&DpManagerConfig{ManagedVDSMap: map[string]string{}}, nil)
+	dpMgr.FlowIDToRules[0x10000001] = &EveroutePolicyRuleEntry{
+		EveroutePolicyRule: &EveroutePolicyRule{RuleID: "allow-rule", Action: EveroutePolicyAllow},
+	}
+	dpMgr.FlowIDToRules[0x10000002] = &EveroutePolicyRuleEntry{
+		EveroutePolicyRule: &EveroutePolicyRule{RuleID: "deny-rule", Action: EveroutePolicyDeny, Logged: true},
+		LoggingTags:        map[string]string{constants.LoggingTagPolicyType: "SecurityPolicyAllow"},
+	}
+	var logged []PolicyDecision
+	dpMgr.decisionLogFunc = func(decision PolicyDecision, loggingTags map[string]string) {
+		Expect(loggingTags).Should(HaveKeyWithValue(constants.LoggingTagPolicyType, "SecurityPolicyAllow"))
+		logged = append(logged, decision)
+	}
+	policyBridge := NewPolicyBridge("ovsbr1", dpMgr)
+
+	policyBridge.PacketRcvd(nil, puntPacketIn(newIPv4PacketIn(11, nil), 0x10000001))
+	Expect(logged).Should(BeEmpty(), "allow rule not logged")
+
+	policyBridge.PacketRcvd(nil, puntPacketIn(newIPv4PacketIn(11, nil), 0x10000002))
+	Expect(logged).Should(BeEmpty(), "connection not marked for logging")
+
+	policyBridge.PacketRcvd(nil, markLogged(puntPacketIn(newIPv4PacketIn(11, nil), 0x10000002)))
+	Expect(logged).Should(HaveLen(1))
+	Expect(logged[0].RuleID).Should(Equal("deny-rule"))
+	Expect(logged[0].Action).Should(Equal(EveroutePolicyDeny))
+	Expect(logged[0].SrcIP).Should(Equal("10.0.0.1"))
+	Expect(logged[0].DstIP).Should(Equal("10.0.0.2"))
+	Expect(dpMgr.GetPolicyDecisions()).Should(BeEmpty(), "decision recording disabled")
+}
+
+func TestLogRuleDecisionSampling(t *testing.T) {
+	RegisterTestingT(t)
+
+	// connections are sampled by the select group in datapath, only sampled connections are marked for logging
+	dpMgr := NewDatapathManager(&DpManagerConfig{ManagedVDSMap: map[string]string{}, DecisionRecordSize: 100}, nil)
+	dpMgr.FlowIDToRules[0x10000001] = &EveroutePolicyRuleEntry{
+		EveroutePolicyRule: &EveroutePolicyRule{RuleID: "allow-rule", Action: EveroutePolicyAllow, Logged: true, LogSampleRate: 25},
+	}
+	var logged = make(map[string]int)
+	dpMgr.decisionLogFunc = func(decision PolicyDecision, _ map[string]string) {
+		logged[decision.RuleID]++
+	}
+	policyBridge := NewPolicyBridge("ovsbr1", dpMgr)
+
+	for i := 0; i < 100; i++ {
+		pkt := puntPacketIn(newIPv4PacketIn(11, nil), 0x10000001)
+		if i%4 == 0 {
+			pkt = markLogged(pkt)
+		}
+		policyBridge.PacketRcvd(nil, pkt)
+	}
+	Expect(logged).Should(HaveKeyWithValue("allow-rule", 25), "only sampled connections logged")
+	Expect(dpMgr.GetPolicyDecisions()).Should(HaveLen(100), "all connections recorded")
+}
@@ -0,0 +1,398 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datapath
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/everoute/everoute/pkg/constants"
+)
+
+// PolicyTier maps the tier name of policy to the tier of policy rule and its tables in policy bridge
+type PolicyTier struct {
+	Name         string
+	ID           uint8
+	EgressTable  uint8
+	IngressTable uint8
+}
+
+// PolicyTiers is the ordered list of policy tiers packet walked through in policy bridge, rules of a tier
+// override rules of the tiers after it. Custom tiers are inserted among the builtin tiers, they only
+// support work mode.
+type PolicyTiers []PolicyTier
+
+var builtinPolicyTiers = PolicyTiers{
+	{Name: constants.Tier0, ID: POLICY_TIER1, EgressTable: EGRESS_TIER1_TABLE, IngressTable: INGRESS_TIER1_TABLE},
+	{Name: constants.Tier1, ID: POLICY_TIER2, EgressTable: EGRESS_TIER2_TABLE, IngressTable: INGRESS_TIER2_TABLE},
+	{Name: constants.TierECP, ID: POLICY_TIER_ECP, EgressTable: EGRESS_TIER_ECP_TABLE, IngressTable: INGRESS_TIER_ECP_TABLE},
+	{Name: constants.Tier2, ID: POLICY_TIER3, EgressTable: EGRESS_TIER3_TABLE, IngressTable: INGRESS_TIER3_TABLE},
+}
+
+// policyTierMonitorTables are the monitor tables of builtin tiers support monitor mode, packet walks
+// through the monitor table of a tier before its work table.
+var policyTierMonitorTables = map[uint8]map[uint8]uint8{
+	POLICY_TIER2: {POLICY_DIRECTION_OUT: EGRESS_TIER2_MONITOR_TABLE, POLICY_DIRECTION_IN: INGRESS_TIER2_MONITOR_TABLE},
+	POLICY_TIER3: {POLICY_DIRECTION_OUT: EGRESS_TIER3_MONITOR_TABLE, POLICY_DIRECTION_IN: INGRESS_TIER3_MONITOR_TABLE},
+}
+
+var defaultPolicyTiers = DefaultPolicyTiers()
+
+// DefaultPolicyTiers returns the builtin tiers tier0, tier1, tier-ecp and tier2 in order
+func DefaultPolicyTiers() PolicyTiers {
+	return append(PolicyTiers{}, builtinPolicyTiers...)
+}
+
+// Validate checks builtin tiers are kept unchanged in order, and custom tiers take unique ids and
+// unused tables in the egress and ingress policy table ranges.
+func (t PolicyTiers) Validate() error {
+	names := make(map[string]bool, len(t))
+	ids := make(map[uint8]bool, len(t))
+	tables := make(map[uint8]bool)
+	for _, tier := range builtinPolicyTiers {
+		tables[tier.EgressTable], tables[tier.IngressTable] = true, true
+		for _, monitorTable := range policyTierMonitorTables[tier.ID] {
+			tables[monitorTable] = true
+		}
+	}
+
+	builtinIndex := 0
+	for _, tier := range t {
+		if tier.Name == "" || names[tier.Name] {
+			return fmt.Errorf("tier name %q must be non-empty and unique", tier.Name)
+		}
+		if tier.ID == 0 || ids[tier.ID] {
+			return fmt.Errorf("tier %s id %d must be positive and unique", tier.Name, tier.ID)
+		}
+		names[tier.Name], ids[tier.ID] = true, true
+
+		if builtin, ok := BuiltinPolicyTier(tier.Name); ok {
+			if tier != builtin {
+				return fmt.Errorf("builtin tier %s can't be changed, expect %+v", tier.Name, builtin)
+			}
+			if builtinPolicyTiers[builtinIndex] != builtin {
+				return fmt.Errorf("builtin tier %s out of order, expect tier %s", tier.Name, builtinPolicyTiers[builtinIndex].Name)
+			}
+			builtinIndex++
+			continue
+		}
+
+		if tier.EgressTable <= EGRESS_TIER1_TABLE || tier.EgressTable >= INGRESS_TIER1_TABLE || tables[tier.EgressTable] {
+			return fmt.Errorf("egress table %d of tier %s must be unused in (%d, %d)",
+				tier.EgressTable, tier.Name, EGRESS_TIER1_TABLE, INGRESS_TIER1_TABLE)
+		}
+		tables[tier.EgressTable] = true
+		if tier.IngressTable <= INGRESS_TIER1_TABLE || tier.IngressTable >= CT_COMMIT_TABLE || tables[tier.IngressTable] {
+			return fmt.Errorf("ingress table %d of tier %s must be unused in (%d, %d)",
+				tier.IngressTable, tier.Name, INGRESS_TIER1_TABLE, CT_COMMIT_TABLE)
+		}
+		tables[tier.IngressTable] = true
+	}
+
+	if builtinIndex != len(builtinPolicyTiers) {
+		return fmt.Errorf("builtin tier %s is missing", builtinPolicyTiers[builtinIndex].Name)
+	}
+	return nil
+}
+
+// BuiltinPolicyTier returns the builtin tier of the name
+func BuiltinPolicyTier(name string) (PolicyTier, bool) {
+	for _, tier := range builtinPolicyTiers {
+		if tier.Name == name {
+			return tier, true
+		}
+	}
+	return PolicyTier{}, false
+}
+
+// TierID returns the tier of policy rule by the tier name of policy
+func (t PolicyTiers) TierID(name string) (uint8, bool) {
+	for _, tier := range t {
+		if tier.Name == name {
+			return tier.ID, true
+		}
+	}
+	return 0, false
+}
+
+// order returns the tiers of policy rule in the order packet walked through
+func (t PolicyTiers) order() []uint8 {
+	order := make([]uint8, 0, len(t))
+	for _, tier := range t {
+		order = append(order, tier.ID)
+	}
+	return order
+}
+
+// table returns the work mode table of the tier in the direction
+func (t PolicyTiers) table(direction uint8, id uint8) (uint8, bool) {
+	for _, tier := range t {
+		if tier.ID != id {
+			continue
+		}
+		if direction == POLICY_DIRECTION_OUT {
+			return tier.EgressTable, true
+		}
+		return tier.IngressTable, true
+	}
+	return 0, false
+}
+
+// tableChain returns the tables of all tiers in the direction in the order packet walked through,
+// including the monitor tables.
+func (t PolicyTiers) tableChain(direction uint8) []uint8 {
+	var chain []uint8
+	for _, tier := range t {
+		if monitorTable, ok := policyTierMonitorTables[tier.ID][direction]; ok {
+			chain = append(chain, monitorTable)
+		}
+		table, _ := t.table(direction, tier.ID)
+		chain = append(chain, table)
+	}
+	return chain
+}
+
+// ruleTables returns the tables which policy rule flows installed in
+func (t PolicyTiers) ruleTables() map[uint8]bool {
+	ruleTables := make(map[uint8]bool)
+	for _, direction := range []uint8{POLICY_DIRECTION_OUT, POLICY_DIRECTION_IN} {
+		for _, table := range t.tableChain(direction) {
+			ruleTables[table] = true
+		}
+	}
+	return ruleTables
+}
+
+func (datapathManager *DpManager) policyTiers() PolicyTiers {
+	if datapathManager.Config == nil || datapathManager.Config.PolicyTiers == nil {
+		return defaultPolicyTiers
+	}
+	return datapathManager.Config.PolicyTiers
+}
+
+// PolicyTierID returns the tier of policy rule by the tier name of policy in the configured tiers
+func (datapathManager *DpManager) PolicyTierID(name string) (uint8, bool) {
+	datapathManager.lockRflowReplayWithTimeout()
+	defer datapathManager.flowReplayMutex.RUnlock()
+
+	return datapathManager.policyTiers().TierID(name)
+}
+
+// checkReload checks tiers of the installed rules are kept in the reloaded tiers with the same name and id in
+// the same order, or the installed rules would invert their priorities. Tiers without installed rules could be
+// added, removed or reordered freely.
+func (t PolicyTiers) checkReload(old PolicyTiers, installed sets.Set[uint8]) error {
+	var oldOrder, newOrder []string
+	for _, tier := range old {
+		if !installed.Has(tier.ID) {
+			continue
+		}
+		oldOrder = append(oldOrder, tier.Name)
+		if name, ok := t.tierName(tier.ID); !ok || name != tier.Name {
+			return fmt.Errorf("tier %s with installed rules can't be removed or renamed", tier.Name)
+		}
+	}
+	for _, tier := range t {
+		if installed.Has(tier.ID) {
+			newOrder = append(newOrder, tier.Name)
+		}
+	}
+	for index := range oldOrder {
+		if oldOrder[index] != newOrder[index] {
+			return fmt.Errorf("tiers with installed rules %v can't be reordered to %v", oldOrder, newOrder)
+		}
+	}
+	return nil
+}
+
+func (t PolicyTiers) tierName(id uint8) (string, bool) {
+	for _, tier := range t {
+		if tier.ID == id {
+			return tier.Name, true
+		}
+	}
+	return "", false
+}
+
+// ReloadTierConfig validates the policy tiers and applies them without restarting agent, see checkReload for
+// changes allowed. Rules of the tiers whose tables changed are moved to the new tables, the new rule flows are
+// installed before packets switched to walk through the new tables, so packets are always decided by the rules.
+// On the disconnected bridges, flows of the moved rules are dropped and would be reinstalled on replay.
+func (datapathManager *DpManager) ReloadTierConfig(tiers PolicyTiers) error {
+	if err := tiers.Validate(); err != nil {
+		return fmt.Errorf("invalid policy tiers: %s", err)
+	}
+
+	datapathManager.lockflowReplayWithTimeout()
+	defer datapathManager.flowReplayMutex.Unlock()
+
+	oldTiers := datapathManager.policyTiers()
+	installed := sets.New[uint8]()
+	for _, entry := range datapathManager.Rules {
+		installed.Insert(entry.Tier)
+	}
+	if err := tiers.checkReload(oldTiers, installed); err != nil {
+		return err
+	}
+
+	movedTiers := sets.New[uint8]()
+	for _, tier := range tiers {
+		for _, oldTier := range oldTiers {
+			if oldTier.ID == tier.ID && oldTier != tier {
+				movedTiers.Insert(tier.ID)
+			}
+		}
+	}
+	oldConfigTiers := datapathManager.Config.PolicyTiers
+	datapathManager.Config.PolicyTiers = append(PolicyTiers{}, tiers...)
+
+	var appliedVDS []string
+	for _, vdsID := range sets.StringKeySet(datapathManager.BridgeChainMap).List() {
+		if _, ok := datapathManager.BridgeChainMap[vdsID][POLICY_BRIDGE_KEYWORD].(policyTierLoader); !ok {
+			continue
+		}
+		err := datapathManager.applyPolicyTiers(vdsID, tiers, movedTiers)
+		if err == nil {
+			appliedVDS = append(appliedVDS, vdsID)
+			continue
+		}
+
+		// rollback all vds to the old tiers, so that rules of all vds are always installed by the same tiers
+		datapathManager.Config.PolicyTiers = oldConfigTiers
+		for _, rollbackVDS := range append(appliedVDS, vdsID) {
+			if rollbackErr := datapathManager.applyPolicyTiers(rollbackVDS, oldTiers, movedTiers); rollbackErr != nil {
+				log.Errorf("Failed to rollback policy tiers of vds %s: %s", rollbackVDS, rollbackErr)
+			}
+		}
+		return fmt.Errorf("failed to reload policy tiers of vds %s, rollback to the old tiers: %s", vdsID, err)
+	}
+	log.Infof("Reload policy tiers %+v, rules of tiers %v moved", tiers, sets.List(movedTiers))
+
+	return nil
+}
+
+// applyPolicyTiers moves rule flows of the moved tiers into tables of the tiers on the vds, and switches packets
+// to walk through the tables. Caller must hold flowReplayMutex and set the tiers into Config.
+func (datapathManager *DpManager) applyPolicyTiers(vdsID string, tiers PolicyTiers, movedTiers sets.Set[uint8]) error {
+	policyBridge := datapathManager.BridgeChainMap[vdsID][POLICY_BRIDGE_KEYWORD]
+	loader := policyBridge.(policyTierLoader)
+	if policyBridge.IsSwitchConnected() {
+		loader.preparePolicyTables(tiers)
+		if err := datapathManager.moveTierRuleFlows(vdsID, movedTiers); err != nil {
+			return fmt.Errorf("failed to move rule flows: %s", err)
+		}
+	} else {
+		datapathManager.dropTierRuleFlows(vdsID, movedTiers)
+	}
+	if err := loader.switchPolicyTables(tiers); err != nil {
+		return fmt.Errorf("failed to switch policy tables: %s", err)
+	}
+	return nil
+}
+
+// moveTierRuleFlows reinstalls rule flows of the tiers into their current tables on the vds, flows in the old
+// tables are deleted after the new flows installed. Caller must hold flowReplayMutex.
+func (datapathManager *DpManager) moveTierRuleFlows(vdsID string, tiers sets.Set[uint8]) error {
+	for ruleID, entry := range datapathManager.Rules {
+		oldFlowEntry := entry.RuleFlowMap[vdsID]
+		if oldFlowEntry == nil || !tiers.Has(entry.Tier) {
+			continue
+		}
+		if err := datapathManager.replayRuleFlow(vdsID, ruleID, entry); err != nil {
+			return err
+		}
+		if err := datapathManager.deleteFlowFunc(oldFlowEntry.Table, oldFlowEntry.Priority, oldFlowEntry.FlowID); err != nil {
+			return fmt.Errorf("failed to delete flow %#x of rule %s: %s", oldFlowEntry.FlowID, ruleID, err)
+		}
+		delete(datapathManager.FlowIDToRules, oldFlowEntry.FlowID)
+		datapathManager.forgetRuleTableFlow(vdsID, oldFlowEntry.FlowID)
+	}
+	return nil
+}
+
+// dropTierRuleFlows forgets rule flows of the tiers on the vds, so they would be reinstalled on replay.
+// Caller must hold flowReplayMutex.
+func (datapathManager *DpManager) dropTierRuleFlows(vdsID string, tiers sets.Set[uint8]) {
+	for _, entry := range datapathManager.Rules {
+		flowEntry := entry.RuleFlowMap[vdsID]
+		if flowEntry == nil || !tiers.Has(entry.Tier) {
+			continue
+		}
+		delete(datapathManager.FlowIDToRules, flowEntry.FlowID)
+		datapathManager.AgentMetric.RemoveRuleFlow(flowEntry.FlowID)
+		delete(entry.RuleFlowMap, vdsID)
+	}
+}
+
+// preparePolicyTables creates tables of the tiers, so that rule flows could be installed into them before
+// packets switched to walk through them. Caller must hold flowReplayMutex.
+func (p *PolicyBridge) preparePolicyTables(tiers PolicyTiers) {
+	for tableID := range tiers.ruleTables() {
+		if p.policyTables[tableID] != nil {
+			continue
+		}
+		if p.policyTables[tableID] = p.OfSwitch.GetTable(tableID); p.policyTables[tableID] == nil {
+			p.policyTables[tableID], _ = p.OfSwitch.NewTable(tableID)
+		}
+	}
+}
+
+// switchPolicyTables switches packets to walk through tables of the tiers, default flows of the tables no
+// longer used are deleted. Tables are only recorded if the switch hasn't connected, they would be
+// initialized on bridge init. Caller must hold flowReplayMutex.
+func (p *PolicyBridge) switchPolicyTables(tiers PolicyTiers) error {
+	ruleTables := tiers.ruleTables()
+	if p.IsSwitchConnected() {
+		if err := p.initPolicyTableChain(); err != nil {
+			return err
+		}
+		if err := p.initDirectionSelectionTable(); err != nil {
+			return err
+		}
+		for tableID := range p.getRuleTables() {
+			if ruleTables[tableID] {
+				continue
+			}
+			if defaultFlow := p.tierDefaultFlows[tableID]; defaultFlow != nil {
+				if err := defaultFlow.Delete(); err != nil {
+					return fmt.Errorf("failed to delete policy table %d default flow, error: %v", tableID, err)
+				}
+			}
+			delete(p.tierDefaultFlows, tableID)
+			delete(p.policyTables, tableID)
+		}
+	}
+	p.setRuleTables(ruleTables)
+	return nil
+}
+
+// getRuleTables returns the tables which policy rule flows installed in, the map must not be modified
+func (p *PolicyBridge) getRuleTables() map[uint8]bool {
+	p.ruleTablesMutex.RLock()
+	defer p.ruleTablesMutex.RUnlock()
+	return p.ruleTables
+}
+
+// setRuleTables replaces the tables which policy rule flows installed in, it could be read by packet in and
+// flow stats concurrently
+func (p *PolicyBridge) setRuleTables(ruleTables map[uint8]bool) {
+	p.ruleTablesMutex.Lock()
+	defer p.ruleTablesMutex.Unlock()
+	p.ruleTables = ruleTables
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datapath
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/contiv/ofnet/ofctrl"
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/everoute/pkg/constants"
+)
+
+const (
+	customTierName    = "tier-custom"
+	customTierID      = 140
+	customTierEgress  = 31
+	customTierIngress = 61
+)
+
+// customPolicyTiers inserts the custom tier between tier-ecp and tier2
+func customPolicyTiers() PolicyTiers {
+	tiers := DefaultPolicyTiers()
+	custom := PolicyTier{Name: customTierName, ID: customTierID, EgressTable: customTierEgress, IngressTable: customTierIngress}
+	return append(tiers[:3], append(PolicyTiers{custom}, tiers[3:]...)...)
+}
+
+func TestPolicyTiersValidate(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(DefaultPolicyTiers().Validate()).Should(Succeed())
+	Expect(customPolicyTiers().Validate()).Should(Succeed())
+
+	tier0, _ := BuiltinPolicyTier(constants.Tier0)
+	tier1, _ := BuiltinPolicyTier(constants.Tier1)
+	tierECP, _ := BuiltinPolicyTier(constants.TierECP)
+	tier2, _ := BuiltinPolicyTier(constants.Tier2)
+	custom := func(name string, id, egressTable, ingressTable uint8) PolicyTier {
+		return PolicyTier{Name: name, ID: id, EgressTable: egressTable, IngressTable: ingressTable}
+	}
+	invalidTiers := map[string]PolicyTiers{
+		"builtin tier missing":         {tier0, tier1, tierECP},
+		"builtin tier out of order":    {tier0, tierECP, tier1, tier2},
+		"builtin tier changed":         {tier0, tier1, tierECP, custom(constants.Tier2, POLICY_TIER3, 31, 61)},
+		"duplicate tier name":          {tier0, tier1, custom("c", 140, 31, 61), custom("c", 141, 32, 62), tierECP, tier2},
+		"duplicate tier id":            {tier0, tier1, custom("c", POLICY_TIER2, 31, 61), tierECP, tier2},
+		"zero tier id":                 {tier0, tier1, custom("c", 0, 31, 61), tierECP, tier2},
+		"empty tier name":              {tier0, tier1, custom("", 140, 31, 61), tierECP, tier2},
+		"egress table used by builtin": {tier0, tier1, custom("c", 140, EGRESS_TIER3_MONITOR_TABLE, 61), tierECP, tier2},
+		"egress table in ingress":      {tier0, tier1, custom("c", 140, 61, 62), tierECP, tier2},
+		"ingress table out of range":   {tier0, tier1, custom("c", 140, 31, CT_COMMIT_TABLE), tierECP, tier2},
+		"table used by other custom":   {tier0, custom("a", 140, 31, 61), custom("b", 141, 31, 62), tier1, tierECP, tier2},
+	}
+	for name, tiers := range invalidTiers {
+		Expect(tiers.Validate()).ShouldNot(Succeed(), name)
+	}
+}
+
+func TestCustomPolicyTierOrder(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := NewDatapathManager(&DpManagerConfig{ManagedVDSMap: map[string]string{}, PolicyTiers: customPolicyTiers()}, nil)
+
+	t.Run("custom tier tables should be walked through between tier-ecp and tier2", func(t *testing.T) {
+		Expect(dpMgr.policyTiers().tableChain(POLICY_DIRECTION_OUT)).Should(Equal([]uint8{
+			EGRESS_TIER1_TABLE, EGRESS_TIER2_MONITOR_TABLE, EGRESS_TIER2_TABLE, EGRESS_TIER_ECP_TABLE,
+			customTierEgress, EGRESS_TIER3_MONITOR_TABLE, EGRESS_TIER3_TABLE,
+		}))
+		Expect(dpMgr.policyTiers().tableChain(POLICY_DIRECTION_IN)).Should(Equal([]uint8{
+			INGRESS_TIER1_TABLE, INGRESS_TIER2_MONITOR_TABLE, INGRESS_TIER2_TABLE, INGRESS_TIER_ECP_TABLE,
+			customTierIngress, INGRESS_TIER3_MONITOR_TABLE, INGRESS_TIER3_TABLE,
+		}))
+		id, ok := dpMgr.PolicyTierID(customTierName)
+		Expect(ok).Should(BeTrue())
+		Expect(id).Should(Equal(uint8(customTierID)))
+		Expect(dpMgr.GetTableLayout().GetTierTables()).Should(ContainElement(HaveField("Table", uint32(customTierIngress))))
+	})
+
+	addRule := func(ruleID string, tier uint8, action string) {
+		rule := &EveroutePolicyRule{RuleID: ruleID, Priority: 100, Action: action}
+		dpMgr.Rules[ruleID] = &EveroutePolicyRuleEntry{EveroutePolicyRule: rule, Direction: POLICY_DIRECTION_IN,
+			Tier: tier, Mode: DEFAULT_POLICY_ENFORCEMENT_MODE, RuleFlowMap: map[string]*FlowEntry{}}
+	}
+	query := func() string {
+		return dpMgr.QueryReachable(net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.2"), PROTOCOL_TCP, 80).GetIngress().GetRuleID()
+	}
+
+	addRule("tier2-allow", POLICY_TIER3, EveroutePolicyAllow)
+	Expect(query()).Should(Equal("tier2-allow"))
+
+	t.Run("custom tier should override tier2", func(t *testing.T) {
+		addRule("custom-deny", customTierID, EveroutePolicyDeny)
+		Expect(query()).Should(Equal("custom-deny"))
+	})
+
+	t.Run("custom tier should be overridden by tier-ecp", func(t *testing.T) {
+		addRule("ecp-allow", POLICY_TIER_ECP, EveroutePolicyAllow)
+		Expect(query()).Should(Equal("ecp-allow"))
+	})
+}
+
+func TestReloadTierConfig(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpMgr := NewDatapathManager(&DpManagerConfig{ManagedVDSMap: map[string]string{}, PolicyTiers: customPolicyTiers()}, nil)
+	bridge := &fakePolicyBridge{PolicyBridge: NewPolicyBridge("ovsbr1", dpMgr)}
+	dpMgr.BridgeChainMap["vds1"] = map[string]Bridge{POLICY_BRIDGE_KEYWORD: bridge}
+	var deletedFlowIDs []uint64
+	dpMgr.deleteFlowFunc = func(_ *ofctrl.Table, _ uint16, flowID uint64) error {
+		deletedFlowIDs = append(deletedFlowIDs, flowID)
+		return nil
+	}
+
+	addRule := func(ruleID string, tier uint8, action string) uint64 {
+		rule := &EveroutePolicyRule{RuleID: ruleID, Priority: 100, Action: action}
+		Expect(dpMgr.AddEveroutePolicyRule(rule, "policy/"+ruleID, POLICY_DIRECTION_IN, tier, DEFAULT_POLICY_ENFORCEMENT_MODE)).Should(Succeed())
+		return dpMgr.Rules[ruleID].RuleFlowMap["vds1"].FlowID
+	}
+	query := func() string {
+		return dpMgr.QueryReachable(net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.2"), PROTOCOL_TCP, 80).GetIngress().GetRuleID()
+	}
+	customFlowID := addRule("custom-deny", customTierID, EveroutePolicyDeny)
+	tier2FlowID := addRule("tier2-allow", POLICY_TIER3, EveroutePolicyAllow)
+
+	t.Run("reload should move rules of the tier whose tables changed", func(t *testing.T) {
+		tiers := customPolicyTiers()
+		tiers[3].EgressTable, tiers[3].IngressTable = customTierEgress+1, customTierIngress+1
+		newTier := PolicyTier{Name: "tier-new", ID: customTierID + 1, EgressTable: customTierEgress + 2, IngressTable: customTierIngress + 2}
+		tiers = append(tiers[:1], append(PolicyTiers{newTier}, tiers[1:]...)...)
+		Expect(dpMgr.ReloadTierConfig(tiers)).Should(Succeed())
+
+		Expect(dpMgr.policyTiers()).Should(Equal(tiers))
+		Expect(bridge.getRuleTables()).Should(HaveKey(uint8(customTierIngress + 1)))
+		Expect(bridge.getRuleTables()).ShouldNot(HaveKey(uint8(customTierIngress)))
+		Expect(deletedFlowIDs).Should(Equal([]uint64{customFlowID}))
+
+		movedFlowID := dpMgr.Rules["custom-deny"].RuleFlowMap["vds1"].FlowID
+		Expect(movedFlowID).ShouldNot(Equal(customFlowID))
+		Expect(dpMgr.FlowIDToRules).Should(HaveKey(movedFlowID))
+		Expect(dpMgr.FlowIDToRules).ShouldNot(HaveKey(customFlowID))
+		Expect(dpMgr.Rules["tier2-allow"].RuleFlowMap["vds1"].FlowID).Should(Equal(tier2FlowID))
+		Expect(query()).Should(Equal("custom-deny"))
+	})
+
+	t.Run("rules of the new tier should override the tiers after it", func(t *testing.T) {
+		addRule("new-allow", customTierID+1, EveroutePolicyAllow)
+		Expect(query()).Should(Equal("new-allow"))
+	})
+
+	reloaded := dpMgr.policyTiers()
+	tier0, _ := BuiltinPolicyTier(constants.Tier0)
+	tier1, _ := BuiltinPolicyTier(constants.Tier1)
+	tierECP, _ := BuiltinPolicyTier(constants.TierECP)
+	tier2, _ := BuiltinPolicyTier(constants.Tier2)
+	newTier, customTier := reloaded[1], reloaded[4]
+	rejectedTiers := map[string]PolicyTiers{
+		"invalid tiers":                   {tier0, tier1, tierECP},
+		"tier with rules removed":         {tier0, newTier, tier1, tierECP, tier2},
+		"tier with rules renamed":         {tier0, newTier, tier1, tierECP, PolicyTier{Name: "tier-renamed", ID: customTierID, EgressTable: customTierEgress, IngressTable: customTierIngress}, tier2},
+		"tier with rules reordered":       {tier0, newTier, tier1, tierECP, tier2, customTier},
+		"tiers with rules order inverted": {tier0, tier1, tierECP, customTier, newTier, tier2},
+	}
+	for name, tiers := range rejectedTiers {
+		t.Run(name+" should be rejected", func(t *testing.T) {
+			Expect(dpMgr.ReloadTierConfig(tiers)).ShouldNot(Succeed())
+			Expect(dpMgr.policyTiers()).Should(Equal(reloaded))
+			Expect(deletedFlowIDs).Should(HaveLen(1))
+		})
+	}
+
+	t.Run("reload failed on any vds should rollback all vds to the old tiers", func(t *testing.T) {
+		failedBridge := &fakePolicyBridge{PolicyBridge: NewPolicyBridge("ovsbr2", dpMgr)}
+		dpMgr.BridgeChainMap["vds2"] = map[string]Bridge{POLICY_BRIDGE_KEYWORD: failedBridge}
+		defer delete(dpMgr.BridgeChainMap, "vds2")
+		addRule("custom-allow", customTierID, EveroutePolicyAllow)
+		failedBridge.addErr = fmt.Errorf("some error")
+
+		tiers := append(PolicyTiers{}, reloaded...)
+		tiers[4].EgressTable, tiers[4].IngressTable = customTierEgress+3, customTierIngress+3
+		Expect(dpMgr.ReloadTierConfig(tiers)).ShouldNot(Succeed())
+		Expect(dpMgr.policyTiers()).Should(Equal(reloaded))
+		Expect(bridge.getRuleTables()).Should(Equal(reloaded.ruleTables()))
+		Expect(failedBridge.getRuleTables()).Should(Equal(reloaded.ruleTables()))
+		Expect(query()).Should(Equal("new-allow"))
+	})
+}
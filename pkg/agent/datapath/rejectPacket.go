@@ -0,0 +1,305 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datapath
+
+import (
+	"encoding/binary"
+
+	"github.com/contiv/libOpenflow/openflow13"
+	"github.com/contiv/libOpenflow/protocol"
+	"github.com/contiv/libOpenflow/util"
+	"github.com/contiv/ofnet/ofctrl"
+)
+
+const (
+	tcpFlagFIN = 0x01
+	tcpFlagSYN = 0x02
+	tcpFlagRST = 0x04
+	tcpFlagACK = 0x10
+
+	icmpTypeEchoRequest       = 8
+	icmpTypeDestUnreachable   = 3
+	icmpCodeAdminProhibited   = 13
+	icmpUnreachableOrigLength = 8 // bytes of the original datagram payload carried in icmp unreachable
+
+	icmpv6TypeDestUnreachable = 1
+	icmpv6CodeAdminProhibited = 1
+	icmpv6TypeEchoRequest     = 128
+	// icmpv6 error carries as much of the original packet as possible without exceeding the ipv6 minimum
+	// mtu, see RFC 4443
+	icmpv6ErrorMaxLength = 1280
+
+	ipv6HeaderLen  = 40
+	rejectReplyTTL = 64
+)
+
+// rejectPacket returns the packet out which replies tcp rst or icmp unreachable to the source of the packet
+// denied by reject rule, the reply is sent back through the port the packet came in. Returns nil if the packet
+// should not be replied, e.g. not ip, with ip options or ipv6 extension headers, tcp rst, icmp error or
+// non-first fragment.
+func rejectPacket(pkt *ofctrl.PacketIn) *openflow13.PacketOut {
+	var reply util.Message
+	var ethertype uint16
+	switch ip := pkt.Data.Data.(type) {
+	case *protocol.IPv4:
+		if ipv4 := rejectIPv4Reply(ip); ipv4 != nil {
+			reply, ethertype = ipv4, protocol.IPv4_MSG
+		}
+	case *protocol.IPv6:
+		if ipv6 := rejectIPv6Reply(ip); ipv6 != nil {
+			reply, ethertype = ipv6, protocol.IPv6_MSG
+		}
+	}
+	if reply == nil {
+		return nil
+	}
+
+	eth := protocol.NewEthernet()
+	eth.HWSrc, eth.HWDst = pkt.Data.HWDst, pkt.Data.HWSrc
+	eth.VLANID = pkt.Data.VLANID
+	eth.Ethertype = ethertype
+	eth.Data = reply
+
+	packetOut := openflow13.NewPacketOut()
+	packetOut.InPort = packetInPort(pkt)
+	packetOut.AddAction(openflow13.NewActionOutput(openflow13.P_IN_PORT))
+	packetOut.Data = eth
+	return packetOut
+}
+
+// rejectIPv4Reply returns the ipv4 tcp rst or icmp unreachable replies to the packet, or nil if not replied
+func rejectIPv4Reply(ipv4 *protocol.IPv4) *protocol.IPv4 {
+	if ipv4.IHL > IPv4HeaderMinIHL || ipv4.FragmentOffset != 0 || !ipv4.NWSrc.IsGlobalUnicast() {
+		return nil
+	}
+	origin, err := ipv4.MarshalBinary()
+	if err != nil {
+		return nil
+	}
+	payload := origin[IPv4HeaderMinIHL*4:]
+
+	reply := protocol.NewIPv4()
+	reply.Version = 4
+	reply.TTL = rejectReplyTTL
+	reply.NWSrc, reply.NWDst = ipv4.NWDst, ipv4.NWSrc
+
+	switch ipv4.Protocol {
+	case protocol.Type_TCP:
+		rst := tcpResetOf(payload)
+		if rst == nil {
+			return nil
+		}
+		reply.Protocol = protocol.Type_TCP
+		reply.Data = rst
+	case protocol.Type_ICMP:
+		if icmp, ok := ipv4.Data.(*protocol.ICMP); !ok || icmp.Type != icmpTypeEchoRequest {
+			return nil
+		}
+		reply.Protocol = protocol.Type_ICMP
+		reply.Data = icmpUnreachableOf(origin)
+	default:
+		reply.Protocol = protocol.Type_ICMP
+		reply.Data = icmpUnreachableOf(origin)
+	}
+	if err := fillIPv4Checksums(reply); err != nil {
+		return nil
+	}
+	return reply
+}
+
+// rejectIPv6Reply returns the ipv6 tcp rst or icmpv6 unreachable replies to the packet, or nil if not replied
+func rejectIPv6Reply(ipv6 *protocol.IPv6) *protocol.IPv6 {
+	if ipv6.HbhHeader != nil || ipv6.RoutingHeader != nil || ipv6.FragmentHeader != nil || !ipv6.NWSrc.IsGlobalUnicast() {
+		return nil
+	}
+	origin, err := ipv6.MarshalBinary()
+	if err != nil {
+		return nil
+	}
+	payload := origin[ipv6HeaderLen:]
+
+	reply := new(protocol.IPv6)
+	reply.Version = 6
+	reply.HopLimit = rejectReplyTTL
+	reply.NWSrc, reply.NWDst = ipv6.NWDst, ipv6.NWSrc
+
+	switch ipv6.NextHeader {
+	case protocol.Type_TCP:
+		rst := tcpResetOf(payload)
+		if rst == nil {
+			return nil
+		}
+		reply.NextHeader = protocol.Type_TCP
+		reply.Data = rst
+	case protocol.Type_IPv6ICMP:
+		if icmp, ok := ipv6.Data.(*protocol.ICMP); !ok || icmp.Type != icmpv6TypeEchoRequest {
+			return nil
+		}
+		reply.NextHeader = protocol.Type_IPv6ICMP
+		reply.Data = icmpv6UnreachableOf(origin)
+	default:
+		reply.NextHeader = protocol.Type_IPv6ICMP
+		reply.Data = icmpv6UnreachableOf(origin)
+	}
+	if err := fillIPv6Checksums(reply); err != nil {
+		return nil
+	}
+	return reply
+}
+
+// tcpResetOf returns the tcp rst replies to the tcp segment, or nil if the segment is rst itself
+func tcpResetOf(segment []byte) *protocol.TCP {
+	tcp := protocol.NewTCP()
+	if err := tcp.UnmarshalBinary(segment); err != nil || tcp.Code&tcpFlagRST != 0 {
+		return nil
+	}
+
+	rst := protocol.NewTCP()
+	rst.PortSrc, rst.PortDst = tcp.PortDst, tcp.PortSrc
+	rst.HdrLen = 5
+	if tcp.Code&tcpFlagACK != 0 {
+		rst.SeqNum = tcp.AckNum
+		rst.Code = tcpFlagRST
+		return rst
+	}
+
+	// acknowledge all the sequence space the segment occupies, so that the source accepts the rst
+	ackNum := tcp.SeqNum
+	if dataLen := len(segment) - int(tcp.HdrLen)*4; dataLen > 0 {
+		ackNum += uint32(dataLen)
+	}
+	if tcp.Code&tcpFlagSYN != 0 {
+		ackNum++
+	}
+	if tcp.Code&tcpFlagFIN != 0 {
+		ackNum++
+	}
+	rst.AckNum = ackNum
+	rst.Code = tcpFlagRST | tcpFlagACK
+	return rst
+}
+
+// icmpUnreachableOf returns icmp communication administratively prohibited carries the original datagram
+func icmpUnreachableOf(origin []byte) *protocol.ICMP {
+	carried := len(origin)
+	if carried > IPv4HeaderMinIHL*4+icmpUnreachableOrigLength {
+		carried = IPv4HeaderMinIHL*4 + icmpUnreachableOrigLength
+	}
+
+	icmp := protocol.NewICMP()
+	icmp.Type = icmpTypeDestUnreachable
+	icmp.Code = icmpCodeAdminProhibited
+	// 4 bytes unused before the original datagram
+	icmp.Data = make([]byte, 4+carried)
+	copy(icmp.Data[4:], origin[:carried])
+	return icmp
+}
+
+// icmpv6UnreachableOf returns icmpv6 communication with destination administratively prohibited carries the
+// original packet
+func icmpv6UnreachableOf(origin []byte) *protocol.ICMP {
+	carried := len(origin)
+	// ipv6 header, icmpv6 header and 4 bytes unused
+	if maxCarried := icmpv6ErrorMaxLength - ipv6HeaderLen - 8; carried > maxCarried {
+		carried = maxCarried
+	}
+
+	icmp := protocol.NewICMP()
+	icmp.Type = icmpv6TypeDestUnreachable
+	icmp.Code = icmpv6CodeAdminProhibited
+	icmp.Data = make([]byte, 4+carried)
+	copy(icmp.Data[4:], origin[:carried])
+	return icmp
+}
+
+// fillIPv4Checksums fills length and checksum of the ipv4 packet, and checksum of its tcp or icmp payload
+func fillIPv4Checksums(ipv4 *protocol.IPv4) error {
+	ipv4.Length = ipv4.Len()
+
+	payload, err := ipv4.Data.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	switch data := ipv4.Data.(type) {
+	case *protocol.TCP:
+		pseudoHeader := make([]byte, 12)
+		copy(pseudoHeader[0:4], ipv4.NWSrc.To4())
+		copy(pseudoHeader[4:8], ipv4.NWDst.To4())
+		pseudoHeader[9] = protocol.Type_TCP
+		binary.BigEndian.PutUint16(pseudoHeader[10:], uint16(len(payload)))
+		data.Checksum = checksum(append(pseudoHeader, payload...))
+	case *protocol.ICMP:
+		data.Checksum = checksum(payload)
+	}
+
+	header, err := ipv4.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	ipv4.Checksum = checksum(header[:IPv4HeaderMinIHL*4])
+	return nil
+}
+
+// fillIPv6Checksums fills payload length of the ipv6 packet, and checksum of its tcp or icmpv6 payload
+func fillIPv6Checksums(ipv6 *protocol.IPv6) error {
+	ipv6.Length = ipv6.Data.Len()
+
+	payload, err := ipv6.Data.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	pseudoHeader := make([]byte, 40)
+	copy(pseudoHeader[0:16], ipv6.NWSrc.To16())
+	copy(pseudoHeader[16:32], ipv6.NWDst.To16())
+	binary.BigEndian.PutUint32(pseudoHeader[32:], uint32(len(payload)))
+	pseudoHeader[39] = ipv6.NextHeader
+	sum := checksum(append(pseudoHeader, payload...))
+
+	switch data := ipv6.Data.(type) {
+	case *protocol.TCP:
+		data.Checksum = sum
+	case *protocol.ICMP:
+		data.Checksum = sum
+	}
+	return nil
+}
+
+// checksum returns the internet checksum of the data, see RFC 1071
+func checksum(data []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(data); i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(data[i:]))
+	}
+	if len(data)%2 == 1 {
+		sum += uint32(data[len(data)-1]) << 8
+	}
+	for sum>>16 != 0 {
+		sum = sum&0xffff + sum>>16
+	}
+	return ^uint16(sum)
+}
+
+// packetInPort returns the in port of the packet in, or OFPP_ANY if not found
+func packetInPort(pkt *ofctrl.PacketIn) uint32 {
+	inPort := uint32(openflow13.P_ANY)
+	for _, field := range pkt.Match.Fields {
+		if inPortField, ok := field.Value.(*openflow13.InPortField); ok {
+			inPort = inPortField.InPort
+		}
+	}
+	return inPort
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datapath
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// TCPRSTDetectConfig configs detection of sources sending tcp rst, e.g. scanners or broken clients. Source
+// sent over Threshold rst of established connections in Window would be denied for DenyTimeout, sources in
+// AllowCIDRs, e.g. load balancers or gateways, are never denied.
+type TCPRSTDetectConfig struct {
+	Threshold   int
+	Window      time.Duration
+	DenyTimeout time.Duration
+	AllowCIDRs  []*net.IPNet
+}
+
+// rstDetector counts tcp rst of sources in fixed windows, and records sources denied temporarily
+type rstDetector struct {
+	config *TCPRSTDetectConfig
+
+	lock        sync.Mutex
+	windowStart map[string]time.Time // map source to start time of its current count window
+	counts      map[string]int       // map source to rst num in its current count window
+	denied      map[string]time.Time // map denied source to deny expire time
+}
+
+func newRSTDetector(config *TCPRSTDetectConfig) *rstDetector {
+	return &rstDetector{
+		config:      config,
+		windowStart: make(map[string]time.Time),
+		counts:      make(map[string]int),
+		denied:      make(map[string]time.Time),
+	}
+}
+
+// allowed returns true if the source is in the allowlist and should never be denied
+func (d *rstDetector) allowed(src net.IP) bool {
+	for _, cidr := range d.config.AllowCIDRs {
+		if cidr.Contains(src) {
+			return true
+		}
+	}
+	return false
+}
+
+// observe records a rst sent by the source, returns true if the source exceeds the threshold and should be denied
+func (d *rstDetector) observe(src string, now time.Time) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if _, ok := d.denied[src]; ok {
+		return false
+	}
+	if start, ok := d.windowStart[src]; !ok || now.Sub(start) >= d.config.Window {
+		d.windowStart[src] = now
+		d.counts[src] = 0
+	}
+	d.counts[src]++
+	if d.counts[src] <= d.config.Threshold {
+		return false
+	}
+
+	delete(d.windowStart, src)
+	delete(d.counts, src)
+	d.denied[src] = now.Add(d.config.DenyTimeout)
+	return true
+}
+
+// expire forgets sources whose deny expired and idle count windows
+func (d *rstDetector) expire(now time.Time) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	for src, start := range d.windowStart {
+		if now.Sub(start) >= d.config.Window {
+			delete(d.windowStart, src)
+			delete(d.counts, src)
+		}
+	}
+	for src, expireTime := range d.denied {
+		if !now.Before(expireTime) {
+			delete(d.denied, src)
+		}
+	}
+}
+
+// deniedSources returns sources denied currently
+func (d *rstDetector) deniedSources() []string {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	sources := make([]string, 0, len(d.denied))
+	for src := range d.denied {
+		sources = append(sources, src)
+	}
+	return sources
+}
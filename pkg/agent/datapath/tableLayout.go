@@ -0,0 +1,99 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datapath
+
+import (
+	"github.com/everoute/everoute/pkg/apis/rpc/v1alpha1"
+)
+
+// tierTables returns the policy bridge tables of each tier, direction and mode, it must keep
+// consistent with PolicyBridge.GetTierTable.
+func (t PolicyTiers) tierTables() []*v1alpha1.TierTable {
+	var tierTables []*v1alpha1.TierTable
+	for _, direction := range []uint8{POLICY_DIRECTION_OUT, POLICY_DIRECTION_IN} {
+		for _, tier := range t {
+			table, _ := t.table(direction, tier.ID)
+			tierTables = append(tierTables, &v1alpha1.TierTable{
+				Direction: uint32(direction), Tier: uint32(tier.ID), Mode: "work", Table: uint32(table), NextTable: CT_COMMIT_TABLE,
+			})
+		}
+		for _, tier := range t {
+			monitorTable, ok := policyTierMonitorTables[tier.ID][direction]
+			if !ok {
+				continue
+			}
+			table, _ := t.table(direction, tier.ID)
+			tierTables = append(tierTables, &v1alpha1.TierTable{
+				Direction: uint32(direction), Tier: uint32(tier.ID), Mode: "monitor", Table: uint32(monitorTable), NextTable: uint32(table),
+			})
+		}
+	}
+	return tierTables
+}
+
+// bridgeStages are the pipeline stage tables of bridges in all modes
+var bridgeStages = []*v1alpha1.BridgeStage{
+	{Bridge: LOCAL_BRIDGE_KEYWORD, Stage: "VlanInput", Table: VLAN_INPUT_TABLE},
+	{Bridge: LOCAL_BRIDGE_KEYWORD, Stage: "VlanFilter", Table: VLAN_FILTER_TABLE},
+	{Bridge: LOCAL_BRIDGE_KEYWORD, Stage: "L2Forwarding", Table: L2_FORWARDING_TABLE},
+	{Bridge: LOCAL_BRIDGE_KEYWORD, Stage: "L2Learning", Table: L2_LEARNING_TABLE},
+	{Bridge: LOCAL_BRIDGE_KEYWORD, Stage: "FromLocalRedirect", Table: FROM_LOCAL_REDIRECT_TABLE},
+	{Bridge: LOCAL_BRIDGE_KEYWORD, Stage: "FromLocalArpPass", Table: FROM_LOCAL_ARP_PASS_TABLE},
+	{Bridge: LOCAL_BRIDGE_KEYWORD, Stage: "FromLocalArpToController", Table: FROM_LOCAL_ARP_TO_CONTROLLER_TABLE},
+	{Bridge: POLICY_BRIDGE_KEYWORD, Stage: "Input", Table: INPUT_TABLE},
+	{Bridge: POLICY_BRIDGE_KEYWORD, Stage: "CTState", Table: CT_STATE_TABLE},
+	{Bridge: POLICY_BRIDGE_KEYWORD, Stage: "CTEstablished", Table: CT_ESTABLISHED_TABLE},
+	{Bridge: POLICY_BRIDGE_KEYWORD, Stage: "DirectionSelection", Table: DIRECTION_SELECTION_TABLE},
+	{Bridge: POLICY_BRIDGE_KEYWORD, Stage: "CTCommit", Table: CT_COMMIT_TABLE},
+	{Bridge: POLICY_BRIDGE_KEYWORD, Stage: "CTDrop", Table: CT_DROP_TABLE},
+	{Bridge: POLICY_BRIDGE_KEYWORD, Stage: "SFCPolicy", Table: SFC_POLICY_TABLE},
+	{Bridge: POLICY_BRIDGE_KEYWORD, Stage: "PolicyForwarding", Table: POLICY_FORWARDING_TABLE},
+	{Bridge: CLS_BRIDGE_KEYWORD, Stage: "Learning", Table: CLSBRIDGE_LEARNING_TABLE_ID},
+	{Bridge: CLS_BRIDGE_KEYWORD, Stage: "Forwarding", Table: CLSBRIDGE_FORWARDING_TABLE_ID},
+	{Bridge: CLS_BRIDGE_KEYWORD, Stage: "Output", Table: CLSBRIDGE_OUTPUT_TABLE_ID},
+}
+
+// GetTableLayout returns the tables of each policy tier and the pipeline stage tables of bridges,
+// nat bridge stages are included only when proxy enabled.
+func (datapathManager *DpManager) GetTableLayout() *v1alpha1.TableLayout {
+	datapathManager.lockRflowReplayWithTimeout()
+	defer datapathManager.flowReplayMutex.RUnlock()
+
+	layout := &v1alpha1.TableLayout{
+		TierTables:   datapathManager.policyTiers().tierTables(),
+		BridgeStages: bridgeStages,
+	}
+	if datapathManager.IsEnableProxy() {
+		layout.BridgeStages = append(append([]*v1alpha1.BridgeStage{}, bridgeStages...), natBridgeStages()...)
+	}
+	return layout
+}
+
+func natBridgeStages() []*v1alpha1.BridgeStage {
+	return []*v1alpha1.BridgeStage{
+		{Bridge: NAT_BRIDGE_KEYWORD, Stage: "Input", Table: uint32(NatBrInputTable)},
+		{Bridge: NAT_BRIDGE_KEYWORD, Stage: "InPort", Table: uint32(NatBrInPortTable)},
+		{Bridge: NAT_BRIDGE_KEYWORD, Stage: "CTZone", Table: uint32(NatBrCTZoneTable)},
+		{Bridge: NAT_BRIDGE_KEYWORD, Stage: "CTState", Table: uint32(NatBrCTStateTable)},
+		{Bridge: NAT_BRIDGE_KEYWORD, Stage: "SessionAffinity", Table: uint32(NatBrSessionAffinityTable)},
+		{Bridge: NAT_BRIDGE_KEYWORD, Stage: "ServiceLB", Table: uint32(NatBrServiceLBTable)},
+		{Bridge: NAT_BRIDGE_KEYWORD, Stage: "SessionAffinityLearn", Table: uint32(NatBrSessionAffinityLearnTable)},
+		{Bridge: NAT_BRIDGE_KEYWORD, Stage: "Dnat", Table: uint32(NatBrDnatTable)},
+		{Bridge: NAT_BRIDGE_KEYWORD, Stage: "L3Forward", Table: uint32(NatBrL3ForwardTable)},
+		{Bridge: NAT_BRIDGE_KEYWORD, Stage: "Output", Table: uint32(NatBrOutputTable)},
+	}
+}
@@ -8,7 +8,6 @@ import (
 
 	cnitypes "github.com/containernetworking/cni/pkg/types"
 	"github.com/contiv/ofnet/ofctrl"
-	"github.com/contiv/ofnet/ofctrl/cookie"
 	"github.com/contiv/ofnet/ovsdbDriver"
 	"k8s.io/klog"
 
@@ -112,11 +111,11 @@ func setupUplinkBridge() error {
 		datapathManager.WaitForBridgeConnected()
 	}
 
-	roundInfo, err := getRoundInfo(driver)
+	roundInfo, err := getRoundInfo(driver, defaultFlowCookie)
 	if err != nil {
 		return err
 	}
-	cookieAllocator := cookie.NewAllocator(roundInfo.curRoundNum)
+	cookieAllocator := defaultFlowCookie.newCookieAllocator(roundInfo.curRoundNum)
 	br.getOfSwitch().CookieAllocator = cookieAllocator
 	return nil
 }
@@ -126,6 +125,7 @@ func delUplinkBridge() error {
 }
 
 func TestBridgeInitCNI(t *testing.T) {
+	skipWithoutOVS(t)
 	t.Cleanup(func() {
 		delUplinkBridge()
 	})
@@ -21,15 +21,19 @@ import (
 	"fmt"
 	"net"
 	"os/exec"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	cnitypes "github.com/containernetworking/cni/pkg/types"
 	openflow "github.com/contiv/libOpenflow/openflow13"
 	"github.com/contiv/ofnet/ofctrl"
 	log "github.com/sirupsen/logrus"
 	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/everoute/everoute/pkg/apis/rpc/v1alpha1"
 	"github.com/everoute/everoute/pkg/constants"
@@ -144,6 +148,172 @@ const (
 	`
 )
 
+// ovsSystemDatapath is the kernel datapath name in ovsdb
+const ovsSystemDatapath = "system"
+
+// ctTimeoutPolicyAttrs map protocol to the ovs ct timeout policy attributes of its idle connections
+var ctTimeoutPolicyAttrs = map[string][]string{
+	"tcp":  {"tcp_established"},
+	"udp":  {"udp_first", "udp_single", "udp_multiple"},
+	"icmp": {"icmp_first", "icmp_reply"},
+}
+
+// ctTimeoutPolicyArgs convert protocol timeouts into sorted ovs ct timeout policy attributes
+func ctTimeoutPolicyArgs(policy map[string]uint32) ([]string, error) {
+	var args []string
+	for protocol, timeout := range policy {
+		attrs, ok := ctTimeoutPolicyAttrs[strings.ToLower(protocol)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported ct timeout policy protocol %s", protocol)
+		}
+		if timeout == 0 {
+			return nil, fmt.Errorf("invalid ct timeout 0 for protocol %s", protocol)
+		}
+		for _, attr := range attrs {
+			args = append(args, fmt.Sprintf("%s=%d", attr, timeout))
+		}
+	}
+	sort.Strings(args)
+	return args, nil
+}
+
+// SetCTTimeoutPolicy set the ct timeout policy of the conntrack zone, connections
+// committed into the zone would expire after the protocol timeout when idle.
+func SetCTTimeoutPolicy(zone uint16, policy map[string]uint32) error {
+	args, err := ctTimeoutPolicyArgs(policy)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return nil
+	}
+
+	cmdStr := fmt.Sprintf("ovs-vsctl --may-exist add-dp %s -- --if-exists del-zone-tp %s zone=%d -- add-zone-tp %s zone=%d %s",
+		ovsSystemDatapath, ovsSystemDatapath, zone, ovsSystemDatapath, zone, strings.Join(args, " "))
+	out, err := exec.Command("/bin/sh", "-c", cmdStr).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to set ct timeout policy for zone %d: %v, output: %s", zone, err, string(out))
+	}
+	return nil
+}
+
+// InstalledFlow is a flow read back from ovs
+type InstalledFlow struct {
+	TableID  uint8
+	Priority uint16
+	Cookie   uint64
+	Match    map[string]string // map match field to value, value of protocol keywords like ip and tcp is empty
+}
+
+// ofctlFlowStatsFields are fields printed by ovs-ofctl dump-flows which aren't match fields
+var ofctlFlowStatsFields = sets.NewString("cookie", "duration", "table", "n_packets", "n_bytes",
+	"idle_age", "hard_age", "idle_timeout", "hard_timeout", "priority", "reset_counts")
+
+// parseOfctlFlow parses a flow printed by ovs-ofctl dump-flows, actions of the flow are ignored
+func parseOfctlFlow(flowStr string) (*InstalledFlow, error) {
+	flowStr = strings.TrimSpace(flowStr)
+	if index := strings.Index(flowStr, " actions="); index != -1 {
+		flowStr = flowStr[:index]
+	}
+
+	flow := &InstalledFlow{Match: make(map[string]string)}
+	for _, item := range strings.FieldsFunc(flowStr, func(r rune) bool { return r == ',' || r == ' ' }) {
+		key, value, _ := strings.Cut(item, "=")
+		var err error
+		switch key {
+		case "cookie":
+			flow.Cookie, err = strconv.ParseUint(value, 0, 64)
+		case "table":
+			var tableID uint64
+			tableID, err = strconv.ParseUint(value, 10, 8)
+			flow.TableID = uint8(tableID)
+		case "priority":
+			var priority uint64
+			priority, err = strconv.ParseUint(value, 10, 16)
+			flow.Priority = uint16(priority)
+		default:
+			if !ofctlFlowStatsFields.Has(key) {
+				flow.Match[key] = value
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s in flow %s: %s", key, flowStr, err)
+		}
+	}
+	return flow, nil
+}
+
+// parseOfctlAggregateFlowCount parses flow_count from the reply printed by ovs-ofctl dump-aggregate
+func parseOfctlAggregateFlowCount(out string) (int, error) {
+	for _, item := range strings.Fields(out) {
+		if strings.HasPrefix(item, "flow_count=") {
+			return strconv.Atoi(strings.TrimPrefix(item, "flow_count="))
+		}
+	}
+	return 0, fmt.Errorf("flow_count not found in aggregate reply: %s", out)
+}
+
+// countRoundFlows returns the number of flows on the bridge with cookie allocated in the round
+func countRoundFlows(bridgeName string, flowCookie *FlowCookieConfig, roundNum uint64) (int, error) {
+	roundCookie, roundCookieMask := flowCookie.roundCookieWithMask(roundNum)
+	cmdStr := fmt.Sprintf("ovs-ofctl -O Openflow13 dump-aggregate %s 'cookie=%#x/%#x'", bridgeName, roundCookie, roundCookieMask)
+	out, err := exec.Command("/bin/sh", "-c", cmdStr).CombinedOutput()
+	if err != nil {
+		return 0, fmt.Errorf("failed to dump aggregate of bridge %s: %v, output: %s", bridgeName, err, string(out))
+	}
+	return parseOfctlAggregateFlowCount(string(out))
+}
+
+// flushAfterFlowConverged calls flush once after synced closed and the flow count returned by countFlows
+// keeps unchanged in stableTimes successive samples, flush is never called in minDelay. A nil synced isn't
+// waited. If it doesn't converge in timeout, flush would still be called. The flush would be skipped if
+// stopChan closed before.
+func flushAfterFlowConverged(countFlows func() (int, error), flush func(), synced <-chan struct{},
+	interval time.Duration, stableTimes int, minDelay, timeout time.Duration, stopChan <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	notBefore := time.Now().Add(minDelay)
+
+	if synced != nil {
+		select {
+		case <-stopChan:
+			return
+		case <-deadline.C:
+			log.Warnf("Policy rules aren't synced in %s, flush anyway", timeout)
+			flush()
+			return
+		case <-synced:
+		}
+	}
+
+	lastCount, stable := -1, 0
+	for stable < stableTimes || time.Now().Before(notBefore) {
+		select {
+		case <-stopChan:
+			return
+		case <-deadline.C:
+			log.Warnf("Flow count doesn't converge in %s, flush anyway", timeout)
+			flush()
+			return
+		case <-ticker.C:
+		}
+
+		count, err := countFlows()
+		switch {
+		case err != nil:
+			log.Errorf("Failed to count flows: %s", err)
+			lastCount, stable = -1, 0
+		case count == 0 || count != lastCount:
+			lastCount, stable = count, 0
+		default:
+			stable++
+		}
+	}
+	flush()
+}
+
 func ExcuteCommand(cmdStr, arg string) error {
 	commandStr := fmt.Sprintf(cmdStr, arg)
 	out, err := exec.Command("/bin/sh", "-c", commandStr).CombinedOutput()
@@ -224,25 +394,18 @@ func ParseMacToUint64(b []byte) uint64 {
 		uint64(b[1])<<32 | uint64(b[0])<<40 | 0<<48 | 0<<56
 }
 
-func datapathRule2RpcRule(entry *EveroutePolicyRuleEntry) *v1alpha1.RuleEntry {
+// datapathRule2RpcRule converts the rule entry with flows of all vds, or only flows of the vds when
+// vdsID is not empty. It returns nil if the rule has no flow on the specified vds.
+func datapathRule2RpcRule(entry *EveroutePolicyRuleEntry, vdsID string) *v1alpha1.RuleEntry {
 	rpcRFM := map[string]*v1alpha1.FlowEntry{}
 	for k, v := range entry.RuleFlowMap {
-		rpcRFM[k] = &v1alpha1.FlowEntry{
-			Priority: uint32(v.Priority),
-			FlowID:   v.FlowID,
-		}
-	}
-	rpcReference := []*v1alpha1.PolicyRuleReference{}
-	for reference := range entry.PolicyRuleReference {
-		references := strings.Split(reference, "/")
-		if len(references) < 3 {
+		if vdsID != "" && k != vdsID {
 			continue
 		}
-		rpcReference = append(rpcReference, &v1alpha1.PolicyRuleReference{
-			NameSpace: references[0],
-			Name:      references[1],
-			Type:      references[2],
-		})
+		rpcRFM[k] = flowEntry2RpcFlowEntry(v)
+	}
+	if vdsID != "" && len(rpcRFM) == 0 {
+		return nil
 	}
 	return &v1alpha1.RuleEntry{
 		EveroutePolicyRule: &v1alpha1.PolicyRule{
@@ -261,11 +424,41 @@ func datapathRule2RpcRule(entry *EveroutePolicyRuleEntry) *v1alpha1.RuleEntry {
 		Tier:                uint32(entry.Tier),
 		Mode:                entry.Mode,
 		RuleFlowMap:         rpcRFM,
-		PolicyRuleReference: rpcReference,
+		PolicyRuleReference: policyRuleReference2RpcReference(entry.PolicyRuleReference),
+	}
+}
+
+func flowEntry2RpcFlowEntry(entry *FlowEntry) *v1alpha1.FlowEntry {
+	rpcFlowEntry := &v1alpha1.FlowEntry{
+		Priority: uint32(entry.Priority),
+		FlowID:   entry.FlowID,
+	}
+	if entry.Table != nil {
+		rpcFlowEntry.TableID = uint32(entry.Table.TableId)
+	}
+	return rpcFlowEntry
+}
+
+func policyRuleReference2RpcReference(policyRuleReference sets.String) []*v1alpha1.PolicyRuleReference {
+	rpcReference := []*v1alpha1.PolicyRuleReference{}
+	for reference := range policyRuleReference {
+		references := strings.Split(reference, "/")
+		if len(references) < 3 {
+			continue
+		}
+		rpcReference = append(rpcReference, &v1alpha1.PolicyRuleReference{
+			NameSpace: references[0],
+			Name:      references[1],
+			Type:      references[2],
+		})
 	}
+	return rpcReference
 }
 
 func (rule EveroutePolicyRule) MatchConntrackFlow(flow *netlink.ConntrackFlow) bool {
+	if len(rule.CTZones) != 0 && !matchCTZone(rule.CTZones, flow.Zone) {
+		return false
+	}
 	return rule.matchIPTuple(
 		flow.Forward.Protocol,
 		flow.Forward.SrcIP,
@@ -301,6 +494,111 @@ func (rule EveroutePolicyRule) matchIPTuple(protocol uint8, srcIP, dstIP net.IP,
 	return true
 }
 
+// coveredBy returns true if every packet matches the rule also matches the other rule
+func (rule EveroutePolicyRule) coveredBy(other EveroutePolicyRule) bool {
+	return newRuleMatch(&rule).coveredBy(newRuleMatch(&other))
+}
+
+// ruleMatch is the rule with src and dst ip pre-parsed, so that checking coverage against many rules never
+// parses the ips again
+type ruleMatch struct {
+	*EveroutePolicyRule
+	srcIPNet *net.IPNet // nil if the rule matches all src ips or the src ip is invalid
+	dstIPNet *net.IPNet // nil if the rule matches all dst ips or the dst ip is invalid
+}
+
+func newRuleMatch(rule *EveroutePolicyRule) *ruleMatch {
+	return &ruleMatch{
+		EveroutePolicyRule: rule,
+		srcIPNet:           parseIPNet(rule.SrcIPAddr),
+		dstIPNet:           parseIPNet(rule.DstIPAddr),
+	}
+}
+
+// coveredBy returns true if every packet matches the rule also matches the other rule
+func (rule *ruleMatch) coveredBy(other *ruleMatch) bool {
+	if other.IPProtocol != 0 && other.IPProtocol != rule.IPProtocol {
+		return false
+	}
+	if other.IPOptions && !rule.IPOptions {
+		return false
+	}
+	if other.TrafficLocality != "" && other.TrafficLocality != rule.TrafficLocality {
+		return false
+	}
+	if other.VlanID != 0 && other.VlanID != rule.VlanID {
+		return false
+	}
+	if other.ICMPType != nil && (rule.ICMPType == nil || *rule.ICMPType != *other.ICMPType) {
+		return false
+	}
+	if other.ICMPCode != nil && (rule.ICMPCode == nil || *rule.ICMPCode != *other.ICMPCode) {
+		return false
+	}
+	if other.SrcIPAddr != "" && !ipNetCoveredBy(rule.srcIPNet, other.srcIPNet) {
+		return false
+	}
+	if other.DstIPAddr != "" && !ipNetCoveredBy(rule.dstIPNet, other.dstIPNet) {
+		return false
+	}
+	if other.SrcPort != 0 && !portCoveredBy(rule.SrcPortMask, rule.SrcPort, other.SrcPortMask, other.SrcPort) {
+		return false
+	}
+	if other.DstPort != 0 && !portCoveredBy(rule.DstPortMask, rule.DstPort, other.DstPortMask, other.DstPort) {
+		return false
+	}
+	return true
+}
+
+// ipNetCoveredBy returns true if the cidr is in the other cidr, nil cidr is never covered
+func ipNetCoveredBy(ipNet, otherNet *net.IPNet) bool {
+	if ipNet == nil || otherNet == nil {
+		return false
+	}
+	ones, bits := ipNet.Mask.Size()
+	otherOnes, otherBits := otherNet.Mask.Size()
+	return bits == otherBits && otherOnes <= ones && otherNet.Contains(ipNet.IP)
+}
+
+// parseIPNet parses the cidr, or the ip as a host cidr
+func parseIPNet(ipRaw string) *net.IPNet {
+	if _, ipNet, err := net.ParseCIDR(ipRaw); err == nil {
+		return ipNet
+	}
+	ip := net.ParseIP(ipRaw)
+	if ip == nil {
+		return nil
+	}
+	if ip.To4() != nil {
+		return &net.IPNet{IP: ip.To4(), Mask: net.CIDRMask(32, 32)}
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+}
+
+// portCoveredBy returns true if ports matched by the port and mask are all matched by the other, port 0 matches all
+func portCoveredBy(mask, port, otherMask, otherPort uint16) bool {
+	if port == 0 {
+		return false
+	}
+	// mask 0 matches the exact port
+	if mask == 0 {
+		mask = 0xffff
+	}
+	if otherMask == 0 {
+		otherMask = 0xffff
+	}
+	return otherMask&^mask == 0 && port&otherMask == otherPort&otherMask
+}
+
+func matchCTZone(zones []uint16, zone uint16) bool {
+	for _, z := range zones {
+		if z == zone {
+			return true
+		}
+	}
+	return false
+}
+
 func matchPort(mask, port1, port2 uint16) bool {
 	if mask == 0 {
 		return port1 == port2
@@ -317,6 +615,23 @@ func matchIP(ipRaw string, ip net.IP) bool {
 
 type EveroutePolicyRuleList []EveroutePolicyRule
 
+// FilterByFamily returns rules may match conntrack entries of the ip family, rules without ip address
+// match both ipv4 and ipv6 entries.
+func (list EveroutePolicyRuleList) FilterByFamily(family netlink.InetFamily) EveroutePolicyRuleList {
+	var ans EveroutePolicyRuleList
+	for _, rule := range list {
+		ipAddr := rule.SrcIPAddr
+		if ipAddr == "" {
+			ipAddr = rule.DstIPAddr
+		}
+		if ipAddr != "" && isIPv6Addr(ipAddr) != (family == unix.AF_INET6) {
+			continue
+		}
+		ans = append(ans, rule)
+	}
+	return ans
+}
+
 func (list EveroutePolicyRuleList) MatchConntrackFlow(flow *netlink.ConntrackFlow) bool {
 	for _, rule := range list {
 		if rule.MatchConntrackFlow(flow) {
@@ -434,6 +749,33 @@ func posToMask(pos int) uint16 {
 	return ret
 }
 
+// PortMask represent a set of ports could be matched in one flow, e.g. 8080/0xfff8 matches 8080-8087
+type PortMask struct {
+	Port uint16
+	Mask uint16
+}
+
+// PortRangeToMasks decomposes port range [begin, end] into the minimal set of port/mask pairs,
+// each pair is the biggest aligned block start from the current port that inside the range.
+func PortRangeToMasks(begin, end uint16) []PortMask {
+	var portMasks []PortMask
+
+	// use uint32 to avoid overflow when end is 65535
+	for cur := uint32(begin); cur <= uint32(end); {
+		size := uint32(1)
+		for size < 1<<16 && cur%(size<<1) == 0 && cur+(size<<1)-1 <= uint32(end) {
+			size <<= 1
+		}
+		portMasks = append(portMasks, PortMask{
+			Port: uint16(cur),
+			Mask: uint16(1<<16 - size),
+		})
+		cur += size
+	}
+
+	return portMasks
+}
+
 func toTrunkVlanIDs(trunks string) []uint16 {
 	var idList []uint16
 	for _, id := range strings.Split(trunks, ",") {
@@ -20,7 +20,9 @@ import (
 	"fmt"
 	"net"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestMatchIP(t *testing.T) {
@@ -105,6 +107,360 @@ func TestMatchPort(t *testing.T) {
 	}
 }
 
+func TestRuleCoveredBy(t *testing.T) {
+	testCases := map[string]struct {
+		rule      EveroutePolicyRule
+		other     EveroutePolicyRule
+		isCovered bool
+	}{
+		"any rule should be covered by rule matches all": {
+			rule:      EveroutePolicyRule{SrcIPAddr: "10.0.0.1", IPProtocol: PROTOCOL_TCP, DstPort: 80},
+			other:     EveroutePolicyRule{},
+			isCovered: true,
+		},
+		"host should be covered by the cidr contains it": {
+			rule:      EveroutePolicyRule{SrcIPAddr: "10.0.0.1"},
+			other:     EveroutePolicyRule{SrcIPAddr: "10.0.0.0/24"},
+			isCovered: true,
+		},
+		"cidr should not be covered by smaller cidr": {
+			rule:      EveroutePolicyRule{DstIPAddr: "10.0.0.0/16"},
+			other:     EveroutePolicyRule{DstIPAddr: "10.0.0.0/24"},
+			isCovered: false,
+		},
+		"rule matches all ip should not be covered by rule with ip": {
+			rule:      EveroutePolicyRule{},
+			other:     EveroutePolicyRule{DstIPAddr: "10.0.0.0/8"},
+			isCovered: false,
+		},
+		"different protocol should not be covered": {
+			rule:      EveroutePolicyRule{IPProtocol: PROTOCOL_UDP},
+			other:     EveroutePolicyRule{IPProtocol: PROTOCOL_TCP},
+			isCovered: false,
+		},
+		"port should be covered by masked port range": {
+			rule:      EveroutePolicyRule{IPProtocol: PROTOCOL_TCP, DstPort: 22, DstPortMask: 0xffff},
+			other:     EveroutePolicyRule{IPProtocol: PROTOCOL_TCP, DstPort: 16, DstPortMask: 0xfff0},
+			isCovered: true,
+		},
+		"masked port range should not be covered by a port": {
+			rule:      EveroutePolicyRule{IPProtocol: PROTOCOL_TCP, DstPort: 16, DstPortMask: 0xfff0},
+			other:     EveroutePolicyRule{IPProtocol: PROTOCOL_TCP, DstPort: 22},
+			isCovered: false,
+		},
+		"rule on the vlan should be covered by rule matches all vlans": {
+			rule:      EveroutePolicyRule{SrcIPAddr: "10.0.0.1", VlanID: 100},
+			other:     EveroutePolicyRule{SrcIPAddr: "10.0.0.0/24"},
+			isCovered: true,
+		},
+		"rule matches all vlans should not be covered by rule on the vlan": {
+			rule:      EveroutePolicyRule{SrcIPAddr: "10.0.0.1"},
+			other:     EveroutePolicyRule{SrcIPAddr: "10.0.0.0/24", VlanID: 100},
+			isCovered: false,
+		},
+		"rule on different vlan should not be covered": {
+			rule:      EveroutePolicyRule{VlanID: 200},
+			other:     EveroutePolicyRule{VlanID: 100},
+			isCovered: false,
+		},
+		"rule matches all icmp should not be covered by rule with icmp type": {
+			rule:      EveroutePolicyRule{IPProtocol: PROTOCOL_ICMP},
+			other:     EveroutePolicyRule{IPProtocol: PROTOCOL_ICMP, ICMPType: new(uint8)},
+			isCovered: false,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if isCovered := tc.rule.coveredBy(tc.other); isCovered != tc.isCovered {
+				t.Fatalf("expect coveredBy = %t, got %t", tc.isCovered, isCovered)
+			}
+		})
+	}
+}
+
+func TestPortRangeToMasks(t *testing.T) {
+	testCases := []struct {
+		begin     uint16
+		end       uint16
+		portMasks []PortMask
+	}{
+		{
+			begin:     80,
+			end:       80,
+			portMasks: []PortMask{{Port: 80, Mask: 0xffff}},
+		},
+		{
+			begin:     20,
+			end:       25,
+			portMasks: []PortMask{{Port: 20, Mask: 0xfffc}, {Port: 24, Mask: 0xfffe}},
+		},
+		{
+			begin: 8080,
+			end:   8090,
+			portMasks: []PortMask{
+				{Port: 8080, Mask: 0xfff8},
+				{Port: 8088, Mask: 0xfffe},
+				{Port: 8090, Mask: 0xffff},
+			},
+		},
+		{
+			begin:     1024,
+			end:       65535,
+			portMasks: []PortMask{{Port: 1024, Mask: 0xfc00}, {Port: 2048, Mask: 0xf800}, {Port: 4096, Mask: 0xf000}, {Port: 8192, Mask: 0xe000}, {Port: 16384, Mask: 0xc000}, {Port: 32768, Mask: 0x8000}},
+		},
+		{
+			begin:     0,
+			end:       65535,
+			portMasks: []PortMask{{Port: 0, Mask: 0}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%d", tc.begin, tc.end), func(t *testing.T) {
+			portMasks := PortRangeToMasks(tc.begin, tc.end)
+			if !reflect.DeepEqual(portMasks, tc.portMasks) {
+				t.Fatalf("expect port masks %v, got %v", tc.portMasks, portMasks)
+			}
+
+			// port reachability should be the same as the origin port range
+			for port := 0; port <= 65535; port++ {
+				var matched bool
+				for _, portMask := range portMasks {
+					if uint16(port)&portMask.Mask == portMask.Port {
+						matched = true
+						break
+					}
+				}
+				if expect := port >= int(tc.begin) && port <= int(tc.end); matched != expect {
+					t.Fatalf("port %d expect matched = %t, got matched = %t", port, expect, matched)
+				}
+			}
+		})
+	}
+}
+
+func TestCTTimeoutPolicyArgs(t *testing.T) {
+	testCases := []struct {
+		name     string
+		policy   map[string]uint32
+		args     []string
+		expectOK bool
+	}{
+		{
+			name:     "empty policy",
+			policy:   nil,
+			args:     nil,
+			expectOK: true,
+		},
+		{
+			name:     "udp and tcp policy",
+			policy:   map[string]uint32{"UDP": 10, "tcp": 600},
+			args:     []string{"tcp_established=600", "udp_first=10", "udp_multiple=10", "udp_single=10"},
+			expectOK: true,
+		},
+		{
+			name:     "unsupported protocol",
+			policy:   map[string]uint32{"sctp": 10},
+			expectOK: false,
+		},
+		{
+			name:     "zero timeout",
+			policy:   map[string]uint32{"icmp": 0},
+			expectOK: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			args, err := ctTimeoutPolicyArgs(tc.policy)
+			if (err == nil) != tc.expectOK {
+				t.Fatalf("expect ok = %t, got error %v", tc.expectOK, err)
+			}
+			if !reflect.DeepEqual(args, tc.args) {
+				t.Fatalf("expect args %v, got %v", tc.args, args)
+			}
+		})
+	}
+}
+
+func TestParseOfctlFlow(t *testing.T) {
+	flowStr := " cookie=0x10000001, duration=3.618s, table=55, n_packets=0, n_bytes=0, idle_age=3, priority=200," +
+		"tcp,nw_src=10.100.100.0/24,tp_dst=0x1000/0xf000 actions=load:0x1->NXM_NX_XXREG0[0..3],goto_table:70"
+	expectFlow := &InstalledFlow{
+		TableID:  55,
+		Priority: 200,
+		Cookie:   0x10000001,
+		Match:    map[string]string{"tcp": "", "nw_src": "10.100.100.0/24", "tp_dst": "0x1000/0xf000"},
+	}
+
+	flow, err := parseOfctlFlow(flowStr)
+	if err != nil {
+		t.Fatalf("failed to parse flow %s: %s", flowStr, err)
+	}
+	if !reflect.DeepEqual(flow, expectFlow) {
+		t.Fatalf("expect flow %+v, got %+v", expectFlow, flow)
+	}
+
+	rule := &EveroutePolicyRule{SrcIPAddr: "10.100.100.1/24", IPProtocol: PROTOCOL_TCP, DstPort: 0x1000, DstPortMask: 0xf000}
+	match, err := ruleFlowMatch(rule)
+	if err != nil {
+		t.Fatalf("failed to get match of rule %+v: %s", rule, err)
+	}
+	if !reflect.DeepEqual(match, expectFlow.Match) {
+		t.Fatalf("expect rule match %v, got %v", expectFlow.Match, match)
+	}
+
+	vlanFlowStr := "table=55, priority=200,tcp,dl_vlan=100,nw_src=10.100.100.1,tp_dst=80 actions=goto_table:70"
+	flow, err = parseOfctlFlow(vlanFlowStr)
+	if err != nil {
+		t.Fatalf("failed to parse flow %s: %s", vlanFlowStr, err)
+	}
+	vlanRule := &EveroutePolicyRule{SrcIPAddr: "10.100.100.1", IPProtocol: PROTOCOL_TCP, DstPort: 80, VlanID: 100}
+	if match, err = ruleFlowMatch(vlanRule); err != nil {
+		t.Fatalf("failed to get match of rule %+v: %s", vlanRule, err)
+	}
+	if !reflect.DeepEqual(match, flow.Match) {
+		t.Fatalf("expect rule match %v, got %v", flow.Match, match)
+	}
+
+	if _, err = parseOfctlFlow("table=300, priority=200,ip actions=drop"); err == nil {
+		t.Fatalf("expect error when parse flow with invalid table")
+	}
+}
+
+func TestParseOfctlAggregateFlowCount(t *testing.T) {
+	count, err := parseOfctlAggregateFlowCount("OFPST_AGGREGATE reply (OF1.3) (xid=0x2): packet_count=10 byte_count=980 flow_count=42\n")
+	if err != nil {
+		t.Fatalf("failed to parse aggregate reply: %s", err)
+	}
+	if count != 42 {
+		t.Fatalf("expect flow count 42, got %d", count)
+	}
+
+	if _, err = parseOfctlAggregateFlowCount("ovs-ofctl: br0 is not a bridge or a socket"); err == nil {
+		t.Fatalf("expect error when parse reply without flow_count")
+	}
+}
+
+func TestFlushAfterFlowConverged(t *testing.T) {
+	t.Run("flush once after converged", func(t *testing.T) {
+		// -1 means failed to count flows
+		counts := []int{0, 10, 20, -1, 20, 35, 40, 40, 40, 40}
+		var sampled, flushed, sampledAtFlush int
+		countFlows := func() (int, error) {
+			count := counts[len(counts)-1]
+			if sampled < len(counts) {
+				count = counts[sampled]
+			}
+			sampled++
+			if count == -1 {
+				return 0, fmt.Errorf("some error")
+			}
+			return count, nil
+		}
+		flush := func() {
+			flushed++
+			sampledAtFlush = sampled
+		}
+
+		flushAfterFlowConverged(countFlows, flush, nil, time.Millisecond, 3, 0, time.Minute, make(chan struct{}))
+		if flushed != 1 {
+			t.Fatalf("expect flush exactly once, got %d", flushed)
+		}
+		// flow count keeps unchanged in the last three samples
+		if sampledAtFlush != len(counts) {
+			t.Fatalf("expect flush after %d samples, got %d", len(counts), sampledAtFlush)
+		}
+	})
+
+	t.Run("flush once after timeout", func(t *testing.T) {
+		var count, flushed int
+		countFlows := func() (int, error) {
+			count++
+			return count, nil
+		}
+		flushAfterFlowConverged(countFlows, func() { flushed++ }, nil, time.Millisecond, 3, 0, 50*time.Millisecond, make(chan struct{}))
+		if flushed != 1 {
+			t.Fatalf("expect flush exactly once, got %d", flushed)
+		}
+	})
+
+	t.Run("no flush after stopped", func(t *testing.T) {
+		var flushed int
+		stopChan := make(chan struct{})
+		close(stopChan)
+		flushAfterFlowConverged(func() (int, error) { return 0, nil }, func() { flushed++ }, nil, time.Millisecond, 3, 0, time.Minute, stopChan)
+		if flushed != 0 {
+			t.Fatalf("expect no flush after stopped, got %d", flushed)
+		}
+	})
+
+	t.Run("previous round rule flows should survive until current round rules installed", func(t *testing.T) {
+		// basic flows of the current round are installed before, they converge at once
+		var lock sync.Mutex
+		curRoundFlows := 40
+		previousRoundRuleFlows := 100
+		countFlows := func() (int, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			return curRoundFlows, nil
+		}
+		flush := func() {
+			lock.Lock()
+			defer lock.Unlock()
+			if curRoundFlows != 50 {
+				t.Errorf("previous round flows flushed before current round rule flows installed")
+			}
+			previousRoundRuleFlows = 0
+		}
+		synced := make(chan struct{})
+		flushDone := make(chan struct{})
+		go func() {
+			flushAfterFlowConverged(countFlows, flush, synced, time.Millisecond, 3, 0, time.Minute, make(chan struct{}))
+			close(flushDone)
+		}()
+
+		time.Sleep(50 * time.Millisecond)
+		lock.Lock()
+		if previousRoundRuleFlows != 100 {
+			t.Fatalf("expect previous round rule flows kept before policy rules synced")
+		}
+		// rules of the current round installed, then policy rules synced
+		curRoundFlows = 50
+		lock.Unlock()
+		close(synced)
+
+		select {
+		case <-flushDone:
+		case <-time.After(10 * time.Second):
+			t.Fatalf("expect previous round flows flushed after policy rules synced")
+		}
+		if previousRoundRuleFlows != 0 {
+			t.Fatalf("expect previous round rule flows flushed after policy rules synced")
+		}
+	})
+
+	t.Run("no flush in min delay", func(t *testing.T) {
+		var flushed int
+		start := time.Now()
+		flushAfterFlowConverged(func() (int, error) { return 10, nil }, func() { flushed++ }, nil, time.Millisecond, 3,
+			100*time.Millisecond, time.Minute, make(chan struct{}))
+		if flushed != 1 || time.Since(start) < 100*time.Millisecond {
+			t.Fatalf("expect flush once after min delay, got %d flushes after %s", flushed, time.Since(start))
+		}
+	})
+
+	t.Run("flush once if policy rules not synced in timeout", func(t *testing.T) {
+		var flushed int
+		flushAfterFlowConverged(func() (int, error) { return 10, nil }, func() { flushed++ }, make(chan struct{}),
+			time.Millisecond, 3, 0, 50*time.Millisecond, make(chan struct{}))
+		if flushed != 1 {
+			t.Fatalf("expect flush exactly once, got %d", flushed)
+		}
+	})
+}
+
 func TestUintToByteBigEndian(t *testing.T) {
 	tests := []struct {
 		name string
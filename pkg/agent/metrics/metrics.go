@@ -0,0 +1,307 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	namespace = "everoute"
+	subsystem = "agent"
+
+	RuleLabel           = "rule"
+	PolicyTypeLabel     = "policy_type"
+	BridgeLabel         = "bridge"
+	VDSLabel            = "vds"
+	OperationLabel      = "operation"
+	InterfaceUUIDLabel  = "interface_uuid"
+	InterfaceLabel      = "interface"
+	TierLabel           = "tier"
+	PacketInReasonLabel = "reason"
+	FlowIDExemplarName  = "flow_id"
+
+	RuleActionDeny   = "deny"
+	RuleActionReject = "reject"
+
+	RuleOperationAdd    = "add"
+	RuleOperationUpdate = "update"
+	RuleOperationRemove = "remove"
+)
+
+// ruleFlow is a policy rule flow installed in datapath
+type ruleFlow struct {
+	ruleID      string
+	action      string
+	policyType  string // policy type from the policy logging tags
+	packetCount uint64 // packet count of the last flow stats
+}
+
+// AgentMetric collects everoute agent metrics, per-rule counters carry the flow id
+// as exemplar, so that the metric could be correlated with the exact flow.
+type AgentMetric struct {
+	registry *prometheus.Registry
+
+	rulePacketCount     *prometheus.CounterVec
+	ruleDropPacketCount *prometheus.CounterVec
+
+	unmanagedBridgeEndpointCount *prometheus.CounterVec
+
+	ruleFlowInstallDuration *prometheus.HistogramVec
+	ruleFlowInstallFailures *prometheus.CounterVec
+
+	leakedFlows *prometheus.GaugeVec
+
+	ruleReplayReplayed *prometheus.GaugeVec
+	ruleReplayTotal    *prometheus.GaugeVec
+
+	packetInDroppedCount *prometheus.CounterVec
+
+	ruleFlowReinstalledCount *prometheus.CounterVec
+
+	endpointRules     *prometheus.GaugeVec
+	endpointRuleFlows *prometheus.GaugeVec
+
+	unknownTierRuleCount *prometheus.CounterVec
+
+	lock      sync.Mutex
+	ruleFlows map[uint64]*ruleFlow // map flow id to the rule flow
+}
+
+func NewAgentMetric() *AgentMetric {
+	m := &AgentMetric{
+		registry: prometheus.NewRegistry(),
+		rulePacketCount: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "rule_packets_total",
+			Help:      "The number of packets matched the policy rule",
+		}, []string{RuleLabel, PolicyTypeLabel}),
+		ruleDropPacketCount: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "rule_drop_packets_total",
+			Help:      "The number of packets dropped by the policy rule",
+		}, []string{RuleLabel, PolicyTypeLabel}),
+		unmanagedBridgeEndpointCount: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "unmanaged_bridge_endpoint_total",
+			Help:      "The number of endpoints skipped for attached to unmanaged bridge",
+		}, []string{BridgeLabel}),
+		ruleFlowInstallDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "rule_flow_install_duration_seconds",
+			Help:      "The duration of installing policy rule flow into vds",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+		}, []string{VDSLabel, OperationLabel}),
+		ruleFlowInstallFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "rule_flow_install_failures_total",
+			Help:      "The number of failures when install or remove policy rule flow",
+		}, []string{VDSLabel, OperationLabel}),
+		leakedFlows: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "leaked_flows",
+			Help:      "The number of flows on policy bridge not owned by any rule",
+		}, []string{VDSLabel}),
+		ruleReplayReplayed: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "rule_replay_replayed_rules",
+			Help:      "The number of rules replayed in the latest rule flows replay of vds",
+		}, []string{VDSLabel}),
+		ruleReplayTotal: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "rule_replay_total_rules",
+			Help:      "The number of rules to replay in the latest rule flows replay of vds",
+		}, []string{VDSLabel}),
+		packetInDroppedCount: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "packet_in_dropped_total",
+			Help:      "The number of packet-in dropped for exceeding the packet-in rate limit",
+		}, []string{BridgeLabel, PacketInReasonLabel}),
+		ruleFlowReinstalledCount: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "rule_flow_reinstalled_total",
+			Help:      "The number of rule flows absent in vds and reinstalled by the periodic reconcile",
+		}, []string{VDSLabel}),
+		endpointRules: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "endpoint_rules",
+			Help:      "The number of policy rules applied to the local endpoint",
+		}, []string{InterfaceUUIDLabel, InterfaceLabel}),
+		endpointRuleFlows: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "endpoint_rule_flows",
+			Help:      "The number of policy rule flows applied to the local endpoint installed in its vds",
+		}, []string{InterfaceUUIDLabel, InterfaceLabel}),
+		unknownTierRuleCount: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "unknown_tier_rules_skipped_total",
+			Help:      "The number of policy rules skipped for the tier isn't one of the configured policy tiers",
+		}, []string{TierLabel}),
+		ruleFlows: make(map[uint64]*ruleFlow),
+	}
+
+	m.registry.MustRegister(m.rulePacketCount, m.ruleDropPacketCount, m.unmanagedBridgeEndpointCount,
+		m.ruleFlowInstallDuration, m.ruleFlowInstallFailures, m.leakedFlows, m.ruleReplayReplayed, m.ruleReplayTotal,
+		m.packetInDroppedCount, m.ruleFlowReinstalledCount, m.endpointRules, m.endpointRuleFlows, m.unknownTierRuleCount)
+	return m
+}
+
+// Registry returns the registry which all agent metrics registered to
+func (m *AgentMetric) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Handler serves agent metrics in OpenMetrics format when negotiated, which is required by exemplars
+func (m *AgentMetric) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{EnableOpenMetrics: true})
+}
+
+// AddRuleFlow records the rule flow, its flow stats would be counted into the rule metrics labeled with
+// the policy type. Policy id and name are not used as labels, for they are unbounded.
+func (m *AgentMetric) AddRuleFlow(ruleID, action, policyType string, flowID uint64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.ruleFlows[flowID] = &ruleFlow{ruleID: ruleID, action: action, policyType: policyType}
+}
+
+// RemoveRuleFlow forgets the rule flow, and removes the rule metrics when none flow of the rule left
+func (m *AgentMetric) RemoveRuleFlow(flowID uint64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	flow, ok := m.ruleFlows[flowID]
+	if !ok {
+		return
+	}
+	delete(m.ruleFlows, flowID)
+
+	for _, item := range m.ruleFlows {
+		if item.ruleID == flow.ruleID && item.policyType == flow.policyType {
+			return
+		}
+	}
+	m.rulePacketCount.DeleteLabelValues(flow.ruleID, flow.policyType)
+	m.ruleDropPacketCount.DeleteLabelValues(flow.ruleID, flow.policyType)
+}
+
+// UpdateRuleFlowStats updates rule metrics with the flow stats, flows not belong to any rule would be ignored
+func (m *AgentMetric) UpdateRuleFlowStats(flowID uint64, packetCount uint64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	flow, ok := m.ruleFlows[flowID]
+	if !ok {
+		return
+	}
+
+	delta := packetCount - flow.packetCount
+	if packetCount < flow.packetCount {
+		// flow has been reinstalled, e.g. ovs-vswitchd restart
+		delta = packetCount
+	}
+	flow.packetCount = packetCount
+	if delta == 0 {
+		return
+	}
+
+	exemplar := prometheus.Labels{FlowIDExemplarName: fmt.Sprintf("%#x", flowID)}
+	addWithExemplar(m.rulePacketCount.WithLabelValues(flow.ruleID, flow.policyType), float64(delta), exemplar)
+	if flow.action == RuleActionDeny || flow.action == RuleActionReject {
+		addWithExemplar(m.ruleDropPacketCount.WithLabelValues(flow.ruleID, flow.policyType), float64(delta), exemplar)
+	}
+}
+
+// IncUnmanagedBridgeEndpoint counts endpoint attached to unmanaged bridge
+func (m *AgentMetric) IncUnmanagedBridgeEndpoint(bridge string) {
+	m.unmanagedBridgeEndpointCount.WithLabelValues(bridge).Inc()
+}
+
+// ObserveRuleFlowInstall records the duration of rule flow install, operation is add or update
+func (m *AgentMetric) ObserveRuleFlowInstall(vdsID, operation string, duration time.Duration) {
+	m.ruleFlowInstallDuration.WithLabelValues(vdsID, operation).Observe(duration.Seconds())
+}
+
+// IncRuleFlowInstallFailure counts rule flow install failures, operation is add, update or remove
+func (m *AgentMetric) IncRuleFlowInstallFailure(vdsID, operation string) {
+	m.ruleFlowInstallFailures.WithLabelValues(vdsID, operation).Inc()
+}
+
+// SetLeakedFlows sets the number of flows not owned by any rule on the vds
+func (m *AgentMetric) SetLeakedFlows(vdsID string, count int) {
+	m.leakedFlows.WithLabelValues(vdsID).Set(float64(count))
+}
+
+// SetRuleReplayProgress sets the progress of rule flows replay on the vds
+func (m *AgentMetric) SetRuleReplayProgress(vdsID string, replayed, total int) {
+	m.ruleReplayReplayed.WithLabelValues(vdsID).Set(float64(replayed))
+	m.ruleReplayTotal.WithLabelValues(vdsID).Set(float64(total))
+}
+
+// IncPacketInDropped counts packet-in of the reason from the bridge dropped by the packet-in rate limit
+func (m *AgentMetric) IncPacketInDropped(bridge, reason string) {
+	m.packetInDroppedCount.WithLabelValues(bridge, reason).Inc()
+}
+
+// IncUnknownTierRuleSkipped counts policy rules skipped for the tier unknown
+func (m *AgentMetric) IncUnknownTierRuleSkipped(tier string) {
+	m.unknownTierRuleCount.WithLabelValues(tier).Inc()
+}
+
+// AddRuleFlowReinstalled counts rule flows absent in the vds and reinstalled
+func (m *AgentMetric) AddRuleFlowReinstalled(vdsID string, count int) {
+	m.ruleFlowReinstalledCount.WithLabelValues(vdsID).Add(float64(count))
+}
+
+// SetEndpointRuleStats sets the number of rules and rule flows applied to the local endpoint
+func (m *AgentMetric) SetEndpointRuleStats(interfaceUUID, interfaceName string, rules, flows int) {
+	m.endpointRules.WithLabelValues(interfaceUUID, interfaceName).Set(float64(rules))
+	m.endpointRuleFlows.WithLabelValues(interfaceUUID, interfaceName).Set(float64(flows))
+}
+
+// RemoveEndpointRuleStats removes the rule metrics of the local endpoint
+func (m *AgentMetric) RemoveEndpointRuleStats(interfaceUUID, interfaceName string) {
+	m.endpointRules.DeleteLabelValues(interfaceUUID, interfaceName)
+	m.endpointRuleFlows.DeleteLabelValues(interfaceUUID, interfaceName)
+}
+
+func addWithExemplar(counter prometheus.Counter, value float64, exemplar prometheus.Labels) {
+	if adder, ok := counter.(prometheus.ExemplarAdder); ok {
+		adder.AddWithExemplar(value, exemplar)
+		return
+	}
+	counter.Add(value)
+}
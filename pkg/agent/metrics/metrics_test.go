@@ -0,0 +1,145 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func getRuleCounter(t *testing.T, m *AgentMetric, name, ruleID string) *dto.Counter {
+	mfs, err := m.Registry().Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %s", err)
+	}
+	for _, mf := range mfs {
+		if mf.GetName() != name {
+			continue
+		}
+		for _, metric := range mf.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == RuleLabel && label.GetValue() == ruleID {
+					return metric.GetCounter()
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func getLabelValue(t *testing.T, m *AgentMetric, name, ruleID, labelName string) (string, bool) {
+	mfs, err := m.Registry().Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %s", err)
+	}
+	for _, mf := range mfs {
+		if mf.GetName() != name {
+			continue
+		}
+		for _, metric := range mf.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels[RuleLabel] == ruleID {
+				value, ok := labels[labelName]
+				return value, ok
+			}
+		}
+	}
+	return "", false
+}
+
+func getExemplarFlowID(counter *dto.Counter) string {
+	for _, label := range counter.GetExemplar().GetLabel() {
+		if label.GetName() == FlowIDExemplarName {
+			return label.GetValue()
+		}
+	}
+	return ""
+}
+
+func TestRuleFlowStatsExemplar(t *testing.T) {
+	RegisterTestingT(t)
+
+	m := NewAgentMetric()
+	m.AddRuleFlow("rule1", "allow", "", 0x10000001)
+	m.AddRuleFlow("rule2", RuleActionDeny, "", 0x10000002)
+
+	t.Run("allow rule counter should carry flow id exemplar", func(t *testing.T) {
+		m.UpdateRuleFlowStats(0x10000001, 10)
+		counter := getRuleCounter(t, m, "everoute_agent_rule_packets_total", "rule1")
+		Expect(counter).ShouldNot(BeNil())
+		Expect(counter.GetValue()).Should(Equal(float64(10)))
+		Expect(getExemplarFlowID(counter)).Should(Equal("0x10000001"))
+		Expect(getRuleCounter(t, m, "everoute_agent_rule_drop_packets_total", "rule1")).Should(BeNil())
+	})
+
+	t.Run("deny rule drop counter should carry flow id exemplar", func(t *testing.T) {
+		m.UpdateRuleFlowStats(0x10000002, 5)
+		m.UpdateRuleFlowStats(0x10000002, 8)
+		counter := getRuleCounter(t, m, "everoute_agent_rule_drop_packets_total", "rule2")
+		Expect(counter).ShouldNot(BeNil())
+		Expect(counter.GetValue()).Should(Equal(float64(8)))
+		Expect(getExemplarFlowID(counter)).Should(Equal("0x10000002"))
+	})
+
+	t.Run("flow reinstalled should count new packets", func(t *testing.T) {
+		m.AddRuleFlow("rule1", "allow", "", 0x20000001)
+		m.RemoveRuleFlow(0x10000001)
+		m.UpdateRuleFlowStats(0x20000001, 3)
+		counter := getRuleCounter(t, m, "everoute_agent_rule_packets_total", "rule1")
+		Expect(counter.GetValue()).Should(Equal(float64(13)))
+		Expect(getExemplarFlowID(counter)).Should(Equal("0x20000001"))
+	})
+
+	t.Run("unknown flow should be ignored", func(t *testing.T) {
+		m.UpdateRuleFlowStats(0x30000001, 3)
+		Expect(getRuleCounter(t, m, "everoute_agent_rule_packets_total", "")).Should(BeNil())
+	})
+
+	t.Run("rule metrics should be removed with rule flows", func(t *testing.T) {
+		m.RemoveRuleFlow(0x20000001)
+		Expect(getRuleCounter(t, m, "everoute_agent_rule_packets_total", "rule1")).Should(BeNil())
+	})
+}
+
+func TestRuleMetricsPolicyTypeLabel(t *testing.T) {
+	RegisterTestingT(t)
+
+	m := NewAgentMetric()
+	m.AddRuleFlow("rule1", RuleActionDeny, "SecurityPolicyDeny", 0x10000001)
+	m.AddRuleFlow("rule2", "allow", "QuarantinePolicy", 0x10000002)
+	m.UpdateRuleFlowStats(0x10000001, 10)
+	m.UpdateRuleFlowStats(0x10000002, 10)
+
+	for name, expect := range map[string]map[string]string{
+		"everoute_agent_rule_packets_total":      {"rule1": "SecurityPolicyDeny", "rule2": "QuarantinePolicy"},
+		"everoute_agent_rule_drop_packets_total": {"rule1": "SecurityPolicyDeny"},
+	} {
+		for ruleID, policyType := range expect {
+			value, ok := getLabelValue(t, m, name, ruleID, PolicyTypeLabel)
+			Expect(ok).Should(BeTrue(), "metric %s of rule %s should have label %s", name, ruleID, PolicyTypeLabel)
+			Expect(value).Should(Equal(policyType))
+		}
+	}
+
+	m.RemoveRuleFlow(0x10000001)
+	Expect(getRuleCounter(t, m, "everoute_agent_rule_packets_total", "rule1")).Should(BeNil())
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rpcserver
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/everoute/everoute/pkg/agent/datapath"
+	"github.com/everoute/everoute/pkg/apis/rpc/v1alpha1"
+)
+
+// Admin serves rpcs changing the datapath at runtime, it's registered only on the unix socket, which
+// is accessible only to privileged users of the node.
+type Admin struct {
+	dpManager *datapath.DpManager
+}
+
+func NewAdminServer(datapathManager *datapath.DpManager) *Admin {
+	return &Admin{dpManager: datapathManager}
+}
+
+func (a *Admin) SetTableMissAction(ctx context.Context, action *v1alpha1.TableMissAction) (*emptypb.Empty, error) {
+	err := a.dpManager.SetTableMissAction(action.GetVdsID(), datapath.TableMissAction(action.GetAction()))
+	return &emptypb.Empty{}, err
+}
+
+func (a *Admin) SetRuleGroup(ctx context.Context, group *v1alpha1.RuleGroup) (*emptypb.Empty, error) {
+	err := a.dpManager.SetRuleGroupEnabled(group.GetName(), group.GetEnabled())
+	return &emptypb.Empty{}, err
+}
+
+func (a *Admin) RefreshEndpointIP(ctx context.Context, refresh *v1alpha1.EndpointIPRefresh) (*v1alpha1.EndpointIPRefreshResults, error) {
+	results, err := a.dpManager.RefreshEndpointIP(ctx, refresh.GetInterfaceUUID())
+	if err != nil {
+		return nil, err
+	}
+	return &v1alpha1.EndpointIPRefreshResults{Results: results}, nil
+}
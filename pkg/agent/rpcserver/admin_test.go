@@ -0,0 +1,56 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rpcserver
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/everoute/pkg/agent/datapath"
+	"github.com/everoute/everoute/pkg/apis/rpc/v1alpha1"
+)
+
+func TestSetTableMissAction(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpManager := newFakeDpManager()
+	admin := NewAdminServer(dpManager)
+
+	_, err := admin.SetTableMissAction(context.Background(), &v1alpha1.TableMissAction{VdsID: "vds1", Action: string(datapath.TableMissFailOpen)})
+	Expect(err).ShouldNot(HaveOccurred())
+	action, err := dpManager.GetTableMissAction("vds1")
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(action).Should(Equal(datapath.TableMissFailOpen))
+
+	_, err = admin.SetTableMissAction(context.Background(), &v1alpha1.TableMissAction{VdsID: "vds2", Action: string(datapath.TableMissFailOpen)})
+	Expect(err).Should(HaveOccurred())
+}
+
+func TestRPCSetTableMissAction(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpManager := newFakeDpManager()
+	clients := startTestServer(t, dpManager)
+
+	_, err := clients.Admin.SetTableMissAction(context.Background(), &v1alpha1.TableMissAction{VdsID: "vds1", Action: string(datapath.TableMissFailOpen)})
+	Expect(err).ShouldNot(HaveOccurred())
+	action, err := dpManager.GetTableMissAction("vds1")
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(action).Should(Equal(datapath.TableMissFailOpen))
+}
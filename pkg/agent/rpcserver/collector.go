@@ -18,6 +18,7 @@ package rpcserver
 
 import (
 	"context"
+	"time"
 
 	"google.golang.org/protobuf/types/known/emptypb"
 	"k8s.io/klog"
@@ -88,6 +89,32 @@ func (c *Collector) Policy(ctx context.Context, req *v1alpha1.PolicyRequest) (*v
 	return &v1alpha1.PolicyResponse{List: policyList}, nil
 }
 
+// RuleStatsStream sends hit counters of rules periodically until the client or server stopped
+func (c *Collector) RuleStatsStream(req *v1alpha1.RuleStatsRequest, srv v1alpha1.Collector_RuleStatsStreamServer) error {
+	interval := datapath.RuleFlowStatsUpdateInterval * time.Second
+	if req.GetIntervalSeconds() != 0 {
+		interval = time.Duration(req.GetIntervalSeconds()) * time.Second
+	}
+	klog.Infof("receive collector client, start rule stats stream with interval %s", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := srv.Send(&v1alpha1.RuleStatsResponse{Stats: c.dpManager.GetRuleStats()}); err != nil {
+			klog.Infof("send error %v", err)
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-srv.Context().Done():
+			return nil
+		case <-c.stopChan:
+			return nil
+		}
+	}
+}
+
 func NewCollectorServer(datapathManager *datapath.DpManager, stopChan <-chan struct{}) *Collector {
 	c := &Collector{
 		dpManager: datapathManager,
@@ -0,0 +1,104 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rpcserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/contiv/libOpenflow/openflow13"
+	"github.com/contiv/ofnet/ofctrl"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+
+	"github.com/everoute/everoute/pkg/agent/datapath"
+	"github.com/everoute/everoute/pkg/apis/rpc/v1alpha1"
+)
+
+type fakeRuleStatsStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	cancel context.CancelFunc
+	sent   []*v1alpha1.RuleStatsResponse
+}
+
+func (s *fakeRuleStatsStream) Context() context.Context {
+	return s.ctx
+}
+
+// Send records the response and closes the stream after the first response
+func (s *fakeRuleStatsStream) Send(resp *v1alpha1.RuleStatsResponse) error {
+	s.sent = append(s.sent, resp)
+	s.cancel()
+	return nil
+}
+
+func flowStatsReply(stats ...*openflow13.FlowStats) *openflow13.MultipartReply {
+	reply := &openflow13.MultipartReply{Type: openflow13.MultipartType_Flow}
+	for _, item := range stats {
+		reply.Body = append(reply.Body, item)
+	}
+	return reply
+}
+
+func TestRuleStatsStream(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpManager := newFakeDpManager()
+	policyBridge2 := datapath.NewPolicyBridge("ovsbr2", dpManager)
+	dpManager.BridgeChainMap["vds2"] = map[string]datapath.Bridge{
+		datapath.LOCAL_BRIDGE_KEYWORD:  datapath.NewLocalBridge("ovsbr2", dpManager),
+		datapath.POLICY_BRIDGE_KEYWORD: policyBridge2,
+	}
+	rule := dpManager.Rules["rule1"]
+	rule.RuleFlowMap["vds2"] = &datapath.FlowEntry{
+		Table:    &ofctrl.Table{TableId: datapath.INGRESS_TIER2_TABLE},
+		Priority: 200,
+		FlowID:   0x20000001,
+	}
+	dpManager.FlowIDToRules[0x10000001] = rule
+	dpManager.FlowIDToRules[0x20000001] = rule
+
+	policyBridge1 := dpManager.BridgeChainMap["vds1"][datapath.POLICY_BRIDGE_KEYWORD].(*datapath.PolicyBridge)
+	policyBridge1.MultipartReply(nil, flowStatsReply(
+		&openflow13.FlowStats{TableId: datapath.INGRESS_TIER2_TABLE, Priority: 200, Cookie: 0x10000001, PacketCount: 10, ByteCount: 1000},
+		// flow not owned by any rule should be skipped
+		&openflow13.FlowStats{TableId: datapath.INGRESS_TIER2_TABLE, Priority: 200, Cookie: 0x10000002, PacketCount: 7, ByteCount: 700},
+	))
+	policyBridge2.MultipartReply(nil, flowStatsReply(
+		&openflow13.FlowStats{TableId: datapath.INGRESS_TIER2_TABLE, Priority: 200, Cookie: 0x20000001, PacketCount: 5, ByteCount: 300},
+	))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &fakeRuleStatsStream{ctx: ctx, cancel: cancel}
+	collector := NewCollectorServer(dpManager, make(chan struct{}))
+	Expect(collector.RuleStatsStream(&v1alpha1.RuleStatsRequest{IntervalSeconds: 1}, stream)).Should(Succeed())
+
+	Expect(stream.sent).Should(HaveLen(1))
+	stats := stream.sent[0].GetStats()
+	Expect(stats).Should(HaveLen(1))
+	Expect(stats[0].GetRuleID()).Should(Equal("rule1"))
+	Expect(stats[0].GetPacketCount()).Should(Equal(uint64(15)))
+	Expect(stats[0].GetByteCount()).Should(Equal(uint64(1300)))
+	Expect(stats[0].GetFlows()).Should(HaveLen(2))
+	Expect(stats[0].GetFlows()[0].GetVdsID()).Should(Equal("vds1"))
+	Expect(stats[0].GetFlows()[0].GetFlowID()).Should(Equal(uint64(0x10000001)))
+	Expect(stats[0].GetFlows()[0].GetPacketCount()).Should(Equal(uint64(10)))
+	Expect(stats[0].GetFlows()[1].GetVdsID()).Should(Equal("vds2"))
+	Expect(stats[0].GetFlows()[1].GetFlowID()).Should(Equal(uint64(0x20000001)))
+	Expect(stats[0].GetFlows()[1].GetByteCount()).Should(Equal(uint64(300)))
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rpcserver
+
+import (
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// HealthCheckInterval is the interval to update the health status of the agent
+const HealthCheckInterval = time.Second
+
+// newHealthServer returns the standard grpc health server, it reports NOT_SERVING until ready
+func newHealthServer() *health.Server {
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	return healthServer
+}
+
+// runHealthCheck updates the health status by ready periodically until stopChan closed, the status
+// is SERVING when ready, e.g. all bridges connected, otherwise NOT_SERVING.
+func runHealthCheck(healthServer *health.Server, ready func() bool, interval time.Duration, stopChan <-chan struct{}) {
+	wait.Until(func() {
+		status := healthpb.HealthCheckResponse_NOT_SERVING
+		if ready() {
+			status = healthpb.HealthCheckResponse_SERVING
+		}
+		healthServer.SetServingStatus("", status)
+	}, interval, stopChan)
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rpcserver
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestHealthCheck(t *testing.T) {
+	RegisterTestingT(t)
+
+	var bridgesConnected atomic.Bool
+	healthServer := newHealthServer()
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+
+	status := func() healthpb.HealthCheckResponse_ServingStatus {
+		resp, err := healthServer.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		Expect(err).ShouldNot(HaveOccurred())
+		return resp.GetStatus()
+	}
+	Expect(status()).Should(Equal(healthpb.HealthCheckResponse_NOT_SERVING))
+
+	go runHealthCheck(healthServer, bridgesConnected.Load, 10*time.Millisecond, stopChan)
+	Consistently(status, 100*time.Millisecond, 10*time.Millisecond).Should(Equal(healthpb.HealthCheckResponse_NOT_SERVING))
+
+	bridgesConnected.Store(true)
+	Eventually(status, time.Second, 10*time.Millisecond).Should(Equal(healthpb.HealthCheckResponse_SERVING))
+
+	bridgesConnected.Store(false)
+	Eventually(status, time.Second, 10*time.Millisecond).Should(Equal(healthpb.HealthCheckResponse_NOT_SERVING))
+}
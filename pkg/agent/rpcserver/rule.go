@@ -3,32 +3,130 @@ package rpcserver
 import (
 	"context"
 	"fmt"
+	"math"
+	"net"
 	"strconv"
 
 	"google.golang.org/protobuf/types/known/emptypb"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	ctrlProxy "github.com/everoute/everoute/pkg/agent/controller/proxy"
 	"github.com/everoute/everoute/pkg/agent/datapath"
 	"github.com/everoute/everoute/pkg/apis/rpc/v1alpha1"
+	securityv1alpha1 "github.com/everoute/everoute/pkg/apis/security/v1alpha1"
+	"github.com/everoute/everoute/pkg/labels"
 )
 
 type Getter struct {
 	dpManager  *datapath.DpManager
 	proxyCache *ctrlProxy.Cache
+	// policyReader reads policies from the agent policy cache
+	policyReader client.Reader
 }
 
-func (g *Getter) GetAllRules(context.Context, *emptypb.Empty) (*v1alpha1.RuleEntries, error) {
-	rules := g.dpManager.GetAllRules()
+func (g *Getter) GetAllRules(ctx context.Context, query *v1alpha1.RuleQuery) (*v1alpha1.RuleEntries, error) {
+	rules := g.dpManager.GetAllRules(query.GetVdsID())
 	return &v1alpha1.RuleEntries{RuleEntries: rules}, nil
 }
 
 func (g *Getter) GetRulesByName(ctx context.Context, ruleIDs *v1alpha1.RuleIDs) (*v1alpha1.RuleEntries, error) {
-	rules := g.dpManager.GetRulesByRuleIDs(ruleIDs.RuleIDs...)
+	rules := g.dpManager.GetRulesByRuleIDs(ruleIDs.GetVdsID(), ruleIDs.RuleIDs...)
 	return &v1alpha1.RuleEntries{RuleEntries: rules}, nil
 }
 
 func (g *Getter) GetRulesByFlow(ctx context.Context, flowIDs *v1alpha1.FlowIDs) (*v1alpha1.RuleEntries, error) {
-	rules := g.dpManager.GetRulesByFlowIDs(flowIDs.FlowIDs...)
+	rules := g.dpManager.GetRulesByFlowIDs(flowIDs.GetVdsID(), flowIDs.FlowIDs...)
+	return &v1alpha1.RuleEntries{RuleEntries: rules}, nil
+}
+
+func (g *Getter) DumpFlows(context.Context, *emptypb.Empty) (*v1alpha1.FlowDumps, error) {
+	bridgeFlowDumps := g.dpManager.DumpFlows()
+	return &v1alpha1.FlowDumps{BridgeFlowDumps: bridgeFlowDumps}, nil
+}
+
+func (g *Getter) GetLeakedFlows(ctx context.Context, query *v1alpha1.LeakedFlowQuery) (*v1alpha1.LeakedFlows, error) {
+	leakedFlows := g.dpManager.DetectLeakedFlows(query.GetRemove())
+	return &v1alpha1.LeakedFlows{LeakedFlows: leakedFlows}, nil
+}
+
+func (g *Getter) GetEndpoints(ctx context.Context, empty *emptypb.Empty) (*v1alpha1.Endpoints, error) {
+	return &v1alpha1.Endpoints{Endpoints: g.dpManager.GetEndpoints()}, nil
+}
+
+func (g *Getter) GetReplayStatus(ctx context.Context, empty *emptypb.Empty) (*v1alpha1.ReplayStatuses, error) {
+	return &v1alpha1.ReplayStatuses{ReplayStatuses: g.dpManager.GetReplayStatus()}, nil
+}
+
+func (g *Getter) GetRuleConflicts(ctx context.Context, empty *emptypb.Empty) (*v1alpha1.RuleConflicts, error) {
+	return &v1alpha1.RuleConflicts{RuleConflicts: g.dpManager.GetRuleConflicts()}, nil
+}
+
+// GetReferencedLabels returns endpoint labels referenced by selectors of policies in the policy cache,
+// labels referenced should not be removed from endpoints, or the policies would not select them.
+func (g *Getter) GetReferencedLabels(ctx context.Context, empty *emptypb.Empty) (*v1alpha1.ReferencedLabels, error) {
+	if g.policyReader == nil {
+		return nil, fmt.Errorf("agent doesn't enable policy cache")
+	}
+	var policyList securityv1alpha1.SecurityPolicyList
+	if err := g.policyReader.List(ctx, &policyList); err != nil {
+		return nil, fmt.Errorf("list policies: %s", err)
+	}
+	return &v1alpha1.ReferencedLabels{Labels: referencedLabels(policyList.Items)}, nil
+}
+
+// GetTableLayout returns the ovs tables each policy tier and bridge stage maps to
+func (g *Getter) GetTableLayout(ctx context.Context, empty *emptypb.Empty) (*v1alpha1.TableLayout, error) {
+	return g.dpManager.GetTableLayout(), nil
+}
+
+// GetPolicyDecisions returns the recorded policy decisions from the oldest to the latest
+func (g *Getter) GetPolicyDecisions(ctx context.Context, empty *emptypb.Empty) (*v1alpha1.PolicyDecisions, error) {
+	if g.dpManager.Config.DecisionRecordSize <= 0 {
+		return nil, fmt.Errorf("agent doesn't enable decision recording")
+	}
+	ans := &v1alpha1.PolicyDecisions{}
+	for _, decision := range g.dpManager.GetPolicyDecisions() {
+		ans.Decisions = append(ans.Decisions, &v1alpha1.PolicyDecision{
+			Timestamp:  decision.Time.Unix(),
+			BridgeName: decision.BridgeName,
+			SrcIP:      decision.SrcIP,
+			DstIP:      decision.DstIP,
+			Protocol:   uint32(decision.IPProtocol),
+			SrcPort:    uint32(decision.SrcPort),
+			DstPort:    uint32(decision.DstPort),
+			FlowID:     decision.FlowID,
+			RuleID:     decision.RuleID,
+			Action:     decision.Action,
+		})
+	}
+	return ans, nil
+}
+
+func (g *Getter) QueryReachable(ctx context.Context, query *v1alpha1.ReachableQuery) (*v1alpha1.ReachableResult, error) {
+	srcIP, dstIP := net.ParseIP(query.GetSrcIP()), net.ParseIP(query.GetDstIP())
+	if srcIP == nil || dstIP == nil {
+		return nil, fmt.Errorf("invalid src ip %s or dst ip %s", query.GetSrcIP(), query.GetDstIP())
+	}
+	if query.GetProtocol() > math.MaxUint8 || query.GetPort() > math.MaxUint16 {
+		return nil, fmt.Errorf("invalid protocol %d or port %d", query.GetProtocol(), query.GetPort())
+	}
+	return g.dpManager.QueryReachable(srcIP, dstIP, uint8(query.GetProtocol()), uint16(query.GetPort())), nil
+}
+
+func (g *Getter) GetEffectiveRules(ctx context.Context, query *v1alpha1.EffectiveRuleQuery) (*v1alpha1.RuleEntries, error) {
+	ip := net.ParseIP(query.GetIP())
+	if ip == nil {
+		return nil, fmt.Errorf("invalid ip %s", query.GetIP())
+	}
+	if query.GetVlanID() > math.MaxUint16 {
+		return nil, fmt.Errorf("invalid vlan id %d", query.GetVlanID())
+	}
+	rules, err := g.dpManager.GetEffectiveRules(ip, uint16(query.GetVlanID()))
+	if err != nil {
+		return nil, err
+	}
 	return &v1alpha1.RuleEntries{RuleEntries: rules}, nil
 }
 
@@ -112,10 +210,69 @@ func (g *Getter) GetSvcInfoBySvcID(ctx context.Context, svcID *v1alpha1.SvcID) (
 	return svcInfo, nil
 }
 
-func NewGetterServer(datapathManager *datapath.DpManager, proxyCache *ctrlProxy.Cache) *Getter {
+// referencedLabels collects label keys and values from endpoint selectors of policies applied to and peers,
+// the result sorted by key.
+func referencedLabels(policies []securityv1alpha1.SecurityPolicy) []*v1alpha1.ReferencedLabel {
+	values := make(map[string]sets.Set[string])
+	anyValue := sets.New[string]()
+	reference := func(selector *labels.Selector) {
+		if selector == nil || selector.MatchNothing {
+			return
+		}
+		refValues := func(key string, vs ...string) {
+			if values[key] == nil {
+				values[key] = sets.New[string]()
+			}
+			values[key].Insert(vs...)
+		}
+		for key, value := range selector.MatchLabels {
+			refValues(key, value)
+		}
+		for key, vs := range selector.ExtendMatchLabels {
+			refValues(key, vs...)
+		}
+		for _, expr := range selector.MatchExpressions {
+			refValues(expr.Key, expr.Values...)
+			if expr.Operator != metav1.LabelSelectorOpIn {
+				anyValue.Insert(expr.Key)
+			}
+		}
+	}
+
+	for i := range policies {
+		spec := &policies[i].Spec
+		for _, appliedTo := range spec.AppliedTo {
+			reference(appliedTo.EndpointSelector)
+		}
+		for _, rule := range spec.IngressRules {
+			for _, peer := range rule.From {
+				reference(peer.EndpointSelector)
+			}
+		}
+		for _, rule := range spec.EgressRules {
+			for _, peer := range rule.To {
+				reference(peer.EndpointSelector)
+			}
+		}
+	}
+
+	keys := sets.List(sets.KeySet(values))
+	ans := make([]*v1alpha1.ReferencedLabel, 0, len(keys))
+	for _, key := range keys {
+		ans = append(ans, &v1alpha1.ReferencedLabel{
+			Key:      key,
+			Values:   sets.List(values[key]),
+			AnyValue: anyValue.Has(key),
+		})
+	}
+	return ans
+}
+
+func NewGetterServer(datapathManager *datapath.DpManager, proxyCache *ctrlProxy.Cache, policyReader client.Reader) *Getter {
 	s := &Getter{
-		dpManager:  datapathManager,
-		proxyCache: proxyCache,
+		dpManager:    datapathManager,
+		proxyCache:   proxyCache,
+		policyReader: policyReader,
 	}
 
 	return s
@@ -0,0 +1,443 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rpcserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/contiv/ofnet/ofctrl"
+	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/types/known/emptypb"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/everoute/everoute/pkg/agent/datapath"
+	"github.com/everoute/everoute/pkg/apis/rpc/v1alpha1"
+	securityv1alpha1 "github.com/everoute/everoute/pkg/apis/security/v1alpha1"
+	"github.com/everoute/everoute/pkg/client/clientset_generated/clientset/scheme"
+	"github.com/everoute/everoute/pkg/labels"
+)
+
+// newFakeDpManager returns a datapath manager without any vds connected, bridges and rules could be filled by test
+func newFakeDpManager() *datapath.DpManager {
+	dpManager := datapath.NewDatapathManager(&datapath.DpManagerConfig{ManagedVDSMap: map[string]string{}}, nil)
+	dpManager.BridgeChainMap["vds1"] = map[string]datapath.Bridge{
+		datapath.LOCAL_BRIDGE_KEYWORD:  datapath.NewLocalBridge("ovsbr1", dpManager),
+		datapath.POLICY_BRIDGE_KEYWORD: datapath.NewPolicyBridge("ovsbr1", dpManager),
+	}
+	dpManager.Rules["rule1"] = &datapath.EveroutePolicyRuleEntry{
+		EveroutePolicyRule: &datapath.EveroutePolicyRule{
+			RuleID:    "rule1",
+			Priority:  200,
+			SrcIPAddr: "10.100.100.1",
+			Action:    "allow",
+		},
+		Direction: datapath.POLICY_DIRECTION_IN,
+		Tier:      datapath.POLICY_TIER2,
+		Mode:      datapath.DEFAULT_POLICY_ENFORCEMENT_MODE,
+		RuleFlowMap: map[string]*datapath.FlowEntry{
+			"vds1": {
+				Table:    &ofctrl.Table{TableId: datapath.INGRESS_TIER2_TABLE},
+				Priority: 200,
+				FlowID:   0x10000001,
+			},
+		},
+		PolicyRuleReference: sets.NewString("ns1/policy1/normal"),
+	}
+	return dpManager
+}
+
+func TestDumpFlows(t *testing.T) {
+	RegisterTestingT(t)
+
+	getter := NewGetterServer(newFakeDpManager(), nil, nil)
+	flowDumps, err := getter.DumpFlows(context.Background(), &emptypb.Empty{})
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(flowDumps.GetBridgeFlowDumps()).Should(HaveLen(2))
+
+	localDump := flowDumps.GetBridgeFlowDumps()[0]
+	Expect(localDump.GetVdsID()).Should(Equal("vds1"))
+	Expect(localDump.GetBridgeKeyword()).Should(Equal(datapath.LOCAL_BRIDGE_KEYWORD))
+	Expect(localDump.GetBridgeName()).Should(Equal("ovsbr1"))
+	Expect(localDump.GetFlowEntries()).Should(BeEmpty())
+
+	policyDump := flowDumps.GetBridgeFlowDumps()[1]
+	Expect(policyDump.GetVdsID()).Should(Equal("vds1"))
+	Expect(policyDump.GetBridgeKeyword()).Should(Equal(datapath.POLICY_BRIDGE_KEYWORD))
+	Expect(policyDump.GetBridgeName()).Should(Equal("ovsbr1-policy"))
+	Expect(policyDump.GetFlowEntries()).Should(HaveLen(1))
+
+	flowEntry := policyDump.GetFlowEntries()[0]
+	Expect(flowEntry.GetRuleID()).Should(Equal("rule1"))
+	Expect(flowEntry.GetFlowEntry().GetTableID()).Should(Equal(uint32(datapath.INGRESS_TIER2_TABLE)))
+	Expect(flowEntry.GetFlowEntry().GetPriority()).Should(Equal(uint32(200)))
+	Expect(flowEntry.GetFlowEntry().GetFlowID()).Should(Equal(uint64(0x10000001)))
+	Expect(flowEntry.GetPolicyRuleReference()).Should(ConsistOf(&v1alpha1.PolicyRuleReference{
+		NameSpace: "ns1",
+		Name:      "policy1",
+		Type:      "normal",
+	}))
+}
+
+func newTestRuleEntry(ruleID, action string, direction, tier uint8, priority int, srcIP, dstIP string, port uint16, ref string) *datapath.EveroutePolicyRuleEntry {
+	rule := &datapath.EveroutePolicyRule{
+		RuleID:    ruleID,
+		Priority:  priority,
+		SrcIPAddr: srcIP,
+		DstIPAddr: dstIP,
+		Action:    action,
+	}
+	if port != 0 {
+		rule.IPProtocol = 6
+		rule.DstPort = port
+		rule.DstPortMask = 0xffff
+	}
+	return &datapath.EveroutePolicyRuleEntry{
+		EveroutePolicyRule:  rule,
+		Direction:           direction,
+		Tier:                tier,
+		Mode:                datapath.DEFAULT_POLICY_ENFORCEMENT_MODE,
+		RuleFlowMap:         map[string]*datapath.FlowEntry{},
+		PolicyRuleReference: sets.NewString(ref),
+	}
+}
+
+func TestQueryReachable(t *testing.T) {
+	const (
+		srcIP = "10.0.0.1"
+		dstIP = "10.0.0.2"
+	)
+	in, out := uint8(datapath.POLICY_DIRECTION_IN), uint8(datapath.POLICY_DIRECTION_OUT)
+	dstDefaultDrop := newTestRuleEntry("dst-default-drop", "deny", in, datapath.POLICY_TIER3, 70, "", dstIP+"/32", 0, "ns/dst-policy/normal")
+	dstAllowHTTP := newTestRuleEntry("dst-allow-http", "allow", in, datapath.POLICY_TIER3, 100, srcIP+"/32", dstIP+"/32", 80, "ns/dst-policy/normal")
+	srcDefaultDrop := newTestRuleEntry("src-default-drop", "deny", out, datapath.POLICY_TIER3, 70, srcIP+"/32", "", 0, "ns/src-policy/normal")
+	srcSymmetricAllowHTTP := newTestRuleEntry("src-symmetric-allow-http", "allow", out, datapath.POLICY_TIER3, 100, srcIP+"/32", dstIP+"/32", 80, "ns/dst-policy/normal")
+
+	testCases := map[string]struct {
+		rules         []*datapath.EveroutePolicyRuleEntry
+		port          uint32
+		reachable     bool
+		egressRuleID  string
+		ingressRuleID string
+		decidedPolicy string
+	}{
+		"should reachable without any rules": {
+			port:      80,
+			reachable: true,
+		},
+		"should reachable by ingress allow rule": {
+			rules:         []*datapath.EveroutePolicyRuleEntry{dstDefaultDrop, dstAllowHTTP},
+			port:          80,
+			reachable:     true,
+			ingressRuleID: "dst-allow-http",
+			decidedPolicy: "dst-policy",
+		},
+		"should unreachable by ingress default rule when port not match": {
+			rules:         []*datapath.EveroutePolicyRuleEntry{dstDefaultDrop, dstAllowHTTP},
+			port:          443,
+			reachable:     false,
+			ingressRuleID: "dst-default-drop",
+			decidedPolicy: "dst-policy",
+		},
+		"isolation tier should override lower tiers": {
+			rules: []*datapath.EveroutePolicyRuleEntry{
+				dstDefaultDrop, dstAllowHTTP,
+				newTestRuleEntry("isolation-drop", "deny", in, datapath.POLICY_TIER1, 70, "", dstIP+"/32", 0, "ns/isolation/normal"),
+			},
+			port:          80,
+			reachable:     false,
+			ingressRuleID: "isolation-drop",
+			decidedPolicy: "isolation",
+		},
+		"tier ecp should override tier2 rules even with lower priority": {
+			rules: []*datapath.EveroutePolicyRuleEntry{
+				newTestRuleEntry("ecp-drop", "deny", in, datapath.POLICY_TIER_ECP, 10, srcIP, "", 0, "ns/ecp/normal"),
+				newTestRuleEntry("tier2-allow", "allow", in, datapath.POLICY_TIER3, 200, srcIP, "", 80, "ns/tier2/normal"),
+			},
+			port:          80,
+			reachable:     false,
+			ingressRuleID: "ecp-drop",
+			decidedPolicy: "ecp",
+		},
+		"tier1 should override tier ecp rules": {
+			rules: []*datapath.EveroutePolicyRuleEntry{
+				newTestRuleEntry("tier1-allow", "allow", in, datapath.POLICY_TIER2, 10, srcIP, "", 80, "ns/tier1/normal"),
+				newTestRuleEntry("ecp-drop", "deny", in, datapath.POLICY_TIER_ECP, 200, srcIP, "", 0, "ns/ecp/normal"),
+			},
+			port:          80,
+			reachable:     true,
+			ingressRuleID: "tier1-allow",
+			decidedPolicy: "tier1",
+		},
+		"higher priority rule should win in the same tier": {
+			rules: []*datapath.EveroutePolicyRuleEntry{
+				newTestRuleEntry("low-allow", "allow", in, datapath.POLICY_TIER3, 100, srcIP, "", 80, "ns/low/normal"),
+				newTestRuleEntry("high-drop", "deny", in, datapath.POLICY_TIER3, 110, srcIP, "", 80, "ns/high/normal"),
+			},
+			port:          80,
+			reachable:     false,
+			ingressRuleID: "high-drop",
+			decidedPolicy: "high",
+		},
+		"should unreachable by egress default rule without symmetric rule": {
+			rules:         []*datapath.EveroutePolicyRuleEntry{srcDefaultDrop, dstDefaultDrop, dstAllowHTTP},
+			port:          80,
+			reachable:     false,
+			egressRuleID:  "src-default-drop",
+			ingressRuleID: "dst-allow-http",
+			decidedPolicy: "src-policy",
+		},
+		"should reachable by symmetric egress rule": {
+			rules:         []*datapath.EveroutePolicyRuleEntry{srcDefaultDrop, srcSymmetricAllowHTTP, dstDefaultDrop, dstAllowHTTP},
+			port:          80,
+			reachable:     true,
+			egressRuleID:  "src-symmetric-allow-http",
+			ingressRuleID: "dst-allow-http",
+			decidedPolicy: "dst-policy",
+		},
+		"monitor mode rule should not decide": {
+			rules: []*datapath.EveroutePolicyRuleEntry{func() *datapath.EveroutePolicyRuleEntry {
+				entry := newTestRuleEntry("monitor-drop", "deny", in, datapath.POLICY_TIER3, 100, srcIP, "", 0, "ns/monitor/normal")
+				entry.Mode = "monitor"
+				return entry
+			}()},
+			port:      80,
+			reachable: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			RegisterTestingT(t)
+			dpManager := datapath.NewDatapathManager(&datapath.DpManagerConfig{ManagedVDSMap: map[string]string{}}, nil)
+			for _, entry := range tc.rules {
+				dpManager.Rules[entry.EveroutePolicyRule.RuleID] = entry
+			}
+			getter := NewGetterServer(dpManager, nil, nil)
+
+			result, err := getter.QueryReachable(context.Background(), &v1alpha1.ReachableQuery{
+				SrcIP:    srcIP,
+				DstIP:    dstIP,
+				Protocol: 6,
+				Port:     tc.port,
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result.GetReachable()).Should(Equal(tc.reachable))
+			Expect(result.GetEgress().GetRuleID()).Should(Equal(tc.egressRuleID))
+			Expect(result.GetIngress().GetRuleID()).Should(Equal(tc.ingressRuleID))
+
+			if tc.decidedPolicy != "" {
+				decision := result.GetIngress()
+				if result.GetEgress().GetAction() == "deny" || result.GetReachable() && result.GetEgress().GetRuleID() != "" && result.GetIngress().GetRuleID() == "" {
+					decision = result.GetEgress()
+				}
+				Expect(decision.GetPolicyRuleReference()).Should(HaveLen(1))
+				Expect(decision.GetPolicyRuleReference()[0].GetName()).Should(Equal(tc.decidedPolicy))
+			}
+		})
+	}
+
+	t.Run("should return error with invalid ip", func(t *testing.T) {
+		RegisterTestingT(t)
+		getter := NewGetterServer(newFakeDpManager(), nil, nil)
+		_, err := getter.QueryReachable(context.Background(), &v1alpha1.ReachableQuery{SrcIP: "invalid", DstIP: dstIP})
+		Expect(err).Should(HaveOccurred())
+	})
+}
+
+func TestGetRuleConflicts(t *testing.T) {
+	RegisterTestingT(t)
+	in, out := uint8(datapath.POLICY_DIRECTION_IN), uint8(datapath.POLICY_DIRECTION_OUT)
+
+	dpManager := datapath.NewDatapathManager(&datapath.DpManagerConfig{ManagedVDSMap: map[string]string{}}, nil)
+	for _, entry := range []*datapath.EveroutePolicyRuleEntry{
+		// allow http from the host shadowed by deny of the whole subnet
+		newTestRuleEntry("high-deny-subnet", "deny", in, datapath.POLICY_TIER3, 110, "10.0.0.0/24", "", 0, "ns/high/normal"),
+		newTestRuleEntry("low-allow-http", "allow", in, datapath.POLICY_TIER3, 100, "10.0.0.1/32", "", 80, "ns/low/normal"),
+		// deny shadowed by allow vice versa
+		newTestRuleEntry("high-allow-all", "allow", out, datapath.POLICY_TIER3, 110, "", "", 0, "ns/high/normal"),
+		newTestRuleEntry("low-deny-host", "deny", out, datapath.POLICY_TIER3, 100, "10.0.0.1", "", 0, "ns/low/normal"),
+		// partially overlapped, different tier or same action should not be reported
+		newTestRuleEntry("higher-deny-http", "deny", in, datapath.POLICY_TIER3, 120, "10.0.0.0/16", "", 80, "ns/higher/normal"),
+		newTestRuleEntry("tier1-allow", "allow", in, datapath.POLICY_TIER1, 100, "10.0.0.1", "", 80, "ns/tier1/normal"),
+		newTestRuleEntry("low-deny-http", "deny", in, datapath.POLICY_TIER3, 100, "10.0.0.2", "", 80, "ns/low/normal"),
+	} {
+		dpManager.Rules[entry.EveroutePolicyRule.RuleID] = entry
+	}
+	getter := NewGetterServer(dpManager, nil, nil)
+
+	conflicts, err := getter.GetRuleConflicts(context.Background(), &emptypb.Empty{})
+	Expect(err).ShouldNot(HaveOccurred())
+
+	var pairs [][2]string
+	for _, conflict := range conflicts.GetRuleConflicts() {
+		pairs = append(pairs, [2]string{conflict.GetShadowed().GetEveroutePolicyRule().GetRuleID(), conflict.GetShadowedBy().GetEveroutePolicyRule().GetRuleID()})
+	}
+	Expect(pairs).Should(Equal([][2]string{
+		{"low-deny-host", "high-allow-all"},
+		{"low-allow-http", "high-deny-subnet"},
+		{"low-allow-http", "higher-deny-http"},
+	}))
+}
+
+func TestGetRulesFilterByVds(t *testing.T) {
+	dpManager := newFakeDpManager()
+	dpManager.Rules["rule1"].RuleFlowMap["vds2"] = &datapath.FlowEntry{
+		Table:    &ofctrl.Table{TableId: datapath.INGRESS_TIER2_TABLE},
+		Priority: 200,
+		FlowID:   0x20000001,
+	}
+	rule2 := newTestRuleEntry("rule2", "deny", datapath.POLICY_DIRECTION_OUT, datapath.POLICY_TIER2, 100, "10.100.100.2", "", 0, "ns1/policy2/normal")
+	rule2.RuleFlowMap["vds2"] = &datapath.FlowEntry{
+		Table:    &ofctrl.Table{TableId: datapath.EGRESS_TIER2_TABLE},
+		Priority: 100,
+		FlowID:   0x20000002,
+	}
+	dpManager.Rules["rule2"] = rule2
+	dpManager.FlowIDToRules[0x10000001] = dpManager.Rules["rule1"]
+	dpManager.FlowIDToRules[0x20000001] = dpManager.Rules["rule1"]
+	dpManager.FlowIDToRules[0x20000002] = rule2
+	getter := NewGetterServer(dpManager, nil, nil)
+
+	ruleFlows := func(entries *v1alpha1.RuleEntries) map[string][]string {
+		ans := make(map[string][]string)
+		for _, entry := range entries.GetRuleEntries() {
+			ruleID := entry.GetEveroutePolicyRule().GetRuleID()
+			ans[ruleID] = sets.StringKeySet(entry.GetRuleFlowMap()).List()
+		}
+		return ans
+	}
+
+	t.Run("get all rules without vds should return flows of all vds", func(t *testing.T) {
+		RegisterTestingT(t)
+		entries, err := getter.GetAllRules(context.Background(), &v1alpha1.RuleQuery{})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(ruleFlows(entries)).Should(Equal(map[string][]string{
+			"rule1": {"vds1", "vds2"},
+			"rule2": {"vds2"},
+		}))
+	})
+
+	t.Run("get all rules should only return flows of the vds", func(t *testing.T) {
+		RegisterTestingT(t)
+		entries, err := getter.GetAllRules(context.Background(), &v1alpha1.RuleQuery{VdsID: "vds1"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(ruleFlows(entries)).Should(Equal(map[string][]string{"rule1": {"vds1"}}))
+
+		entries, err = getter.GetAllRules(context.Background(), &v1alpha1.RuleQuery{VdsID: "vds2"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(ruleFlows(entries)).Should(Equal(map[string][]string{
+			"rule1": {"vds2"},
+			"rule2": {"vds2"},
+		}))
+	})
+
+	t.Run("get rules by name should only return flows of the vds", func(t *testing.T) {
+		RegisterTestingT(t)
+		entries, err := getter.GetRulesByName(context.Background(), &v1alpha1.RuleIDs{RuleIDs: []string{"rule1", "rule2"}, VdsID: "vds1"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(ruleFlows(entries)).Should(Equal(map[string][]string{"rule1": {"vds1"}}))
+	})
+
+	t.Run("get rules by flow should only return flows of the vds", func(t *testing.T) {
+		RegisterTestingT(t)
+		entries, err := getter.GetRulesByFlow(context.Background(), &v1alpha1.FlowIDs{FlowIDs: []uint64{0x10000001, 0x20000002}, VdsID: "vds2"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(ruleFlows(entries)).Should(Equal(map[string][]string{
+			"rule1": {"vds2"},
+			"rule2": {"vds2"},
+		}))
+	})
+
+	t.Run("unknown vds should return none rules", func(t *testing.T) {
+		RegisterTestingT(t)
+		entries, err := getter.GetAllRules(context.Background(), &v1alpha1.RuleQuery{VdsID: "vds3"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(entries.GetRuleEntries()).Should(BeEmpty())
+	})
+}
+
+func TestGetTableLayout(t *testing.T) {
+	RegisterTestingT(t)
+
+	getter := NewGetterServer(newFakeDpManager(), nil, nil)
+	layout, err := getter.GetTableLayout(context.Background(), &emptypb.Empty{})
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(layout.GetTierTables()).Should(ContainElement(&v1alpha1.TierTable{
+		Direction: datapath.POLICY_DIRECTION_IN,
+		Tier:      datapath.POLICY_TIER2,
+		Mode:      "monitor",
+		Table:     datapath.INGRESS_TIER2_MONITOR_TABLE,
+		NextTable: datapath.INGRESS_TIER2_TABLE,
+	}))
+	for _, stage := range layout.GetBridgeStages() {
+		Expect(stage.GetBridge()).ShouldNot(Equal(datapath.NAT_BRIDGE_KEYWORD), "nat bridge stages without proxy enabled")
+	}
+}
+
+func TestGetReferencedLabels(t *testing.T) {
+	RegisterTestingT(t)
+
+	policy1 := &securityv1alpha1.SecurityPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "policy1"},
+		Spec: securityv1alpha1.SecurityPolicySpec{
+			AppliedTo: []securityv1alpha1.ApplyToPeer{{
+				EndpointSelector: &labels.Selector{LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+			}},
+			IngressRules: []securityv1alpha1.Rule{{
+				From: []securityv1alpha1.SecurityPolicyPeer{{
+					EndpointSelector: &labels.Selector{ExtendMatchLabels: map[string][]string{"app": {"db", "cache"}}},
+				}},
+			}},
+		},
+	}
+	policy2 := &securityv1alpha1.SecurityPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "policy2"},
+		Spec: securityv1alpha1.SecurityPolicySpec{
+			AppliedTo: []securityv1alpha1.ApplyToPeer{{
+				EndpointSelector: &labels.Selector{LabelSelector: metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "env", Operator: metav1.LabelSelectorOpIn, Values: []string{"prod"}},
+					{Key: "tier", Operator: metav1.LabelSelectorOpExists},
+				}}},
+			}},
+			EgressRules: []securityv1alpha1.Rule{{
+				To: []securityv1alpha1.SecurityPolicyPeer{
+					{EndpointSelector: &labels.Selector{LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"env": "dev"}}}},
+					{EndpointSelector: &labels.Selector{MatchNothing: true, LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"nothing": "x"}}}},
+				},
+			}},
+		},
+	}
+	reader := fakeclient.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(policy1, policy2).Build()
+	getter := NewGetterServer(newFakeDpManager(), nil, reader)
+
+	referenced, err := getter.GetReferencedLabels(context.Background(), &emptypb.Empty{})
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(referenced.GetLabels()).Should(Equal([]*v1alpha1.ReferencedLabel{
+		{Key: "app", Values: []string{"cache", "db", "web"}},
+		{Key: "env", Values: []string{"dev", "prod"}},
+		{Key: "tier", Values: []string{}, AnyValue: true},
+	}))
+
+	t.Run("should return error without policy cache", func(t *testing.T) {
+		RegisterTestingT(t)
+		_, err := NewGetterServer(newFakeDpManager(), nil, nil).GetReferencedLabels(context.Background(), &emptypb.Empty{})
+		Expect(err).Should(HaveOccurred())
+	})
+}
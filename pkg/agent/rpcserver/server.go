@@ -17,10 +17,17 @@ limitations under the License.
 package rpcserver
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
 	"net"
 	"os"
+	"path/filepath"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"k8s.io/klog"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -30,6 +37,9 @@ import (
 	"github.com/everoute/everoute/pkg/constants"
 )
 
+// gracefulStopTimeout is the max time to wait in-flight rpcs finished when the server stop
+const gracefulStopTimeout = 5 * time.Second
+
 type Server struct {
 	k8sClient client.Client
 	dpManager *datapath.DpManager
@@ -38,15 +48,37 @@ type Server struct {
 
 	enableCNI bool
 
+	// socketAddr is the unix socket path the server listens on
+	socketAddr string
+
+	// tcpConfig enables listening on tcp besides the unix socket, nil means disable
+	tcpConfig *TCPConfig
+
 	stopChan <-chan struct{}
 }
 
-func Initialize(datapathManager *datapath.DpManager, k8sClient client.Client, enableCNI bool, proxyCache *ctrlProxy.Cache) *Server {
+// TCPConfig configs the rpc server listening on tcp for out-of-node tools or sidecar collectors, only
+// collector and getter services are served on tcp. Connections must be TLS, and clients must present
+// certificates signed by CAFile.
+type TCPConfig struct {
+	Addr     string // listen address, e.g. 0.0.0.0:10700
+	CertFile string // server certificate file, required
+	KeyFile  string // server private key file, required
+	CAFile   string // ca file to verify client certificates, required
+
+	// DisableUnixSocket listens only on tcp instead of the default unix socket, it can't be set
+	// when cni enabled, for cni plugin connects to the unix socket.
+	DisableUnixSocket bool
+}
+
+func Initialize(datapathManager *datapath.DpManager, k8sClient client.Client, enableCNI bool, proxyCache *ctrlProxy.Cache, tcpConfig *TCPConfig) *Server {
 	s := &Server{
 		dpManager:  datapathManager,
 		k8sClient:  k8sClient,
 		proxyCache: proxyCache,
 		enableCNI:  enableCNI,
+		socketAddr: constants.RPCSocketAddr,
+		tcpConfig:  tcpConfig,
 	}
 
 	return s
@@ -56,32 +88,6 @@ func (s *Server) Run(stopChan <-chan struct{}) {
 	klog.Info("Starting Everoute RPC Server")
 	s.stopChan = stopChan
 
-	// create path
-	if _, err := os.Stat(constants.EverouteLibPath); os.IsNotExist(err) {
-		if err := os.MkdirAll(constants.EverouteLibPath, os.ModePerm); err != nil {
-			klog.Fatalf("unable to create %s", constants.EverouteLibPath)
-		}
-		if err := os.Chmod(constants.EverouteLibPath, os.ModePerm); err != nil {
-			klog.Fatalf("unable to chmod %s", constants.EverouteLibPath)
-		}
-	}
-
-	// remove the remaining sock file
-	_, err := os.Stat(constants.RPCSocketAddr)
-	if err == nil {
-		err = os.Remove(constants.RPCSocketAddr)
-		if err != nil {
-			klog.Fatalf("remove remaining sock file error, err:%s", err)
-			return
-		}
-	}
-
-	// listen socket
-	listener, err := net.Listen("unix", constants.RPCSocketAddr)
-	if err != nil {
-		klog.Fatalf("Failed to bind on %s: %v", constants.RPCSocketAddr, err)
-	}
-
 	rpcServer := grpc.NewServer()
 	// register collector service
 	collector := NewCollectorServer(s.dpManager, stopChan)
@@ -89,10 +95,20 @@ func (s *Server) Run(stopChan <-chan struct{}) {
 	klog.Infoln("Enable collector rpc server")
 
 	// register cli server
-	getterServer := NewGetterServer(s.dpManager, s.proxyCache)
+	getterServer := NewGetterServer(s.dpManager, s.proxyCache, s.k8sClient)
 	v1alpha1.RegisterGetterServer(rpcServer, getterServer)
 	klog.Infoln("Enable cli tools rpc server")
 
+	// register admin server, it's served only on the unix socket
+	v1alpha1.RegisterAdminServer(rpcServer, NewAdminServer(s.dpManager))
+	klog.Infoln("Enable admin rpc server")
+
+	// register health server, it serves until bridges connected
+	healthServer := newHealthServer()
+	healthpb.RegisterHealthServer(rpcServer, healthServer)
+	go runHealthCheck(healthServer, s.dpManager.IsBridgesConnected, HealthCheckInterval, stopChan)
+	klog.Infoln("Enable health rpc server")
+
 	// register cni server
 	if s.enableCNI {
 		cniServer := NewCNIServer(s.k8sClient, s.dpManager)
@@ -100,13 +116,132 @@ func (s *Server) Run(stopChan <-chan struct{}) {
 		klog.Infoln("Enable CNI rpc server")
 	}
 
-	// start rpc Server
-	go func() {
-		if err = rpcServer.Serve(listener); err != nil {
-			klog.Fatalf("Failed to serve collectorServer connections: %v", err)
+	var servers []*grpc.Server
+	listenUnix := s.tcpConfig == nil || !s.tcpConfig.DisableUnixSocket
+
+	if listenUnix {
+		// create path
+		socketPath := filepath.Dir(s.socketAddr)
+		if _, err := os.Stat(socketPath); os.IsNotExist(err) {
+			if err := os.MkdirAll(socketPath, os.ModePerm); err != nil {
+				klog.Fatalf("unable to create %s", socketPath)
+			}
+			if err := os.Chmod(socketPath, os.ModePerm); err != nil {
+				klog.Fatalf("unable to chmod %s", socketPath)
+			}
 		}
-	}()
+
+		// remove the remaining sock file
+		_, err := os.Stat(s.socketAddr)
+		if err == nil {
+			err = os.Remove(s.socketAddr)
+			if err != nil {
+				klog.Fatalf("remove remaining sock file error, err:%s", err)
+				return
+			}
+		}
+
+		// listen socket
+		listener, err := net.Listen("unix", s.socketAddr)
+		if err != nil {
+			klog.Fatalf("Failed to bind on %s: %v", s.socketAddr, err)
+		}
+
+		// start rpc Server
+		go func() {
+			if err = rpcServer.Serve(listener); err != nil {
+				klog.Fatalf("Failed to serve collectorServer connections: %v", err)
+			}
+		}()
+		servers = append(servers, rpcServer)
+	}
+
+	if s.tcpConfig != nil {
+		tcpServer, err := newTCPServer(s.tcpConfig, collector, getterServer)
+		if err != nil {
+			klog.Fatalf("Failed to create tcp rpc server: %v", err)
+		}
+		healthpb.RegisterHealthServer(tcpServer, healthServer)
+		tcpListener, err := net.Listen("tcp", s.tcpConfig.Addr)
+		if err != nil {
+			klog.Fatalf("Failed to bind on %s: %v", s.tcpConfig.Addr, err)
+		}
+		go func() {
+			if err := tcpServer.Serve(tcpListener); err != nil {
+				klog.Fatalf("Failed to serve tcp connections: %v", err)
+			}
+		}()
+		servers = append(servers, tcpServer)
+		klog.Infof("Enable tcp rpc server on %s", s.tcpConfig.Addr)
+	}
 
 	klog.Info("RPC server is listening ...")
 	<-s.stopChan
+
+	klog.Info("Stopping Everoute RPC Server")
+	healthServer.Shutdown()
+	for _, server := range servers {
+		gracefulStop(server, gracefulStopTimeout)
+	}
+	if listenUnix {
+		if err := os.Remove(s.socketAddr); err != nil && !os.IsNotExist(err) {
+			klog.Errorf("remove sock file %s error, err:%s", s.socketAddr, err)
+		}
+	}
+}
+
+// gracefulStop waits in-flight rpcs and streams finished, and stops the server forcibly after timeout
+func gracefulStop(server *grpc.Server, timeout time.Duration) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		klog.Warningf("RPC server graceful stop timeout after %s, stop it forcibly", timeout)
+		server.Stop()
+	}
+}
+
+// newTCPServer returns the rpc server with TLS and client certificate verification, which serves the
+// collector and getter services
+func newTCPServer(config *TCPConfig, collector *Collector, getter *Getter) (*grpc.Server, error) {
+	creds, err := newTCPServerCreds(config)
+	if err != nil {
+		return nil, err
+	}
+
+	rpcServer := grpc.NewServer(grpc.Creds(creds))
+	v1alpha1.RegisterCollectorServer(rpcServer, collector)
+	v1alpha1.RegisterGetterServer(rpcServer, getter)
+	return rpcServer, nil
+}
+
+// newTCPServerCreds returns TLS credentials of the tcp server, which requires and verifies client certificates
+func newTCPServerCreds(config *TCPConfig) (credentials.TransportCredentials, error) {
+	if config.CertFile == "" || config.KeyFile == "" || config.CAFile == "" {
+		return nil, fmt.Errorf("certFile, keyFile and caFile are required for tcp rpc server")
+	}
+	cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load server certificate: %s", err)
+	}
+	caPEM, err := os.ReadFile(config.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("read ca file: %s", err)
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no valid certificate found in ca file %s", config.CAFile)
+	}
+
+	return credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+		MinVersion:   tls.VersionTLS12,
+	}), nil
 }
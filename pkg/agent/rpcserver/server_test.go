@@ -0,0 +1,197 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rpcserver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/everoute/everoute/pkg/agent/datapath"
+	"github.com/everoute/everoute/pkg/apis/rpc/v1alpha1"
+)
+
+// newTestCert returns certificate signed by the parent, it's self signed if the parent is nil
+func newTestCert(commonName string, isCA bool, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, []byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).ShouldNot(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		IsCA:         isCA,
+
+		BasicConstraintsValid: true,
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	Expect(err).ShouldNot(HaveOccurred())
+	cert, err := x509.ParseCertificate(der)
+	Expect(err).ShouldNot(HaveOccurred())
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	Expect(err).ShouldNot(HaveOccurred())
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	return cert, key, certPEM, keyPEM
+}
+
+func TestTCPServer(t *testing.T) {
+	RegisterTestingT(t)
+
+	dir := t.TempDir()
+	writeFile := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		Expect(os.WriteFile(path, data, 0600)).Should(Succeed())
+		return path
+	}
+	ca, caKey, caPEM, _ := newTestCert("test-ca", true, nil, nil)
+	_, _, serverCertPEM, serverKeyPEM := newTestCert("everoute-agent", false, ca, caKey)
+	_, _, clientCertPEM, clientKeyPEM := newTestCert("everoute-debugger", false, ca, caKey)
+
+	config := &TCPConfig{
+		Addr:     "127.0.0.1:0",
+		CertFile: writeFile("server.crt", serverCertPEM),
+		KeyFile:  writeFile("server.key", serverKeyPEM),
+		CAFile:   writeFile("ca.crt", caPEM),
+	}
+	dpManager := newFakeDpManager()
+	tcpServer, err := newTCPServer(config, NewCollectorServer(dpManager, make(chan struct{})), NewGetterServer(dpManager, nil, nil))
+	Expect(err).ShouldNot(HaveOccurred())
+	listener, err := net.Listen("tcp", config.Addr)
+	Expect(err).ShouldNot(HaveOccurred())
+	go func() { _ = tcpServer.Serve(listener) }()
+	defer tcpServer.Stop()
+
+	caPool := x509.NewCertPool()
+	caPool.AppendCertsFromPEM(caPEM)
+	dial := func(certificates []tls.Certificate) (*grpc.ClientConn, error) {
+		creds := credentials.NewTLS(&tls.Config{Certificates: certificates, RootCAs: caPool, MinVersion: tls.VersionTLS12})
+		return grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(creds))
+	}
+
+	t.Run("client with certificate signed by ca should call getter rpc", func(t *testing.T) {
+		RegisterTestingT(t)
+		clientCert, err := tls.X509KeyPair(clientCertPEM, clientKeyPEM)
+		Expect(err).ShouldNot(HaveOccurred())
+		conn, err := dial([]tls.Certificate{clientCert})
+		Expect(err).ShouldNot(HaveOccurred())
+		defer conn.Close()
+
+		flowDumps, err := v1alpha1.NewGetterClient(conn).DumpFlows(context.Background(), &emptypb.Empty{})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(flowDumps.GetBridgeFlowDumps()).Should(HaveLen(2))
+	})
+
+	t.Run("admin rpc should not be served on tcp", func(t *testing.T) {
+		RegisterTestingT(t)
+		clientCert, err := tls.X509KeyPair(clientCertPEM, clientKeyPEM)
+		Expect(err).ShouldNot(HaveOccurred())
+		conn, err := dial([]tls.Certificate{clientCert})
+		Expect(err).ShouldNot(HaveOccurred())
+		defer conn.Close()
+
+		_, err = v1alpha1.NewAdminClient(conn).SetTableMissAction(context.Background(), &v1alpha1.TableMissAction{VdsID: "vds1", Action: string(datapath.TableMissFailOpen)})
+		Expect(status.Code(err)).Should(Equal(codes.Unimplemented))
+	})
+
+	t.Run("client without certificate should be rejected", func(t *testing.T) {
+		RegisterTestingT(t)
+		conn, err := dial(nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		defer conn.Close()
+
+		_, err = v1alpha1.NewGetterClient(conn).DumpFlows(context.Background(), &emptypb.Empty{})
+		Expect(err).Should(HaveOccurred())
+	})
+}
+
+func TestTCPServerRequireClientAuth(t *testing.T) {
+	RegisterTestingT(t)
+
+	dir := t.TempDir()
+	writeFile := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		Expect(os.WriteFile(path, data, 0600)).Should(Succeed())
+		return path
+	}
+	_, _, certPEM, keyPEM := newTestCert("everoute-agent", false, nil, nil)
+	certFile, keyFile := writeFile("server.crt", certPEM), writeFile("server.key", keyPEM)
+
+	dpManager := newFakeDpManager()
+	for _, config := range []*TCPConfig{
+		{Addr: "127.0.0.1:0"},
+		{Addr: "127.0.0.1:0", CertFile: certFile, KeyFile: keyFile},
+	} {
+		_, err := newTCPServer(config, NewCollectorServer(dpManager, make(chan struct{})), NewGetterServer(dpManager, nil, nil))
+		Expect(err).Should(HaveOccurred())
+	}
+}
+
+func TestServerStop(t *testing.T) {
+	RegisterTestingT(t)
+
+	server := Initialize(newFakeDpManager(), nil, false, nil, nil)
+	server.socketAddr = filepath.Join(t.TempDir(), "rpc.sock")
+	stopChan := make(chan struct{})
+	runDone := make(chan struct{})
+	go func() {
+		server.Run(stopChan)
+		close(runDone)
+	}()
+
+	Eventually(func() error {
+		_, err := os.Stat(server.socketAddr)
+		return err
+	}, 5*time.Second, 50*time.Millisecond).Should(Succeed())
+	conn, err := grpc.Dial("unix://"+server.socketAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	Expect(err).ShouldNot(HaveOccurred())
+	defer conn.Close()
+	_, err = v1alpha1.NewGetterClient(conn).DumpFlows(context.Background(), &emptypb.Empty{})
+	Expect(err).ShouldNot(HaveOccurred())
+
+	close(stopChan)
+	Eventually(runDone, 2*gracefulStopTimeout).Should(BeClosed())
+	_, err = os.Stat(server.socketAddr)
+	Expect(os.IsNotExist(err)).Should(BeTrue())
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2021 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rpcserver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/everoute/everoute/pkg/agent/datapath"
+	"github.com/everoute/everoute/pkg/apis/rpc/v1alpha1"
+)
+
+// testServerStartTimeout is the max time waiting for the test server listening
+const testServerStartTimeout = 5 * time.Second
+
+// testClients are clients connected to the in-process rpc server
+type testClients struct {
+	Collector v1alpha1.CollectorClient
+	Getter    v1alpha1.GetterClient
+	Admin     v1alpha1.AdminClient
+}
+
+// startTestServer runs the Server backed by the dpManager on a temp unix socket, and returns clients
+// connected to it. The clients are closed and the server is stopped when the test finished.
+func startTestServer(t testing.TB, dpManager *datapath.DpManager) *testClients {
+	t.Helper()
+
+	// unix socket path is limited to 108 bytes, don't use t.TempDir() which contains the test name
+	socketDir, err := os.MkdirTemp("", "rpc")
+	if err != nil {
+		t.Fatalf("unable create socket dir: %s", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(socketDir) })
+
+	server := Initialize(dpManager, nil, false, nil, nil)
+	server.socketAddr = filepath.Join(socketDir, "rpc.sock")
+	stopChan := make(chan struct{})
+	runDone := make(chan struct{})
+	go func() {
+		server.Run(stopChan)
+		close(runDone)
+	}()
+	t.Cleanup(func() {
+		close(stopChan)
+		select {
+		case <-runDone:
+		case <-time.After(2 * gracefulStopTimeout):
+			t.Errorf("rpc server not stopped in %s", 2*gracefulStopTimeout)
+		}
+	})
+
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		if _, err = os.Stat(server.socketAddr); err == nil {
+			break
+		}
+		if time.Since(start) > testServerStartTimeout {
+			t.Fatalf("rpc server not listening on %s in %s", server.socketAddr, testServerStartTimeout)
+		}
+	}
+
+	conn, err := grpc.Dial("unix://"+server.socketAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("unable connect to rpc server: %s", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return &testClients{
+		Collector: v1alpha1.NewCollectorClient(conn),
+		Getter:    v1alpha1.NewGetterClient(conn),
+		Admin:     v1alpha1.NewAdminClient(conn),
+	}
+}
+
+func TestRPCGetRules(t *testing.T) {
+	RegisterTestingT(t)
+
+	dpManager := newFakeDpManager()
+	dpManager.FlowIDToRules[0x10000001] = dpManager.Rules["rule1"]
+	clients := startTestServer(t, dpManager)
+	ctx := context.Background()
+
+	t.Run("getter should return all rules of datapath", func(t *testing.T) {
+		RegisterTestingT(t)
+		entries, err := clients.Getter.GetAllRules(ctx, &v1alpha1.RuleQuery{})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(entries.GetRuleEntries()).Should(HaveLen(1))
+		Expect(entries.GetRuleEntries()[0].GetEveroutePolicyRule().GetRuleID()).Should(Equal("rule1"))
+		Expect(entries.GetRuleEntries()[0].GetRuleFlowMap()).Should(HaveKey("vds1"))
+	})
+
+	t.Run("getter should return rules by name", func(t *testing.T) {
+		RegisterTestingT(t)
+		entries, err := clients.Getter.GetRulesByName(ctx, &v1alpha1.RuleIDs{RuleIDs: []string{"rule1", "unknown"}})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(entries.GetRuleEntries()).Should(HaveLen(1))
+		Expect(entries.GetRuleEntries()[0].GetEveroutePolicyRule().GetRuleID()).Should(Equal("rule1"))
+	})
+
+	t.Run("collector should return policies of the rule flow", func(t *testing.T) {
+		RegisterTestingT(t)
+		resp, err := clients.Collector.Policy(ctx, &v1alpha1.PolicyRequest{FlowIDs: []uint64{0x10000001}})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(resp.GetList()).Should(HaveLen(1))
+		Expect(resp.GetList()[0].GetAction()).Should(Equal("allow"))
+		Expect(resp.GetList()[0].GetItems()).Should(HaveLen(1))
+		Expect(resp.GetList()[0].GetItems()[0].GetName()).Should(Equal("policy1"))
+	})
+
+	t.Run("collector should return chain bridges", func(t *testing.T) {
+		RegisterTestingT(t)
+		_, err := clients.Collector.GetChainBridge(ctx, &emptypb.Empty{})
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+}
@@ -372,6 +372,242 @@ func (x *ChainBridgeResp) GetBridge() []string {
 	return nil
 }
 
+type RuleStatsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IntervalSeconds uint32 `protobuf:"varint,1,opt,name=intervalSeconds,proto3" json:"intervalSeconds,omitempty"`
+}
+
+func (x *RuleStatsRequest) Reset() {
+	*x = RuleStatsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_collector_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RuleStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuleStatsRequest) ProtoMessage() {}
+
+func (x *RuleStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_collector_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuleStatsRequest.ProtoReflect.Descriptor instead.
+func (*RuleStatsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_collector_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *RuleStatsRequest) GetIntervalSeconds() uint32 {
+	if x != nil {
+		return x.IntervalSeconds
+	}
+	return 0
+}
+
+type RuleFlowStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FlowID      uint64 `protobuf:"varint,1,opt,name=flowID,proto3" json:"flowID,omitempty"`
+	VdsID       string `protobuf:"bytes,2,opt,name=vdsID,proto3" json:"vdsID,omitempty"`
+	PacketCount uint64 `protobuf:"varint,3,opt,name=packetCount,proto3" json:"packetCount,omitempty"`
+	ByteCount   uint64 `protobuf:"varint,4,opt,name=byteCount,proto3" json:"byteCount,omitempty"`
+}
+
+func (x *RuleFlowStats) Reset() {
+	*x = RuleFlowStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_collector_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RuleFlowStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuleFlowStats) ProtoMessage() {}
+
+func (x *RuleFlowStats) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_collector_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuleFlowStats.ProtoReflect.Descriptor instead.
+func (*RuleFlowStats) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_collector_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RuleFlowStats) GetFlowID() uint64 {
+	if x != nil {
+		return x.FlowID
+	}
+	return 0
+}
+
+func (x *RuleFlowStats) GetVdsID() string {
+	if x != nil {
+		return x.VdsID
+	}
+	return ""
+}
+
+func (x *RuleFlowStats) GetPacketCount() uint64 {
+	if x != nil {
+		return x.PacketCount
+	}
+	return 0
+}
+
+func (x *RuleFlowStats) GetByteCount() uint64 {
+	if x != nil {
+		return x.ByteCount
+	}
+	return 0
+}
+
+type RuleStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RuleID      string           `protobuf:"bytes,1,opt,name=ruleID,proto3" json:"ruleID,omitempty"`
+	PacketCount uint64           `protobuf:"varint,2,opt,name=packetCount,proto3" json:"packetCount,omitempty"`
+	ByteCount   uint64           `protobuf:"varint,3,opt,name=byteCount,proto3" json:"byteCount,omitempty"`
+	Flows       []*RuleFlowStats `protobuf:"bytes,4,rep,name=flows,proto3" json:"flows,omitempty"`
+}
+
+func (x *RuleStats) Reset() {
+	*x = RuleStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_collector_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RuleStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuleStats) ProtoMessage() {}
+
+func (x *RuleStats) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_collector_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuleStats.ProtoReflect.Descriptor instead.
+func (*RuleStats) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_collector_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RuleStats) GetRuleID() string {
+	if x != nil {
+		return x.RuleID
+	}
+	return ""
+}
+
+func (x *RuleStats) GetPacketCount() uint64 {
+	if x != nil {
+		return x.PacketCount
+	}
+	return 0
+}
+
+func (x *RuleStats) GetByteCount() uint64 {
+	if x != nil {
+		return x.ByteCount
+	}
+	return 0
+}
+
+func (x *RuleStats) GetFlows() []*RuleFlowStats {
+	if x != nil {
+		return x.Flows
+	}
+	return nil
+}
+
+type RuleStatsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Stats []*RuleStats `protobuf:"bytes,1,rep,name=stats,proto3" json:"stats,omitempty"`
+}
+
+func (x *RuleStatsResponse) Reset() {
+	*x = RuleStatsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_collector_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RuleStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuleStatsResponse) ProtoMessage() {}
+
+func (x *RuleStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_collector_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuleStatsResponse.ProtoReflect.Descriptor instead.
+func (*RuleStatsResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_collector_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *RuleStatsResponse) GetStats() []*RuleStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
 var File_pkg_apis_rpc_v1alpha1_collector_proto protoreflect.FileDescriptor
 
 var file_pkg_apis_rpc_v1alpha1_collector_proto_rawDesc = []byte{
@@ -413,29 +649,65 @@ var file_pkg_apis_rpc_v1alpha1_collector_proto_rawDesc = []byte{
 	0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79,
 	0x54, 0x79, 0x70, 0x65, 0x22, 0x29, 0x0a, 0x0f, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x42, 0x72, 0x69,
 	0x64, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x72, 0x69, 0x64, 0x67,
-	0x65, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x62, 0x72, 0x69, 0x64, 0x67, 0x65, 0x32,
-	0xb5, 0x02, 0x0a, 0x09, 0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x57, 0x0a,
-	0x09, 0x41, 0x72, 0x70, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f,
-	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70,
-	0x74, 0x79, 0x1a, 0x2e, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f,
-	0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31,
-	0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x41, 0x72, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x22, 0x00, 0x30, 0x01, 0x12, 0x6f, 0x0a, 0x06, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79,
-	0x12, 0x30, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70,
-	0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c,
-	0x70, 0x68, 0x61, 0x31, 0x2e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x1a, 0x31, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f,
-	0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31,
-	0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x5e, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x43, 0x68,
-	0x61, 0x69, 0x6e, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
-	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74,
-	0x79, 0x1a, 0x32, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e,
+	0x65, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x62, 0x72, 0x69, 0x64, 0x67, 0x65, 0x22,
+	0x3c, 0x0a, 0x10, 0x52, 0x75, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x28, 0x0a, 0x0f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x53,
+	0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0f, 0x69, 0x6e,
+	0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x22, 0x7d, 0x0a,
+	0x0d, 0x52, 0x75, 0x6c, 0x65, 0x46, 0x6c, 0x6f, 0x77, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x16,
+	0x0a, 0x06, 0x66, 0x6c, 0x6f, 0x77, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06,
+	0x66, 0x6c, 0x6f, 0x77, 0x49, 0x44, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x64, 0x73, 0x49, 0x44, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x64, 0x73, 0x49, 0x44, 0x12, 0x20, 0x0a, 0x0b,
+	0x70, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x0b, 0x70, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1c,
+	0x0a, 0x09, 0x62, 0x79, 0x74, 0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x09, 0x62, 0x79, 0x74, 0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0xab, 0x01, 0x0a,
+	0x09, 0x52, 0x75, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x75,
+	0x6c, 0x65, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x75, 0x6c, 0x65,
+	0x49, 0x44, 0x12, 0x20, 0x0a, 0x0b, 0x70, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x43, 0x6f, 0x75, 0x6e,
+	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x70, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x43,
+	0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x62, 0x79, 0x74, 0x65, 0x43, 0x6f, 0x75, 0x6e,
+	0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x62, 0x79, 0x74, 0x65, 0x43, 0x6f, 0x75,
+	0x6e, 0x74, 0x12, 0x46, 0x0a, 0x05, 0x66, 0x6c, 0x6f, 0x77, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x30, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e,
 	0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61,
-	0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x42, 0x72, 0x69, 0x64, 0x67,
-	0x65, 0x52, 0x65, 0x73, 0x70, 0x22, 0x00, 0x42, 0x17, 0x5a, 0x15, 0x70, 0x6b, 0x67, 0x2f, 0x61,
-	0x70, 0x69, 0x73, 0x2f, 0x72, 0x70, 0x63, 0x2f, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31,
-	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x46, 0x6c, 0x6f, 0x77, 0x53, 0x74,
+	0x61, 0x74, 0x73, 0x52, 0x05, 0x66, 0x6c, 0x6f, 0x77, 0x73, 0x22, 0x57, 0x0a, 0x11, 0x52, 0x75,
+	0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x42, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2c,
+	0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67,
+	0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68,
+	0x61, 0x31, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x05, 0x73, 0x74,
+	0x61, 0x74, 0x73, 0x32, 0xb8, 0x03, 0x0a, 0x09, 0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x6f,
+	0x72, 0x12, 0x57, 0x0a, 0x09, 0x41, 0x72, 0x70, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x16,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x2e, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74,
+	0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70,
+	0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x41, 0x72, 0x70, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x30, 0x01, 0x12, 0x6f, 0x0a, 0x06, 0x50, 0x6f,
+	0x6c, 0x69, 0x63, 0x79, 0x12, 0x30, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f,
+	0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e,
+	0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x31, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74,
+	0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70,
+	0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x50, 0x6f, 0x6c, 0x69, 0x63,
+	0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x5e, 0x0a, 0x0e, 0x47,
+	0x65, 0x74, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x12, 0x16, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x32, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65,
+	0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63,
+	0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x42,
+	0x72, 0x69, 0x64, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x22, 0x00, 0x12, 0x80, 0x01, 0x0a, 0x0f,
+	0x52, 0x75, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12,
+	0x33, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b,
+	0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70,
+	0x68, 0x61, 0x31, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x34, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f,
+	0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e,
+	0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x53, 0x74, 0x61,
+	0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x30, 0x01, 0x42, 0x17,
+	0x5a, 0x15, 0x70, 0x6b, 0x67, 0x2f, 0x61, 0x70, 0x69, 0x73, 0x2f, 0x72, 0x70, 0x63, 0x2f, 0x76,
+	0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -450,30 +722,38 @@ func file_pkg_apis_rpc_v1alpha1_collector_proto_rawDescGZIP() []byte {
 	return file_pkg_apis_rpc_v1alpha1_collector_proto_rawDescData
 }
 
-var file_pkg_apis_rpc_v1alpha1_collector_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_pkg_apis_rpc_v1alpha1_collector_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
 var file_pkg_apis_rpc_v1alpha1_collector_proto_goTypes = []interface{}{
-	(*ArpResponse)(nil),     // 0: everoute_io.pkg.apis.rpc.v1alpha1.ArpResponse
-	(*PolicyRequest)(nil),   // 1: everoute_io.pkg.apis.rpc.v1alpha1.PolicyRequest
-	(*PolicyResponse)(nil),  // 2: everoute_io.pkg.apis.rpc.v1alpha1.PolicyResponse
-	(*PolicyList)(nil),      // 3: everoute_io.pkg.apis.rpc.v1alpha1.PolicyList
-	(*PolicyItem)(nil),      // 4: everoute_io.pkg.apis.rpc.v1alpha1.PolicyItem
-	(*ChainBridgeResp)(nil), // 5: everoute_io.pkg.apis.rpc.v1alpha1.ChainBridgeResp
-	(*emptypb.Empty)(nil),   // 6: google.protobuf.Empty
+	(*ArpResponse)(nil),       // 0: everoute_io.pkg.apis.rpc.v1alpha1.ArpResponse
+	(*PolicyRequest)(nil),     // 1: everoute_io.pkg.apis.rpc.v1alpha1.PolicyRequest
+	(*PolicyResponse)(nil),    // 2: everoute_io.pkg.apis.rpc.v1alpha1.PolicyResponse
+	(*PolicyList)(nil),        // 3: everoute_io.pkg.apis.rpc.v1alpha1.PolicyList
+	(*PolicyItem)(nil),        // 4: everoute_io.pkg.apis.rpc.v1alpha1.PolicyItem
+	(*ChainBridgeResp)(nil),   // 5: everoute_io.pkg.apis.rpc.v1alpha1.ChainBridgeResp
+	(*RuleStatsRequest)(nil),  // 6: everoute_io.pkg.apis.rpc.v1alpha1.RuleStatsRequest
+	(*RuleFlowStats)(nil),     // 7: everoute_io.pkg.apis.rpc.v1alpha1.RuleFlowStats
+	(*RuleStats)(nil),         // 8: everoute_io.pkg.apis.rpc.v1alpha1.RuleStats
+	(*RuleStatsResponse)(nil), // 9: everoute_io.pkg.apis.rpc.v1alpha1.RuleStatsResponse
+	(*emptypb.Empty)(nil),     // 10: google.protobuf.Empty
 }
 var file_pkg_apis_rpc_v1alpha1_collector_proto_depIdxs = []int32{
-	3, // 0: everoute_io.pkg.apis.rpc.v1alpha1.PolicyResponse.list:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.PolicyList
-	4, // 1: everoute_io.pkg.apis.rpc.v1alpha1.PolicyList.items:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.PolicyItem
-	6, // 2: everoute_io.pkg.apis.rpc.v1alpha1.Collector.ArpStream:input_type -> google.protobuf.Empty
-	1, // 3: everoute_io.pkg.apis.rpc.v1alpha1.Collector.Policy:input_type -> everoute_io.pkg.apis.rpc.v1alpha1.PolicyRequest
-	6, // 4: everoute_io.pkg.apis.rpc.v1alpha1.Collector.GetChainBridge:input_type -> google.protobuf.Empty
-	0, // 5: everoute_io.pkg.apis.rpc.v1alpha1.Collector.ArpStream:output_type -> everoute_io.pkg.apis.rpc.v1alpha1.ArpResponse
-	2, // 6: everoute_io.pkg.apis.rpc.v1alpha1.Collector.Policy:output_type -> everoute_io.pkg.apis.rpc.v1alpha1.PolicyResponse
-	5, // 7: everoute_io.pkg.apis.rpc.v1alpha1.Collector.GetChainBridge:output_type -> everoute_io.pkg.apis.rpc.v1alpha1.ChainBridgeResp
-	5, // [5:8] is the sub-list for method output_type
-	2, // [2:5] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	3,  // 0: everoute_io.pkg.apis.rpc.v1alpha1.PolicyResponse.list:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.PolicyList
+	4,  // 1: everoute_io.pkg.apis.rpc.v1alpha1.PolicyList.items:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.PolicyItem
+	7,  // 2: everoute_io.pkg.apis.rpc.v1alpha1.RuleStats.flows:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.RuleFlowStats
+	8,  // 3: everoute_io.pkg.apis.rpc.v1alpha1.RuleStatsResponse.stats:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.RuleStats
+	10, // 4: everoute_io.pkg.apis.rpc.v1alpha1.Collector.ArpStream:input_type -> google.protobuf.Empty
+	1,  // 5: everoute_io.pkg.apis.rpc.v1alpha1.Collector.Policy:input_type -> everoute_io.pkg.apis.rpc.v1alpha1.PolicyRequest
+	10, // 6: everoute_io.pkg.apis.rpc.v1alpha1.Collector.GetChainBridge:input_type -> google.protobuf.Empty
+	6,  // 7: everoute_io.pkg.apis.rpc.v1alpha1.Collector.RuleStatsStream:input_type -> everoute_io.pkg.apis.rpc.v1alpha1.RuleStatsRequest
+	0,  // 8: everoute_io.pkg.apis.rpc.v1alpha1.Collector.ArpStream:output_type -> everoute_io.pkg.apis.rpc.v1alpha1.ArpResponse
+	2,  // 9: everoute_io.pkg.apis.rpc.v1alpha1.Collector.Policy:output_type -> everoute_io.pkg.apis.rpc.v1alpha1.PolicyResponse
+	5,  // 10: everoute_io.pkg.apis.rpc.v1alpha1.Collector.GetChainBridge:output_type -> everoute_io.pkg.apis.rpc.v1alpha1.ChainBridgeResp
+	9,  // 11: everoute_io.pkg.apis.rpc.v1alpha1.Collector.RuleStatsStream:output_type -> everoute_io.pkg.apis.rpc.v1alpha1.RuleStatsResponse
+	8,  // [8:12] is the sub-list for method output_type
+	4,  // [4:8] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_pkg_apis_rpc_v1alpha1_collector_proto_init() }
@@ -554,6 +834,54 @@ func file_pkg_apis_rpc_v1alpha1_collector_proto_init() {
 				return nil
 			}
 		}
+		file_pkg_apis_rpc_v1alpha1_collector_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RuleStatsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_collector_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RuleFlowStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_collector_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RuleStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_collector_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RuleStatsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -561,7 +889,7 @@ func file_pkg_apis_rpc_v1alpha1_collector_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_pkg_apis_rpc_v1alpha1_collector_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   6,
+			NumMessages:   10,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -590,6 +918,7 @@ type CollectorClient interface {
 	ArpStream(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (Collector_ArpStreamClient, error)
 	Policy(ctx context.Context, in *PolicyRequest, opts ...grpc.CallOption) (*PolicyResponse, error)
 	GetChainBridge(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ChainBridgeResp, error)
+	RuleStatsStream(ctx context.Context, in *RuleStatsRequest, opts ...grpc.CallOption) (Collector_RuleStatsStreamClient, error)
 }
 
 type collectorClient struct {
@@ -650,11 +979,44 @@ func (c *collectorClient) GetChainBridge(ctx context.Context, in *emptypb.Empty,
 	return out, nil
 }
 
+func (c *collectorClient) RuleStatsStream(ctx context.Context, in *RuleStatsRequest, opts ...grpc.CallOption) (Collector_RuleStatsStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Collector_serviceDesc.Streams[1], "/everoute_io.pkg.apis.rpc.v1alpha1.Collector/RuleStatsStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &collectorRuleStatsStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Collector_RuleStatsStreamClient interface {
+	Recv() (*RuleStatsResponse, error)
+	grpc.ClientStream
+}
+
+type collectorRuleStatsStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *collectorRuleStatsStreamClient) Recv() (*RuleStatsResponse, error) {
+	m := new(RuleStatsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // CollectorServer is the server API for Collector service.
 type CollectorServer interface {
 	ArpStream(*emptypb.Empty, Collector_ArpStreamServer) error
 	Policy(context.Context, *PolicyRequest) (*PolicyResponse, error)
 	GetChainBridge(context.Context, *emptypb.Empty) (*ChainBridgeResp, error)
+	RuleStatsStream(*RuleStatsRequest, Collector_RuleStatsStreamServer) error
 }
 
 // UnimplementedCollectorServer can be embedded to have forward compatible implementations.
@@ -670,6 +1032,9 @@ func (*UnimplementedCollectorServer) Policy(context.Context, *PolicyRequest) (*P
 func (*UnimplementedCollectorServer) GetChainBridge(context.Context, *emptypb.Empty) (*ChainBridgeResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetChainBridge not implemented")
 }
+func (*UnimplementedCollectorServer) RuleStatsStream(*RuleStatsRequest, Collector_RuleStatsStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method RuleStatsStream not implemented")
+}
 
 func RegisterCollectorServer(s *grpc.Server, srv CollectorServer) {
 	s.RegisterService(&_Collector_serviceDesc, srv)
@@ -732,6 +1097,27 @@ func _Collector_GetChainBridge_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Collector_RuleStatsStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RuleStatsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CollectorServer).RuleStatsStream(m, &collectorRuleStatsStreamServer{stream})
+}
+
+type Collector_RuleStatsStreamServer interface {
+	Send(*RuleStatsResponse) error
+	grpc.ServerStream
+}
+
+type collectorRuleStatsStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *collectorRuleStatsStreamServer) Send(m *RuleStatsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _Collector_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "everoute_io.pkg.apis.rpc.v1alpha1.Collector",
 	HandlerType: (*CollectorServer)(nil),
@@ -751,6 +1137,11 @@ var _Collector_serviceDesc = grpc.ServiceDesc{
 			Handler:       _Collector_ArpStream_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "RuleStatsStream",
+			Handler:       _Collector_RuleStatsStream_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "pkg/apis/rpc/v1alpha1/collector.proto",
 }
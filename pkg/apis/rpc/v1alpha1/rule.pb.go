@@ -152,6 +152,7 @@ type FlowEntry struct {
 
 	Priority uint32 `protobuf:"varint,1,opt,name=Priority,proto3" json:"Priority,omitempty"`
 	FlowID   uint64 `protobuf:"varint,2,opt,name=FlowID,proto3" json:"FlowID,omitempty"`
+	TableID  uint32 `protobuf:"varint,3,opt,name=TableID,proto3" json:"TableID,omitempty"`
 }
 
 func (x *FlowEntry) Reset() {
@@ -200,6 +201,13 @@ func (x *FlowEntry) GetFlowID() uint64 {
 	return 0
 }
 
+func (x *FlowEntry) GetTableID() uint32 {
+	if x != nil {
+		return x.TableID
+	}
+	return 0
+}
+
 type PolicyRuleReference struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -397,18 +405,66 @@ func (x *RuleEntries) GetRuleEntries() []*RuleEntry {
 	return nil
 }
 
+type RuleQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	VdsID string `protobuf:"bytes,1,opt,name=VdsID,proto3" json:"VdsID,omitempty"`
+}
+
+func (x *RuleQuery) Reset() {
+	*x = RuleQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RuleQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuleQuery) ProtoMessage() {}
+
+func (x *RuleQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuleQuery.ProtoReflect.Descriptor instead.
+func (*RuleQuery) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *RuleQuery) GetVdsID() string {
+	if x != nil {
+		return x.VdsID
+	}
+	return ""
+}
+
 type RuleIDs struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	RuleIDs []string `protobuf:"bytes,1,rep,name=RuleIDs,proto3" json:"RuleIDs,omitempty"`
+	VdsID   string   `protobuf:"bytes,2,opt,name=VdsID,proto3" json:"VdsID,omitempty"`
 }
 
 func (x *RuleIDs) Reset() {
 	*x = RuleIDs{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[5]
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -421,7 +477,7 @@ func (x *RuleIDs) String() string {
 func (*RuleIDs) ProtoMessage() {}
 
 func (x *RuleIDs) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[5]
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -434,7 +490,7 @@ func (x *RuleIDs) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RuleIDs.ProtoReflect.Descriptor instead.
 func (*RuleIDs) Descriptor() ([]byte, []int) {
-	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{5}
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *RuleIDs) GetRuleIDs() []string {
@@ -444,18 +500,26 @@ func (x *RuleIDs) GetRuleIDs() []string {
 	return nil
 }
 
+func (x *RuleIDs) GetVdsID() string {
+	if x != nil {
+		return x.VdsID
+	}
+	return ""
+}
+
 type FlowIDs struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	FlowIDs []uint64 `protobuf:"varint,1,rep,packed,name=FlowIDs,proto3" json:"FlowIDs,omitempty"`
+	VdsID   string   `protobuf:"bytes,2,opt,name=VdsID,proto3" json:"VdsID,omitempty"`
 }
 
 func (x *FlowIDs) Reset() {
 	*x = FlowIDs{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[6]
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -468,7 +532,7 @@ func (x *FlowIDs) String() string {
 func (*FlowIDs) ProtoMessage() {}
 
 func (x *FlowIDs) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[6]
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -481,7 +545,7 @@ func (x *FlowIDs) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FlowIDs.ProtoReflect.Descriptor instead.
 func (*FlowIDs) Descriptor() ([]byte, []int) {
-	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{6}
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *FlowIDs) GetFlowIDs() []uint64 {
@@ -491,6 +555,13 @@ func (x *FlowIDs) GetFlowIDs() []uint64 {
 	return nil
 }
 
+func (x *FlowIDs) GetVdsID() string {
+	if x != nil {
+		return x.VdsID
+	}
+	return ""
+}
+
 type SvcID struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -502,7 +573,7 @@ type SvcID struct {
 func (x *SvcID) Reset() {
 	*x = SvcID{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[7]
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -515,7 +586,7 @@ func (x *SvcID) String() string {
 func (*SvcID) ProtoMessage() {}
 
 func (x *SvcID) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[7]
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -528,7 +599,7 @@ func (x *SvcID) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SvcID.ProtoReflect.Descriptor instead.
 func (*SvcID) Descriptor() ([]byte, []int) {
-	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{7}
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *SvcID) GetID() string {
@@ -551,7 +622,7 @@ type SvcPort struct {
 func (x *SvcPort) Reset() {
 	*x = SvcPort{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[8]
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -564,7 +635,7 @@ func (x *SvcPort) String() string {
 func (*SvcPort) ProtoMessage() {}
 
 func (x *SvcPort) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[8]
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -577,7 +648,7 @@ func (x *SvcPort) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SvcPort.ProtoReflect.Descriptor instead.
 func (*SvcPort) Descriptor() ([]byte, []int) {
-	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{8}
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *SvcPort) GetName() string {
@@ -615,7 +686,7 @@ type Backend struct {
 func (x *Backend) Reset() {
 	*x = Backend{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[9]
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -628,7 +699,7 @@ func (x *Backend) String() string {
 func (*Backend) ProtoMessage() {}
 
 func (x *Backend) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[9]
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -641,7 +712,7 @@ func (x *Backend) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Backend.ProtoReflect.Descriptor instead.
 func (*Backend) Descriptor() ([]byte, []int) {
-	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{9}
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *Backend) GetIP() string {
@@ -691,7 +762,7 @@ type SvcCache struct {
 func (x *SvcCache) Reset() {
 	*x = SvcCache{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[10]
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -704,7 +775,7 @@ func (x *SvcCache) String() string {
 func (*SvcCache) ProtoMessage() {}
 
 func (x *SvcCache) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[10]
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -717,7 +788,7 @@ func (x *SvcCache) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SvcCache.ProtoReflect.Descriptor instead.
 func (*SvcCache) Descriptor() ([]byte, []int) {
-	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{10}
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *SvcCache) GetSvcID() string {
@@ -797,7 +868,7 @@ type SvcFlowEntry struct {
 func (x *SvcFlowEntry) Reset() {
 	*x = SvcFlowEntry{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[11]
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -810,7 +881,7 @@ func (x *SvcFlowEntry) String() string {
 func (*SvcFlowEntry) ProtoMessage() {}
 
 func (x *SvcFlowEntry) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[11]
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -823,7 +894,7 @@ func (x *SvcFlowEntry) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SvcFlowEntry.ProtoReflect.Descriptor instead.
 func (*SvcFlowEntry) Descriptor() ([]byte, []int) {
-	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{11}
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *SvcFlowEntry) GetIP() string {
@@ -867,7 +938,7 @@ type SvcDnatFlowEntry struct {
 func (x *SvcDnatFlowEntry) Reset() {
 	*x = SvcDnatFlowEntry{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[12]
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -880,7 +951,7 @@ func (x *SvcDnatFlowEntry) String() string {
 func (*SvcDnatFlowEntry) ProtoMessage() {}
 
 func (x *SvcDnatFlowEntry) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[12]
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -893,7 +964,7 @@ func (x *SvcDnatFlowEntry) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SvcDnatFlowEntry.ProtoReflect.Descriptor instead.
 func (*SvcDnatFlowEntry) Descriptor() ([]byte, []int) {
-	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{12}
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *SvcDnatFlowEntry) GetBackend() *Backend {
@@ -930,7 +1001,7 @@ type SvcFlow struct {
 func (x *SvcFlow) Reset() {
 	*x = SvcFlow{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[13]
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -943,7 +1014,7 @@ func (x *SvcFlow) String() string {
 func (*SvcFlow) ProtoMessage() {}
 
 func (x *SvcFlow) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[13]
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -956,7 +1027,7 @@ func (x *SvcFlow) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SvcFlow.ProtoReflect.Descriptor instead.
 func (*SvcFlow) Descriptor() ([]byte, []int) {
-	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{13}
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *SvcFlow) GetLBFlows() []*SvcFlowEntry {
@@ -994,7 +1065,7 @@ type SvcGroup struct {
 func (x *SvcGroup) Reset() {
 	*x = SvcGroup{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[14]
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1007,7 +1078,7 @@ func (x *SvcGroup) String() string {
 func (*SvcGroup) ProtoMessage() {}
 
 func (x *SvcGroup) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[14]
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1020,7 +1091,7 @@ func (x *SvcGroup) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SvcGroup.ProtoReflect.Descriptor instead.
 func (*SvcGroup) Descriptor() ([]byte, []int) {
-	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{14}
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *SvcGroup) GetPortName() string {
@@ -1064,7 +1135,7 @@ type SvcInfo struct {
 func (x *SvcInfo) Reset() {
 	*x = SvcInfo{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[15]
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1077,7 +1148,7 @@ func (x *SvcInfo) String() string {
 func (*SvcInfo) ProtoMessage() {}
 
 func (x *SvcInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[15]
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1090,7 +1161,7 @@ func (x *SvcInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SvcInfo.ProtoReflect.Descriptor instead.
 func (*SvcInfo) Descriptor() ([]byte, []int) {
-	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{15}
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *SvcInfo) GetSvcCache() *SvcCache {
@@ -1114,290 +1185,2451 @@ func (x *SvcInfo) GetSvcGroup() []*SvcGroup {
 	return nil
 }
 
-var File_pkg_apis_rpc_v1alpha1_rule_proto protoreflect.FileDescriptor
+type FlowDumpEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 
-var file_pkg_apis_rpc_v1alpha1_rule_proto_rawDesc = []byte{
-	0x0a, 0x20, 0x70, 0x6b, 0x67, 0x2f, 0x61, 0x70, 0x69, 0x73, 0x2f, 0x72, 0x70, 0x63, 0x2f, 0x76,
-	0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2f, 0x72, 0x75, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x12, 0x21, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e,
-	0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61,
-	0x6c, 0x70, 0x68, 0x61, 0x31, 0x1a, 0x1b, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x22, 0xac, 0x02, 0x0a, 0x0a, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x75, 0x6c,
-	0x65, 0x12, 0x16, 0x0a, 0x06, 0x52, 0x75, 0x6c, 0x65, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x06, 0x52, 0x75, 0x6c, 0x65, 0x49, 0x44, 0x12, 0x1a, 0x0a, 0x08, 0x50, 0x72, 0x69,
-	0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x50, 0x72, 0x69,
-	0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x53, 0x72, 0x63, 0x49, 0x50, 0x41, 0x64,
-	0x64, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x53, 0x72, 0x63, 0x49, 0x50, 0x41,
-	0x64, 0x64, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x44, 0x73, 0x74, 0x49, 0x50, 0x41, 0x64, 0x64, 0x72,
-	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x44, 0x73, 0x74, 0x49, 0x50, 0x41, 0x64, 0x64,
-	0x72, 0x12, 0x1e, 0x0a, 0x0a, 0x49, 0x50, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x18,
-	0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x49, 0x50, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f,
-	0x6c, 0x12, 0x18, 0x0a, 0x07, 0x53, 0x72, 0x63, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x06, 0x20, 0x01,
-	0x28, 0x0d, 0x52, 0x07, 0x53, 0x72, 0x63, 0x50, 0x6f, 0x72, 0x74, 0x12, 0x20, 0x0a, 0x0b, 0x53,
-	0x72, 0x63, 0x50, 0x6f, 0x72, 0x74, 0x4d, 0x61, 0x73, 0x6b, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d,
-	0x52, 0x0b, 0x53, 0x72, 0x63, 0x50, 0x6f, 0x72, 0x74, 0x4d, 0x61, 0x73, 0x6b, 0x12, 0x18, 0x0a,
-	0x07, 0x44, 0x73, 0x74, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07,
-	0x44, 0x73, 0x74, 0x50, 0x6f, 0x72, 0x74, 0x12, 0x20, 0x0a, 0x0b, 0x44, 0x73, 0x74, 0x50, 0x6f,
-	0x72, 0x74, 0x4d, 0x61, 0x73, 0x6b, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x44, 0x73,
-	0x74, 0x50, 0x6f, 0x72, 0x74, 0x4d, 0x61, 0x73, 0x6b, 0x12, 0x16, 0x0a, 0x06, 0x41, 0x63, 0x74,
-	0x69, 0x6f, 0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x41, 0x63, 0x74, 0x69, 0x6f,
-	0x6e, 0x22, 0x3f, 0x0a, 0x09, 0x46, 0x6c, 0x6f, 0x77, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x1a,
-	0x0a, 0x08, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d,
-	0x52, 0x08, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x46, 0x6c,
-	0x6f, 0x77, 0x49, 0x44, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x46, 0x6c, 0x6f, 0x77,
-	0x49, 0x44, 0x22, 0x5b, 0x0a, 0x13, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x75, 0x6c, 0x65,
-	0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x4e, 0x61, 0x6d,
-	0x65, 0x53, 0x70, 0x61, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x4e, 0x61,
-	0x6d, 0x65, 0x53, 0x70, 0x61, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x54,
-	0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x54, 0x79, 0x70, 0x65, 0x22,
-	0xe9, 0x03, 0x0a, 0x09, 0x52, 0x75, 0x6c, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x5d, 0x0a,
-	0x12, 0x45, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52,
-	0x75, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2d, 0x2e, 0x65, 0x76, 0x65, 0x72,
-	0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73,
-	0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x50, 0x6f,
-	0x6c, 0x69, 0x63, 0x79, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x12, 0x45, 0x76, 0x65, 0x72, 0x6f, 0x75,
-	0x74, 0x65, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x75, 0x6c, 0x65, 0x12, 0x1c, 0x0a, 0x09,
-	0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52,
-	0x09, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x54, 0x69,
-	0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x54, 0x69, 0x65, 0x72, 0x12, 0x12,
-	0x0a, 0x04, 0x4d, 0x6f, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x4d, 0x6f,
-	0x64, 0x65, 0x12, 0x5f, 0x0a, 0x0b, 0x52, 0x75, 0x6c, 0x65, 0x46, 0x6c, 0x6f, 0x77, 0x4d, 0x61,
-	0x70, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x3d, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75,
-	0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72,
-	0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x52, 0x75, 0x6c, 0x65,
-	0x45, 0x6e, 0x74, 0x72, 0x79, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x46, 0x6c, 0x6f, 0x77, 0x4d, 0x61,
-	0x70, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0b, 0x52, 0x75, 0x6c, 0x65, 0x46, 0x6c, 0x6f, 0x77,
-	0x4d, 0x61, 0x70, 0x12, 0x68, 0x0a, 0x13, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x75, 0x6c,
-	0x65, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b,
-	0x32, 0x36, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70,
-	0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c,
-	0x70, 0x68, 0x61, 0x31, 0x2e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x75, 0x6c, 0x65, 0x52,
-	0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x52, 0x13, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79,
-	0x52, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x1a, 0x6c, 0x0a,
-	0x10, 0x52, 0x75, 0x6c, 0x65, 0x46, 0x6c, 0x6f, 0x77, 0x4d, 0x61, 0x70, 0x45, 0x6e, 0x74, 0x72,
-	0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
-	0x6b, 0x65, 0x79, 0x12, 0x42, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x2c, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f,
-	0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31,
-	0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x46, 0x6c, 0x6f, 0x77, 0x45, 0x6e, 0x74, 0x72, 0x79,
-	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x5d, 0x0a, 0x0b, 0x52,
-	0x75, 0x6c, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x12, 0x4e, 0x0a, 0x0b, 0x52, 0x75,
-	0x6c, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
-	0x2c, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b,
-	0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70,
-	0x68, 0x61, 0x31, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0b, 0x52,
-	0x75, 0x6c, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x22, 0x23, 0x0a, 0x07, 0x52, 0x75,
-	0x6c, 0x65, 0x49, 0x44, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x52, 0x75, 0x6c, 0x65, 0x49, 0x44, 0x73,
-	0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x52, 0x75, 0x6c, 0x65, 0x49, 0x44, 0x73, 0x22,
-	0x23, 0x0a, 0x07, 0x46, 0x6c, 0x6f, 0x77, 0x49, 0x44, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x46, 0x6c,
-	0x6f, 0x77, 0x49, 0x44, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x04, 0x52, 0x07, 0x46, 0x6c, 0x6f,
-	0x77, 0x49, 0x44, 0x73, 0x22, 0x17, 0x0a, 0x05, 0x53, 0x76, 0x63, 0x49, 0x44, 0x12, 0x0e, 0x0a,
-	0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x49, 0x44, 0x22, 0x4d, 0x0a,
-	0x07, 0x53, 0x76, 0x63, 0x50, 0x6f, 0x72, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x61, 0x6d, 0x65,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04,
-	0x50, 0x6f, 0x72, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x50, 0x6f, 0x72, 0x74,
-	0x12, 0x1a, 0x0a, 0x08, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x18, 0x03, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x08, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x22, 0x5d, 0x0a, 0x07,
-	0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x50, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x02, 0x49, 0x50, 0x12, 0x1a, 0x0a, 0x08, 0x50, 0x72, 0x6f, 0x74, 0x6f,
-	0x63, 0x6f, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x50, 0x72, 0x6f, 0x74, 0x6f,
-	0x63, 0x6f, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x05, 0x52, 0x04, 0x50, 0x6f, 0x72, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x6f, 0x64, 0x65, 0x18,
-	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x4e, 0x6f, 0x64, 0x65, 0x22, 0xae, 0x03, 0x0a, 0x08,
-	0x53, 0x76, 0x63, 0x43, 0x61, 0x63, 0x68, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x53, 0x76, 0x63, 0x49,
-	0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x53, 0x76, 0x63, 0x49, 0x44, 0x12, 0x18,
-	0x0a, 0x07, 0x53, 0x76, 0x63, 0x54, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x07, 0x53, 0x76, 0x63, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x43, 0x6c, 0x75, 0x73,
-	0x74, 0x65, 0x72, 0x49, 0x50, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x43, 0x6c, 0x75,
-	0x73, 0x74, 0x65, 0x72, 0x49, 0x50, 0x12, 0x40, 0x0a, 0x05, 0x50, 0x6f, 0x72, 0x74, 0x73, 0x18,
-	0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65,
-	0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63,
-	0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x53, 0x76, 0x63, 0x50, 0x6f, 0x72,
-	0x74, 0x52, 0x05, 0x50, 0x6f, 0x72, 0x74, 0x73, 0x12, 0x28, 0x0a, 0x0f, 0x53, 0x65, 0x73, 0x73,
-	0x69, 0x6f, 0x6e, 0x41, 0x66, 0x66, 0x69, 0x6e, 0x69, 0x74, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x0f, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x41, 0x66, 0x66, 0x69, 0x6e, 0x69,
-	0x74, 0x79, 0x12, 0x36, 0x0a, 0x16, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x41, 0x66, 0x66,
-	0x69, 0x6e, 0x69, 0x74, 0x79, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x06, 0x20, 0x01,
-	0x28, 0x05, 0x52, 0x16, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x41, 0x66, 0x66, 0x69, 0x6e,
-	0x69, 0x74, 0x79, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x34, 0x0a, 0x15, 0x49, 0x6e,
-	0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x54, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x50, 0x6f, 0x6c,
-	0x69, 0x63, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x15, 0x49, 0x6e, 0x74, 0x65, 0x72,
-	0x6e, 0x61, 0x6c, 0x54, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79,
-	0x12, 0x46, 0x0a, 0x08, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x73, 0x18, 0x08, 0x20, 0x03,
-	0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f,
-	0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31,
-	0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x08,
-	0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x73, 0x12, 0x32, 0x0a, 0x14, 0x53, 0x76, 0x63, 0x50,
-	0x6f, 0x72, 0x74, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x73,
-	0x18, 0x09, 0x20, 0x03, 0x28, 0x09, 0x52, 0x14, 0x53, 0x76, 0x63, 0x50, 0x6f, 0x72, 0x74, 0x52,
-	0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x22, 0x66, 0x0a, 0x0c,
-	0x53, 0x76, 0x63, 0x46, 0x6c, 0x6f, 0x77, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x0e, 0x0a, 0x02,
-	0x49, 0x50, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x49, 0x50, 0x12, 0x1a, 0x0a, 0x08,
-	0x50, 0x6f, 0x72, 0x74, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
-	0x50, 0x6f, 0x72, 0x74, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x46, 0x6c, 0x6f, 0x77,
-	0x49, 0x44, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x46, 0x6c, 0x6f, 0x77, 0x49, 0x44,
-	0x12, 0x12, 0x0a, 0x04, 0x49, 0x6e, 0x66, 0x6f, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
-	0x49, 0x6e, 0x66, 0x6f, 0x22, 0x84, 0x01, 0x0a, 0x10, 0x53, 0x76, 0x63, 0x44, 0x6e, 0x61, 0x74,
-	0x46, 0x6c, 0x6f, 0x77, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x44, 0x0a, 0x07, 0x42, 0x61, 0x63,
-	0x6b, 0x65, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x65, 0x76, 0x65,
-	0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69,
-	0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x42,
-	0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x07, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x12,
-	0x16, 0x0a, 0x06, 0x46, 0x6c, 0x6f, 0x77, 0x49, 0x44, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52,
-	0x06, 0x46, 0x6c, 0x6f, 0x77, 0x49, 0x44, 0x12, 0x12, 0x0a, 0x04, 0x49, 0x6e, 0x66, 0x6f, 0x18,
-	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x49, 0x6e, 0x66, 0x6f, 0x22, 0x8c, 0x02, 0x0a, 0x07,
-	0x53, 0x76, 0x63, 0x46, 0x6c, 0x6f, 0x77, 0x12, 0x49, 0x0a, 0x07, 0x4c, 0x42, 0x46, 0x6c, 0x6f,
-	0x77, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2f, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f,
-	0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e,
-	0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x53, 0x76, 0x63,
-	0x46, 0x6c, 0x6f, 0x77, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x4c, 0x42, 0x46, 0x6c, 0x6f,
-	0x77, 0x73, 0x12, 0x63, 0x0a, 0x14, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x41, 0x66, 0x66,
-	0x69, 0x6e, 0x69, 0x74, 0x79, 0x46, 0x6c, 0x6f, 0x77, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b,
-	0x32, 0x2f, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70,
-	0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c,
-	0x70, 0x68, 0x61, 0x31, 0x2e, 0x53, 0x76, 0x63, 0x46, 0x6c, 0x6f, 0x77, 0x45, 0x6e, 0x74, 0x72,
-	0x79, 0x52, 0x14, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x41, 0x66, 0x66, 0x69, 0x6e, 0x69,
-	0x74, 0x79, 0x46, 0x6c, 0x6f, 0x77, 0x73, 0x12, 0x51, 0x0a, 0x09, 0x44, 0x6e, 0x61, 0x74, 0x46,
-	0x6c, 0x6f, 0x77, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x33, 0x2e, 0x65, 0x76, 0x65,
-	0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69,
-	0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x53,
-	0x76, 0x63, 0x44, 0x6e, 0x61, 0x74, 0x46, 0x6c, 0x6f, 0x77, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52,
-	0x09, 0x44, 0x6e, 0x61, 0x74, 0x46, 0x6c, 0x6f, 0x77, 0x73, 0x22, 0x7a, 0x0a, 0x08, 0x53, 0x76,
-	0x63, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x1a, 0x0a, 0x08, 0x50, 0x6f, 0x72, 0x74, 0x4e, 0x61,
-	0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x50, 0x6f, 0x72, 0x74, 0x4e, 0x61,
-	0x6d, 0x65, 0x12, 0x24, 0x0a, 0x0d, 0x54, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x50, 0x6f, 0x6c,
-	0x69, 0x63, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x54, 0x72, 0x61, 0x66, 0x66,
-	0x69, 0x63, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x47, 0x72, 0x6f, 0x75,
-	0x70, 0x49, 0x44, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x47, 0x72, 0x6f, 0x75, 0x70,
-	0x49, 0x44, 0x12, 0x12, 0x0a, 0x04, 0x49, 0x6e, 0x66, 0x6f, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x04, 0x49, 0x6e, 0x66, 0x6f, 0x22, 0xe1, 0x01, 0x0a, 0x07, 0x53, 0x76, 0x63, 0x49, 0x6e,
-	0x66, 0x6f, 0x12, 0x47, 0x0a, 0x08, 0x53, 0x76, 0x63, 0x43, 0x61, 0x63, 0x68, 0x65, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x2b, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f,
-	0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e,
-	0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x53, 0x76, 0x63, 0x43, 0x61, 0x63, 0x68,
-	0x65, 0x52, 0x08, 0x53, 0x76, 0x63, 0x43, 0x61, 0x63, 0x68, 0x65, 0x12, 0x44, 0x0a, 0x07, 0x53,
-	0x76, 0x63, 0x46, 0x6c, 0x6f, 0x77, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x65,
-	0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61,
-	0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31,
-	0x2e, 0x53, 0x76, 0x63, 0x46, 0x6c, 0x6f, 0x77, 0x52, 0x07, 0x53, 0x76, 0x63, 0x46, 0x6c, 0x6f,
-	0x77, 0x12, 0x47, 0x0a, 0x08, 0x53, 0x76, 0x63, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x18, 0x03, 0x20,
-	0x03, 0x28, 0x0b, 0x32, 0x2b, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69,
-	0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76,
-	0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x53, 0x76, 0x63, 0x47, 0x72, 0x6f, 0x75, 0x70,
-	0x52, 0x08, 0x53, 0x76, 0x63, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x32, 0xae, 0x03, 0x0a, 0x06, 0x47,
-	0x65, 0x74, 0x74, 0x65, 0x72, 0x12, 0x57, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x41, 0x6c, 0x6c, 0x52,
-	0x75, 0x6c, 0x65, 0x73, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x2e, 0x2e, 0x65,
-	0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61,
-	0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31,
-	0x2e, 0x52, 0x75, 0x6c, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x22, 0x00, 0x12, 0x6e,
-	0x0a, 0x0e, 0x47, 0x65, 0x74, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x42, 0x79, 0x4e, 0x61, 0x6d, 0x65,
-	0x12, 0x2a, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70,
-	0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c,
-	0x70, 0x68, 0x61, 0x31, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x49, 0x44, 0x73, 0x1a, 0x2e, 0x2e, 0x65,
-	0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61,
-	0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31,
-	0x2e, 0x52, 0x75, 0x6c, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x22, 0x00, 0x12, 0x6e,
-	0x0a, 0x0e, 0x47, 0x65, 0x74, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x42, 0x79, 0x46, 0x6c, 0x6f, 0x77,
-	0x12, 0x2a, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70,
-	0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c,
-	0x70, 0x68, 0x61, 0x31, 0x2e, 0x46, 0x6c, 0x6f, 0x77, 0x49, 0x44, 0x73, 0x1a, 0x2e, 0x2e, 0x65,
-	0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61,
-	0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31,
-	0x2e, 0x52, 0x75, 0x6c, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x22, 0x00, 0x12, 0x6b,
-	0x0a, 0x11, 0x47, 0x65, 0x74, 0x53, 0x76, 0x63, 0x49, 0x6e, 0x66, 0x6f, 0x42, 0x79, 0x53, 0x76,
-	0x63, 0x49, 0x44, 0x12, 0x28, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69,
-	0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76,
-	0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x53, 0x76, 0x63, 0x49, 0x44, 0x1a, 0x2a, 0x2e,
-	0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e,
-	0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61,
-	0x31, 0x2e, 0x53, 0x76, 0x63, 0x49, 0x6e, 0x66, 0x6f, 0x22, 0x00, 0x42, 0x17, 0x5a, 0x15, 0x70,
-	0x6b, 0x67, 0x2f, 0x61, 0x70, 0x69, 0x73, 0x2f, 0x72, 0x70, 0x63, 0x2f, 0x76, 0x31, 0x61, 0x6c,
-	0x70, 0x68, 0x61, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	FlowEntry           *FlowEntry             `protobuf:"bytes,1,opt,name=FlowEntry,proto3" json:"FlowEntry,omitempty"`
+	RuleID              string                 `protobuf:"bytes,2,opt,name=RuleID,proto3" json:"RuleID,omitempty"`
+	PolicyRuleReference []*PolicyRuleReference `protobuf:"bytes,3,rep,name=PolicyRuleReference,proto3" json:"PolicyRuleReference,omitempty"`
 }
 
-var (
-	file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescOnce sync.Once
-	file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescData = file_pkg_apis_rpc_v1alpha1_rule_proto_rawDesc
-)
+func (x *FlowDumpEntry) Reset() {
+	*x = FlowDumpEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
 
-func file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP() []byte {
-	file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescOnce.Do(func() {
-		file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescData = protoimpl.X.CompressGZIP(file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescData)
-	})
-	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescData
+func (x *FlowDumpEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-var file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
-var file_pkg_apis_rpc_v1alpha1_rule_proto_goTypes = []interface{}{
-	(*PolicyRule)(nil),          // 0: everoute_io.pkg.apis.rpc.v1alpha1.PolicyRule
-	(*FlowEntry)(nil),           // 1: everoute_io.pkg.apis.rpc.v1alpha1.FlowEntry
-	(*PolicyRuleReference)(nil), // 2: everoute_io.pkg.apis.rpc.v1alpha1.PolicyRuleReference
-	(*RuleEntry)(nil),           // 3: everoute_io.pkg.apis.rpc.v1alpha1.RuleEntry
-	(*RuleEntries)(nil),         // 4: everoute_io.pkg.apis.rpc.v1alpha1.RuleEntries
-	(*RuleIDs)(nil),             // 5: everoute_io.pkg.apis.rpc.v1alpha1.RuleIDs
-	(*FlowIDs)(nil),             // 6: everoute_io.pkg.apis.rpc.v1alpha1.FlowIDs
-	(*SvcID)(nil),               // 7: everoute_io.pkg.apis.rpc.v1alpha1.SvcID
-	(*SvcPort)(nil),             // 8: everoute_io.pkg.apis.rpc.v1alpha1.SvcPort
-	(*Backend)(nil),             // 9: everoute_io.pkg.apis.rpc.v1alpha1.Backend
-	(*SvcCache)(nil),            // 10: everoute_io.pkg.apis.rpc.v1alpha1.SvcCache
-	(*SvcFlowEntry)(nil),        // 11: everoute_io.pkg.apis.rpc.v1alpha1.SvcFlowEntry
-	(*SvcDnatFlowEntry)(nil),    // 12: everoute_io.pkg.apis.rpc.v1alpha1.SvcDnatFlowEntry
-	(*SvcFlow)(nil),             // 13: everoute_io.pkg.apis.rpc.v1alpha1.SvcFlow
-	(*SvcGroup)(nil),            // 14: everoute_io.pkg.apis.rpc.v1alpha1.SvcGroup
-	(*SvcInfo)(nil),             // 15: everoute_io.pkg.apis.rpc.v1alpha1.SvcInfo
-	nil,                         // 16: everoute_io.pkg.apis.rpc.v1alpha1.RuleEntry.RuleFlowMapEntry
-	(*emptypb.Empty)(nil),       // 17: google.protobuf.Empty
+func (*FlowDumpEntry) ProtoMessage() {}
+
+func (x *FlowDumpEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
-var file_pkg_apis_rpc_v1alpha1_rule_proto_depIdxs = []int32{
-	0,  // 0: everoute_io.pkg.apis.rpc.v1alpha1.RuleEntry.EveroutePolicyRule:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.PolicyRule
-	16, // 1: everoute_io.pkg.apis.rpc.v1alpha1.RuleEntry.RuleFlowMap:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.RuleEntry.RuleFlowMapEntry
-	2,  // 2: everoute_io.pkg.apis.rpc.v1alpha1.RuleEntry.PolicyRuleReference:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.PolicyRuleReference
-	3,  // 3: everoute_io.pkg.apis.rpc.v1alpha1.RuleEntries.RuleEntries:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.RuleEntry
-	8,  // 4: everoute_io.pkg.apis.rpc.v1alpha1.SvcCache.Ports:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.SvcPort
-	9,  // 5: everoute_io.pkg.apis.rpc.v1alpha1.SvcCache.Backends:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.Backend
-	9,  // 6: everoute_io.pkg.apis.rpc.v1alpha1.SvcDnatFlowEntry.Backend:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.Backend
-	11, // 7: everoute_io.pkg.apis.rpc.v1alpha1.SvcFlow.LBFlows:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.SvcFlowEntry
-	11, // 8: everoute_io.pkg.apis.rpc.v1alpha1.SvcFlow.SessionAffinityFlows:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.SvcFlowEntry
-	12, // 9: everoute_io.pkg.apis.rpc.v1alpha1.SvcFlow.DnatFlows:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.SvcDnatFlowEntry
-	10, // 10: everoute_io.pkg.apis.rpc.v1alpha1.SvcInfo.SvcCache:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.SvcCache
-	13, // 11: everoute_io.pkg.apis.rpc.v1alpha1.SvcInfo.SvcFlow:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.SvcFlow
-	14, // 12: everoute_io.pkg.apis.rpc.v1alpha1.SvcInfo.SvcGroup:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.SvcGroup
-	1,  // 13: everoute_io.pkg.apis.rpc.v1alpha1.RuleEntry.RuleFlowMapEntry.value:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.FlowEntry
-	17, // 14: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetAllRules:input_type -> google.protobuf.Empty
-	5,  // 15: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetRulesByName:input_type -> everoute_io.pkg.apis.rpc.v1alpha1.RuleIDs
-	6,  // 16: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetRulesByFlow:input_type -> everoute_io.pkg.apis.rpc.v1alpha1.FlowIDs
-	7,  // 17: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetSvcInfoBySvcID:input_type -> everoute_io.pkg.apis.rpc.v1alpha1.SvcID
-	4,  // 18: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetAllRules:output_type -> everoute_io.pkg.apis.rpc.v1alpha1.RuleEntries
-	4,  // 19: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetRulesByName:output_type -> everoute_io.pkg.apis.rpc.v1alpha1.RuleEntries
-	4,  // 20: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetRulesByFlow:output_type -> everoute_io.pkg.apis.rpc.v1alpha1.RuleEntries
-	15, // 21: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetSvcInfoBySvcID:output_type -> everoute_io.pkg.apis.rpc.v1alpha1.SvcInfo
-	18, // [18:22] is the sub-list for method output_type
-	14, // [14:18] is the sub-list for method input_type
-	14, // [14:14] is the sub-list for extension type_name
-	14, // [14:14] is the sub-list for extension extendee
-	0,  // [0:14] is the sub-list for field type_name
+
+// Deprecated: Use FlowDumpEntry.ProtoReflect.Descriptor instead.
+func (*FlowDumpEntry) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{17}
 }
 
-func init() { file_pkg_apis_rpc_v1alpha1_rule_proto_init() }
-func file_pkg_apis_rpc_v1alpha1_rule_proto_init() {
-	if File_pkg_apis_rpc_v1alpha1_rule_proto != nil {
-		return
+func (x *FlowDumpEntry) GetFlowEntry() *FlowEntry {
+	if x != nil {
+		return x.FlowEntry
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*PolicyRule); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+	return nil
+}
+
+func (x *FlowDumpEntry) GetRuleID() string {
+	if x != nil {
+		return x.RuleID
+	}
+	return ""
+}
+
+func (x *FlowDumpEntry) GetPolicyRuleReference() []*PolicyRuleReference {
+	if x != nil {
+		return x.PolicyRuleReference
+	}
+	return nil
+}
+
+type BridgeFlowDump struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	VdsID         string           `protobuf:"bytes,1,opt,name=VdsID,proto3" json:"VdsID,omitempty"`
+	BridgeKeyword string           `protobuf:"bytes,2,opt,name=BridgeKeyword,proto3" json:"BridgeKeyword,omitempty"`
+	BridgeName    string           `protobuf:"bytes,3,opt,name=BridgeName,proto3" json:"BridgeName,omitempty"`
+	FlowEntries   []*FlowDumpEntry `protobuf:"bytes,4,rep,name=FlowEntries,proto3" json:"FlowEntries,omitempty"`
+}
+
+func (x *BridgeFlowDump) Reset() {
+	*x = BridgeFlowDump{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BridgeFlowDump) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BridgeFlowDump) ProtoMessage() {}
+
+func (x *BridgeFlowDump) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BridgeFlowDump.ProtoReflect.Descriptor instead.
+func (*BridgeFlowDump) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *BridgeFlowDump) GetVdsID() string {
+	if x != nil {
+		return x.VdsID
+	}
+	return ""
+}
+
+func (x *BridgeFlowDump) GetBridgeKeyword() string {
+	if x != nil {
+		return x.BridgeKeyword
+	}
+	return ""
+}
+
+func (x *BridgeFlowDump) GetBridgeName() string {
+	if x != nil {
+		return x.BridgeName
+	}
+	return ""
+}
+
+func (x *BridgeFlowDump) GetFlowEntries() []*FlowDumpEntry {
+	if x != nil {
+		return x.FlowEntries
+	}
+	return nil
+}
+
+type FlowDumps struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BridgeFlowDumps []*BridgeFlowDump `protobuf:"bytes,1,rep,name=BridgeFlowDumps,proto3" json:"BridgeFlowDumps,omitempty"`
+}
+
+func (x *FlowDumps) Reset() {
+	*x = FlowDumps{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FlowDumps) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlowDumps) ProtoMessage() {}
+
+func (x *FlowDumps) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlowDumps.ProtoReflect.Descriptor instead.
+func (*FlowDumps) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *FlowDumps) GetBridgeFlowDumps() []*BridgeFlowDump {
+	if x != nil {
+		return x.BridgeFlowDumps
+	}
+	return nil
+}
+
+type ReachableQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SrcIP    string `protobuf:"bytes,1,opt,name=SrcIP,proto3" json:"SrcIP,omitempty"`
+	DstIP    string `protobuf:"bytes,2,opt,name=DstIP,proto3" json:"DstIP,omitempty"`
+	Protocol uint32 `protobuf:"varint,3,opt,name=Protocol,proto3" json:"Protocol,omitempty"`
+	Port     uint32 `protobuf:"varint,4,opt,name=Port,proto3" json:"Port,omitempty"`
+}
+
+func (x *ReachableQuery) Reset() {
+	*x = ReachableQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReachableQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReachableQuery) ProtoMessage() {}
+
+func (x *ReachableQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReachableQuery.ProtoReflect.Descriptor instead.
+func (*ReachableQuery) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ReachableQuery) GetSrcIP() string {
+	if x != nil {
+		return x.SrcIP
+	}
+	return ""
+}
+
+func (x *ReachableQuery) GetDstIP() string {
+	if x != nil {
+		return x.DstIP
+	}
+	return ""
+}
+
+func (x *ReachableQuery) GetProtocol() uint32 {
+	if x != nil {
+		return x.Protocol
+	}
+	return 0
+}
+
+func (x *ReachableQuery) GetPort() uint32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+type RuleDecision struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Direction           uint32                 `protobuf:"varint,1,opt,name=Direction,proto3" json:"Direction,omitempty"`
+	Action              string                 `protobuf:"bytes,2,opt,name=Action,proto3" json:"Action,omitempty"`
+	Tier                uint32                 `protobuf:"varint,3,opt,name=Tier,proto3" json:"Tier,omitempty"`
+	Priority            int32                  `protobuf:"varint,4,opt,name=Priority,proto3" json:"Priority,omitempty"`
+	RuleID              string                 `protobuf:"bytes,5,opt,name=RuleID,proto3" json:"RuleID,omitempty"`
+	PolicyRuleReference []*PolicyRuleReference `protobuf:"bytes,6,rep,name=PolicyRuleReference,proto3" json:"PolicyRuleReference,omitempty"`
+}
+
+func (x *RuleDecision) Reset() {
+	*x = RuleDecision{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RuleDecision) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuleDecision) ProtoMessage() {}
+
+func (x *RuleDecision) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuleDecision.ProtoReflect.Descriptor instead.
+func (*RuleDecision) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *RuleDecision) GetDirection() uint32 {
+	if x != nil {
+		return x.Direction
+	}
+	return 0
+}
+
+func (x *RuleDecision) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *RuleDecision) GetTier() uint32 {
+	if x != nil {
+		return x.Tier
+	}
+	return 0
+}
+
+func (x *RuleDecision) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *RuleDecision) GetRuleID() string {
+	if x != nil {
+		return x.RuleID
+	}
+	return ""
+}
+
+func (x *RuleDecision) GetPolicyRuleReference() []*PolicyRuleReference {
+	if x != nil {
+		return x.PolicyRuleReference
+	}
+	return nil
+}
+
+type ReachableResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Reachable bool          `protobuf:"varint,1,opt,name=Reachable,proto3" json:"Reachable,omitempty"`
+	Egress    *RuleDecision `protobuf:"bytes,2,opt,name=Egress,proto3" json:"Egress,omitempty"`
+	Ingress   *RuleDecision `protobuf:"bytes,3,opt,name=Ingress,proto3" json:"Ingress,omitempty"`
+}
+
+func (x *ReachableResult) Reset() {
+	*x = ReachableResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReachableResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReachableResult) ProtoMessage() {}
+
+func (x *ReachableResult) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReachableResult.ProtoReflect.Descriptor instead.
+func (*ReachableResult) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ReachableResult) GetReachable() bool {
+	if x != nil {
+		return x.Reachable
+	}
+	return false
+}
+
+func (x *ReachableResult) GetEgress() *RuleDecision {
+	if x != nil {
+		return x.Egress
+	}
+	return nil
+}
+
+func (x *ReachableResult) GetIngress() *RuleDecision {
+	if x != nil {
+		return x.Ingress
+	}
+	return nil
+}
+
+type LeakedFlowQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Remove bool `protobuf:"varint,1,opt,name=Remove,proto3" json:"Remove,omitempty"`
+}
+
+func (x *LeakedFlowQuery) Reset() {
+	*x = LeakedFlowQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LeakedFlowQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeakedFlowQuery) ProtoMessage() {}
+
+func (x *LeakedFlowQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeakedFlowQuery.ProtoReflect.Descriptor instead.
+func (*LeakedFlowQuery) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *LeakedFlowQuery) GetRemove() bool {
+	if x != nil {
+		return x.Remove
+	}
+	return false
+}
+
+type LeakedFlow struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	VdsID      string     `protobuf:"bytes,1,opt,name=VdsID,proto3" json:"VdsID,omitempty"`
+	BridgeName string     `protobuf:"bytes,2,opt,name=BridgeName,proto3" json:"BridgeName,omitempty"`
+	FlowEntry  *FlowEntry `protobuf:"bytes,3,opt,name=FlowEntry,proto3" json:"FlowEntry,omitempty"`
+}
+
+func (x *LeakedFlow) Reset() {
+	*x = LeakedFlow{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LeakedFlow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeakedFlow) ProtoMessage() {}
+
+func (x *LeakedFlow) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeakedFlow.ProtoReflect.Descriptor instead.
+func (*LeakedFlow) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *LeakedFlow) GetVdsID() string {
+	if x != nil {
+		return x.VdsID
+	}
+	return ""
+}
+
+func (x *LeakedFlow) GetBridgeName() string {
+	if x != nil {
+		return x.BridgeName
+	}
+	return ""
+}
+
+func (x *LeakedFlow) GetFlowEntry() *FlowEntry {
+	if x != nil {
+		return x.FlowEntry
+	}
+	return nil
+}
+
+type LeakedFlows struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	LeakedFlows []*LeakedFlow `protobuf:"bytes,1,rep,name=LeakedFlows,proto3" json:"LeakedFlows,omitempty"`
+}
+
+func (x *LeakedFlows) Reset() {
+	*x = LeakedFlows{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LeakedFlows) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeakedFlows) ProtoMessage() {}
+
+func (x *LeakedFlows) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeakedFlows.ProtoReflect.Descriptor instead.
+func (*LeakedFlows) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *LeakedFlows) GetLeakedFlows() []*LeakedFlow {
+	if x != nil {
+		return x.LeakedFlows
+	}
+	return nil
+}
+
+type TableMissAction struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	VdsID  string `protobuf:"bytes,1,opt,name=VdsID,proto3" json:"VdsID,omitempty"`
+	Action string `protobuf:"bytes,2,opt,name=Action,proto3" json:"Action,omitempty"`
+}
+
+func (x *TableMissAction) Reset() {
+	*x = TableMissAction{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TableMissAction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TableMissAction) ProtoMessage() {}
+
+func (x *TableMissAction) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TableMissAction.ProtoReflect.Descriptor instead.
+func (*TableMissAction) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *TableMissAction) GetVdsID() string {
+	if x != nil {
+		return x.VdsID
+	}
+	return ""
+}
+
+func (x *TableMissAction) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+type EffectiveRuleQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IP     string `protobuf:"bytes,1,opt,name=IP,proto3" json:"IP,omitempty"`
+	VlanID uint32 `protobuf:"varint,2,opt,name=VlanID,proto3" json:"VlanID,omitempty"`
+}
+
+func (x *EffectiveRuleQuery) Reset() {
+	*x = EffectiveRuleQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EffectiveRuleQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EffectiveRuleQuery) ProtoMessage() {}
+
+func (x *EffectiveRuleQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EffectiveRuleQuery.ProtoReflect.Descriptor instead.
+func (*EffectiveRuleQuery) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *EffectiveRuleQuery) GetIP() string {
+	if x != nil {
+		return x.IP
+	}
+	return ""
+}
+
+func (x *EffectiveRuleQuery) GetVlanID() uint32 {
+	if x != nil {
+		return x.VlanID
+	}
+	return 0
+}
+
+type EndpointEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	InterfaceUUID        string `protobuf:"bytes,1,opt,name=InterfaceUUID,proto3" json:"InterfaceUUID,omitempty"`
+	InterfaceName        string `protobuf:"bytes,2,opt,name=InterfaceName,proto3" json:"InterfaceName,omitempty"`
+	IPAddr               string `protobuf:"bytes,3,opt,name=IPAddr,proto3" json:"IPAddr,omitempty"`
+	IPv6Addr             string `protobuf:"bytes,4,opt,name=IPv6Addr,proto3" json:"IPv6Addr,omitempty"`
+	MacAddr              string `protobuf:"bytes,5,opt,name=MacAddr,proto3" json:"MacAddr,omitempty"`
+	VlanID               uint32 `protobuf:"varint,6,opt,name=VlanID,proto3" json:"VlanID,omitempty"`
+	OfPort               uint32 `protobuf:"varint,7,opt,name=OfPort,proto3" json:"OfPort,omitempty"`
+	BridgeName           string `protobuf:"bytes,8,opt,name=BridgeName,proto3" json:"BridgeName,omitempty"`
+	IPAddrLastUpdateTime int64  `protobuf:"varint,9,opt,name=IPAddrLastUpdateTime,proto3" json:"IPAddrLastUpdateTime,omitempty"`
+}
+
+func (x *EndpointEntry) Reset() {
+	*x = EndpointEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EndpointEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EndpointEntry) ProtoMessage() {}
+
+func (x *EndpointEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EndpointEntry.ProtoReflect.Descriptor instead.
+func (*EndpointEntry) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *EndpointEntry) GetInterfaceUUID() string {
+	if x != nil {
+		return x.InterfaceUUID
+	}
+	return ""
+}
+
+func (x *EndpointEntry) GetInterfaceName() string {
+	if x != nil {
+		return x.InterfaceName
+	}
+	return ""
+}
+
+func (x *EndpointEntry) GetIPAddr() string {
+	if x != nil {
+		return x.IPAddr
+	}
+	return ""
+}
+
+func (x *EndpointEntry) GetIPv6Addr() string {
+	if x != nil {
+		return x.IPv6Addr
+	}
+	return ""
+}
+
+func (x *EndpointEntry) GetMacAddr() string {
+	if x != nil {
+		return x.MacAddr
+	}
+	return ""
+}
+
+func (x *EndpointEntry) GetVlanID() uint32 {
+	if x != nil {
+		return x.VlanID
+	}
+	return 0
+}
+
+func (x *EndpointEntry) GetOfPort() uint32 {
+	if x != nil {
+		return x.OfPort
+	}
+	return 0
+}
+
+func (x *EndpointEntry) GetBridgeName() string {
+	if x != nil {
+		return x.BridgeName
+	}
+	return ""
+}
+
+func (x *EndpointEntry) GetIPAddrLastUpdateTime() int64 {
+	if x != nil {
+		return x.IPAddrLastUpdateTime
+	}
+	return 0
+}
+
+type Endpoints struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Endpoints []*EndpointEntry `protobuf:"bytes,1,rep,name=Endpoints,proto3" json:"Endpoints,omitempty"`
+}
+
+func (x *Endpoints) Reset() {
+	*x = Endpoints{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Endpoints) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Endpoints) ProtoMessage() {}
+
+func (x *Endpoints) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Endpoints.ProtoReflect.Descriptor instead.
+func (*Endpoints) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *Endpoints) GetEndpoints() []*EndpointEntry {
+	if x != nil {
+		return x.Endpoints
+	}
+	return nil
+}
+
+type EndpointIPRefresh struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	InterfaceUUID string `protobuf:"bytes,1,opt,name=InterfaceUUID,proto3" json:"InterfaceUUID,omitempty"`
+}
+
+func (x *EndpointIPRefresh) Reset() {
+	*x = EndpointIPRefresh{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EndpointIPRefresh) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EndpointIPRefresh) ProtoMessage() {}
+
+func (x *EndpointIPRefresh) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EndpointIPRefresh.ProtoReflect.Descriptor instead.
+func (*EndpointIPRefresh) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *EndpointIPRefresh) GetInterfaceUUID() string {
+	if x != nil {
+		return x.InterfaceUUID
+	}
+	return ""
+}
+
+type EndpointIPRefreshResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	InterfaceUUID string `protobuf:"bytes,1,opt,name=InterfaceUUID,proto3" json:"InterfaceUUID,omitempty"`
+	Success       bool   `protobuf:"varint,2,opt,name=Success,proto3" json:"Success,omitempty"`
+	Error         string `protobuf:"bytes,3,opt,name=Error,proto3" json:"Error,omitempty"`
+}
+
+func (x *EndpointIPRefreshResult) Reset() {
+	*x = EndpointIPRefreshResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EndpointIPRefreshResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EndpointIPRefreshResult) ProtoMessage() {}
+
+func (x *EndpointIPRefreshResult) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EndpointIPRefreshResult.ProtoReflect.Descriptor instead.
+func (*EndpointIPRefreshResult) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *EndpointIPRefreshResult) GetInterfaceUUID() string {
+	if x != nil {
+		return x.InterfaceUUID
+	}
+	return ""
+}
+
+func (x *EndpointIPRefreshResult) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *EndpointIPRefreshResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type EndpointIPRefreshResults struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Results []*EndpointIPRefreshResult `protobuf:"bytes,1,rep,name=Results,proto3" json:"Results,omitempty"`
+}
+
+func (x *EndpointIPRefreshResults) Reset() {
+	*x = EndpointIPRefreshResults{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EndpointIPRefreshResults) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EndpointIPRefreshResults) ProtoMessage() {}
+
+func (x *EndpointIPRefreshResults) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EndpointIPRefreshResults.ProtoReflect.Descriptor instead.
+func (*EndpointIPRefreshResults) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *EndpointIPRefreshResults) GetResults() []*EndpointIPRefreshResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type ReplayStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	VdsID         string `protobuf:"bytes,1,opt,name=VdsID,proto3" json:"VdsID,omitempty"`
+	ReplayedRules uint32 `protobuf:"varint,2,opt,name=ReplayedRules,proto3" json:"ReplayedRules,omitempty"`
+	TotalRules    uint32 `protobuf:"varint,3,opt,name=TotalRules,proto3" json:"TotalRules,omitempty"`
+	InProgress    bool   `protobuf:"varint,4,opt,name=InProgress,proto3" json:"InProgress,omitempty"`
+	StartTime     int64  `protobuf:"varint,5,opt,name=StartTime,proto3" json:"StartTime,omitempty"`
+	FinishTime    int64  `protobuf:"varint,6,opt,name=FinishTime,proto3" json:"FinishTime,omitempty"`
+	Error         string `protobuf:"bytes,7,opt,name=Error,proto3" json:"Error,omitempty"`
+}
+
+func (x *ReplayStatus) Reset() {
+	*x = ReplayStatus{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReplayStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayStatus) ProtoMessage() {}
+
+func (x *ReplayStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayStatus.ProtoReflect.Descriptor instead.
+func (*ReplayStatus) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ReplayStatus) GetVdsID() string {
+	if x != nil {
+		return x.VdsID
+	}
+	return ""
+}
+
+func (x *ReplayStatus) GetReplayedRules() uint32 {
+	if x != nil {
+		return x.ReplayedRules
+	}
+	return 0
+}
+
+func (x *ReplayStatus) GetTotalRules() uint32 {
+	if x != nil {
+		return x.TotalRules
+	}
+	return 0
+}
+
+func (x *ReplayStatus) GetInProgress() bool {
+	if x != nil {
+		return x.InProgress
+	}
+	return false
+}
+
+func (x *ReplayStatus) GetStartTime() int64 {
+	if x != nil {
+		return x.StartTime
+	}
+	return 0
+}
+
+func (x *ReplayStatus) GetFinishTime() int64 {
+	if x != nil {
+		return x.FinishTime
+	}
+	return 0
+}
+
+func (x *ReplayStatus) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ReplayStatuses struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ReplayStatuses []*ReplayStatus `protobuf:"bytes,1,rep,name=ReplayStatuses,proto3" json:"ReplayStatuses,omitempty"`
+}
+
+func (x *ReplayStatuses) Reset() {
+	*x = ReplayStatuses{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[34]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReplayStatuses) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayStatuses) ProtoMessage() {}
+
+func (x *ReplayStatuses) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[34]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayStatuses.ProtoReflect.Descriptor instead.
+func (*ReplayStatuses) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ReplayStatuses) GetReplayStatuses() []*ReplayStatus {
+	if x != nil {
+		return x.ReplayStatuses
+	}
+	return nil
+}
+
+type RuleGroup struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name    string `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
+	Enabled bool   `protobuf:"varint,2,opt,name=Enabled,proto3" json:"Enabled,omitempty"`
+}
+
+func (x *RuleGroup) Reset() {
+	*x = RuleGroup{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[35]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RuleGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuleGroup) ProtoMessage() {}
+
+func (x *RuleGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[35]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuleGroup.ProtoReflect.Descriptor instead.
+func (*RuleGroup) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *RuleGroup) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RuleGroup) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+type RuleConflict struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Shadowed   *RuleEntry `protobuf:"bytes,1,opt,name=Shadowed,proto3" json:"Shadowed,omitempty"`
+	ShadowedBy *RuleEntry `protobuf:"bytes,2,opt,name=ShadowedBy,proto3" json:"ShadowedBy,omitempty"`
+}
+
+func (x *RuleConflict) Reset() {
+	*x = RuleConflict{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[36]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RuleConflict) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuleConflict) ProtoMessage() {}
+
+func (x *RuleConflict) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[36]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuleConflict.ProtoReflect.Descriptor instead.
+func (*RuleConflict) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *RuleConflict) GetShadowed() *RuleEntry {
+	if x != nil {
+		return x.Shadowed
+	}
+	return nil
+}
+
+func (x *RuleConflict) GetShadowedBy() *RuleEntry {
+	if x != nil {
+		return x.ShadowedBy
+	}
+	return nil
+}
+
+type RuleConflicts struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RuleConflicts []*RuleConflict `protobuf:"bytes,1,rep,name=RuleConflicts,proto3" json:"RuleConflicts,omitempty"`
+}
+
+func (x *RuleConflicts) Reset() {
+	*x = RuleConflicts{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[37]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RuleConflicts) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuleConflicts) ProtoMessage() {}
+
+func (x *RuleConflicts) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[37]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuleConflicts.ProtoReflect.Descriptor instead.
+func (*RuleConflicts) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *RuleConflicts) GetRuleConflicts() []*RuleConflict {
+	if x != nil {
+		return x.RuleConflicts
+	}
+	return nil
+}
+
+type ReferencedLabel struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key      string   `protobuf:"bytes,1,opt,name=Key,proto3" json:"Key,omitempty"`
+	Values   []string `protobuf:"bytes,2,rep,name=Values,proto3" json:"Values,omitempty"`
+	AnyValue bool     `protobuf:"varint,3,opt,name=AnyValue,proto3" json:"AnyValue,omitempty"`
+}
+
+func (x *ReferencedLabel) Reset() {
+	*x = ReferencedLabel{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[38]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReferencedLabel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReferencedLabel) ProtoMessage() {}
+
+func (x *ReferencedLabel) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[38]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReferencedLabel.ProtoReflect.Descriptor instead.
+func (*ReferencedLabel) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *ReferencedLabel) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *ReferencedLabel) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+func (x *ReferencedLabel) GetAnyValue() bool {
+	if x != nil {
+		return x.AnyValue
+	}
+	return false
+}
+
+type ReferencedLabels struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Labels []*ReferencedLabel `protobuf:"bytes,1,rep,name=Labels,proto3" json:"Labels,omitempty"`
+}
+
+func (x *ReferencedLabels) Reset() {
+	*x = ReferencedLabels{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[39]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReferencedLabels) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReferencedLabels) ProtoMessage() {}
+
+func (x *ReferencedLabels) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[39]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReferencedLabels.ProtoReflect.Descriptor instead.
+func (*ReferencedLabels) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *ReferencedLabels) GetLabels() []*ReferencedLabel {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+type PolicyDecision struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Timestamp  int64  `protobuf:"varint,1,opt,name=Timestamp,proto3" json:"Timestamp,omitempty"`
+	BridgeName string `protobuf:"bytes,2,opt,name=BridgeName,proto3" json:"BridgeName,omitempty"`
+	SrcIP      string `protobuf:"bytes,3,opt,name=SrcIP,proto3" json:"SrcIP,omitempty"`
+	DstIP      string `protobuf:"bytes,4,opt,name=DstIP,proto3" json:"DstIP,omitempty"`
+	Protocol   uint32 `protobuf:"varint,5,opt,name=Protocol,proto3" json:"Protocol,omitempty"`
+	SrcPort    uint32 `protobuf:"varint,6,opt,name=SrcPort,proto3" json:"SrcPort,omitempty"`
+	DstPort    uint32 `protobuf:"varint,7,opt,name=DstPort,proto3" json:"DstPort,omitempty"`
+	FlowID     uint64 `protobuf:"varint,8,opt,name=FlowID,proto3" json:"FlowID,omitempty"`
+	RuleID     string `protobuf:"bytes,9,opt,name=RuleID,proto3" json:"RuleID,omitempty"`
+	Action     string `protobuf:"bytes,10,opt,name=Action,proto3" json:"Action,omitempty"`
+}
+
+func (x *PolicyDecision) Reset() {
+	*x = PolicyDecision{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[40]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PolicyDecision) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PolicyDecision) ProtoMessage() {}
+
+func (x *PolicyDecision) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[40]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PolicyDecision.ProtoReflect.Descriptor instead.
+func (*PolicyDecision) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *PolicyDecision) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *PolicyDecision) GetBridgeName() string {
+	if x != nil {
+		return x.BridgeName
+	}
+	return ""
+}
+
+func (x *PolicyDecision) GetSrcIP() string {
+	if x != nil {
+		return x.SrcIP
+	}
+	return ""
+}
+
+func (x *PolicyDecision) GetDstIP() string {
+	if x != nil {
+		return x.DstIP
+	}
+	return ""
+}
+
+func (x *PolicyDecision) GetProtocol() uint32 {
+	if x != nil {
+		return x.Protocol
+	}
+	return 0
+}
+
+func (x *PolicyDecision) GetSrcPort() uint32 {
+	if x != nil {
+		return x.SrcPort
+	}
+	return 0
+}
+
+func (x *PolicyDecision) GetDstPort() uint32 {
+	if x != nil {
+		return x.DstPort
+	}
+	return 0
+}
+
+func (x *PolicyDecision) GetFlowID() uint64 {
+	if x != nil {
+		return x.FlowID
+	}
+	return 0
+}
+
+func (x *PolicyDecision) GetRuleID() string {
+	if x != nil {
+		return x.RuleID
+	}
+	return ""
+}
+
+func (x *PolicyDecision) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+type PolicyDecisions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Decisions []*PolicyDecision `protobuf:"bytes,1,rep,name=Decisions,proto3" json:"Decisions,omitempty"`
+}
+
+func (x *PolicyDecisions) Reset() {
+	*x = PolicyDecisions{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[41]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PolicyDecisions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PolicyDecisions) ProtoMessage() {}
+
+func (x *PolicyDecisions) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[41]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PolicyDecisions.ProtoReflect.Descriptor instead.
+func (*PolicyDecisions) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *PolicyDecisions) GetDecisions() []*PolicyDecision {
+	if x != nil {
+		return x.Decisions
+	}
+	return nil
+}
+
+type TierTable struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Direction uint32 `protobuf:"varint,1,opt,name=Direction,proto3" json:"Direction,omitempty"`
+	Tier      uint32 `protobuf:"varint,2,opt,name=Tier,proto3" json:"Tier,omitempty"`
+	Mode      string `protobuf:"bytes,3,opt,name=Mode,proto3" json:"Mode,omitempty"`
+	Table     uint32 `protobuf:"varint,4,opt,name=Table,proto3" json:"Table,omitempty"`
+	NextTable uint32 `protobuf:"varint,5,opt,name=NextTable,proto3" json:"NextTable,omitempty"`
+}
+
+func (x *TierTable) Reset() {
+	*x = TierTable{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[42]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TierTable) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TierTable) ProtoMessage() {}
+
+func (x *TierTable) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[42]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TierTable.ProtoReflect.Descriptor instead.
+func (*TierTable) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *TierTable) GetDirection() uint32 {
+	if x != nil {
+		return x.Direction
+	}
+	return 0
+}
+
+func (x *TierTable) GetTier() uint32 {
+	if x != nil {
+		return x.Tier
+	}
+	return 0
+}
+
+func (x *TierTable) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *TierTable) GetTable() uint32 {
+	if x != nil {
+		return x.Table
+	}
+	return 0
+}
+
+func (x *TierTable) GetNextTable() uint32 {
+	if x != nil {
+		return x.NextTable
+	}
+	return 0
+}
+
+type BridgeStage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Bridge string `protobuf:"bytes,1,opt,name=Bridge,proto3" json:"Bridge,omitempty"`
+	Stage  string `protobuf:"bytes,2,opt,name=Stage,proto3" json:"Stage,omitempty"`
+	Table  uint32 `protobuf:"varint,3,opt,name=Table,proto3" json:"Table,omitempty"`
+}
+
+func (x *BridgeStage) Reset() {
+	*x = BridgeStage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[43]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BridgeStage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BridgeStage) ProtoMessage() {}
+
+func (x *BridgeStage) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[43]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BridgeStage.ProtoReflect.Descriptor instead.
+func (*BridgeStage) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *BridgeStage) GetBridge() string {
+	if x != nil {
+		return x.Bridge
+	}
+	return ""
+}
+
+func (x *BridgeStage) GetStage() string {
+	if x != nil {
+		return x.Stage
+	}
+	return ""
+}
+
+func (x *BridgeStage) GetTable() uint32 {
+	if x != nil {
+		return x.Table
+	}
+	return 0
+}
+
+type TableLayout struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TierTables   []*TierTable   `protobuf:"bytes,1,rep,name=TierTables,proto3" json:"TierTables,omitempty"`
+	BridgeStages []*BridgeStage `protobuf:"bytes,2,rep,name=BridgeStages,proto3" json:"BridgeStages,omitempty"`
+}
+
+func (x *TableLayout) Reset() {
+	*x = TableLayout{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[44]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TableLayout) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TableLayout) ProtoMessage() {}
+
+func (x *TableLayout) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[44]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TableLayout.ProtoReflect.Descriptor instead.
+func (*TableLayout) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *TableLayout) GetTierTables() []*TierTable {
+	if x != nil {
+		return x.TierTables
+	}
+	return nil
+}
+
+func (x *TableLayout) GetBridgeStages() []*BridgeStage {
+	if x != nil {
+		return x.BridgeStages
+	}
+	return nil
+}
+
+var File_pkg_apis_rpc_v1alpha1_rule_proto protoreflect.FileDescriptor
+
+var file_pkg_apis_rpc_v1alpha1_rule_proto_rawDesc = []byte{
+	0x0a, 0x20, 0x70, 0x6b, 0x67, 0x2f, 0x61, 0x70, 0x69, 0x73, 0x2f, 0x72, 0x70, 0x63, 0x2f, 0x76,
+	0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2f, 0x72, 0x75, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x12, 0x21, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e,
+	0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61,
+	0x6c, 0x70, 0x68, 0x61, 0x31, 0x1a, 0x1b, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x22, 0xac, 0x02, 0x0a, 0x0a, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x75, 0x6c,
+	0x65, 0x12, 0x16, 0x0a, 0x06, 0x52, 0x75, 0x6c, 0x65, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x52, 0x75, 0x6c, 0x65, 0x49, 0x44, 0x12, 0x1a, 0x0a, 0x08, 0x50, 0x72, 0x69,
+	0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x50, 0x72, 0x69,
+	0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x53, 0x72, 0x63, 0x49, 0x50, 0x41, 0x64,
+	0x64, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x53, 0x72, 0x63, 0x49, 0x50, 0x41,
+	0x64, 0x64, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x44, 0x73, 0x74, 0x49, 0x50, 0x41, 0x64, 0x64, 0x72,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x44, 0x73, 0x74, 0x49, 0x50, 0x41, 0x64, 0x64,
+	0x72, 0x12, 0x1e, 0x0a, 0x0a, 0x49, 0x50, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x49, 0x50, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f,
+	0x6c, 0x12, 0x18, 0x0a, 0x07, 0x53, 0x72, 0x63, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x07, 0x53, 0x72, 0x63, 0x50, 0x6f, 0x72, 0x74, 0x12, 0x20, 0x0a, 0x0b, 0x53,
+	0x72, 0x63, 0x50, 0x6f, 0x72, 0x74, 0x4d, 0x61, 0x73, 0x6b, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x0b, 0x53, 0x72, 0x63, 0x50, 0x6f, 0x72, 0x74, 0x4d, 0x61, 0x73, 0x6b, 0x12, 0x18, 0x0a,
+	0x07, 0x44, 0x73, 0x74, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07,
+	0x44, 0x73, 0x74, 0x50, 0x6f, 0x72, 0x74, 0x12, 0x20, 0x0a, 0x0b, 0x44, 0x73, 0x74, 0x50, 0x6f,
+	0x72, 0x74, 0x4d, 0x61, 0x73, 0x6b, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x44, 0x73,
+	0x74, 0x50, 0x6f, 0x72, 0x74, 0x4d, 0x61, 0x73, 0x6b, 0x12, 0x16, 0x0a, 0x06, 0x41, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x41, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x22, 0x59, 0x0a, 0x09, 0x46, 0x6c, 0x6f, 0x77, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x1a,
+	0x0a, 0x08, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x08, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x46, 0x6c,
+	0x6f, 0x77, 0x49, 0x44, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x46, 0x6c, 0x6f, 0x77,
+	0x49, 0x44, 0x12, 0x18, 0x0a, 0x07, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x49, 0x44, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x07, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x49, 0x44, 0x22, 0x5b, 0x0a, 0x13,
+	0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65,
+	0x6e, 0x63, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x4e, 0x61, 0x6d, 0x65, 0x53, 0x70, 0x61, 0x63, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x4e, 0x61, 0x6d, 0x65, 0x53, 0x70, 0x61, 0x63,
+	0x65, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x54, 0x79, 0x70, 0x65, 0x22, 0xe9, 0x03, 0x0a, 0x09, 0x52, 0x75,
+	0x6c, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x5d, 0x0a, 0x12, 0x45, 0x76, 0x65, 0x72, 0x6f,
+	0x75, 0x74, 0x65, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x75, 0x6c, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x2d, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69,
+	0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76,
+	0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x75,
+	0x6c, 0x65, 0x52, 0x12, 0x45, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x50, 0x6f, 0x6c, 0x69,
+	0x63, 0x79, 0x52, 0x75, 0x6c, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x44, 0x69, 0x72, 0x65, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x54, 0x69, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x04, 0x54, 0x69, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x4d, 0x6f, 0x64, 0x65,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x5f, 0x0a, 0x0b,
+	0x52, 0x75, 0x6c, 0x65, 0x46, 0x6c, 0x6f, 0x77, 0x4d, 0x61, 0x70, 0x18, 0x05, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x3d, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e,
+	0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61,
+	0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x2e,
+	0x52, 0x75, 0x6c, 0x65, 0x46, 0x6c, 0x6f, 0x77, 0x4d, 0x61, 0x70, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x52, 0x0b, 0x52, 0x75, 0x6c, 0x65, 0x46, 0x6c, 0x6f, 0x77, 0x4d, 0x61, 0x70, 0x12, 0x68, 0x0a,
+	0x13, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x66, 0x65, 0x72,
+	0x65, 0x6e, 0x63, 0x65, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x36, 0x2e, 0x65, 0x76, 0x65,
+	0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69,
+	0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x50,
+	0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e,
+	0x63, 0x65, 0x52, 0x13, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x65,
+	0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x1a, 0x6c, 0x0a, 0x10, 0x52, 0x75, 0x6c, 0x65, 0x46,
+	0x6c, 0x6f, 0x77, 0x4d, 0x61, 0x70, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b,
+	0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x42, 0x0a,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2c, 0x2e, 0x65,
+	0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61,
+	0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31,
+	0x2e, 0x46, 0x6c, 0x6f, 0x77, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x5d, 0x0a, 0x0b, 0x52, 0x75, 0x6c, 0x65, 0x45, 0x6e, 0x74,
+	0x72, 0x69, 0x65, 0x73, 0x12, 0x4e, 0x0a, 0x0b, 0x52, 0x75, 0x6c, 0x65, 0x45, 0x6e, 0x74, 0x72,
+	0x69, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2c, 0x2e, 0x65, 0x76, 0x65, 0x72,
+	0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73,
+	0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x52, 0x75,
+	0x6c, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0b, 0x52, 0x75, 0x6c, 0x65, 0x45, 0x6e, 0x74,
+	0x72, 0x69, 0x65, 0x73, 0x22, 0x21, 0x0a, 0x09, 0x52, 0x75, 0x6c, 0x65, 0x51, 0x75, 0x65, 0x72,
+	0x79, 0x12, 0x14, 0x0a, 0x05, 0x56, 0x64, 0x73, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x56, 0x64, 0x73, 0x49, 0x44, 0x22, 0x39, 0x0a, 0x07, 0x52, 0x75, 0x6c, 0x65, 0x49,
+	0x44, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x52, 0x75, 0x6c, 0x65, 0x49, 0x44, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x07, 0x52, 0x75, 0x6c, 0x65, 0x49, 0x44, 0x73, 0x12, 0x14, 0x0a, 0x05,
+	0x56, 0x64, 0x73, 0x49, 0x44, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x56, 0x64, 0x73,
+	0x49, 0x44, 0x22, 0x39, 0x0a, 0x07, 0x46, 0x6c, 0x6f, 0x77, 0x49, 0x44, 0x73, 0x12, 0x18, 0x0a,
+	0x07, 0x46, 0x6c, 0x6f, 0x77, 0x49, 0x44, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x04, 0x52, 0x07,
+	0x46, 0x6c, 0x6f, 0x77, 0x49, 0x44, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x56, 0x64, 0x73, 0x49, 0x44,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x56, 0x64, 0x73, 0x49, 0x44, 0x22, 0x17, 0x0a,
+	0x05, 0x53, 0x76, 0x63, 0x49, 0x44, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x02, 0x49, 0x44, 0x22, 0x4d, 0x0a, 0x07, 0x53, 0x76, 0x63, 0x50, 0x6f, 0x72,
+	0x74, 0x12, 0x12, 0x0a, 0x04, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x04, 0x50, 0x6f, 0x72, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x50, 0x72, 0x6f,
+	0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x50, 0x72, 0x6f,
+	0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x22, 0x5d, 0x0a, 0x07, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64,
+	0x12, 0x0e, 0x0a, 0x02, 0x49, 0x50, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x49, 0x50,
+	0x12, 0x1a, 0x0a, 0x08, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x12, 0x12, 0x0a, 0x04,
+	0x50, 0x6f, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x50, 0x6f, 0x72, 0x74,
+	0x12, 0x12, 0x0a, 0x04, 0x4e, 0x6f, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x4e, 0x6f, 0x64, 0x65, 0x22, 0xae, 0x03, 0x0a, 0x08, 0x53, 0x76, 0x63, 0x43, 0x61, 0x63, 0x68,
+	0x65, 0x12, 0x14, 0x0a, 0x05, 0x53, 0x76, 0x63, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x53, 0x76, 0x63, 0x49, 0x44, 0x12, 0x18, 0x0a, 0x07, 0x53, 0x76, 0x63, 0x54, 0x79,
+	0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x53, 0x76, 0x63, 0x54, 0x79, 0x70,
+	0x65, 0x12, 0x1c, 0x0a, 0x09, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x49, 0x50, 0x18, 0x03,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x49, 0x50, 0x12,
+	0x40, 0x0a, 0x05, 0x50, 0x6f, 0x72, 0x74, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2a,
+	0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67,
+	0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68,
+	0x61, 0x31, 0x2e, 0x53, 0x76, 0x63, 0x50, 0x6f, 0x72, 0x74, 0x52, 0x05, 0x50, 0x6f, 0x72, 0x74,
+	0x73, 0x12, 0x28, 0x0a, 0x0f, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x41, 0x66, 0x66, 0x69,
+	0x6e, 0x69, 0x74, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x53, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x41, 0x66, 0x66, 0x69, 0x6e, 0x69, 0x74, 0x79, 0x12, 0x36, 0x0a, 0x16, 0x53,
+	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x41, 0x66, 0x66, 0x69, 0x6e, 0x69, 0x74, 0x79, 0x54, 0x69,
+	0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x16, 0x53, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x41, 0x66, 0x66, 0x69, 0x6e, 0x69, 0x74, 0x79, 0x54, 0x69, 0x6d, 0x65,
+	0x6f, 0x75, 0x74, 0x12, 0x34, 0x0a, 0x15, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x54,
+	0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x15, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x54, 0x72, 0x61, 0x66,
+	0x66, 0x69, 0x63, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x46, 0x0a, 0x08, 0x42, 0x61, 0x63,
+	0x6b, 0x65, 0x6e, 0x64, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x65, 0x76,
+	0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70,
+	0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e,
+	0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x08, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64,
+	0x73, 0x12, 0x32, 0x0a, 0x14, 0x53, 0x76, 0x63, 0x50, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x73, 0x6f,
+	0x75, 0x72, 0x63, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x09, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x14, 0x53, 0x76, 0x63, 0x50, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65,
+	0x4e, 0x61, 0x6d, 0x65, 0x73, 0x22, 0x66, 0x0a, 0x0c, 0x53, 0x76, 0x63, 0x46, 0x6c, 0x6f, 0x77,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x50, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x02, 0x49, 0x50, 0x12, 0x1a, 0x0a, 0x08, 0x50, 0x6f, 0x72, 0x74, 0x4e, 0x61, 0x6d,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x50, 0x6f, 0x72, 0x74, 0x4e, 0x61, 0x6d,
+	0x65, 0x12, 0x16, 0x0a, 0x06, 0x46, 0x6c, 0x6f, 0x77, 0x49, 0x44, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x06, 0x46, 0x6c, 0x6f, 0x77, 0x49, 0x44, 0x12, 0x12, 0x0a, 0x04, 0x49, 0x6e, 0x66,
+	0x6f, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x49, 0x6e, 0x66, 0x6f, 0x22, 0x84, 0x01,
+	0x0a, 0x10, 0x53, 0x76, 0x63, 0x44, 0x6e, 0x61, 0x74, 0x46, 0x6c, 0x6f, 0x77, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x12, 0x44, 0x0a, 0x07, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69,
+	0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76,
+	0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52,
+	0x07, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x46, 0x6c, 0x6f, 0x77,
+	0x49, 0x44, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x46, 0x6c, 0x6f, 0x77, 0x49, 0x44,
+	0x12, 0x12, 0x0a, 0x04, 0x49, 0x6e, 0x66, 0x6f, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x49, 0x6e, 0x66, 0x6f, 0x22, 0x8c, 0x02, 0x0a, 0x07, 0x53, 0x76, 0x63, 0x46, 0x6c, 0x6f, 0x77,
+	0x12, 0x49, 0x0a, 0x07, 0x4c, 0x42, 0x46, 0x6c, 0x6f, 0x77, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x2f, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e,
+	0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61,
+	0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x53, 0x76, 0x63, 0x46, 0x6c, 0x6f, 0x77, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x52, 0x07, 0x4c, 0x42, 0x46, 0x6c, 0x6f, 0x77, 0x73, 0x12, 0x63, 0x0a, 0x14, 0x53,
+	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x41, 0x66, 0x66, 0x69, 0x6e, 0x69, 0x74, 0x79, 0x46, 0x6c,
+	0x6f, 0x77, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2f, 0x2e, 0x65, 0x76, 0x65, 0x72,
+	0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73,
+	0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x53, 0x76,
+	0x63, 0x46, 0x6c, 0x6f, 0x77, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x14, 0x53, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x41, 0x66, 0x66, 0x69, 0x6e, 0x69, 0x74, 0x79, 0x46, 0x6c, 0x6f, 0x77, 0x73,
+	0x12, 0x51, 0x0a, 0x09, 0x44, 0x6e, 0x61, 0x74, 0x46, 0x6c, 0x6f, 0x77, 0x73, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x33, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69,
+	0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76,
+	0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x53, 0x76, 0x63, 0x44, 0x6e, 0x61, 0x74, 0x46,
+	0x6c, 0x6f, 0x77, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x09, 0x44, 0x6e, 0x61, 0x74, 0x46, 0x6c,
+	0x6f, 0x77, 0x73, 0x22, 0x7a, 0x0a, 0x08, 0x53, 0x76, 0x63, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x12,
+	0x1a, 0x0a, 0x08, 0x50, 0x6f, 0x72, 0x74, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x50, 0x6f, 0x72, 0x74, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x24, 0x0a, 0x0d, 0x54,
+	0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0d, 0x54, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x50, 0x6f, 0x6c, 0x69, 0x63,
+	0x79, 0x12, 0x18, 0x0a, 0x07, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x49, 0x44, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x07, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x49, 0x44, 0x12, 0x12, 0x0a, 0x04, 0x49,
+	0x6e, 0x66, 0x6f, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x49, 0x6e, 0x66, 0x6f, 0x22,
+	0xe1, 0x01, 0x0a, 0x07, 0x53, 0x76, 0x63, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x47, 0x0a, 0x08, 0x53,
+	0x76, 0x63, 0x43, 0x61, 0x63, 0x68, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2b, 0x2e,
+	0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e,
+	0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61,
+	0x31, 0x2e, 0x53, 0x76, 0x63, 0x43, 0x61, 0x63, 0x68, 0x65, 0x52, 0x08, 0x53, 0x76, 0x63, 0x43,
+	0x61, 0x63, 0x68, 0x65, 0x12, 0x44, 0x0a, 0x07, 0x53, 0x76, 0x63, 0x46, 0x6c, 0x6f, 0x77, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65,
+	0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63,
+	0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x53, 0x76, 0x63, 0x46, 0x6c, 0x6f,
+	0x77, 0x52, 0x07, 0x53, 0x76, 0x63, 0x46, 0x6c, 0x6f, 0x77, 0x12, 0x47, 0x0a, 0x08, 0x53, 0x76,
+	0x63, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2b, 0x2e, 0x65,
+	0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61,
+	0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31,
+	0x2e, 0x53, 0x76, 0x63, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x52, 0x08, 0x53, 0x76, 0x63, 0x47, 0x72,
+	0x6f, 0x75, 0x70, 0x22, 0xdd, 0x01, 0x0a, 0x0d, 0x46, 0x6c, 0x6f, 0x77, 0x44, 0x75, 0x6d, 0x70,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x4a, 0x0a, 0x09, 0x46, 0x6c, 0x6f, 0x77, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2c, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f,
+	0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e,
+	0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x46, 0x6c, 0x6f,
+	0x77, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x09, 0x46, 0x6c, 0x6f, 0x77, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x16, 0x0a, 0x06, 0x52, 0x75, 0x6c, 0x65, 0x49, 0x44, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x52, 0x75, 0x6c, 0x65, 0x49, 0x44, 0x12, 0x68, 0x0a, 0x13, 0x50, 0x6f, 0x6c,
+	0x69, 0x63, 0x79, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x36, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74,
+	0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70,
+	0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x50, 0x6f, 0x6c, 0x69, 0x63,
+	0x79, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x52, 0x13,
+	0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65,
+	0x6e, 0x63, 0x65, 0x22, 0xc0, 0x01, 0x0a, 0x0e, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x46, 0x6c,
+	0x6f, 0x77, 0x44, 0x75, 0x6d, 0x70, 0x12, 0x14, 0x0a, 0x05, 0x56, 0x64, 0x73, 0x49, 0x44, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x56, 0x64, 0x73, 0x49, 0x44, 0x12, 0x24, 0x0a, 0x0d,
+	0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x4b, 0x65, 0x79, 0x77, 0x6f, 0x72, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0d, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x4b, 0x65, 0x79, 0x77, 0x6f,
+	0x72, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x4e, 0x61, 0x6d, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x4e, 0x61,
+	0x6d, 0x65, 0x12, 0x52, 0x0a, 0x0b, 0x46, 0x6c, 0x6f, 0x77, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65,
+	0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x30, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75,
+	0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72,
+	0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x46, 0x6c, 0x6f, 0x77,
+	0x44, 0x75, 0x6d, 0x70, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0b, 0x46, 0x6c, 0x6f, 0x77, 0x45,
+	0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x22, 0x68, 0x0a, 0x09, 0x46, 0x6c, 0x6f, 0x77, 0x44, 0x75,
+	0x6d, 0x70, 0x73, 0x12, 0x5b, 0x0a, 0x0f, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x46, 0x6c, 0x6f,
+	0x77, 0x44, 0x75, 0x6d, 0x70, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x31, 0x2e, 0x65,
+	0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61,
+	0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31,
+	0x2e, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x46, 0x6c, 0x6f, 0x77, 0x44, 0x75, 0x6d, 0x70, 0x52,
+	0x0f, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x46, 0x6c, 0x6f, 0x77, 0x44, 0x75, 0x6d, 0x70, 0x73,
+	0x22, 0x6c, 0x0a, 0x0e, 0x52, 0x65, 0x61, 0x63, 0x68, 0x61, 0x62, 0x6c, 0x65, 0x51, 0x75, 0x65,
+	0x72, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x53, 0x72, 0x63, 0x49, 0x50, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x53, 0x72, 0x63, 0x49, 0x50, 0x12, 0x14, 0x0a, 0x05, 0x44, 0x73, 0x74, 0x49,
+	0x50, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x44, 0x73, 0x74, 0x49, 0x50, 0x12, 0x1a,
+	0x0a, 0x08, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x08, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x50, 0x6f,
+	0x72, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x50, 0x6f, 0x72, 0x74, 0x22, 0xf6,
+	0x01, 0x0a, 0x0c, 0x52, 0x75, 0x6c, 0x65, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x1c, 0x0a, 0x09, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x09, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a,
+	0x06, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x41,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x54, 0x69, 0x65, 0x72, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x04, 0x54, 0x69, 0x65, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x50, 0x72, 0x69,
+	0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x50, 0x72, 0x69,
+	0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x52, 0x75, 0x6c, 0x65, 0x49, 0x44, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x52, 0x75, 0x6c, 0x65, 0x49, 0x44, 0x12, 0x68, 0x0a,
+	0x13, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x66, 0x65, 0x72,
+	0x65, 0x6e, 0x63, 0x65, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x36, 0x2e, 0x65, 0x76, 0x65,
+	0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69,
+	0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x50,
+	0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e,
+	0x63, 0x65, 0x52, 0x13, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x65,
+	0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x22, 0xc3, 0x01, 0x0a, 0x0f, 0x52, 0x65, 0x61, 0x63,
+	0x68, 0x61, 0x62, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x52,
+	0x65, 0x61, 0x63, 0x68, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09,
+	0x52, 0x65, 0x61, 0x63, 0x68, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x47, 0x0a, 0x06, 0x45, 0x67, 0x72,
+	0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2f, 0x2e, 0x65, 0x76, 0x65, 0x72,
+	0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73,
+	0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x52, 0x75,
+	0x6c, 0x65, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x45, 0x67, 0x72, 0x65,
+	0x73, 0x73, 0x12, 0x49, 0x0a, 0x07, 0x49, 0x6e, 0x67, 0x72, 0x65, 0x73, 0x73, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x2f, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69,
+	0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76,
+	0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x44, 0x65, 0x63, 0x69,
+	0x73, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x49, 0x6e, 0x67, 0x72, 0x65, 0x73, 0x73, 0x22, 0x29, 0x0a,
+	0x0f, 0x4c, 0x65, 0x61, 0x6b, 0x65, 0x64, 0x46, 0x6c, 0x6f, 0x77, 0x51, 0x75, 0x65, 0x72, 0x79,
+	0x12, 0x16, 0x0a, 0x06, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x06, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x22, 0x8e, 0x01, 0x0a, 0x0a, 0x4c, 0x65, 0x61,
+	0x6b, 0x65, 0x64, 0x46, 0x6c, 0x6f, 0x77, 0x12, 0x14, 0x0a, 0x05, 0x56, 0x64, 0x73, 0x49, 0x44,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x56, 0x64, 0x73, 0x49, 0x44, 0x12, 0x1e, 0x0a,
+	0x0a, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0a, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x4a, 0x0a,
+	0x09, 0x46, 0x6c, 0x6f, 0x77, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x2c, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70,
+	0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c,
+	0x70, 0x68, 0x61, 0x31, 0x2e, 0x46, 0x6c, 0x6f, 0x77, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x09,
+	0x46, 0x6c, 0x6f, 0x77, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x22, 0x5e, 0x0a, 0x0b, 0x4c, 0x65, 0x61,
+	0x6b, 0x65, 0x64, 0x46, 0x6c, 0x6f, 0x77, 0x73, 0x12, 0x4f, 0x0a, 0x0b, 0x4c, 0x65, 0x61, 0x6b,
+	0x65, 0x64, 0x46, 0x6c, 0x6f, 0x77, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2d, 0x2e,
+	0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e,
+	0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61,
+	0x31, 0x2e, 0x4c, 0x65, 0x61, 0x6b, 0x65, 0x64, 0x46, 0x6c, 0x6f, 0x77, 0x52, 0x0b, 0x4c, 0x65,
+	0x61, 0x6b, 0x65, 0x64, 0x46, 0x6c, 0x6f, 0x77, 0x73, 0x22, 0x3f, 0x0a, 0x0f, 0x54, 0x61, 0x62,
+	0x6c, 0x65, 0x4d, 0x69, 0x73, 0x73, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05,
+	0x56, 0x64, 0x73, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x56, 0x64, 0x73,
+	0x49, 0x44, 0x12, 0x16, 0x0a, 0x06, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x3c, 0x0a, 0x12, 0x45, 0x66,
+	0x66, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x52, 0x75, 0x6c, 0x65, 0x51, 0x75, 0x65, 0x72, 0x79,
+	0x12, 0x0e, 0x0a, 0x02, 0x49, 0x50, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x49, 0x50,
+	0x12, 0x16, 0x0a, 0x06, 0x56, 0x6c, 0x61, 0x6e, 0x49, 0x44, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x06, 0x56, 0x6c, 0x61, 0x6e, 0x49, 0x44, 0x22, 0xad, 0x02, 0x0a, 0x0d, 0x45, 0x6e, 0x64,
+	0x70, 0x6f, 0x69, 0x6e, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x24, 0x0a, 0x0d, 0x49, 0x6e,
+	0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x55, 0x55, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0d, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x55, 0x55, 0x49, 0x44,
+	0x12, 0x24, 0x0a, 0x0d, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x4e, 0x61, 0x6d,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61,
+	0x63, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x49, 0x50, 0x41, 0x64, 0x64, 0x72,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x49, 0x50, 0x41, 0x64, 0x64, 0x72, 0x12, 0x1a,
+	0x0a, 0x08, 0x49, 0x50, 0x76, 0x36, 0x41, 0x64, 0x64, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x49, 0x50, 0x76, 0x36, 0x41, 0x64, 0x64, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x4d, 0x61,
+	0x63, 0x41, 0x64, 0x64, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x4d, 0x61, 0x63,
+	0x41, 0x64, 0x64, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x56, 0x6c, 0x61, 0x6e, 0x49, 0x44, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x56, 0x6c, 0x61, 0x6e, 0x49, 0x44, 0x12, 0x16, 0x0a, 0x06,
+	0x4f, 0x66, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x4f, 0x66,
+	0x50, 0x6f, 0x72, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x4e, 0x61,
+	0x6d, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65,
+	0x4e, 0x61, 0x6d, 0x65, 0x12, 0x32, 0x0a, 0x14, 0x49, 0x50, 0x41, 0x64, 0x64, 0x72, 0x4c, 0x61,
+	0x73, 0x74, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x09, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x14, 0x49, 0x50, 0x41, 0x64, 0x64, 0x72, 0x4c, 0x61, 0x73, 0x74, 0x55, 0x70,
+	0x64, 0x61, 0x74, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x22, 0x5b, 0x0a, 0x09, 0x45, 0x6e, 0x64, 0x70,
+	0x6f, 0x69, 0x6e, 0x74, 0x73, 0x12, 0x4e, 0x0a, 0x09, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e,
+	0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x30, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f,
+	0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e,
+	0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x45, 0x6e, 0x64,
+	0x70, 0x6f, 0x69, 0x6e, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x09, 0x45, 0x6e, 0x64, 0x70,
+	0x6f, 0x69, 0x6e, 0x74, 0x73, 0x22, 0x39, 0x0a, 0x11, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e,
+	0x74, 0x49, 0x50, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x12, 0x24, 0x0a, 0x0d, 0x49, 0x6e,
+	0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x55, 0x55, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0d, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x55, 0x55, 0x49, 0x44,
+	0x22, 0x6f, 0x0a, 0x17, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x49, 0x50, 0x52, 0x65,
+	0x66, 0x72, 0x65, 0x73, 0x68, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x24, 0x0a, 0x0d, 0x49,
+	0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x55, 0x55, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0d, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x55, 0x55, 0x49,
+	0x44, 0x12, 0x18, 0x0a, 0x07, 0x53, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x07, 0x53, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x45,
+	0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x45, 0x72, 0x72, 0x6f,
+	0x72, 0x22, 0x70, 0x0a, 0x18, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x49, 0x50, 0x52,
+	0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x12, 0x54, 0x0a,
+	0x07, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x3a,
+	0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67,
+	0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68,
+	0x61, 0x31, 0x2e, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x49, 0x50, 0x52, 0x65, 0x66,
+	0x72, 0x65, 0x73, 0x68, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x07, 0x52, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x73, 0x22, 0xde, 0x01, 0x0a, 0x0c, 0x52, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x53, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x56, 0x64, 0x73, 0x49, 0x44, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x56, 0x64, 0x73, 0x49, 0x44, 0x12, 0x24, 0x0a, 0x0d, 0x52, 0x65,
+	0x70, 0x6c, 0x61, 0x79, 0x65, 0x64, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x0d, 0x52, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x65, 0x64, 0x52, 0x75, 0x6c, 0x65, 0x73,
+	0x12, 0x1e, 0x0a, 0x0a, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x52, 0x75, 0x6c, 0x65, 0x73,
+	0x12, 0x1e, 0x0a, 0x0a, 0x49, 0x6e, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x49, 0x6e, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73,
+	0x12, 0x1c, 0x0a, 0x09, 0x53, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x09, 0x53, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x1e,
+	0x0a, 0x0a, 0x46, 0x69, 0x6e, 0x69, 0x73, 0x68, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0a, 0x46, 0x69, 0x6e, 0x69, 0x73, 0x68, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x14,
+	0x0a, 0x05, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x45,
+	0x72, 0x72, 0x6f, 0x72, 0x22, 0x69, 0x0a, 0x0e, 0x52, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x53, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x65, 0x73, 0x12, 0x57, 0x0a, 0x0e, 0x52, 0x65, 0x70, 0x6c, 0x61, 0x79,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2f,
+	0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67,
+	0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68,
+	0x61, 0x31, 0x2e, 0x52, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52,
+	0x0e, 0x52, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x65, 0x73, 0x22,
+	0x39, 0x0a, 0x09, 0x52, 0x75, 0x6c, 0x65, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x12, 0x0a, 0x04,
+	0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x4e, 0x61, 0x6d, 0x65,
+	0x12, 0x18, 0x0a, 0x07, 0x45, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x07, 0x45, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x22, 0xa6, 0x01, 0x0a, 0x0c, 0x52,
+	0x75, 0x6c, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63, 0x74, 0x12, 0x48, 0x0a, 0x08, 0x53,
+	0x68, 0x61, 0x64, 0x6f, 0x77, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2c, 0x2e,
+	0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e,
+	0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61,
+	0x31, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x53, 0x68, 0x61,
+	0x64, 0x6f, 0x77, 0x65, 0x64, 0x12, 0x4c, 0x0a, 0x0a, 0x53, 0x68, 0x61, 0x64, 0x6f, 0x77, 0x65,
+	0x64, 0x42, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2c, 0x2e, 0x65, 0x76, 0x65, 0x72,
+	0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73,
+	0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x52, 0x75,
+	0x6c, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0a, 0x53, 0x68, 0x61, 0x64, 0x6f, 0x77, 0x65,
+	0x64, 0x42, 0x79, 0x22, 0x66, 0x0a, 0x0d, 0x52, 0x75, 0x6c, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x6c,
+	0x69, 0x63, 0x74, 0x73, 0x12, 0x55, 0x0a, 0x0d, 0x52, 0x75, 0x6c, 0x65, 0x43, 0x6f, 0x6e, 0x66,
+	0x6c, 0x69, 0x63, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2f, 0x2e, 0x65, 0x76,
+	0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70,
+	0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e,
+	0x52, 0x75, 0x6c, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63, 0x74, 0x52, 0x0d, 0x52, 0x75,
+	0x6c, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63, 0x74, 0x73, 0x22, 0x57, 0x0a, 0x0f, 0x52,
+	0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x64, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x12, 0x10,
+	0x0a, 0x03, 0x4b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x4b, 0x65, 0x79,
+	0x12, 0x16, 0x0a, 0x06, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x06, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x41, 0x6e, 0x79, 0x56,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x41, 0x6e, 0x79, 0x56,
+	0x61, 0x6c, 0x75, 0x65, 0x22, 0x5e, 0x0a, 0x10, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63,
+	0x65, 0x64, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x12, 0x4a, 0x0a, 0x06, 0x4c, 0x61, 0x62, 0x65,
+	0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x32, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f,
+	0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e,
+	0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x52, 0x65, 0x66,
+	0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x64, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x52, 0x06, 0x4c, 0x61,
+	0x62, 0x65, 0x6c, 0x73, 0x22, 0x92, 0x02, 0x0a, 0x0e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x44,
+	0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x1e, 0x0a, 0x0a, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x4e,
+	0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x42, 0x72, 0x69, 0x64, 0x67,
+	0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x53, 0x72, 0x63, 0x49, 0x50, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x53, 0x72, 0x63, 0x49, 0x50, 0x12, 0x14, 0x0a, 0x05, 0x44,
+	0x73, 0x74, 0x49, 0x50, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x44, 0x73, 0x74, 0x49,
+	0x50, 0x12, 0x1a, 0x0a, 0x08, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x08, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x12, 0x18, 0x0a,
+	0x07, 0x53, 0x72, 0x63, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07,
+	0x53, 0x72, 0x63, 0x50, 0x6f, 0x72, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x44, 0x73, 0x74, 0x50, 0x6f,
+	0x72, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x44, 0x73, 0x74, 0x50, 0x6f, 0x72,
+	0x74, 0x12, 0x16, 0x0a, 0x06, 0x46, 0x6c, 0x6f, 0x77, 0x49, 0x44, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x06, 0x46, 0x6c, 0x6f, 0x77, 0x49, 0x44, 0x12, 0x16, 0x0a, 0x06, 0x52, 0x75, 0x6c,
+	0x65, 0x49, 0x44, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x52, 0x75, 0x6c, 0x65, 0x49,
+	0x44, 0x12, 0x16, 0x0a, 0x06, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x62, 0x0a, 0x0f, 0x50, 0x6f, 0x6c,
+	0x69, 0x63, 0x79, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x4f, 0x0a, 0x09,
+	0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x31, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b,
+	0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70,
+	0x68, 0x61, 0x31, 0x2e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69,
+	0x6f, 0x6e, 0x52, 0x09, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x85, 0x01,
+	0x0a, 0x09, 0x54, 0x69, 0x65, 0x72, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x44,
+	0x69, 0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09,
+	0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x54, 0x69, 0x65,
+	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x54, 0x69, 0x65, 0x72, 0x12, 0x12, 0x0a,
+	0x04, 0x4d, 0x6f, 0x64, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x4d, 0x6f, 0x64,
+	0x65, 0x12, 0x14, 0x0a, 0x05, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x05, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x4e, 0x65, 0x78, 0x74, 0x54,
+	0x61, 0x62, 0x6c, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x4e, 0x65, 0x78, 0x74,
+	0x54, 0x61, 0x62, 0x6c, 0x65, 0x22, 0x51, 0x0a, 0x0b, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x53,
+	0x74, 0x61, 0x67, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x12, 0x14, 0x0a, 0x05,
+	0x53, 0x74, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x53, 0x74, 0x61,
+	0x67, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x05, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x22, 0xaf, 0x01, 0x0a, 0x0b, 0x54, 0x61, 0x62,
+	0x6c, 0x65, 0x4c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x12, 0x4c, 0x0a, 0x0a, 0x54, 0x69, 0x65, 0x72,
+	0x54, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2c, 0x2e, 0x65,
+	0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61,
+	0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31,
+	0x2e, 0x54, 0x69, 0x65, 0x72, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x52, 0x0a, 0x54, 0x69, 0x65, 0x72,
+	0x54, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x12, 0x52, 0x0a, 0x0c, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65,
+	0x53, 0x74, 0x61, 0x67, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2e, 0x2e, 0x65,
+	0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61,
+	0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31,
+	0x2e, 0x42, 0x72, 0x69, 0x64, 0x67, 0x65, 0x53, 0x74, 0x61, 0x67, 0x65, 0x52, 0x0c, 0x42, 0x72,
+	0x69, 0x64, 0x67, 0x65, 0x53, 0x74, 0x61, 0x67, 0x65, 0x73, 0x32, 0xc8, 0x0b, 0x0a, 0x06, 0x47,
+	0x65, 0x74, 0x74, 0x65, 0x72, 0x12, 0x6d, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x41, 0x6c, 0x6c, 0x52,
+	0x75, 0x6c, 0x65, 0x73, 0x12, 0x2c, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f,
+	0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e,
+	0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x51, 0x75, 0x65,
+	0x72, 0x79, 0x1a, 0x2e, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f,
+	0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31,
+	0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x69,
+	0x65, 0x73, 0x22, 0x00, 0x12, 0x6e, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x52, 0x75, 0x6c, 0x65, 0x73,
+	0x42, 0x79, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x2a, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74,
+	0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70,
+	0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x49,
+	0x44, 0x73, 0x1a, 0x2e, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f,
+	0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31,
+	0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x69,
+	0x65, 0x73, 0x22, 0x00, 0x12, 0x6e, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x52, 0x75, 0x6c, 0x65, 0x73,
+	0x42, 0x79, 0x46, 0x6c, 0x6f, 0x77, 0x12, 0x2a, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74,
+	0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70,
+	0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x46, 0x6c, 0x6f, 0x77, 0x49,
+	0x44, 0x73, 0x1a, 0x2e, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f,
+	0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31,
+	0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x69,
+	0x65, 0x73, 0x22, 0x00, 0x12, 0x6b, 0x0a, 0x11, 0x47, 0x65, 0x74, 0x53, 0x76, 0x63, 0x49, 0x6e,
+	0x66, 0x6f, 0x42, 0x79, 0x53, 0x76, 0x63, 0x49, 0x44, 0x12, 0x28, 0x2e, 0x65, 0x76, 0x65, 0x72,
+	0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73,
+	0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x53, 0x76,
+	0x63, 0x49, 0x44, 0x1a, 0x2a, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69,
+	0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76,
+	0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x53, 0x76, 0x63, 0x49, 0x6e, 0x66, 0x6f, 0x22,
+	0x00, 0x12, 0x53, 0x0a, 0x09, 0x44, 0x75, 0x6d, 0x70, 0x46, 0x6c, 0x6f, 0x77, 0x73, 0x12, 0x16,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x2c, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74,
+	0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70,
+	0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x46, 0x6c, 0x6f, 0x77, 0x44,
+	0x75, 0x6d, 0x70, 0x73, 0x22, 0x00, 0x12, 0x79, 0x0a, 0x0e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x52,
+	0x65, 0x61, 0x63, 0x68, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x31, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f,
+	0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e,
+	0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x52, 0x65, 0x61,
+	0x63, 0x68, 0x61, 0x62, 0x6c, 0x65, 0x51, 0x75, 0x65, 0x72, 0x79, 0x1a, 0x32, 0x2e, 0x65, 0x76,
+	0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70,
+	0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e,
+	0x52, 0x65, 0x61, 0x63, 0x68, 0x61, 0x62, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22,
+	0x00, 0x12, 0x76, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x4c, 0x65, 0x61, 0x6b, 0x65, 0x64, 0x46, 0x6c,
+	0x6f, 0x77, 0x73, 0x12, 0x32, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69,
+	0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76,
+	0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x4c, 0x65, 0x61, 0x6b, 0x65, 0x64, 0x46, 0x6c,
+	0x6f, 0x77, 0x51, 0x75, 0x65, 0x72, 0x79, 0x1a, 0x2e, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75,
+	0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72,
+	0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x4c, 0x65, 0x61, 0x6b,
+	0x65, 0x64, 0x46, 0x6c, 0x6f, 0x77, 0x73, 0x22, 0x00, 0x12, 0x7c, 0x0a, 0x11, 0x47, 0x65, 0x74,
+	0x45, 0x66, 0x66, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x12, 0x35,
+	0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67,
+	0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68,
+	0x61, 0x31, 0x2e, 0x45, 0x66, 0x66, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x52, 0x75, 0x6c, 0x65,
+	0x51, 0x75, 0x65, 0x72, 0x79, 0x1a, 0x2e, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65,
+	0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63,
+	0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x45, 0x6e,
+	0x74, 0x72, 0x69, 0x65, 0x73, 0x22, 0x00, 0x12, 0x56, 0x0a, 0x0c, 0x47, 0x65, 0x74, 0x45, 0x6e,
+	0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a,
+	0x2c, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b,
+	0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70,
+	0x68, 0x61, 0x31, 0x2e, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x22, 0x00, 0x12,
+	0x5e, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x31, 0x2e, 0x65, 0x76, 0x65,
+	0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69,
+	0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x52,
+	0x65, 0x70, 0x6c, 0x61, 0x79, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x65, 0x73, 0x22, 0x00, 0x12,
+	0x5e, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x52, 0x75, 0x6c, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x6c, 0x69,
+	0x63, 0x74, 0x73, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x30, 0x2e, 0x65, 0x76,
+	0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70,
+	0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e,
+	0x52, 0x75, 0x6c, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63, 0x74, 0x73, 0x22, 0x00, 0x12,
+	0x64, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x64,
+	0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x33,
+	0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67,
+	0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68,
+	0x61, 0x31, 0x2e, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x64, 0x4c, 0x61, 0x62,
+	0x65, 0x6c, 0x73, 0x22, 0x00, 0x12, 0x62, 0x0a, 0x12, 0x47, 0x65, 0x74, 0x50, 0x6f, 0x6c, 0x69,
+	0x63, 0x79, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x16, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d,
+	0x70, 0x74, 0x79, 0x1a, 0x32, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69,
+	0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76,
+	0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x44, 0x65,
+	0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x00, 0x12, 0x5a, 0x0a, 0x0e, 0x47, 0x65, 0x74,
+	0x54, 0x61, 0x62, 0x6c, 0x65, 0x4c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x12, 0x16, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d,
+	0x70, 0x74, 0x79, 0x1a, 0x2e, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69,
+	0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76,
+	0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x4c, 0x61, 0x79,
+	0x6f, 0x75, 0x74, 0x22, 0x00, 0x32, 0xce, 0x02, 0x0a, 0x05, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x12,
+	0x62, 0x0a, 0x12, 0x53, 0x65, 0x74, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x4d, 0x69, 0x73, 0x73, 0x41,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x32, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65,
+	0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63,
+	0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x4d,
+	0x69, 0x73, 0x73, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74,
+	0x79, 0x22, 0x00, 0x12, 0x56, 0x0a, 0x0c, 0x53, 0x65, 0x74, 0x52, 0x75, 0x6c, 0x65, 0x47, 0x72,
+	0x6f, 0x75, 0x70, 0x12, 0x2c, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69,
+	0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76,
+	0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x47, 0x72, 0x6f, 0x75,
+	0x70, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x88, 0x01, 0x0a, 0x11,
+	0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x49,
+	0x50, 0x12, 0x34, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e,
+	0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x76, 0x31, 0x61,
+	0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x49, 0x50,
+	0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x1a, 0x3b, 0x2e, 0x65, 0x76, 0x65, 0x72, 0x6f, 0x75,
+	0x74, 0x65, 0x5f, 0x69, 0x6f, 0x2e, 0x70, 0x6b, 0x67, 0x2e, 0x61, 0x70, 0x69, 0x73, 0x2e, 0x72,
+	0x70, 0x63, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x45, 0x6e, 0x64, 0x70,
+	0x6f, 0x69, 0x6e, 0x74, 0x49, 0x50, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x52, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x73, 0x22, 0x00, 0x42, 0x17, 0x5a, 0x15, 0x70, 0x6b, 0x67, 0x2f, 0x61, 0x70,
+	0x69, 0x73, 0x2f, 0x72, 0x70, 0x63, 0x2f, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x62,
+	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescOnce sync.Once
+	file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescData = file_pkg_apis_rpc_v1alpha1_rule_proto_rawDesc
+)
+
+func file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescGZIP() []byte {
+	file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescOnce.Do(func() {
+		file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescData = protoimpl.X.CompressGZIP(file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescData)
+	})
+	return file_pkg_apis_rpc_v1alpha1_rule_proto_rawDescData
+}
+
+var file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes = make([]protoimpl.MessageInfo, 46)
+var file_pkg_apis_rpc_v1alpha1_rule_proto_goTypes = []interface{}{
+	(*PolicyRule)(nil),               // 0: everoute_io.pkg.apis.rpc.v1alpha1.PolicyRule
+	(*FlowEntry)(nil),                // 1: everoute_io.pkg.apis.rpc.v1alpha1.FlowEntry
+	(*PolicyRuleReference)(nil),      // 2: everoute_io.pkg.apis.rpc.v1alpha1.PolicyRuleReference
+	(*RuleEntry)(nil),                // 3: everoute_io.pkg.apis.rpc.v1alpha1.RuleEntry
+	(*RuleEntries)(nil),              // 4: everoute_io.pkg.apis.rpc.v1alpha1.RuleEntries
+	(*RuleQuery)(nil),                // 5: everoute_io.pkg.apis.rpc.v1alpha1.RuleQuery
+	(*RuleIDs)(nil),                  // 6: everoute_io.pkg.apis.rpc.v1alpha1.RuleIDs
+	(*FlowIDs)(nil),                  // 7: everoute_io.pkg.apis.rpc.v1alpha1.FlowIDs
+	(*SvcID)(nil),                    // 8: everoute_io.pkg.apis.rpc.v1alpha1.SvcID
+	(*SvcPort)(nil),                  // 9: everoute_io.pkg.apis.rpc.v1alpha1.SvcPort
+	(*Backend)(nil),                  // 10: everoute_io.pkg.apis.rpc.v1alpha1.Backend
+	(*SvcCache)(nil),                 // 11: everoute_io.pkg.apis.rpc.v1alpha1.SvcCache
+	(*SvcFlowEntry)(nil),             // 12: everoute_io.pkg.apis.rpc.v1alpha1.SvcFlowEntry
+	(*SvcDnatFlowEntry)(nil),         // 13: everoute_io.pkg.apis.rpc.v1alpha1.SvcDnatFlowEntry
+	(*SvcFlow)(nil),                  // 14: everoute_io.pkg.apis.rpc.v1alpha1.SvcFlow
+	(*SvcGroup)(nil),                 // 15: everoute_io.pkg.apis.rpc.v1alpha1.SvcGroup
+	(*SvcInfo)(nil),                  // 16: everoute_io.pkg.apis.rpc.v1alpha1.SvcInfo
+	(*FlowDumpEntry)(nil),            // 17: everoute_io.pkg.apis.rpc.v1alpha1.FlowDumpEntry
+	(*BridgeFlowDump)(nil),           // 18: everoute_io.pkg.apis.rpc.v1alpha1.BridgeFlowDump
+	(*FlowDumps)(nil),                // 19: everoute_io.pkg.apis.rpc.v1alpha1.FlowDumps
+	(*ReachableQuery)(nil),           // 20: everoute_io.pkg.apis.rpc.v1alpha1.ReachableQuery
+	(*RuleDecision)(nil),             // 21: everoute_io.pkg.apis.rpc.v1alpha1.RuleDecision
+	(*ReachableResult)(nil),          // 22: everoute_io.pkg.apis.rpc.v1alpha1.ReachableResult
+	(*LeakedFlowQuery)(nil),          // 23: everoute_io.pkg.apis.rpc.v1alpha1.LeakedFlowQuery
+	(*LeakedFlow)(nil),               // 24: everoute_io.pkg.apis.rpc.v1alpha1.LeakedFlow
+	(*LeakedFlows)(nil),              // 25: everoute_io.pkg.apis.rpc.v1alpha1.LeakedFlows
+	(*TableMissAction)(nil),          // 26: everoute_io.pkg.apis.rpc.v1alpha1.TableMissAction
+	(*EffectiveRuleQuery)(nil),       // 27: everoute_io.pkg.apis.rpc.v1alpha1.EffectiveRuleQuery
+	(*EndpointEntry)(nil),            // 28: everoute_io.pkg.apis.rpc.v1alpha1.EndpointEntry
+	(*Endpoints)(nil),                // 29: everoute_io.pkg.apis.rpc.v1alpha1.Endpoints
+	(*EndpointIPRefresh)(nil),        // 30: everoute_io.pkg.apis.rpc.v1alpha1.EndpointIPRefresh
+	(*EndpointIPRefreshResult)(nil),  // 31: everoute_io.pkg.apis.rpc.v1alpha1.EndpointIPRefreshResult
+	(*EndpointIPRefreshResults)(nil), // 32: everoute_io.pkg.apis.rpc.v1alpha1.EndpointIPRefreshResults
+	(*ReplayStatus)(nil),             // 33: everoute_io.pkg.apis.rpc.v1alpha1.ReplayStatus
+	(*ReplayStatuses)(nil),           // 34: everoute_io.pkg.apis.rpc.v1alpha1.ReplayStatuses
+	(*RuleGroup)(nil),                // 35: everoute_io.pkg.apis.rpc.v1alpha1.RuleGroup
+	(*RuleConflict)(nil),             // 36: everoute_io.pkg.apis.rpc.v1alpha1.RuleConflict
+	(*RuleConflicts)(nil),            // 37: everoute_io.pkg.apis.rpc.v1alpha1.RuleConflicts
+	(*ReferencedLabel)(nil),          // 38: everoute_io.pkg.apis.rpc.v1alpha1.ReferencedLabel
+	(*ReferencedLabels)(nil),         // 39: everoute_io.pkg.apis.rpc.v1alpha1.ReferencedLabels
+	(*PolicyDecision)(nil),           // 40: everoute_io.pkg.apis.rpc.v1alpha1.PolicyDecision
+	(*PolicyDecisions)(nil),          // 41: everoute_io.pkg.apis.rpc.v1alpha1.PolicyDecisions
+	(*TierTable)(nil),                // 42: everoute_io.pkg.apis.rpc.v1alpha1.TierTable
+	(*BridgeStage)(nil),              // 43: everoute_io.pkg.apis.rpc.v1alpha1.BridgeStage
+	(*TableLayout)(nil),              // 44: everoute_io.pkg.apis.rpc.v1alpha1.TableLayout
+	nil,                              // 45: everoute_io.pkg.apis.rpc.v1alpha1.RuleEntry.RuleFlowMapEntry
+	(*emptypb.Empty)(nil),            // 46: google.protobuf.Empty
+}
+var file_pkg_apis_rpc_v1alpha1_rule_proto_depIdxs = []int32{
+	0,  // 0: everoute_io.pkg.apis.rpc.v1alpha1.RuleEntry.EveroutePolicyRule:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.PolicyRule
+	45, // 1: everoute_io.pkg.apis.rpc.v1alpha1.RuleEntry.RuleFlowMap:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.RuleEntry.RuleFlowMapEntry
+	2,  // 2: everoute_io.pkg.apis.rpc.v1alpha1.RuleEntry.PolicyRuleReference:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.PolicyRuleReference
+	3,  // 3: everoute_io.pkg.apis.rpc.v1alpha1.RuleEntries.RuleEntries:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.RuleEntry
+	9,  // 4: everoute_io.pkg.apis.rpc.v1alpha1.SvcCache.Ports:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.SvcPort
+	10, // 5: everoute_io.pkg.apis.rpc.v1alpha1.SvcCache.Backends:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.Backend
+	10, // 6: everoute_io.pkg.apis.rpc.v1alpha1.SvcDnatFlowEntry.Backend:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.Backend
+	12, // 7: everoute_io.pkg.apis.rpc.v1alpha1.SvcFlow.LBFlows:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.SvcFlowEntry
+	12, // 8: everoute_io.pkg.apis.rpc.v1alpha1.SvcFlow.SessionAffinityFlows:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.SvcFlowEntry
+	13, // 9: everoute_io.pkg.apis.rpc.v1alpha1.SvcFlow.DnatFlows:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.SvcDnatFlowEntry
+	11, // 10: everoute_io.pkg.apis.rpc.v1alpha1.SvcInfo.SvcCache:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.SvcCache
+	14, // 11: everoute_io.pkg.apis.rpc.v1alpha1.SvcInfo.SvcFlow:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.SvcFlow
+	15, // 12: everoute_io.pkg.apis.rpc.v1alpha1.SvcInfo.SvcGroup:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.SvcGroup
+	1,  // 13: everoute_io.pkg.apis.rpc.v1alpha1.FlowDumpEntry.FlowEntry:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.FlowEntry
+	2,  // 14: everoute_io.pkg.apis.rpc.v1alpha1.FlowDumpEntry.PolicyRuleReference:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.PolicyRuleReference
+	17, // 15: everoute_io.pkg.apis.rpc.v1alpha1.BridgeFlowDump.FlowEntries:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.FlowDumpEntry
+	18, // 16: everoute_io.pkg.apis.rpc.v1alpha1.FlowDumps.BridgeFlowDumps:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.BridgeFlowDump
+	2,  // 17: everoute_io.pkg.apis.rpc.v1alpha1.RuleDecision.PolicyRuleReference:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.PolicyRuleReference
+	21, // 18: everoute_io.pkg.apis.rpc.v1alpha1.ReachableResult.Egress:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.RuleDecision
+	21, // 19: everoute_io.pkg.apis.rpc.v1alpha1.ReachableResult.Ingress:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.RuleDecision
+	1,  // 20: everoute_io.pkg.apis.rpc.v1alpha1.LeakedFlow.FlowEntry:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.FlowEntry
+	24, // 21: everoute_io.pkg.apis.rpc.v1alpha1.LeakedFlows.LeakedFlows:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.LeakedFlow
+	28, // 22: everoute_io.pkg.apis.rpc.v1alpha1.Endpoints.Endpoints:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.EndpointEntry
+	31, // 23: everoute_io.pkg.apis.rpc.v1alpha1.EndpointIPRefreshResults.Results:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.EndpointIPRefreshResult
+	33, // 24: everoute_io.pkg.apis.rpc.v1alpha1.ReplayStatuses.ReplayStatuses:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.ReplayStatus
+	3,  // 25: everoute_io.pkg.apis.rpc.v1alpha1.RuleConflict.Shadowed:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.RuleEntry
+	3,  // 26: everoute_io.pkg.apis.rpc.v1alpha1.RuleConflict.ShadowedBy:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.RuleEntry
+	36, // 27: everoute_io.pkg.apis.rpc.v1alpha1.RuleConflicts.RuleConflicts:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.RuleConflict
+	38, // 28: everoute_io.pkg.apis.rpc.v1alpha1.ReferencedLabels.Labels:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.ReferencedLabel
+	40, // 29: everoute_io.pkg.apis.rpc.v1alpha1.PolicyDecisions.Decisions:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.PolicyDecision
+	42, // 30: everoute_io.pkg.apis.rpc.v1alpha1.TableLayout.TierTables:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.TierTable
+	43, // 31: everoute_io.pkg.apis.rpc.v1alpha1.TableLayout.BridgeStages:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.BridgeStage
+	1,  // 32: everoute_io.pkg.apis.rpc.v1alpha1.RuleEntry.RuleFlowMapEntry.value:type_name -> everoute_io.pkg.apis.rpc.v1alpha1.FlowEntry
+	5,  // 33: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetAllRules:input_type -> everoute_io.pkg.apis.rpc.v1alpha1.RuleQuery
+	6,  // 34: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetRulesByName:input_type -> everoute_io.pkg.apis.rpc.v1alpha1.RuleIDs
+	7,  // 35: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetRulesByFlow:input_type -> everoute_io.pkg.apis.rpc.v1alpha1.FlowIDs
+	8,  // 36: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetSvcInfoBySvcID:input_type -> everoute_io.pkg.apis.rpc.v1alpha1.SvcID
+	46, // 37: everoute_io.pkg.apis.rpc.v1alpha1.Getter.DumpFlows:input_type -> google.protobuf.Empty
+	20, // 38: everoute_io.pkg.apis.rpc.v1alpha1.Getter.QueryReachable:input_type -> everoute_io.pkg.apis.rpc.v1alpha1.ReachableQuery
+	23, // 39: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetLeakedFlows:input_type -> everoute_io.pkg.apis.rpc.v1alpha1.LeakedFlowQuery
+	27, // 40: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetEffectiveRules:input_type -> everoute_io.pkg.apis.rpc.v1alpha1.EffectiveRuleQuery
+	46, // 41: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetEndpoints:input_type -> google.protobuf.Empty
+	46, // 42: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetReplayStatus:input_type -> google.protobuf.Empty
+	46, // 43: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetRuleConflicts:input_type -> google.protobuf.Empty
+	46, // 44: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetReferencedLabels:input_type -> google.protobuf.Empty
+	46, // 45: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetPolicyDecisions:input_type -> google.protobuf.Empty
+	46, // 46: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetTableLayout:input_type -> google.protobuf.Empty
+	26, // 47: everoute_io.pkg.apis.rpc.v1alpha1.Admin.SetTableMissAction:input_type -> everoute_io.pkg.apis.rpc.v1alpha1.TableMissAction
+	35, // 48: everoute_io.pkg.apis.rpc.v1alpha1.Admin.SetRuleGroup:input_type -> everoute_io.pkg.apis.rpc.v1alpha1.RuleGroup
+	30, // 49: everoute_io.pkg.apis.rpc.v1alpha1.Admin.RefreshEndpointIP:input_type -> everoute_io.pkg.apis.rpc.v1alpha1.EndpointIPRefresh
+	4,  // 50: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetAllRules:output_type -> everoute_io.pkg.apis.rpc.v1alpha1.RuleEntries
+	4,  // 51: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetRulesByName:output_type -> everoute_io.pkg.apis.rpc.v1alpha1.RuleEntries
+	4,  // 52: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetRulesByFlow:output_type -> everoute_io.pkg.apis.rpc.v1alpha1.RuleEntries
+	16, // 53: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetSvcInfoBySvcID:output_type -> everoute_io.pkg.apis.rpc.v1alpha1.SvcInfo
+	19, // 54: everoute_io.pkg.apis.rpc.v1alpha1.Getter.DumpFlows:output_type -> everoute_io.pkg.apis.rpc.v1alpha1.FlowDumps
+	22, // 55: everoute_io.pkg.apis.rpc.v1alpha1.Getter.QueryReachable:output_type -> everoute_io.pkg.apis.rpc.v1alpha1.ReachableResult
+	25, // 56: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetLeakedFlows:output_type -> everoute_io.pkg.apis.rpc.v1alpha1.LeakedFlows
+	4,  // 57: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetEffectiveRules:output_type -> everoute_io.pkg.apis.rpc.v1alpha1.RuleEntries
+	29, // 58: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetEndpoints:output_type -> everoute_io.pkg.apis.rpc.v1alpha1.Endpoints
+	34, // 59: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetReplayStatus:output_type -> everoute_io.pkg.apis.rpc.v1alpha1.ReplayStatuses
+	37, // 60: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetRuleConflicts:output_type -> everoute_io.pkg.apis.rpc.v1alpha1.RuleConflicts
+	39, // 61: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetReferencedLabels:output_type -> everoute_io.pkg.apis.rpc.v1alpha1.ReferencedLabels
+	41, // 62: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetPolicyDecisions:output_type -> everoute_io.pkg.apis.rpc.v1alpha1.PolicyDecisions
+	44, // 63: everoute_io.pkg.apis.rpc.v1alpha1.Getter.GetTableLayout:output_type -> everoute_io.pkg.apis.rpc.v1alpha1.TableLayout
+	46, // 64: everoute_io.pkg.apis.rpc.v1alpha1.Admin.SetTableMissAction:output_type -> google.protobuf.Empty
+	46, // 65: everoute_io.pkg.apis.rpc.v1alpha1.Admin.SetRuleGroup:output_type -> google.protobuf.Empty
+	32, // 66: everoute_io.pkg.apis.rpc.v1alpha1.Admin.RefreshEndpointIP:output_type -> everoute_io.pkg.apis.rpc.v1alpha1.EndpointIPRefreshResults
+	50, // [50:67] is the sub-list for method output_type
+	33, // [33:50] is the sub-list for method input_type
+	33, // [33:33] is the sub-list for extension type_name
+	33, // [33:33] is the sub-list for extension extendee
+	0,  // [0:33] is the sub-list for field type_name
+}
+
+func init() { file_pkg_apis_rpc_v1alpha1_rule_proto_init() }
+func file_pkg_apis_rpc_v1alpha1_rule_proto_init() {
+	if File_pkg_apis_rpc_v1alpha1_rule_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PolicyRule); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
 		}
 		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*FlowEntry); i {
@@ -1411,8 +3643,164 @@ func file_pkg_apis_rpc_v1alpha1_rule_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*PolicyRuleReference); i {
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PolicyRuleReference); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RuleEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RuleEntries); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RuleQuery); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RuleIDs); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FlowIDs); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SvcID); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SvcPort); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Backend); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SvcCache); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SvcFlowEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SvcDnatFlowEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SvcFlow); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SvcGroup); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1423,8 +3811,8 @@ func file_pkg_apis_rpc_v1alpha1_rule_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RuleEntry); i {
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SvcInfo); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1435,8 +3823,8 @@ func file_pkg_apis_rpc_v1alpha1_rule_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RuleEntries); i {
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FlowDumpEntry); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1447,8 +3835,8 @@ func file_pkg_apis_rpc_v1alpha1_rule_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RuleIDs); i {
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BridgeFlowDump); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1459,8 +3847,8 @@ func file_pkg_apis_rpc_v1alpha1_rule_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*FlowIDs); i {
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FlowDumps); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1471,8 +3859,8 @@ func file_pkg_apis_rpc_v1alpha1_rule_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SvcID); i {
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReachableQuery); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1483,8 +3871,8 @@ func file_pkg_apis_rpc_v1alpha1_rule_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SvcPort); i {
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RuleDecision); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1495,8 +3883,8 @@ func file_pkg_apis_rpc_v1alpha1_rule_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Backend); i {
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReachableResult); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1507,8 +3895,8 @@ func file_pkg_apis_rpc_v1alpha1_rule_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SvcCache); i {
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LeakedFlowQuery); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1519,8 +3907,8 @@ func file_pkg_apis_rpc_v1alpha1_rule_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SvcFlowEntry); i {
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LeakedFlow); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1531,8 +3919,8 @@ func file_pkg_apis_rpc_v1alpha1_rule_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SvcDnatFlowEntry); i {
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LeakedFlows); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1543,8 +3931,8 @@ func file_pkg_apis_rpc_v1alpha1_rule_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SvcFlow); i {
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TableMissAction); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1555,8 +3943,8 @@ func file_pkg_apis_rpc_v1alpha1_rule_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SvcGroup); i {
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EffectiveRuleQuery); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1567,8 +3955,200 @@ func file_pkg_apis_rpc_v1alpha1_rule_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SvcInfo); i {
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EndpointEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Endpoints); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EndpointIPRefresh); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EndpointIPRefreshResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EndpointIPRefreshResults); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReplayStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReplayStatuses); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[35].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RuleGroup); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[36].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RuleConflict); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[37].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RuleConflicts); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[38].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReferencedLabel); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[39].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReferencedLabels); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[40].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PolicyDecision); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[41].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PolicyDecisions); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[42].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TierTable); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[43].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BridgeStage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_rpc_v1alpha1_rule_proto_msgTypes[44].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TableLayout); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1586,9 +4166,9 @@ func file_pkg_apis_rpc_v1alpha1_rule_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_pkg_apis_rpc_v1alpha1_rule_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   17,
+			NumMessages:   46,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_pkg_apis_rpc_v1alpha1_rule_proto_goTypes,
 		DependencyIndexes: file_pkg_apis_rpc_v1alpha1_rule_proto_depIdxs,
@@ -1612,10 +4192,20 @@ const _ = grpc.SupportPackageIsVersion6
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type GetterClient interface {
-	GetAllRules(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*RuleEntries, error)
+	GetAllRules(ctx context.Context, in *RuleQuery, opts ...grpc.CallOption) (*RuleEntries, error)
 	GetRulesByName(ctx context.Context, in *RuleIDs, opts ...grpc.CallOption) (*RuleEntries, error)
 	GetRulesByFlow(ctx context.Context, in *FlowIDs, opts ...grpc.CallOption) (*RuleEntries, error)
 	GetSvcInfoBySvcID(ctx context.Context, in *SvcID, opts ...grpc.CallOption) (*SvcInfo, error)
+	DumpFlows(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*FlowDumps, error)
+	QueryReachable(ctx context.Context, in *ReachableQuery, opts ...grpc.CallOption) (*ReachableResult, error)
+	GetLeakedFlows(ctx context.Context, in *LeakedFlowQuery, opts ...grpc.CallOption) (*LeakedFlows, error)
+	GetEffectiveRules(ctx context.Context, in *EffectiveRuleQuery, opts ...grpc.CallOption) (*RuleEntries, error)
+	GetEndpoints(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*Endpoints, error)
+	GetReplayStatus(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ReplayStatuses, error)
+	GetRuleConflicts(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*RuleConflicts, error)
+	GetReferencedLabels(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ReferencedLabels, error)
+	GetPolicyDecisions(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*PolicyDecisions, error)
+	GetTableLayout(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*TableLayout, error)
 }
 
 type getterClient struct {
@@ -1626,7 +4216,7 @@ func NewGetterClient(cc grpc.ClientConnInterface) GetterClient {
 	return &getterClient{cc}
 }
 
-func (c *getterClient) GetAllRules(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*RuleEntries, error) {
+func (c *getterClient) GetAllRules(ctx context.Context, in *RuleQuery, opts ...grpc.CallOption) (*RuleEntries, error) {
 	out := new(RuleEntries)
 	err := c.cc.Invoke(ctx, "/everoute_io.pkg.apis.rpc.v1alpha1.Getter/GetAllRules", in, out, opts...)
 	if err != nil {
@@ -1662,19 +4252,119 @@ func (c *getterClient) GetSvcInfoBySvcID(ctx context.Context, in *SvcID, opts ..
 	return out, nil
 }
 
+func (c *getterClient) DumpFlows(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*FlowDumps, error) {
+	out := new(FlowDumps)
+	err := c.cc.Invoke(ctx, "/everoute_io.pkg.apis.rpc.v1alpha1.Getter/DumpFlows", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *getterClient) QueryReachable(ctx context.Context, in *ReachableQuery, opts ...grpc.CallOption) (*ReachableResult, error) {
+	out := new(ReachableResult)
+	err := c.cc.Invoke(ctx, "/everoute_io.pkg.apis.rpc.v1alpha1.Getter/QueryReachable", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *getterClient) GetLeakedFlows(ctx context.Context, in *LeakedFlowQuery, opts ...grpc.CallOption) (*LeakedFlows, error) {
+	out := new(LeakedFlows)
+	err := c.cc.Invoke(ctx, "/everoute_io.pkg.apis.rpc.v1alpha1.Getter/GetLeakedFlows", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *getterClient) GetEffectiveRules(ctx context.Context, in *EffectiveRuleQuery, opts ...grpc.CallOption) (*RuleEntries, error) {
+	out := new(RuleEntries)
+	err := c.cc.Invoke(ctx, "/everoute_io.pkg.apis.rpc.v1alpha1.Getter/GetEffectiveRules", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *getterClient) GetEndpoints(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*Endpoints, error) {
+	out := new(Endpoints)
+	err := c.cc.Invoke(ctx, "/everoute_io.pkg.apis.rpc.v1alpha1.Getter/GetEndpoints", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *getterClient) GetReplayStatus(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ReplayStatuses, error) {
+	out := new(ReplayStatuses)
+	err := c.cc.Invoke(ctx, "/everoute_io.pkg.apis.rpc.v1alpha1.Getter/GetReplayStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *getterClient) GetRuleConflicts(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*RuleConflicts, error) {
+	out := new(RuleConflicts)
+	err := c.cc.Invoke(ctx, "/everoute_io.pkg.apis.rpc.v1alpha1.Getter/GetRuleConflicts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *getterClient) GetReferencedLabels(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ReferencedLabels, error) {
+	out := new(ReferencedLabels)
+	err := c.cc.Invoke(ctx, "/everoute_io.pkg.apis.rpc.v1alpha1.Getter/GetReferencedLabels", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *getterClient) GetPolicyDecisions(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*PolicyDecisions, error) {
+	out := new(PolicyDecisions)
+	err := c.cc.Invoke(ctx, "/everoute_io.pkg.apis.rpc.v1alpha1.Getter/GetPolicyDecisions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *getterClient) GetTableLayout(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*TableLayout, error) {
+	out := new(TableLayout)
+	err := c.cc.Invoke(ctx, "/everoute_io.pkg.apis.rpc.v1alpha1.Getter/GetTableLayout", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // GetterServer is the server API for Getter service.
 type GetterServer interface {
-	GetAllRules(context.Context, *emptypb.Empty) (*RuleEntries, error)
+	GetAllRules(context.Context, *RuleQuery) (*RuleEntries, error)
 	GetRulesByName(context.Context, *RuleIDs) (*RuleEntries, error)
 	GetRulesByFlow(context.Context, *FlowIDs) (*RuleEntries, error)
 	GetSvcInfoBySvcID(context.Context, *SvcID) (*SvcInfo, error)
+	DumpFlows(context.Context, *emptypb.Empty) (*FlowDumps, error)
+	QueryReachable(context.Context, *ReachableQuery) (*ReachableResult, error)
+	GetLeakedFlows(context.Context, *LeakedFlowQuery) (*LeakedFlows, error)
+	GetEffectiveRules(context.Context, *EffectiveRuleQuery) (*RuleEntries, error)
+	GetEndpoints(context.Context, *emptypb.Empty) (*Endpoints, error)
+	GetReplayStatus(context.Context, *emptypb.Empty) (*ReplayStatuses, error)
+	GetRuleConflicts(context.Context, *emptypb.Empty) (*RuleConflicts, error)
+	GetReferencedLabels(context.Context, *emptypb.Empty) (*ReferencedLabels, error)
+	GetPolicyDecisions(context.Context, *emptypb.Empty) (*PolicyDecisions, error)
+	GetTableLayout(context.Context, *emptypb.Empty) (*TableLayout, error)
 }
 
 // UnimplementedGetterServer can be embedded to have forward compatible implementations.
 type UnimplementedGetterServer struct {
 }
 
-func (*UnimplementedGetterServer) GetAllRules(context.Context, *emptypb.Empty) (*RuleEntries, error) {
+func (*UnimplementedGetterServer) GetAllRules(context.Context, *RuleQuery) (*RuleEntries, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetAllRules not implemented")
 }
 func (*UnimplementedGetterServer) GetRulesByName(context.Context, *RuleIDs) (*RuleEntries, error) {
@@ -1686,13 +4376,43 @@ func (*UnimplementedGetterServer) GetRulesByFlow(context.Context, *FlowIDs) (*Ru
 func (*UnimplementedGetterServer) GetSvcInfoBySvcID(context.Context, *SvcID) (*SvcInfo, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetSvcInfoBySvcID not implemented")
 }
+func (*UnimplementedGetterServer) DumpFlows(context.Context, *emptypb.Empty) (*FlowDumps, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DumpFlows not implemented")
+}
+func (*UnimplementedGetterServer) QueryReachable(context.Context, *ReachableQuery) (*ReachableResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryReachable not implemented")
+}
+func (*UnimplementedGetterServer) GetLeakedFlows(context.Context, *LeakedFlowQuery) (*LeakedFlows, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLeakedFlows not implemented")
+}
+func (*UnimplementedGetterServer) GetEffectiveRules(context.Context, *EffectiveRuleQuery) (*RuleEntries, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEffectiveRules not implemented")
+}
+func (*UnimplementedGetterServer) GetEndpoints(context.Context, *emptypb.Empty) (*Endpoints, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEndpoints not implemented")
+}
+func (*UnimplementedGetterServer) GetReplayStatus(context.Context, *emptypb.Empty) (*ReplayStatuses, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReplayStatus not implemented")
+}
+func (*UnimplementedGetterServer) GetRuleConflicts(context.Context, *emptypb.Empty) (*RuleConflicts, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRuleConflicts not implemented")
+}
+func (*UnimplementedGetterServer) GetReferencedLabels(context.Context, *emptypb.Empty) (*ReferencedLabels, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReferencedLabels not implemented")
+}
+func (*UnimplementedGetterServer) GetPolicyDecisions(context.Context, *emptypb.Empty) (*PolicyDecisions, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPolicyDecisions not implemented")
+}
+func (*UnimplementedGetterServer) GetTableLayout(context.Context, *emptypb.Empty) (*TableLayout, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTableLayout not implemented")
+}
 
 func RegisterGetterServer(s *grpc.Server, srv GetterServer) {
 	s.RegisterService(&_Getter_serviceDesc, srv)
 }
 
 func _Getter_GetAllRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(emptypb.Empty)
+	in := new(RuleQuery)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -1704,7 +4424,7 @@ func _Getter_GetAllRules_Handler(srv interface{}, ctx context.Context, dec func(
 		FullMethod: "/everoute_io.pkg.apis.rpc.v1alpha1.Getter/GetAllRules",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(GetterServer).GetAllRules(ctx, req.(*emptypb.Empty))
+		return srv.(GetterServer).GetAllRules(ctx, req.(*RuleQuery))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -1763,6 +4483,186 @@ func _Getter_GetSvcInfoBySvcID_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Getter_DumpFlows_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GetterServer).DumpFlows(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/everoute_io.pkg.apis.rpc.v1alpha1.Getter/DumpFlows",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GetterServer).DumpFlows(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Getter_QueryReachable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReachableQuery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GetterServer).QueryReachable(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/everoute_io.pkg.apis.rpc.v1alpha1.Getter/QueryReachable",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GetterServer).QueryReachable(ctx, req.(*ReachableQuery))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Getter_GetLeakedFlows_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LeakedFlowQuery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GetterServer).GetLeakedFlows(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/everoute_io.pkg.apis.rpc.v1alpha1.Getter/GetLeakedFlows",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GetterServer).GetLeakedFlows(ctx, req.(*LeakedFlowQuery))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Getter_GetEffectiveRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EffectiveRuleQuery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GetterServer).GetEffectiveRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/everoute_io.pkg.apis.rpc.v1alpha1.Getter/GetEffectiveRules",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GetterServer).GetEffectiveRules(ctx, req.(*EffectiveRuleQuery))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Getter_GetEndpoints_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GetterServer).GetEndpoints(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/everoute_io.pkg.apis.rpc.v1alpha1.Getter/GetEndpoints",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GetterServer).GetEndpoints(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Getter_GetReplayStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GetterServer).GetReplayStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/everoute_io.pkg.apis.rpc.v1alpha1.Getter/GetReplayStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GetterServer).GetReplayStatus(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Getter_GetRuleConflicts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GetterServer).GetRuleConflicts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/everoute_io.pkg.apis.rpc.v1alpha1.Getter/GetRuleConflicts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GetterServer).GetRuleConflicts(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Getter_GetReferencedLabels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GetterServer).GetReferencedLabels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/everoute_io.pkg.apis.rpc.v1alpha1.Getter/GetReferencedLabels",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GetterServer).GetReferencedLabels(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Getter_GetPolicyDecisions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GetterServer).GetPolicyDecisions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/everoute_io.pkg.apis.rpc.v1alpha1.Getter/GetPolicyDecisions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GetterServer).GetPolicyDecisions(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Getter_GetTableLayout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GetterServer).GetTableLayout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/everoute_io.pkg.apis.rpc.v1alpha1.Getter/GetTableLayout",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GetterServer).GetTableLayout(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Getter_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "everoute_io.pkg.apis.rpc.v1alpha1.Getter",
 	HandlerType: (*GetterServer)(nil),
@@ -1783,6 +4683,190 @@ var _Getter_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetSvcInfoBySvcID",
 			Handler:    _Getter_GetSvcInfoBySvcID_Handler,
 		},
+		{
+			MethodName: "DumpFlows",
+			Handler:    _Getter_DumpFlows_Handler,
+		},
+		{
+			MethodName: "QueryReachable",
+			Handler:    _Getter_QueryReachable_Handler,
+		},
+		{
+			MethodName: "GetLeakedFlows",
+			Handler:    _Getter_GetLeakedFlows_Handler,
+		},
+		{
+			MethodName: "GetEffectiveRules",
+			Handler:    _Getter_GetEffectiveRules_Handler,
+		},
+		{
+			MethodName: "GetEndpoints",
+			Handler:    _Getter_GetEndpoints_Handler,
+		},
+		{
+			MethodName: "GetReplayStatus",
+			Handler:    _Getter_GetReplayStatus_Handler,
+		},
+		{
+			MethodName: "GetRuleConflicts",
+			Handler:    _Getter_GetRuleConflicts_Handler,
+		},
+		{
+			MethodName: "GetReferencedLabels",
+			Handler:    _Getter_GetReferencedLabels_Handler,
+		},
+		{
+			MethodName: "GetPolicyDecisions",
+			Handler:    _Getter_GetPolicyDecisions_Handler,
+		},
+		{
+			MethodName: "GetTableLayout",
+			Handler:    _Getter_GetTableLayout_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/apis/rpc/v1alpha1/rule.proto",
+}
+
+// AdminClient is the client API for Admin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type AdminClient interface {
+	SetTableMissAction(ctx context.Context, in *TableMissAction, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	SetRuleGroup(ctx context.Context, in *RuleGroup, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	RefreshEndpointIP(ctx context.Context, in *EndpointIPRefresh, opts ...grpc.CallOption) (*EndpointIPRefreshResults, error)
+}
+
+type adminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAdminClient(cc grpc.ClientConnInterface) AdminClient {
+	return &adminClient{cc}
+}
+
+func (c *adminClient) SetTableMissAction(ctx context.Context, in *TableMissAction, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/everoute_io.pkg.apis.rpc.v1alpha1.Admin/SetTableMissAction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) SetRuleGroup(ctx context.Context, in *RuleGroup, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/everoute_io.pkg.apis.rpc.v1alpha1.Admin/SetRuleGroup", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) RefreshEndpointIP(ctx context.Context, in *EndpointIPRefresh, opts ...grpc.CallOption) (*EndpointIPRefreshResults, error) {
+	out := new(EndpointIPRefreshResults)
+	err := c.cc.Invoke(ctx, "/everoute_io.pkg.apis.rpc.v1alpha1.Admin/RefreshEndpointIP", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdminServer is the server API for Admin service.
+type AdminServer interface {
+	SetTableMissAction(context.Context, *TableMissAction) (*emptypb.Empty, error)
+	SetRuleGroup(context.Context, *RuleGroup) (*emptypb.Empty, error)
+	RefreshEndpointIP(context.Context, *EndpointIPRefresh) (*EndpointIPRefreshResults, error)
+}
+
+// UnimplementedAdminServer can be embedded to have forward compatible implementations.
+type UnimplementedAdminServer struct {
+}
+
+func (*UnimplementedAdminServer) SetTableMissAction(context.Context, *TableMissAction) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetTableMissAction not implemented")
+}
+func (*UnimplementedAdminServer) SetRuleGroup(context.Context, *RuleGroup) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetRuleGroup not implemented")
+}
+func (*UnimplementedAdminServer) RefreshEndpointIP(context.Context, *EndpointIPRefresh) (*EndpointIPRefreshResults, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefreshEndpointIP not implemented")
+}
+
+func RegisterAdminServer(s *grpc.Server, srv AdminServer) {
+	s.RegisterService(&_Admin_serviceDesc, srv)
+}
+
+func _Admin_SetTableMissAction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TableMissAction)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SetTableMissAction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/everoute_io.pkg.apis.rpc.v1alpha1.Admin/SetTableMissAction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SetTableMissAction(ctx, req.(*TableMissAction))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_SetRuleGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RuleGroup)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SetRuleGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/everoute_io.pkg.apis.rpc.v1alpha1.Admin/SetRuleGroup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SetRuleGroup(ctx, req.(*RuleGroup))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_RefreshEndpointIP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EndpointIPRefresh)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).RefreshEndpointIP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/everoute_io.pkg.apis.rpc.v1alpha1.Admin/RefreshEndpointIP",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).RefreshEndpointIP(ctx, req.(*EndpointIPRefresh))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Admin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "everoute_io.pkg.apis.rpc.v1alpha1.Admin",
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SetTableMissAction",
+			Handler:    _Admin_SetTableMissAction_Handler,
+		},
+		{
+			MethodName: "SetRuleGroup",
+			Handler:    _Admin_SetRuleGroup_Handler,
+		},
+		{
+			MethodName: "RefreshEndpointIP",
+			Handler:    _Admin_RefreshEndpointIP_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "pkg/apis/rpc/v1alpha1/rule.proto",
@@ -17,6 +17,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"time"
+
 	networkingv1 "k8s.io/api/networking/v1"
 )
 
@@ -42,6 +44,82 @@ func (p *SecurityPolicy) IsEnable() (ingressEnabled bool, egressEnabled bool) {
 	return
 }
 
+// IsSymmetric returns whether symmetry rules should be generated for rules of the policyType
+func (p *SecurityPolicy) IsSymmetric(policyType networkingv1.PolicyType) bool {
+	if !p.Spec.SymmetricMode {
+		return false
+	}
+	if len(p.Spec.SymmetricPolicyTypes) == 0 {
+		return true
+	}
+	for _, symmetricPolicyType := range p.Spec.SymmetricPolicyTypes {
+		if symmetricPolicyType == policyType {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActive returns whether the rule is active at the time, the rule is active in [ActiveFrom, ActiveUntil)
+func (r *Rule) IsActive(now time.Time) bool {
+	if r.ActiveFrom != nil && now.Before(r.ActiveFrom.Time) {
+		return false
+	}
+	if r.ActiveUntil != nil && !now.Before(r.ActiveUntil.Time) {
+		return false
+	}
+	return true
+}
+
+// GetVlanID returns the vlan the rule limited to, 0 means all vlans
+func (r *Rule) GetVlanID() uint16 {
+	if r.VlanID == nil {
+		return 0
+	}
+	return uint16(*r.VlanID)
+}
+
+// IsDropEnabled returns whether dropped connections should be logged, default to Enabled
+func (l *Logging) IsDropEnabled() bool {
+	if l == nil {
+		return false
+	}
+	if l.DropEnabled != nil {
+		return *l.DropEnabled
+	}
+	return l.Enabled
+}
+
+// IsAllowEnabled returns whether allowed connections should be logged, default to Enabled
+func (l *Logging) IsAllowEnabled() bool {
+	if l == nil {
+		return false
+	}
+	if l.AllowEnabled != nil {
+		return *l.AllowEnabled
+	}
+	return l.Enabled
+}
+
+// DefaultLoggingSampleRate logs all connections matched the policy
+const DefaultLoggingSampleRate int32 = 100
+
+// GetDropSampleRate returns the percentage of dropped connections should be logged, default to 100
+func (l *Logging) GetDropSampleRate() int32 {
+	if l == nil || l.DropSampleRate == nil {
+		return DefaultLoggingSampleRate
+	}
+	return *l.DropSampleRate
+}
+
+// GetAllowSampleRate returns the percentage of allowed connections should be logged, default to 100
+func (l *Logging) GetAllowSampleRate() int32 {
+	if l == nil || l.AllowSampleRate == nil {
+		return DefaultLoggingSampleRate
+	}
+	return *l.AllowSampleRate
+}
+
 func (p PolicyMode) String() string {
 	return string(p)
 }
@@ -90,6 +90,12 @@ type SecurityPolicySpec struct {
 	// Defaults to false.
 	SymmetricMode bool `json:"symmetricMode,omitempty"`
 
+	// SymmetricPolicyTypes limits the rule types SymmetricMode generates symmetry rules for.
+	// Valid options are "Ingress", "Egress", or "Ingress,Egress". It works only when SymmetricMode
+	// is true, empty means symmetry rules are generated for both ingress and egress rules.
+	// +optional
+	SymmetricPolicyTypes []networkingv1.PolicyType `json:"symmetricPolicyTypes,omitempty"`
+
 	// Selects the endpoints to which this SecurityPolicy object applies.
 	// Empty or nil means select all endpoints.
 	// Notice: if AppliedTo is empty, IngressRule's Ports can't be namedPorts.
@@ -109,6 +115,16 @@ type SecurityPolicySpec struct {
 	// +kubebuilder:default=drop
 	DefaultRule DefaultRuleType `json:"defaultRule,omitempty"`
 
+	// DefaultIngressRuleLogging defines the logging configuration of the default ingress rule,
+	// the policy logging is used if not set.
+	// +optional
+	DefaultIngressRuleLogging *Logging `json:"defaultIngressRuleLogging,omitempty"`
+
+	// DefaultEgressRuleLogging defines the logging configuration of the default egress rule,
+	// the policy logging is used if not set.
+	// +optional
+	DefaultEgressRuleLogging *Logging `json:"defaultEgressRuleLogging,omitempty"`
+
 	// Logging defines the policy logging configuration.
 	// +optional
 	Logging *Logging `json:"logging,omitempty"`
@@ -117,6 +133,12 @@ type SecurityPolicySpec struct {
 	// Default is false
 	IsBlocklist bool `json:"isBlocklist,omitempty"`
 
+	// BlockARP denies ARP and ND of the applied endpoints besides ip traffics. By default ARP and ND
+	// are not governed by policy. It only works in tier0 policy with default drop and without rules,
+	// e.g. strict isolation.
+	// +optional
+	BlockARP bool `json:"blockARP,omitempty"`
+
 	// List of rule types that the Security relates to.
 	// Valid options are "Ingress", "Egress", or "Ingress,Egress".
 	// If this field is not specified, it will default based on the existence of Ingress or Egress rules;
@@ -134,12 +156,36 @@ type Logging struct {
 	// Enabled would log connections when the policy matched.
 	Enabled bool `json:"enabled"`
 
+	// DropEnabled would log dropped connections when the policy matched.
+	// Default to Enabled if not set.
+	// +optional
+	DropEnabled *bool `json:"dropEnabled,omitempty"`
+
+	// AllowEnabled would log allowed connections when the policy matched.
+	// Default to Enabled if not set.
+	// +optional
+	AllowEnabled *bool `json:"allowEnabled,omitempty"`
+
+	// DropSampleRate is the percentage of dropped connections would be logged,
+	// between 1 and 100. Default to 100 if not set.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	DropSampleRate *int32 `json:"dropSampleRate,omitempty"`
+
+	// AllowSampleRate is the percentage of allowed connections would be logged,
+	// between 1 and 100. Default to 100 if not set.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	AllowSampleRate *int32 `json:"allowSampleRate,omitempty"`
+
 	// Tags should be logging when the policy matched.
 	Tags map[string]string `json:"tags,omitempty"`
 }
 
 // ApplyToPeer describes sets of endpoints which this SecurityPolicy object applies
-// At least one field (Endpoint or EndpointSelector) should be set.
+// At least one field (Endpoint, EndpointSelector, InterfaceName or VlanRange) should be set.
 type ApplyToPeer struct {
 	// Endpoint defines policy on a specific Endpoint.
 	//
@@ -158,6 +204,28 @@ type ApplyToPeer struct {
 	// If this field is set then neither of the other fields can be.
 	// +optional
 	EndpointSelector *labels.Selector `json:"endpointSelector,omitempty"`
+
+	// InterfaceName selects endpoints which ovs interface name matches the pattern,
+	// e.g. veth*. The pattern follows shell file name pattern, and is resolved by
+	// each agent with its local endpoints regardless of the endpoint namespace.
+	// If this field is set then neither of the other fields can be.
+	// +optional
+	InterfaceName *string `json:"interfaceName,omitempty"`
+
+	// VlanRange selects endpoints which vlan id is in the range. It is resolved
+	// by each agent with its local endpoints regardless of the endpoint namespace.
+	// If this field is set then neither of the other fields can be.
+	// +optional
+	VlanRange *VlanRange `json:"vlanRange,omitempty"`
+}
+
+// VlanRange is a closed range of vlan id
+type VlanRange struct {
+	// Start is the first vlan id of the range.
+	Start uint32 `json:"start"`
+
+	// End is the last vlan id of the range, it must not be less than Start.
+	End uint32 `json:"end"`
 }
 
 // Rule describes a particular set of traffic that is allowed from/to the endpoints
@@ -191,8 +259,54 @@ type Rule struct {
 	// This field only works when rule is egress.
 	// +optional
 	To []SecurityPolicyPeer `json:"to,omitempty"`
+
+	// Logging defines the rule logging configuration, it's tags of the policy logging
+	// with the rule direction.
+	// +optional
+	Logging *Logging `json:"logging,omitempty"`
+
+	// EnforcementMode overrides SecurityPolicyEnforcementMode of the policy for the rule, e.g. monitor
+	// a new rule while the other rules of the policy work. The policy mode is used when empty.
+	// +kubebuilder:validation:Enum=work;monitor
+	// +optional
+	EnforcementMode PolicyMode `json:"enforcementMode,omitempty"`
+
+	// TrafficLocality limits the rule to traffic stays on the node or not, e.g. log all inter-node
+	// traffic. Inter-node traffic includes traffic from or to outside the cluster. Only supported in
+	// overlay mode, the rule is skipped in other modes. Matches traffic of both when empty.
+	// +optional
+	TrafficLocality TrafficLocality `json:"trafficLocality,omitempty"`
+
+	// VlanID limits the rule to packets tagged with the vlan, e.g. allow only from vlan 100.
+	// Matches packets of all vlans when empty.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=4094
+	// +optional
+	VlanID *int32 `json:"vlanID,omitempty"`
+
+	// ActiveFrom is the time the rule becomes active, e.g. start of a maintenance window.
+	// The rule is active since the policy created when empty.
+	// +optional
+	ActiveFrom *metav1.Time `json:"activeFrom,omitempty"`
+
+	// ActiveUntil is the time the rule becomes inactive, it must be after ActiveFrom.
+	// The rule never expires when empty.
+	// +optional
+	ActiveUntil *metav1.Time `json:"activeUntil,omitempty"`
 }
 
+// TrafficLocality describes whether traffic stays on the node or crosses nodes.
+// +kubebuilder:validation:Enum=IntraNode;InterNode
+type TrafficLocality string
+
+const (
+	// TrafficLocalityIntraNode is traffic between endpoints on the same node.
+	TrafficLocalityIntraNode TrafficLocality = "IntraNode"
+	// TrafficLocalityInterNode is traffic not between endpoints on the same node, including traffic
+	// from or to other nodes and outside the cluster.
+	TrafficLocalityInterNode TrafficLocality = "InterNode"
+)
+
 // SecurityPolicyPeer describes a peer to allow traffic to/from. Only certain combinations
 // of fields are allowed
 type SecurityPolicyPeer struct {
@@ -227,8 +341,41 @@ type SecurityPolicyPeer struct {
 	// Otherwise, it selects all Endpoints in the Namespaces selected by NamespaceSelector.
 	// +optional
 	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// EndpointNetwork defines policy on the gateway or the subnet of endpoints, it resolves
+	// dynamically on each agent instead of a hardcoded CIDR. If this field is set then neither
+	// of the other fields can be.
+	// +optional
+	EndpointNetwork *EndpointNetworkType `json:"endpointNetwork,omitempty"`
+
+	// IPPool defines policy on endpoints assigned ip from the everoute ipam pool, it resolves to
+	// the pool allocatable cidr or subnet, and follows changes of the pool. If this field is set
+	// then neither of the other fields can be.
+	// +optional
+	IPPool *NamespacedName `json:"ipPool,omitempty"`
+
+	// FQDN defines policy on addresses the domain name resolves to, it resolves A and AAAA records
+	// on each agent, and refreshes the addresses when the records ttl expired. Only supported in
+	// egress rules. If this field is set then neither of the other fields can be.
+	// +optional
+	FQDN string `json:"fqdn,omitempty"`
 }
 
+// EndpointNetworkType defines which address of the endpoint network a peer resolves to.
+// +kubebuilder:validation:Enum=Gateway;Subnet;LocalEndpoints
+type EndpointNetworkType string
+
+const (
+	// EndpointNetworkGateway resolves to the gateway ip of endpoints on the agent.
+	EndpointNetworkGateway EndpointNetworkType = "Gateway"
+	// EndpointNetworkSubnet resolves to the subnet of endpoints on the agent.
+	EndpointNetworkSubnet EndpointNetworkType = "Subnet"
+	// EndpointNetworkLocalEndpoints resolves to the ips of endpoints on the agent, it matches traffic
+	// from or to endpoints on the same node but not remote peers, e.g. traffic from tunnel. Besides the
+	// ips, the traffic is matched by the intra node packet mark, so it's only supported in overlay mode.
+	EndpointNetworkLocalEndpoints EndpointNetworkType = "LocalEndpoints"
+)
+
 // PortType defaines the PortRange is real port numbers or port names which needed resolve. If it is empty, equal to "number".
 // +kubebuilder:validation:Enum=number;name
 type PortType string
@@ -253,6 +400,18 @@ type SecurityPolicyPort struct {
 	// the effect is equal to "number" for compatibility.
 	// +kubebuilder:default:=number
 	Type PortType `json:"type,omitempty"`
+
+	// ICMPType is the icmp type which traffic must match, only valid when Protocol is ICMP.
+	// If it is empty, all icmp types will matches.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=255
+	ICMPType *int32 `json:"icmpType,omitempty"`
+
+	// ICMPCode is the icmp code which traffic must match, only valid when ICMPType is set.
+	// If it is empty, all icmp codes of the ICMPType will matches.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=255
+	ICMPCode *int32 `json:"icmpCode,omitempty"`
 }
 
 // NamespacedName contains information to specify an object.
@@ -269,7 +428,7 @@ func (n NamespacedName) String() string {
 }
 
 // Protocol defines network protocols supported for SecurityPolicy.
-// +kubebuilder:validation:Enum=TCP;UDP;ICMP;IPIP;VRRP
+// +kubebuilder:validation:Enum=TCP;UDP;ICMP;IPIP;VRRP;GRE;ICMPv6
 type Protocol string
 
 const (
@@ -283,6 +442,10 @@ const (
 	ProtocolIPIP Protocol = "IPIP"
 	// ProtocolVRRP is the VRRP protocol.
 	ProtocolVRRP Protocol = "VRRP"
+	// ProtocolGRE is the GRE protocol.
+	ProtocolGRE Protocol = "GRE"
+	// ProtocolICMPv6 is the ICMPv6 protocol.
+	ProtocolICMPv6 Protocol = "ICMPv6"
 )
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -422,7 +585,7 @@ type GlobalPolicySpec struct {
 }
 
 // GlobalDefaultAction defines actions supported for GlobalPolicy.
-// +kubebuilder:validation:Enum=Allow;Drop
+// +kubebuilder:validation:Enum=Allow;Drop;Reject
 type GlobalDefaultAction string
 
 const (
@@ -430,6 +593,9 @@ const (
 	GlobalDefaultActionAllow GlobalDefaultAction = "Allow"
 	// GlobalDefaultActionDrop default drop all traffics between Endpoints.
 	GlobalDefaultActionDrop GlobalDefaultAction = "Drop"
+	// GlobalDefaultActionReject default drop all traffics between Endpoints, and reply tcp rst or
+	// icmp unreachable to the source, so that clients fail fast instead of timeout.
+	GlobalDefaultActionReject GlobalDefaultAction = "Reject"
 )
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -42,6 +42,16 @@ func (in *ApplyToPeer) DeepCopyInto(out *ApplyToPeer) {
 		*out = new(labels.Selector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.InterfaceName != nil {
+		in, out := &in.InterfaceName, &out.InterfaceName
+		*out = new(string)
+		**out = **in
+	}
+	if in.VlanRange != nil {
+		in, out := &in.VlanRange, &out.VlanRange
+		*out = new(VlanRange)
+		**out = **in
+	}
 	return
 }
 
@@ -279,6 +289,26 @@ func (in *GlobalPolicySpec) DeepCopy() *GlobalPolicySpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Logging) DeepCopyInto(out *Logging) {
 	*out = *in
+	if in.DropEnabled != nil {
+		in, out := &in.DropEnabled, &out.DropEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AllowEnabled != nil {
+		in, out := &in.AllowEnabled, &out.AllowEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DropSampleRate != nil {
+		in, out := &in.DropSampleRate, &out.DropSampleRate
+		*out = new(int32)
+		**out = **in
+	}
+	if in.AllowSampleRate != nil {
+		in, out := &in.AllowSampleRate, &out.AllowSampleRate
+		*out = new(int32)
+		**out = **in
+	}
 	if in.Tags != nil {
 		in, out := &in.Tags, &out.Tags
 		*out = make(map[string]string, len(*in))
@@ -337,7 +367,9 @@ func (in *Rule) DeepCopyInto(out *Rule) {
 	if in.Ports != nil {
 		in, out := &in.Ports, &out.Ports
 		*out = make([]SecurityPolicyPort, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.From != nil {
 		in, out := &in.From, &out.From
@@ -353,6 +385,24 @@ func (in *Rule) DeepCopyInto(out *Rule) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Logging != nil {
+		in, out := &in.Logging, &out.Logging
+		*out = new(Logging)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VlanID != nil {
+		in, out := &in.VlanID, &out.VlanID
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ActiveFrom != nil {
+		in, out := &in.ActiveFrom, &out.ActiveFrom
+		*out = (*in).DeepCopy()
+	}
+	if in.ActiveUntil != nil {
+		in, out := &in.ActiveUntil, &out.ActiveUntil
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
@@ -449,6 +499,16 @@ func (in *SecurityPolicyPeer) DeepCopyInto(out *SecurityPolicyPeer) {
 		*out = new(metav1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.EndpointNetwork != nil {
+		in, out := &in.EndpointNetwork, &out.EndpointNetwork
+		*out = new(EndpointNetworkType)
+		**out = **in
+	}
+	if in.IPPool != nil {
+		in, out := &in.IPPool, &out.IPPool
+		*out = new(NamespacedName)
+		**out = **in
+	}
 	return
 }
 
@@ -465,6 +525,16 @@ func (in *SecurityPolicyPeer) DeepCopy() *SecurityPolicyPeer {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecurityPolicyPort) DeepCopyInto(out *SecurityPolicyPort) {
 	*out = *in
+	if in.ICMPType != nil {
+		in, out := &in.ICMPType, &out.ICMPType
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ICMPCode != nil {
+		in, out := &in.ICMPCode, &out.ICMPCode
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
@@ -481,6 +551,11 @@ func (in *SecurityPolicyPort) DeepCopy() *SecurityPolicyPort {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecurityPolicySpec) DeepCopyInto(out *SecurityPolicySpec) {
 	*out = *in
+	if in.SymmetricPolicyTypes != nil {
+		in, out := &in.SymmetricPolicyTypes, &out.SymmetricPolicyTypes
+		*out = make([]v1.PolicyType, len(*in))
+		copy(*out, *in)
+	}
 	if in.AppliedTo != nil {
 		in, out := &in.AppliedTo, &out.AppliedTo
 		*out = make([]ApplyToPeer, len(*in))
@@ -502,6 +577,16 @@ func (in *SecurityPolicySpec) DeepCopyInto(out *SecurityPolicySpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.DefaultIngressRuleLogging != nil {
+		in, out := &in.DefaultIngressRuleLogging, &out.DefaultIngressRuleLogging
+		*out = new(Logging)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DefaultEgressRuleLogging != nil {
+		in, out := &in.DefaultEgressRuleLogging, &out.DefaultEgressRuleLogging
+		*out = new(Logging)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Logging != nil {
 		in, out := &in.Logging, &out.Logging
 		*out = new(Logging)
@@ -524,3 +609,19 @@ func (in *SecurityPolicySpec) DeepCopy() *SecurityPolicySpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VlanRange) DeepCopyInto(out *VlanRange) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VlanRange.
+func (in *VlanRange) DeepCopy() *VlanRange {
+	if in == nil {
+		return nil
+	}
+	out := new(VlanRange)
+	in.DeepCopyInto(out)
+	return out
+}
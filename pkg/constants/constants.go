@@ -28,11 +28,13 @@ const (
 
 	IfaceIPTimeoutDuration = 30 * time.Minute
 
-	DefaultMaxConcurrentReconciles   = 4
-	DependentsCleanFinalizer         = "finalizer.everoute.io/dependentsclean"
-	OwnerGroupLabelKey               = "label.everoute.io/ownergroup"
-	OwnerPolicyLabelKey              = "label.everoute.io/ownerpolicy"
-	IsGlobalPolicyRuleLabel          = "label.everoute.io/isglobalpolicy"
+	DefaultMaxConcurrentReconciles = 4
+	DependentsCleanFinalizer       = "finalizer.everoute.io/dependentsclean"
+	OwnerGroupLabelKey             = "label.everoute.io/ownergroup"
+	OwnerPolicyLabelKey            = "label.everoute.io/ownerpolicy"
+	IsGlobalPolicyRuleLabel        = "label.everoute.io/isglobalpolicy"
+	// RuleGroupLabelKey on SecurityPolicy puts all rules of the policy into the named rule group
+	RuleGroupLabelKey = "label.everoute.io/rulegroup"
 
 	// Tier0 used for isolation policy and forensic one side drop
 	Tier0 = "tier0"
@@ -45,6 +47,9 @@ const (
 
 	SecurityPolicyByEndpointGroupIndex = "SecurityPolicyByEndpointGroupIndex"
 
+	// LoggingTagPolicyType is the key of policy type in policy logging tags
+	LoggingTagPolicyType = "PolicyType"
+
 	EverouteWebhookName     = "validator.everoute.io"
 	EverouteIPAMWebhookName = "vipam.everoute.io"
 	EverouteSecretName      = "everoute-controller-tls"
@@ -65,6 +70,8 @@ const (
 	AllEpWithNamedPort = "all-endpoints-with-named-port"
 
 	HealthCheckPath = "/healthz"
+	// AgentMetricsPath serves agent metrics with exemplars in OpenMetrics format
+	AgentMetricsPath = "/metrics/agent"
 
 	// sks related
 	SksManagedLabelKey   = "sks-managed"
@@ -95,6 +102,8 @@ const (
 	ExternalSvcPktMarkBit = 28
 	// SvcLocalPktMarkBit set when ExternalTrafficPolicy=local
 	SvcLocalPktMarkBit = 30
+	// IntraNodePktMarkBit set when pod requests a pod on the same node, used in local bridge and policy bridge
+	IntraNodePktMarkBit = 27
 
 	PktMarkSetValue   uint64 = 0x1
 	PktMarkResetValue uint64 = 0x0
@@ -108,10 +117,15 @@ const (
 	// ct zone used by cni
 	CTZoneNatBrFromLocal  = 65505
 	CTZoneNatBrFromUplink = 65506
+	CTZoneNatBrHairpin    = 65507
 	CTZoneLocalBr         = 65510
 	CTZoneUplinkBr        = 65503
 	// ct zone used by securitypolicy
 	CTZoneForPolicy uint16 = 65520
+	// ct zone used by securitypolicy for connections committed by rules with ct timeout policy
+	CTZoneForPolicyCTTimeout uint16 = 65521
+	// ct zone used by securitypolicy when assign ct zone by vlan, zone of the vlan is base|vlanID
+	CTZoneForPolicyVlanBase uint16 = 0xe000
 
 	// endpoint
 	EndpointExternalIDKey = "iface-id"
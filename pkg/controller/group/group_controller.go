@@ -50,6 +50,10 @@ import (
 type GroupReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// CardinalityWarnThreshold warns when the number of endpoints matched the endpointgroup exceeds it,
+	// zero means never warn. The cardinality is reported as metric regardless of it.
+	CardinalityWarnThreshold int
 }
 
 // Reconcile receive endpointgroup from work queue, first it create groupmemberspatch,
@@ -452,6 +456,8 @@ func (r *GroupReconciler) processEndpointGroupDelete(ctx context.Context, group
 		return ctrl.Result{}, err
 	}
 
+	groupMembers.DeleteLabelValues(group.Name)
+
 	group.ObjectMeta.Finalizers = []string{}
 	err = r.Update(ctx, group)
 	if err != nil {
@@ -472,6 +478,7 @@ func (r *GroupReconciler) processEndpointGroupUpdate(ctx context.Context, group
 	members := groupv1alpha1.GroupMembers{}
 	members.Name = group.Name
 	members.GroupMembers = currGroupMembers.GroupMembers
+	r.recordCardinality(group.Name, len(members.GroupMembers))
 
 	err = r.syncGroupMembers(ctx, group.Name, members)
 	if err != nil {
@@ -579,6 +586,15 @@ func (r *GroupReconciler) fetchCurrGroupMembers(ctx context.Context, group *grou
 	return &groupv1alpha1.GroupMembers{GroupMembers: memberList}, nil
 }
 
+// recordCardinality reports the number of endpoints matched the endpointgroup, and warns overly-broad selector
+func (r *GroupReconciler) recordCardinality(groupName string, cardinality int) {
+	groupMembers.WithLabelValues(groupName).Set(float64(cardinality))
+	if r.CardinalityWarnThreshold > 0 && cardinality > r.CardinalityWarnThreshold {
+		klog.Warningf("endpointgroup %s matched %d endpoints, exceeds cardinality threshold %d, the selector may be overly-broad",
+			groupName, cardinality, r.CardinalityWarnThreshold)
+	}
+}
+
 func (r *GroupReconciler) syncGroupMembers(ctx context.Context, groupName string, members groupv1alpha1.GroupMembers) error {
 	groupMembers := groupv1alpha1.GroupMembers{}
 	err := r.Get(ctx, k8stypes.NamespacedName{Name: groupName}, &groupMembers)
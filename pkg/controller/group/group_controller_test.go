@@ -30,10 +30,12 @@ import (
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/klog"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	groupv1alpha1 "github.com/everoute/everoute/pkg/apis/group/v1alpha1"
 	securityv1alpha1 "github.com/everoute/everoute/pkg/apis/security/v1alpha1"
 	"github.com/everoute/everoute/pkg/constants"
+	groupctrl "github.com/everoute/everoute/pkg/controller/group"
 	"github.com/everoute/everoute/pkg/labels"
 	"github.com/everoute/everoute/pkg/types"
 )
@@ -121,6 +123,11 @@ var _ = Describe("GroupController", func() {
 				By(fmt.Sprintf("wait endpoint %s in endpointgroup %s", ep.Name, epGroup.Name))
 				assertHasGroupMembers(epGroup, groupv1alpha1.GroupMembers{GroupMembers: []groupv1alpha1.GroupMember{endpointToGroupMember(ep)}})
 			})
+			It("should report the group cardinality", func() {
+				Eventually(func() float64 {
+					return getGroupCardinality(epGroup.Name)
+				}, timeout, interval).Should(Equal(float64(1)))
+			})
 			When("update the endpoint IPs", func() {
 				BeforeEach(func() {
 					ep.Status.IPs = append(ep.Status.IPs, "192.168.2.1")
@@ -531,3 +538,21 @@ func assertHasGroupMembers(epGroup *groupv1alpha1.EndpointGroup, members groupv1
 		g.Expect(res.Revision).Should(Equal(members.Revision))
 	}, timeout, interval).Should(Succeed())
 }
+
+func getGroupCardinality(groupName string) float64 {
+	metricFamilies, err := ctrlmetrics.Registry.Gather()
+	Expect(err).Should(Succeed())
+	for _, mf := range metricFamilies {
+		if mf.GetName() != groupctrl.GroupMembersMetricName {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == groupctrl.GroupLabel && label.GetValue() == groupName {
+					return m.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package group
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// GroupMembersMetricName is the full name of the endpointgroup cardinality metric
+	GroupMembersMetricName = "everoute_controller_endpoint_group_members"
+	// GroupLabel is the label of the endpointgroup name in the cardinality metric
+	GroupLabel = "group"
+)
+
+// groupMembers records the cardinality of each endpointgroup selector, overly-broad selectors
+// produce huge flow sets in agents.
+var groupMembers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "everoute",
+	Subsystem: "controller",
+	Name:      "endpoint_group_members",
+	Help:      "The number of endpoints matched the endpointgroup selector",
+}, []string{GroupLabel})
+
+func init() {
+	metrics.Registry.MustRegister(groupMembers)
+}
@@ -0,0 +1,263 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/everoute/everoute/pkg/apis/security/v1alpha1"
+	"github.com/everoute/everoute/pkg/constants"
+	"github.com/everoute/everoute/pkg/labels"
+)
+
+// ExportNetworkPolicy translates the SecurityPolicy into NetworkPolicy, so that the policy could be reviewed
+// by the one familiar with NetworkPolicy. Features which only make the NetworkPolicy stricter when skipped,
+// e.g. tier and monitor mode, are skipped from the NetworkPolicy and returned as notes. The export is refused
+// with an error if any rule can't be represented exactly, because a skipped peer or port could widen the
+// rule (an empty peer list allows all), and rules of blocklist would be turned from deny into allow.
+func ExportNetworkPolicy(policy *v1alpha1.SecurityPolicy) (*networkingv1.NetworkPolicy, []string, error) {
+	e := &networkPolicyExporter{}
+
+	networkPolicy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      policy.Name,
+			Namespace: policy.Namespace,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: e.exportAppliedTo(policy.Spec.AppliedTo),
+			PolicyTypes: append([]networkingv1.PolicyType{}, policy.Spec.PolicyTypes...),
+		},
+	}
+	e.exportPolicyFeatures(&policy.Spec)
+
+	for _, rule := range policy.Spec.IngressRules {
+		e.exportRuleFeatures("ingress", &rule)
+		networkPolicy.Spec.Ingress = append(networkPolicy.Spec.Ingress, networkingv1.NetworkPolicyIngressRule{
+			Ports: e.exportPorts(rule.Name, rule.Ports),
+			From:  e.exportPeers(rule.Name, rule.From),
+		})
+	}
+	for _, rule := range policy.Spec.EgressRules {
+		e.exportRuleFeatures("egress", &rule)
+		networkPolicy.Spec.Egress = append(networkPolicy.Spec.Egress, networkingv1.NetworkPolicyEgressRule{
+			Ports: e.exportPorts(rule.Name, rule.Ports),
+			To:    e.exportPeers(rule.Name, rule.To),
+		})
+	}
+
+	if len(e.errs) != 0 {
+		return nil, e.notes, fmt.Errorf("policy %s/%s can't be exported as NetworkPolicy exactly: %w",
+			policy.Namespace, policy.Name, utilerrors.NewAggregate(e.errs))
+	}
+	return networkPolicy, e.notes, nil
+}
+
+// networkPolicyExporter records notes of features not translated and errors of features not representable
+// when export NetworkPolicy
+type networkPolicyExporter struct {
+	notes []string
+	errs  []error
+}
+
+func (e *networkPolicyExporter) notef(format string, args ...interface{}) {
+	e.notes = append(e.notes, fmt.Sprintf(format, args...))
+}
+
+func (e *networkPolicyExporter) refusef(format string, args ...interface{}) {
+	e.errs = append(e.errs, fmt.Errorf(format, args...))
+}
+
+func (e *networkPolicyExporter) exportPolicyFeatures(spec *v1alpha1.SecurityPolicySpec) {
+	if spec.Tier != constants.Tier2 {
+		e.notef("tier %s is not supported, NetworkPolicy works as tier %s", spec.Tier, constants.Tier2)
+	}
+	if spec.Priority != 0 {
+		e.notef("priority %d is not supported", spec.Priority)
+	}
+	if spec.SecurityPolicyEnforcementMode == v1alpha1.MonitorMode {
+		e.notef("policy monitor mode is not supported, NetworkPolicy always works")
+	}
+	if spec.SymmetricMode {
+		e.notef("symmetric mode is not supported, peers need their own NetworkPolicy")
+	}
+	if spec.IsBlocklist {
+		e.refusef("blocklist is not supported, rules of NetworkPolicy always allow traffic")
+	}
+	if spec.DefaultRule == v1alpha1.DefaultRuleNone {
+		e.notef("default rule none is not supported, NetworkPolicy drops traffic not allowed")
+	}
+	if spec.Logging != nil && spec.Logging.Enabled {
+		e.notef("logging is not supported")
+	}
+}
+
+func (e *networkPolicyExporter) exportRuleFeatures(direction string, rule *v1alpha1.Rule) {
+	if rule.EnforcementMode == v1alpha1.MonitorMode {
+		e.notef("%s rule %s monitor mode is not supported", direction, rule.Name)
+	}
+	if rule.Logging != nil && rule.Logging.Enabled {
+		e.notef("%s rule %s logging is not supported", direction, rule.Name)
+	}
+	if rule.TrafficLocality != "" {
+		e.refusef("%s rule %s traffic locality %s is not supported", direction, rule.Name, rule.TrafficLocality)
+	}
+	if rule.VlanID != nil {
+		e.refusef("%s rule %s vlan id %d is not supported", direction, rule.Name, *rule.VlanID)
+	}
+	if rule.ActiveFrom != nil || rule.ActiveUntil != nil {
+		e.refusef("%s rule %s active time window is not supported", direction, rule.Name)
+	}
+}
+
+// exportAppliedTo returns pod selector of the applied peers, empty applied peers select all pods
+func (e *networkPolicyExporter) exportAppliedTo(appliedTo []v1alpha1.ApplyToPeer) metav1.LabelSelector {
+	var selectors []metav1.LabelSelector
+	for _, peer := range appliedTo {
+		if peer.Endpoint != nil {
+			e.refusef("appliedTo endpoint %s is not supported", *peer.Endpoint)
+			continue
+		}
+		if peer.InterfaceName != nil {
+			e.refusef("appliedTo interface name %s is not supported", *peer.InterfaceName)
+			continue
+		}
+		if peer.VlanRange != nil {
+			e.refusef("appliedTo vlan range %d-%d is not supported", peer.VlanRange.Start, peer.VlanRange.End)
+			continue
+		}
+		selector, ok := e.exportSelector("appliedTo", peer.EndpointSelector)
+		if ok && selector != nil {
+			selectors = append(selectors, *selector)
+		}
+	}
+
+	switch len(selectors) {
+	case 0:
+		return metav1.LabelSelector{}
+	case 1:
+		return selectors[0]
+	default:
+		e.refusef("appliedTo with %d selectors is not supported", len(selectors))
+		return selectors[0]
+	}
+}
+
+func (e *networkPolicyExporter) exportSelector(item string, selector *labels.Selector) (*metav1.LabelSelector, bool) {
+	if selector == nil {
+		return nil, true
+	}
+	if selector.MatchNothing {
+		e.refusef("%s selector matches nothing is not supported", item)
+		return nil, false
+	}
+	if len(selector.ExtendMatchLabels) != 0 {
+		e.refusef("%s selector extendMatchLabels %v is not supported", item, selector.ExtendMatchLabels)
+		return nil, false
+	}
+	return selector.LabelSelector.DeepCopy(), true
+}
+
+func (e *networkPolicyExporter) exportPeers(ruleName string, peers []v1alpha1.SecurityPolicyPeer) []networkingv1.NetworkPolicyPeer {
+	var networkPolicyPeers []networkingv1.NetworkPolicyPeer
+	for _, peer := range peers {
+		switch {
+		case peer.IPBlock != nil:
+			networkPolicyPeers = append(networkPolicyPeers, networkingv1.NetworkPolicyPeer{IPBlock: peer.IPBlock.DeepCopy()})
+		case peer.Endpoint != nil:
+			e.refusef("rule %s peer endpoint %s/%s is not supported", ruleName, peer.Endpoint.Namespace, peer.Endpoint.Name)
+		case peer.EndpointNetwork != nil:
+			e.refusef("rule %s peer endpointNetwork %s is not supported", ruleName, *peer.EndpointNetwork)
+		case peer.IPPool != nil:
+			e.refusef("rule %s peer ipPool %s/%s is not supported", ruleName, peer.IPPool.Namespace, peer.IPPool.Name)
+		case peer.FQDN != "":
+			e.refusef("rule %s peer fqdn %s is not supported", ruleName, peer.FQDN)
+		default:
+			podSelector, ok := e.exportSelector(fmt.Sprintf("rule %s peer", ruleName), peer.EndpointSelector)
+			if !ok {
+				continue
+			}
+			networkPolicyPeers = append(networkPolicyPeers, networkingv1.NetworkPolicyPeer{
+				PodSelector:       podSelector,
+				NamespaceSelector: peer.NamespaceSelector.DeepCopy(),
+			})
+		}
+	}
+	return networkPolicyPeers
+}
+
+func (e *networkPolicyExporter) exportPorts(ruleName string, ports []v1alpha1.SecurityPolicyPort) []networkingv1.NetworkPolicyPort {
+	var networkPolicyPorts []networkingv1.NetworkPolicyPort
+	for _, port := range ports {
+		var protocol corev1.Protocol
+		switch port.Protocol {
+		case v1alpha1.ProtocolTCP, v1alpha1.ProtocolUDP, v1alpha1.Protocol(corev1.ProtocolSCTP):
+			protocol = corev1.Protocol(port.Protocol)
+		default:
+			e.refusef("rule %s protocol %s is not supported", ruleName, port.Protocol)
+			continue
+		}
+
+		if port.PortRange == "" {
+			networkPolicyPorts = append(networkPolicyPorts, networkingv1.NetworkPolicyPort{Protocol: &protocol})
+			continue
+		}
+		for _, item := range strings.Split(port.PortRange, ",") {
+			networkPolicyPort, err := exportPort(protocol, item, port.Type)
+			if err != nil {
+				e.refusef("rule %s port %s is not supported: %s", ruleName, item, err)
+				continue
+			}
+			networkPolicyPorts = append(networkPolicyPorts, *networkPolicyPort)
+		}
+	}
+	return networkPolicyPorts
+}
+
+// exportPort exports the port item, which is a port number, port range begin-end, or port name
+func exportPort(protocol corev1.Protocol, item string, portType v1alpha1.PortType) (*networkingv1.NetworkPolicyPort, error) {
+	if portType == v1alpha1.PortTypeName {
+		port := intstr.FromString(item)
+		return &networkingv1.NetworkPolicyPort{Protocol: &protocol, Port: &port}, nil
+	}
+
+	begin, end, isRange := strings.Cut(item, "-")
+	beginPort, err := strconv.ParseInt(begin, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	port := intstr.FromInt(int(beginPort))
+	networkPolicyPort := &networkingv1.NetworkPolicyPort{Protocol: &protocol, Port: &port}
+	if isRange {
+		endPort, err := strconv.ParseInt(end, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		if endPort != beginPort {
+			endPort32 := int32(endPort)
+			networkPolicyPort.EndPort = &endPort32
+		}
+	}
+	return networkPolicyPort, nil
+}
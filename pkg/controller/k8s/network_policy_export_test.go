@@ -0,0 +1,124 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+
+	"github.com/everoute/everoute/pkg/apis/security/v1alpha1"
+	"github.com/everoute/everoute/pkg/constants"
+	"github.com/everoute/everoute/pkg/labels"
+)
+
+func TestExportNetworkPolicy(t *testing.T) {
+	RegisterTestingT(t)
+
+	webSelector := &labels.Selector{LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}}
+	dbSelector := &labels.Selector{LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}}
+	policy := &v1alpha1.SecurityPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "allow-web", Namespace: "default"},
+		Spec: v1alpha1.SecurityPolicySpec{
+			Tier:        constants.Tier2,
+			AppliedTo:   []v1alpha1.ApplyToPeer{{EndpointSelector: webSelector}},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+			IngressRules: []v1alpha1.Rule{{
+				Name:  "ingress0",
+				Ports: []v1alpha1.SecurityPolicyPort{{Protocol: v1alpha1.ProtocolTCP, PortRange: "80,8000-8080"}},
+				From:  []v1alpha1.SecurityPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/24"}}},
+			}},
+			EgressRules: []v1alpha1.Rule{{
+				Name:  "egress0",
+				Ports: []v1alpha1.SecurityPolicyPort{{Protocol: v1alpha1.ProtocolUDP, PortRange: "53"}},
+				To:    []v1alpha1.SecurityPolicyPeer{{EndpointSelector: dbSelector}},
+			}},
+		},
+	}
+	tcp, udp := corev1.ProtocolTCP, corev1.ProtocolUDP
+	port80, port8000, port53 := intstr.FromInt(80), intstr.FromInt(8000), intstr.FromInt(53)
+
+	networkPolicy, notes, err := ExportNetworkPolicy(policy)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(notes).Should(BeEmpty())
+	Expect(networkPolicy.Name).Should(Equal("allow-web"))
+	Expect(networkPolicy.Namespace).Should(Equal("default"))
+	Expect(networkPolicy.Spec).Should(Equal(networkingv1.NetworkPolicySpec{
+		PodSelector: webSelector.LabelSelector,
+		PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+		Ingress: []networkingv1.NetworkPolicyIngressRule{{
+			Ports: []networkingv1.NetworkPolicyPort{
+				{Protocol: &tcp, Port: &port80},
+				{Protocol: &tcp, Port: &port8000, EndPort: pointer.Int32(8080)},
+			},
+			From: []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/24"}}},
+		}},
+		Egress: []networkingv1.NetworkPolicyEgressRule{{
+			Ports: []networkingv1.NetworkPolicyPort{{Protocol: &udp, Port: &port53}},
+			To:    []networkingv1.NetworkPolicyPeer{{PodSelector: &dbSelector.LabelSelector}},
+		}},
+	}))
+
+	t.Run("features only make policy stricter should be noted", func(t *testing.T) {
+		policy := policy.DeepCopy()
+		policy.Spec.Tier = constants.Tier1
+		policy.Spec.SecurityPolicyEnforcementMode = v1alpha1.MonitorMode
+		policy.Spec.DefaultRule = v1alpha1.DefaultRuleNone
+
+		networkPolicy, notes, err := ExportNetworkPolicy(policy)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(notes).Should(HaveLen(3))
+		Expect(networkPolicy.Spec.Ingress[0].Ports).Should(HaveLen(2))
+	})
+
+	t.Run("rules not representable should be refused", func(t *testing.T) {
+		for name, mutate := range map[string]func(policy *v1alpha1.SecurityPolicy){
+			"icmp port": func(policy *v1alpha1.SecurityPolicy) {
+				policy.Spec.IngressRules[0].Ports = []v1alpha1.SecurityPolicyPort{{Protocol: v1alpha1.ProtocolICMP}}
+			},
+			"endpoint peer": func(policy *v1alpha1.SecurityPolicy) {
+				policy.Spec.EgressRules[0].To = []v1alpha1.SecurityPolicyPeer{{
+					Endpoint: &v1alpha1.NamespacedName{Namespace: "default", Name: "ep1"},
+				}}
+			},
+			"selector matches nothing": func(policy *v1alpha1.SecurityPolicy) {
+				policy.Spec.EgressRules[0].To = []v1alpha1.SecurityPolicyPeer{{EndpointSelector: &labels.Selector{MatchNothing: true}}}
+			},
+			"multiple appliedTo": func(policy *v1alpha1.SecurityPolicy) {
+				policy.Spec.AppliedTo = append(policy.Spec.AppliedTo, v1alpha1.ApplyToPeer{EndpointSelector: dbSelector})
+			},
+			"vlan id": func(policy *v1alpha1.SecurityPolicy) {
+				policy.Spec.IngressRules[0].VlanID = pointer.Int32(10)
+			},
+			"blocklist": func(policy *v1alpha1.SecurityPolicy) {
+				policy.Spec.IsBlocklist = true
+			},
+		} {
+			policy := policy.DeepCopy()
+			mutate(policy)
+			networkPolicy, _, err := ExportNetworkPolicy(policy)
+			if err == nil || networkPolicy != nil {
+				t.Errorf("export policy with %s should be refused, got %v", name, networkPolicy)
+			}
+		}
+	})
+}
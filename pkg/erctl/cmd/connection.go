@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/everoute/everoute/pkg/erctl"
+)
+
+var (
+	connPolicyNs string
+	connLimit    int
+)
+
+var connectionCmd = &cobra.Command{
+	Use:     "connection",
+	Aliases: []string{"conn"},
+	Short: "get deduplicated established connections permitted by allow rules of the policy in local agent\n" +
+		"-n policy-namespace, default value is 'default'\n" +
+		"--limit max number of connections, connections over it are truncated",
+	Example: "erctl get conn [policyname] -n [nsname] --limit 100",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if connLimit <= 0 {
+			return fmt.Errorf("limit must be positive, got %d", connLimit)
+		}
+		err := erctl.ConnectRule(false)
+		if err != nil {
+			return err
+		}
+		inventory, err := erctl.GetPolicyConnections(connPolicyNs, args[0], connLimit)
+		if err != nil {
+			return err
+		}
+
+		out, err := setOutput()
+		if err != nil {
+			return err
+		}
+		return print(out, inventory)
+	},
+}
+
+func init() {
+	getCmd.AddCommand(connectionCmd)
+	connectionCmd.Flags().StringVarP(&connPolicyNs, "namespace", "n", "default", "namespace of the policy")
+	connectionCmd.Flags().IntVar(&connLimit, "limit", erctl.DefaultConnectionLimit, "max number of connections")
+}
@@ -12,6 +12,7 @@ import (
 
 var (
 	srcIP, dstIP, dstPort, protocol string
+	vdsID                           string
 	showCTflows                     bool
 )
 
@@ -21,6 +22,7 @@ var ruleCmd = &cobra.Command{
 	Long: "use grpc to get rules, default get all\n" +
 		"-f means get rule by flowids\n" +
 		"-r means get rule by ruleids\n" +
+		"--vds means only get rule flows of the vds\n" +
 		"sort rules by --srcip --dstip --dstport --protocol",
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		err = erctl.ConnectRule(showCTflows)
@@ -31,11 +33,11 @@ var ruleCmd = &cobra.Command{
 		var rules interface{}
 		switch {
 		case len(ruleIDs) != 0:
-			rules, err = erctl.GetRulesByName(ruleIDs)
+			rules, err = erctl.GetRulesByName(vdsID, ruleIDs)
 		case len(flowIDs) != 0:
-			rules, err = erctl.GetRulesByFlow(flowIDs)
+			rules, err = erctl.GetRulesByFlow(vdsID, flowIDs)
 		default:
-			rules, err = erctl.GetAllRules()
+			rules, err = erctl.GetAllRules(vdsID)
 		}
 		if err != nil {
 			return err
@@ -77,6 +79,7 @@ func init() {
 	ruleCmd.Flags().StringVar(&dstPort, "dstport", "", "specify destination port")
 	ruleCmd.Flags().StringVar(&protocol, "protocol", "", "specify protocol")
 	ruleCmd.Flags().BoolVar(&showCTflows, "ctflows", false, "use to show ctflows")
+	ruleCmd.Flags().StringVar(&vdsID, "vds", "", "specify vds to get rule flows")
 }
 
 func appendToSort() {
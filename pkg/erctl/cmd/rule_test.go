@@ -13,7 +13,7 @@ var _ = Describe("Rule", func() {
 	gomonkey.ApplyFunc(erctl.ConnectRule, func(bool) error {
 		return nil
 	})
-	gomonkey.ApplyFunc(erctl.GetAllRules, func() ([]*erctl.Rule, error) {
+	gomonkey.ApplyFunc(erctl.GetAllRules, func(string) ([]*erctl.Rule, error) {
 		r1 := &v1alpha1.RuleEntry{
 			EveroutePolicyRule: &v1alpha1.PolicyRule{
 				DstIPAddr: "10.0.0.3",
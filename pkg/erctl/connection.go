@@ -0,0 +1,97 @@
+package erctl
+
+import (
+	"context"
+	"sort"
+
+	"github.com/everoute/everoute/pkg/apis/rpc/v1alpha1"
+)
+
+// DefaultConnectionLimit is the default max number of connections in a policy connection inventory
+const DefaultConnectionLimit = 1000
+
+const ruleActionAllow = "allow"
+
+// Connection is an established connection permitted by the policy rules
+type Connection struct {
+	SrcIP    string `json:"SrcIP"`
+	DstIP    string `json:"DstIP"`
+	Protocol uint32 `json:"Protocol"`
+	DstPort  uint32 `json:"DstPort"`
+	State    string `json:"State"`
+}
+
+// ConnectionInventory is the connection list of a policy, Truncated means connections over the limit
+// are dropped
+type ConnectionInventory struct {
+	Namespace   string       `json:"Namespace"`
+	Name        string       `json:"Name"`
+	Connections []Connection `json:"Connections"`
+	Truncated   bool         `json:"Truncated,omitempty"`
+}
+
+// GetPolicyConnections returns the deduplicated established connections permitted by allow rules of the policy,
+// at most limit connections returned. ConnectRule must be called before.
+func GetPolicyConnections(namespace, name string, limit int) (*ConnectionInventory, error) {
+	ruleEntries, err := ruleconn.GetAllRules(context.Background(), &v1alpha1.RuleQuery{})
+	if err != nil {
+		return nil, err
+	}
+	return policyConnections(ruleEntries.GetRuleEntries(), namespace, name, limit), nil
+}
+
+func policyConnections(ruleEntries []*v1alpha1.RuleEntry, namespace, name string, limit int) *ConnectionInventory {
+	inventory := &ConnectionInventory{Namespace: namespace, Name: name, Connections: []Connection{}}
+	seenFlows := make(map[uint64]bool)
+	seenConns := make(map[Connection]bool)
+
+	for _, entry := range ruleEntries {
+		if entry.GetEveroutePolicyRule().GetAction() != ruleActionAllow || !referencePolicy(entry, namespace, name) {
+			continue
+		}
+		for _, flowEntry := range entry.GetRuleFlowMap() {
+			if seenFlows[flowEntry.GetFlowID()] {
+				continue
+			}
+			seenFlows[flowEntry.GetFlowID()] = true
+			for _, tp := range cnt[flowEntry.GetFlowID()] {
+				if !tp.established {
+					continue
+				}
+				conn := Connection{SrcIP: tp.srcIP, DstIP: tp.dstIP, Protocol: tp.protocol, DstPort: tp.dstPort, State: tp.status}
+				if seenConns[conn] {
+					continue
+				}
+				seenConns[conn] = true
+				inventory.Connections = append(inventory.Connections, conn)
+			}
+		}
+	}
+
+	sort.Slice(inventory.Connections, func(i, j int) bool {
+		a, b := inventory.Connections[i], inventory.Connections[j]
+		if a.SrcIP != b.SrcIP {
+			return a.SrcIP < b.SrcIP
+		}
+		if a.DstIP != b.DstIP {
+			return a.DstIP < b.DstIP
+		}
+		if a.Protocol != b.Protocol {
+			return a.Protocol < b.Protocol
+		}
+		return a.DstPort < b.DstPort
+	})
+	if len(inventory.Connections) > limit {
+		inventory.Connections, inventory.Truncated = inventory.Connections[:limit], true
+	}
+	return inventory
+}
+
+func referencePolicy(entry *v1alpha1.RuleEntry, namespace, name string) bool {
+	for _, ref := range entry.GetPolicyRuleReference() {
+		if ref.GetNameSpace() == namespace && ref.GetName() == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package erctl
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/everoute/everoute/pkg/apis/rpc/v1alpha1"
+)
+
+func TestPolicyConnections(t *testing.T) {
+	RegisterTestingT(t)
+
+	ruleEntry := func(ruleID, action, policyName string, flowIDs ...uint64) *v1alpha1.RuleEntry {
+		entry := &v1alpha1.RuleEntry{
+			EveroutePolicyRule:  &v1alpha1.PolicyRule{RuleID: ruleID, Action: action},
+			RuleFlowMap:         map[string]*v1alpha1.FlowEntry{},
+			PolicyRuleReference: []*v1alpha1.PolicyRuleReference{{NameSpace: "default", Name: policyName}},
+		}
+		for i, flowID := range flowIDs {
+			entry.RuleFlowMap[string(rune('a'+i))] = &v1alpha1.FlowEntry{FlowID: flowID}
+		}
+		return entry
+	}
+	ruleEntries := []*v1alpha1.RuleEntry{
+		ruleEntry("web-ingress", ruleActionAllow, "web", 1, 2),
+		ruleEntry("web-egress", ruleActionAllow, "web", 3),
+		ruleEntry("web-default", "deny", "web", 4),
+		ruleEntry("db-ingress", ruleActionAllow, "db", 5),
+	}
+
+	established := func(src, dst string, srcPort, dstPort uint32) tuple {
+		return tuple{srcIP: src, dstIP: dst, srcPort: srcPort, dstPort: dstPort, protocol: 6, status: "SEEN_REPLY|ASSURED", established: true}
+	}
+	cnt = map[uint64][]tuple{
+		1: {established("10.0.0.1", "10.0.0.10", 40001, 80), established("10.0.0.1", "10.0.0.10", 40002, 80)},
+		2: {established("10.0.0.2", "10.0.0.10", 40001, 80)},
+		3: {established("10.0.0.10", "10.0.0.20", 40001, 3306), {srcIP: "10.0.0.3", dstIP: "10.0.0.10", dstPort: 80, protocol: 6}},
+		4: {established("10.0.0.4", "10.0.0.10", 40001, 22)},
+		5: {established("10.0.0.5", "10.0.0.20", 40001, 3306)},
+	}
+	defer func() { cnt = nil }()
+
+	t.Run("inventory should reflect established connections matching allow rules of the policy", func(t *testing.T) {
+		inventory := policyConnections(ruleEntries, "default", "web", DefaultConnectionLimit)
+		Expect(inventory.Truncated).Should(BeFalse())
+		Expect(inventory.Connections).Should(Equal([]Connection{
+			{SrcIP: "10.0.0.1", DstIP: "10.0.0.10", Protocol: 6, DstPort: 80, State: "SEEN_REPLY|ASSURED"},
+			{SrcIP: "10.0.0.10", DstIP: "10.0.0.20", Protocol: 6, DstPort: 3306, State: "SEEN_REPLY|ASSURED"},
+			{SrcIP: "10.0.0.2", DstIP: "10.0.0.10", Protocol: 6, DstPort: 80, State: "SEEN_REPLY|ASSURED"},
+		}))
+	})
+
+	t.Run("inventory should be bounded by the limit", func(t *testing.T) {
+		inventory := policyConnections(ruleEntries, "default", "web", 2)
+		Expect(inventory.Truncated).Should(BeTrue())
+		Expect(inventory.Connections).Should(HaveLen(2))
+	})
+
+	t.Run("inventory of unknown policy should be empty", func(t *testing.T) {
+		inventory := policyConnections(ruleEntries, "default", "unknown", DefaultConnectionLimit)
+		Expect(inventory.Connections).Should(BeEmpty())
+		Expect(inventory.Truncated).Should(BeFalse())
+	})
+}
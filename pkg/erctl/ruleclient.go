@@ -8,7 +8,6 @@ import (
 
 	"github.com/ti-mo/conntrack"
 	"google.golang.org/grpc"
-	"google.golang.org/protobuf/types/known/emptypb"
 
 	"github.com/everoute/everoute/pkg/apis/rpc/v1alpha1"
 	"github.com/everoute/everoute/pkg/constants"
@@ -61,8 +60,8 @@ func ConnectRule(show bool) error {
 	return err
 }
 
-func GetAllRules() ([]*Rule, error) {
-	ruleEntries, err := ruleconn.GetAllRules(context.Background(), &emptypb.Empty{})
+func GetAllRules(vdsID string) ([]*Rule, error) {
+	ruleEntries, err := ruleconn.GetAllRules(context.Background(), &v1alpha1.RuleQuery{VdsID: vdsID})
 	if err != nil {
 		return nil, err
 	}
@@ -70,8 +69,8 @@ func GetAllRules() ([]*Rule, error) {
 	return rules, nil
 }
 
-func GetRulesByName(ruleIDs []string) ([]*Rule, error) {
-	ruleEntries, err := ruleconn.GetRulesByName(context.Background(), &v1alpha1.RuleIDs{RuleIDs: ruleIDs})
+func GetRulesByName(vdsID string, ruleIDs []string) ([]*Rule, error) {
+	ruleEntries, err := ruleconn.GetRulesByName(context.Background(), &v1alpha1.RuleIDs{RuleIDs: ruleIDs, VdsID: vdsID})
 	if err != nil {
 		return nil, err
 	}
@@ -79,12 +78,12 @@ func GetRulesByName(ruleIDs []string) ([]*Rule, error) {
 	return rules, nil
 }
 
-func GetRulesByFlow(flowIDs []int64) ([]*Rule, error) {
+func GetRulesByFlow(vdsID string, flowIDs []int64) ([]*Rule, error) {
 	fids := make([]uint64, len(flowIDs))
 	for i := 0; i < len(flowIDs); i++ {
 		fids[i] = uint64(flowIDs[i])
 	}
-	ruleEntries, err := ruleconn.GetRulesByFlow(context.Background(), &v1alpha1.FlowIDs{FlowIDs: fids})
+	ruleEntries, err := ruleconn.GetRulesByFlow(context.Background(), &v1alpha1.FlowIDs{FlowIDs: fids, VdsID: vdsID})
 	if err != nil {
 		return nil, err
 	}
@@ -100,6 +99,8 @@ type tuple struct {
 	srcIP, dstIP, status string
 	srcPort, dstPort     uint32
 	protocol             uint32
+	// established means reply of the connection seen and the connection is not dying
+	established bool
 }
 
 func (t tuple) MarshalJSON() ([]byte, error) {
@@ -120,6 +121,8 @@ func parseFlows(flows []conntrack.Flow) {
 			dstPort:  uint32(flow.TupleOrig.Proto.DestinationPort),
 			protocol: uint32(flow.TupleOrig.Proto.Protocol),
 			status:   flow.Status.String(),
+
+			established: flow.Status.SeenReply() && !flow.Status.Dying(),
 		}
 		u1, u2, u3 := utils.CtLabelDecode(flow.Labels)
 		addCnt(origTuple, u1, u2, u3)
@@ -47,6 +47,7 @@ import (
 const (
 	LocalEndpointIdentity = "attached-mac"
 	LocalEndpointIPv4     = "attached-ipv4"
+	LocalEndpointNotReady = "endpoint-not-ready"
 	InterfaceDriver       = "driver_name"
 	InterfaceStatus       = "status"
 	AgentInfoSyncInterval = 60
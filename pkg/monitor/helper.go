@@ -81,6 +81,16 @@ func getIPv4Addr(externalIDs map[interface{}]interface{}) net.IP {
 	return nil
 }
 
+// getEndpointNotReady returns true if the endpoint has been marked not ready by the cni, traffic to it
+// would be dropped until it marked ready
+func getEndpointNotReady(externalIDs map[interface{}]interface{}) bool {
+	if notReady, ok := externalIDs[LocalEndpointNotReady]; ok {
+		return notReady.(string) == "true"
+	}
+
+	return false
+}
+
 func getDriverNameFromInterface(row ovsdb.Row) string {
 	if status, ok := row.Fields[InterfaceStatus].(ovsdb.OvsMap); ok {
 		if driver, ok := status.GoMap[InterfaceDriver]; ok {
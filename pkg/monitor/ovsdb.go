@@ -210,6 +210,7 @@ func (monitor *OVSDBMonitor) filterPortVlanModeUpdate(rowupdate ovsdb.RowUpdate,
 			MacAddrStr:    oldEndpoint.MacAddrStr,
 			PortNo:        oldEndpoint.PortNo,
 			BridgeName:    oldEndpoint.BridgeName,
+			NotReady:      oldEndpoint.NotReady,
 			Trunk:         trunkString,
 			VlanID:        0,
 		}
@@ -222,6 +223,7 @@ func (monitor *OVSDBMonitor) filterPortVlanModeUpdate(rowupdate ovsdb.RowUpdate,
 			MacAddrStr:    oldEndpoint.MacAddrStr,
 			PortNo:        oldEndpoint.PortNo,
 			BridgeName:    oldEndpoint.BridgeName,
+			NotReady:      oldEndpoint.NotReady,
 			VlanID:        uint16(*newTag),
 			Trunk:         "",
 		}
@@ -253,6 +255,7 @@ func (monitor *OVSDBMonitor) filterPortVlanTagUpdate(rowupdate ovsdb.RowUpdate,
 		MacAddrStr:    oldEndpoint.MacAddrStr,
 		PortNo:        oldEndpoint.PortNo,
 		BridgeName:    oldEndpoint.BridgeName,
+		NotReady:      oldEndpoint.NotReady,
 		VlanID:        uint16(newTag),
 		Trunk:         "",
 	}
@@ -285,6 +288,7 @@ func (monitor *OVSDBMonitor) filterPortVlanTrunkUpdate(rowupdate ovsdb.RowUpdate
 		MacAddrStr:    oldEndpoint.MacAddrStr,
 		PortNo:        oldEndpoint.PortNo,
 		BridgeName:    oldEndpoint.BridgeName,
+		NotReady:      oldEndpoint.NotReady,
 		Trunk:         strings.Trim(strings.Join(strings.Split(fmt.Sprintf("%v", newTrunk), " "), ","), "[]"),
 	}
 
@@ -394,6 +398,7 @@ func (monitor *OVSDBMonitor) processOvsInterfaceAdd(uuid string, rowupdate ovsdb
 	if newExternalIds, ok := rowupdate.New.Fields["external_ids"].(ovsdb.OvsMap); ok {
 		ip := getIPv4Addr(newExternalIds.GoMap)
 		monitor.endpointMap[uuid].IPAddr = ip
+		monitor.endpointMap[uuid].NotReady = getEndpointNotReady(newExternalIds.GoMap)
 	}
 
 	// if endpoint info is ready, trigger endpoint add callback
@@ -491,8 +496,10 @@ func (monitor *OVSDBMonitor) processOvsInterfaceUpdate(uuid string, rowupdate ov
 	}
 
 	var newIP net.IP
+	var newNotReady bool
 	if newExternalIds, ok := rowupdate.New.Fields["external_ids"].(ovsdb.OvsMap); ok {
 		newIP = getIPv4Addr(newExternalIds.GoMap)
+		newNotReady = getEndpointNotReady(newExternalIds.GoMap)
 	}
 
 	var newEndpoint, oldEndpoint *datapath.Endpoint
@@ -504,6 +511,7 @@ func (monitor *OVSDBMonitor) processOvsInterfaceUpdate(uuid string, rowupdate ov
 			MacAddrStr:    newMacStr,
 			IPAddr:        utils.IPCopy(newIP),
 			PortNo:        newOfPort,
+			NotReady:      newNotReady,
 		}
 		return
 	}
@@ -524,6 +532,7 @@ func (monitor *OVSDBMonitor) processOvsInterfaceUpdate(uuid string, rowupdate ov
 	}
 
 	newEndpoint.IPAddr = utils.IPCopy(newIP)
+	newEndpoint.NotReady = newNotReady
 
 	if oldEndpoint.PortNo != newOfPort {
 		newEndpoint.PortNo = newOfPort
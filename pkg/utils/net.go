@@ -19,6 +19,8 @@ package utils
 import (
 	"fmt"
 	"net"
+	"net/netip"
+	"sort"
 
 	"github.com/vishvananda/netlink"
 	"golang.org/x/sys/unix"
@@ -209,3 +211,51 @@ func RuleDel(rule *netlink.Rule, filterMask uint64) error {
 	}
 	return nil
 }
+
+// MergeIPPrefixes return the minimal prefixes cover exactly the same addresses as the given prefixes,
+// prefixes must be masked.
+func MergeIPPrefixes(prefixes []netip.Prefix) []netip.Prefix {
+	merged := prefixes
+	for {
+		sort.Slice(merged, func(i, j int) bool {
+			if c := merged[i].Addr().Compare(merged[j].Addr()); c != 0 {
+				return c < 0
+			}
+			return merged[i].Bits() < merged[j].Bits()
+		})
+
+		var next []netip.Prefix
+		var changed bool
+		for _, prefix := range merged {
+			if len(next) == 0 {
+				next = append(next, prefix)
+				continue
+			}
+			last := next[len(next)-1]
+			switch {
+			case last.Contains(prefix.Addr()) && last.Bits() <= prefix.Bits():
+				// covered by the previous prefix
+				changed = true
+			case isSiblingPrefix(last, prefix):
+				next[len(next)-1] = netip.PrefixFrom(last.Addr(), last.Bits()-1).Masked()
+				changed = true
+			default:
+				next = append(next, prefix)
+			}
+		}
+
+		merged = next
+		if !changed {
+			return merged
+		}
+	}
+}
+
+// isSiblingPrefix return true if the prefixes are two halves of the same parent prefix
+func isSiblingPrefix(a, b netip.Prefix) bool {
+	if a.Bits() != b.Bits() || a.Bits() == 0 || a.Addr().Is4() != b.Addr().Is4() || a == b {
+		return false
+	}
+	parent := netip.PrefixFrom(a.Addr(), a.Bits()-1).Masked()
+	return parent.Contains(b.Addr())
+}
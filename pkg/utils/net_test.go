@@ -19,6 +19,7 @@ package utils
 import (
 	"fmt"
 	"net"
+	"net/netip"
 	"testing"
 
 	. "github.com/onsi/gomega"
@@ -88,6 +89,51 @@ func TestParseIPBlock(t *testing.T) {
 	}
 }
 
+func TestMergeIPPrefixes(t *testing.T) {
+	RegisterTestingT(t)
+
+	tests := []struct {
+		name     string
+		prefixes []string
+		want     []string
+	}{
+		{
+			name:     "should merge covered and adjacent prefixes",
+			prefixes: []string{"10.0.0.0/24", "10.0.0.0/25", "10.0.0.128/25"},
+			want:     []string{"10.0.0.0/24"},
+		},
+		{
+			name:     "should merge adjacent prefixes recursively",
+			prefixes: []string{"10.0.0.3/32", "10.0.0.0/32", "10.0.0.2/32", "10.0.0.1/32"},
+			want:     []string{"10.0.0.0/30"},
+		},
+		{
+			name:     "should drop duplicate prefixes",
+			prefixes: []string{"10.0.0.1/32", "10.0.0.1/32", "fe80::1/128", "fe80::1/128"},
+			want:     []string{"10.0.0.1/32", "fe80::1/128"},
+		},
+		{
+			name:     "should not merge unaligned prefixes",
+			prefixes: []string{"10.0.0.1/32", "10.0.0.2/32", "10.0.0.4/30"},
+			want:     []string{"10.0.0.1/32", "10.0.0.2/32", "10.0.0.4/30"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var prefixes []netip.Prefix
+			for _, prefix := range tt.prefixes {
+				prefixes = append(prefixes, netip.MustParsePrefix(prefix))
+			}
+			var got []string
+			for _, prefix := range MergeIPPrefixes(prefixes) {
+				got = append(got, prefix.String())
+			}
+			Expect(got).Should(Equal(tt.want))
+		})
+	}
+}
+
 func mustParseCIDR(cidr string) *net.IPNet {
 	_, ipNet, err := net.ParseCIDR(cidr)
 	if err != nil {
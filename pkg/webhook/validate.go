@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 
 	admv1 "k8s.io/api/admission/v1"
@@ -38,11 +39,17 @@ type ValidateHandle interface {
 // ValidateWebhook register webhook for validate everoute objects.
 type ValidateWebhook struct {
 	Scheme *runtime.Scheme
+	// ClusterPodCIDR warns ipBlock peers overlap with it, nil means never warn
+	ClusterPodCIDR *net.IPNet
+	// CustomPolicyTiers allows securityPolicy attached to the tiers besides the builtin tiers
+	CustomPolicyTiers []string
 }
 
 // SetupWithManager create and add a ValidateWebhook to the manager.
 func (v *ValidateWebhook) SetupWithManager(mgr ctrl.Manager) error {
 	crdValidate := validates.NewCRDValidate(mgr.GetClient(), mgr.GetScheme())
+	crdValidate.SetClusterPodCIDR(v.ClusterPodCIDR)
+	crdValidate.SetCustomTiers(v.CustomPolicyTiers...)
 
 	mgr.GetWebhookServer().Register("/validate/crds", v.Handler(crdValidate))
 	return nil
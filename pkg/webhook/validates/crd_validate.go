@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"path"
 	"regexp"
 	"strconv"
 	"strings"
@@ -45,19 +46,28 @@ import (
 	"github.com/everoute/everoute/pkg/labels"
 )
 
+// maxVlanID is the max vlan id of 802.1Q
+const maxVlanID = 4095
+
 // CRDValidate maintains list of validator for validate everoute objects.
 type CRDValidate struct {
 	client   client.Client
 	scheme   *runtime.Scheme
 	validate map[metav1.GroupVersionKind][]validator
+
+	// clusterPodCIDR warns ipBlock peers overlap with it, nil means never warn
+	clusterPodCIDR *net.IPNet
+	// customTiers are policy tiers configured on agents besides the builtin tiers
+	customTiers sets.Set[string]
 }
 
 // NewCRDValidate return a new *CRDValidate and register validators.
 func NewCRDValidate(client client.Client, scheme *runtime.Scheme) *CRDValidate {
 	v := &CRDValidate{
-		client:   client,
-		scheme:   scheme,
-		validate: make(map[metav1.GroupVersionKind][]validator),
+		client:      client,
+		scheme:      scheme,
+		validate:    make(map[metav1.GroupVersionKind][]validator),
+		customTiers: sets.New[string](),
 	}
 
 	// security.everoute.io/v1alpha1 endpoint validator
@@ -79,7 +89,7 @@ func NewCRDValidate(client client.Client, scheme *runtime.Scheme) *CRDValidate {
 		Group:   "security.everoute.io",
 		Version: "v1alpha1",
 		Kind:    "SecurityPolicy",
-	}, &securityPolicyValidator{v.client})
+	}, &securityPolicyValidator{Client: v.client, customTiers: v.customTiers})
 
 	// security.everoute.io/v1alpha1 globalpolicy validator
 	v.register(metav1.GroupVersionKind{
@@ -172,9 +182,14 @@ func (v *CRDValidate) Validate(ar *admv1.AdmissionReview) *admv1.AdmissionRespon
 			Message: msg,
 		}
 	}
+	var warnings []string
+	if allowed && (operation == admv1.Create || operation == admv1.Update) {
+		warnings = v.warnings(curObj)
+	}
 	return &admv1.AdmissionResponse{
-		Allowed: allowed,
-		Result:  result,
+		Allowed:  allowed,
+		Result:   result,
+		Warnings: warnings,
 	}
 }
 
@@ -194,6 +209,30 @@ func (v *CRDValidate) unmarshal(raw []byte, gvk metav1.GroupVersionKind) (runtim
 	return obj, nil
 }
 
+// SetClusterPodCIDR sets the cluster pod cidr, the allowed securityPolicy with ipBlock peers overlap
+// the cidr would be warned, because the ipBlock often unintentionally allow or deny pod traffic.
+func (v *CRDValidate) SetClusterPodCIDR(cidr *net.IPNet) {
+	v.clusterPodCIDR = cidr
+}
+
+// SetCustomTiers allows securityPolicy attached to the custom tiers, the tiers must be configured on agents
+// in policyTiers, and they only support work mode.
+func (v *CRDValidate) SetCustomTiers(tiers ...string) {
+	v.customTiers.Insert(tiers...)
+}
+
+// warnings returns warnings of the object, it never rejects the object.
+func (v *CRDValidate) warnings(obj runtime.Object) []string {
+	if v.clusterPodCIDR == nil {
+		return nil
+	}
+	policy, ok := obj.(*securityv1alpha1.SecurityPolicy)
+	if !ok {
+		return nil
+	}
+	return ipBlockPodCIDRWarnings(append(policy.Spec.IngressRules, policy.Spec.EgressRules...), v.clusterPodCIDR)
+}
+
 func (v *CRDValidate) register(kind metav1.GroupVersionKind, t validator) {
 	v.validate[kind] = append(v.validate[kind], t)
 }
@@ -294,7 +333,10 @@ func (v endpointGroupValidator) deleteValidate(oldObj runtime.Object, userInfo a
 	return "", true
 }
 
-type securityPolicyValidator resourceValidator
+type securityPolicyValidator struct {
+	client.Client
+	customTiers sets.Set[string]
+}
 
 func (v securityPolicyValidator) createValidate(curObj runtime.Object, userInfo authv1.UserInfo) (string, bool) {
 	err := v.validatePolicy(curObj.(*securityv1alpha1.SecurityPolicy))
@@ -325,6 +367,12 @@ func (v *securityPolicyValidator) validatePolicy(policy *securityv1alpha1.Securi
 			return fmt.Errorf("monitor mode doesn't support tier %s", policy.Spec.Tier)
 		}
 	default:
+		if v.customTiers.Has(policy.Spec.Tier) {
+			if policy.Spec.SecurityPolicyEnforcementMode == securityv1alpha1.MonitorMode {
+				return fmt.Errorf("monitor mode doesn't support custom tier %s", policy.Spec.Tier)
+			}
+			break
+		}
 		return fmt.Errorf("tier %s not in: %s, %s, %s, %s", policy.Spec.Tier, constants.Tier0, constants.Tier1, constants.Tier2, constants.TierECP)
 	}
 
@@ -338,6 +386,30 @@ func (v *securityPolicyValidator) validatePolicy(policy *securityv1alpha1.Securi
 		}
 	}
 
+	if policy.Spec.BlockARP {
+		if policy.Spec.Tier != constants.Tier0 || policy.Spec.DefaultRule != securityv1alpha1.DefaultRuleDrop {
+			return fmt.Errorf("blockARP only supported by %s policy with default rule drop", constants.Tier0)
+		}
+		if policy.Spec.SecurityPolicyEnforcementMode == securityv1alpha1.MonitorMode {
+			return fmt.Errorf("blockARP doesn't support monitor mode")
+		}
+		if len(policy.Spec.IngressRules) != 0 || len(policy.Spec.EgressRules) != 0 {
+			return fmt.Errorf("blockARP policy can't have ingress or egress rules")
+		}
+		if len(policy.Spec.AppliedTo) == 0 {
+			return fmt.Errorf("blockARP policy must set appliedTo")
+		}
+	}
+
+	if len(policy.Spec.SymmetricPolicyTypes) != 0 && !policy.Spec.SymmetricMode {
+		return fmt.Errorf("symmetricPolicyTypes can only be set with SymmetricMode")
+	}
+	for _, policyType := range policy.Spec.SymmetricPolicyTypes {
+		if policyType != networkingv1.PolicyTypeIngress && policyType != networkingv1.PolicyTypeEgress {
+			return fmt.Errorf("symmetricPolicyTypes %s not in: %s, %s", policyType, networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress)
+		}
+	}
+
 	// check validate of spec.appliedTo
 	err := v.validateAppliedTo(policy.Spec.AppliedTo)
 	if err != nil {
@@ -355,8 +427,26 @@ func (v *securityPolicyValidator) validatePolicy(policy *securityv1alpha1.Securi
 
 func (v *securityPolicyValidator) validateAppliedTo(appliedTo []securityv1alpha1.ApplyToPeer) error {
 	for _, peer := range appliedTo {
+		if peer.InterfaceName != nil {
+			if peer.Endpoint != nil || peer.EndpointSelector != nil || peer.VlanRange != nil {
+				return fmt.Errorf("InterfaceName cannot be set with Endpoint, EndpointSelector or VlanRange")
+			}
+			if _, err := path.Match(*peer.InterfaceName, ""); err != nil || *peer.InterfaceName == "" {
+				return fmt.Errorf("%s not a available interface name pattern", *peer.InterfaceName)
+			}
+			continue
+		}
+		if peer.VlanRange != nil {
+			if peer.Endpoint != nil || peer.EndpointSelector != nil {
+				return fmt.Errorf("VlanRange cannot be set with Endpoint or EndpointSelector")
+			}
+			if peer.VlanRange.Start > peer.VlanRange.End || peer.VlanRange.End > maxVlanID {
+				return fmt.Errorf("%+v not a available vlan range, must be in [0, %d]", *peer.VlanRange, maxVlanID)
+			}
+			continue
+		}
 		if peer.Endpoint == nil && peer.EndpointSelector == nil {
-			return fmt.Errorf("must specific one of Endpoint or EndpointSelector")
+			return fmt.Errorf("must specific one of Endpoint, EndpointSelector, InterfaceName or VlanRange")
 		}
 		if peer.Endpoint != nil && peer.EndpointSelector != nil {
 			return fmt.Errorf("cannot both set Endpoint and EndpointSelector")
@@ -402,6 +492,16 @@ func (v *securityPolicyValidator) validateRule(rule *securityv1alpha1.Rule) erro
 	ruleErrList := make([]error, 0, len(rulePeerList))
 	portErrList := make([]error, 0, len(rule.Ports))
 
+	if rule.ActiveFrom != nil && rule.ActiveUntil != nil && !rule.ActiveUntil.After(rule.ActiveFrom.Time) {
+		ruleErrList = append(ruleErrList, fmt.Errorf("activeUntil %s must be after activeFrom %s", rule.ActiveUntil, rule.ActiveFrom))
+	}
+
+	for item := range rule.From {
+		if rule.From[item].FQDN != "" {
+			ruleErrList = append(ruleErrList, fmt.Errorf("fqdn peer %s only supported in egress rules", rule.From[item].FQDN))
+		}
+	}
+
 	for item := range rulePeerList {
 		err := v.validateRulePeer(&rulePeerList[item])
 		if err != nil {
@@ -428,7 +528,7 @@ func (v *securityPolicyValidator) validateRule(rule *securityv1alpha1.Rule) erro
 
 func (v *securityPolicyValidator) validateRulePeer(peer *securityv1alpha1.SecurityPolicyPeer) error {
 	if peer.IPBlock != nil {
-		if peer.Endpoint != nil || peer.EndpointSelector != nil || peer.NamespaceSelector != nil {
+		if peer.Endpoint != nil || peer.EndpointSelector != nil || peer.NamespaceSelector != nil || peer.EndpointNetwork != nil || peer.IPPool != nil || peer.FQDN != "" {
 			return fmt.Errorf("ipBlock is set then neither of the other fields can be")
 		}
 		if err := validateIPBlock(*peer.IPBlock); err != nil {
@@ -437,6 +537,36 @@ func (v *securityPolicyValidator) validateRulePeer(peer *securityv1alpha1.Securi
 		return nil
 	}
 
+	if peer.EndpointNetwork != nil {
+		if peer.Endpoint != nil || peer.EndpointSelector != nil || peer.NamespaceSelector != nil || peer.IPPool != nil || peer.FQDN != "" {
+			return fmt.Errorf("endpointNetwork is set then neither of the other fields can be")
+		}
+		return nil
+	}
+
+	if peer.IPPool != nil {
+		if peer.Endpoint != nil || peer.EndpointSelector != nil || peer.NamespaceSelector != nil || peer.FQDN != "" {
+			return fmt.Errorf("ipPool is set then neither of the other fields can be")
+		}
+		es1 := validation.IsDNS1123Subdomain(peer.IPPool.Name)
+		es2 := validation.IsDNS1123Subdomain(peer.IPPool.Namespace)
+		if len(es1)+len(es2) != 0 {
+			return fmt.Errorf("%+v not a available ipPool", peer.IPPool)
+		}
+		return nil
+	}
+
+	if peer.FQDN != "" {
+		if peer.Endpoint != nil || peer.EndpointSelector != nil || peer.NamespaceSelector != nil {
+			return fmt.Errorf("fqdn is set then neither of the other fields can be")
+		}
+		// fully qualified domain name may end with the root dot
+		if es := validation.IsDNS1123Subdomain(strings.TrimSuffix(peer.FQDN, ".")); len(es) != 0 {
+			return fmt.Errorf("%s not a available fqdn: %s", peer.FQDN, strings.Join(es, ", "))
+		}
+		return nil
+	}
+
 	if peer.Endpoint != nil {
 		if peer.IPBlock != nil || peer.EndpointSelector != nil || peer.NamespaceSelector != nil {
 			return fmt.Errorf("endpoint is set then neither of the other fields can be")
@@ -470,6 +600,13 @@ func (v *securityPolicyValidator) validateRulePeer(peer *securityv1alpha1.Securi
 }
 
 func (v *securityPolicyValidator) validatePort(port *securityv1alpha1.SecurityPolicyPort) error {
+	if port.ICMPType != nil && port.Protocol != securityv1alpha1.ProtocolICMP {
+		return fmt.Errorf("icmp type only supported by protocol %s", securityv1alpha1.ProtocolICMP)
+	}
+	if port.ICMPCode != nil && port.ICMPType == nil {
+		return fmt.Errorf("icmp code must be specified with icmp type")
+	}
+
 	// Only validate PortRange, port.Protocol and port.Type validate by crd
 	if port.Type != securityv1alpha1.PortTypeName {
 		return v.validatePortRange(port.PortRange)
@@ -596,3 +733,44 @@ func validateIPBlock(ipBlock networkingv1.IPBlock) error {
 
 	return nil
 }
+
+// ipBlockPodCIDRWarnings returns warnings of ipBlock peers overlap the pod cidr, the overlap excluded
+// by the excepts of ipBlock is ignored.
+func ipBlockPodCIDRWarnings(rules []securityv1alpha1.Rule, podCIDR *net.IPNet) []string {
+	var warnings []string
+	for _, rule := range rules {
+		for _, peer := range append(rule.From, rule.To...) {
+			if peer.IPBlock == nil || !ipBlockOverlapCIDR(*peer.IPBlock, podCIDR) {
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf("ipBlock %s of rule %s overlaps cluster pod cidr %s, it may unintentionally allow or deny pod traffic",
+				peer.IPBlock.CIDR, rule.Name, podCIDR))
+		}
+	}
+	return warnings
+}
+
+func ipBlockOverlapCIDR(ipBlock networkingv1.IPBlock, cidr *net.IPNet) bool {
+	_, blockIPNet, err := net.ParseCIDR(ipBlock.CIDR)
+	if err != nil || !blockIPNet.Contains(cidr.IP) && !cidr.Contains(blockIPNet.IP) {
+		return false
+	}
+
+	// cidrs either contain or disjoint with each other, the overlap is the smaller one
+	overlap := blockIPNet
+	if maskLen(blockIPNet) < maskLen(cidr) {
+		overlap = cidr
+	}
+	for _, exceptCIDR := range ipBlock.Except {
+		_, exceptIPNet, err := net.ParseCIDR(exceptCIDR)
+		if err == nil && exceptIPNet.Contains(overlap.IP) && maskLen(exceptIPNet) <= maskLen(overlap) {
+			return false
+		}
+	}
+	return true
+}
+
+func maskLen(ipNet *net.IPNet) int {
+	ones, _ := ipNet.Mask.Size()
+	return ones
+}
@@ -18,6 +18,7 @@ package validates_test
 import (
 	"context"
 	"encoding/json"
+	"net"
 	"time"
 
 	. "github.com/onsi/ginkgo"
@@ -28,6 +29,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	groupv1alpha1 "github.com/everoute/everoute/pkg/apis/group/v1alpha1"
@@ -360,6 +362,21 @@ var _ = Describe("CRD Validate", func() {
 			policy.Spec.DefaultRule = securityv1alpha1.DefaultRuleNone
 			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
 		})
+		It("Create policy with symmetric policy types should set symmetric mode", func() {
+			policy := securityPolicyIngress.DeepCopy()
+			policy.Name = "new-policy"
+			policy.Spec.SymmetricPolicyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}
+			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
+			policy.Spec.SymmetricMode = true
+			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeTrue())
+		})
+		It("Create policy with unknown symmetric policy types should not allowed", func() {
+			policy := securityPolicyIngress.DeepCopy()
+			policy.Name = "new-policy"
+			policy.Spec.SymmetricMode = true
+			policy.Spec.SymmetricPolicyTypes = []networkingv1.PolicyType{"Unknown"}
+			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
+		})
 		It("Create blocklist policy can't set default rule drop", func() {
 			policy := securityPolicyIngress.DeepCopy()
 			policy.Name = "new-blocklist"
@@ -374,6 +391,20 @@ var _ = Describe("CRD Validate", func() {
 			policy.Spec.DefaultRule = securityv1alpha1.DefaultRuleNone
 			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeTrue())
 		})
+		It("Create block arp policy should be tier0 default drop without rules", func() {
+			policy := securityPolicyIngress.DeepCopy()
+			policy.Name = "new-isolation"
+			policy.Spec.BlockARP = true
+			policy.Spec.Tier = constants.Tier0
+			policy.Spec.DefaultRule = securityv1alpha1.DefaultRuleDrop
+			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
+
+			policy.Spec.IngressRules, policy.Spec.EgressRules = nil, nil
+			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeTrue())
+
+			policy.Spec.Tier = constants.Tier1
+			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
+		})
 		It("Update policy with unexists tier should not allowed", func() {
 			policy := securityPolicyIngress.DeepCopy()
 			policy.Spec.Tier = "UNExist-Tier-endpointName"
@@ -385,6 +416,15 @@ var _ = Describe("CRD Validate", func() {
 			policy.Spec.SecurityPolicyEnforcementMode = securityv1alpha1.MonitorMode
 			Expect(validate.Validate(fakeAdmissionReview(policy, securityPolicyIngress, "")).Allowed).Should(BeFalse())
 		})
+		It("Create policy with custom tier should allowed in work mode only", func() {
+			validate.SetCustomTiers("tier-custom")
+			policy := securityPolicyIngress.DeepCopy()
+			policy.Name = "new-policy"
+			policy.Spec.Tier = "tier-custom"
+			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeTrue())
+			policy.Spec.SecurityPolicyEnforcementMode = securityv1alpha1.MonitorMode
+			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
+		})
 		It("Delete policy should always allowed", func() {
 			Expect(validate.Validate(fakeAdmissionReview(nil, securityPolicyEgress, "")).Allowed).Should(BeTrue())
 			Expect(validate.Validate(fakeAdmissionReview(nil, securityPolicyIngress, "")).Allowed).Should(BeTrue())
@@ -422,6 +462,41 @@ var _ = Describe("CRD Validate", func() {
 					EndpointSelector: &labels.Selector{},
 				}
 				Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeTrue())
+
+				policy.Spec.AppliedTo[0] = securityv1alpha1.ApplyToPeer{
+					InterfaceName: pointer.String("veth*"),
+				}
+				Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeTrue())
+			})
+			It("Create policy with applied to peer InterfaceName and EndpointSelector should not allowed", func() {
+				policy.Spec.AppliedTo[0] = securityv1alpha1.ApplyToPeer{
+					InterfaceName:    pointer.String("veth*"),
+					EndpointSelector: &labels.Selector{},
+				}
+				Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
+			})
+			It("Create policy with error format of applied to peer InterfaceName should not allowed", func() {
+				policy.Spec.AppliedTo[0] = securityv1alpha1.ApplyToPeer{
+					InterfaceName: pointer.String("veth[0-"),
+				}
+				Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
+			})
+			It("Create policy with applied to peer VlanRange should allowed", func() {
+				policy.Spec.AppliedTo[0] = securityv1alpha1.ApplyToPeer{
+					VlanRange: &securityv1alpha1.VlanRange{Start: 100, End: 199},
+				}
+				Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeTrue())
+			})
+			It("Create policy with error applied to peer VlanRange should not allowed", func() {
+				for _, peer := range []securityv1alpha1.ApplyToPeer{
+					{VlanRange: &securityv1alpha1.VlanRange{Start: 200, End: 100}},
+					{VlanRange: &securityv1alpha1.VlanRange{Start: 100, End: 4096}},
+					{VlanRange: &securityv1alpha1.VlanRange{Start: 100, End: 199}, EndpointSelector: &labels.Selector{}},
+					{VlanRange: &securityv1alpha1.VlanRange{Start: 100, End: 199}, InterfaceName: pointer.String("veth*")},
+				} {
+					policy.Spec.AppliedTo[0] = peer
+					Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
+				}
 			})
 		})
 
@@ -446,6 +521,45 @@ var _ = Describe("CRD Validate", func() {
 				policy.Spec.IngressRules[0].Ports[0].PortRange = "22,80,"
 				Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
 			})
+			It("Create policy with icmp type and code should allowed", func() {
+				policy := securityPolicyIngress.DeepCopy()
+				icmpType, icmpCode := int32(8), int32(0)
+				policy.Spec.IngressRules[0].Ports[0] = securityv1alpha1.SecurityPolicyPort{
+					Protocol: securityv1alpha1.ProtocolICMP,
+					ICMPType: &icmpType,
+					ICMPCode: &icmpCode,
+				}
+				Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeTrue())
+			})
+			It("Create policy with icmp type on non icmp protocol should not allowed", func() {
+				policy := securityPolicyIngress.DeepCopy()
+				icmpType := int32(8)
+				policy.Spec.IngressRules[0].Ports[0].ICMPType = &icmpType
+				Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
+			})
+			It("Create policy with icmp code but without icmp type should not allowed", func() {
+				policy := securityPolicyIngress.DeepCopy()
+				icmpCode := int32(0)
+				policy.Spec.IngressRules[0].Ports[0] = securityv1alpha1.SecurityPolicyPort{
+					Protocol: securityv1alpha1.ProtocolICMP,
+					ICMPCode: &icmpCode,
+				}
+				Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
+			})
+			It("Create policy with rule active window should allowed", func() {
+				policy := securityPolicyIngress.DeepCopy()
+				activeFrom := metav1.Now()
+				policy.Spec.IngressRules[0].ActiveFrom = &activeFrom
+				policy.Spec.IngressRules[0].ActiveUntil = &metav1.Time{Time: activeFrom.Add(time.Hour)}
+				Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeTrue())
+			})
+			It("Create policy with rule active until not after active from should not allowed", func() {
+				policy := securityPolicyIngress.DeepCopy()
+				activeFrom := metav1.Now()
+				policy.Spec.IngressRules[0].ActiveFrom = &activeFrom
+				policy.Spec.IngressRules[0].ActiveUntil = &metav1.Time{Time: activeFrom.Add(-time.Hour)}
+				Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
+			})
 		})
 
 		Context("Validate On SecurityPolicyPeer", func() {
@@ -473,6 +587,41 @@ var _ = Describe("CRD Validate", func() {
 					EndpointSelector: &labels.Selector{},
 				}
 				Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
+
+				gateway := securityv1alpha1.EndpointNetworkGateway
+				policy.Spec.IngressRules[0].From[0] = securityv1alpha1.SecurityPolicyPeer{
+					EndpointNetwork: &gateway,
+					IPBlock:         &networkingv1.IPBlock{CIDR: "0.0.0.0/0"},
+				}
+				Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
+
+				policy.Spec.IngressRules[0].From[0] = securityv1alpha1.SecurityPolicyPeer{
+					IPPool:           &securityv1alpha1.NamespacedName{Name: "pool", Namespace: "default"},
+					EndpointSelector: &labels.Selector{},
+				}
+				Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
+
+				policy.Spec.IngressRules[0].From[0] = securityv1alpha1.SecurityPolicyPeer{
+					IPPool: &securityv1alpha1.NamespacedName{Name: "Invalid_Pool"},
+				}
+				Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
+
+				policy.Spec.IngressRules[0].From[0] = securityv1alpha1.SecurityPolicyPeer{
+					FQDN: "api.example.com",
+				}
+				Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
+
+				policy.Spec.IngressRules[0].From = nil
+				policy.Spec.EgressRules[0].To[0] = securityv1alpha1.SecurityPolicyPeer{
+					FQDN:             "api.example.com",
+					EndpointSelector: &labels.Selector{},
+				}
+				Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
+
+				policy.Spec.EgressRules[0].To[0] = securityv1alpha1.SecurityPolicyPeer{
+					FQDN: "invalid_domain.com",
+				}
+				Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
 			})
 			It("Create policy with nil SecurityPolicyPeer should allowed", func() {
 				policy.Spec.IngressRules[0].From = nil
@@ -507,6 +656,22 @@ var _ = Describe("CRD Validate", func() {
 					NamespaceSelector: &metav1.LabelSelector{},
 				}
 				Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeTrue())
+
+				subnet := securityv1alpha1.EndpointNetworkSubnet
+				policy.Spec.IngressRules[0].From[0] = securityv1alpha1.SecurityPolicyPeer{
+					EndpointNetwork: &subnet,
+				}
+				Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeTrue())
+
+				policy.Spec.IngressRules[0].From[0] = securityv1alpha1.SecurityPolicyPeer{
+					IPPool: &securityv1alpha1.NamespacedName{Name: "pool", Namespace: "default"},
+				}
+				Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeTrue())
+
+				policy.Spec.EgressRules[0].To[0] = securityv1alpha1.SecurityPolicyPeer{
+					FQDN: "api.example.com.",
+				}
+				Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeTrue())
 			})
 		})
 
@@ -551,6 +716,42 @@ var _ = Describe("CRD Validate", func() {
 				policy.Spec.IngressRules[0].From[0].IPBlock.Except = []string{"192.168.1.0/24"}
 				Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeTrue())
 			})
+
+			When("cluster pod cidr has been set", func() {
+				BeforeEach(func() {
+					_, podCIDR, _ := net.ParseCIDR("10.244.0.0/16")
+					validate.SetClusterPodCIDR(podCIDR)
+				})
+				AfterEach(func() {
+					validate.SetClusterPodCIDR(nil)
+				})
+
+				It("Create policy with IPBlock overlaps pod cidr should allowed with warning", func() {
+					policy.Spec.IngressRules[0].From[0].IPBlock.CIDR = "10.0.0.0/8"
+					resp := validate.Validate(fakeAdmissionReview(policy, nil, ""))
+					Expect(resp.Allowed).Should(BeTrue())
+					Expect(resp.Warnings).Should(HaveLen(1))
+					Expect(resp.Warnings[0]).Should(ContainSubstring("10.244.0.0/16"))
+
+					policy.Spec.IngressRules[0].From[0].IPBlock.CIDR = "10.244.1.0/24"
+					resp = validate.Validate(fakeAdmissionReview(policy, nil, ""))
+					Expect(resp.Allowed).Should(BeTrue())
+					Expect(resp.Warnings).Should(HaveLen(1))
+				})
+				It("Create policy with IPBlock not overlaps pod cidr should allowed without warning", func() {
+					policy.Spec.IngressRules[0].From[0].IPBlock.CIDR = "192.168.0.0/16"
+					resp := validate.Validate(fakeAdmissionReview(policy, nil, ""))
+					Expect(resp.Allowed).Should(BeTrue())
+					Expect(resp.Warnings).Should(BeEmpty())
+
+					// the overlap has been excepted
+					policy.Spec.IngressRules[0].From[0].IPBlock.CIDR = "10.0.0.0/8"
+					policy.Spec.IngressRules[0].From[0].IPBlock.Except = []string{"10.244.0.0/15"}
+					resp = validate.Validate(fakeAdmissionReview(policy, nil, ""))
+					Expect(resp.Allowed).Should(BeTrue())
+					Expect(resp.Warnings).Should(BeEmpty())
+				})
+			})
 		})
 	})
 
@@ -206,7 +206,7 @@ func (c *Controller) getCurrentGlobalPolicySpec() v1alpha1.GlobalPolicySpec {
 		case schema.GlobalPolicyActionDrop:
 			globalPolicySpec.DefaultAction = v1alpha1.GlobalDefaultActionDrop
 		}
-		globalPolicySpec.Logging = policy.NewLoggingOptionsFrom(obj.(*schema.EverouteCluster), nil)
+		globalPolicySpec.Logging = policy.NewLoggingOptionsFrom(obj.(*schema.EverouteCluster), nil, false)
 	} else {
 		// if everoute cluster not found, use default action allow
 		globalPolicySpec.DefaultAction = v1alpha1.GlobalDefaultActionAllow
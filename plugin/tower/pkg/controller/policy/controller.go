@@ -18,14 +18,19 @@ package policy
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net"
+	"net/netip"
 	"reflect"
+	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/mikioh/ipaddr"
 	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -33,6 +38,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog"
 
@@ -42,6 +48,7 @@ import (
 	crd "github.com/everoute/everoute/pkg/client/informers_generated/externalversions"
 	"github.com/everoute/everoute/pkg/constants"
 	"github.com/everoute/everoute/pkg/labels"
+	"github.com/everoute/everoute/pkg/utils"
 	"github.com/everoute/everoute/plugin/tower/pkg/controller/endpoint"
 	"github.com/everoute/everoute/plugin/tower/pkg/informer"
 	"github.com/everoute/everoute/plugin/tower/pkg/schema"
@@ -61,6 +68,10 @@ const (
 	FTPPortRange  = "21"
 	TFTPPortRange = "69"
 
+	// DefaultMaxIPBlockEntries is the default max number of cidrs and excepts generated from an ip block,
+	// unlimited by default
+	DefaultMaxIPBlockEntries = 0
+
 	InternalAllowlistPriority int32 = 90
 	BlocklistPriority         int32 = 50
 	AllowlistPriority         int32 = 30
@@ -71,12 +82,17 @@ const (
 	securityPolicyIndex  = "towerSecurityPolicyIndex"
 	isolationPolicyIndex = "towerIsolationPolicyIndex"
 	serviceIndex         = "serviceIndex"
+	controllerIndex      = "controllerIndex"
+
+	// controllerIndexKey is the index key of policies have rules peer with everoute controllers
+	controllerIndexKey = "everouteController"
 
 	/* logging tags key enum */
 
 	LoggingTagPolicyID   = "PolicyID"
 	LoggingTagPolicyName = "PolicyName"
-	LoggingTagPolicyType = "PolicyType"
+	LoggingTagPolicyType = constants.LoggingTagPolicyType
+	LoggingTagDirection  = "Direction"
 
 	/* logging policy type enum */
 
@@ -84,6 +100,11 @@ const (
 	LoggingTagPolicyTypeSecurityPolicyDeny  = "SecurityPolicyDeny"
 	LoggingTagPolicyTypeQuarantinePolicy    = "QuarantinePolicy"
 	LoggingTagPolicyTypeGlobalPolicy        = "GlobalPolicy"
+
+	/* logging direction enum */
+
+	LoggingTagDirectionIngress = "Ingress"
+	LoggingTagDirectionEgress  = "Egress"
 )
 
 // Controller sync SecurityPolicy and IsolationPolicy as v1alpha1.SecurityPolicy
@@ -144,7 +165,46 @@ type Controller struct {
 	serviceInformer       cache.SharedIndexInformer
 	serviceLister         informer.Lister
 	serviceInformerSynced cache.InformerSynced
-}
+
+	// partialIsolationKeepTier keeps ingress and egress policy of partial isolation in Tier1 even if the rules empty
+	partialIsolationKeepTier atomic.Bool
+	// intragroupSymmetricMode generates communicable intragroup policy in symmetric mode
+	intragroupSymmetricMode atomic.Bool
+	// intragroupSymmetricPorts limits symmetric mode of communicable intragroup policy to the ports, all
+	// protocols are symmetric when empty
+	intragroupSymmetricPorts atomic.Pointer[[]v1alpha1.SecurityPolicyPort]
+	// zeroIPAsHost treats single ip 0.0.0.0 and :: in ip block as the host address instead of match all
+	zeroIPAsHost atomic.Bool
+	// isolationBlockARP denies ARP and ND of the endpoints isolated by isolation mode all
+	isolationBlockARP atomic.Bool
+	// maxIPBlockEntries limits the cidrs and excepts generated from an ip block, zero means unlimited
+	maxIPBlockEntries atomic.Int64
+	// loggingDropOnly logs only dropped connections of policies enabled logging
+	loggingDropOnly atomic.Bool
+	// emptyAppliedToAll applies SecurityPolicy without AppliedTo to all endpoints instead of nothing
+	emptyAppliedToAll atomic.Bool
+
+	// recorder records events of tower policies, nil if events disabled
+	recorder record.EventRecorder
+}
+
+// EmptyAppliedToMode decides how a tower SecurityPolicy without AppliedTo is handled
+type EmptyAppliedToMode string
+
+const (
+	// EmptyAppliedToNone generates no policy for SecurityPolicy without AppliedTo, the policy takes no effect
+	EmptyAppliedToNone EmptyAppliedToMode = "none"
+	// EmptyAppliedToAll applies SecurityPolicy without AppliedTo to all endpoints, like k8s NetworkPolicy
+	// with empty podSelector. SecurityPolicy with AppliedTo resolves to no endpoints still applies to nothing.
+	EmptyAppliedToAll EmptyAppliedToMode = "all"
+)
+
+// EmptyAppliedToReason is the reason of the event when a SecurityPolicy with AppliedTo applies to no endpoints
+const EmptyAppliedToReason = "EmptyAppliedTo"
+
+// IPBlockExceedLimitReason is the reason of the event when a tower policy rejected for its ip block exceeds the
+// max ip block entries
+const IPBlockExceedLimitReason = "IPBlockExceedLimit"
 
 // New creates a new instance of controller.
 //
@@ -254,9 +314,9 @@ func New(
 
 	erClusterInformer.AddEventHandlerWithResyncPeriod(
 		cache.ResourceEventHandlerFuncs{
-			AddFunc:    c.handleEverouteCluster,
+			AddFunc:    c.addOrDeleteEverouteCluster,
 			UpdateFunc: c.updateEverouteCluster,
-			DeleteFunc: c.handleEverouteCluster,
+			DeleteFunc: c.addOrDeleteEverouteCluster,
 		},
 		resyncPeriod,
 	)
@@ -289,19 +349,21 @@ func New(
 		resyncPeriod,
 	)
 
-	// relate selected labels and security groups
+	// relate selected labels, security groups and everoute controllers
 	_ = securityPolicyInformer.AddIndexers(cache.Indexers{
 		labelIndex:         c.labelIndexFunc,
 		securityGroupIndex: c.securityGroupIndexFunc,
 		serviceIndex:       c.serviceIndexFunc,
+		controllerIndex:    c.controllerIndexFunc,
 	})
 
-	// relate isolate vm, selected labels and security groups
+	// relate isolate vm, selected labels, security groups and everoute controllers
 	_ = isolationPolicyInformer.AddIndexers(cache.Indexers{
 		vmIndex:            c.vmIndexFunc,
 		labelIndex:         c.labelIndexFunc,
 		securityGroupIndex: c.securityGroupIndexFunc,
 		serviceIndex:       c.serviceIndexFunc,
+		controllerIndex:    c.controllerIndexFunc,
 	})
 
 	// relate vms and selected labels
@@ -357,10 +419,19 @@ func (c *Controller) Run(workers uint, stopCh <-chan struct{}) {
 	// handle systemendpoints and everoutecluster separately
 	go wait.Until(informer.ReconcileWorker(c.name, c.everouteClusterPolicyQueue, c.syncEverouteClusterPolicy), time.Second, stopCh)
 	go wait.Until(informer.ReconcileWorker(c.name, c.systemEndpointPolicyQueue, c.syncSystemEndpointsPolicy), time.Second, stopCh)
+	go wait.Until(c.updateQueueDepthMetrics, queueDepthUpdateInterval, stopCh)
 
 	<-stopCh
 }
 
+// updateQueueDepthMetrics records the current depth of each workqueue
+func (c *Controller) updateQueueDepthMetrics() {
+	queueDepth.WithLabelValues("securityPolicy").Set(float64(c.securityPolicyQueue.Len()))
+	queueDepth.WithLabelValues("isolationPolicy").Set(float64(c.isolationPolicyQueue.Len()))
+	queueDepth.WithLabelValues("systemEndpointPolicy").Set(float64(c.systemEndpointPolicyQueue.Len()))
+	queueDepth.WithLabelValues("everouteClusterPolicy").Set(float64(c.everouteClusterPolicyQueue.Len()))
+}
+
 func (c *Controller) labelIndexFunc(obj interface{}) ([]string, error) {
 	var labelReferences []schema.ObjectReference
 
@@ -461,6 +532,24 @@ func (c *Controller) serviceIndexFunc(obj interface{}) ([]string, error) {
 	return serviceIDs, nil
 }
 
+func (c *Controller) controllerIndexFunc(obj interface{}) ([]string, error) {
+	var rules []schema.NetworkPolicyRule
+
+	switch o := obj.(type) {
+	case *schema.SecurityPolicy:
+		rules = append(o.Ingress, o.Egress...)
+	case *schema.IsolationPolicy:
+		rules = append(o.Ingress, o.Egress...)
+	}
+
+	for _, rule := range rules {
+		if rule.Type == schema.NetworkPolicyRuleTypeEverouteController {
+			return []string{controllerIndexKey}, nil
+		}
+	}
+	return nil, nil
+}
+
 func (c *Controller) securityPolicyIndexFunc(obj interface{}) ([]string, error) {
 	policy := obj.(*v1alpha1.SecurityPolicy)
 
@@ -642,21 +731,39 @@ func (c *Controller) handleEverouteCluster(interface{}) {
 	c.everouteClusterPolicyQueue.Add("key")
 }
 
+func (c *Controller) addOrDeleteEverouteCluster(obj interface{}) {
+	c.handleEverouteCluster(obj)
+	c.handleControllerPeerPolicies()
+}
+
 func (c *Controller) updateEverouteCluster(old, new interface{}) {
 	oldERCluster := old.(*schema.EverouteCluster)
 	newERCluster := new.(*schema.EverouteCluster)
 
-	if newERCluster.ID == c.everouteCluster {
-		c.handleEverouteCluster(newERCluster)
-		return
+	// handle controller instance ip changes
+	controllerChanged := !reflect.DeepEqual(newERCluster.ControllerInstances, oldERCluster.ControllerInstances)
+	if controllerChanged {
+		c.handleControllerPeerPolicies()
 	}
 
-	// handle controller instance ip changes
-	if !reflect.DeepEqual(newERCluster.ControllerInstances, oldERCluster.ControllerInstances) {
+	if newERCluster.ID == c.everouteCluster || controllerChanged {
 		c.handleEverouteCluster(newERCluster)
 	}
 }
 
+// handleControllerPeerPolicies enqueues policies have rules peer with everoute controllers
+func (c *Controller) handleControllerPeerPolicies() {
+	securityPolicies, _ := c.securityPolicyLister.ByIndex(controllerIndex, controllerIndexKey)
+	for _, securityPolicy := range securityPolicies {
+		c.handleSecurityPolicy(securityPolicy)
+	}
+
+	isolationPolicies, _ := c.isolationPolicyLister.ByIndex(controllerIndex, controllerIndexKey)
+	for _, isolationPolicy := range isolationPolicies {
+		c.handleIsolationPolicy(isolationPolicy)
+	}
+}
+
 func (c *Controller) handleSystemEndpoints(interface{}) {
 	c.systemEndpointPolicyQueue.Add("key")
 }
@@ -741,6 +848,8 @@ func (c *Controller) updateService(old, new interface{}) {
 
 // syncSecurityPolicy sync SecurityPoicy to v1alpha1.SecurityPolicy
 func (c *Controller) syncSecurityPolicy(key string) error {
+	defer observePolicySync(SecurityPolicyKind, time.Now())
+
 	policy, exist, err := c.securityPolicyLister.GetByKey(key)
 	if err != nil {
 		klog.Errorf("get SecurityPolicy %s: %s", key, err)
@@ -748,6 +857,9 @@ func (c *Controller) syncSecurityPolicy(key string) error {
 	}
 
 	if !exist {
+		emptyAppliedToPolicies.DeleteLabelValues(key)
+		ipBlockExceedLimitPolicies.DeleteLabelValues(key, SecurityPolicyKind)
+		missingServiceRules.DeleteLabelValues(key, SecurityPolicyKind)
 		return c.deleteRelatedPolicies(securityPolicyIndex, key)
 	}
 	return c.processSecurityPolicyUpdate(policy.(*schema.SecurityPolicy))
@@ -755,6 +867,8 @@ func (c *Controller) syncSecurityPolicy(key string) error {
 
 // syncIsolationPolicy sync IsolationPolicy to v1alpha1.SecurityPolicy
 func (c *Controller) syncIsolationPolicy(key string) error {
+	defer observePolicySync(IsolationPolicyKind, time.Now())
+
 	policy, exist, err := c.isolationPolicyLister.GetByKey(key)
 	if err != nil {
 		klog.Errorf("get IsolationPolicy %s: %s", key, err)
@@ -762,6 +876,8 @@ func (c *Controller) syncIsolationPolicy(key string) error {
 	}
 
 	if !exist {
+		ipBlockExceedLimitPolicies.DeleteLabelValues(key, IsolationPolicyKind)
+		missingServiceRules.DeleteLabelValues(key, IsolationPolicyKind)
 		return c.deleteRelatedPolicies(isolationPolicyIndex, key)
 	}
 	return c.processIsolationPolicyUpdate(policy.(*schema.IsolationPolicy))
@@ -770,23 +886,55 @@ func (c *Controller) syncIsolationPolicy(key string) error {
 // syncSystemEndpointsPolicy sync SystemEndpoints to v1alpha1.SecurityPolicy
 func (c *Controller) syncSystemEndpointsPolicy(key string) error {
 	systemEndpointsList := c.systemEndpointLister.List()
-	switch len(systemEndpointsList) {
-	case 0:
+	if len(systemEndpointsList) == 0 {
 		err := c.applyPoliciesChanges([]string{c.getSystemEndpointsPolicyKey()}, nil)
 		if err != nil {
 			klog.Errorf("unable delete systemEndpoints policies %+v: %s", key, err)
 		}
 		return err
-	case 1:
-		policy, _ := c.parseSystemEndpointsPolicy(systemEndpointsList[0].(*schema.SystemEndpoints))
-		err := c.applyPoliciesChanges([]string{c.getSystemEndpointsPolicyKey()}, policy)
-		if err != nil {
-			klog.Errorf("unable update systemEndpoints policies %+v: %s", key, err)
+	}
+
+	policy, _ := c.parseSystemEndpointsPolicy(selectSystemEndpoints(systemEndpointsList))
+	err := c.applyPoliciesChanges([]string{c.getSystemEndpointsPolicyKey()}, policy)
+	if err != nil {
+		klog.Errorf("unable update systemEndpoints policies %+v: %s", key, err)
+	}
+	return err
+}
+
+// selectSystemEndpoints select the systemEndpoints with the lowest key from the list deterministically, the
+// one with the lowest content is selected if keys are the same. It warns if more than one in the list.
+func selectSystemEndpoints(systemEndpointsList []interface{}) *schema.SystemEndpoints {
+	var selected *schema.SystemEndpoints
+	var selectedKey, selectedContent string
+	for _, obj := range systemEndpointsList {
+		systemEndpoints := obj.(*schema.SystemEndpoints)
+		key := systemEndpointsKey(systemEndpoints)
+		raw, _ := json.Marshal(systemEndpoints)
+		content := string(raw)
+		if selected == nil || key < selectedKey || (key == selectedKey && content < selectedContent) {
+			selected, selectedKey, selectedContent = systemEndpoints, key, content
 		}
-		return err
-	default:
-		return fmt.Errorf("invalid systemEndpoints in cluster, %+v", systemEndpointsList)
 	}
+	if len(systemEndpointsList) > 1 {
+		klog.Warningf("found %d systemEndpoints in cluster, use the one with the lowest key %s: %s",
+			len(systemEndpointsList), selectedKey, selectedContent)
+	}
+	return selected
+}
+
+// systemEndpointsKey returns the sorted keys of the endpoints as the key of the systemEndpoints, GetID of
+// systemEndpoints is the same for all objects and can't tell them apart
+func systemEndpointsKey(systemEndpoints *schema.SystemEndpoints) string {
+	keys := make([]string, 0, len(systemEndpoints.IDEndpoints)+len(systemEndpoints.IPPortEndpoints))
+	for _, endpoint := range systemEndpoints.IDEndpoints {
+		keys = append(keys, endpoint.Key)
+	}
+	for _, endpoint := range systemEndpoints.IPPortEndpoints {
+		keys = append(keys, endpoint.Key)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
 }
 
 // syncEverouteClusterPolicy sync EverouteCluster to v1alpha1.SecurityPolicy
@@ -844,9 +992,21 @@ func (c *Controller) deleteRelatedPolicies(indexName, key string) error {
 
 func (c *Controller) processSecurityPolicyUpdate(policy *schema.SecurityPolicy) error {
 	policies, err := c.parseSecurityPolicy(policy)
-	if err != nil {
+	c.observeIPBlockExceedLimit(SecurityPolicyKind, SecurityPolicyPrefix+policy.GetID(), policy.GetID(), err)
+	c.observeMissingServiceRules(SecurityPolicyKind, policy.GetID(), policy.Ingress, policy.Egress)
+	switch {
+	case IsEmptyAppliedToError(err):
+		klog.Infof("SecurityPolicy %s resolves to zero endpoints, no policy would be generated", policy.GetID())
+		emptyAppliedToPolicies.WithLabelValues(policy.GetID()).Set(1)
+		if len(policy.ApplyTo) != 0 {
+			// the policy has AppliedTo but none of them resolves to endpoints, e.g. security group without members
+			c.recordEmptyAppliedTo(policy)
+		}
+	case err != nil:
 		klog.Errorf("parse SecurityPolicy %+v to []v1alpha1.SecurityPolicy: %s", policy, err)
 		return err
+	default:
+		emptyAppliedToPolicies.DeleteLabelValues(policy.GetID())
 	}
 
 	currentPolicyKeys, err := c.crdPolicyLister.IndexKeys(securityPolicyIndex, policy.GetID())
@@ -864,8 +1024,77 @@ func (c *Controller) processSecurityPolicyUpdate(policy *schema.SecurityPolicy)
 	return nil
 }
 
+// recordEmptyAppliedTo records a warning event of the SecurityPolicy applies to no endpoints unexpectedly
+func (c *Controller) recordEmptyAppliedTo(policy *schema.SecurityPolicy) {
+	if c.recorder == nil {
+		return
+	}
+	ref := &corev1.ObjectReference{
+		Kind:       "SecurityPolicy",
+		APIVersion: v1alpha1.SchemeGroupVersion.String(),
+		Namespace:  c.namespace,
+		Name:       SecurityPolicyPrefix + policy.GetID(),
+	}
+	c.recorder.Eventf(ref, corev1.EventTypeWarning, EmptyAppliedToReason,
+		"tower SecurityPolicy %s applies to no endpoints, no policy would be generated", policy.GetID())
+}
+
+// observeMissingServiceRules records the number of rules of the tower policy dropped since all services
+// referenced by them not found
+func (c *Controller) observeMissingServiceRules(kind, policyID string, ruleLists ...[]schema.NetworkPolicyRule) {
+	var missing int
+	for _, rules := range ruleLists {
+		for item := range rules {
+			if c.allServicesMissing(&rules[item]) {
+				missing++
+			}
+		}
+	}
+	if missing == 0 {
+		missingServiceRules.DeleteLabelValues(policyID, kind)
+		return
+	}
+	missingServiceRules.WithLabelValues(policyID, kind).Set(float64(missing))
+}
+
+// allServicesMissing returns true if all ports of the rule come from services not found
+func (c *Controller) allServicesMissing(rule *schema.NetworkPolicyRule) bool {
+	if len(rule.Ports) != 0 || len(rule.Services) == 0 {
+		return false
+	}
+	for _, svc := range rule.Services {
+		if _, exists, err := c.serviceLister.GetByKey(svc.ID); err != nil || exists {
+			return false
+		}
+	}
+	return true
+}
+
+// observeIPBlockExceedLimit records the tower policy rejected for its ip block exceeds the max ip block entries
+// by metric and warning event, the metric is cleared once the policy parsed without the error
+func (c *Controller) observeIPBlockExceedLimit(kind, name, policyID string, err error) {
+	if !IsIPBlockExceedLimitError(err) {
+		ipBlockExceedLimitPolicies.DeleteLabelValues(policyID, kind)
+		return
+	}
+
+	ipBlockExceedLimitPolicies.WithLabelValues(policyID, kind).Set(1)
+	if c.recorder == nil {
+		return
+	}
+	ref := &corev1.ObjectReference{
+		Kind:       "SecurityPolicy",
+		APIVersion: v1alpha1.SchemeGroupVersion.String(),
+		Namespace:  c.namespace,
+		Name:       name,
+	}
+	c.recorder.Eventf(ref, corev1.EventTypeWarning, IPBlockExceedLimitReason, "tower %s %s rejected: %s", kind, policyID, err)
+}
+
 func (c *Controller) processIsolationPolicyUpdate(policy *schema.IsolationPolicy) error {
 	policies, err := c.parseIsolationPolicy(policy)
+	c.observeIPBlockExceedLimit(IsolationPolicyKind, IsolationPolicyPrefix+policy.GetID(), policy.GetID(), err)
+	c.observeMissingServiceRules(IsolationPolicyKind, policy.GetID(), policy.Ingress, policy.Egress)
 	if err != nil {
 		klog.Errorf("parse IsolationPolicy %+v to []v1alpha1.SecurityPolicy: %s", policy, err)
 		return err
@@ -888,6 +1117,7 @@ func (c *Controller) processIsolationPolicyUpdate(policy *schema.IsolationPolicy
 
 func (c *Controller) applyPoliciesChanges(oldKeys []string, new []v1alpha1.SecurityPolicy) error {
 	oldKeySet := sets.NewString(oldKeys...)
+	setRulesLoggingOptions(new)
 
 	for _, policy := range new {
 		policyKey, _ := cache.MetaNamespaceKeyFunc(policy.DeepCopy())
@@ -947,7 +1177,7 @@ func (c *Controller) parseGlobalWhitelistPolicy(cluster *schema.EverouteCluster)
 
 	ingress, egress, err := c.parseNetworkPolicyRules(cluster.GlobalWhitelist.Ingress, cluster.GlobalWhitelist.Egress)
 	if err != nil {
-		return nil, fmt.Errorf("parse NetworkPolicyRules error, err: %s", err)
+		return nil, fmt.Errorf("parse NetworkPolicyRules error, err: %w", err)
 	}
 
 	sp := v1alpha1.SecurityPolicy{
@@ -961,7 +1191,7 @@ func (c *Controller) parseGlobalWhitelistPolicy(cluster *schema.EverouteCluster)
 			DefaultRule:                   v1alpha1.DefaultRuleNone,
 			IngressRules:                  ingress,
 			EgressRules:                   egress,
-			Logging:                       NewLoggingOptionsFrom(cluster, c.vmLister),
+			Logging:                       NewLoggingOptionsFrom(cluster, c.vmLister, c.loggingDropOnly.Load()),
 			PolicyTypes:                   []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
 		},
 	}
@@ -1037,6 +1267,18 @@ func (c *Controller) parseSystemEndpointsPolicy(systemEndpoints *schema.SystemEn
 	return []v1alpha1.SecurityPolicy{sp}, nil
 }
 
+// PreviewPolicy returns the v1alpha1.SecurityPolicy generated from the schema.SecurityPolicy,
+// without writing anything into apiserver. It is useful for unit tests and tools.
+// EmptyAppliedToError is returned when the SecurityPolicy applies to no endpoints.
+func (c *Controller) PreviewPolicy(securityPolicy *schema.SecurityPolicy) ([]v1alpha1.SecurityPolicy, error) {
+	policies, err := c.parseSecurityPolicy(securityPolicy)
+	if err != nil {
+		return nil, err
+	}
+	setRulesLoggingOptions(policies)
+	return policies, nil
+}
+
 // parseSecurityPolicy convert schema.SecurityPolicy to []v1alpha1.SecurityPolicy
 func (c *Controller) parseSecurityPolicy(securityPolicy *schema.SecurityPolicy) ([]v1alpha1.SecurityPolicy, error) {
 	var policyList []v1alpha1.SecurityPolicy
@@ -1047,14 +1289,18 @@ func (c *Controller) parseSecurityPolicy(securityPolicy *schema.SecurityPolicy)
 		return nil, err
 	}
 	if len(applyToPeers) == 0 {
-		return nil, nil
+		if len(securityPolicy.ApplyTo) != 0 || !c.emptyAppliedToAll.Load() {
+			return nil, &EmptyAppliedToError{PolicyID: securityPolicy.GetID()}
+		}
+		// empty selector selects all endpoints
+		applyToPeers = []v1alpha1.ApplyToPeer{{EndpointSelector: &labels.Selector{}}}
 	}
 
 	ingress, egress, err := c.parseNetworkPolicyRules(securityPolicy.Ingress, securityPolicy.Egress)
 	if err != nil {
 		return nil, err
 	}
-	loggingOptions := NewLoggingOptionsFrom(securityPolicy, c.vmLister)
+	loggingOptions := NewLoggingOptionsFrom(securityPolicy, c.vmLister, c.loggingDropOnly.Load())
 
 	policy := v1alpha1.SecurityPolicy{
 		ObjectMeta: metav1.ObjectMeta{
@@ -1072,7 +1318,7 @@ func (c *Controller) parseSecurityPolicy(securityPolicy *schema.SecurityPolicy)
 			EgressRules:                   egress,
 			DefaultRule:                   c.getPolicyDefaultRule(securityPolicy),
 			Logging:                       loggingOptions,
-			PolicyTypes:                   []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+			PolicyTypes:                   parsePolicyTypes(securityPolicy.Direction),
 		},
 	}
 	policyList = append(policyList, policy)
@@ -1094,6 +1340,99 @@ func (c *Controller) parseSecurityPolicy(securityPolicy *schema.SecurityPolicy)
 	return policyList, nil
 }
 
+// SetPartialIsolationKeepTier sets whether keep both ingress and egress policy of partial isolation in Tier1
+// with an explicit default drop when the rules empty, instead of demote the policy to Tier0.
+func (c *Controller) SetPartialIsolationKeepTier(keep bool) {
+	c.partialIsolationKeepTier.Store(keep)
+}
+
+// SetIsolationBlockARP sets whether deny ARP and ND of the endpoints isolated by isolation mode all. By default
+// ARP and ND are not governed by policy, the isolated endpoints could still resolve their neighbors.
+func (c *Controller) SetIsolationBlockARP(block bool) {
+	c.isolationBlockARP.Store(block)
+}
+
+// SetMaxIPBlockEntries sets the max number of cidrs and excepts could be generated from an ip block of a rule,
+// rules exceed the limit fail to parse, protect against huge flows from an ip range with many excepts.
+// Zero or negative means unlimited.
+func (c *Controller) SetMaxIPBlockEntries(max int) {
+	c.maxIPBlockEntries.Store(int64(max))
+}
+
+// SetLoggingDropOnly sets whether only dropped connections would be logged for policies enabled logging,
+// avoid logs flooded with allowed traffics.
+func (c *Controller) SetLoggingDropOnly(dropOnly bool) {
+	c.loggingDropOnly.Store(dropOnly)
+}
+
+// SetEmptyAppliedToMode sets how SecurityPolicy without AppliedTo is handled, returns error if the mode unknown.
+// By default no policy is generated for it.
+func (c *Controller) SetEmptyAppliedToMode(mode EmptyAppliedToMode) error {
+	switch mode {
+	case EmptyAppliedToNone:
+		c.emptyAppliedToAll.Store(false)
+	case EmptyAppliedToAll:
+		c.emptyAppliedToAll.Store(true)
+	default:
+		return fmt.Errorf("unknown empty AppliedTo mode %q, must be one of %q and %q", mode, EmptyAppliedToNone, EmptyAppliedToAll)
+	}
+	return nil
+}
+
+// SetEventRecorder sets the recorder which records warning events of tower policies, e.g. policy applies to
+// no endpoints unexpectedly. The events are recorded on the v1alpha1.SecurityPolicy the tower policy generates.
+func (c *Controller) SetEventRecorder(recorder record.EventRecorder) {
+	c.recorder = recorder
+}
+
+// SetZeroIPAsHost sets whether treat single ip 0.0.0.0 and :: in ip block as the host address, e.g. 0.0.0.0/32.
+// By default they are taken as match all addresses, e.g. 0.0.0.0/0, for compatible with tower.
+func (c *Controller) SetZeroIPAsHost(asHost bool) {
+	c.zeroIPAsHost.Store(asHost)
+}
+
+// SetIntragroupSymmetricMode sets whether generate communicable intragroup policy in symmetric mode. The
+// intragroup rules match all protocols and ports, so symmetric mode applies to every protocol between members.
+func (c *Controller) SetIntragroupSymmetricMode(symmetric bool) {
+	c.intragroupSymmetricMode.Store(symmetric)
+}
+
+// SetIntragroupSymmetricProtocols limits symmetric mode of communicable intragroup policy to the protocols,
+// the protocols are parsed as rule ports of tower policies. Intragroup traffics of other protocols are still
+// allowed but not symmetric. All protocols are symmetric if empty.
+func (c *Controller) SetIntragroupSymmetricProtocols(protocols []string) error {
+	ports := make([]v1alpha1.SecurityPolicyPort, 0, len(protocols))
+	for _, protocol := range protocols {
+		protocol = strings.ToUpper(strings.TrimSpace(protocol))
+		switch schema.NetworkPolicyRulePortProtocol(protocol) {
+		case schema.NetworkPolicyRulePortProtocolTCP, schema.NetworkPolicyRulePortProtocolUDP, schema.NetworkPolicyRulePortProtocolIcmp,
+			schema.NetworkPolicyRulePortProtocolIcmpv6, schema.NetworkPolicyRulePortProtocolIPIP, schema.NetworkPolicyRulePortProtocolGRE:
+		default:
+			return fmt.Errorf("unsupported intragroup symmetric protocol %q", protocol)
+		}
+		port, err := parseNetworkPolicyRulePort(schema.NetworkPolicyRulePort{Protocol: schema.NetworkPolicyRulePortProtocol(protocol)})
+		if err != nil {
+			return fmt.Errorf("parse intragroup symmetric protocol %q: %s", protocol, err)
+		}
+		ports = append(ports, *port)
+	}
+	c.intragroupSymmetricPorts.Store(&ports)
+	return nil
+}
+
+// parsePolicyTypes returns the policy types isolated by the direction, both ingress
+// and egress are isolated when the direction is empty
+func parsePolicyTypes(direction schema.PolicyDirection) []networkingv1.PolicyType {
+	switch direction {
+	case schema.PolicyDirectionIngress:
+		return []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}
+	case schema.PolicyDirectionEgress:
+		return []networkingv1.PolicyType{networkingv1.PolicyTypeEgress}
+	default:
+		return []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}
+	}
+}
+
 func (c *Controller) getPolicyPriority(policy *schema.SecurityPolicy) int32 {
 	if policy.IsBlocklist {
 		return BlocklistPriority
@@ -1123,7 +1462,7 @@ func (c *Controller) parseIsolationPolicy(isolationPolicy *schema.IsolationPolic
 	}
 
 	var isolationPolices []v1alpha1.SecurityPolicy
-	var loggingOptions = NewLoggingOptionsFrom(isolationPolicy, c.vmLister)
+	var loggingOptions = NewLoggingOptionsFrom(isolationPolicy, c.vmLister, c.loggingDropOnly.Load())
 
 	switch isolationPolicy.Mode {
 	case schema.IsolationModeAll:
@@ -1151,9 +1490,35 @@ func (c *Controller) parseIsolationPolicy(isolationPolicy *schema.IsolationPolic
 		)...)
 	}
 
+	if err := ValidateIsolationPolicies(isolationPolicy.GetID(), isolationPolices); err != nil {
+		return nil, err
+	}
 	return isolationPolices, nil
 }
 
+// ValidateIsolationPolicies validates policies generated from the IsolationPolicy isolate both ingress
+// and egress of the vm: each direction must be covered by a policy dropping traffics by default, only
+// traffics allowed by the rules could pass. The direction without rules, e.g. egress of ingress-only
+// isolation, drops all traffics rather than left open.
+func ValidateIsolationPolicies(id string, policies []v1alpha1.SecurityPolicy) error {
+	for _, direction := range []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress} {
+		var isolated bool
+		for i := range policies {
+			if policies[i].Spec.DefaultRule == v1alpha1.DefaultRuleDrop && lo.Contains(policies[i].Spec.PolicyTypes, direction) {
+				isolated = true
+				break
+			}
+		}
+		if !isolated {
+			return &IsolationPostureError{PolicyID: id, Direction: direction}
+		}
+	}
+	return nil
+}
+
+// generateIsolationPolicy generates policies isolate both ingress and egress of the vm. Partial isolation is
+// separated into ingress and egress policy, the one without rules drops all traffics of its direction, so
+// ingress-only isolation still drops all egress traffics, and vice versa.
 func (c *Controller) generateIsolationPolicy(
 	id string,
 	mode schema.IsolationMode,
@@ -1176,6 +1541,7 @@ func (c *Controller) generateIsolationPolicy(
 				DefaultRule:   v1alpha1.DefaultRuleDrop,
 				Logging:       loggingOptions,
 				PolicyTypes:   []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+				BlockARP:      c.isolationBlockARP.Load(),
 			},
 		}
 		isolationPolices = append(isolationPolices, policy)
@@ -1193,12 +1559,9 @@ func (c *Controller) generateIsolationPolicy(
 				Logging:       loggingOptions,
 				PolicyTypes:   []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
 				IngressRules:  ingress,
-				Tier:          constants.Tier1,
+				Tier:          c.partialIsolationTier(ingress),
 			},
 		}
-		if len(ingress) == 0 {
-			ingressPolicy.Spec.Tier = constants.Tier0
-		}
 		isolationPolices = append(isolationPolices, ingressPolicy)
 
 		egressPolicy := v1alpha1.SecurityPolicy{
@@ -1212,19 +1575,27 @@ func (c *Controller) generateIsolationPolicy(
 				DefaultRule:   v1alpha1.DefaultRuleDrop,
 				PolicyTypes:   []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
 				EgressRules:   egress,
-				Tier:          constants.Tier1,
+				Tier:          c.partialIsolationTier(egress),
 				Logging:       loggingOptions,
 			},
 		}
-		if len(egress) == 0 {
-			egressPolicy.Spec.Tier = constants.Tier0
-		}
 		isolationPolices = append(isolationPolices, egressPolicy)
 	}
 
 	return isolationPolices
 }
 
+// partialIsolationTier returns tier of the ingress or egress policy of partial isolation. The policy with rules
+// is in Tier1, the same tier as forensic policy, its default rule drops the traffics not allowed by the rules.
+// The policy without rules isolates all traffics of the direction, it's demoted to Tier0 as the isolation policy
+// by default, or keeps in Tier1 with the default drop rule if partialIsolationKeepTier set.
+func (c *Controller) partialIsolationTier(rules []v1alpha1.Rule) string {
+	if len(rules) == 0 && !c.partialIsolationKeepTier.Load() {
+		return constants.Tier0
+	}
+	return constants.Tier1
+}
+
 func (c *Controller) generateIntragroupPolicy(
 	id string,
 	policyMode v1alpha1.PolicyMode,
@@ -1247,23 +1618,46 @@ func (c *Controller) generateIntragroupPolicy(
 			Namespace: c.namespace,
 		},
 		Spec: v1alpha1.SecurityPolicySpec{
-			Tier:      constants.Tier2,
-			AppliedTo: appliedPeers,
-			IngressRules: []v1alpha1.Rule{{
-				Name: "ingress",
-				From: c.appliedPeersAsPolicyPeers(appliedPeers, false),
-			}},
-			EgressRules: []v1alpha1.Rule{{
-				Name: "egress",
-				To:   c.appliedPeersAsPolicyPeers(appliedPeers, false),
-			}},
+			Tier:                          constants.Tier2,
+			AppliedTo:                     appliedPeers,
 			SecurityPolicyEnforcementMode: policyMode,
+			SymmetricMode:                 c.intragroupSymmetricMode.Load(),
 			DefaultRule:                   v1alpha1.DefaultRuleDrop,
 			Logging:                       loggingOptions,
 			PolicyTypes:                   []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
 		},
 	}
 
+	symmetricPorts := c.intragroupSymmetricPorts.Load()
+	if !policy.Spec.SymmetricMode || symmetricPorts == nil || len(*symmetricPorts) == 0 {
+		policy.Spec.IngressRules = []v1alpha1.Rule{{
+			Name: "ingress",
+			From: c.appliedPeersAsPolicyPeers(appliedPeers, false),
+		}}
+		policy.Spec.EgressRules = []v1alpha1.Rule{{
+			Name: "egress",
+			To:   c.appliedPeersAsPolicyPeers(appliedPeers, false),
+		}}
+		return &policy, nil
+	}
+
+	// allow all protocols between the members, and only the symmetric protocols are symmetric
+	policy.Spec.IngressRules = []v1alpha1.Rule{{
+		Name: "ingress",
+		From: c.appliedPeersAsPolicyPeers(appliedPeers, true),
+	}, {
+		Name:  "ingress-symmetric",
+		From:  c.appliedPeersAsPolicyPeers(appliedPeers, false),
+		Ports: append([]v1alpha1.SecurityPolicyPort(nil), *symmetricPorts...),
+	}}
+	policy.Spec.EgressRules = []v1alpha1.Rule{{
+		Name: "egress",
+		To:   c.appliedPeersAsPolicyPeers(appliedPeers, true),
+	}, {
+		Name:  "egress-symmetric",
+		To:    c.appliedPeersAsPolicyPeers(appliedPeers, false),
+		Ports: append([]v1alpha1.SecurityPolicyPort(nil), *symmetricPorts...),
+	}}
 	return &policy, nil
 }
 
@@ -1322,6 +1716,10 @@ func (c *Controller) parseNetworkPolicyRules(ingressRules, egressRules []schema.
 
 	for item, rule := range ingressRules {
 		peers, ports, err := c.parseNetworkPolicyRule(&ingressRules[item])
+		if IsMissingServicesError(err) {
+			klog.Warningf("drop ingress rule %d: %s", item, err)
+			continue
+		}
 		if err != nil {
 			return nil, nil, err
 		}
@@ -1331,14 +1729,19 @@ func (c *Controller) parseNetworkPolicyRules(ingressRules, egressRules []schema.
 			continue
 		}
 		ingress = append(ingress, v1alpha1.Rule{
-			Name:  fmt.Sprintf("ingress%d", item),
-			Ports: ports,
-			From:  peers,
+			Name:            fmt.Sprintf("ingress%d", item),
+			Ports:           ports,
+			From:            peers,
+			EnforcementMode: parseEnforcementMode(rule.PolicyMode),
 		})
 	}
 
 	for item, rule := range egressRules {
 		peers, ports, err := c.parseNetworkPolicyRule(&egressRules[item])
+		if IsMissingServicesError(err) {
+			klog.Warningf("drop egress rule %d: %s", item, err)
+			continue
+		}
 		if err != nil {
 			return nil, nil, err
 		}
@@ -1348,21 +1751,29 @@ func (c *Controller) parseNetworkPolicyRules(ingressRules, egressRules []schema.
 			continue
 		}
 		egress = append(egress, v1alpha1.Rule{
-			Name:  fmt.Sprintf("egress%d", item),
-			Ports: ports,
-			To:    peers,
+			Name:            fmt.Sprintf("egress%d", item),
+			Ports:           ports,
+			To:              peers,
+			EnforcementMode: parseEnforcementMode(rule.PolicyMode),
 		})
 	}
 
 	return ingress, egress, nil
 }
 
-// parseNetworkPolicyRule parse NetworkPolicyRule to []v1alpha1.SecurityPolicyPeer and []v1alpha1.SecurityPolicyPort
+// parseNetworkPolicyRule parse NetworkPolicyRule to []v1alpha1.SecurityPolicyPeer and []v1alpha1.SecurityPolicyPort,
+// returns MissingServicesError if all ports of the rule come from services not found
 func (c *Controller) parseNetworkPolicyRule(rule *schema.NetworkPolicyRule) ([]v1alpha1.SecurityPolicyPeer, []v1alpha1.SecurityPolicyPort, error) {
 	var policyPeers []v1alpha1.SecurityPolicyPeer
 	var policyPorts = make([]v1alpha1.SecurityPolicyPort, 0, len(rule.Ports))
+	var anyProtocol bool
+	var missingServices []string
 
 	for _, port := range rule.Ports {
+		if isAnyProtocolPort(port) {
+			anyProtocol = true
+			continue
+		}
 		policyPort, err := parseNetworkPolicyRulePort(port)
 		if err != nil {
 			return nil, nil, err
@@ -1376,15 +1787,28 @@ func (c *Controller) parseNetworkPolicyRule(rule *schema.NetworkPolicyRule) ([]v
 		}
 		if !exists {
 			klog.Errorf("policy related service %s doesn't exists", svc.ID)
+			missingServices = append(missingServices, svc.ID)
 			continue
 		}
 		svc := svcObj.(*schema.NetworkPolicyRuleService)
+		if lo.ContainsBy(svc.Members, isAnyProtocolPort) {
+			anyProtocol = true
+			continue
+		}
 		svcPorts, err := parseNetworkPolicyService(svc)
 		if err != nil {
 			return nil, nil, fmt.Errorf("parse service %s failed: %s", svc.ID, err)
 		}
 		policyPorts = append(policyPorts, svcPorts...)
 	}
+	if len(rule.Ports) == 0 && len(rule.Services) != 0 && len(missingServices) == len(rule.Services) {
+		// rule without ports would match all ports of the peers, drop the rule instead
+		return nil, nil, &MissingServicesError{Services: missingServices}
+	}
+	if anyProtocol {
+		// rule without ports matches all protocols and ports to the peers
+		policyPorts = policyPorts[:0]
+	}
 
 	disableSymmetric := false
 	if rule.OnlyApplyToExternalTraffic {
@@ -1398,9 +1822,9 @@ func (c *Controller) parseNetworkPolicyRule(rule *schema.NetworkPolicyRule) ([]v
 		if rule.IPBlock == nil {
 			return nil, nil, fmt.Errorf("receive rule.Type %s but empty IPBlock", schema.NetworkPolicyRuleTypeIPBlock)
 		}
-		ipBlocks, err := parseIPBlock(*rule.IPBlock, rule.ExceptIPBlock)
+		ipBlocks, err := parseIPBlock(*rule.IPBlock, rule.ExceptIPBlock, c.zeroIPAsHost.Load(), int(c.maxIPBlockEntries.Load()))
 		if err != nil {
-			return nil, nil, fmt.Errorf("parse IPBlock %s with except %v: %s", *rule.IPBlock, rule.ExceptIPBlock, err)
+			return nil, nil, fmt.Errorf("parse IPBlock %s with except %v: %w", *rule.IPBlock, rule.ExceptIPBlock, err)
 		}
 		for _, ipBlock := range ipBlocks {
 			policyPeers = append(policyPeers, v1alpha1.SecurityPolicyPeer{IPBlock: ipBlock, DisableSymmetric: disableSymmetric})
@@ -1423,11 +1847,33 @@ func (c *Controller) parseNetworkPolicyRule(rule *schema.NetworkPolicyRule) ([]v
 			return nil, nil, err
 		}
 		policyPeers = append(policyPeers, c.appliedPeersAsPolicyPeers(peers, disableSymmetric)...)
+	case schema.NetworkPolicyRuleTypeEverouteController:
+		policyPeers = append(policyPeers, c.appliedPeersAsPolicyPeers(c.controllerAsAppliedTo(), disableSymmetric)...)
 	}
 
 	return policyPeers, policyPorts, nil
 }
 
+// controllerAsAppliedTo returns endpoints of controller instances of all everoute clusters
+func (c *Controller) controllerAsAppliedTo() []v1alpha1.ApplyToPeer {
+	clusters := c.everouteClusterLister.List()
+	sort.Slice(clusters, func(i, j int) bool {
+		return clusters[i].(*schema.EverouteCluster).GetID() < clusters[j].(*schema.EverouteCluster).GetID()
+	})
+
+	var applyToPeers []v1alpha1.ApplyToPeer
+	for _, obj := range clusters {
+		cluster := obj.(*schema.EverouteCluster)
+		for _, ctrl := range cluster.ControllerInstances {
+			epName := endpoint.GetCtrlEndpointName(cluster.ID, ctrl)
+			applyToPeers = append(applyToPeers, v1alpha1.ApplyToPeer{
+				Endpoint: &epName,
+			})
+		}
+	}
+	return applyToPeers
+}
+
 func (c *Controller) parseSelectors(selectors []schema.ObjectReference) (*labels.Selector, error) {
 	if len(selectors) == 0 {
 		return &labels.Selector{MatchNothing: true}, nil
@@ -1528,47 +1974,121 @@ func (c *Controller) getGlobalWhitelistPolicyKey() string {
 	return c.namespace + "/" + GlobalWhitelistPolicyName
 }
 
-func parseIPBlock(ipBlock string, excepts []string) ([]*networkingv1.IPBlock, error) {
+// parseIPBlock parses the ip block with excepts into cidrs, each with the excepts inside it. If maxEntries
+// is positive, returns error when the cidrs and excepts generated exceed it.
+func parseIPBlock(ipBlock string, excepts []string, zeroIPAsHost bool, maxEntries int) ([]*networkingv1.IPBlock, error) {
 	var block []*networkingv1.IPBlock
 	var exceptAll []string
+	var entries int
 
 	for _, item := range excepts {
-		cidr, err := formatIPBlock(item)
+		cidr, err := formatIPBlock(item, zeroIPAsHost)
 		if err != nil {
 			return nil, err
 		}
 		exceptAll = append(exceptAll, cidr...)
 	}
 
-	ipBlockList := strings.Split(ipBlock, ",")
-	for _, item := range ipBlockList {
-		cidrs, err := formatIPBlock(item)
+	cidrs, err := normalizeCIDRs(strings.Split(ipBlock, ","), zeroIPAsHost)
+	if err != nil {
+		return nil, err
+	}
+	for _, cidr := range cidrs {
+		var exceptValid []string
+		var covered bool
+		for _, exceptItem := range exceptAll {
+			if exceptCovers(exceptItem, cidr) {
+				covered = true
+				break
+			}
+			if except, ok := intersectingExcept(cidr, exceptItem); ok && !lo.Contains(exceptValid, except) {
+				exceptValid = append(exceptValid, except)
+			}
+		}
+		if covered {
+			// all addresses of the cidr are excepted, the cidr matches nothing
+			continue
+		}
+		entries += 1 + len(exceptValid)
+		if maxEntries > 0 && entries > maxEntries {
+			return nil, &IPBlockExceedLimitError{IPBlock: ipBlock, Limit: maxEntries}
+		}
+		block = append(block, &networkingv1.IPBlock{
+			CIDR:   cidr,
+			Except: exceptValid,
+		})
+	}
+
+	return block, nil
+}
+
+// intersectingExcept returns the except unchanged if it intersects the cidr, and false if it doesn't, so that
+// excepts out of the cidr are filtered out. The except covers the cidr should be checked by exceptCovers before.
+func intersectingExcept(cidr, except string) (string, bool) {
+	cidrPrefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return "", false
+	}
+	exceptPrefix, err := netip.ParsePrefix(except)
+	if err != nil || !cidrPrefix.Overlaps(exceptPrefix) {
+		return "", false
+	}
+	return except, true
+}
+
+// exceptCovers returns true if the except contains all addresses of the cidr. Except summarized from ip range
+// may span the cidr boundary, the cidr should be dropped instead of producing an except equal to the cidr.
+func exceptCovers(except, cidr string) bool {
+	cidrPrefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return false
+	}
+	exceptPrefix, err := netip.ParsePrefix(except)
+	if err != nil {
+		return false
+	}
+	return exceptPrefix.Bits() <= cidrPrefix.Bits() && exceptPrefix.Overlaps(cidrPrefix)
+}
+
+// normalizeCIDRs formats the ip blocks into cidrs, duplicate, overlapping and adjacent cidrs are merged.
+// Excepts are the same for all the cidrs, so merging cidrs doesn't change addresses matched.
+func normalizeCIDRs(ipBlocks []string, zeroIPAsHost bool) ([]string, error) {
+	var prefixes []netip.Prefix
+	var origins = make(map[netip.Prefix]string)
+
+	for _, item := range ipBlocks {
+		cidrs, err := formatIPBlock(item, zeroIPAsHost)
 		if err != nil {
 			return nil, err
 		}
 		for _, cidr := range cidrs {
-			_, cidrNet, _ := net.ParseCIDR(cidr)
-			var exceptValid []string
-			for _, exceptItem := range exceptAll {
-				_, exceptItemCidr, _ := net.ParseCIDR(exceptItem)
-				if cidrNet.Contains(exceptItemCidr.IP) ||
-					cidrNet.Contains(ipaddr.NewPrefix(exceptItemCidr).Last()) ||
-					exceptItemCidr.Contains(cidrNet.IP) ||
-					exceptItemCidr.Contains(ipaddr.NewPrefix(cidrNet).Last()) {
-					exceptValid = append(exceptValid, exceptItem)
-				}
+			prefix, err := netip.ParsePrefix(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid cidr %s: %s", cidr, err)
+			}
+			prefix = prefix.Masked()
+			prefixes = append(prefixes, prefix)
+			if _, ok := origins[prefix]; !ok {
+				origins[prefix] = cidr
 			}
-			block = append(block, &networkingv1.IPBlock{
-				CIDR:   cidr,
-				Except: exceptValid,
-			})
 		}
 	}
 
-	return block, nil
+	var cidrs []string
+	for _, prefix := range utils.MergeIPPrefixes(prefixes) {
+		// keep the origin cidr format if it's not merged
+		if cidr, ok := origins[prefix]; ok {
+			cidrs = append(cidrs, cidr)
+		} else {
+			cidrs = append(cidrs, prefix.String())
+		}
+	}
+	return cidrs, nil
 }
 
-func formatIPBlock(ipBlock string) ([]string, error) {
+// formatIPBlock formats the cidr, ip range or single ip into cidrs. Single ip 0.0.0.0 and :: are taken as match
+// all addresses 0.0.0.0/0 and ::/0, unless zeroIPAsHost set they are taken as host address 0.0.0.0/32 and ::/128.
+func formatIPBlock(ipBlock string, zeroIPAsHost bool) ([]string, error) {
 	ipBlock = strings.TrimSpace(ipBlock)
 
 	// for ip block
@@ -1599,9 +2119,12 @@ func formatIPBlock(ipBlock string) ([]string, error) {
 
 	// for single ip
 	ip := net.ParseIP(ipBlock)
-	if ip.Equal(net.IPv4zero) || ip.Equal(net.IPv6zero) {
+	if !zeroIPAsHost && ip.Equal(net.IPv4zero) {
 		return []string{"0.0.0.0/0"}, nil
 	}
+	if !zeroIPAsHost && ip.Equal(net.IPv6zero) {
+		return []string{"::/0"}, nil
+	}
 	if ip.To4() != nil {
 		return []string{fmt.Sprintf("%s/%d", ipBlock, net.IPv4len*8)}, nil
 	}
@@ -1624,10 +2147,17 @@ func parseEnforcementMode(mode schema.PolicyMode) v1alpha1.PolicyMode {
 	}
 }
 
+// isAnyProtocolPort returns true if the port matches all protocols and ports
+func isAnyProtocolPort(port schema.NetworkPolicyRulePort) bool {
+	return port.Protocol == schema.NetworkPolicyRulePortProtocolAny || port.Protocol == ""
+}
+
 func parseNetworkPolicyRulePort(port schema.NetworkPolicyRulePort) (*v1alpha1.SecurityPolicyPort, error) {
 	switch port.Protocol {
-	case schema.NetworkPolicyRulePortProtocolIcmp, schema.NetworkPolicyRulePortProtocolIPIP:
+	case schema.NetworkPolicyRulePortProtocolIcmp, schema.NetworkPolicyRulePortProtocolIPIP, schema.NetworkPolicyRulePortProtocolGRE:
 		return &v1alpha1.SecurityPolicyPort{Protocol: v1alpha1.Protocol(port.Protocol)}, nil
+	case schema.NetworkPolicyRulePortProtocolIcmpv6:
+		return &v1alpha1.SecurityPolicyPort{Protocol: v1alpha1.ProtocolICMPv6}, nil
 	case schema.NetworkPolicyRulePortProtocolALG:
 		switch port.AlgProtocol {
 		case schema.NetworkPolicyRulePortAlgProtocolFTP:
@@ -1679,13 +2209,13 @@ func getGlobalWhitelistPolicyEnforceMode(enable bool) v1alpha1.PolicyMode {
 	return v1alpha1.MonitorMode
 }
 
-func NewLoggingOptionsFrom(obj schema.Object, vmLister informer.Lister) *v1alpha1.Logging {
+func NewLoggingOptionsFrom(obj schema.Object, vmLister informer.Lister, dropOnly bool) *v1alpha1.Logging {
 	switch t := obj.(type) {
 	case *schema.EverouteCluster:
-		return newLoggingOptions(t.EnableLogging, t.ID, "", LoggingTagPolicyTypeGlobalPolicy)
+		return newLoggingOptions(t.EnableLogging, dropOnly, t.ID, "", LoggingTagPolicyTypeGlobalPolicy)
 	case *schema.SecurityPolicy:
 		pt := lo.If(t.IsBlocklist, LoggingTagPolicyTypeSecurityPolicyDeny).Else(LoggingTagPolicyTypeSecurityPolicyAllow)
-		return newLoggingOptions(t.EnableLogging, t.ID, t.Name, pt)
+		return newLoggingOptions(t.EnableLogging, dropOnly, t.ID, t.Name, pt)
 	case *schema.IsolationPolicy:
 		var name string
 		if vmLister != nil {
@@ -1693,15 +2223,19 @@ func NewLoggingOptionsFrom(obj schema.Object, vmLister informer.Lister) *v1alpha
 				name = vm.(*schema.VM).Name
 			}
 		}
-		return newLoggingOptions(t.EnableLogging, t.ID, name, LoggingTagPolicyTypeQuarantinePolicy)
+		return newLoggingOptions(t.EnableLogging, dropOnly, t.ID, name, LoggingTagPolicyTypeQuarantinePolicy)
 	default:
-		return newLoggingOptions(false, "", "", "")
+		return newLoggingOptions(false, false, "", "", "")
 	}
 }
 
-func newLoggingOptions(enabled bool, policyID, policyName, policyType string) *v1alpha1.Logging {
+// newLoggingOptions returns logging options of the policy, allowed connections would not be logged if dropOnly
+func newLoggingOptions(enabled, dropOnly bool, policyID, policyName, policyType string) *v1alpha1.Logging {
+	allowEnabled := enabled && !dropOnly
 	return &v1alpha1.Logging{
-		Enabled: enabled,
+		Enabled:      enabled,
+		DropEnabled:  &enabled,
+		AllowEnabled: &allowEnabled,
 		Tags: map[string]string{
 			LoggingTagPolicyID:   policyID,
 			LoggingTagPolicyName: policyName,
@@ -1709,3 +2243,34 @@ func newLoggingOptions(enabled bool, policyID, policyName, policyType string) *v
 		},
 	}
 }
+
+// newRuleLoggingOptions returns logging options of the rule, which has the policy logging tags and the rule direction tag
+func newRuleLoggingOptions(policyLogging *v1alpha1.Logging, direction string) *v1alpha1.Logging {
+	if policyLogging == nil {
+		return nil
+	}
+	ruleLogging := policyLogging.DeepCopy()
+	if ruleLogging.Tags == nil {
+		ruleLogging.Tags = make(map[string]string)
+	}
+	ruleLogging.Tags[LoggingTagDirection] = direction
+	return ruleLogging
+}
+
+// setRulesLoggingOptions sets logging options of policy rules and default rules, so that ingress and egress logs
+// could be distinguished
+func setRulesLoggingOptions(policies []v1alpha1.SecurityPolicy) {
+	for i := range policies {
+		spec := &policies[i].Spec
+		for j := range spec.IngressRules {
+			spec.IngressRules[j].Logging = newRuleLoggingOptions(spec.Logging, LoggingTagDirectionIngress)
+		}
+		for j := range spec.EgressRules {
+			spec.EgressRules[j].Logging = newRuleLoggingOptions(spec.Logging, LoggingTagDirectionEgress)
+		}
+		if spec.DefaultRule == v1alpha1.DefaultRuleDrop {
+			spec.DefaultIngressRuleLogging = newRuleLoggingOptions(spec.Logging, LoggingTagDirectionIngress)
+			spec.DefaultEgressRuleLogging = newRuleLoggingOptions(spec.Logging, LoggingTagDirectionEgress)
+		}
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"strings"
 	"time"
 
 	. "github.com/onsi/ginkgo"
@@ -30,6 +31,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	"github.com/everoute/everoute/pkg/apis/security/v1alpha1"
 	"github.com/everoute/everoute/pkg/constants"
@@ -210,13 +212,9 @@ var _ = Describe("PolicyController", func() {
 					policy.Ingress = append(policy.Ingress, *ingress)
 					policy.Egress = append(policy.Egress, *egress)
 
+					// cidrs all excepted are dropped
 					ingressBlock = []*networkingv1.IPBlock{
-						{CIDR: "192.168.0.0/24", Except: []string{"192.168.0.0/24"}},
 						{CIDR: "192.168.1.1/32", Except: []string{}},
-						{CIDR: "192.168.3.1/32", Except: []string{"192.168.3.1/32"}},
-						{CIDR: "192.168.3.2/31", Except: []string{"192.168.3.2/31"}},
-						{CIDR: "192.168.3.4/30", Except: []string{"192.168.3.4/30"}},
-						{CIDR: "192.168.3.8/29", Except: []string{"192.168.3.8/29"}},
 						{CIDR: "192.168.3.16/28", Except: []string{"192.168.3.16/30", "192.168.3.20/32"}},
 						{CIDR: "192.168.3.32/27", Except: []string{}},
 						{CIDR: "192.168.3.64/27", Except: []string{}},
@@ -294,6 +292,135 @@ var _ = Describe("PolicyController", func() {
 				})
 			})
 
+			When("create SecurityPolicy with overlapping IPBlocks", func() {
+				var policy *schema.SecurityPolicy
+
+				BeforeEach(func() {
+					policy = NewSecurityPolicy(everouteCluster, false, nil, labelA)
+					ingress := NewNetworkPolicyRule("tcp", "22", &networkingv1.IPBlock{CIDR: "10.0.0.0/24,10.0.0.0/25,10.0.0.128/25", Except: []string{"10.0.0.16/28"}})
+					egress := NewNetworkPolicyRule("udp", "53", &networkingv1.IPBlock{CIDR: "10.0.1.1,10.0.1.1,10.0.1.0-10.0.1.1"})
+					policy.Ingress = append(policy.Ingress, *ingress)
+					policy.Egress = append(policy.Egress, *egress)
+
+					By(fmt.Sprintf("create SecurityPolicy %+v", policy))
+					server.TrackerFactory().SecurityPolicy().CreateOrUpdate(policy)
+				})
+				It("should merge overlapping and duplicate IPBlocks", func() {
+					assertPoliciesNum(ctx, 1)
+					assertHasPolicy(ctx, constants.Tier2, true, "", v1alpha1.DefaultRuleDrop, allPolicyTypes(),
+						NewSecurityPolicyRuleIngress("tcp", "22", []*networkingv1.IPBlock{{CIDR: "10.0.0.0/24", Except: []string{"10.0.0.16/28"}}}),
+						NewSecurityPolicyRuleEgress("udp", "53", []*networkingv1.IPBlock{{CIDR: "10.0.1.0/31"}}),
+						NewSecurityPolicyApplyPeer("", labelA),
+					)
+				})
+			})
+
+			When("create SecurityPolicy with IPBlock exceed the max entries", func() {
+				var policy *schema.SecurityPolicy
+
+				BeforeEach(func() {
+					var excepts []string
+					for i := 0; i < 16; i++ {
+						excepts = append(excepts, fmt.Sprintf("10.0.0.%d", 2*i+1))
+					}
+					policy = NewSecurityPolicy(everouteCluster, false, nil, labelA)
+					ingress := NewNetworkPolicyRule("tcp", "22", &networkingv1.IPBlock{CIDR: "10.0.0.0/24", Except: excepts})
+					policy.Ingress = append(policy.Ingress, *ingress)
+
+					policyController.SetMaxIPBlockEntries(16)
+					By(fmt.Sprintf("create SecurityPolicy %+v", policy))
+					server.TrackerFactory().SecurityPolicy().CreateOrUpdate(policy)
+				})
+				AfterEach(func() {
+					policyController.SetMaxIPBlockEntries(0)
+				})
+
+				It("should not generate the policy", func() {
+					By("wait some time to wait for controller handle it")
+					time.Sleep(3 * time.Second)
+					assertPoliciesNum(ctx, 0)
+					Expect(getIPBlockExceedLimitMetric(policy.GetID())).Should(Equal(1.0))
+				})
+
+				It("should generate the policy after the limit raised", func() {
+					policyController.SetMaxIPBlockEntries(17)
+					server.TrackerFactory().SecurityPolicy().CreateOrUpdate(policy)
+
+					assertPoliciesNum(ctx, 1)
+					Eventually(func() float64 {
+						return getIPBlockExceedLimitMetric(policy.GetID())
+					}, timeout, interval).Should(BeZero())
+				})
+			})
+
+			When("create SecurityPolicy with zero ip IPBlock", func() {
+				var policy *schema.SecurityPolicy
+
+				BeforeEach(func() {
+					policy = NewSecurityPolicy(everouteCluster, false, nil, labelA)
+					ingress := NewNetworkPolicyRule("tcp", "22", &networkingv1.IPBlock{CIDR: "0.0.0.0"})
+					egress := NewNetworkPolicyRule("udp", "53", &networkingv1.IPBlock{CIDR: "10.0.0.0/24", Except: []string{"0.0.0.0"}})
+					policy.Ingress = append(policy.Ingress, *ingress)
+					policy.Egress = append(policy.Egress, *egress)
+				})
+
+				It("should take zero ip as match all by default", func() {
+					By(fmt.Sprintf("create SecurityPolicy %+v", policy))
+					server.TrackerFactory().SecurityPolicy().CreateOrUpdate(policy)
+
+					assertPoliciesNum(ctx, 1)
+					assertHasPolicy(ctx, constants.Tier2, true, "", v1alpha1.DefaultRuleDrop, allPolicyTypes(),
+						NewSecurityPolicyRuleIngress("tcp", "22", []*networkingv1.IPBlock{{CIDR: "0.0.0.0/0"}}),
+						nil,
+						NewSecurityPolicyApplyPeer("", labelA),
+					)
+				})
+
+				When("enable zero ip as host", func() {
+					BeforeEach(func() {
+						policyController.SetZeroIPAsHost(true)
+						By(fmt.Sprintf("create SecurityPolicy %+v", policy))
+						server.TrackerFactory().SecurityPolicy().CreateOrUpdate(policy)
+					})
+					AfterEach(func() {
+						policyController.SetZeroIPAsHost(false)
+					})
+					It("should take zero ip as the host address", func() {
+						assertPoliciesNum(ctx, 1)
+						assertHasPolicy(ctx, constants.Tier2, true, "", v1alpha1.DefaultRuleDrop, allPolicyTypes(),
+							NewSecurityPolicyRuleIngress("tcp", "22", []*networkingv1.IPBlock{{CIDR: "0.0.0.0/32"}}),
+							NewSecurityPolicyRuleEgress("udp", "53", []*networkingv1.IPBlock{{CIDR: "10.0.0.0/24"}}),
+							NewSecurityPolicyApplyPeer("", labelA),
+						)
+					})
+				})
+			})
+
+			When("create SecurityPolicy with except ranges span the IPBlock boundary", func() {
+				var policy *schema.SecurityPolicy
+
+				BeforeEach(func() {
+					policy = NewSecurityPolicy(everouteCluster, false, nil, labelA)
+					ingress := NewNetworkPolicyRule("tcp", "22", &networkingv1.IPBlock{CIDR: "10.0.0.0/24", Except: []string{"10.0.0.10-10.0.1.20"}})
+					egress := NewNetworkPolicyRule("udp", "53", &networkingv1.IPBlock{CIDR: "10.0.2.0/25,10.0.4.0/24", Except: []string{"10.0.2.0-10.0.3.255"}})
+					policy.Ingress = append(policy.Ingress, *ingress)
+					policy.Egress = append(policy.Egress, *egress)
+
+					By(fmt.Sprintf("create SecurityPolicy %+v", policy))
+					server.TrackerFactory().SecurityPolicy().CreateOrUpdate(policy)
+				})
+				It("should keep excepts inside the IPBlock and drop cidrs all excepted", func() {
+					assertPoliciesNum(ctx, 1)
+					assertHasPolicy(ctx, constants.Tier2, true, "", v1alpha1.DefaultRuleDrop, allPolicyTypes(),
+						NewSecurityPolicyRuleIngress("tcp", "22", []*networkingv1.IPBlock{{CIDR: "10.0.0.0/24", Except: []string{
+							"10.0.0.10/31", "10.0.0.12/30", "10.0.0.16/28", "10.0.0.32/27", "10.0.0.64/26", "10.0.0.128/25",
+						}}}),
+						NewSecurityPolicyRuleEgress("udp", "53", []*networkingv1.IPBlock{{CIDR: "10.0.4.0/24"}}),
+						NewSecurityPolicyApplyPeer("", labelA),
+					)
+				})
+			})
+
 			When("create SecurityPolicy with allow all Ports", func() {
 				var policy *schema.SecurityPolicy
 				var ingress, egress *schema.NetworkPolicyRule
@@ -322,6 +449,35 @@ var _ = Describe("PolicyController", func() {
 				})
 			})
 
+			When("create SecurityPolicy with any protocol Ports", func() {
+				var policy *schema.SecurityPolicy
+				var ingress, egress *schema.NetworkPolicyRule
+
+				BeforeEach(func() {
+					policy = NewSecurityPolicy(everouteCluster, false, nil, labelA, labelB)
+					ingress = NewNetworkPolicyRule("tcp", "20-80", nil, labelB, labelC)
+					NetworkPolicyRuleAddPorts(ingress, *NewNetworkPolicyRulePort("ANY", "", ""))
+					egress = NewNetworkPolicyRule("ANY", "", nil, labelA, labelC)
+					policy.Ingress = append(policy.Ingress, *ingress)
+					policy.Egress = append(policy.Egress, *egress)
+
+					By(fmt.Sprintf("create SecurityPolicy %+v", policy))
+					server.TrackerFactory().SecurityPolicy().CreateOrUpdate(policy)
+
+					By("wait for v1alpha1.SecurityPolicy created")
+					assertPoliciesNum(ctx, 1)
+				})
+				It("should create policy with peers and without ports", func() {
+					assertPoliciesNum(ctx, 1)
+					assertHasPolicy(ctx, constants.Tier2, true, "", v1alpha1.DefaultRuleDrop, allPolicyTypes(),
+						NewSecurityPolicyRuleIngress("", "", nil, labelB, labelC),
+						NewSecurityPolicyRuleEgress("", "", nil, labelA, labelC),
+						NewSecurityPolicyApplyPeer("", labelA, labelB),
+					)
+					assertAllowlist(ctx)
+				})
+			})
+
 			When("create SecurityPolicy with intragroup communicable", func() {
 				var policy *schema.SecurityPolicy
 				var ingress, egress *schema.NetworkPolicyRule
@@ -354,6 +510,58 @@ var _ = Describe("PolicyController", func() {
 					)
 				})
 
+				When("enable intragroup symmetric mode", func() {
+					BeforeEach(func() {
+						policyController.SetIntragroupSymmetricMode(true)
+						policy.Name = rand.String(6)
+						By(fmt.Sprintf("update SecurityPolicy %+v", policy))
+						server.TrackerFactory().SecurityPolicy().CreateOrUpdate(policy)
+					})
+					AfterEach(func() {
+						policyController.SetIntragroupSymmetricMode(false)
+					})
+					It("should generate intragroup policy in symmetric mode", func() {
+						assertPoliciesNum(ctx, 2)
+						assertHasPolicy(ctx, constants.Tier2, true, "", v1alpha1.DefaultRuleDrop, allPolicyTypes(),
+							NewSecurityPolicyRuleIngress("", "", nil, labelA, labelB),
+							NewSecurityPolicyRuleEgress("", "", nil, labelA, labelB),
+							NewSecurityPolicyApplyPeer("", labelA, labelB),
+						)
+					})
+
+					When("limit intragroup symmetric protocols", func() {
+						BeforeEach(func() {
+							Expect(policyController.SetIntragroupSymmetricProtocols([]string{"tcp", "ICMP"})).Should(Succeed())
+							policy.Name = rand.String(6)
+							server.TrackerFactory().SecurityPolicy().CreateOrUpdate(policy)
+						})
+						AfterEach(func() {
+							Expect(policyController.SetIntragroupSymmetricProtocols(nil)).Should(Succeed())
+						})
+						It("should only make the protocols symmetric", func() {
+							Eventually(func(g Gomega) {
+								intragroupPolicy := getIntragroupPolicy(ctx, g)
+								g.Expect(intragroupPolicy.Spec.SymmetricMode).Should(BeTrue())
+								for _, rules := range [][]v1alpha1.Rule{intragroupPolicy.Spec.IngressRules, intragroupPolicy.Spec.EgressRules} {
+									g.Expect(rules).Should(HaveLen(2))
+									g.Expect(rules[0].Ports).Should(BeEmpty())
+									g.Expect(append(rules[0].From, rules[0].To...)).Should(HaveEach(HaveField("DisableSymmetric", true)))
+									g.Expect(rules[1].Ports).Should(ConsistOf(
+										v1alpha1.SecurityPolicyPort{Protocol: v1alpha1.ProtocolTCP},
+										v1alpha1.SecurityPolicyPort{Protocol: v1alpha1.ProtocolICMP},
+									))
+									g.Expect(append(rules[1].From, rules[1].To...)).Should(HaveEach(HaveField("DisableSymmetric", false)))
+								}
+							}, timeout, interval).Should(Succeed())
+						})
+					})
+
+					It("should reject unsupported intragroup symmetric protocols", func() {
+						Expect(policyController.SetIntragroupSymmetricProtocols([]string{"ALG"})).ShouldNot(Succeed())
+						Expect(policyController.SetIntragroupSymmetricProtocols([]string{"SCTP"})).ShouldNot(Succeed())
+					})
+				})
+
 				When("update SecurityPolicy intragroup not communicable", func() {
 					BeforeEach(func() {
 						policy.ApplyTo[0].Communicable = false
@@ -584,6 +792,30 @@ var _ = Describe("PolicyController", func() {
 						expectIngress, expectEgress, NewSecurityPolicyApplyPeer("", labelA, labelB))
 				})
 
+				It("update service with ICMPv6 member", func() {
+					svcB.Members = nil
+					svcB.Members = append(svcB.Members, *NewNetworkPolicyRulePort("ICMPV6", "", ""))
+					server.TrackerFactory().Service().CreateOrUpdate(svcB)
+
+					expectIngress := NewSecurityPolicyRuleIngress("ICMP", "", []*networkingv1.IPBlock{ipBlock1})
+					expectEgress := NewSecurityPolicyRuleEgress("ICMPv6", "", []*networkingv1.IPBlock{ipBlock2})
+					RuleAddPorts(expectEgress, "UDP", "12,23")
+					assertHasPolicy(ctx, constants.Tier2, true, "", v1alpha1.DefaultRuleDrop, allPolicyTypes(),
+						expectIngress, expectEgress, NewSecurityPolicyApplyPeer("", labelA, labelB))
+				})
+
+				It("update service with GRE member", func() {
+					svcB.Members = nil
+					svcB.Members = append(svcB.Members, *NewNetworkPolicyRulePort("GRE", "", ""))
+					server.TrackerFactory().Service().CreateOrUpdate(svcB)
+
+					expectIngress := NewSecurityPolicyRuleIngress("ICMP", "", []*networkingv1.IPBlock{ipBlock1})
+					expectEgress := NewSecurityPolicyRuleEgress("GRE", "", []*networkingv1.IPBlock{ipBlock2})
+					RuleAddPorts(expectEgress, "UDP", "12,23")
+					assertHasPolicy(ctx, constants.Tier2, true, "", v1alpha1.DefaultRuleDrop, allPolicyTypes(),
+						expectIngress, expectEgress, NewSecurityPolicyApplyPeer("", labelA, labelB))
+				})
+
 				It("update SecurityPolicy referenced service", func() {
 					NetworkPolicyRuleAddServices(&policy.Ingress[0], svcB.ID)
 					NetworkPolicyRuleDelServices(&policy.Egress[0], svcB.ID)
@@ -601,6 +833,42 @@ var _ = Describe("PolicyController", func() {
 					assertPoliciesNum(ctx, 0)
 				})
 			})
+			When("SecurityPolicy with rule only references missing service", func() {
+				var ipBlock1, ipBlock2 *networkingv1.IPBlock
+				var policy *schema.SecurityPolicy
+				BeforeEach(func() {
+					By("create SecurityPolicy with missing service")
+					policy = NewSecurityPolicy(everouteCluster, false, nil, labelA, labelB)
+					ipBlock1 = NewRandomIPBlock()
+					ipBlock2 = NewRandomIPBlock()
+					ingress := NewNetworkPolicyRule("TCP", "22", ipBlock1)
+					NetworkPolicyRuleAddServices(ingress, rand.String(10))
+					egress := NewNetworkPolicyRule("", "", ipBlock2)
+					NetworkPolicyRuleAddServices(egress, rand.String(10))
+					policy.Ingress = append(policy.Ingress, *ingress)
+					policy.Egress = append(policy.Egress, *egress)
+					server.TrackerFactory().SecurityPolicy().Create(policy)
+
+					assertPoliciesNum(ctx, 1)
+				})
+
+				It("should drop the rule rather than allow all ports", func() {
+					assertHasPolicy(ctx, constants.Tier2, true, "", v1alpha1.DefaultRuleDrop, allPolicyTypes(),
+						NewSecurityPolicyRuleIngress("TCP", "22", []*networkingv1.IPBlock{ipBlock1}),
+						nil,
+						NewSecurityPolicyApplyPeer("", labelA, labelB),
+					)
+					Expect(getMissingServiceRulesMetric(policy.GetID())).Should(Equal(1.0))
+				})
+
+				It("should clean the metric after the policy deleted", func() {
+					server.TrackerFactory().SecurityPolicy().Delete(policy.GetID())
+					assertPoliciesNum(ctx, 0)
+					Eventually(func() float64 {
+						return getMissingServiceRulesMetric(policy.GetID())
+					}, timeout, interval).Should(BeZero())
+				})
+			})
 		})
 
 		When("create SecurityPolicy with enforce mode", func() {
@@ -624,6 +892,32 @@ var _ = Describe("PolicyController", func() {
 			})
 		})
 
+		When("create SecurityPolicy with rule enforce mode", func() {
+			var policy *schema.SecurityPolicy
+
+			BeforeEach(func() {
+				policy = NewSecurityPolicy(everouteCluster, false, nil, labelA, labelB)
+				policy.PolicyMode = schema.PolicyModeWork
+				ingress := NewNetworkPolicyRule("tcp", "20-80", nil, labelB)
+				ingress.PolicyMode = schema.PolicyModeMonitor
+				policy.Ingress = append(policy.Ingress, *ingress)
+				policy.Egress = append(policy.Egress, *NewNetworkPolicyRule("udp", "123", nil, labelC))
+				By(fmt.Sprintf("create SecurityPolicy %+v", policy))
+				server.TrackerFactory().SecurityPolicy().CreateOrUpdate(policy)
+			})
+
+			It("should create policy with rule enforce mode", func() {
+				expectIngress := NewSecurityPolicyRuleIngress("tcp", "20-80", nil, labelB)
+				expectIngress.EnforcementMode = v1alpha1.MonitorMode
+				assertPoliciesNum(ctx, 1)
+				assertHasPolicy(ctx, constants.Tier2, true, v1alpha1.WorkMode, v1alpha1.DefaultRuleDrop, allPolicyTypes(),
+					expectIngress,
+					NewSecurityPolicyRuleEgress("udp", "123", nil, labelC),
+					NewSecurityPolicyApplyPeer("", labelA, labelB),
+				)
+			})
+		})
+
 		When("create SecurityPolicy out of the EverouteCluster", func() {
 			var policy *schema.SecurityPolicy
 			var randomEverouteCluster string
@@ -668,6 +962,23 @@ var _ = Describe("PolicyController", func() {
 				)
 				assertAllowlist(ctx)
 			})
+
+			When("update SecurityPolicy direction to ingress", func() {
+				BeforeEach(func() {
+					policy.Direction = schema.PolicyDirectionIngress
+					By(fmt.Sprintf("update SecurityPolicy %+v", policy))
+					server.TrackerFactory().SecurityPolicy().CreateOrUpdate(policy)
+				})
+				It("should create policy isolates ingress only", func() {
+					assertPoliciesNum(ctx, 1)
+					assertHasPolicy(ctx, constants.Tier2, true, "", v1alpha1.DefaultRuleDrop,
+						[]networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+						NewSecurityPolicyRuleIngress("tcp", "20-80", nil, labelB, labelC),
+						nil,
+						NewSecurityPolicyApplyPeer("", labelA, labelB),
+					)
+				})
+			})
 		})
 
 		When("create SecurityPolicy with egress only", func() {
@@ -694,6 +1005,23 @@ var _ = Describe("PolicyController", func() {
 				)
 				assertAllowlist(ctx)
 			})
+
+			When("update SecurityPolicy direction to egress", func() {
+				BeforeEach(func() {
+					policy.Direction = schema.PolicyDirectionEgress
+					By(fmt.Sprintf("update SecurityPolicy %+v", policy))
+					server.TrackerFactory().SecurityPolicy().CreateOrUpdate(policy)
+				})
+				It("should create policy isolates egress only", func() {
+					assertPoliciesNum(ctx, 1)
+					assertHasPolicy(ctx, constants.Tier2, true, "", v1alpha1.DefaultRuleDrop,
+						[]networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+						nil,
+						NewSecurityPolicyRuleEgress("udp", "123", nil, labelA, labelC),
+						NewSecurityPolicyApplyPeer("", labelA, labelB),
+					)
+				})
+			})
 		})
 
 		When("create SecurityPolicy with no rules", func() {
@@ -716,6 +1044,78 @@ var _ = Describe("PolicyController", func() {
 			})
 		})
 
+		When("create SecurityPolicy applies to no endpoints", func() {
+			var policy *schema.SecurityPolicy
+
+			BeforeEach(func() {
+				policy = NewSecurityPolicy(everouteCluster, false, nil)
+				policy.Ingress = append(policy.Ingress, *NewNetworkPolicyRule("tcp", "20-80", nil, labelB))
+				By(fmt.Sprintf("create SecurityPolicy %+v", policy))
+				server.TrackerFactory().SecurityPolicy().CreateOrUpdate(policy)
+			})
+
+			It("should record the policy applies to no endpoints", func() {
+				Eventually(func() bool {
+					value, ok := getEmptyAppliedToMetric(policy.GetID())
+					return ok && value == 1
+				}, timeout, interval).Should(BeTrue())
+				assertPoliciesNum(ctx, 0)
+			})
+
+			It("should record the sync duration of the policy", func() {
+				samples := getSyncDurationSampleCount(pc.SecurityPolicyKind)
+				policy.Ingress = append(policy.Ingress, *NewNetworkPolicyRule("udp", "53", nil, labelB))
+				server.TrackerFactory().SecurityPolicy().CreateOrUpdate(policy)
+				Eventually(func() uint64 {
+					return getSyncDurationSampleCount(pc.SecurityPolicyKind)
+				}, timeout, interval).Should(BeNumerically(">", samples))
+			})
+
+			When("update SecurityPolicy applies to selector", func() {
+				BeforeEach(func() {
+					policy.ApplyTo = NewSecurityPolicy(everouteCluster, false, nil, labelA).ApplyTo
+					By(fmt.Sprintf("update SecurityPolicy %+v", policy))
+					server.TrackerFactory().SecurityPolicy().CreateOrUpdate(policy)
+				})
+
+				It("should remove the policy from empty applied-to record", func() {
+					assertPoliciesNum(ctx, 1)
+					Eventually(func() bool {
+						_, ok := getEmptyAppliedToMetric(policy.GetID())
+						return ok
+					}, timeout, interval).Should(BeFalse())
+				})
+			})
+
+			When("empty AppliedTo applies to all endpoints", func() {
+				BeforeEach(func() {
+					Expect(policyController.SetEmptyAppliedToMode(pc.EmptyAppliedToAll)).Should(Succeed())
+					// trigger the policy reconcile
+					server.TrackerFactory().SecurityPolicy().CreateOrUpdate(policy)
+				})
+				AfterEach(func() {
+					Expect(policyController.SetEmptyAppliedToMode(pc.EmptyAppliedToNone)).Should(Succeed())
+				})
+
+				It("should create policy applies to all endpoints", func() {
+					assertPoliciesNum(ctx, 1)
+					assertHasPolicy(ctx, constants.Tier2, true, "", v1alpha1.DefaultRuleDrop, allPolicyTypes(),
+						NewSecurityPolicyRuleIngress("tcp", "20-80", nil, labelB),
+						nil,
+						v1alpha1.ApplyToPeer{EndpointSelector: &labels.Selector{}},
+					)
+					Eventually(func() bool {
+						_, ok := getEmptyAppliedToMetric(policy.GetID())
+						return ok
+					}, timeout, interval).Should(BeFalse())
+				})
+			})
+		})
+
+		It("should fail set unknown empty AppliedTo mode", func() {
+			Expect(policyController.SetEmptyAppliedToMode("unknown")).ShouldNot(Succeed())
+		})
+
 		When("create SecurityPolicy with enable logging", func() {
 			var policy *schema.SecurityPolicy
 
@@ -740,6 +1140,26 @@ var _ = Describe("PolicyController", func() {
 					NewSecurityPolicyApplyPeer("", labelA, labelB),
 				)
 				assertLogging(ctx, Default, true, policy.ID, policy.Name, pc.LoggingTagPolicyTypeSecurityPolicyAllow)
+				assertLoggingActions(ctx, Default, true, true)
+			})
+
+			When("only log dropped connections", func() {
+				BeforeEach(func() {
+					policyController.SetLoggingDropOnly(true)
+					policy.Name = rand.String(10)
+					By(fmt.Sprintf("update SecurityPolicy %+v", policy))
+					server.TrackerFactory().SecurityPolicy().CreateOrUpdate(policy)
+				})
+				AfterEach(func() {
+					policyController.SetLoggingDropOnly(false)
+				})
+
+				It("should update policy with logging dropped connections only", func() {
+					Eventually(func(g Gomega) {
+						assertLogging(ctx, g, true, policy.ID, policy.Name, pc.LoggingTagPolicyTypeSecurityPolicyAllow)
+						assertLoggingActions(ctx, g, true, false)
+					}, timeout, interval).Should(Succeed())
+				})
 			})
 
 			When("update SecurityPolicy with disable logging", func() {
@@ -800,17 +1220,74 @@ var _ = Describe("PolicyController", func() {
 				assertLogging(ctx, Default, true, policy.ID, policy.Name, pc.LoggingTagPolicyTypeSecurityPolicyDeny)
 			})
 
-			When("update SecurityPolicy with disable logging", func() {
+			When("update SecurityPolicy with disable logging", func() {
+				BeforeEach(func() {
+					policy.EnableLogging = false
+					By(fmt.Sprintf("update SecurityPolicy %+v", policy))
+					server.TrackerFactory().SecurityPolicy().CreateOrUpdate(policy)
+				})
+
+				It("should update policy with disable logging", func() {
+					Eventually(func(g Gomega) {
+						assertLogging(ctx, g, false, policy.ID, policy.Name, pc.LoggingTagPolicyTypeSecurityPolicyDeny)
+					}, timeout, interval).Should(Succeed())
+				})
+			})
+		})
+
+		When("create SecurityPolicy allows egress to everoute controllers only", func() {
+			var cluster *schema.EverouteCluster
+			var policy *schema.SecurityPolicy
+
+			controllerEgress := func() *v1alpha1.Rule {
+				egress := NewSecurityPolicyRuleEgress("tcp", "6443", nil)
+				for _, ctrl := range cluster.ControllerInstances {
+					egress.To = append(egress.To, v1alpha1.SecurityPolicyPeer{
+						Endpoint: &v1alpha1.NamespacedName{Name: endpoint.GetCtrlEndpointName(cluster.GetID(), ctrl), Namespace: namespace},
+					})
+				}
+				return egress
+			}
+
+			BeforeEach(func() {
+				cluster = NewEverouteCluster(everouteCluster, schema.GlobalPolicyActionAllow)
+				By(fmt.Sprintf("create everouteCluster %+v", cluster))
+				server.TrackerFactory().EverouteCluster().CreateOrUpdate(cluster)
+
+				policy = NewSecurityPolicy(everouteCluster, false, nil, labelA)
+				egress := NewNetworkPolicyRule("tcp", "6443", nil)
+				egress.Type = schema.NetworkPolicyRuleTypeEverouteController
+				policy.Egress = append(policy.Egress, *egress)
+				By(fmt.Sprintf("create SecurityPolicy %+v", policy))
+				server.TrackerFactory().SecurityPolicy().CreateOrUpdate(policy)
+			})
+
+			It("should allow egress to controllers and deny other egress", func() {
+				// the internal controller policy and the security policy
+				assertPoliciesNum(ctx, 2)
+				assertHasPolicy(ctx, constants.Tier2, true, "", v1alpha1.DefaultRuleDrop, allPolicyTypes(),
+					nil,
+					controllerEgress(),
+					NewSecurityPolicyApplyPeer("", labelA),
+				)
+			})
+
+			When("add controller instance to everouteCluster", func() {
 				BeforeEach(func() {
-					policy.EnableLogging = false
-					By(fmt.Sprintf("update SecurityPolicy %+v", policy))
-					server.TrackerFactory().SecurityPolicy().CreateOrUpdate(policy)
+					cluster.ControllerInstances = append(cluster.ControllerInstances, schema.EverouteControllerInstance{
+						IPAddr: NewRandomIP().String(),
+					})
+					By(fmt.Sprintf("update everouteCluster to %+v", cluster))
+					server.TrackerFactory().EverouteCluster().CreateOrUpdate(cluster)
 				})
 
-				It("should update policy with disable logging", func() {
-					Eventually(func(g Gomega) {
-						assertLogging(ctx, g, false, policy.ID, policy.Name, pc.LoggingTagPolicyTypeSecurityPolicyDeny)
-					}, timeout, interval).Should(Succeed())
+				It("should allow egress to the new controller", func() {
+					assertPoliciesNum(ctx, 2)
+					assertHasPolicy(ctx, constants.Tier2, true, "", v1alpha1.DefaultRuleDrop, allPolicyTypes(),
+						nil,
+						controllerEgress(),
+						NewSecurityPolicyApplyPeer("", labelA),
+					)
 				})
 			})
 		})
@@ -931,6 +1408,25 @@ var _ = Describe("PolicyController", func() {
 				)
 			})
 
+			It("should drop all egress traffics of ingress-only isolation", func() {
+				assertPoliciesNum(ctx, 2)
+				policyList, err := crdClient.SecurityV1alpha1().SecurityPolicies(namespace).List(ctx, metav1.ListOptions{})
+				Expect(err).Should(Succeed())
+
+				var egressPolicies []v1alpha1.SecurityPolicy
+				for _, item := range policyList.Items {
+					for _, policyType := range item.Spec.PolicyTypes {
+						if policyType == networkingv1.PolicyTypeEgress {
+							egressPolicies = append(egressPolicies, item)
+						}
+					}
+				}
+				Expect(egressPolicies).Should(HaveLen(1))
+				Expect(egressPolicies[0].Spec.DefaultRule).Should(Equal(v1alpha1.DefaultRuleDrop))
+				Expect(egressPolicies[0].Spec.EgressRules).Should(BeEmpty())
+				Expect(pc.ValidateIsolationPolicies(policy.GetID(), policyList.Items)).Should(Succeed())
+			})
+
 			It("update ingress with service", func() {
 				svcA := NewService(*NewNetworkPolicyRulePort("TCP", "", "34"))
 				NetworkPolicyRuleAddServices(&policy.Ingress[0], svcA.ID)
@@ -956,14 +1452,9 @@ var _ = Describe("PolicyController", func() {
 					ingress = NewNetworkPolicyRule("tcp", "22-80", &networkingv1.IPBlock{CIDR: "192.168.0.0/24,192.168.3.1-192.168.3.100", Except: []string{"192.168.3.0/26", "192.168.0.0"}})
 					policy.Ingress = []schema.NetworkPolicyRule{*ingress}
 
+					// cidrs inside the except 192.168.3.0/26 are dropped
 					ingressBlock = []*networkingv1.IPBlock{
 						{CIDR: "192.168.0.0/24", Except: []string{"192.168.0.0/32"}},
-						{CIDR: "192.168.3.1/32", Except: []string{"192.168.3.0/26"}},
-						{CIDR: "192.168.3.2/31", Except: []string{"192.168.3.0/26"}},
-						{CIDR: "192.168.3.4/30", Except: []string{"192.168.3.0/26"}},
-						{CIDR: "192.168.3.8/29", Except: []string{"192.168.3.0/26"}},
-						{CIDR: "192.168.3.16/28", Except: []string{"192.168.3.0/26"}},
-						{CIDR: "192.168.3.32/27", Except: []string{"192.168.3.0/26"}},
 						{CIDR: "192.168.3.64/27", Except: []string{}},
 						{CIDR: "192.168.3.96/30", Except: []string{}},
 						{CIDR: "192.168.3.100/32", Except: []string{}},
@@ -1021,6 +1512,101 @@ var _ = Describe("PolicyController", func() {
 			})
 		})
 
+		When("create IsolationPolicy with partial isolation keep tier", func() {
+			var policy *schema.IsolationPolicy
+
+			BeforeEach(func() {
+				policyController.SetPartialIsolationKeepTier(true)
+				policy = NewIsolationPolicy(everouteCluster, vm, schema.IsolationModePartial)
+			})
+			AfterEach(func() {
+				policyController.SetPartialIsolationKeepTier(false)
+			})
+
+			It("should keep egress policy with empty rules in tier1", func() {
+				policy.Ingress = append(policy.Ingress, *NewNetworkPolicyRule("tcp", "22", nil, labelA))
+				By(fmt.Sprintf("create IsolationPolicy %+v", policy))
+				server.TrackerFactory().IsolationPolicy().CreateOrUpdate(policy)
+
+				assertPoliciesNum(ctx, 2)
+				assertHasPolicy(ctx, constants.Tier1, true, "", v1alpha1.DefaultRuleDrop,
+					[]networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+					nil, nil,
+					NewSecurityPolicyApplyPeer(vnicA.GetID()),
+					NewSecurityPolicyApplyPeer(vnicB.GetID()),
+				)
+				assertHasPolicy(ctx, constants.Tier1, true, "", v1alpha1.DefaultRuleDrop,
+					[]networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+					NewSecurityPolicyRuleIngress("tcp", "22", nil, labelA),
+					nil,
+					NewSecurityPolicyApplyPeer(vnicA.GetID()),
+					NewSecurityPolicyApplyPeer(vnicB.GetID()),
+				)
+			})
+
+			It("should keep ingress policy with empty rules in tier1", func() {
+				policy.Egress = append(policy.Egress, *NewNetworkPolicyRule("udp", "53", nil, labelB))
+				By(fmt.Sprintf("create IsolationPolicy %+v", policy))
+				server.TrackerFactory().IsolationPolicy().CreateOrUpdate(policy)
+
+				assertPoliciesNum(ctx, 2)
+				assertHasPolicy(ctx, constants.Tier1, true, "", v1alpha1.DefaultRuleDrop,
+					[]networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+					nil, nil,
+					NewSecurityPolicyApplyPeer(vnicA.GetID()),
+					NewSecurityPolicyApplyPeer(vnicB.GetID()),
+				)
+				assertHasPolicy(ctx, constants.Tier1, true, "", v1alpha1.DefaultRuleDrop,
+					[]networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+					nil,
+					NewSecurityPolicyRuleEgress("udp", "53", nil, labelB),
+					NewSecurityPolicyApplyPeer(vnicA.GetID()),
+					NewSecurityPolicyApplyPeer(vnicB.GetID()),
+				)
+			})
+		})
+
+		When("create IsolationPolicy with isolation block arp", func() {
+			var policy *schema.IsolationPolicy
+
+			BeforeEach(func() {
+				policyController.SetIsolationBlockARP(true)
+			})
+			AfterEach(func() {
+				policyController.SetIsolationBlockARP(false)
+			})
+
+			assertBlockARP := func(blockARP bool) {
+				Eventually(func() []bool {
+					policyList, err := crdClient.SecurityV1alpha1().SecurityPolicies(namespace).List(ctx, metav1.ListOptions{})
+					Expect(err).Should(Succeed())
+					var blockARPs []bool
+					for _, item := range policyList.Items {
+						blockARPs = append(blockARPs, item.Spec.BlockARP)
+					}
+					return blockARPs
+				}, timeout, interval).Should(HaveEach(blockARP))
+			}
+
+			It("should block arp of completely isolation", func() {
+				policy = NewIsolationPolicy(everouteCluster, vm, schema.IsolationModeAll)
+				By(fmt.Sprintf("create IsolationPolicy %+v", policy))
+				server.TrackerFactory().IsolationPolicy().CreateOrUpdate(policy)
+
+				assertPoliciesNum(ctx, 1)
+				assertBlockARP(true)
+			})
+
+			It("should not block arp of partial isolation", func() {
+				policy = NewIsolationPolicy(everouteCluster, vm, schema.IsolationModePartial)
+				By(fmt.Sprintf("create IsolationPolicy %+v", policy))
+				server.TrackerFactory().IsolationPolicy().CreateOrUpdate(policy)
+
+				assertPoliciesNum(ctx, 2)
+				assertBlockARP(false)
+			})
+		})
+
 		When("create IsolationPolicy with allow alg protocol", func() {
 			var policy *schema.IsolationPolicy
 			var egress_ftp *schema.NetworkPolicyRule
@@ -1489,6 +2075,25 @@ var _ = Describe("PolicyController", func() {
 				time.Sleep(3 * time.Second) // wait for reconcile
 				assertPoliciesNum(ctx, 0)
 			})
+
+			It("should record event the policy applies to no endpoints", func() {
+				assertEmptyAppliedToEvent(policy.GetID())
+			})
+
+			When("empty AppliedTo applies to all endpoints", func() {
+				BeforeEach(func() {
+					Expect(policyController.SetEmptyAppliedToMode(pc.EmptyAppliedToAll)).Should(Succeed())
+					server.TrackerFactory().SecurityPolicy().CreateOrUpdate(policy)
+				})
+				AfterEach(func() {
+					Expect(policyController.SetEmptyAppliedToMode(pc.EmptyAppliedToNone)).Should(Succeed())
+				})
+
+				It("should not create security policy with empty group", func() {
+					time.Sleep(3 * time.Second) // wait for reconcile
+					assertPoliciesNum(ctx, 0)
+				})
+			})
 		})
 
 		When("create SecurityPolicy with normal security group", func() {
@@ -1567,6 +2172,42 @@ var _ = Describe("PolicyController", func() {
 				})
 			})
 
+			When("update security group members from vms to labels", func() {
+				BeforeEach(func() {
+					assertPoliciesNum(ctx, 1)
+					normalGroup.VMs = nil
+					normalGroup.LabelGroups = []schema.LabelGroup{{Labels: LabelAsReference(labelA)}}
+					server.TrackerFactory().SecurityGroup().CreateOrUpdate(normalGroup)
+				})
+
+				It("should replace vm peers with label peers", func() {
+					assertPoliciesNum(ctx, 1)
+					assertHasPolicy(ctx, constants.Tier2, true, "", v1alpha1.DefaultRuleDrop, allPolicyTypes(),
+						nil,
+						nil,
+						NewSecurityPolicyApplyPeer("", labelA),
+					)
+				})
+
+				When("update security group members from labels to vms", func() {
+					BeforeEach(func() {
+						normalGroup.VMs = []schema.ObjectReference{{ID: vm.ID}}
+						normalGroup.LabelGroups = nil
+						server.TrackerFactory().SecurityGroup().CreateOrUpdate(normalGroup)
+					})
+
+					It("should replace label peers with vm peers", func() {
+						assertPoliciesNum(ctx, 1)
+						assertHasPolicy(ctx, constants.Tier2, true, "", v1alpha1.DefaultRuleDrop, allPolicyTypes(),
+							nil,
+							nil,
+							NewSecurityPolicyApplyPeer(vnicA.ID),
+							NewSecurityPolicyApplyPeer(vnicB.ID),
+						)
+					})
+				})
+			})
+
 			When("update security group to empty", func() {
 				BeforeEach(func() {
 					normalGroup.VMs = nil
@@ -1610,8 +2251,248 @@ var _ = Describe("PolicyController", func() {
 			})
 		})
 	})
+	Context("PreviewPolicy", func() {
+		When("preview blocklist SecurityPolicy", func() {
+			var policy *schema.SecurityPolicy
+
+			BeforeEach(func() {
+				policy = NewSecurityPolicy(everouteCluster, false, nil, labelA, labelB)
+				policy.IsBlocklist = true
+			})
+
+			It("should generate blocklist policy", func() {
+				assertPreviewHasPolicy(policy, 1, constants.Tier2, false, "", v1alpha1.DefaultRuleNone, allPolicyTypes(),
+					nil,
+					nil,
+					NewSecurityPolicyApplyPeer("", labelA, labelB),
+				)
+				policies, err := policyController.PreviewPolicy(policy)
+				Expect(err).Should(Succeed())
+				Expect(policies[0].Spec.IsBlocklist).Should(BeTrue())
+				Expect(policies[0].Spec.Priority).Should(Equal(pc.BlocklistPriority))
+				assertPoliciesNum(ctx, 0)
+			})
+		})
+
+		When("preview SecurityPolicy applies to no endpoints", func() {
+			It("should return empty applied-to error", func() {
+				policy := NewSecurityPolicy(everouteCluster, false, nil)
+				_, err := policyController.PreviewPolicy(policy)
+				Expect(err).Should(HaveOccurred())
+				Expect(pc.IsEmptyAppliedToError(err)).Should(BeTrue())
+			})
+
+			It("should not take parse error as empty applied-to", func() {
+				policy := NewSecurityPolicy(everouteCluster, false, nil, labelA)
+				policy.Ingress = append(policy.Ingress, schema.NetworkPolicyRule{Type: schema.NetworkPolicyRuleTypeIPBlock})
+				_, err := policyController.PreviewPolicy(policy)
+				Expect(err).Should(HaveOccurred())
+				Expect(pc.IsEmptyAppliedToError(err)).Should(BeFalse())
+			})
+		})
+
+		When("preview communicable SecurityPolicy", func() {
+			var policy *schema.SecurityPolicy
+
+			BeforeEach(func() {
+				policy = NewSecurityPolicy(everouteCluster, true, nil, labelA, labelB)
+				policy.Ingress = append(policy.Ingress, *NewNetworkPolicyRule("tcp", "20-80", nil, labelB, labelC))
+			})
+
+			It("should generate policy and intragroup policy", func() {
+				assertPreviewHasPolicy(policy, 2, constants.Tier2, true, "", v1alpha1.DefaultRuleDrop, allPolicyTypes(),
+					NewSecurityPolicyRuleIngress("tcp", "20-80", nil, labelB, labelC),
+					nil,
+					NewSecurityPolicyApplyPeer("", labelA, labelB),
+				)
+				assertPreviewHasPolicy(policy, 2, constants.Tier2, false, "", v1alpha1.DefaultRuleDrop, allPolicyTypes(),
+					NewSecurityPolicyRuleIngress("", "", nil, labelA, labelB),
+					NewSecurityPolicyRuleEgress("", "", nil, labelA, labelB),
+					NewSecurityPolicyApplyPeer("", labelA, labelB),
+				)
+				assertPoliciesNum(ctx, 0)
+			})
+		})
+
+		When("preview SecurityPolicy with security group", func() {
+			var policy *schema.SecurityPolicy
+			var vnic *schema.VMNic
+
+			BeforeEach(func() {
+				vm := NewRandomVM()
+				vnic = NewRandomVMNicAttachedTo(vm)
+				group := NewSecurityGroup(everouteCluster)
+				group.LabelGroups = append(group.LabelGroups, schema.LabelGroup{
+					Labels: LabelAsReference(labelA, labelC),
+				})
+				group.VMs = append(group.VMs, schema.ObjectReference{ID: vm.ID})
+
+				By(fmt.Sprintf("create vm %+v and security group %+v", vm, group))
+				server.TrackerFactory().VM().CreateOrUpdate(vm)
+				server.TrackerFactory().SecurityGroup().CreateOrUpdate(group)
+				policy = NewSecurityPolicy(everouteCluster, false, group)
+			})
+
+			It("should generate policy applied to security group members", func() {
+				assertPreviewHasPolicy(policy, 1, constants.Tier2, true, "", v1alpha1.DefaultRuleDrop, allPolicyTypes(),
+					nil,
+					nil,
+					NewSecurityPolicyApplyPeer(vnic.ID),
+					NewSecurityPolicyApplyPeer("", labelA, labelC),
+				)
+				assertPoliciesNum(ctx, 0)
+			})
+		})
+	})
+})
+
+var _ = Describe("ValidateIsolationPolicies", func() {
+	newIsolationPolicy := func(defaultRule v1alpha1.DefaultRuleType, policyTypes ...networkingv1.PolicyType) v1alpha1.SecurityPolicy {
+		return v1alpha1.SecurityPolicy{Spec: v1alpha1.SecurityPolicySpec{DefaultRule: defaultRule, PolicyTypes: policyTypes}}
+	}
+
+	It("should pass when ingress and egress both drop by default", func() {
+		Expect(pc.ValidateIsolationPolicies("isolation", []v1alpha1.SecurityPolicy{
+			newIsolationPolicy(v1alpha1.DefaultRuleDrop, networkingv1.PolicyTypeIngress),
+			newIsolationPolicy(v1alpha1.DefaultRuleDrop, networkingv1.PolicyTypeEgress),
+		})).Should(Succeed())
+		Expect(pc.ValidateIsolationPolicies("isolation", []v1alpha1.SecurityPolicy{
+			newIsolationPolicy(v1alpha1.DefaultRuleDrop, networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress),
+		})).Should(Succeed())
+	})
+
+	It("should fail when ingress-only isolation leaves egress open", func() {
+		err := pc.ValidateIsolationPolicies("isolation", []v1alpha1.SecurityPolicy{
+			newIsolationPolicy(v1alpha1.DefaultRuleDrop, networkingv1.PolicyTypeIngress),
+		})
+		Expect(pc.IsIsolationPostureError(err)).Should(BeTrue())
+
+		err = pc.ValidateIsolationPolicies("isolation", []v1alpha1.SecurityPolicy{
+			newIsolationPolicy(v1alpha1.DefaultRuleDrop, networkingv1.PolicyTypeIngress),
+			newIsolationPolicy(v1alpha1.DefaultRuleNone, networkingv1.PolicyTypeEgress),
+		})
+		Expect(pc.IsIsolationPostureError(err)).Should(BeTrue())
+		Expect(err.Error()).Should(ContainSubstring(string(networkingv1.PolicyTypeEgress)))
+	})
 })
 
+func assertPreviewHasPolicy(policy *schema.SecurityPolicy, numOfPolicies int, tier string, symmetricMode bool, enforceMode v1alpha1.PolicyMode,
+	defaultRule v1alpha1.DefaultRuleType, policyTypes []networkingv1.PolicyType, ingress, egress *v1alpha1.Rule, applyToPeers ...v1alpha1.ApplyToPeer) {
+	Eventually(func() bool {
+		policies, err := policyController.PreviewPolicy(policy)
+		if err != nil || len(policies) != numOfPolicies {
+			return false
+		}
+		for item := range policies {
+			if matchPolicy(&policies[item], tier, symmetricMode, enforceMode,
+				defaultRule, policyTypes, ingress, egress, applyToPeers...) {
+				return true
+			}
+		}
+		return false
+	}, timeout, interval).Should(BeTrue())
+}
+
+// assertEmptyAppliedToEvent drains recorded events until the empty AppliedTo event of the policy found
+func assertEmptyAppliedToEvent(policyID string) {
+	Eventually(func() bool {
+		for {
+			select {
+			case event := <-eventRecorder.Events:
+				if strings.Contains(event, pc.EmptyAppliedToReason) && strings.Contains(event, policyID) {
+					return true
+				}
+			default:
+				return false
+			}
+		}
+	}, timeout, interval).Should(BeTrue())
+}
+
+func getEmptyAppliedToMetric(policyID string) (float64, bool) {
+	metricFamilies, err := metrics.Registry.Gather()
+	Expect(err).Should(Succeed())
+	for _, mf := range metricFamilies {
+		if mf.GetName() != pc.EmptyAppliedToMetricName {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == pc.PolicyLabel && label.GetValue() == policyID {
+					return m.GetGauge().GetValue(), true
+				}
+			}
+		}
+	}
+	return 0, false
+}
+
+func getIPBlockExceedLimitMetric(policyID string) float64 {
+	metricFamilies, err := metrics.Registry.Gather()
+	Expect(err).Should(Succeed())
+	for _, mf := range metricFamilies {
+		if mf.GetName() != pc.IPBlockExceedLimitMetricName {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == pc.PolicyLabel && label.GetValue() == policyID {
+					return m.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func getIntragroupPolicy(ctx context.Context, g Gomega) *v1alpha1.SecurityPolicy {
+	policyList, err := crdClient.SecurityV1alpha1().SecurityPolicies(namespace).List(ctx, metav1.ListOptions{})
+	g.Expect(err).Should(Succeed())
+	for item := range policyList.Items {
+		if strings.HasPrefix(policyList.Items[item].Name, pc.SecurityPolicyCommunicablePrefix) {
+			return &policyList.Items[item]
+		}
+	}
+	g.Expect(policyList.Items).Should(ContainElement(HaveField("Name", HavePrefix(pc.SecurityPolicyCommunicablePrefix))))
+	return nil
+}
+
+func getMissingServiceRulesMetric(policyID string) float64 {
+	metricFamilies, err := metrics.Registry.Gather()
+	Expect(err).Should(Succeed())
+	for _, mf := range metricFamilies {
+		if mf.GetName() != pc.MissingServiceRulesMetricName {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == pc.PolicyLabel && label.GetValue() == policyID {
+					return m.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func getSyncDurationSampleCount(kind string) uint64 {
+	metricFamilies, err := metrics.Registry.Gather()
+	Expect(err).Should(Succeed())
+	for _, mf := range metricFamilies {
+		if mf.GetName() != pc.SyncDurationMetricName {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == pc.PolicyKindLabel && label.GetValue() == kind {
+					return m.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+	}
+	return 0
+}
+
 func assertPoliciesNum(ctx context.Context, numOfPolicies int) {
 	Eventually(func() int {
 		policyList, err := crdClient.SecurityV1alpha1().SecurityPolicies(namespace).List(ctx, metav1.ListOptions{})
@@ -1657,9 +2538,43 @@ func assertLogging(ctx context.Context, g Gomega, enabled bool, policyID, policy
 		g.Expect(policy.Spec.Logging.Tags[pc.LoggingTagPolicyID]).Should(Equal(policyID))
 		g.Expect(policy.Spec.Logging.Tags[pc.LoggingTagPolicyName]).Should(Equal(policyName))
 		g.Expect(policy.Spec.Logging.Tags[pc.LoggingTagPolicyType]).Should(Equal(policyType))
+		for _, rule := range policy.Spec.IngressRules {
+			assertRuleLogging(g, rule.Logging, enabled, policyID, policyName, policyType, pc.LoggingTagDirectionIngress)
+		}
+		for _, rule := range policy.Spec.EgressRules {
+			assertRuleLogging(g, rule.Logging, enabled, policyID, policyName, policyType, pc.LoggingTagDirectionEgress)
+		}
+		if policy.Spec.DefaultRule == v1alpha1.DefaultRuleDrop {
+			assertRuleLogging(g, policy.Spec.DefaultIngressRuleLogging, enabled, policyID, policyName, policyType, pc.LoggingTagDirectionIngress)
+			assertRuleLogging(g, policy.Spec.DefaultEgressRuleLogging, enabled, policyID, policyName, policyType, pc.LoggingTagDirectionEgress)
+		}
+	}
+}
+
+func assertLoggingActions(ctx context.Context, g Gomega, dropEnabled, allowEnabled bool) {
+	policyList, err := crdClient.SecurityV1alpha1().SecurityPolicies(namespace).List(ctx, metav1.ListOptions{})
+	g.Expect(err).Should(Succeed())
+	g.Expect(policyList.Items).ShouldNot(HaveLen(0))
+	for _, policy := range policyList.Items {
+		g.Expect(policy.Spec.Logging.IsDropEnabled()).Should(Equal(dropEnabled))
+		g.Expect(policy.Spec.Logging.IsAllowEnabled()).Should(Equal(allowEnabled))
+		for _, rule := range append(policy.Spec.IngressRules, policy.Spec.EgressRules...) {
+			g.Expect(rule.Logging.IsDropEnabled()).Should(Equal(dropEnabled))
+			g.Expect(rule.Logging.IsAllowEnabled()).Should(Equal(allowEnabled))
+		}
 	}
 }
 
+func assertRuleLogging(g Gomega, logging *v1alpha1.Logging, enabled bool, policyID, policyName, policyType, direction string) {
+	g.Expect(logging).ShouldNot(BeNil())
+	g.Expect(logging.Enabled).Should(Equal(enabled))
+	g.Expect(logging.Tags).Should(HaveLen(4))
+	g.Expect(logging.Tags[pc.LoggingTagPolicyID]).Should(Equal(policyID))
+	g.Expect(logging.Tags[pc.LoggingTagPolicyName]).Should(Equal(policyName))
+	g.Expect(logging.Tags[pc.LoggingTagPolicyType]).Should(Equal(policyType))
+	g.Expect(logging.Tags[pc.LoggingTagDirection]).Should(Equal(direction))
+}
+
 func assertHasPolicy(ctx context.Context, tier string, symmetricMode bool, enforceMode v1alpha1.PolicyMode, defaultRule v1alpha1.DefaultRuleType,
 	policyTypes []networkingv1.PolicyType, ingress, egress *v1alpha1.Rule, applyToPeers ...v1alpha1.ApplyToPeer) {
 	Eventually(func() bool {
@@ -1743,6 +2658,9 @@ func matchPolicy(policy *v1alpha1.SecurityPolicy, tier string, symmetricMode boo
 		if !(len(rule[0].Ports) == 0 && len(expectRule.Ports) == 0 || matchPorts(rule[0].Ports, expectRule.Ports)) {
 			return false
 		}
+		if rule[0].EnforcementMode != expectRule.EnforcementMode {
+			return false
+		}
 
 		return (len(rule[0].From) == 0 && len(expectRule.From) == 0 || matchPeer(rule[0].From, expectRule.From)) &&
 			(len(rule[0].To) == 0 && len(expectRule.To) == 0 || matchPeer(rule[0].To, expectRule.To))
@@ -0,0 +1,86 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"errors"
+	"fmt"
+
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+// EmptyAppliedToError means the tower policy resolves to zero endpoints, it is not a parse failure,
+// but no v1alpha1.SecurityPolicy would be generated for the policy
+type EmptyAppliedToError struct {
+	PolicyID string
+}
+
+func (e *EmptyAppliedToError) Error() string {
+	return fmt.Sprintf("policy %s applies to no endpoints", e.PolicyID)
+}
+
+func IsEmptyAppliedToError(err error) bool {
+	var target *EmptyAppliedToError
+	return errors.As(err, &target)
+}
+
+// IPBlockExceedLimitError means cidrs and excepts generated from the ip block of a rule exceed the max ip
+// block entries, the policy is rejected
+type IPBlockExceedLimitError struct {
+	IPBlock string
+	Limit   int
+}
+
+func (e *IPBlockExceedLimitError) Error() string {
+	return fmt.Sprintf("cidrs and excepts generated from %s exceed the limit %d", e.IPBlock, e.Limit)
+}
+
+func IsIPBlockExceedLimitError(err error) bool {
+	var target *IPBlockExceedLimitError
+	return errors.As(err, &target)
+}
+
+// MissingServicesError means all ports of the rule come from services not found, the rule is dropped
+// rather than generated without ports, which would match all ports of the peers
+type MissingServicesError struct {
+	Services []string
+}
+
+func (e *MissingServicesError) Error() string {
+	return fmt.Sprintf("all services %v of the rule not found", e.Services)
+}
+
+func IsMissingServicesError(err error) bool {
+	var target *MissingServicesError
+	return errors.As(err, &target)
+}
+
+// IsolationPostureError means policies generated from the IsolationPolicy leave traffics of the
+// direction not isolated, e.g. egress of ingress-only isolation allowed accidentally
+type IsolationPostureError struct {
+	PolicyID  string
+	Direction networkingv1.PolicyType
+}
+
+func (e *IsolationPostureError) Error() string {
+	return fmt.Sprintf("isolation policy %s doesn't drop %s traffics by default", e.PolicyID, e.Direction)
+}
+
+func IsIsolationPostureError(err error) bool {
+	var target *IsolationPostureError
+	return errors.As(err, &target)
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// EmptyAppliedToMetricName is the full name of the empty applied-to tower policies metric
+	EmptyAppliedToMetricName = "everoute_tower_policy_empty_applied_to"
+	// IPBlockExceedLimitMetricName is the full name of the tower policies rejected for ip block exceeds limit metric
+	IPBlockExceedLimitMetricName = "everoute_tower_policy_ipblock_exceed_limit"
+	// QueueDepthMetricName is the full name of the policy controller workqueue depth metric
+	QueueDepthMetricName = "everoute_tower_policy_queue_depth"
+	// SyncDurationMetricName is the full name of the per-policy sync duration metric
+	SyncDurationMetricName = "everoute_tower_policy_sync_duration_seconds"
+	// MissingServiceRulesMetricName is the full name of the per-policy rules dropped for missing services metric
+	MissingServiceRulesMetricName = "everoute_tower_policy_missing_service_rules"
+
+	// PolicyLabel is the label of the tower policy id in the metrics
+	PolicyLabel = "policy"
+	// QueueLabel is the label of the workqueue name in the metrics
+	QueueLabel = "queue"
+	// PolicyKindLabel is the label of the tower policy kind in the metrics
+	PolicyKindLabel = "kind"
+)
+
+// kinds of tower policy in the sync duration metric
+const (
+	SecurityPolicyKind  = "SecurityPolicy"
+	IsolationPolicyKind = "IsolationPolicy"
+)
+
+// queueDepthUpdateInterval is the interval the workqueue depth metric updated
+const queueDepthUpdateInterval = 5 * time.Second
+
+// emptyAppliedToPolicies records tower SecurityPolicies resolved to zero endpoints, no
+// v1alpha1.SecurityPolicy would be generated for them.
+var emptyAppliedToPolicies = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "everoute",
+	Subsystem: "tower",
+	Name:      "policy_empty_applied_to",
+	Help:      "Whether the tower SecurityPolicy applies to no endpoints",
+}, []string{PolicyLabel})
+
+// ipBlockExceedLimitPolicies records tower policies rejected for ip block of the rules generated cidrs and
+// excepts exceed the max ip block entries
+var ipBlockExceedLimitPolicies = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "everoute",
+	Subsystem: "tower",
+	Name:      "policy_ipblock_exceed_limit",
+	Help:      "Whether the tower policy rejected for ip block exceeds the max ip block entries",
+}, []string{PolicyLabel, PolicyKindLabel})
+
+// queueDepth records the number of items waiting in the workqueues of the policy controller
+var queueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "everoute",
+	Subsystem: "tower",
+	Name:      "policy_queue_depth",
+	Help:      "The number of items waiting in the policy controller workqueue",
+}, []string{QueueLabel})
+
+// policySyncDuration records how long a tower policy translated and applied as v1alpha1.SecurityPolicy
+var policySyncDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "everoute",
+	Subsystem: "tower",
+	Name:      "policy_sync_duration_seconds",
+	Help:      "The duration of syncing a tower policy to SecurityPolicy",
+	Buckets:   prometheus.ExponentialBuckets(0.001, 2, 15),
+}, []string{PolicyKindLabel})
+
+// missingServiceRules records the number of rules of the tower policy dropped since all services referenced
+// by them not found
+var missingServiceRules = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "everoute",
+	Subsystem: "tower",
+	Name:      "policy_missing_service_rules",
+	Help:      "The number of tower policy rules dropped since all services referenced by them not found",
+}, []string{PolicyLabel, PolicyKindLabel})
+
+func init() {
+	metrics.Registry.MustRegister(emptyAppliedToPolicies, ipBlockExceedLimitPolicies, queueDepth, policySyncDuration, missingServiceRules)
+}
+
+// observePolicySync records the sync duration of the tower policy kind since start
+func observePolicySync(kind string, start time.Time) {
+	policySyncDuration.WithLabelValues(kind).Observe(time.Since(start).Seconds())
+}
@@ -24,6 +24,7 @@ import (
 	. "github.com/onsi/gomega"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/tools/record"
 
 	"github.com/everoute/everoute/pkg/client/clientset_generated/clientset"
 	"github.com/everoute/everoute/pkg/client/clientset_generated/clientset/fake"
@@ -34,11 +35,13 @@ import (
 )
 
 var (
-	crdClient       clientset.Interface
-	server          *fakeserver.Server
-	namespace       = metav1.NamespaceDefault
-	stopCh          = make(chan struct{})
-	everouteCluster = rand.String(10)
+	crdClient        clientset.Interface
+	policyController *controller.Controller
+	eventRecorder    *record.FakeRecorder
+	server           *fakeserver.Server
+	namespace        = metav1.NamespaceDefault
+	stopCh           = make(chan struct{})
+	everouteCluster  = rand.String(10)
 )
 
 const (
@@ -61,8 +64,10 @@ var _ = BeforeSuite(func() {
 	crdFactory := externalversions.NewSharedInformerFactory(crdClient, 0)
 
 	By("create and start PolicyController")
-	controller := controller.New(towerFactory, crdFactory, crdClient, 0, namespace, everouteCluster)
-	go controller.Run(10, stopCh)
+	policyController = controller.New(towerFactory, crdFactory, crdClient, 0, namespace, everouteCluster)
+	eventRecorder = record.NewFakeRecorder(1024)
+	policyController.SetEventRecorder(eventRecorder)
+	go policyController.Run(10, stopCh)
 
 	By("start towerFactory and crdFactory")
 	towerFactory.Start(stopCh)
@@ -0,0 +1,77 @@
+/*
+Copyright 2023 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+
+	"k8s.io/klog"
+
+	"github.com/everoute/everoute/plugin/tower/pkg/schema"
+)
+
+func TestSelectSystemEndpoints(t *testing.T) {
+	var logs bytes.Buffer
+	flagSet := flag.NewFlagSet("klog", flag.ContinueOnError)
+	klog.InitFlags(flagSet)
+	_ = flagSet.Set("logtostderr", "false")
+	klog.SetOutput(&logs)
+	defer func() {
+		_ = flagSet.Set("logtostderr", "true")
+		klog.SetOutput(nil)
+	}()
+
+	systemEndpoints1 := &schema.SystemEndpoints{IPPortEndpoints: []schema.IPPortSystemEndpoint{{Key: "key1", IP: "10.0.0.1"}}}
+	systemEndpoints2 := &schema.SystemEndpoints{IPPortEndpoints: []schema.IPPortSystemEndpoint{{Key: "key2", IP: "10.0.0.2"}}}
+
+	t.Run("select the only systemEndpoints", func(t *testing.T) {
+		logs.Reset()
+		if selected := selectSystemEndpoints([]interface{}{systemEndpoints2}); selected != systemEndpoints2 {
+			t.Fatalf("expect select %+v, got %+v", systemEndpoints2, selected)
+		}
+		klog.Flush()
+		if strings.Contains(logs.String(), "found 1 systemEndpoints") {
+			t.Fatalf("unexpected warning for the only systemEndpoints: %s", logs.String())
+		}
+	})
+
+	t.Run("select the lowest one from two systemEndpoints deterministically", func(t *testing.T) {
+		for _, list := range [][]interface{}{{systemEndpoints1, systemEndpoints2}, {systemEndpoints2, systemEndpoints1}} {
+			logs.Reset()
+			if selected := selectSystemEndpoints(list); selected != systemEndpoints1 {
+				t.Fatalf("expect select %+v, got %+v", systemEndpoints1, selected)
+			}
+			klog.Flush()
+			if !strings.Contains(logs.String(), "found 2 systemEndpoints in cluster") {
+				t.Fatalf("expect warning for duplicate systemEndpoints, got logs: %s", logs.String())
+			}
+		}
+	})
+
+	t.Run("select by keys of the endpoints rather than content", func(t *testing.T) {
+		// content of systemEndpoints3 is lower since id endpoints marshaled first, but key of it is higher
+		systemEndpoints3 := &schema.SystemEndpoints{IDEndpoints: []schema.IDSystemEndpoint{{Key: "key3", VMID: "vm1"}}}
+		for _, list := range [][]interface{}{{systemEndpoints1, systemEndpoints3}, {systemEndpoints3, systemEndpoints1}} {
+			if selected := selectSystemEndpoints(list); selected != systemEndpoints1 {
+				t.Fatalf("expect select %+v, got %+v", systemEndpoints1, selected)
+			}
+		}
+	})
+}
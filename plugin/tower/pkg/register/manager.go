@@ -20,6 +20,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"strings"
 	"time"
 
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -43,6 +44,22 @@ type Options struct {
 	// which EverouteCluster should synchronize SecurityPolicy from
 	EverouteCluster string
 	SharedFactory   informer.SharedInformerFactory
+	// keep both ingress and egress policy of partial isolation in Tier1 even if the rules empty
+	PartialIsolationKeepTier bool
+	// generate communicable intragroup policy in symmetric mode
+	IntragroupSymmetricMode bool
+	// limit symmetric mode of communicable intragroup policy to the comma separated protocols, all when empty
+	IntragroupSymmetricProtocols string
+	// treat single ip 0.0.0.0 and :: in ip block as the host address instead of match all
+	ZeroIPAsHost bool
+	// deny ARP and ND of endpoints isolated by isolation mode all besides ip traffics
+	IsolationBlockARP bool
+	// max number of cidrs and excepts generated from an ip block, zero means unlimited
+	MaxIPBlockEntries int
+	// only log dropped connections of policies enabled logging
+	LoggingDropOnly bool
+	// how SecurityPolicy without AppliedTo handled, none or all
+	EmptyAppliedTo string
 }
 
 // InitFlags set and load options from flagset.
@@ -70,6 +87,24 @@ func InitFlags(opts *Options, flagset *flag.FlagSet, flagPrefix string) {
 	flagset.StringVar(&opts.EverouteCluster, withPrefix("everoute-cluster"), "", "Which EverouteCluster should synchronize SecurityPolicy from")
 	flagset.UintVar(&opts.WorkerNumber, withPrefix("worker-number"), 10, "Controller worker number")
 	flagset.DurationVar(&opts.ResyncPeriod, withPrefix("resync-period"), 10*time.Hour, "Controller resync period")
+	flagset.BoolVar(&opts.PartialIsolationKeepTier, withPrefix("partial-isolation-keep-tier"), false,
+		"If true, partial isolation keeps ingress and egress policy in tier1 with default drop even if the rules empty")
+	flagset.BoolVar(&opts.IntragroupSymmetricMode, withPrefix("intragroup-symmetric-mode"), false,
+		"If true, communicable intragroup policy would be generated in symmetric mode")
+	flagset.StringVar(&opts.IntragroupSymmetricProtocols, withPrefix("intragroup-symmetric-protocols"), "",
+		"Comma separated protocols of TCP, UDP, ICMP, ICMPV6, IPIP and GRE, only these protocols would be symmetric in "+
+			"communicable intragroup policy of symmetric mode, all protocols if empty")
+	flagset.BoolVar(&opts.ZeroIPAsHost, withPrefix("zero-ip-as-host"), false,
+		"If true, single ip 0.0.0.0 and :: in ip block would be taken as the host address instead of match all")
+	flagset.BoolVar(&opts.IsolationBlockARP, withPrefix("isolation-block-arp"), false,
+		"If true, ARP and ND of the endpoints isolated by isolation mode all would be denied as well as ip traffics")
+	flagset.IntVar(&opts.MaxIPBlockEntries, withPrefix("max-ipblock-entries"), policy.DefaultMaxIPBlockEntries,
+		"Max number of cidrs and excepts generated from an ip block of a rule, rules exceed it would fail, zero means unlimited")
+	flagset.BoolVar(&opts.LoggingDropOnly, withPrefix("logging-drop-only"), false,
+		"If true, only dropped connections would be logged for policies enabled logging")
+	flagset.StringVar(&opts.EmptyAppliedTo, withPrefix("empty-applied-to"), string(policy.EmptyAppliedToNone),
+		"How SecurityPolicy without AppliedTo handled, one of none and all. If none, the policy takes no effect; "+
+			"if all, the policy applies to all endpoints like k8s NetworkPolicy with empty podSelector")
 }
 
 // AddToManager allow you register controller to Manager.
@@ -94,6 +129,23 @@ func AddToManager(opts *Options, mgr manager.Manager) error {
 	crdFactory := externalversions.NewSharedInformerFactoryWithOptions(crdClient, opts.ResyncPeriod, externalversions.WithNamespace(opts.Namespace))
 	endpointController := endpoint.New(opts.SharedFactory, crdFactory, crdClient, opts.ResyncPeriod, opts.Namespace)
 	policyController := policy.New(opts.SharedFactory, crdFactory, crdClient, opts.ResyncPeriod, opts.Namespace, opts.EverouteCluster)
+	policyController.SetPartialIsolationKeepTier(opts.PartialIsolationKeepTier)
+	policyController.SetIntragroupSymmetricMode(opts.IntragroupSymmetricMode)
+	var intragroupSymmetricProtocols []string
+	if opts.IntragroupSymmetricProtocols != "" {
+		intragroupSymmetricProtocols = strings.Split(opts.IntragroupSymmetricProtocols, ",")
+	}
+	if err := policyController.SetIntragroupSymmetricProtocols(intragroupSymmetricProtocols); err != nil {
+		return err
+	}
+	policyController.SetZeroIPAsHost(opts.ZeroIPAsHost)
+	policyController.SetIsolationBlockARP(opts.IsolationBlockARP)
+	policyController.SetMaxIPBlockEntries(opts.MaxIPBlockEntries)
+	policyController.SetLoggingDropOnly(opts.LoggingDropOnly)
+	if err := policyController.SetEmptyAppliedToMode(policy.EmptyAppliedToMode(opts.EmptyAppliedTo)); err != nil {
+		return err
+	}
+	policyController.SetEventRecorder(mgr.GetEventRecorderFor("everoute-tower-plugin"))
 	globalController := global.New(opts.SharedFactory, crdFactory, crdClient, opts.ResyncPeriod, opts.EverouteCluster)
 
 	err = mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
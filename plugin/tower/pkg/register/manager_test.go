@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/everoute/everoute/plugin/tower/pkg/client"
+	"github.com/everoute/everoute/plugin/tower/pkg/controller/policy"
 )
 
 func TestInitFlags(t *testing.T) {
@@ -36,11 +37,13 @@ func TestInitFlags(t *testing.T) {
 	}{
 		"should prase default options": {
 			expectOptions: &Options{
-				Enable:       &boolFalse,
-				Client:       &client.Client{UserInfo: &client.UserInfo{}, AllowInsecure: true},
-				ResyncPeriod: 10 * time.Hour,
-				WorkerNumber: 10,
-				Namespace:    "tower-space",
+				Enable:            &boolFalse,
+				Client:            &client.Client{UserInfo: &client.UserInfo{}, AllowInsecure: true},
+				ResyncPeriod:      10 * time.Hour,
+				WorkerNumber:      10,
+				Namespace:         "tower-space",
+				MaxIPBlockEntries: policy.DefaultMaxIPBlockEntries,
+				EmptyAppliedTo:    string(policy.EmptyAppliedToNone),
 			},
 		},
 		"should prase normal options with prefix": {
@@ -52,6 +55,8 @@ func TestInitFlags(t *testing.T) {
 				"--plugins.tower.worker-number=1",
 				"--plugins.tower.allow-insecure=false",
 				"--plugins.tower.namespace=test-namespace",
+				"--plugins.tower.max-ipblock-entries=100",
+				"--plugins.tower.empty-applied-to=all",
 			},
 			expectOptions: &Options{
 				Enable: &boolTrue,
@@ -59,9 +64,11 @@ func TestInitFlags(t *testing.T) {
 					URL:      "127.0.0.1:8800",
 					UserInfo: &client.UserInfo{},
 				},
-				ResyncPeriod: time.Second,
-				WorkerNumber: 1,
-				Namespace:    "test-namespace",
+				ResyncPeriod:      time.Second,
+				WorkerNumber:      1,
+				Namespace:         "test-namespace",
+				MaxIPBlockEntries: 100,
+				EmptyAppliedTo:    string(policy.EmptyAppliedToAll),
 			},
 		},
 	}
@@ -35,6 +35,8 @@ type SecurityPolicy struct {
 	PolicyMode      PolicyMode            `json:"policy_mode,omitempty"`
 	IsBlocklist     bool                  `json:"is_blocklist,omitempty"`
 	EnableLogging   bool                  `json:"enable_logging,omitempty"`
+	// Direction is the traffic direction the policy isolates, both directions are isolated when empty
+	Direction PolicyDirection `json:"direction,omitempty"`
 }
 
 type PolicyMode string
@@ -44,6 +46,13 @@ const (
 	PolicyModeWork    = "WORK"
 )
 
+type PolicyDirection string
+
+const (
+	PolicyDirectionIngress PolicyDirection = "INGRESS"
+	PolicyDirectionEgress  PolicyDirection = "EGRESS"
+)
+
 type IsolationPolicy struct {
 	ObjectMeta
 
@@ -72,6 +81,8 @@ type NetworkPolicyRule struct {
 	ExceptIPBlock []string          `json:"except_ip_block,omitempty"`
 	Selector      []ObjectReference `json:"selector"`
 	SecurityGroup *ObjectReference  `json:"security_group,omitempty"`
+	// PolicyMode overrides mode of the policy for the rule, the policy mode is used when empty
+	PolicyMode PolicyMode `json:"policy_mode,omitempty"`
 }
 
 type NetworkPolicyRulePort struct {
@@ -90,11 +101,14 @@ const (
 type NetworkPolicyRulePortProtocol string
 
 const (
-	NetworkPolicyRulePortProtocolIcmp NetworkPolicyRulePortProtocol = "ICMP"
-	NetworkPolicyRulePortProtocolTCP  NetworkPolicyRulePortProtocol = "TCP"
-	NetworkPolicyRulePortProtocolUDP  NetworkPolicyRulePortProtocol = "UDP"
-	NetworkPolicyRulePortProtocolALG  NetworkPolicyRulePortProtocol = "ALG"
-	NetworkPolicyRulePortProtocolIPIP NetworkPolicyRulePortProtocol = "IPIP"
+	NetworkPolicyRulePortProtocolIcmp   NetworkPolicyRulePortProtocol = "ICMP"
+	NetworkPolicyRulePortProtocolIcmpv6 NetworkPolicyRulePortProtocol = "ICMPV6"
+	NetworkPolicyRulePortProtocolTCP    NetworkPolicyRulePortProtocol = "TCP"
+	NetworkPolicyRulePortProtocolUDP    NetworkPolicyRulePortProtocol = "UDP"
+	NetworkPolicyRulePortProtocolALG    NetworkPolicyRulePortProtocol = "ALG"
+	NetworkPolicyRulePortProtocolIPIP   NetworkPolicyRulePortProtocol = "IPIP"
+	NetworkPolicyRulePortProtocolGRE    NetworkPolicyRulePortProtocol = "GRE"
+	NetworkPolicyRulePortProtocolAny    NetworkPolicyRulePortProtocol = "ANY"
 )
 
 type NetworkPolicyRulePortAlgProtocol string
@@ -119,6 +133,8 @@ const (
 	NetworkPolicyRuleTypeIPBlock       NetworkPolicyRuleType = "IP_BLOCK"
 	NetworkPolicyRuleTypeSelector      NetworkPolicyRuleType = "SELECTOR"
 	NetworkPolicyRuleTypeSecurityGroup NetworkPolicyRuleType = "SECURITY_GROUP"
+	// NetworkPolicyRuleTypeEverouteController matches controller instances of all everoute clusters, e.g. the api server
+	NetworkPolicyRuleTypeEverouteController NetworkPolicyRuleType = "EVEROUTE_CONTROLLER"
 )
 
 type SecurityGroup struct {
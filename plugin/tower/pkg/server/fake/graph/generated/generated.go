@@ -143,6 +143,7 @@ type ComplexityRoot struct {
 		ExceptIPBlock              func(childComplexity int) int
 		IPBlock                    func(childComplexity int) int
 		OnlyApplyToExternalTraffic func(childComplexity int) int
+		PolicyMode                 func(childComplexity int) int
 		Ports                      func(childComplexity int) int
 		SecurityGroup              func(childComplexity int) int
 		Selector                   func(childComplexity int) int
@@ -201,6 +202,7 @@ type ComplexityRoot struct {
 
 	SecurityPolicy struct {
 		ApplyTo         func(childComplexity int) int
+		Direction       func(childComplexity int) int
 		Egress          func(childComplexity int) int
 		EnableLogging   func(childComplexity int) int
 		EverouteCluster func(childComplexity int) int
@@ -688,6 +690,13 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.NetworkPolicyRule.OnlyApplyToExternalTraffic(childComplexity), true
 
+	case "NetworkPolicyRule.policy_mode":
+		if e.complexity.NetworkPolicyRule.PolicyMode == nil {
+			break
+		}
+
+		return e.complexity.NetworkPolicyRule.PolicyMode(childComplexity), true
+
 	case "NetworkPolicyRule.ports":
 		if e.complexity.NetworkPolicyRule.Ports == nil {
 			break
@@ -931,6 +940,13 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.SecurityPolicy.ApplyTo(childComplexity), true
 
+	case "SecurityPolicy.direction":
+		if e.complexity.SecurityPolicy.Direction == nil {
+			break
+		}
+
+		return e.complexity.SecurityPolicy.Direction(childComplexity), true
+
 	case "SecurityPolicy.egress":
 		if e.complexity.SecurityPolicy.Egress == nil {
 			break
@@ -1592,6 +1608,12 @@ enum TaskOrderByInput {
     policy_mode: PolicyMode
     is_blocklist: Boolean
     enable_logging: Boolean
+    direction: PolicyDirection
+}
+
+enum PolicyDirection {
+    INGRESS
+    EGRESS
 }
 
 type SecurityPolicyApply {
@@ -1635,6 +1657,7 @@ type NetworkPolicyRule {
     services: [ObjectReference!]
     selector: [ObjectReference!]
     security_group: ObjectReference
+    policy_mode: PolicyMode
 }
 
 type NetworkPolicyRulePort {
@@ -1645,10 +1668,13 @@ type NetworkPolicyRulePort {
 
 enum NetworkPolicyRulePortProtocol {
     ICMP
+    ICMPV6
     TCP
     UDP
     ALG
     IPIP
+    GRE
+    ANY
 }
 
 enum NetworkPolicyRulePortAlgProtocol {
@@ -1661,6 +1687,7 @@ enum NetworkPolicyRuleType {
     IP_BLOCK
     SELECTOR
     SECURITY_GROUP
+    EVEROUTE_CONTROLLER
 }
 
 type SystemEndpoints {
@@ -2426,6 +2453,8 @@ func (ec *executionContext) fieldContext_EverouteClusterWhitelist_egress(ctx con
 				return ec.fieldContext_NetworkPolicyRule_selector(ctx, field)
 			case "security_group":
 				return ec.fieldContext_NetworkPolicyRule_security_group(ctx, field)
+			case "policy_mode":
+				return ec.fieldContext_NetworkPolicyRule_policy_mode(ctx, field)
 			}
 			return nil, fmt.Errorf("no field named %q was found under type NetworkPolicyRule", field.Name)
 		},
@@ -2529,6 +2558,8 @@ func (ec *executionContext) fieldContext_EverouteClusterWhitelist_ingress(ctx co
 				return ec.fieldContext_NetworkPolicyRule_selector(ctx, field)
 			case "security_group":
 				return ec.fieldContext_NetworkPolicyRule_security_group(ctx, field)
+			case "policy_mode":
+				return ec.fieldContext_NetworkPolicyRule_policy_mode(ctx, field)
 			}
 			return nil, fmt.Errorf("no field named %q was found under type NetworkPolicyRule", field.Name)
 		},
@@ -3315,6 +3346,8 @@ func (ec *executionContext) fieldContext_IsolationPolicy_ingress(ctx context.Con
 				return ec.fieldContext_NetworkPolicyRule_selector(ctx, field)
 			case "security_group":
 				return ec.fieldContext_NetworkPolicyRule_security_group(ctx, field)
+			case "policy_mode":
+				return ec.fieldContext_NetworkPolicyRule_policy_mode(ctx, field)
 			}
 			return nil, fmt.Errorf("no field named %q was found under type NetworkPolicyRule", field.Name)
 		},
@@ -3374,6 +3407,8 @@ func (ec *executionContext) fieldContext_IsolationPolicy_egress(ctx context.Cont
 				return ec.fieldContext_NetworkPolicyRule_selector(ctx, field)
 			case "security_group":
 				return ec.fieldContext_NetworkPolicyRule_security_group(ctx, field)
+			case "policy_mode":
+				return ec.fieldContext_NetworkPolicyRule_policy_mode(ctx, field)
 			}
 			return nil, fmt.Errorf("no field named %q was found under type NetworkPolicyRule", field.Name)
 		},
@@ -4402,6 +4437,47 @@ func (ec *executionContext) fieldContext_NetworkPolicyRule_security_group(ctx co
 	return fc, nil
 }
 
+func (ec *executionContext) _NetworkPolicyRule_policy_mode(ctx context.Context, field graphql.CollectedField, obj *schema.NetworkPolicyRule) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_NetworkPolicyRule_policy_mode(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.PolicyMode, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(schema.PolicyMode)
+	fc.Result = res
+	return ec.marshalOPolicyMode2githubᚗcomᚋeverouteᚋeverouteᚋpluginᚋtowerᚋpkgᚋschemaᚐPolicyMode(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_NetworkPolicyRule_policy_mode(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "NetworkPolicyRule",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type PolicyMode does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
 func (ec *executionContext) _NetworkPolicyRulePort_port(ctx context.Context, field graphql.CollectedField, obj *schema.NetworkPolicyRulePort) (ret graphql.Marshaler) {
 	fc, err := ec.fieldContext_NetworkPolicyRulePort_port(ctx, field)
 	if err != nil {
@@ -5053,6 +5129,8 @@ func (ec *executionContext) fieldContext_Query_securityPolicies(ctx context.Cont
 				return ec.fieldContext_SecurityPolicy_is_blocklist(ctx, field)
 			case "enable_logging":
 				return ec.fieldContext_SecurityPolicy_enable_logging(ctx, field)
+			case "direction":
+				return ec.fieldContext_SecurityPolicy_direction(ctx, field)
 			}
 			return nil, fmt.Errorf("no field named %q was found under type SecurityPolicy", field.Name)
 		},
@@ -6155,6 +6233,8 @@ func (ec *executionContext) fieldContext_SecurityPolicy_ingress(ctx context.Cont
 				return ec.fieldContext_NetworkPolicyRule_selector(ctx, field)
 			case "security_group":
 				return ec.fieldContext_NetworkPolicyRule_security_group(ctx, field)
+			case "policy_mode":
+				return ec.fieldContext_NetworkPolicyRule_policy_mode(ctx, field)
 			}
 			return nil, fmt.Errorf("no field named %q was found under type NetworkPolicyRule", field.Name)
 		},
@@ -6214,6 +6294,8 @@ func (ec *executionContext) fieldContext_SecurityPolicy_egress(ctx context.Conte
 				return ec.fieldContext_NetworkPolicyRule_selector(ctx, field)
 			case "security_group":
 				return ec.fieldContext_NetworkPolicyRule_security_group(ctx, field)
+			case "policy_mode":
+				return ec.fieldContext_NetworkPolicyRule_policy_mode(ctx, field)
 			}
 			return nil, fmt.Errorf("no field named %q was found under type NetworkPolicyRule", field.Name)
 		},
@@ -6344,6 +6426,47 @@ func (ec *executionContext) fieldContext_SecurityPolicy_enable_logging(ctx conte
 	return fc, nil
 }
 
+func (ec *executionContext) _SecurityPolicy_direction(ctx context.Context, field graphql.CollectedField, obj *schema.SecurityPolicy) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_SecurityPolicy_direction(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Direction, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(schema.PolicyDirection)
+	fc.Result = res
+	return ec.marshalOPolicyDirection2githubᚗcomᚋeverouteᚋeverouteᚋpluginᚋtowerᚋpkgᚋschemaᚐPolicyDirection(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_SecurityPolicy_direction(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "SecurityPolicy",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type PolicyDirection does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
 func (ec *executionContext) _SecurityPolicyApply_type(ctx context.Context, field graphql.CollectedField, obj *schema.SecurityPolicyApply) (ret graphql.Marshaler) {
 	fc, err := ec.fieldContext_SecurityPolicyApply_type(ctx, field)
 	if err != nil {
@@ -6626,6 +6749,8 @@ func (ec *executionContext) fieldContext_SecurityPolicyEvent_node(ctx context.Co
 				return ec.fieldContext_SecurityPolicy_is_blocklist(ctx, field)
 			case "enable_logging":
 				return ec.fieldContext_SecurityPolicy_enable_logging(ctx, field)
+			case "direction":
+				return ec.fieldContext_SecurityPolicy_direction(ctx, field)
 			}
 			return nil, fmt.Errorf("no field named %q was found under type SecurityPolicy", field.Name)
 		},
@@ -11580,6 +11705,10 @@ func (ec *executionContext) _NetworkPolicyRule(ctx context.Context, sel ast.Sele
 
 			out.Values[i] = ec._NetworkPolicyRule_security_group(ctx, field, obj)
 
+		case "policy_mode":
+
+			out.Values[i] = ec._NetworkPolicyRule_policy_mode(ctx, field, obj)
+
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -12152,6 +12281,10 @@ func (ec *executionContext) _SecurityPolicy(ctx context.Context, sel ast.Selecti
 
 			out.Values[i] = ec._SecurityPolicy_enable_logging(ctx, field, obj)
 
+		case "direction":
+
+			out.Values[i] = ec._SecurityPolicy_direction(ctx, field, obj)
+
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -14728,6 +14861,17 @@ func (ec *executionContext) marshalOObjectReference2ᚖgithubᚗcomᚋeveroute
 	return ec._ObjectReference(ctx, sel, v)
 }
 
+func (ec *executionContext) unmarshalOPolicyDirection2githubᚗcomᚋeverouteᚋeverouteᚋpluginᚋtowerᚋpkgᚋschemaᚐPolicyDirection(ctx context.Context, v interface{}) (schema.PolicyDirection, error) {
+	tmp, err := graphql.UnmarshalString(v)
+	res := schema.PolicyDirection(tmp)
+	return res, graphql.ErrorOnPath(ctx, err)
+}
+
+func (ec *executionContext) marshalOPolicyDirection2githubᚗcomᚋeverouteᚋeverouteᚋpluginᚋtowerᚋpkgᚋschemaᚐPolicyDirection(ctx context.Context, sel ast.SelectionSet, v schema.PolicyDirection) graphql.Marshaler {
+	res := graphql.MarshalString(string(v))
+	return res
+}
+
 func (ec *executionContext) unmarshalOPolicyMode2githubᚗcomᚋeverouteᚋeverouteᚋpluginᚋtowerᚋpkgᚋschemaᚐPolicyMode(ctx context.Context, v interface{}) (schema.PolicyMode, error) {
 	tmp, err := graphql.UnmarshalString(v)
 	res := schema.PolicyMode(tmp)
@@ -19,6 +19,7 @@ package cases
 import (
 	"context"
 	"fmt"
+	"net"
 	"regexp"
 	"strconv"
 	"strings"
@@ -37,6 +38,7 @@ import (
 	"github.com/everoute/everoute/pkg/labels"
 	"github.com/everoute/everoute/tests/e2e/framework"
 	"github.com/everoute/everoute/tests/e2e/framework/config"
+	"github.com/everoute/everoute/tests/e2e/framework/endpoint"
 	"github.com/everoute/everoute/tests/e2e/framework/ipam"
 	"github.com/everoute/everoute/tests/e2e/framework/matcher"
 	"github.com/everoute/everoute/tests/e2e/framework/model"
@@ -478,6 +480,32 @@ var _ = Describe("SecurityPolicy", func() {
 				expectedTruthTable.SetAllFrom(ep01.Name, false)
 				expectedTruthTable.SetAllTo(ep01.Name, false)
 				assertMatchReachTable("TCP", tcpPort, expectedTruthTable)
+
+				By("verify arp is not governed by isolation policy")
+				assertMatchReachTable("ARP", 0, withoutSelfARP(securityModel.NewEmptyTruthTable(true), securityModel.Endpoints))
+			})
+
+			When("isolation policy block arp", func() {
+				BeforeEach(func() {
+					isolationPolicy.Spec.BlockARP = true
+					Expect(e2eEnv.UpdateObjects(ctx, isolationPolicy)).Should(Succeed())
+				})
+
+				It("Isolated endpoint should not allow to resolve or be resolved by all of endpoint", func() {
+					securityModel := &SecurityModel{
+						Policies:  []*securityv1alpha1.SecurityPolicy{isolationPolicy},
+						Endpoints: []*model.Endpoint{ep01, ep02, ep03, ep04},
+					}
+
+					By("verify reachable between endpoints")
+					expectedTruthTable := securityModel.NewEmptyTruthTable(true)
+					expectedTruthTable.SetAllFrom(ep01.Name, false)
+					expectedTruthTable.SetAllTo(ep01.Name, false)
+					assertMatchReachTable("TCP", tcpPort, expectedTruthTable)
+
+					By("verify arp between endpoints")
+					assertMatchReachTable("ARP", 0, withoutSelfARP(expectedTruthTable, securityModel.Endpoints))
+				})
 			})
 		})
 
@@ -687,6 +715,72 @@ var _ = Describe("SecurityPolicy", func() {
 		})
 	})
 
+	// This case is the same as the udp and ipblocks case, but the endpoints are assigned IPv6 addresses.
+	//
+	//  |-------------------|         |--------------- |    |---------------- |         |-------------------|
+	//  | "fd00:10::/124"   |  <--->  | ntp-production |    | ntp-development |  <--->  | "fd00:10::10/124" |
+	//  | ------------------|         |--------------- |    |---------------- |         |-------------------|
+	//
+	Context("environment with IPv6 endpoints provide internal udp service [Feature:UDP] [Feature:IPBlocks] [Feature:IPv6]", func() {
+		var ntp01, ntp02, client01, client02 *model.Endpoint
+		var ntpProductionSelector, ntpDevelopmentSelector *labels.Selector
+
+		var ntpPort int
+		var productionCidr, developmentCidr string
+
+		BeforeEach(func() {
+			if e2eEnv.IPv6Range() == "" {
+				Skip("no IPv6 range in ipam config, skip it")
+			}
+
+			ntpPort = 123
+			productionCidr = "fd00:10::/124"
+			developmentCidr = "fd00:10::10/124"
+
+			client01 = &model.Endpoint{Name: "ntp-client01", ExpectSubnet: productionCidr}
+			client02 = &model.Endpoint{Name: "ntp-client02", ExpectSubnet: developmentCidr}
+			ntp01 = &model.Endpoint{Name: "ntp01-server", ExpectSubnet: productionCidr, UDPPort: ntpPort, Labels: map[string][]string{"component": {"ntp"}, "env": {"production"}}}
+			ntp02 = &model.Endpoint{Name: "ntp02-server", ExpectSubnet: developmentCidr, UDPPort: ntpPort, Labels: map[string][]string{"component": {"ntp"}, "env": {"development"}}}
+
+			ntpProductionSelector = newSelector(map[string][]string{"component": {"ntp"}, "env": {"production"}})
+			ntpDevelopmentSelector = newSelector(map[string][]string{"component": {"ntp"}, "env": {"development"}})
+
+			Expect(e2eEnv.EndpointManager().SetupMany(ctx, ntp01, ntp02, client01, client02)).Should(Succeed())
+		})
+
+		When("limits udp packets by IPv6 ipBlocks between server and client", func() {
+			BeforeEach(func() {
+				ntpProductionPolicy := newPolicy("ntp-production-policy", constants.Tier2, securityv1alpha1.DefaultRuleDrop, ntpProductionSelector)
+				addIngressRule(ntpProductionPolicy, "UDP", ntpPort, &networkingv1.IPBlock{CIDR: productionCidr})
+
+				ntpDevelopmentPolicy := newPolicy("ntp-development-policy", constants.Tier2, securityv1alpha1.DefaultRuleDrop, ntpDevelopmentSelector)
+				addIngressRule(ntpDevelopmentPolicy, "UDP", ntpPort, &networkingv1.IPBlock{CIDR: developmentCidr})
+
+				Expect(e2eEnv.SetupObjects(ctx, ntpProductionPolicy, ntpDevelopmentPolicy)).Should(Succeed())
+			})
+
+			// the expected flows of SecurityModel are IPv4 only, verify the policy by reachable
+			It("should allow normal packets and limits illegal packets", func() {
+				By("verify policy limits illegal packets")
+				assertReachable([]*model.Endpoint{client01}, []*model.Endpoint{ntp02}, "UDP", false)
+				assertReachable([]*model.Endpoint{client02}, []*model.Endpoint{ntp01}, "UDP", false)
+
+				By("verify reachable between servers")
+				assertReachable([]*model.Endpoint{ntp01}, []*model.Endpoint{ntp02}, "UDP", false)
+				assertReachable([]*model.Endpoint{ntp02}, []*model.Endpoint{ntp01}, "UDP", false)
+
+				By("verify reachable between server and client")
+				assertReachable([]*model.Endpoint{client01}, []*model.Endpoint{ntp01}, "UDP", true)
+				assertReachable([]*model.Endpoint{client02}, []*model.Endpoint{ntp02}, "UDP", true)
+			})
+
+			It("should keep the connection healthy between clients", func() {
+				// no policy applies to clients, ping6 between them always succeeds
+				Expect(<-checkConnectionHealth(client01, client02)).Should(Equal(HEALTHY))
+			})
+		})
+	})
+
 	Context("Complicated securityPolicy definition that contains semanticly conflict policyrules", func() {
 		var group1Endpoint1, group2Endpoint01, group3Endpoint01 *model.Endpoint
 		var group1, group2, group3 *labels.Selector
@@ -889,6 +983,48 @@ var _ = Describe("SecurityPolicy", func() {
 		})
 	})
 
+	Context("endpoint with gre tunnel [Feature:GRE]", func() {
+		var greEp1, greEp2 *model.Endpoint
+		var ep1InternalIP, ep2InternalIP string
+		var greSelector *labels.Selector
+		var tcpPort = 7878
+
+		BeforeEach(func() {
+			if e2eEnv.EndpointManager().Name() == "tower" {
+				Skip("tower e2e has no gre feature, skip it")
+			}
+			greEp1 = &model.Endpoint{Name: "gre-1", TCPPort: tcpPort, Labels: map[string][]string{"component": {"gre"}}}
+			greEp2 = &model.Endpoint{Name: "gre-2", Labels: map[string][]string{"component": {"client"}}}
+			greSelector = newSelector(map[string][]string{"component": {"gre"}})
+			Expect(e2eEnv.EndpointManager().SetupMany(ctx, greEp1, greEp2)).Should(Succeed())
+
+			ipPool, _ := ipam.NewPool(&config.IPAMConfig{IPRange: "15.20.0.1/24"})
+			ep1InternalIP, _ = ipPool.Assign()
+			ep2InternalIP, _ = ipPool.Assign()
+			Expect(e2eEnv.EndpointManager().SetupGRE(ctx, greEp1.Name, greEp2.Status.IPAddr, greEp1.Status.IPAddr, ep1InternalIP)).Should(Succeed())
+			Expect(e2eEnv.EndpointManager().SetupGRE(ctx, greEp2.Name, greEp1.Status.IPAddr, greEp2.Status.IPAddr, ep2InternalIP)).Should(Succeed())
+
+			assertReachable([]*model.Endpoint{greEp2}, []*model.Endpoint{greEp1}, "ICMP", true, ep1InternalIP)
+			assertReachable([]*model.Endpoint{greEp2}, []*model.Endpoint{greEp1}, "TCP", true)
+		})
+
+		It("limit GRE packets", func() {
+			policy := newPolicy("test-gre", constants.Tier2, securityv1alpha1.DefaultRuleDrop, greSelector)
+			addIngressRule(policy, "TCP", tcpPort)
+			Expect(e2eEnv.SetupObjects(ctx, policy)).Should(Succeed())
+			assertReachable([]*model.Endpoint{greEp2}, []*model.Endpoint{greEp1}, "ICMP", false, ep1InternalIP)
+			assertReachable([]*model.Endpoint{greEp2}, []*model.Endpoint{greEp1}, "TCP", true)
+		})
+
+		It("allow GRE packets", func() {
+			policy := newPolicy("test-gre", constants.Tier2, securityv1alpha1.DefaultRuleDrop, greSelector)
+			addIngressRule(policy, "GRE", 0)
+			Expect(e2eEnv.SetupObjects(ctx, policy)).Should(Succeed())
+			assertReachable([]*model.Endpoint{greEp2}, []*model.Endpoint{greEp1}, "ICMP", true, ep1InternalIP)
+			assertReachable([]*model.Endpoint{greEp2}, []*model.Endpoint{greEp1}, "TCP", false)
+		})
+	})
+
 	Context("ecp networkPolicy [Feature:TierECP]", func() {
 		var nginx, server, db *model.Endpoint
 		var nginxSelector, serverSelector, dbSelector *labels.Selector
@@ -1107,6 +1243,38 @@ var _ = Describe("GlobalPolicy", func() {
 				})
 			})
 		})
+
+		When("update global default action to reject", func() {
+			BeforeEach(func() {
+				if e2eEnv.GlobalPolicyProvider().Name() == "tower" {
+					Skip("skip verify global reject, tower doesn't support default action reject")
+				}
+				Expect(e2eEnv.GlobalPolicyProvider().SetDefaultAction(ctx, securityv1alpha1.GlobalDefaultActionReject)).Should(Succeed())
+			})
+
+			It("should reset tcp connection promptly rather than timeout", func() {
+				dstIP := strings.Split(endpointB.Status.IPAddr, "/")[0]
+				args := []string{"connect", "--protocol", "TCP", "--timeout", "10s", "--server", fmt.Sprintf("%s:%d", dstIP, tcpPort)}
+
+				Eventually(func() error {
+					start := time.Now()
+					rc, out, err := e2eEnv.EndpointManager().RunCommand(ctx, endpointA.Name, "net-utils", args...)
+					if err != nil {
+						return err
+					}
+					if rc == 0 {
+						return fmt.Errorf("connect to %s:%d should be rejected", dstIP, tcpPort)
+					}
+					if !strings.Contains(string(out), "connection refused") {
+						return fmt.Errorf("connect to %s:%d should be reset, output: %s", dstIP, tcpPort, string(out))
+					}
+					if elapsed := time.Since(start); elapsed > 5*time.Second {
+						return fmt.Errorf("connect to %s:%d reset after %s, want reset promptly", dstIP, tcpPort, elapsed)
+					}
+					return nil
+				}, e2eEnv.Timeout(), e2eEnv.Interval()).Should(Succeed())
+			})
+		})
 	})
 
 	Context("environment with global white list policy [Feature:GlobalWhitelistPolicy]", func() {
@@ -1315,6 +1483,7 @@ func assertReachable(sources []*model.Endpoint, destinations []*model.Endpoint,
 		var errList []error
 
 		for _, src := range sources {
+			var targets []endpoint.ReachTarget
 			for _, dst := range destinations {
 				if src.Name == dst.Name {
 					continue
@@ -1326,9 +1495,16 @@ func assertReachable(sources []*model.Endpoint, destinations []*model.Endpoint,
 				if protocol == "UDP" {
 					port = dst.UDPPort
 				}
-				reach, err := e2eEnv.EndpointManager().Reachable(ctx, src.Name, dst.Name, protocol, port, extraArgs...)
-				Expect(err).Should(Succeed())
+				targets = append(targets, endpoint.ReachTarget{Name: dst.Name, Port: port})
+			}
+			reachable, err := e2eEnv.EndpointManager().ReachableMany(ctx, src.Name, targets, protocol, extraArgs...)
+			Expect(err).Should(Succeed())
 
+			for _, dst := range destinations {
+				if src.Name == dst.Name {
+					continue
+				}
+				reach := reachable[dst.Name]
 				if reach == expectReach {
 					continue
 				}
@@ -1352,6 +1528,14 @@ func assertMatchReachTable(protocol string, port int, expectedTruthTable *model.
 	}, e2eEnv.Timeout(), e2eEnv.Interval()).Should(matcher.MatchTruthTable(expectedTruthTable, true))
 }
 
+// withoutSelfARP unsets reachable of the endpoints to themselves, for no one replies arp of its own address
+func withoutSelfARP(truthTable *model.TruthTable, endpoints []*model.Endpoint) *model.TruthTable {
+	for _, ep := range endpoints {
+		truthTable.Set(ep.Name, ep.Name, false)
+	}
+	return truthTable
+}
+
 type ConnHealth string
 
 const (
@@ -1363,12 +1547,29 @@ const (
 
 const CheckConnectionHealthTime int = 20 // 20s
 
+// pingLossRegexp matches the packet loss in ping statistics of both IPv4 and IPv6, e.g.
+// 20 packets transmitted, 20 received, 0% packet loss, time 19027ms
+// 20 packets transmitted, 19 packets received, 5% packet loss
+var pingLossRegexp = regexp.MustCompile(`([0-9.]+)% packet loss`)
+
+// parsePingLossRate returns the packet loss rate in percentage from the ping output
+func parsePingLossRate(output string) (float64, error) {
+	match := pingLossRegexp.FindStringSubmatch(output)
+	if match == nil {
+		return 0, fmt.Errorf("packet loss not found in ping output: %s", output)
+	}
+	return strconv.ParseFloat(match[1], 64)
+}
+
 func checkConnectionHealth(src, dst *model.Endpoint) <-chan ConnHealth {
 	resultChan := make(chan ConnHealth)
 	klog.Info("Check connection health from endpoint ", src.Name, " to endpoint ", dst.Name, ".")
 	go func(src, dst *model.Endpoint, resultChan chan ConnHealth) {
 		var command string = "ping"
-		var args []string = []string{"-W", "1", "-c", strconv.Itoa(CheckConnectionHealthTime / 1), "-q", strings.Split(dst.Status.IPAddr, "/")[0]}
+		var args []string = []string{"-W", "1", "-c", strconv.Itoa(CheckConnectionHealthTime / 1), "-q", dst.Status.GetIP()}
+		if net.ParseIP(dst.Status.GetIP()).To4() == nil {
+			args = append([]string{"-6"}, args...)
+		}
 		rc, b, err := e2eEnv.EndpointManager().RunCommand(ctx, src.Name, command, args...)
 		fullOut := string(b)
 		if err != nil {
@@ -1376,16 +1577,8 @@ func checkConnectionHealth(src, dst *model.Endpoint) <-chan ConnHealth {
 			resultChan <- UNKNOWN
 			return
 		}
-		// Expect 4 lines at least:
-		// PING [dstIP] ([dstIP]): 56 data bytes
-		//
-		// --- [dstIP] ping statistics ---
-		// [time] packets transmitted, [n1] packets received, [n2]% packet loss
-		output := regexp.MustCompile("\r\n|\n|\r").Split(fullOut, -1)
-		infoOutput := output[3] // expect: [n0] packets transmitted, [n1] packets received, [n2]% packet loss
-		connInfo := regexp.MustCompile("[0-9]+").FindAllString(infoOutput, -1)
-		klog.Info("Total,Received,Loss Rate,Time:", connInfo)
-		lossRate, err := strconv.Atoi(connInfo[2])
+		lossRate, err := parsePingLossRate(fullOut)
+		klog.Info("Loss Rate:", lossRate, ", ping output:", fullOut)
 		if err != nil {
 			resultChan <- UNKNOWN
 		} else if lossRate == 0 {
@@ -93,6 +93,9 @@ type GlobalPolicyConfig struct {
 
 type IPAMConfig struct {
 	IPRange string `yaml:"ip-range"`
+	// IPv6Range assigns IPv6 addresses to endpoints expect IPv6 subnet, IPv6 disabled if empty. It is empty
+	// by default, IPv6 cases are skipped unless the environment enables IPv6 and sets it, e.g. "fd00:10::/120"
+	IPv6Range string `yaml:"ipv6-range,omitempty"`
 }
 
 func LoadDefault(kubeConfig string) (*Config, error) {
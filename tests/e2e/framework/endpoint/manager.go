@@ -48,8 +48,41 @@ const (
 		ip a add $ip dev ${tunName}
 		ip link set ${tunName} up
 	`
+	SetupGRE = `
+		tunName=${1}
+		remoteIP=${2%/*}
+		localIP=${3%/*}
+		ip=${4}
+
+		ip tunnel add ${tunName} mode gre remote ${remoteIP} local ${localIP}
+		ip a add $ip dev ${tunName}
+		ip link set ${tunName} up
+	`
+	// BatchReachable runs the net-utils probes concurrently, each probe is the net-utils
+	// arguments joined by comma. Prints the index and return code of each probe per line.
+	BatchReachable = `
+		tmpdir=$(mktemp -d)
+		trap 'rm -rf ${tmpdir}' EXIT
+
+		index=0
+		for probe in "$@"; do
+			(net-utils ${probe//,/ } >/dev/null 2>&1; echo $? >${tmpdir}/${index}) &
+			index=$((index+1))
+		done
+		wait
+
+		for ((i=0; i<index; i++)); do
+			echo "${i} $(cat ${tmpdir}/${i})"
+		done
+	`
 )
 
+// ReachTarget is the destination endpoint and port of a reachable probe
+type ReachTarget struct {
+	Name string
+	Port int
+}
+
 type Manager struct {
 	model.EndpointProvider
 }
@@ -130,37 +163,50 @@ func (m *Manager) ResetResource(ctx context.Context) error {
 
 func (m *Manager) Reachable(ctx context.Context, src string, dst string, protocol string, port int, exaArgs ...string) (bool, error) {
 	var cmd = `net-utils`
-	var args []string
 
-	dstEp, err := m.Get(ctx, dst)
+	args, err := m.connectArgs(ctx, dst, protocol, port, exaArgs...)
 	if err != nil {
-		return false, fmt.Errorf("unable get dest endpoint: %s", err)
+		return false, err
 	}
 
-	dstIPCIDR := dstEp.Status.IPAddr
-	if protocol == "ICMP" && len(exaArgs) > 0 {
-		dstIPCIDR = exaArgs[0]
+	rc, out, err := m.RunCommand(ctx, src, cmd, args...)
+	klog.Infof("connect from %s to %s, command: net-utils %s, result: %s", src, dst, strings.Join(args, " "), string(out))
+
+	return rc == 0, err
+}
+
+// ReachableMany probes all targets from src in a single script, instead of a command per target.
+// Returns whether each target is reachable, keyed by the target name.
+func (m *Manager) ReachableMany(ctx context.Context, src string, targets []ReachTarget, protocol string, exaArgs ...string) (map[string]bool, error) {
+	var probes = make([]string, 0, len(targets))
+
+	for _, target := range targets {
+		args, err := m.connectArgs(ctx, target.Name, protocol, target.Port, exaArgs...)
+		if err != nil {
+			return nil, err
+		}
+		probes = append(probes, strings.Join(args, ","))
 	}
-	ip, _, err := net.ParseCIDR(dstIPCIDR)
-	if err != nil {
-		return false, fmt.Errorf("unexpect ipaddr %s of %s", dstEp.Status.IPAddr, dstEp.Name)
+	if len(probes) == 0 {
+		return map[string]bool{}, nil
 	}
 
-	switch strings.ToUpper(protocol) {
-	case "TCP", "UDP":
-		args = []string{`connect`, `--protocol`, protocol, `--timeout`, "1s", `--server`, fmt.Sprintf("%s:%d", ip.String(), port)}
-	case "ICMP":
-		args = []string{`connect`, `--protocol`, protocol, `--timeout`, "1s", `--server`, ip.String()}
-	case "FTP":
-		args = []string{`connect`, `--protocol`, protocol, `--server`, ip.String()}
-	default:
-		return false, fmt.Errorf("unknow protocol %s", protocol)
+	_, out, err := m.RunScript(ctx, src, []byte(BatchReachable), probes...)
+	if err != nil {
+		return nil, err
+	}
+	rcList, err := parseBatchReachable(out, len(probes))
+	if err != nil {
+		return nil, fmt.Errorf("connect from %s: %s", src, err)
 	}
 
-	rc, out, err := m.RunCommand(ctx, src, cmd, args...)
-	klog.Infof("connect from %s to %s, command: net-utils %s, result: %s", src, dst, strings.Join(args, " "), string(out))
-
-	return rc == 0, err
+	reachable := make(map[string]bool, len(targets))
+	for index, target := range targets {
+		klog.Infof("connect from %s to %s, command: net-utils %s, return code: %d",
+			src, target.Name, strings.ReplaceAll(probes[index], ",", " "), rcList[index])
+		reachable[target.Name] = rcList[index] == 0
+	}
+	return reachable, nil
 }
 
 func (m *Manager) ReachTruthTable(ctx context.Context, protocol string, port int) (*model.TruthTable, error) {
@@ -177,15 +223,20 @@ func (m *Manager) ReachTruthTable(ctx context.Context, protocol string, port int
 	}
 	tt := model.NewTruthTableFromItems(endpointNames, nil)
 
+	targets := make([]ReachTarget, 0, len(endpoints))
+	for _, ep := range endpoints {
+		targets = append(targets, ReachTarget{Name: ep.Name, Port: port})
+	}
+
 	err = m.concurrentVisit(func(srcEp *model.Endpoint) error {
-		return m.concurrentVisit(func(dstEp *model.Endpoint) error {
-			limitChan <- struct{}{}
-			defer func() { <-limitChan }()
-
-			reach, err := m.Reachable(ctx, srcEp.Name, dstEp.Name, protocol, port)
-			tt.Set(srcEp.Name, dstEp.Name, err == nil && reach)
-			return err
-		}, endpoints)
+		limitChan <- struct{}{}
+		defer func() { <-limitChan }()
+
+		reachable, err := m.ReachableMany(ctx, srcEp.Name, targets, protocol)
+		for _, target := range targets {
+			tt.Set(srcEp.Name, target.Name, err == nil && reachable[target.Name])
+		}
+		return err
 	}, endpoints)
 
 	return tt, err
@@ -201,6 +252,72 @@ func (m *Manager) SetupIPIP(ctx context.Context, object, remoteTunIP, localTunIP
 	return err
 }
 
+func (m *Manager) SetupGRE(ctx context.Context, object, remoteTunIP, localTunIP, localIP string) error {
+	tunName := "gre" + strconv.Itoa(rand.Intn(100))
+	_, out, err := m.RunScript(ctx, object, []byte(SetupGRE), tunName, remoteTunIP, localTunIP, localIP)
+	if err != nil {
+		klog.Errorf("%s setup gre tunnel, out: %s, err: %s", object, out, err)
+	}
+
+	return err
+}
+
+func (m *Manager) connectArgs(ctx context.Context, dst string, protocol string, port int, exaArgs ...string) ([]string, error) {
+	dstEp, err := m.Get(ctx, dst)
+	if err != nil {
+		return nil, fmt.Errorf("unable get dest endpoint: %s", err)
+	}
+
+	dstIPCIDR := dstEp.Status.IPAddr
+	if protocol == "ICMP" && len(exaArgs) > 0 {
+		dstIPCIDR = exaArgs[0]
+	}
+	ip, _, err := net.ParseCIDR(dstIPCIDR)
+	if err != nil {
+		return nil, fmt.Errorf("unexpect ipaddr %s of %s", dstEp.Status.IPAddr, dstEp.Name)
+	}
+
+	switch strings.ToUpper(protocol) {
+	case "TCP", "UDP":
+		return []string{`connect`, `--protocol`, protocol, `--timeout`, "1s", `--server`, net.JoinHostPort(ip.String(), strconv.Itoa(port))}, nil
+	case "ICMP", "ARP":
+		return []string{`connect`, `--protocol`, protocol, `--timeout`, "1s", `--server`, ip.String()}, nil
+	case "FTP":
+		return []string{`connect`, `--protocol`, protocol, `--server`, ip.String()}, nil
+	default:
+		return nil, fmt.Errorf("unknow protocol %s", protocol)
+	}
+}
+
+// parseBatchReachable parses the output of BatchReachable, returns the return code of each probe
+func parseBatchReachable(out []byte, probeNum int) ([]int, error) {
+	var rcList = make([]int, probeNum)
+	var parsed = make([]bool, probeNum)
+
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		index, err := strconv.Atoi(fields[0])
+		if err != nil || index < 0 || index >= probeNum {
+			continue
+		}
+		rc, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("unexpect return code %q of probe %d", fields[1], index)
+		}
+		rcList[index], parsed[index] = rc, true
+	}
+
+	for index := range parsed {
+		if !parsed[index] {
+			return nil, fmt.Errorf("missing result of probe %d in output: %s", index, string(out))
+		}
+	}
+	return rcList, nil
+}
+
 func (m *Manager) concurrentVisit(visitor func(*model.Endpoint) error, endpoints []*model.Endpoint) error {
 	var errList = make([]error, len(endpoints))
 	var wg = sync.WaitGroup{}
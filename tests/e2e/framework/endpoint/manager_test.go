@@ -0,0 +1,216 @@
+/*
+Copyright 2021 The Everoute Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/everoute/everoute/tests/e2e/framework/model"
+)
+
+// fakeNetUtils is reachable only if "<source> <server>" is listed in the file $REACH_FILE
+const fakeNetUtils = `#!/bin/bash
+while [ $# -gt 0 ]; do
+	case $1 in
+		--server) server=$2; shift;;
+	esac
+	shift
+done
+grep -qx "${E2E_SOURCE} ${server}" ${REACH_FILE}
+`
+
+// localProvider runs scripts and commands of the endpoints on localhost with a fake net-utils
+type localProvider struct {
+	endpoints map[string]*model.Endpoint
+	binDir    string
+	reachFile string
+}
+
+func newLocalProvider(t *testing.T, endpoints []*model.Endpoint, reachable map[string][]string) *localProvider {
+	dir := t.TempDir()
+	p := &localProvider{
+		endpoints: make(map[string]*model.Endpoint),
+		binDir:    dir,
+		reachFile: filepath.Join(dir, "reachable"),
+	}
+
+	var reachList []string
+	for _, ep := range endpoints {
+		p.endpoints[ep.Name] = ep
+	}
+	for src, dsts := range reachable {
+		for _, dst := range dsts {
+			reachList = append(reachList, fmt.Sprintf("%s %s:%d", src, p.endpoints[dst].Status.GetIP(), p.endpoints[dst].TCPPort))
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "net-utils"), []byte(fakeNetUtils), 0755); err != nil {
+		t.Fatalf("unable write fake net-utils: %s", err)
+	}
+	if err := os.WriteFile(p.reachFile, []byte(strings.Join(reachList, "\n")+"\n"), 0644); err != nil {
+		t.Fatalf("unable write reachable file: %s", err)
+	}
+	return p
+}
+
+func (p *localProvider) Name() string { return "local" }
+
+func (p *localProvider) Get(_ context.Context, name string) (*model.Endpoint, error) {
+	ep, ok := p.endpoints[name]
+	if !ok {
+		return nil, fmt.Errorf("endpoint %s not found", name)
+	}
+	return ep, nil
+}
+
+func (p *localProvider) List(_ context.Context) ([]*model.Endpoint, error) {
+	var epList []*model.Endpoint
+	for _, ep := range p.endpoints {
+		epList = append(epList, ep)
+	}
+	return epList, nil
+}
+
+func (p *localProvider) Create(context.Context, *model.Endpoint) (*model.Endpoint, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (p *localProvider) Update(context.Context, *model.Endpoint) (*model.Endpoint, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (p *localProvider) Delete(context.Context, string) error { return errors.New("not implemented") }
+
+func (p *localProvider) RenewIP(context.Context, string) (*model.Endpoint, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (p *localProvider) Migrate(context.Context, string) (*model.Endpoint, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (p *localProvider) RunScript(ctx context.Context, name string, script []byte, arg ...string) (int, []byte, error) {
+	cmd := exec.CommandContext(ctx, "bash", append([]string{"-s"}, arg...)...)
+	cmd.Stdin = bytes.NewBuffer(script)
+	return p.run(name, cmd)
+}
+
+func (p *localProvider) RunCommand(ctx context.Context, name string, command string, arg ...string) (int, []byte, error) {
+	return p.run(name, exec.CommandContext(ctx, "bash", append([]string{"-c", command + ` "$@"`, command}, arg...)...))
+}
+
+func (p *localProvider) run(name string, cmd *exec.Cmd) (int, []byte, error) {
+	cmd.Env = append(os.Environ(),
+		"PATH="+p.binDir+":"+os.Getenv("PATH"),
+		"E2E_SOURCE="+name,
+		"REACH_FILE="+p.reachFile,
+	)
+	out, err := cmd.CombinedOutput()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode(), out, nil
+	}
+	return 0, out, err
+}
+
+func TestReachTruthTable(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not found")
+	}
+
+	ctx := context.Background()
+	endpoints := []*model.Endpoint{
+		{Name: "ep01", TCPPort: 80, Status: &model.EndpointStatus{IPAddr: "10.0.0.1/24"}},
+		{Name: "ep02", TCPPort: 443, Status: &model.EndpointStatus{IPAddr: "10.0.0.2/24"}},
+		{Name: "ep03", TCPPort: 80, Status: &model.EndpointStatus{IPAddr: "10.0.0.3/24"}},
+		{Name: "ep04", TCPPort: 8080, Status: &model.EndpointStatus{IPAddr: "10.0.0.4/24"}},
+	}
+	reachable := map[string][]string{
+		"ep01": {"ep02", "ep03", "ep04"},
+		"ep02": {"ep01"},
+		"ep03": {"ep03", "ep04"},
+	}
+	m := &Manager{EndpointProvider: newLocalProvider(t, endpoints, reachable)}
+
+	t.Run("batched result should match per-pair result", func(t *testing.T) {
+		for _, src := range endpoints {
+			var targets []ReachTarget
+			for _, dst := range endpoints {
+				targets = append(targets, ReachTarget{Name: dst.Name, Port: dst.TCPPort})
+			}
+			batched, err := m.ReachableMany(ctx, src.Name, targets, "TCP")
+			if err != nil {
+				t.Fatalf("unexpect error: %s", err)
+			}
+			for _, dst := range endpoints {
+				reach, err := m.Reachable(ctx, src.Name, dst.Name, "TCP", dst.TCPPort)
+				if err != nil {
+					t.Fatalf("unexpect error: %s", err)
+				}
+				if batched[dst.Name] != reach {
+					t.Errorf("from %s to %s batched result %t, per-pair result %t", src.Name, dst.Name, batched[dst.Name], reach)
+				}
+			}
+		}
+	})
+
+	t.Run("truth table should match per-pair result", func(t *testing.T) {
+		tt, err := m.ReachTruthTable(ctx, "TCP", 80)
+		if err != nil {
+			t.Fatalf("unexpect error: %s", err)
+		}
+		for _, src := range endpoints {
+			for _, dst := range endpoints {
+				reach, err := m.Reachable(ctx, src.Name, dst.Name, "TCP", 80)
+				if err != nil {
+					t.Fatalf("unexpect error: %s", err)
+				}
+				// only ep01 and ep03 listen on port 80
+				expectReach := dst.TCPPort == 80 && sets.New(reachable[src.Name]...).Has(dst.Name)
+				if reach != expectReach {
+					t.Errorf("from %s to %s per-pair result %t, want %t", src.Name, dst.Name, reach, expectReach)
+				}
+				if tt.Get(src.Name, dst.Name) != reach {
+					t.Errorf("from %s to %s truth table %t, per-pair result %t", src.Name, dst.Name, tt.Get(src.Name, dst.Name), reach)
+				}
+			}
+		}
+	})
+}
+
+func TestParseBatchReachable(t *testing.T) {
+	rcList, err := parseBatchReachable([]byte("1 1\n0 0\nunexpected output\n2 2\n"), 3)
+	if err != nil {
+		t.Fatalf("unexpect error: %s", err)
+	}
+	if rcList[0] != 0 || rcList[1] != 1 || rcList[2] != 2 {
+		t.Fatalf("unexpect return codes %v", rcList)
+	}
+
+	if _, err = parseBatchReachable([]byte("0 0\n"), 2); err == nil {
+		t.Fatalf("should fail when missing result of probes")
+	}
+}
@@ -48,6 +48,8 @@ type Framework struct {
 	epManager            *endpoint.Manager
 	nodeManager          *node.Manager
 	globalPolicyProvider model.GlobalPolicyProvider
+	// ipv6Range assigns IPv6 addresses to endpoints, empty if IPv6 not enabled
+	ipv6Range string
 
 	timeout  time.Duration
 	interval time.Duration
@@ -82,6 +84,7 @@ func NewFromKube(kubeConfig string) (*Framework, error) {
 		epManager:            endpoint.NewManager(ipPool, cfg.Namespace, nodeManager, &cfg.Endpoint),
 		nodeManager:          nodeManager,
 		globalPolicyProvider: globalpolicy.NewProvider(&cfg.GlobalPolicy),
+		ipv6Range:            cfg.IPAM.IPv6Range,
 		timeout:              *cfg.Timeout,
 		interval:             *cfg.Interval,
 	}
@@ -101,6 +104,11 @@ func (f *Framework) GlobalPolicyProvider() model.GlobalPolicyProvider {
 	return f.globalPolicyProvider
 }
 
+// IPv6Range returns the IPv6 range endpoints could be assigned from, empty if IPv6 not enabled
+func (f *Framework) IPv6Range() string {
+	return f.ipv6Range
+}
+
 func (f *Framework) KubeClient() client.Client {
 	return f.kubeClient
 }
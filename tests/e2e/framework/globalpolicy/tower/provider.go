@@ -56,6 +56,8 @@ func (m *provider) SetDefaultAction(ctx context.Context, action securityv1alpha1
 		globalDefaultAction = "ALLOW"
 	case securityv1alpha1.GlobalDefaultActionDrop:
 		globalDefaultAction = "DROP"
+	default:
+		return fmt.Errorf("unsupported global default action %s", action)
 	}
 
 	var request = client.Request{
@@ -17,12 +17,12 @@ limitations under the License.
 package ipam
 
 import (
+	crand "crypto/rand"
 	"fmt"
+	"math/big"
 	"net"
 	"sync"
 
-	"k8s.io/apimachinery/pkg/util/rand"
-
 	"github.com/everoute/everoute/tests/e2e/framework/config"
 )
 
@@ -35,31 +35,56 @@ type Pool interface {
 
 // NewPool create an Pool instance
 func NewPool(config *config.IPAMConfig) (Pool, error) {
-	_, ipNet, err := net.ParseCIDR(config.IPRange)
-	if err != nil {
+	var p = &pool{ipUsed: make(map[string]bool)}
+	var err error
+
+	if p.cidr, err = p.addRange(config.IPRange); err != nil {
 		return nil, err
 	}
+	if p.cidr.IP.To4() == nil {
+		return nil, fmt.Errorf("ip range %s must be IPv4", config.IPRange)
+	}
 
-	// ignore the network and broadcast addresses
-	network, broadcast := cidrV4Range(ipNet)
-
-	begin := net.IPNet{IP: network, Mask: ipNet.Mask}
-	end := net.IPNet{IP: broadcast, Mask: ipNet.Mask}
+	if config.IPv6Range != "" {
+		if p.cidrV6, err = p.addRange(config.IPv6Range); err != nil {
+			return nil, err
+		}
+		if p.cidrV6.IP.To4() != nil {
+			return nil, fmt.Errorf("ipv6 range %s must be IPv6", config.IPv6Range)
+		}
+	}
 
-	return &pool{cidr: ipNet, ipUsed: map[string]bool{
-		begin.String(): true,
-		end.String():   true,
-	}}, nil
+	return p, nil
 }
 
 type pool struct {
 	lock sync.RWMutex
-	// assignable address range
+	// assignable IPv4 address range
 	cidr *net.IPNet
+	// assignable IPv6 address range, nil if IPv6 not enabled
+	cidrV6 *net.IPNet
 	// list of ips has been assigned
 	ipUsed map[string]bool
 }
 
+// addRange parses the ip range and marks its first and last addresses as used
+func (f *pool) addRange(ipRange string) (*net.IPNet, error) {
+	_, ipNet, err := net.ParseCIDR(ipRange)
+	if err != nil {
+		return nil, err
+	}
+
+	// ignore the network and broadcast addresses
+	network, broadcast := cidrRange(ipNet)
+
+	begin := net.IPNet{IP: network, Mask: ipNet.Mask}
+	end := net.IPNet{IP: broadcast, Mask: ipNet.Mask}
+	f.ipUsed[begin.String()] = true
+	f.ipUsed[end.String()] = true
+
+	return ipNet, nil
+}
+
 func (f *pool) Assign() (string, error) {
 	return f.AssignFromSubnet("")
 }
@@ -75,7 +100,7 @@ func (f *pool) AssignFromSubnet(subnet string) (string, error) {
 		}
 	}
 
-	return f.randomIPv4(cidr)
+	return f.randomIP(cidr)
 }
 
 func (f *pool) Release(ipnet string) error {
@@ -85,18 +110,26 @@ func (f *pool) Release(ipnet string) error {
 	return nil
 }
 
-func (f *pool) randomIPv4(subnet *net.IPNet) (string, error) {
+func (f *pool) randomIP(subnet *net.IPNet) (string, error) {
 	f.lock.Lock()
 	defer f.lock.Unlock()
 
-	if !containsSubnet(f.cidr, subnet) {
-		return "", fmt.Errorf("subnet %s not in ip pool %s", subnet, f.cidr)
+	var poolCidr = f.cidr
+	if subnet.IP.To4() == nil {
+		if f.cidrV6 == nil {
+			return "", fmt.Errorf("subnet %s is IPv6, but no IPv6 range in ip pool", subnet)
+		}
+		poolCidr = f.cidrV6
+	}
+
+	if !containsSubnet(poolCidr, subnet) {
+		return "", fmt.Errorf("subnet %s not in ip pool %s", subnet, poolCidr)
 	}
 
 	// todo: replace retry with check has available IP first
 	for i := 0; i < 10; i++ {
-		ipv4 := randomIPv4FromSubnet(subnet)
-		ipNet := (&net.IPNet{IP: ipv4, Mask: f.cidr.Mask}).String()
+		ip := randomIPFromSubnet(subnet)
+		ipNet := (&net.IPNet{IP: ip, Mask: poolCidr.Mask}).String()
 
 		if !f.ipUsed[ipNet] {
 			f.ipUsed[ipNet] = true
@@ -107,33 +140,42 @@ func (f *pool) randomIPv4(subnet *net.IPNet) (string, error) {
 	return "", fmt.Errorf("can't found valid ip addr")
 }
 
-func randomIPv4FromSubnet(subnet *net.IPNet) net.IP {
-	maskSize, _ := subnet.Mask.Size()
-	randIP := i32ToIP(ipToI32(subnet.IP) + int32(rand.Intn(1<<(32-maskSize))))
-	return randIP
+func randomIPFromSubnet(subnet *net.IPNet) net.IP {
+	ones, bits := subnet.Mask.Size()
+	offset, _ := crand.Int(crand.Reader, new(big.Int).Lsh(big.NewInt(1), uint(bits-ones)))
+	return intToIP(new(big.Int).Add(ipToInt(subnet.IP), offset), len(subnet.IP))
 }
 
-func cidrV4Range(subnet *net.IPNet) (net.IP, net.IP) {
+func cidrRange(subnet *net.IPNet) (net.IP, net.IP) {
 	ones, bits := subnet.Mask.Size()
-	return subnet.IP, i32ToIP(ipToI32(subnet.IP) + int32(1<<(bits-ones)-1))
+	size := new(big.Int).Lsh(big.NewInt(1), uint(bits-ones))
+	last := new(big.Int).Add(ipToInt(subnet.IP), size.Sub(size, big.NewInt(1)))
+	return subnet.IP, intToIP(last, len(subnet.IP))
 }
 
-func ipToI32(ip net.IP) int32 {
-	ip = ip.To4()
-	return int32(ip[0])<<24 | int32(ip[1])<<16 | int32(ip[2])<<8 | int32(ip[3])
+func ipToInt(ip net.IP) *big.Int {
+	if ipv4 := ip.To4(); ipv4 != nil {
+		ip = ipv4
+	}
+	return new(big.Int).SetBytes(ip)
 }
 
-func i32ToIP(a int32) net.IP {
-	return net.IPv4(byte(a>>24), byte(a>>16), byte(a>>8), byte(a))
+// intToIP converts the integer to ip, ipLen must be net.IPv4len or net.IPv6len
+func intToIP(a *big.Int, ipLen int) net.IP {
+	ip := a.FillBytes(make([]byte, ipLen))
+	if ipLen == net.IPv4len {
+		return net.IPv4(ip[0], ip[1], ip[2], ip[3])
+	}
+	return ip
 }
 
 func containsSubnet(subnet1, subnet2 *net.IPNet) bool {
-	maskSize1, _ := subnet1.Mask.Size()
-	maskSize2, _ := subnet2.Mask.Size()
+	maskSize1, bits1 := subnet1.Mask.Size()
+	maskSize2, bits2 := subnet2.Mask.Size()
 
-	if maskSize1 > maskSize2 {
+	if bits1 != bits2 || maskSize1 > maskSize2 {
 		return false
 	}
 
-	return subnet1.Contains(subnet1.IP)
+	return subnet1.Contains(subnet2.IP)
 }
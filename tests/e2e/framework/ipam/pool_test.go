@@ -20,9 +20,11 @@ import (
 	"fmt"
 	"net"
 	"testing"
+
+	"github.com/everoute/everoute/tests/e2e/framework/config"
 )
 
-func Test_cidrV4Range(t *testing.T) {
+func Test_cidrRange(t *testing.T) {
 	optionsCases := []struct {
 		cidr  string
 		begin string
@@ -32,15 +34,85 @@ func Test_cidrV4Range(t *testing.T) {
 		{cidr: "10.0.0.9/24", begin: "10.0.0.0", end: "10.0.0.255"},
 		{cidr: "10.0.0.9/32", begin: "10.0.0.9", end: "10.0.0.9"},
 		{cidr: "10.0.0.9/0", begin: "0.0.0.0", end: "255.255.255.255"},
+		{cidr: "fd00:10::/120", begin: "fd00:10::", end: "fd00:10::ff"},
+		{cidr: "fd00:10::9/64", begin: "fd00:10::", end: "fd00:10::ffff:ffff:ffff:ffff"},
+		{cidr: "fd00:10::9/128", begin: "fd00:10::9", end: "fd00:10::9"},
+		{cidr: "fd00:10::9/0", begin: "::", end: "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
 	}
 
 	for _, tt := range optionsCases {
-		t.Run(fmt.Sprintf("test cidrV4Range %s", tt), func(t *testing.T) {
+		t.Run(fmt.Sprintf("test cidrRange %s", tt), func(t *testing.T) {
 			_, cidr, _ := net.ParseCIDR(tt.cidr)
-			begin, end := cidrV4Range(cidr)
+			begin, end := cidrRange(cidr)
 			if !begin.Equal(net.ParseIP(tt.begin)) || !end.Equal(net.ParseIP(tt.end)) {
 				t.Fatalf("expect cidr %s range %s-%s, got %s-%s", tt.cidr, tt.begin, tt.end, begin, end)
 			}
 		})
 	}
 }
+
+func TestAssignFromSubnet(t *testing.T) {
+	p, err := NewPool(&config.IPAMConfig{IPRange: "10.0.0.0/24", IPv6Range: "fd00:10::/120"})
+	if err != nil {
+		t.Fatalf("unexpect error: %s", err)
+	}
+	ipv4Only, err := NewPool(&config.IPAMConfig{IPRange: "10.0.0.0/24"})
+	if err != nil {
+		t.Fatalf("unexpect error: %s", err)
+	}
+
+	optionsCases := []struct {
+		pool      Pool
+		subnet    string
+		expectErr bool
+	}{
+		{pool: p, subnet: ""},
+		{pool: p, subnet: "10.0.0.16/28"},
+		{pool: p, subnet: "fd00:10::/124"},
+		{pool: p, subnet: "fd00:10::10/124"},
+		{pool: p, subnet: "10.0.1.0/28", expectErr: true},
+		{pool: p, subnet: "fd00:20::/124", expectErr: true},
+		{pool: p, subnet: "fd00:10::/64", expectErr: true},
+		{pool: ipv4Only, subnet: "fd00:10::/124", expectErr: true},
+	}
+
+	for _, tt := range optionsCases {
+		t.Run(fmt.Sprintf("test assign from subnet %s", tt.subnet), func(t *testing.T) {
+			ipAddr, err := tt.pool.AssignFromSubnet(tt.subnet)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatalf("expect error when assign from subnet %s, got %s", tt.subnet, ipAddr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpect error: %s", err)
+			}
+
+			ip, ipNet, err := net.ParseCIDR(ipAddr)
+			if err != nil {
+				t.Fatalf("unexpect ip addr %s: %s", ipAddr, err)
+			}
+			if tt.subnet == "" {
+				tt.subnet = "10.0.0.0/24"
+			}
+			_, subnet, _ := net.ParseCIDR(tt.subnet)
+			if !subnet.Contains(ip) {
+				t.Fatalf("expect assign ip from subnet %s, got %s", tt.subnet, ipAddr)
+			}
+			// ip addr has the mask of the ip range
+			if ones, _ := ipNet.Mask.Size(); (ip.To4() != nil && ones != 24) || (ip.To4() == nil && ones != 120) {
+				t.Fatalf("unexpect mask of ip addr %s", ipAddr)
+			}
+		})
+	}
+}
+
+func TestNewPoolMismatchFamily(t *testing.T) {
+	if _, err := NewPool(&config.IPAMConfig{IPRange: "fd00:10::/120"}); err == nil {
+		t.Fatalf("expect error when ip range is IPv6")
+	}
+	if _, err := NewPool(&config.IPAMConfig{IPRange: "10.0.0.0/24", IPv6Range: "10.0.1.0/24"}); err == nil {
+		t.Fatalf("expect error when ipv6 range is IPv4")
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/go-ping/ping"
+	"github.com/j-keck/arping"
 	"github.com/secsy/goftp"
 	"github.com/spf13/cobra"
 )
@@ -63,6 +64,8 @@ func runConnect(server string, protocol string, packetNum, passScore int, timeou
 		receive, err = connectUDP(server, packetNum, timeout)
 	case "icmp":
 		receive, err = connectICMP(server, packetNum, timeout)
+	case "arp":
+		receive, err = connectARP(server, packetNum, timeout)
 	case "ftp":
 		err = connectFTP(server)
 		if err != nil {
@@ -124,6 +127,29 @@ func connectICMP(server string, num int, timeout time.Duration) (int, error) {
 	return pinger.Statistics().PacketsRecv, nil
 }
 
+func connectARP(server string, num int, timeout time.Duration) (int, error) {
+	dstIP := net.ParseIP(server)
+	if dstIP == nil {
+		return 0, fmt.Errorf("unexpect ip address %s", server)
+	}
+	if timeout != 0 {
+		arping.SetTimeout(timeout)
+	}
+
+	var succeed int
+	for i := 0; i < num; i++ {
+		hwAddr, duration, err := arping.Ping(dstIP)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		succeed++
+		fmt.Printf("arp reply from %s [%s]: time=%v\n", dstIP, hwAddr, duration)
+	}
+
+	return succeed, nil
+}
+
 func connectRead(conn net.Conn, num int, timeout time.Duration) (int, error) {
 	if timeout != 0 {
 		err := conn.SetDeadline(time.Now().Add(timeout))